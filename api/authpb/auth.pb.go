@@ -11,6 +11,7 @@ import (
 
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/golang/protobuf/proto"
+	_ "go.etcd.io/etcd/api/v3/versionpb"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -52,8 +53,58 @@ func (Permission_Type) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_8bbd6f3875b0e874, []int{2, 0}
 }
 
+// Operation is an operation on keys a permission can be limited to.
+// READ permissions cover RANGE, WATCH and TXN, WRITE permissions cover
+// PUT, DELETE and LEASE.
+type Permission_Operation int32
+
+const (
+	// RANGE reads the keys.
+	RANGE Permission_Operation = 0
+	// PUT writes the keys.
+	PUT Permission_Operation = 1
+	// DELETE deletes the keys, including by revoking the leases attached to them.
+	DELETE Permission_Operation = 2
+	// LEASE attaches leases to the keys and changes the leases attached to them.
+	LEASE Permission_Operation = 3
+	// WATCH watches the keys.
+	WATCH Permission_Operation = 4
+	// TXN compares the keys in transactions.
+	TXN Permission_Operation = 5
+)
+
+var Permission_Operation_name = map[int32]string{
+	0: "RANGE",
+	1: "PUT",
+	2: "DELETE",
+	3: "LEASE",
+	4: "WATCH",
+	5: "TXN",
+}
+
+var Permission_Operation_value = map[string]int32{
+	"RANGE":  0,
+	"PUT":    1,
+	"DELETE": 2,
+	"LEASE":  3,
+	"WATCH":  4,
+	"TXN":    5,
+}
+
+func (x Permission_Operation) String() string {
+	return proto.EnumName(Permission_Operation_name, int32(x))
+}
+
+func (Permission_Operation) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8bbd6f3875b0e874, []int{2, 1}
+}
+
 type UserAddOptions struct {
-	NoPassword           bool     `protobuf:"varint,1,opt,name=no_password,json=noPassword,proto3" json:"no_password,omitempty"`
+	NoPassword bool `protobuf:"varint,1,opt,name=no_password,json=noPassword,proto3" json:"no_password,omitempty"`
+	// prefix binds the user to the tenant of the keys under the prefix. The keys,
+	// ranges and watches of the requests of the user are rewritten under the prefix
+	// by the server, and the prefix is stripped from the keys of the responses.
+	Prefix               []byte   `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -138,12 +189,21 @@ var xxx_messageInfo_User proto.InternalMessageInfo
 
 // Permission is a single entity
 type Permission struct {
-	PermType             Permission_Type `protobuf:"varint,1,opt,name=permType,proto3,enum=authpb.Permission_Type" json:"permType,omitempty"`
-	Key                  []byte          `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	RangeEnd             []byte          `protobuf:"bytes,3,opt,name=range_end,json=rangeEnd,proto3" json:"range_end,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	PermType Permission_Type `protobuf:"varint,1,opt,name=permType,proto3,enum=authpb.Permission_Type" json:"permType,omitempty"`
+	Key      []byte          `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	RangeEnd []byte          `protobuf:"bytes,3,opt,name=range_end,json=rangeEnd,proto3" json:"range_end,omitempty"`
+	// pattern makes key a glob pattern matching whole keys, with the syntax of
+	// Go's path.Match: "*" matches any sequence of characters but "/". Patterns
+	// apply to the operations on single keys; range_end must be empty.
+	Pattern bool `protobuf:"varint,4,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// deny denies the operations of the permission on its keys, even if other
+	// permissions of the roles of a user grant them.
+	Deny bool `protobuf:"varint,5,opt,name=deny,proto3" json:"deny,omitempty"`
+	// ops limits the permission to some of the operations of its type.
+	Ops                  []Permission_Operation `protobuf:"varint,6,rep,packed,name=ops,proto3,enum=authpb.Permission_Operation" json:"ops,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
 }
 
 func (m *Permission) Reset()         { *m = Permission{} }
@@ -223,6 +283,7 @@ var xxx_messageInfo_Role proto.InternalMessageInfo
 
 func init() {
 	proto.RegisterEnum("authpb.Permission_Type", Permission_Type_name, Permission_Type_value)
+	proto.RegisterEnum("authpb.Permission_Operation", Permission_Operation_name, Permission_Operation_value)
 	proto.RegisterType((*UserAddOptions)(nil), "authpb.UserAddOptions")
 	proto.RegisterType((*User)(nil), "authpb.User")
 	proto.RegisterType((*Permission)(nil), "authpb.Permission")
@@ -232,29 +293,38 @@ func init() {
 func init() { proto.RegisterFile("auth.proto", fileDescriptor_8bbd6f3875b0e874) }
 
 var fileDescriptor_8bbd6f3875b0e874 = []byte{
-	// 338 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xcf, 0x4e, 0xea, 0x40,
-	0x14, 0xc6, 0x3b, 0xb4, 0x70, 0xdb, 0xc3, 0x85, 0x90, 0x13, 0x72, 0x6f, 0x83, 0x49, 0x6d, 0xba,
-	0x6a, 0x5c, 0x54, 0x85, 0x8d, 0x5b, 0x8c, 0x2c, 0x5c, 0x49, 0x26, 0x18, 0x97, 0xa4, 0xa4, 0x13,
-	0x24, 0xc0, 0x4c, 0x33, 0x83, 0x31, 0x6c, 0x7c, 0x0e, 0x17, 0x3e, 0x10, 0x4b, 0x1e, 0x41, 0xf0,
-	0x45, 0x4c, 0x67, 0xf8, 0x13, 0xa2, 0xbb, 0xef, 0x7c, 0xe7, 0xfb, 0x66, 0x7e, 0x99, 0x01, 0x48,
-	0x5f, 0x16, 0xcf, 0x49, 0x2e, 0xc5, 0x42, 0x60, 0xa5, 0xd0, 0xf9, 0xa8, 0xd5, 0x1c, 0x8b, 0xb1,
-	0xd0, 0xd6, 0x65, 0xa1, 0xcc, 0x36, 0xba, 0x86, 0xfa, 0xa3, 0x62, 0xb2, 0x9b, 0x65, 0x0f, 0xf9,
-	0x62, 0x22, 0xb8, 0xc2, 0x73, 0xa8, 0x72, 0x31, 0xcc, 0x53, 0xa5, 0x5e, 0x85, 0xcc, 0x7c, 0x12,
-	0x92, 0xd8, 0xa5, 0xc0, 0x45, 0x7f, 0xe7, 0x44, 0x6f, 0xe0, 0x14, 0x15, 0x44, 0x70, 0x78, 0x3a,
-	0x67, 0x3a, 0xf1, 0x97, 0x6a, 0x8d, 0x2d, 0x70, 0x0f, 0xcd, 0x92, 0xf6, 0x0f, 0x33, 0x36, 0xa1,
-	0x2c, 0xc5, 0x8c, 0x29, 0xdf, 0x0e, 0xed, 0xd8, 0xa3, 0x66, 0xc0, 0x2b, 0xf8, 0x23, 0xcc, 0xcd,
-	0xbe, 0x13, 0x92, 0xb8, 0xda, 0xfe, 0x97, 0x18, 0xe0, 0xe4, 0x94, 0x8b, 0xee, 0x63, 0xd1, 0x07,
-	0x01, 0xe8, 0x33, 0x39, 0x9f, 0x28, 0x35, 0x11, 0x1c, 0x3b, 0xe0, 0xe6, 0x4c, 0xce, 0x07, 0xcb,
-	0xdc, 0xa0, 0xd4, 0xdb, 0xff, 0xf7, 0x27, 0x1c, 0x53, 0x49, 0xb1, 0xa6, 0x87, 0x20, 0x36, 0xc0,
-	0x9e, 0xb2, 0xe5, 0x0e, 0xb1, 0x90, 0x78, 0x06, 0x9e, 0x4c, 0xf9, 0x98, 0x0d, 0x19, 0xcf, 0x7c,
-	0xdb, 0xa0, 0x6b, 0xa3, 0xc7, 0xb3, 0xe8, 0x02, 0x1c, 0x5d, 0x73, 0xc1, 0xa1, 0xbd, 0xee, 0x5d,
-	0xc3, 0x42, 0x0f, 0xca, 0x4f, 0xf4, 0x7e, 0xd0, 0x6b, 0x10, 0xac, 0x81, 0x57, 0x98, 0x66, 0x2c,
-	0x45, 0x03, 0x70, 0xa8, 0x98, 0xb1, 0x5f, 0x9f, 0xe7, 0x06, 0x6a, 0x53, 0xb6, 0x3c, 0x62, 0xf9,
-	0xa5, 0xd0, 0x8e, 0xab, 0x6d, 0xfc, 0x09, 0x4c, 0x4f, 0x83, 0xb7, 0xfe, 0x6a, 0x13, 0x58, 0xeb,
-	0x4d, 0x60, 0xad, 0xb6, 0x01, 0x59, 0x6f, 0x03, 0xf2, 0xb9, 0x0d, 0xc8, 0xfb, 0x57, 0x60, 0x8d,
-	0x2a, 0xfa, 0x23, 0x3b, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x61, 0x66, 0xc6, 0x9d, 0xf4, 0x01,
-	0x00, 0x00,
+	// 486 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x52, 0xcb, 0x6e, 0xd3, 0x40,
+	0x14, 0x8d, 0x33, 0xce, 0xc3, 0xb7, 0x6d, 0x64, 0x8d, 0x2a, 0x18, 0xa5, 0xc8, 0x35, 0x5e, 0x59,
+	0x2c, 0x1c, 0x94, 0x48, 0xc0, 0xd6, 0xa5, 0x16, 0x20, 0x55, 0x6d, 0x34, 0x75, 0x05, 0xbb, 0xca,
+	0xc1, 0x43, 0xb0, 0xda, 0xcc, 0x8c, 0xc6, 0xe6, 0x91, 0x0d, 0x1f, 0xc0, 0x92, 0x15, 0x3f, 0xc2,
+	0x3f, 0x74, 0xd9, 0x4f, 0xa0, 0x61, 0xc7, 0x57, 0xa0, 0x19, 0xe7, 0xa1, 0x88, 0xee, 0xce, 0x3d,
+	0xf7, 0xdc, 0xa3, 0x73, 0xec, 0x01, 0xc8, 0x3e, 0x55, 0x1f, 0x23, 0xa9, 0x44, 0x25, 0x70, 0x5b,
+	0x63, 0x39, 0xe9, 0xef, 0x4f, 0xc5, 0x54, 0x18, 0x6a, 0xa0, 0x51, 0xbd, 0xed, 0xfb, 0xac, 0x7a,
+	0x9f, 0x0f, 0x32, 0x59, 0x0c, 0x3e, 0x33, 0x55, 0x16, 0x82, 0xcb, 0xc9, 0x0a, 0xd5, 0x8a, 0x80,
+	0x42, 0xef, 0xa2, 0x64, 0x2a, 0xce, 0xf3, 0x33, 0x59, 0x15, 0x82, 0x97, 0xf8, 0x10, 0x76, 0xb8,
+	0xb8, 0x94, 0x59, 0x59, 0x7e, 0x11, 0x2a, 0x27, 0x96, 0x6f, 0x85, 0x5d, 0x0a, 0x5c, 0x8c, 0x97,
+	0x0c, 0x3e, 0x84, 0xb6, 0x54, 0xec, 0x43, 0xf1, 0x95, 0x34, 0x7d, 0x2b, 0xdc, 0x3d, 0xea, 0x7c,
+	0xff, 0x45, 0xd0, 0x28, 0x7a, 0x46, 0x97, 0x74, 0xf0, 0x0d, 0x6c, 0xed, 0x89, 0x31, 0xd8, 0x3c,
+	0x9b, 0x31, 0x63, 0xb1, 0x4b, 0x0d, 0xc6, 0x7d, 0xe8, 0xae, 0xad, 0xcd, 0x39, 0x5d, 0xcf, 0x78,
+	0x1f, 0x5a, 0x4a, 0x5c, 0xb3, 0x92, 0x20, 0x1f, 0x85, 0x0e, 0xad, 0x07, 0xfc, 0x14, 0x3a, 0xa2,
+	0x8e, 0x46, 0x6c, 0xdf, 0x0a, 0x77, 0x86, 0x0f, 0xa2, 0xba, 0x73, 0xb4, 0x1d, 0x9c, 0xae, 0x64,
+	0xc1, 0xdf, 0x26, 0xc0, 0x98, 0xa9, 0x59, 0x51, 0xea, 0xa2, 0x78, 0x04, 0x5d, 0xc9, 0xd4, 0x2c,
+	0x9d, 0xcb, 0x3a, 0x4a, 0x6f, 0xf8, 0x70, 0xe5, 0xb0, 0x51, 0x45, 0x7a, 0x4d, 0xd7, 0x42, 0xec,
+	0x02, 0xba, 0x62, 0xf3, 0x65, 0x44, 0x0d, 0xf1, 0x01, 0x38, 0x2a, 0xe3, 0x53, 0x76, 0xc9, 0x78,
+	0x4e, 0x50, 0x1d, 0xdd, 0x10, 0x09, 0xcf, 0xf1, 0x63, 0xe8, 0xc8, 0xac, 0xaa, 0x98, 0xe2, 0x26,
+	0x64, 0x77, 0xf3, 0x51, 0x56, 0x3c, 0x3e, 0x00, 0x3b, 0x67, 0x7c, 0x4e, 0x5a, 0xdb, 0x7b, 0x43,
+	0xe2, 0xe7, 0x80, 0x84, 0x2c, 0x49, 0xdb, 0x47, 0x61, 0x6f, 0xf8, 0xe8, 0x9e, 0x78, 0x67, 0x92,
+	0xa9, 0x4c, 0xd7, 0xdb, 0x5c, 0xea, 0x8b, 0xe0, 0x09, 0xd8, 0x26, 0x6f, 0x17, 0x6c, 0x9a, 0xc4,
+	0xc7, 0x6e, 0x03, 0x3b, 0xd0, 0x7a, 0x4b, 0xdf, 0xa4, 0x89, 0x6b, 0xe1, 0x3d, 0x70, 0x34, 0x59,
+	0x8f, 0xcd, 0xe0, 0x1c, 0x9c, 0xb5, 0x8d, 0x96, 0xd1, 0xf8, 0xf4, 0x55, 0xe2, 0x36, 0x70, 0x07,
+	0xd0, 0xf8, 0x22, 0x75, 0x2d, 0x0c, 0xd0, 0x3e, 0x4e, 0x4e, 0x12, 0x2d, 0xd6, 0xfb, 0x93, 0x24,
+	0x3e, 0x4f, 0x5c, 0x64, 0x1c, 0xe3, 0xf4, 0xe5, 0x6b, 0xd7, 0xd6, 0xd2, 0xf4, 0xdd, 0xa9, 0xdb,
+	0xea, 0x77, 0x7e, 0xd4, 0x29, 0x82, 0x14, 0x6c, 0x2a, 0xae, 0xd9, 0xbd, 0x3f, 0xfb, 0x05, 0xec,
+	0x5d, 0xb1, 0xf9, 0xa6, 0x05, 0x69, 0xfa, 0x28, 0xdc, 0x19, 0xe2, 0xff, 0xfb, 0xd1, 0x6d, 0xe1,
+	0x11, 0xb9, 0xb9, 0xf3, 0x1a, 0xb7, 0x77, 0x5e, 0xe3, 0x66, 0xe1, 0x59, 0xb7, 0x0b, 0xcf, 0xfa,
+	0xbd, 0xf0, 0xac, 0x9f, 0x7f, 0xbc, 0xc6, 0xa4, 0x6d, 0xde, 0xed, 0xe8, 0xdf, 0x00, 0x86, 0x98,
+	0x63, 0xf6, 0x05, 0x03, 0x00, 0x00,
 }
 
 func (m *UserAddOptions) Marshal() (dAtA []byte, err error) {
@@ -281,6 +351,13 @@ func (m *UserAddOptions) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Prefix) > 0 {
+		i -= len(m.Prefix)
+		copy(dAtA[i:], m.Prefix)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Prefix)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if m.NoPassword {
 		i--
 		if m.NoPassword {
@@ -380,6 +457,44 @@ func (m *Permission) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Ops) > 0 {
+		dAtA3 := make([]byte, len(m.Ops)*10)
+		var j2 int
+		for _, num := range m.Ops {
+			for num >= 1<<7 {
+				dAtA3[j2] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j2++
+			}
+			dAtA3[j2] = uint8(num)
+			j2++
+		}
+		i -= j2
+		copy(dAtA[i:], dAtA3[:j2])
+		i = encodeVarintAuth(dAtA, i, uint64(j2))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Deny {
+		i--
+		if m.Deny {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Pattern {
+		i--
+		if m.Pattern {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if len(m.RangeEnd) > 0 {
 		i -= len(m.RangeEnd)
 		copy(dAtA[i:], m.RangeEnd)
@@ -470,6 +585,10 @@ func (m *UserAddOptions) Size() (n int) {
 	if m.NoPassword {
 		n += 2
 	}
+	l = len(m.Prefix)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -523,6 +642,19 @@ func (m *Permission) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovAuth(uint64(l))
 	}
+	if m.Pattern {
+		n += 2
+	}
+	if m.Deny {
+		n += 2
+	}
+	if len(m.Ops) > 0 {
+		l = 0
+		for _, e := range m.Ops {
+			l += sovAuth(uint64(e))
+		}
+		n += 1 + sovAuth(uint64(l)) + l
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -606,6 +738,40 @@ func (m *UserAddOptions) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.NoPassword = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefix", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Prefix = append(m.Prefix[:0], dAtA[iNdEx:postIndex]...)
+			if m.Prefix == nil {
+				m.Prefix = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
@@ -931,6 +1097,115 @@ func (m *Permission) Unmarshal(dAtA []byte) error {
 				m.RangeEnd = []byte{}
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pattern", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Pattern = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deny", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Deny = bool(v != 0)
+		case 6:
+			if wireType == 0 {
+				var v Permission_Operation
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowAuth
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= Permission_Operation(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Ops = append(m.Ops, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowAuth
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthAuth
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthAuth
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				if elementCount != 0 && len(m.Ops) == 0 {
+					m.Ops = make([]Permission_Operation, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v Permission_Operation
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowAuth
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= Permission_Operation(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Ops = append(m.Ops, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ops", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
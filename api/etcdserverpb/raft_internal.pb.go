@@ -31,7 +31,10 @@ type RequestHeader struct {
 	// username is a username that is associated with an auth token of gRPC connection
 	Username string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
 	// auth_revision is a revision number of auth.authStore. It is not related to mvcc
-	AuthRevision         uint64   `protobuf:"varint,3,opt,name=auth_revision,json=authRevision,proto3" json:"auth_revision,omitempty"`
+	AuthRevision uint64 `protobuf:"varint,3,opt,name=auth_revision,json=authRevision,proto3" json:"auth_revision,omitempty"`
+	// roles are granted to the user in addition to its own roles by the rules
+	// mapping client certificates to users and roles
+	Roles                []string `protobuf:"bytes,4,rep,name=roles,proto3" json:"roles,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -85,6 +88,13 @@ type InternalRaftRequest struct {
 	LeaseRevoke              *LeaseRevokeRequest                       `protobuf:"bytes,9,opt,name=lease_revoke,json=leaseRevoke,proto3" json:"lease_revoke,omitempty"`
 	Alarm                    *AlarmRequest                             `protobuf:"bytes,10,opt,name=alarm,proto3" json:"alarm,omitempty"`
 	LeaseCheckpoint          *LeaseCheckpointRequest                   `protobuf:"bytes,11,opt,name=lease_checkpoint,json=leaseCheckpoint,proto3" json:"lease_checkpoint,omitempty"`
+	LeaseUpdate              *LeaseUpdateRequest                       `protobuf:"bytes,12,opt,name=lease_update,json=leaseUpdate,proto3" json:"lease_update,omitempty"`
+	LeaseMove                *LeaseMoveRequest                         `protobuf:"bytes,13,opt,name=lease_move,json=leaseMove,proto3" json:"lease_move,omitempty"`
+	LeaseExpire              *LeaseExpireRequest                       `protobuf:"bytes,14,opt,name=lease_expire,json=leaseExpire,proto3" json:"lease_expire,omitempty"`
+	RetentionPut             *RetentionPutRequest                      `protobuf:"bytes,15,opt,name=retention_put,json=retentionPut,proto3" json:"retention_put,omitempty"`
+	RetentionDelete          *RetentionDeleteRequest                   `protobuf:"bytes,16,opt,name=retention_delete,json=retentionDelete,proto3" json:"retention_delete,omitempty"`
+	SchemaPut                *SchemaPutRequest                         `protobuf:"bytes,17,opt,name=schema_put,json=schemaPut,proto3" json:"schema_put,omitempty"`
+	SchemaDelete             *SchemaDeleteRequest                      `protobuf:"bytes,18,opt,name=schema_delete,json=schemaDelete,proto3" json:"schema_delete,omitempty"`
 	AuthEnable               *AuthEnableRequest                        `protobuf:"bytes,1000,opt,name=auth_enable,json=authEnable,proto3" json:"auth_enable,omitempty"`
 	AuthDisable              *AuthDisableRequest                       `protobuf:"bytes,1011,opt,name=auth_disable,json=authDisable,proto3" json:"auth_disable,omitempty"`
 	AuthStatus               *AuthStatusRequest                        `protobuf:"bytes,1013,opt,name=auth_status,json=authStatus,proto3" json:"auth_status,omitempty"`
@@ -228,83 +238,137 @@ func (m *InternalAuthenticateRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_InternalAuthenticateRequest proto.InternalMessageInfo
 
+// LeaseExpireRequest revokes a lease whose time-to-live elapsed. It is proposed by the
+// leader instead of a LeaseRevokeRequest so that members report the revocation as an expiry.
+type LeaseExpireRequest struct {
+	ID                   int64    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseExpireRequest) Reset()         { *m = LeaseExpireRequest{} }
+func (m *LeaseExpireRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaseExpireRequest) ProtoMessage()    {}
+func (*LeaseExpireRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b4c9a9be0cfca103, []int{4}
+}
+func (m *LeaseExpireRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LeaseExpireRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LeaseExpireRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LeaseExpireRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseExpireRequest.Merge(m, src)
+}
+func (m *LeaseExpireRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *LeaseExpireRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseExpireRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseExpireRequest proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*RequestHeader)(nil), "etcdserverpb.RequestHeader")
 	proto.RegisterType((*InternalRaftRequest)(nil), "etcdserverpb.InternalRaftRequest")
 	proto.RegisterType((*EmptyResponse)(nil), "etcdserverpb.EmptyResponse")
 	proto.RegisterType((*InternalAuthenticateRequest)(nil), "etcdserverpb.InternalAuthenticateRequest")
+	proto.RegisterType((*LeaseExpireRequest)(nil), "etcdserverpb.LeaseExpireRequest")
 }
 
 func init() { proto.RegisterFile("raft_internal.proto", fileDescriptor_b4c9a9be0cfca103) }
 
 var fileDescriptor_b4c9a9be0cfca103 = []byte{
-	// 1054 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0x5d, 0x6f, 0x1b, 0x45,
-	0x14, 0xad, 0xd3, 0x34, 0x89, 0xc7, 0x49, 0x9a, 0x4e, 0x52, 0x3a, 0x38, 0x92, 0x71, 0x03, 0x2d,
-	0x01, 0x8a, 0x53, 0x1c, 0x78, 0xe1, 0x05, 0x5c, 0x3b, 0x4a, 0x83, 0x4a, 0x15, 0x6d, 0x0b, 0xaa,
-	0x84, 0xd0, 0x32, 0xde, 0xbd, 0xb1, 0xb7, 0x59, 0xef, 0x2e, 0x33, 0x63, 0x37, 0x7d, 0xe5, 0x91,
-	0x67, 0x40, 0xfc, 0x0c, 0x3e, 0xff, 0x43, 0x85, 0xf8, 0x28, 0xf0, 0x07, 0x20, 0xbc, 0xf0, 0x0e,
-	0xbc, 0xa3, 0xf9, 0xd8, 0x5d, 0xaf, 0x3d, 0xce, 0xdb, 0xfa, 0xde, 0x73, 0xcf, 0x39, 0x33, 0x73,
-	0xef, 0x78, 0xd0, 0x3a, 0xa3, 0x47, 0xc2, 0x0d, 0x22, 0x01, 0x2c, 0xa2, 0x61, 0x23, 0x61, 0xb1,
-	0x88, 0xf1, 0x32, 0x08, 0xcf, 0xe7, 0xc0, 0x46, 0xc0, 0x92, 0x6e, 0x75, 0xa3, 0x17, 0xf7, 0x62,
-	0x95, 0xd8, 0x91, 0x5f, 0x1a, 0x53, 0x5d, 0xcb, 0x31, 0x26, 0x52, 0x66, 0x89, 0x67, 0x3e, 0xeb,
-	0x32, 0xb9, 0x43, 0x93, 0x60, 0x67, 0x04, 0x8c, 0x07, 0x71, 0x94, 0x74, 0xd3, 0x2f, 0x83, 0xb8,
-	0x9e, 0x21, 0x06, 0x30, 0xe8, 0x02, 0xe3, 0xfd, 0x20, 0x49, 0xba, 0x63, 0x3f, 0x34, 0x6e, 0x8b,
-	0xa1, 0x15, 0x07, 0x3e, 0x1e, 0x02, 0x17, 0xb7, 0x81, 0xfa, 0xc0, 0xf0, 0x2a, 0x9a, 0x3b, 0xe8,
-	0x90, 0x52, 0xbd, 0xb4, 0x3d, 0xef, 0xcc, 0x1d, 0x74, 0x70, 0x15, 0x2d, 0x0d, 0xb9, 0x34, 0x3f,
-	0x00, 0x32, 0x57, 0x2f, 0x6d, 0x97, 0x9d, 0xec, 0x37, 0xbe, 0x81, 0x56, 0xe8, 0x50, 0xf4, 0x5d,
-	0x06, 0xa3, 0x40, 0x6a, 0x93, 0xf3, 0xb2, 0xec, 0xd6, 0xe2, 0xa7, 0xdf, 0x93, 0xf3, 0xbb, 0x8d,
-	0xd7, 0x9c, 0x65, 0x99, 0x75, 0x4c, 0xf2, 0xcd, 0xc5, 0x4f, 0x54, 0xf8, 0xe6, 0xd6, 0x0f, 0x18,
-	0xad, 0x1f, 0x98, 0x1d, 0x71, 0xe8, 0x91, 0x30, 0x06, 0xf0, 0x2e, 0x5a, 0xe8, 0x2b, 0x13, 0xc4,
-	0xaf, 0x97, 0xb6, 0x2b, 0xcd, 0xcd, 0xc6, 0xf8, 0x3e, 0x35, 0x0a, 0x3e, 0x1d, 0x03, 0x9d, 0xf2,
-	0x7b, 0x0d, 0xcd, 0x8d, 0x9a, 0xca, 0x69, 0xa5, 0x79, 0xd9, 0x4a, 0xe0, 0xcc, 0x8d, 0x9a, 0xf8,
-	0x26, 0xba, 0xc0, 0x68, 0xd4, 0x03, 0x65, 0xb9, 0xd2, 0xac, 0x4e, 0x20, 0x65, 0x2a, 0x85, 0x6b,
-	0x20, 0x7e, 0x19, 0x9d, 0x4f, 0x86, 0x82, 0xcc, 0x2b, 0x3c, 0x29, 0xe2, 0x0f, 0x87, 0xe9, 0x22,
-	0x1c, 0x09, 0xc2, 0x6d, 0xb4, 0xec, 0x43, 0x08, 0x02, 0x5c, 0x2d, 0x72, 0x41, 0x15, 0xd5, 0x8b,
-	0x45, 0x1d, 0x85, 0x28, 0x48, 0x55, 0xfc, 0x3c, 0x26, 0x05, 0xc5, 0x49, 0x44, 0x16, 0x6c, 0x82,
-	0xf7, 0x4f, 0xa2, 0x4c, 0x50, 0x9c, 0x44, 0xf8, 0x2d, 0x84, 0xbc, 0x78, 0x90, 0x50, 0x4f, 0xc8,
-	0x63, 0x58, 0x54, 0x25, 0xcf, 0x15, 0x4b, 0xda, 0x59, 0x3e, 0xad, 0x1c, 0x2b, 0xc1, 0x6f, 0xa3,
-	0x4a, 0x08, 0x94, 0x83, 0xdb, 0x63, 0x34, 0x12, 0x64, 0xc9, 0xc6, 0x70, 0x47, 0x02, 0xf6, 0x65,
-	0x3e, 0x63, 0x08, 0xb3, 0x90, 0x5c, 0xb3, 0x66, 0x60, 0x30, 0x8a, 0x8f, 0x81, 0x94, 0x6d, 0x6b,
-	0x56, 0x14, 0x8e, 0x02, 0x64, 0x6b, 0x0e, 0xf3, 0x98, 0x3c, 0x16, 0x1a, 0x52, 0x36, 0x20, 0xc8,
-	0x76, 0x2c, 0x2d, 0x99, 0xca, 0x8e, 0x45, 0x01, 0xf1, 0x03, 0xb4, 0xa6, 0x65, 0xbd, 0x3e, 0x78,
-	0xc7, 0x49, 0x1c, 0x44, 0x82, 0x54, 0x54, 0xf1, 0x0b, 0x16, 0xe9, 0x76, 0x06, 0x32, 0x34, 0x69,
-	0xb3, 0xbe, 0xee, 0x5c, 0x0c, 0x8b, 0x00, 0xdc, 0x42, 0x15, 0xd5, 0xdd, 0x10, 0xd1, 0x6e, 0x08,
-	0xe4, 0x6f, 0xeb, 0xae, 0xb6, 0x86, 0xa2, 0xbf, 0xa7, 0x00, 0xd9, 0x9e, 0xd0, 0x2c, 0x84, 0x3b,
-	0x48, 0x8d, 0x80, 0xeb, 0x07, 0x5c, 0x71, 0xfc, 0xb3, 0x68, 0xdb, 0x14, 0xc9, 0xd1, 0xd1, 0x88,
-	0x6c, 0x53, 0x68, 0x1e, 0xc3, 0xef, 0x18, 0x23, 0x5c, 0x50, 0x31, 0xe4, 0xe4, 0xbf, 0x99, 0x46,
-	0xee, 0x29, 0xc0, 0xc4, 0xca, 0xde, 0xd0, 0x8e, 0x74, 0x0e, 0xdf, 0xd5, 0x8e, 0x20, 0x12, 0x81,
-	0x47, 0x05, 0x90, 0x7f, 0x35, 0xd9, 0x4b, 0x45, 0xb2, 0x74, 0x3a, 0x5b, 0x63, 0xd0, 0xd4, 0x5a,
-	0xa1, 0x1e, 0xef, 0x99, 0x2b, 0x40, 0xde, 0x09, 0x2e, 0xf5, 0x7d, 0xf2, 0xe3, 0xd2, 0xac, 0x25,
-	0xbe, 0xc7, 0x81, 0xb5, 0x7c, 0xbf, 0xb0, 0x44, 0x13, 0xc3, 0x77, 0xd1, 0x5a, 0x4e, 0xa3, 0x87,
-	0x80, 0xfc, 0xa4, 0x99, 0x9e, 0xb7, 0x33, 0x99, 0xe9, 0x31, 0x64, 0xab, 0xb4, 0x10, 0x2e, 0xda,
-	0xea, 0x81, 0x20, 0x3f, 0x9f, 0x69, 0x6b, 0x1f, 0xc4, 0x94, 0xad, 0x7d, 0x10, 0xb8, 0x87, 0x9e,
-	0xcd, 0x69, 0xbc, 0xbe, 0x1c, 0x4b, 0x37, 0xa1, 0x9c, 0x3f, 0x8a, 0x99, 0x4f, 0x7e, 0xd1, 0x94,
-	0xaf, 0xd8, 0x29, 0xdb, 0x0a, 0x7d, 0x68, 0xc0, 0x29, 0xfb, 0x33, 0xd4, 0x9a, 0xc6, 0x0f, 0xd0,
-	0xc6, 0x98, 0x5f, 0x39, 0x4f, 0x2e, 0x8b, 0x43, 0x20, 0x4f, 0xb5, 0xc6, 0xf5, 0x19, 0xb6, 0xd5,
-	0x2c, 0xc6, 0x79, 0xdb, 0x5c, 0xa2, 0x93, 0x19, 0xfc, 0x01, 0xba, 0x9c, 0x33, 0xeb, 0xd1, 0xd4,
-	0xd4, 0xbf, 0x6a, 0xea, 0x17, 0xed, 0xd4, 0x66, 0x46, 0xc7, 0xb8, 0x31, 0x9d, 0x4a, 0xe1, 0xdb,
-	0x68, 0x35, 0x27, 0x0f, 0x03, 0x2e, 0xc8, 0x6f, 0x9a, 0xf5, 0xaa, 0x9d, 0xf5, 0x4e, 0xc0, 0x45,
-	0xa1, 0x8f, 0xd2, 0x60, 0xc6, 0x24, 0xad, 0x69, 0xa6, 0xdf, 0x67, 0x32, 0x49, 0xe9, 0x29, 0xa6,
-	0x34, 0x98, 0x1d, 0xbd, 0x62, 0x92, 0x1d, 0xf9, 0x55, 0x79, 0xd6, 0xd1, 0xcb, 0x9a, 0xc9, 0x8e,
-	0x34, 0xb1, 0xac, 0x23, 0x15, 0x8d, 0xe9, 0xc8, 0xaf, 0xcb, 0xb3, 0x3a, 0x52, 0x56, 0x59, 0x3a,
-	0x32, 0x0f, 0x17, 0x6d, 0xc9, 0x8e, 0xfc, 0xe6, 0x4c, 0x5b, 0x93, 0x1d, 0x69, 0x62, 0xf8, 0x21,
-	0xaa, 0x8e, 0xd1, 0xa8, 0x46, 0x49, 0x80, 0x0d, 0x02, 0xae, 0xfe, 0x7f, 0xbf, 0xd5, 0x9c, 0x37,
-	0x66, 0x70, 0x4a, 0xf8, 0x61, 0x86, 0x4e, 0xf9, 0xaf, 0x50, 0x7b, 0x1e, 0x0f, 0xd0, 0x66, 0xae,
-	0x65, 0x5a, 0x67, 0x4c, 0xec, 0x3b, 0x2d, 0xf6, 0xaa, 0x5d, 0x4c, 0x77, 0xc9, 0xb4, 0x1a, 0xa1,
-	0x33, 0x00, 0xf8, 0x23, 0xb4, 0xee, 0x85, 0x43, 0x2e, 0x80, 0xb9, 0xe6, 0x2d, 0xe3, 0x72, 0x10,
-	0xe4, 0x33, 0x64, 0x46, 0x60, 0xfc, 0x21, 0xd3, 0x68, 0x6b, 0xe4, 0xfb, 0x1a, 0x78, 0x0f, 0xc4,
-	0xd4, 0xad, 0x77, 0xc9, 0x9b, 0x84, 0xe0, 0x87, 0xe8, 0x4a, 0xaa, 0xa0, 0xc9, 0x5c, 0x2a, 0x04,
-	0x53, 0x2a, 0x9f, 0x23, 0x73, 0x0f, 0xda, 0x54, 0xde, 0x55, 0xb1, 0x96, 0x10, 0xcc, 0x26, 0xb4,
-	0xe1, 0x59, 0x50, 0xf8, 0x43, 0x84, 0xfd, 0xf8, 0x51, 0xd4, 0x63, 0xd4, 0x07, 0x37, 0x88, 0x8e,
-	0x62, 0x25, 0xf3, 0x85, 0x96, 0xb9, 0x56, 0x94, 0xe9, 0xa4, 0xc0, 0x83, 0xe8, 0x28, 0xb6, 0x49,
-	0xac, 0xf9, 0x13, 0x88, 0xfc, 0x31, 0x75, 0x11, 0xad, 0xec, 0x0d, 0x12, 0xf1, 0xd8, 0x01, 0x9e,
-	0xc4, 0x11, 0x87, 0xad, 0xc7, 0x68, 0xf3, 0x8c, 0xeb, 0x1b, 0x63, 0x34, 0xaf, 0xde, 0x72, 0x25,
-	0xf5, 0x96, 0x53, 0xdf, 0xf2, 0x8d, 0x97, 0xdd, 0x6a, 0xe6, 0x8d, 0x97, 0xfe, 0xc6, 0x57, 0xd1,
-	0x32, 0x0f, 0x06, 0x49, 0x08, 0xae, 0x88, 0x8f, 0x41, 0x3f, 0xf1, 0xca, 0x4e, 0x45, 0xc7, 0xee,
-	0xcb, 0x50, 0xe6, 0xe5, 0xd6, 0xc6, 0x93, 0x3f, 0x6b, 0xe7, 0x9e, 0x9c, 0xd6, 0x4a, 0x4f, 0x4f,
-	0x6b, 0xa5, 0x3f, 0x4e, 0x6b, 0xa5, 0x2f, 0xff, 0xaa, 0x9d, 0xeb, 0x2e, 0xa8, 0x97, 0xe6, 0xee,
-	0xff, 0x01, 0x00, 0x00, 0xff, 0xff, 0x30, 0x36, 0x53, 0xc6, 0x0b, 0x0b, 0x00, 0x00,
+	// 1229 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x97, 0x49, 0x73, 0x1b, 0x45,
+	0x14, 0xc7, 0x23, 0xdb, 0xb1, 0xad, 0x96, 0xbc, 0xa4, 0xed, 0x24, 0x8d, 0x5d, 0x08, 0xc5, 0x90,
+	0x60, 0x20, 0xb1, 0x83, 0x0d, 0x3e, 0x70, 0x01, 0xc5, 0x32, 0x8e, 0x29, 0x27, 0xb8, 0xc6, 0x09,
+	0x95, 0x2a, 0x8a, 0x1a, 0x5a, 0x33, 0x6d, 0x69, 0xe2, 0xd9, 0xe8, 0x6e, 0x29, 0xce, 0x95, 0x23,
+	0x37, 0xaa, 0x80, 0xe2, 0x63, 0xb0, 0x7e, 0x87, 0x1c, 0x58, 0x02, 0x7c, 0x01, 0x30, 0x17, 0xce,
+	0x2c, 0x77, 0xaa, 0x97, 0xd9, 0xa4, 0x1e, 0xdf, 0x34, 0xef, 0xfd, 0xfb, 0xf7, 0xde, 0x9b, 0xf7,
+	0xa6, 0xd5, 0x0d, 0x16, 0x28, 0x3e, 0xe2, 0xb6, 0x17, 0x72, 0x42, 0x43, 0xec, 0xaf, 0xc5, 0x34,
+	0xe2, 0x11, 0xac, 0x13, 0xee, 0xb8, 0x8c, 0xd0, 0x01, 0xa1, 0x71, 0x67, 0x69, 0xb1, 0x1b, 0x75,
+	0x23, 0xe9, 0x58, 0x17, 0xbf, 0x94, 0x66, 0x69, 0x3e, 0xd3, 0x68, 0x4b, 0x95, 0xc6, 0x8e, 0xfe,
+	0xd9, 0x14, 0xce, 0x75, 0x1c, 0x7b, 0xeb, 0x03, 0x42, 0x99, 0x17, 0x85, 0x71, 0x27, 0xf9, 0xa5,
+	0x15, 0xd7, 0x52, 0x45, 0x40, 0x82, 0x0e, 0xa1, 0xac, 0xe7, 0xc5, 0x71, 0x27, 0xf7, 0xa0, 0x74,
+	0x2b, 0x9f, 0x56, 0xc0, 0x8c, 0x45, 0x3e, 0xea, 0x13, 0xc6, 0x6f, 0x13, 0xec, 0x12, 0x0a, 0x67,
+	0xc1, 0xd8, 0x5e, 0x1b, 0x55, 0x9a, 0x95, 0xd5, 0x09, 0x6b, 0x6c, 0xaf, 0x0d, 0x97, 0xc0, 0x74,
+	0x9f, 0x89, 0xec, 0x03, 0x82, 0xc6, 0x9a, 0x95, 0xd5, 0xaa, 0x95, 0x3e, 0xc3, 0xeb, 0x60, 0x06,
+	0xf7, 0x79, 0xcf, 0xa6, 0x64, 0xe0, 0x89, 0xe0, 0x68, 0x5c, 0x2c, 0xbb, 0x35, 0xf5, 0xc9, 0xf7,
+	0x68, 0x7c, 0x73, 0xed, 0x55, 0xab, 0x2e, 0xbc, 0x96, 0x76, 0xc2, 0x67, 0xc1, 0x79, 0x1a, 0xf9,
+	0x84, 0xa1, 0x89, 0xe6, 0xf8, 0x6a, 0x35, 0x51, 0x6d, 0x59, 0xca, 0xfa, 0xc6, 0xd4, 0xc7, 0xf2,
+	0xf9, 0xe6, 0xca, 0xdf, 0x97, 0xc0, 0xc2, 0x9e, 0x7e, 0x63, 0x16, 0x3e, 0xe2, 0x3a, 0x3f, 0xb8,
+	0x09, 0x26, 0x7b, 0x32, 0x47, 0xe4, 0x36, 0x2b, 0xab, 0xb5, 0x8d, 0xe5, 0xb5, 0xfc, 0x7b, 0x5c,
+	0x2b, 0x94, 0x61, 0x4d, 0xf6, 0xcc, 0xe5, 0x5c, 0x05, 0x63, 0x83, 0x0d, 0x59, 0x48, 0x6d, 0xe3,
+	0xa2, 0x11, 0x60, 0x8d, 0x0d, 0x36, 0xe0, 0x4d, 0x70, 0x9e, 0xe2, 0xb0, 0x4b, 0x64, 0x45, 0xb5,
+	0x8d, 0xa5, 0x21, 0xa5, 0x70, 0x25, 0x72, 0x25, 0x84, 0x2f, 0x83, 0xf1, 0xb8, 0xcf, 0xd1, 0x84,
+	0xd4, 0xa3, 0xa2, 0xfe, 0xa0, 0x9f, 0x14, 0x61, 0x09, 0x11, 0xdc, 0x06, 0x75, 0x97, 0xf8, 0x84,
+	0x13, 0x5b, 0x05, 0x39, 0x2f, 0x17, 0x35, 0x8b, 0x8b, 0xda, 0x52, 0x51, 0x08, 0x55, 0x73, 0x33,
+	0x9b, 0x08, 0xc8, 0x4f, 0x42, 0x34, 0x69, 0x0a, 0x78, 0xef, 0x24, 0x4c, 0x03, 0xf2, 0x93, 0x10,
+	0xbe, 0x09, 0x80, 0x13, 0x05, 0x31, 0x76, 0xb8, 0xe8, 0xd2, 0x94, 0x5c, 0xf2, 0x5c, 0x71, 0xc9,
+	0x76, 0xea, 0x4f, 0x56, 0xe6, 0x96, 0xc0, 0xb7, 0x40, 0xcd, 0x27, 0x98, 0x11, 0xbb, 0x4b, 0x71,
+	0xc8, 0xd1, 0xb4, 0x89, 0xb0, 0x2f, 0x04, 0xbb, 0xc2, 0x9f, 0x12, 0xfc, 0xd4, 0x24, 0x6a, 0x56,
+	0x04, 0x4a, 0x06, 0xd1, 0x31, 0x41, 0x55, 0x53, 0xcd, 0x12, 0x61, 0x49, 0x41, 0x5a, 0xb3, 0x9f,
+	0xd9, 0x44, 0x5b, 0xb0, 0x8f, 0x69, 0x80, 0x80, 0xa9, 0x2d, 0x2d, 0xe1, 0x4a, 0xdb, 0x22, 0x85,
+	0xf0, 0x01, 0x98, 0x57, 0x61, 0x9d, 0x1e, 0x71, 0x8e, 0xe3, 0xc8, 0x0b, 0x39, 0xaa, 0xc9, 0xc5,
+	0x2f, 0x18, 0x42, 0x6f, 0xa7, 0x22, 0x8d, 0x49, 0xa6, 0xf4, 0x35, 0x6b, 0xce, 0x2f, 0x0a, 0xe0,
+	0x7e, 0x52, 0x50, 0x3f, 0x76, 0x31, 0x27, 0xa8, 0x5e, 0x5a, 0xd0, 0x7d, 0x29, 0x18, 0x22, 0x6e,
+	0xe9, 0xca, 0x94, 0x13, 0xbe, 0x0d, 0xd4, 0xcb, 0xb2, 0x83, 0x68, 0x40, 0xd0, 0x8c, 0x64, 0x35,
+	0x0c, 0xac, 0x3b, 0xd1, 0x60, 0x94, 0x54, 0xf5, 0x13, 0x57, 0x96, 0x15, 0x39, 0x89, 0x3d, 0x4a,
+	0xd0, 0x6c, 0x69, 0x56, 0x3b, 0x52, 0x50, 0x92, 0x95, 0x72, 0xc2, 0x77, 0xc1, 0x0c, 0x25, 0x9c,
+	0x84, 0x62, 0x06, 0x6c, 0x31, 0xde, 0x73, 0x12, 0x77, 0x65, 0xf8, 0xc3, 0xd1, 0x92, 0x6c, 0xce,
+	0x33, 0x5e, 0x9d, 0xe6, 0xbc, 0xa2, 0x1d, 0x19, 0x50, 0x4d, 0x33, 0x9a, 0x37, 0xb5, 0x23, 0x65,
+	0xea, 0xcf, 0x60, 0x18, 0x3b, 0x47, 0x8b, 0x02, 0xf1, 0x02, 0x99, 0xd3, 0x23, 0x01, 0x96, 0x79,
+	0x5e, 0x30, 0xbd, 0xc0, 0x43, 0xe9, 0x37, 0x25, 0x59, 0x65, 0x89, 0x4b, 0x94, 0xac, 0x39, 0x3a,
+	0x3d, 0x68, 0x2a, 0x59, 0xa1, 0x4a, 0x72, 0xab, 0xb3, 0x9c, 0x17, 0xb6, 0x40, 0x4d, 0x6e, 0x92,
+	0x24, 0xc4, 0x1d, 0x9f, 0xa0, 0xbf, 0x8c, 0x5f, 0x5f, 0xab, 0xcf, 0x7b, 0x3b, 0x52, 0x90, 0x7e,
+	0x3b, 0x38, 0x35, 0xc1, 0x36, 0x90, 0x3b, 0xa9, 0xed, 0x7a, 0x4c, 0x32, 0xfe, 0x99, 0x32, 0x75,
+	0x55, 0x30, 0xda, 0x1e, 0xcb, 0x43, 0x6a, 0x38, 0xb3, 0xc1, 0x77, 0x74, 0x22, 0x8c, 0x63, 0xde,
+	0x67, 0xe8, 0xbf, 0xd2, 0x44, 0x0e, 0xa5, 0x60, 0xa8, 0xac, 0xd7, 0x55, 0x46, 0xca, 0x07, 0xef,
+	0xaa, 0x8c, 0x44, 0x07, 0x1c, 0x31, 0xfc, 0xff, 0x2a, 0xd8, 0x4b, 0x45, 0x58, 0xb2, 0x8b, 0xb7,
+	0x72, 0xd2, 0x24, 0xb5, 0xc2, 0x7a, 0xb8, 0xa3, 0xff, 0x49, 0xfa, 0x8c, 0x50, 0x1b, 0xbb, 0x2e,
+	0xfa, 0x61, 0xba, 0xac, 0xc4, 0xfb, 0x8c, 0xd0, 0x96, 0xeb, 0x16, 0x4a, 0xd4, 0x36, 0x78, 0x17,
+	0xcc, 0x67, 0x18, 0xdd, 0xbf, 0x1f, 0x15, 0xe9, 0x79, 0x33, 0xa9, 0xd0, 0x42, 0x6b, 0x16, 0x17,
+	0xcc, 0xc5, 0xb4, 0xba, 0x84, 0xa3, 0x9f, 0xce, 0x4c, 0x6b, 0x97, 0xf0, 0x91, 0xb4, 0x76, 0x09,
+	0x87, 0x5d, 0xf0, 0x4c, 0x86, 0x71, 0x7a, 0x62, 0xfb, 0xb6, 0x63, 0xcc, 0xd8, 0xa3, 0x88, 0xba,
+	0xe8, 0x67, 0x85, 0x7c, 0xc5, 0x8c, 0xdc, 0x96, 0xea, 0x03, 0x2d, 0x4e, 0xe8, 0x97, 0xb0, 0xd1,
+	0x0d, 0x1f, 0x80, 0xc5, 0x5c, 0xbe, 0x62, 0xdf, 0xb5, 0xc5, 0x9f, 0x2b, 0x7a, 0xaa, 0x62, 0x5c,
+	0x2b, 0x49, 0x5b, 0x08, 0xad, 0x28, 0x1b, 0x9b, 0x0b, 0x78, 0xd8, 0x03, 0xdf, 0x07, 0x17, 0x33,
+	0xb2, 0xda, 0xc2, 0x15, 0xfa, 0x17, 0x85, 0x7e, 0xd1, 0x8c, 0xd6, 0x7b, 0x79, 0x8e, 0x0d, 0xf1,
+	0x88, 0x0b, 0xde, 0x06, 0xb3, 0x19, 0xdc, 0xf7, 0x18, 0x47, 0xbf, 0x4e, 0x9b, 0xbe, 0xba, 0x84,
+	0xba, 0xef, 0x31, 0x5e, 0x98, 0xa3, 0xc4, 0x98, 0x92, 0x44, 0x6a, 0x8a, 0xf4, 0x5b, 0x29, 0x49,
+	0x84, 0x1e, 0x21, 0x25, 0xc6, 0xb4, 0xf5, 0x92, 0x24, 0x26, 0xf2, 0xab, 0x6a, 0x59, 0xeb, 0xc5,
+	0x9a, 0xe1, 0x89, 0xd4, 0xb6, 0x74, 0x22, 0x25, 0x46, 0x4f, 0xe4, 0xd7, 0xd5, 0xb2, 0x89, 0x14,
+	0xab, 0x0c, 0x13, 0x99, 0x99, 0x8b, 0x69, 0x89, 0x89, 0xfc, 0xe6, 0xcc, 0xb4, 0x86, 0x27, 0x52,
+	0xdb, 0xe0, 0x43, 0xb0, 0x94, 0xc3, 0xc8, 0x41, 0x89, 0x09, 0x0d, 0x3c, 0x26, 0x8f, 0x71, 0xdf,
+	0x2a, 0xe6, 0xf5, 0x12, 0xa6, 0x90, 0x1f, 0xa4, 0xea, 0x84, 0x7f, 0x19, 0x9b, 0xfd, 0x30, 0x00,
+	0xcb, 0x59, 0x2c, 0x3d, 0x3a, 0xb9, 0x60, 0xdf, 0xa9, 0x60, 0x37, 0xcc, 0xc1, 0xd4, 0x94, 0x8c,
+	0x46, 0x43, 0xb8, 0x44, 0x00, 0x3f, 0x04, 0x0b, 0x8e, 0xdf, 0x67, 0x9c, 0x50, 0x5b, 0x9f, 0x89,
+	0x6d, 0x46, 0x38, 0xfa, 0x0c, 0xe8, 0x4f, 0x20, 0x7f, 0x20, 0x5e, 0xdb, 0x56, 0xca, 0xf7, 0x94,
+	0xf0, 0x90, 0xf0, 0x91, 0x5d, 0xef, 0x82, 0x33, 0x2c, 0x81, 0x0f, 0xc1, 0xe5, 0x24, 0x82, 0x82,
+	0xd9, 0x98, 0x73, 0x2a, 0xa3, 0x7c, 0x0e, 0xf4, 0x3e, 0x68, 0x8a, 0x72, 0x47, 0xda, 0x5a, 0x9c,
+	0x53, 0x53, 0xa0, 0x45, 0xc7, 0xa0, 0x82, 0x1f, 0x00, 0xe8, 0x46, 0x8f, 0xc2, 0x2e, 0xc5, 0x2e,
+	0xb1, 0xbd, 0xf0, 0x28, 0x92, 0x61, 0xbe, 0x50, 0x61, 0xae, 0x16, 0xc3, 0xb4, 0x13, 0xe1, 0x5e,
+	0x78, 0x14, 0x99, 0x42, 0xcc, 0xbb, 0x43, 0x8a, 0xec, 0xd0, 0x3d, 0x07, 0x66, 0x76, 0x82, 0x98,
+	0x3f, 0xb6, 0x08, 0x8b, 0xa3, 0x90, 0x91, 0x95, 0xc7, 0x60, 0xf9, 0x8c, 0xed, 0x1b, 0x42, 0x30,
+	0x21, 0xaf, 0x04, 0x15, 0x79, 0x25, 0x90, 0xbf, 0xc5, 0x55, 0x21, 0xdd, 0xd5, 0xf4, 0x55, 0x21,
+	0x79, 0x86, 0x57, 0x40, 0x9d, 0x79, 0x41, 0xec, 0x13, 0x9b, 0x47, 0xc7, 0x44, 0xdd, 0x14, 0xaa,
+	0x56, 0x4d, 0xd9, 0xee, 0x09, 0x53, 0x96, 0xcb, 0x0d, 0x00, 0x47, 0x4f, 0x28, 0xb9, 0x93, 0xfc,
+	0xb8, 0x38, 0xc9, 0x27, 0xf2, 0xad, 0x5b, 0x8b, 0x4f, 0xfe, 0x68, 0x9c, 0x7b, 0x72, 0xda, 0xa8,
+	0x3c, 0x3d, 0x6d, 0x54, 0x7e, 0x3f, 0x6d, 0x54, 0xbe, 0xfc, 0xb3, 0x71, 0xae, 0x33, 0x29, 0x2f,
+	0x38, 0x9b, 0xff, 0x0f, 0x00, 0xae, 0x92, 0x8b, 0x1b, 0x82, 0x0d, 0x00, 0x00,
 }
 
 func (m *RequestHeader) Marshal() (dAtA []byte, err error) {
@@ -331,6 +395,15 @@ func (m *RequestHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Roles) > 0 {
+		for iNdEx := len(m.Roles) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Roles[iNdEx])
+			copy(dAtA[i:], m.Roles[iNdEx])
+			i = encodeVarintRaftInternal(dAtA, i, uint64(len(m.Roles[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.AuthRevision != 0 {
 		i = encodeVarintRaftInternal(dAtA, i, uint64(m.AuthRevision))
 		i--
@@ -669,6 +742,96 @@ func (m *InternalRaftRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0xa2
 	}
+	if m.SchemaDelete != nil {
+		{
+			size, err := m.SchemaDelete.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x92
+	}
+	if m.SchemaPut != nil {
+		{
+			size, err := m.SchemaPut.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x8a
+	}
+	if m.RetentionDelete != nil {
+		{
+			size, err := m.RetentionDelete.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	if m.RetentionPut != nil {
+		{
+			size, err := m.RetentionPut.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x7a
+	}
+	if m.LeaseExpire != nil {
+		{
+			size, err := m.LeaseExpire.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x72
+	}
+	if m.LeaseMove != nil {
+		{
+			size, err := m.LeaseMove.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x6a
+	}
+	if m.LeaseUpdate != nil {
+		{
+			size, err := m.LeaseUpdate.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x62
+	}
 	if m.LeaseCheckpoint != nil {
 		{
 			size, err := m.LeaseCheckpoint.MarshalToSizedBuffer(dAtA[:i])
@@ -872,6 +1035,38 @@ func (m *InternalAuthenticateRequest) MarshalToSizedBuffer(dAtA []byte) (int, er
 	return len(dAtA) - i, nil
 }
 
+func (m *LeaseExpireRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LeaseExpireRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LeaseExpireRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.ID != 0 {
+		i = encodeVarintRaftInternal(dAtA, i, uint64(m.ID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintRaftInternal(dAtA []byte, offset int, v uint64) int {
 	offset -= sovRaftInternal(v)
 	base := offset
@@ -899,6 +1094,12 @@ func (m *RequestHeader) Size() (n int) {
 	if m.AuthRevision != 0 {
 		n += 1 + sovRaftInternal(uint64(m.AuthRevision))
 	}
+	if len(m.Roles) > 0 {
+		for _, s := range m.Roles {
+			l = len(s)
+			n += 1 + l + sovRaftInternal(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -954,6 +1155,34 @@ func (m *InternalRaftRequest) Size() (n int) {
 		l = m.LeaseCheckpoint.Size()
 		n += 1 + l + sovRaftInternal(uint64(l))
 	}
+	if m.LeaseUpdate != nil {
+		l = m.LeaseUpdate.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.LeaseMove != nil {
+		l = m.LeaseMove.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.LeaseExpire != nil {
+		l = m.LeaseExpire.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.RetentionPut != nil {
+		l = m.RetentionPut.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.RetentionDelete != nil {
+		l = m.RetentionDelete.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.SchemaPut != nil {
+		l = m.SchemaPut.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.SchemaDelete != nil {
+		l = m.SchemaDelete.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
 	if m.Header != nil {
 		l = m.Header.Size()
 		n += 2 + l + sovRaftInternal(uint64(l))
@@ -1080,6 +1309,21 @@ func (m *InternalAuthenticateRequest) Size() (n int) {
 	return n
 }
 
+func (m *LeaseExpireRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
+		n += 1 + sovRaftInternal(uint64(m.ID))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func sovRaftInternal(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -1185,6 +1429,38 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Roles", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Roles = append(m.Roles, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaftInternal(dAtA[iNdEx:])
@@ -1615,6 +1891,258 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseUpdate", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LeaseUpdate == nil {
+				m.LeaseUpdate = &LeaseUpdateRequest{}
+			}
+			if err := m.LeaseUpdate.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseMove", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LeaseMove == nil {
+				m.LeaseMove = &LeaseMoveRequest{}
+			}
+			if err := m.LeaseMove.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseExpire", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LeaseExpire == nil {
+				m.LeaseExpire = &LeaseExpireRequest{}
+			}
+			if err := m.LeaseExpire.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RetentionPut", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RetentionPut == nil {
+				m.RetentionPut = &RetentionPutRequest{}
+			}
+			if err := m.RetentionPut.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RetentionDelete", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RetentionDelete == nil {
+				m.RetentionDelete = &RetentionDeleteRequest{}
+			}
+			if err := m.RetentionDelete.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SchemaPut", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SchemaPut == nil {
+				m.SchemaPut = &SchemaPutRequest{}
+			}
+			if err := m.SchemaPut.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 18:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SchemaDelete", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SchemaDelete == nil {
+				m.SchemaDelete = &SchemaDeleteRequest{}
+			}
+			if err := m.SchemaDelete.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 100:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
@@ -2591,6 +3119,76 @@ func (m *InternalAuthenticateRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *LeaseExpireRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaftInternal
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LeaseExpireRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LeaseExpireRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			m.ID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ID |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaftInternal(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipRaftInternal(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -116,10 +116,17 @@ type Event struct {
 	// its modification revision set to the revision of deletion.
 	Kv *KeyValue `protobuf:"bytes,2,opt,name=kv,proto3" json:"kv,omitempty"`
 	// prev_kv holds the key-value pair before the event happens.
-	PrevKv               *KeyValue `protobuf:"bytes,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	PrevKv *KeyValue `protobuf:"bytes,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
+	// lease_expired is set on a DELETE event if the key was deleted because its
+	// lease expired, rather than by a delete or a lease revocation. The kv of
+	// such an event holds the ID of the expired lease.
+	LeaseExpired bool `protobuf:"varint,4,opt,name=lease_expired,json=leaseExpired,proto3" json:"lease_expired,omitempty"`
+	// coalesced is set on an event of a coalescing watcher replacing older events of
+	// its key that were not sent to the watcher.
+	Coalesced            bool     `protobuf:"varint,5,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Event) Reset()         { *m = Event{} }
@@ -164,26 +171,28 @@ func init() {
 func init() { proto.RegisterFile("kv.proto", fileDescriptor_2216fe83c9c12408) }
 
 var fileDescriptor_2216fe83c9c12408 = []byte{
-	// 303 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0x41, 0x4e, 0xc2, 0x40,
-	0x14, 0x86, 0x3b, 0x14, 0x0a, 0x3e, 0x08, 0x36, 0x13, 0x12, 0x27, 0x2e, 0x26, 0x95, 0x8d, 0x18,
-	0x13, 0x4c, 0xf0, 0x06, 0xc6, 0xae, 0x70, 0x61, 0x1a, 0x74, 0x4b, 0x4a, 0x79, 0x21, 0xa4, 0x94,
-	0x69, 0x4a, 0x9d, 0xa4, 0x37, 0x71, 0xef, 0xde, 0x73, 0xb0, 0xe4, 0x08, 0x52, 0x2f, 0x62, 0xfa,
-	0xc6, 0xe2, 0xc6, 0xcd, 0xe4, 0xfd, 0xff, 0xff, 0x65, 0xe6, 0x7f, 0x03, 0x9d, 0x58, 0x8f, 0xd3,
-	0x4c, 0xe5, 0x8a, 0x3b, 0x89, 0x8e, 0xa2, 0x74, 0x71, 0x39, 0x58, 0xa9, 0x95, 0x22, 0xeb, 0xae,
-	0x9a, 0x4c, 0x3a, 0xfc, 0x64, 0xd0, 0x99, 0x62, 0xf1, 0x1a, 0x6e, 0xde, 0x90, 0xbb, 0x60, 0xc7,
-	0x58, 0x08, 0xe6, 0xb1, 0x51, 0x2f, 0xa8, 0x46, 0x7e, 0x0d, 0xe7, 0x51, 0x86, 0x61, 0x8e, 0xf3,
-	0x0c, 0xf5, 0x7a, 0xb7, 0x56, 0x5b, 0xd1, 0xf0, 0xd8, 0xc8, 0x0e, 0xfa, 0xc6, 0x0e, 0x7e, 0x5d,
-	0x7e, 0x05, 0xbd, 0x44, 0x2d, 0xff, 0x28, 0x9b, 0xa8, 0x6e, 0xa2, 0x96, 0x27, 0x44, 0x40, 0x5b,
-	0x63, 0x46, 0x69, 0x93, 0xd2, 0x5a, 0xf2, 0x01, 0xb4, 0x74, 0x55, 0x40, 0xb4, 0xe8, 0x65, 0x23,
-	0x2a, 0x77, 0x83, 0xe1, 0x0e, 0x85, 0x43, 0xb4, 0x11, 0xc3, 0x0f, 0x06, 0x2d, 0x5f, 0xe3, 0x36,
-	0xe7, 0xb7, 0xd0, 0xcc, 0x8b, 0x14, 0xa9, 0x6e, 0x7f, 0x72, 0x31, 0x36, 0x7b, 0x8e, 0x29, 0x34,
-	0xe7, 0xac, 0x48, 0x31, 0x20, 0x88, 0x7b, 0xd0, 0x88, 0x35, 0x75, 0xef, 0x4e, 0xdc, 0x1a, 0xad,
-	0x17, 0x0f, 0x1a, 0xb1, 0xe6, 0x37, 0xd0, 0x4e, 0x33, 0xd4, 0xf3, 0x58, 0x53, 0xf9, 0xff, 0x30,
-	0xa7, 0x02, 0xa6, 0x7a, 0xe8, 0xc1, 0xd9, 0xe9, 0x7e, 0xde, 0x06, 0xfb, 0xf9, 0x65, 0xe6, 0x5a,
-	0x1c, 0xc0, 0x79, 0xf4, 0x9f, 0xfc, 0x99, 0xef, 0xb2, 0x07, 0xb1, 0x3f, 0x4a, 0xeb, 0x70, 0x94,
-	0xd6, 0xbe, 0x94, 0xec, 0x50, 0x4a, 0xf6, 0x55, 0x4a, 0xf6, 0xfe, 0x2d, 0xad, 0x85, 0x43, 0xff,
-	0x7e, 0xff, 0x13, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x45, 0x92, 0x5d, 0xa1, 0x01, 0x00, 0x00,
+	// 333 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0x41, 0x6a, 0xc2, 0x40,
+	0x14, 0x86, 0x33, 0x46, 0x63, 0x7c, 0x5a, 0x1b, 0x06, 0xa1, 0xa1, 0x94, 0x90, 0xda, 0x45, 0x2d,
+	0x05, 0x0b, 0xf6, 0x06, 0xa5, 0x59, 0xd9, 0x45, 0x19, 0x6c, 0xb7, 0x12, 0x93, 0x87, 0x48, 0xd4,
+	0x09, 0x31, 0x1d, 0x9a, 0x9b, 0xf4, 0x14, 0x3d, 0x87, 0x4b, 0x8f, 0x50, 0x2d, 0xf4, 0x1c, 0x25,
+	0x6f, 0xaa, 0xae, 0xba, 0x09, 0xef, 0x7d, 0xef, 0x83, 0xfc, 0x3f, 0x03, 0x76, 0xa2, 0xfa, 0x69,
+	0x26, 0x73, 0xc9, 0xad, 0x85, 0x8a, 0xa2, 0x74, 0x72, 0xde, 0x99, 0xca, 0xa9, 0x24, 0x74, 0x57,
+	0x4e, 0xfa, 0xda, 0xfd, 0x64, 0x60, 0x0f, 0xb1, 0x78, 0x0d, 0xe7, 0x6f, 0xc8, 0x1d, 0x30, 0x13,
+	0x2c, 0x5c, 0xe6, 0xb3, 0x5e, 0x4b, 0x94, 0x23, 0xbf, 0x86, 0xd3, 0x28, 0xc3, 0x30, 0xc7, 0x71,
+	0x86, 0x6a, 0xb6, 0x9a, 0xc9, 0xa5, 0x5b, 0xf1, 0x59, 0xcf, 0x14, 0x6d, 0x8d, 0xc5, 0x1f, 0xe5,
+	0x97, 0xd0, 0x5a, 0xc8, 0xf8, 0x68, 0x99, 0x64, 0x35, 0x17, 0x32, 0x3e, 0x28, 0x2e, 0xd4, 0x15,
+	0x66, 0x74, 0xad, 0xd2, 0x75, 0xbf, 0xf2, 0x0e, 0xd4, 0x54, 0x19, 0xc0, 0xad, 0xd1, 0x9f, 0xf5,
+	0x52, 0xd2, 0x39, 0x86, 0x2b, 0x74, 0x2d, 0xb2, 0xf5, 0xd2, 0xfd, 0x61, 0x50, 0x0b, 0x14, 0x2e,
+	0x73, 0x7e, 0x0b, 0xd5, 0xbc, 0x48, 0x91, 0xe2, 0xb6, 0x07, 0x67, 0x7d, 0xdd, 0xb3, 0x4f, 0x47,
+	0xfd, 0x1d, 0x15, 0x29, 0x0a, 0x92, 0xb8, 0x0f, 0x95, 0x44, 0x51, 0xf6, 0xe6, 0xc0, 0xd9, 0xab,
+	0xfb, 0xe2, 0xa2, 0x92, 0x28, 0x7e, 0x03, 0xf5, 0x34, 0x43, 0x35, 0x4e, 0x94, 0x6b, 0xfe, 0xa3,
+	0x59, 0xa5, 0x30, 0x54, 0xfc, 0x0a, 0x4e, 0x28, 0xcc, 0x18, 0xdf, 0xd3, 0x59, 0x86, 0x31, 0xf5,
+	0xb1, 0x45, 0x8b, 0x60, 0xa0, 0x19, 0xbf, 0x80, 0x46, 0x24, 0xc3, 0x39, 0xae, 0x22, 0x8c, 0xa9,
+	0x98, 0x2d, 0x8e, 0xa0, 0xeb, 0x43, 0xe3, 0x10, 0x91, 0xd7, 0xc1, 0x7c, 0x7e, 0x19, 0x39, 0x06,
+	0x07, 0xb0, 0x1e, 0x83, 0xa7, 0x60, 0x14, 0x38, 0xec, 0xc1, 0x5d, 0x6f, 0x3d, 0x63, 0xb3, 0xf5,
+	0x8c, 0xf5, 0xce, 0x63, 0x9b, 0x9d, 0xc7, 0xbe, 0x76, 0x1e, 0xfb, 0xf8, 0xf6, 0x8c, 0x89, 0x45,
+	0x4f, 0x77, 0xff, 0x3b, 0x00, 0x12, 0xd7, 0x34, 0xb1, 0xe4, 0x01, 0x00, 0x00,
 }
 
 func (m *KeyValue) Marshal() (dAtA []byte, err error) {
@@ -271,6 +280,26 @@ func (m *Event) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Coalesced {
+		i--
+		if m.Coalesced {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.LeaseExpired {
+		i--
+		if m.LeaseExpired {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.PrevKv != nil {
 		{
 			size, err := m.PrevKv.MarshalToSizedBuffer(dAtA[:i])
@@ -363,6 +392,12 @@ func (m *Event) Size() (n int) {
 		l = m.PrevKv.Size()
 		n += 1 + l + sovKv(uint64(l))
 	}
+	if m.LeaseExpired {
+		n += 2
+	}
+	if m.Coalesced {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -690,6 +725,46 @@ func (m *Event) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseExpired", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.LeaseExpired = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Coalesced", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Coalesced = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipKv(dAtA[iNdEx:])
@@ -33,10 +33,21 @@ var (
 	ErrGRPCFutureRev               = status.New(codes.OutOfRange, "etcdserver: mvcc: required revision is a future revision").Err()
 	ErrGRPCNoSpace                 = status.New(codes.ResourceExhausted, "etcdserver: mvcc: database space exceeded").Err()
 
+	ErrGRPCSecondaryIndexNotFound = status.New(codes.InvalidArgument, "etcdserver: mvcc: no secondary index on the requested field covers the requested range").Err()
+
+	ErrGRPCRetentionRuleNotFound = status.New(codes.NotFound, "etcdserver: mvcc: retention rule not found").Err()
+	ErrGRPCInvalidRetentionRule  = status.New(codes.InvalidArgument, "etcdserver: mvcc: invalid retention rule").Err()
+
+	ErrGRPCValueSchemaNotFound  = status.New(codes.NotFound, "etcdserver: value schema not found").Err()
+	ErrGRPCInvalidValueSchema   = status.New(codes.InvalidArgument, "etcdserver: invalid value schema").Err()
+	ErrGRPCValueSchemaViolation = status.New(codes.InvalidArgument, "etcdserver: value does not match the schema of its key prefix").Err()
+
 	ErrGRPCLeaseNotFound    = status.New(codes.NotFound, "etcdserver: requested lease not found").Err()
 	ErrGRPCLeaseExist       = status.New(codes.FailedPrecondition, "etcdserver: lease already exists").Err()
 	ErrGRPCLeaseTTLTooLarge = status.New(codes.OutOfRange, "etcdserver: too large lease TTL").Err()
 
+	ErrGRPCLeaseWatchCanceled = status.New(codes.Aborted, "etcdserver: lease watch canceled, events may have been lost").Err()
+
 	ErrGRPCWatchCanceled = status.New(codes.Canceled, "etcdserver: watch canceled").Err()
 
 	ErrGRPCMemberExist            = status.New(codes.FailedPrecondition, "etcdserver: member ID already exist").Err()
@@ -61,6 +72,7 @@ var (
 	ErrGRPCRoleEmpty            = status.New(codes.InvalidArgument, "etcdserver: role name is empty").Err()
 	ErrGRPCAuthFailed           = status.New(codes.InvalidArgument, "etcdserver: authentication failed, invalid user ID or password").Err()
 	ErrGRPCPermissionNotGiven   = status.New(codes.InvalidArgument, "etcdserver: permission not given").Err()
+	ErrGRPCInvalidPermission    = status.New(codes.InvalidArgument, "etcdserver: invalid permission").Err()
 	ErrGRPCPermissionDenied     = status.New(codes.PermissionDenied, "etcdserver: permission denied").Err()
 	ErrGRPCRoleNotGranted       = status.New(codes.FailedPrecondition, "etcdserver: role is not granted to the user").Err()
 	ErrGRPCPermissionNotGranted = status.New(codes.FailedPrecondition, "etcdserver: permission is not granted to the role").Err()
@@ -88,6 +100,9 @@ var (
 	ErrGRPCDowngradeInProcess            = status.New(codes.FailedPrecondition, "etcdserver: cluster has a downgrade job in progress").Err()
 	ErrGRPCNoInflightDowngrade           = status.New(codes.FailedPrecondition, "etcdserver: no inflight downgrade job").Err()
 
+	ErrGRPCConfigReloadUnsupported = status.New(codes.FailedPrecondition, "etcdserver: member cannot reload its configuration without a configuration file").Err()
+	ErrGRPCStaleRead               = status.New(codes.Unavailable, "etcdserver: member is staler than requested").Err()
+
 	ErrGRPCCanceled         = status.New(codes.Canceled, "etcdserver: request canceled").Err()
 	ErrGRPCDeadlineExceeded = status.New(codes.DeadlineExceeded, "etcdserver: context deadline exceeded").Err()
 
@@ -104,10 +119,21 @@ var (
 		ErrorDesc(ErrGRPCFutureRev):         ErrGRPCFutureRev,
 		ErrorDesc(ErrGRPCNoSpace):           ErrGRPCNoSpace,
 
+		ErrorDesc(ErrGRPCSecondaryIndexNotFound): ErrGRPCSecondaryIndexNotFound,
+
+		ErrorDesc(ErrGRPCRetentionRuleNotFound): ErrGRPCRetentionRuleNotFound,
+		ErrorDesc(ErrGRPCInvalidRetentionRule):  ErrGRPCInvalidRetentionRule,
+
+		ErrorDesc(ErrGRPCValueSchemaNotFound):  ErrGRPCValueSchemaNotFound,
+		ErrorDesc(ErrGRPCInvalidValueSchema):   ErrGRPCInvalidValueSchema,
+		ErrorDesc(ErrGRPCValueSchemaViolation): ErrGRPCValueSchemaViolation,
+
 		ErrorDesc(ErrGRPCLeaseNotFound):    ErrGRPCLeaseNotFound,
 		ErrorDesc(ErrGRPCLeaseExist):       ErrGRPCLeaseExist,
 		ErrorDesc(ErrGRPCLeaseTTLTooLarge): ErrGRPCLeaseTTLTooLarge,
 
+		ErrorDesc(ErrGRPCLeaseWatchCanceled): ErrGRPCLeaseWatchCanceled,
+
 		ErrorDesc(ErrGRPCMemberExist):            ErrGRPCMemberExist,
 		ErrorDesc(ErrGRPCPeerURLExist):           ErrGRPCPeerURLExist,
 		ErrorDesc(ErrGRPCMemberNotEnoughStarted): ErrGRPCMemberNotEnoughStarted,
@@ -130,6 +156,7 @@ var (
 		ErrorDesc(ErrGRPCRoleEmpty):            ErrGRPCRoleEmpty,
 		ErrorDesc(ErrGRPCAuthFailed):           ErrGRPCAuthFailed,
 		ErrorDesc(ErrGRPCPermissionDenied):     ErrGRPCPermissionDenied,
+		ErrorDesc(ErrGRPCInvalidPermission):    ErrGRPCInvalidPermission,
 		ErrorDesc(ErrGRPCRoleNotGranted):       ErrGRPCRoleNotGranted,
 		ErrorDesc(ErrGRPCPermissionNotGranted): ErrGRPCPermissionNotGranted,
 		ErrorDesc(ErrGRPCAuthNotEnabled):       ErrGRPCAuthNotEnabled,
@@ -155,6 +182,9 @@ var (
 		ErrorDesc(ErrGRPCInvalidDowngradeTargetVersion): ErrGRPCInvalidDowngradeTargetVersion,
 		ErrorDesc(ErrGRPCDowngradeInProcess):            ErrGRPCDowngradeInProcess,
 		ErrorDesc(ErrGRPCNoInflightDowngrade):           ErrGRPCNoInflightDowngrade,
+
+		ErrorDesc(ErrGRPCConfigReloadUnsupported): ErrGRPCConfigReloadUnsupported,
+		ErrorDesc(ErrGRPCStaleRead):               ErrGRPCStaleRead,
 	}
 )
 
@@ -171,10 +201,21 @@ var (
 	ErrFutureRev         = Error(ErrGRPCFutureRev)
 	ErrNoSpace           = Error(ErrGRPCNoSpace)
 
+	ErrSecondaryIndexNotFound = Error(ErrGRPCSecondaryIndexNotFound)
+
+	ErrRetentionRuleNotFound = Error(ErrGRPCRetentionRuleNotFound)
+	ErrInvalidRetentionRule  = Error(ErrGRPCInvalidRetentionRule)
+
+	ErrValueSchemaNotFound  = Error(ErrGRPCValueSchemaNotFound)
+	ErrInvalidValueSchema   = Error(ErrGRPCInvalidValueSchema)
+	ErrValueSchemaViolation = Error(ErrGRPCValueSchemaViolation)
+
 	ErrLeaseNotFound    = Error(ErrGRPCLeaseNotFound)
 	ErrLeaseExist       = Error(ErrGRPCLeaseExist)
 	ErrLeaseTTLTooLarge = Error(ErrGRPCLeaseTTLTooLarge)
 
+	ErrLeaseWatchCanceled = Error(ErrGRPCLeaseWatchCanceled)
+
 	ErrMemberExist            = Error(ErrGRPCMemberExist)
 	ErrPeerURLExist           = Error(ErrGRPCPeerURLExist)
 	ErrMemberNotEnoughStarted = Error(ErrGRPCMemberNotEnoughStarted)
@@ -197,6 +238,7 @@ var (
 	ErrRoleEmpty            = Error(ErrGRPCRoleEmpty)
 	ErrAuthFailed           = Error(ErrGRPCAuthFailed)
 	ErrPermissionDenied     = Error(ErrGRPCPermissionDenied)
+	ErrInvalidPermission    = Error(ErrGRPCInvalidPermission)
 	ErrRoleNotGranted       = Error(ErrGRPCRoleNotGranted)
 	ErrPermissionNotGranted = Error(ErrGRPCPermissionNotGranted)
 	ErrAuthNotEnabled       = Error(ErrGRPCAuthNotEnabled)
@@ -221,6 +263,9 @@ var (
 	ErrInvalidDowngradeTargetVersion = Error(ErrGRPCInvalidDowngradeTargetVersion)
 	ErrDowngradeInProcess            = Error(ErrGRPCDowngradeInProcess)
 	ErrNoInflightDowngrade           = Error(ErrGRPCNoInflightDowngrade)
+
+	ErrConfigReloadUnsupported = Error(ErrGRPCConfigReloadUnsupported)
+	ErrStaleRead               = Error(ErrGRPCStaleRead)
 )
 
 // EtcdError defines gRPC server errors.
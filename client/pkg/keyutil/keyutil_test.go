@@ -0,0 +1,42 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyutil
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrefixRangeEnd(t *testing.T) {
+	tests := []struct {
+		prefix []byte
+		wend   []byte
+	}{
+		{[]byte("foo"), []byte("fop")},
+		{[]byte("foo\xff"), []byte("fop")},
+		{[]byte{0x01, 0xff, 0xff}, []byte{0x02}},
+		{[]byte{0xff, 0xff}, []byte{0}},
+		{[]byte{}, []byte{0}},
+	}
+	for i, tt := range tests {
+		prefix := append([]byte{}, tt.prefix...)
+		if end := PrefixRangeEnd(prefix); !bytes.Equal(end, tt.wend) {
+			t.Errorf("#%d: PrefixRangeEnd(%q) = %q, want %q", i, tt.prefix, end, tt.wend)
+		}
+		if !bytes.Equal(prefix, tt.prefix) {
+			t.Errorf("#%d: prefix changed to %q", i, prefix)
+		}
+	}
+}
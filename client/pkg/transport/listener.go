@@ -152,6 +152,11 @@ type TLSInfo struct {
 	// Note that cipher suites are prioritized in the given order.
 	CipherSuites []uint16
 
+	// GetConfigForClient is optionally set as the GetConfigForClient of the
+	// server configurations, to change the configuration of the new connections
+	// while serving. A nil configuration keeps the original one.
+	GetConfigForClient func(*tls.ClientHelloInfo) (*tls.Config, error)
+
 	selfCert bool
 
 	// parseFunc exists to simplify testing. Typically, parseFunc
@@ -497,6 +502,8 @@ func (info TLSInfo) ServerConfig() (*tls.Config, error) {
 	// "h2" NextProtos is necessary for enabling HTTP2 for go's HTTP server
 	cfg.NextProtos = []string{"h2"}
 
+	cfg.GetConfigForClient = info.GetConfigForClient
+
 	// go1.13 enables TLS 1.3 by default
 	// and in TLS 1.3, cipher suites are not configurable
 	// setting Max TLS version to TLS 1.2 for go 1.13
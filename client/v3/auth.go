@@ -43,8 +43,9 @@ type (
 	AuthUserListResponse             pb.AuthUserListResponse
 	AuthRoleListResponse             pb.AuthRoleListResponse
 
-	PermissionType authpb.Permission_Type
-	Permission     authpb.Permission
+	PermissionType      authpb.Permission_Type
+	PermissionOperation authpb.Permission_Operation
+	Permission          authpb.Permission
 )
 
 const (
@@ -53,6 +54,15 @@ const (
 	PermReadWrite = authpb.READWRITE
 )
 
+const (
+	PermOpRange  = authpb.RANGE
+	PermOpPut    = authpb.PUT
+	PermOpDelete = authpb.DELETE
+	PermOpLease  = authpb.LEASE
+	PermOpWatch  = authpb.WATCH
+	PermOpTxn    = authpb.TXN
+)
+
 type UserAddOptions authpb.UserAddOptions
 
 type Auth interface {
@@ -98,6 +108,10 @@ type Auth interface {
 	// RoleGrantPermission grants a permission to a role.
 	RoleGrantPermission(ctx context.Context, name string, key, rangeEnd string, permType PermissionType) (*AuthRoleGrantPermissionResponse, error)
 
+	// RoleGrantKeyPermission grants a permission to a role. Unlike RoleGrantPermission,
+	// the permission may be a key pattern, a deny rule or limited to some operations.
+	RoleGrantKeyPermission(ctx context.Context, role string, perm *Permission) (*AuthRoleGrantPermissionResponse, error)
+
 	// RoleGet gets a detailed information of a role.
 	RoleGet(ctx context.Context, role string) (*AuthRoleGetResponse, error)
 
@@ -107,6 +121,10 @@ type Auth interface {
 	// RoleRevokePermission revokes a permission from a role.
 	RoleRevokePermission(ctx context.Context, role string, key, rangeEnd string) (*AuthRoleRevokePermissionResponse, error)
 
+	// RoleRevokeKeyPermission revokes the permission with the key, range end,
+	// pattern and deny rule of perm from a role.
+	RoleRevokeKeyPermission(ctx context.Context, role string, perm *Permission) (*AuthRoleRevokePermissionResponse, error)
+
 	// RoleDelete deletes a role.
 	RoleDelete(ctx context.Context, role string) (*AuthRoleDeleteResponse, error)
 }
@@ -207,6 +225,11 @@ func (auth *authClient) RoleGrantPermission(ctx context.Context, name string, ke
 	return (*AuthRoleGrantPermissionResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) RoleGrantKeyPermission(ctx context.Context, role string, perm *Permission) (*AuthRoleGrantPermissionResponse, error) {
+	resp, err := auth.remote.RoleGrantPermission(ctx, &pb.AuthRoleGrantPermissionRequest{Name: role, Perm: (*authpb.Permission)(perm)}, auth.callOpts...)
+	return (*AuthRoleGrantPermissionResponse)(resp), toErr(ctx, err)
+}
+
 func (auth *authClient) RoleGet(ctx context.Context, role string) (*AuthRoleGetResponse, error) {
 	resp, err := auth.remote.RoleGet(ctx, &pb.AuthRoleGetRequest{Role: role}, auth.callOpts...)
 	return (*AuthRoleGetResponse)(resp), toErr(ctx, err)
@@ -222,6 +245,18 @@ func (auth *authClient) RoleRevokePermission(ctx context.Context, role string, k
 	return (*AuthRoleRevokePermissionResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) RoleRevokeKeyPermission(ctx context.Context, role string, perm *Permission) (*AuthRoleRevokePermissionResponse, error) {
+	req := &pb.AuthRoleRevokePermissionRequest{
+		Role:     role,
+		Key:      perm.Key,
+		RangeEnd: perm.RangeEnd,
+		Pattern:  perm.Pattern,
+		Deny:     perm.Deny,
+	}
+	resp, err := auth.remote.RoleRevokePermission(ctx, req, auth.callOpts...)
+	return (*AuthRoleRevokePermissionResponse)(resp), toErr(ctx, err)
+}
+
 func (auth *authClient) RoleDelete(ctx context.Context, role string) (*AuthRoleDeleteResponse, error) {
 	resp, err := auth.remote.RoleDelete(ctx, &pb.AuthRoleDeleteRequest{Role: role}, auth.callOpts...)
 	return (*AuthRoleDeleteResponse)(resp), toErr(ctx, err)
@@ -234,3 +269,11 @@ func StrToPermissionType(s string) (PermissionType, error) {
 	}
 	return PermissionType(-1), fmt.Errorf("invalid permission type: %s", s)
 }
+
+func StrToPermissionOperation(s string) (PermissionOperation, error) {
+	val, ok := authpb.Permission_Operation_value[strings.ToUpper(s)]
+	if ok {
+		return PermissionOperation(val), nil
+	}
+	return PermissionOperation(-1), fmt.Errorf("invalid permission operation: %s", s)
+}
@@ -178,16 +178,25 @@ func (c *Client) SetEndpoints(eps ...string) {
 	c.resolver.SetEndpoints(eps)
 }
 
+// SetLearnerEndpoints sets which of the client's endpoints are the ones of learner members.
+func (c *Client) SetLearnerEndpoints(eps ...string) {
+	c.resolver.SetLearnerEndpoints(eps)
+}
+
 // Sync synchronizes client's endpoints with the known endpoints from the etcd membership.
 func (c *Client) Sync(ctx context.Context) error {
 	mresp, err := c.MemberList(ctx)
 	if err != nil {
 		return err
 	}
-	var eps []string
+	var eps, learnerEps []string
 	for _, m := range mresp.Members {
 		eps = append(eps, m.ClientURLs...)
+		if m.IsLearner {
+			learnerEps = append(learnerEps, m.ClientURLs...)
+		}
 	}
+	c.SetLearnerEndpoints(learnerEps...)
 	c.SetEndpoints(eps...)
 	return nil
 }
@@ -399,13 +408,21 @@ func newClient(cfg *Config) (*Client, error) {
 		client.callOpts = callOpts
 	}
 
-	client.resolver = resolver.New(cfg.Endpoints...)
+	eps := cfg.Endpoints
+	for _, lep := range cfg.LearnerEndpoints {
+		if !containsString(eps, lep) {
+			eps = append(eps[:len(eps):len(eps)], lep)
+		}
+	}
+	client.resolver = resolver.New(eps...)
+	client.resolver.SetLearnerEndpoints(cfg.LearnerEndpoints)
+	client.resolver.SetPreferLearnerReads(cfg.PreferLearnerReads)
 
-	if len(cfg.Endpoints) < 1 {
+	if len(eps) < 1 {
 		client.cancel()
 		return nil, fmt.Errorf("at least one Endpoint is required in client config")
 	}
-	client.SetEndpoints(cfg.Endpoints...)
+	client.SetEndpoints(eps...)
 
 	// Use a provided endpoint target so that for https:// without any tls config given, then
 	// grpc will assume the certificate server name is the endpoint host.
@@ -608,3 +625,12 @@ func IsConnCanceled(err error) bool {
 	// <= gRPC v1.7.x returns 'errors.New("grpc: the client connection is closing")'
 	return strings.Contains(err.Error(), "grpc: the client connection is closing")
 }
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
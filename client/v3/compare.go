@@ -16,6 +16,7 @@ package clientv3
 
 import (
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
 )
 
 type CompareTarget int
@@ -115,7 +116,7 @@ func (cmp Cmp) WithRange(end string) Cmp {
 
 // WithPrefix sets the comparison to scan all keys prefixed by the key.
 func (cmp Cmp) WithPrefix() Cmp {
-	cmp.RangeEnd = getPrefix(cmp.Key)
+	cmp.RangeEnd = keyutil.PrefixRangeEnd(cmp.Key)
 	return cmp
 }
 
@@ -0,0 +1,201 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	v3 "go.etcd.io/etcd/client/v3"
+)
+
+var (
+	// ErrBarrierHeld is returned by Hold when another session already holds the barrier.
+	ErrBarrierHeld = errors.New("barrier: held by another session")
+	// ErrTooManyClients is returned by Enter when more than the expected
+	// number of sessions enter a DoubleBarrier.
+	ErrTooManyClients = errors.New("barrier: too many clients")
+)
+
+// Barrier blocks processes on Wait until the session holding the barrier
+// releases it. The barrier key is bound to the holder's session lease, so the
+// barrier is released automatically if the holder dies.
+type Barrier struct {
+	s   *Session
+	key string
+}
+
+// NewBarrier creates a Barrier on the given key.
+func NewBarrier(s *Session, key string) *Barrier {
+	return &Barrier{s: s, key: key}
+}
+
+// Hold creates the barrier key, causing processes to block on Wait.
+// It returns ErrBarrierHeld if another session already holds the barrier.
+func (b *Barrier) Hold(ctx context.Context) error {
+	client := b.s.Client()
+	cmp := v3.Compare(v3.CreateRevision(b.key), "=", 0)
+	put := v3.OpPut(b.key, "", v3.WithLease(b.s.Lease()))
+	resp, err := client.Txn(ctx).If(cmp).Then(put).Else(v3.OpGet(b.key)).Commit()
+	if err != nil {
+		return err
+	}
+	if resp.Succeeded {
+		return nil
+	}
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) != 0 && kvs[0].Lease == int64(b.s.Lease()) {
+		// already held by this session
+		return nil
+	}
+	return ErrBarrierHeld
+}
+
+// Release deletes the barrier key to unblock all waiting processes.
+func (b *Barrier) Release(ctx context.Context) error {
+	_, err := b.s.Client().Delete(ctx, b.key)
+	return err
+}
+
+// Wait blocks until the barrier key is deleted or the context is canceled.
+// If there is no key, Wait assumes Release has already been called and
+// returns immediately.
+func (b *Barrier) Wait(ctx context.Context) error {
+	client := b.s.Client()
+	resp, err := client.Get(ctx, b.key, v3.WithFirstKey()...)
+	if err != nil {
+		return err
+	}
+	if len(resp.Kvs) == 0 {
+		// key already removed
+		return nil
+	}
+	return waitDelete(ctx, client, b.key, resp.Header.Revision+1)
+}
+
+// DoubleBarrier blocks processes on Enter until an expected count enters, then
+// blocks again on Leave until all processes have left. Each process is
+// represented by a key bound to its session lease, so a process that dies
+// does not block the others on Leave forever.
+type DoubleBarrier struct {
+	s     *Session
+	key   string
+	count int
+
+	myKey string
+	myRev int64
+}
+
+// NewDoubleBarrier creates a DoubleBarrier on the given key for count processes.
+func NewDoubleBarrier(s *Session, key string, count int) *DoubleBarrier {
+	return &DoubleBarrier{s: s, key: key, count: count, myRev: -1}
+}
+
+func (b *DoubleBarrier) waiters() string { return b.key + "/waiters/" }
+func (b *DoubleBarrier) ready() string   { return b.key + "/ready" }
+
+// Enter waits for "count" processes to enter the barrier then returns.
+func (b *DoubleBarrier) Enter(ctx context.Context) error {
+	client := b.s.Client()
+	b.myKey = fmt.Sprintf("%s%x", b.waiters(), b.s.Lease())
+	rev, resp, err := acquireKey(ctx, b.s, b.myKey, v3.OpGet(b.waiters(), v3.WithPrefix(), v3.WithKeysOnly()))
+	if err != nil {
+		return err
+	}
+	b.myRev = rev
+
+	n := len(resp.Responses[1].GetResponseRange().Kvs)
+	if n > b.count {
+		if _, err = client.Delete(ctx, b.myKey); err != nil {
+			return err
+		}
+		b.myKey, b.myRev = "\x00", -1
+		return ErrTooManyClients
+	}
+
+	if n == b.count {
+		// unblock waiters
+		_, err = client.Put(ctx, b.ready(), "", v3.WithLease(b.s.Lease()))
+		return err
+	}
+
+	err = waitEvent(ctx, client, b.ready(), rev, mvccpb.PUT)
+	if err != nil {
+		b.leave(client.Ctx())
+	}
+	return err
+}
+
+// Leave waits for "count" processes to leave the barrier then returns.
+func (b *DoubleBarrier) Leave(ctx context.Context) error {
+	client := b.s.Client()
+	for {
+		resp, err := client.Get(ctx, b.waiters(), v3.WithPrefix(), v3.WithKeysOnly())
+		if err != nil {
+			return err
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+
+		lowest, highest := resp.Kvs[0], resp.Kvs[0]
+		for _, k := range resp.Kvs {
+			if k.ModRevision < lowest.ModRevision {
+				lowest = k
+			}
+			if k.ModRevision > highest.ModRevision {
+				highest = k
+			}
+		}
+		isLowest := string(lowest.Key) == b.myKey
+
+		if len(resp.Kvs) == 1 {
+			// this is the only node in the barrier; finish up
+			if _, err = client.Delete(ctx, b.ready()); err != nil {
+				return err
+			}
+			return b.leave(ctx)
+		}
+
+		// lowest process in node => wait on highest process
+		if isLowest {
+			if err = waitDelete(ctx, client, string(highest.Key), highest.ModRevision); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// delete self and wait on lowest process
+		if err = b.leave(ctx); err != nil {
+			return err
+		}
+		if err = waitDelete(ctx, client, string(lowest.Key), lowest.ModRevision); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *DoubleBarrier) leave(ctx context.Context) error {
+	if b.myRev == -1 {
+		return nil
+	}
+	if _, err := b.s.Client().Delete(ctx, b.myKey); err != nil {
+		return err
+	}
+	b.myKey, b.myRev = "\x00", -1
+	return nil
+}
@@ -17,6 +17,7 @@ package concurrency
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
@@ -24,14 +25,21 @@ import (
 )
 
 func waitDelete(ctx context.Context, client *v3.Client, key string, rev int64) error {
+	return waitEvent(ctx, client, key, rev, mvccpb.DELETE)
+}
+
+// waitEvent waits until an event of the given type happens on the key at or
+// after the given revision. Extra watch options such as v3.WithPrefix widen
+// the set of keys being waited on.
+func waitEvent(ctx context.Context, client *v3.Client, key string, rev int64, typ mvccpb.Event_EventType, opts ...v3.OpOption) error {
 	cctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	var wr v3.WatchResponse
-	wch := client.Watch(cctx, key, v3.WithRev(rev))
+	wch := client.Watch(cctx, key, append(opts, v3.WithRev(rev))...)
 	for wr = range wch {
 		for _, ev := range wr.Events {
-			if ev.Type == mvccpb.DELETE {
+			if ev.Type == typ {
 				return nil
 			}
 		}
@@ -42,15 +50,21 @@ func waitDelete(ctx context.Context, client *v3.Client, key string, rev int64) e
 	if err := ctx.Err(); err != nil {
 		return err
 	}
-	return fmt.Errorf("lost watcher waiting for delete")
+	return fmt.Errorf("lost watcher waiting for %s", strings.ToLower(typ.String()))
 }
 
 // waitDeletes efficiently waits until all keys matching the prefix and no greater
 // than the create revision.
 func waitDeletes(ctx context.Context, client *v3.Client, pfx string, maxCreateRev int64) (*pb.ResponseHeader, error) {
-	getOpts := append(v3.WithLastCreate(), v3.WithMaxCreateRev(maxCreateRev))
+	return waitDeletesRange(ctx, client, pfx, v3.GetPrefixRangeEnd(pfx), maxCreateRev)
+}
+
+// waitDeletesRange waits until all keys in the range [key, end) with a create
+// revision no greater than maxCreateRev are deleted.
+func waitDeletesRange(ctx context.Context, client *v3.Client, key, end string, maxCreateRev int64) (*pb.ResponseHeader, error) {
+	getOpts := append(v3.WithLastCreate(), v3.WithRange(end), v3.WithMaxCreateRev(maxCreateRev))
 	for {
-		resp, err := client.Get(ctx, pfx, getOpts...)
+		resp, err := client.Get(ctx, key, getOpts...)
 		if err != nil {
 			return nil, err
 		}
@@ -63,3 +77,41 @@ func waitDeletes(ctx context.Context, client *v3.Client, pfx string, maxCreateRe
 		}
 	}
 }
+
+// waitHolders waits until fewer than n keys matching the prefix have a create
+// revision no greater than maxCreateRev.
+func waitHolders(ctx context.Context, client *v3.Client, pfx string, maxCreateRev int64, n int) (*pb.ResponseHeader, error) {
+	getOpts := []v3.OpOption{v3.WithPrefix(), v3.WithKeysOnly(), v3.WithMaxCreateRev(maxCreateRev)}
+	for {
+		resp, err := client.Get(ctx, pfx, getOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Kvs) < n {
+			return resp.Header, nil
+		}
+		// any deletion under the prefix may have freed a slot; recheck afterwards
+		if err = waitEvent(ctx, client, pfx, resp.Header.Revision+1, mvccpb.DELETE, v3.WithPrefix()); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// acquireKey creates the key under the session lease unless it already exists
+// and returns its create revision together with the result of the extra ops,
+// which are evaluated in the same transaction.
+func acquireKey(ctx context.Context, s *Session, key string, ops ...v3.Op) (int64, *v3.TxnResponse, error) {
+	client := s.Client()
+	cmp := v3.Compare(v3.CreateRevision(key), "=", 0)
+	put := v3.OpPut(key, "", v3.WithLease(s.Lease()))
+	get := v3.OpGet(key)
+	resp, err := client.Txn(ctx).If(cmp).Then(append([]v3.Op{put}, ops...)...).Else(append([]v3.Op{get}, ops...)...).Commit()
+	if err != nil {
+		return 0, nil, err
+	}
+	rev := resp.Header.Revision
+	if !resp.Succeeded {
+		rev = resp.Responses[0].GetResponseRange().Kvs[0].CreateRevision
+	}
+	return rev, resp, nil
+}
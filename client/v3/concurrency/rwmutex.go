@@ -0,0 +1,169 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"fmt"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	v3 "go.etcd.io/etcd/client/v3"
+)
+
+// readerPrefix is the sub-prefix holding reader keys. Writer keys are lease
+// IDs in hex and therefore always sort before it.
+const readerPrefix = "read/"
+
+// RWMutex is a reader/writer mutual exclusion lock backed by etcd. The lock
+// can be held by an arbitrary number of readers or a single writer. Lockers
+// are served in the order in which they arrived, so a pending writer blocks
+// later readers and writers cannot be starved.
+//
+// The writer side uses the same keys as a Mutex on the same prefix, so a
+// Mutex and the write lock of an RWMutex exclude each other.
+type RWMutex struct {
+	s *Session
+	w *Mutex
+
+	pfx   string
+	rKey  string
+	rRev  int64
+	rHdr  *pb.ResponseHeader
+	wpEnd string
+}
+
+// NewRWMutex creates a RWMutex on the given prefix.
+func NewRWMutex(s *Session, pfx string) *RWMutex {
+	w := NewMutex(s, pfx)
+	return &RWMutex{
+		s:     s,
+		w:     w,
+		pfx:   w.pfx,
+		rKey:  "\x00",
+		rRev:  -1,
+		wpEnd: w.pfx + readerPrefix,
+	}
+}
+
+// TryRLock locks rw for reading if no writer holds or waits for the lock.
+// Otherwise it cleans up its reader entry and returns ErrLocked.
+func (rw *RWMutex) TryRLock(ctx context.Context) error {
+	blocked, hdr, err := rw.tryAcquireRead(ctx)
+	if err != nil {
+		return err
+	}
+	if !blocked {
+		rw.rHdr = hdr
+		return nil
+	}
+	if _, err := rw.s.Client().Delete(ctx, rw.rKey); err != nil {
+		return err
+	}
+	rw.rKey = "\x00"
+	rw.rRev = -1
+	return ErrLocked
+}
+
+// RLock locks rw for reading, waiting for all writers that arrived earlier to
+// release the lock. If the context is canceled while waiting, the reader
+// entry is cleaned up.
+func (rw *RWMutex) RLock(ctx context.Context) error {
+	blocked, hdr, err := rw.tryAcquireRead(ctx)
+	if err != nil {
+		return err
+	}
+	if !blocked {
+		rw.rHdr = hdr
+		return nil
+	}
+	client := rw.s.Client()
+	// wait for deletion of writers prior to the reader key
+	_, werr := waitDeletesRange(ctx, client, rw.pfx, rw.wpEnd, rw.rRev-1)
+	if werr != nil {
+		rw.RUnlock(client.Ctx())
+		return werr
+	}
+
+	// make sure the session is not expired, and the reader key still exists.
+	gresp, werr := client.Get(ctx, rw.rKey)
+	if werr != nil {
+		rw.RUnlock(client.Ctx())
+		return werr
+	}
+	if len(gresp.Kvs) == 0 { // is the session key lost?
+		return ErrSessionExpired
+	}
+	rw.rHdr = gresp.Header
+	return nil
+}
+
+// tryAcquireRead registers the reader key and reports whether an earlier
+// writer blocks it.
+func (rw *RWMutex) tryAcquireRead(ctx context.Context) (bool, *pb.ResponseHeader, error) {
+	rw.rKey = fmt.Sprintf("%s%s%x", rw.pfx, readerPrefix, rw.s.Lease())
+	// fetch the earliest writer to complete the uncontended path with only one RPC
+	getWriter := v3.OpGet(rw.pfx, append(v3.WithFirstCreate(), v3.WithRange(rw.wpEnd))...)
+	rev, resp, err := acquireKey(ctx, rw.s, rw.rKey, getWriter)
+	if err != nil {
+		return false, nil, err
+	}
+	rw.rRev = rev
+	// a writer created after the reader key does not block it
+	writer := resp.Responses[1].GetResponseRange().Kvs
+	return len(writer) != 0 && writer[0].CreateRevision < rev, resp.Header, nil
+}
+
+// RUnlock releases the read lock held by the session.
+func (rw *RWMutex) RUnlock(ctx context.Context) error {
+	if _, err := rw.s.Client().Delete(ctx, rw.rKey); err != nil {
+		return err
+	}
+	rw.rKey = "\x00"
+	rw.rRev = -1
+	return nil
+}
+
+// TryLock locks rw for writing if no reader or writer holds or waits for
+// the lock. Otherwise it returns ErrLocked.
+func (rw *RWMutex) TryLock(ctx context.Context) error { return rw.w.TryLock(ctx) }
+
+// Lock locks rw for writing, waiting for all readers and writers that arrived
+// earlier to release the lock.
+func (rw *RWMutex) Lock(ctx context.Context) error { return rw.w.Lock(ctx) }
+
+// Unlock releases the write lock held by the session.
+func (rw *RWMutex) Unlock(ctx context.Context) error { return rw.w.Unlock(ctx) }
+
+// RKey returns the key that holds the read lock on etcd.
+func (rw *RWMutex) RKey() string { return rw.rKey }
+
+// Key returns the key that holds the write lock on etcd.
+func (rw *RWMutex) Key() string { return rw.w.Key() }
+
+// IsReader returns a comparison that succeeds only while the session holds
+// the read lock.
+func (rw *RWMutex) IsReader() v3.Cmp {
+	return v3.Compare(v3.CreateRevision(rw.rKey), "=", rw.rRev)
+}
+
+// IsOwner returns a comparison that succeeds only while the session holds
+// the write lock.
+func (rw *RWMutex) IsOwner() v3.Cmp { return rw.w.IsOwner() }
+
+// RHeader is the response header received from etcd on acquiring the read lock.
+func (rw *RWMutex) RHeader() *pb.ResponseHeader { return rw.rHdr }
+
+// Header is the response header received from etcd on acquiring the write lock.
+func (rw *RWMutex) Header() *pb.ResponseHeader { return rw.w.Header() }
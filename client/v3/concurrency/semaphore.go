@@ -0,0 +1,164 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	v3 "go.etcd.io/etcd/client/v3"
+)
+
+// ErrNoPermits is returned by TryAcquire when all permits of the Semaphore
+// are held by other sessions.
+var ErrNoPermits = errors.New("semaphore: no permits available")
+
+// Semaphore is a counting semaphore that allows at most n sessions to hold
+// it at the same time. Waiters are granted permits in the order in which
+// they started waiting, so a waiter is never overtaken by a later one.
+// A Semaphore with a single permit behaves like a Mutex on the same prefix.
+type Semaphore struct {
+	s *Session
+	n int
+
+	pfx   string
+	myKey string
+	myRev int64
+	hdr   *pb.ResponseHeader
+}
+
+// NewSemaphore creates a Semaphore with n permits on the given prefix.
+// If n is less than one, the semaphore has a single permit.
+func NewSemaphore(s *Session, pfx string, n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+	return &Semaphore{s: s, n: n, pfx: pfx + "/", myRev: -1}
+}
+
+// TryAcquire acquires a permit if one is available without waiting. If all
+// permits are held by other sessions, it cleans up its waiter entry and
+// returns ErrNoPermits.
+func (sm *Semaphore) TryAcquire(ctx context.Context) error {
+	resp, err := sm.tryAcquire(ctx)
+	if err != nil {
+		return err
+	}
+	if sm.isHolder(resp) {
+		sm.hdr = resp.Header
+		return nil
+	}
+	// Cannot acquire, so delete the key
+	if _, err := sm.s.Client().Delete(ctx, sm.myKey); err != nil {
+		return err
+	}
+	sm.myKey = "\x00"
+	sm.myRev = -1
+	return ErrNoPermits
+}
+
+// Acquire acquires a permit, waiting until one is released if necessary.
+// If the context is canceled while waiting, the semaphore tries to clean
+// its stale waiter entry.
+func (sm *Semaphore) Acquire(ctx context.Context) error {
+	resp, err := sm.tryAcquire(ctx)
+	if err != nil {
+		return err
+	}
+	if sm.isHolder(resp) {
+		sm.hdr = resp.Header
+		return nil
+	}
+	client := sm.s.Client()
+	// wait until fewer than n waiters ahead of myKey remain
+	_, werr := waitHolders(ctx, client, sm.pfx, sm.myRev-1, sm.n)
+	// release waiter key if wait failed
+	if werr != nil {
+		sm.Release(client.Ctx())
+		return werr
+	}
+
+	// make sure the session is not expired, and the waiter key still exists.
+	gresp, werr := client.Get(ctx, sm.myKey)
+	if werr != nil {
+		sm.Release(client.Ctx())
+		return werr
+	}
+	if len(gresp.Kvs) == 0 { // is the session key lost?
+		return ErrSessionExpired
+	}
+	sm.hdr = gresp.Header
+	return nil
+}
+
+func (sm *Semaphore) tryAcquire(ctx context.Context) (*v3.TxnResponse, error) {
+	sm.myKey = fmt.Sprintf("%s%x", sm.pfx, sm.s.Lease())
+	// fetch the first n waiters to complete the uncontended path with only one RPC
+	getHolders := v3.OpGet(sm.pfx,
+		v3.WithPrefix(),
+		v3.WithKeysOnly(),
+		v3.WithSort(v3.SortByCreateRevision, v3.SortAscend),
+		v3.WithLimit(int64(sm.n)),
+	)
+	rev, resp, err := acquireKey(ctx, sm.s, sm.myKey, getHolders)
+	if err != nil {
+		return nil, err
+	}
+	sm.myRev = rev
+	return resp, nil
+}
+
+// isHolder reports whether myKey is among the first n waiters.
+func (sm *Semaphore) isHolder(resp *v3.TxnResponse) bool {
+	holders := resp.Responses[1].GetResponseRange().Kvs
+	if len(holders) < sm.n {
+		return true
+	}
+	for _, kv := range holders {
+		if kv.CreateRevision == sm.myRev {
+			return true
+		}
+	}
+	return false
+}
+
+// Release gives up the permit held by, or the waiter entry of, the session.
+func (sm *Semaphore) Release(ctx context.Context) error {
+	client := sm.s.Client()
+	if _, err := client.Delete(ctx, sm.myKey); err != nil {
+		return err
+	}
+	sm.myKey = "\x00"
+	sm.myRev = -1
+	return nil
+}
+
+// IsOwner returns a comparison that succeeds only while the session's
+// waiter entry exists. It does not guarantee that a permit is still held
+// if the entry was never granted one.
+func (sm *Semaphore) IsOwner() v3.Cmp {
+	return v3.Compare(v3.CreateRevision(sm.myKey), "=", sm.myRev)
+}
+
+// Key returns the key that holds the permit on etcd.
+func (sm *Semaphore) Key() string { return sm.myKey }
+
+// Limit returns the number of permits of the semaphore.
+func (sm *Semaphore) Limit() int { return sm.n }
+
+// Header is the response header received from etcd on acquiring the permit.
+func (sm *Semaphore) Header() *pb.ResponseHeader { return sm.hdr }
@@ -27,6 +27,17 @@ type Config struct {
 	// Endpoints is a list of URLs.
 	Endpoints []string `json:"endpoints"`
 
+	// LearnerEndpoints is a list of URLs of learner members, added to Endpoints
+	// if missing. The client only sends them requests with PreferLearnerReads, or
+	// without any voter endpoint. Sync updates them from the membership.
+	LearnerEndpoints []string `json:"learner-endpoints"`
+
+	// PreferLearnerReads when set sends the RPCs learners serve, ranges, watches, and
+	// endpoint status and snapshot, to learner endpoints only, as long as one of them
+	// is available. Linearizable ranges and watches need the learners to be started
+	// with --experimental-learner-serve-reads.
+	PreferLearnerReads bool `json:"prefer-learner-reads"`
+
 	// AutoSyncInterval is the interval to update endpoints with its latest members.
 	// 0 disables auto-sync. By default auto-sync is disabled.
 	AutoSyncInterval time.Duration `json:"auto-sync-interval"`
@@ -0,0 +1,51 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consistency is a clientv3 wrapper providing read-your-writes and
+// monotonic reads across processes and endpoints with session consistency
+// tokens.
+//
+// A session tracks the latest revision observed by the responses of its
+// requests. Its token can be passed to another process, for example in the
+// Etcd-Consistency-Token HTTP header, which observes it in its own session.
+// The serializable Get requests of a session, alone or in transactions, are
+// then served by any member once the member has applied the latest revision
+// observed by the session, so they reflect all the writes seen by the session
+// and the sessions whose tokens it observed. Linearizable Get requests always
+// do, and are left as given.
+//
+// First, wrap the KV of a client with a session:
+//
+//	s := consistency.NewSession()
+//	kv := consistency.NewKV(cli.KV, s)
+//	if _, err := kv.Put(ctx, "foo", "bar"); err != nil {
+//		// handle error!
+//	}
+//	req.Header.Set(consistency.TokenHeader, s.Token().String())
+//
+// Next, in the other process, observe the token before reading:
+//
+//	tok, err := consistency.ParseToken(req.Header.Get(consistency.TokenHeader))
+//	if err != nil {
+//		// handle error!
+//	}
+//	s := consistency.NewSession()
+//	if err = s.Observe(tok); err != nil {
+//		// handle error!
+//	}
+//	resp, err := consistency.NewKV(cli.KV, s).Get(ctx, "foo", clientv3.WithSerializable())
+//
+// The Get waits until the serving member has applied the write of "foo", or
+// fails with rpctypes.ErrStaleRead if it does not apply it in time.
+package consistency
@@ -0,0 +1,141 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consistency
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/v3"
+)
+
+// kvSession records the revisions of the responses in a session, and serves
+// the serializable Get requests from members which applied the latest one.
+type kvSession struct {
+	clientv3.KV
+	s *Session
+}
+
+// NewKV wraps a KV so that its responses are observed by the session s, and
+// its serializable Get requests, including those of transactions, read at
+// least the latest revision observed by s. Linearizable Get requests already
+// do, and are left as given.
+func NewKV(kv clientv3.KV, s *Session) clientv3.KV {
+	return &kvSession{kv, s}
+}
+
+func (kv *kvSession) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	r, err := kv.Do(ctx, clientv3.OpGet(key, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(), nil
+}
+
+func (kv *kvSession) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	r, err := kv.Do(ctx, clientv3.OpPut(key, val, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return r.Put(), nil
+}
+
+func (kv *kvSession) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	r, err := kv.Do(ctx, clientv3.OpDelete(key, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return r.Del(), nil
+}
+
+func (kv *kvSession) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	r, err := kv.KV.Do(ctx, kv.s.bound(op))
+	if err != nil {
+		return r, err
+	}
+	kv.s.observeHeader(opResponseHeader(r))
+	return r, nil
+}
+
+func (kv *kvSession) Txn(ctx context.Context) clientv3.Txn {
+	return &txnSession{kv.KV.Txn(ctx), kv.s}
+}
+
+// txnSession records the revision of the transaction response in a session.
+type txnSession struct {
+	clientv3.Txn
+	s *Session
+}
+
+func (txn *txnSession) If(cs ...clientv3.Cmp) clientv3.Txn {
+	txn.Txn = txn.Txn.If(cs...)
+	return txn
+}
+
+func (txn *txnSession) Then(ops ...clientv3.Op) clientv3.Txn {
+	txn.Txn = txn.Txn.Then(txn.s.boundAll(ops)...)
+	return txn
+}
+
+func (txn *txnSession) Else(ops ...clientv3.Op) clientv3.Txn {
+	txn.Txn = txn.Txn.Else(txn.s.boundAll(ops)...)
+	return txn
+}
+
+func (txn *txnSession) Commit() (*clientv3.TxnResponse, error) {
+	resp, err := txn.Txn.Commit()
+	if err != nil {
+		return nil, err
+	}
+	txn.s.observeHeader(resp.Header)
+	return resp, nil
+}
+
+// bound sets the min revision of the serializable Get requests of op, and of
+// its transaction, to the latest revision observed by the session.
+func (s *Session) bound(op clientv3.Op) clientv3.Op {
+	rev := s.Revision()
+	switch {
+	case rev == 0:
+	case op.IsGet() && op.IsSerializable() && op.MinRevision() < rev:
+		clientv3.WithMinRevision(rev)(&op)
+	case op.IsTxn():
+		cmps, thenOps, elseOps := op.Txn()
+		op = clientv3.OpTxn(cmps, s.boundAll(thenOps), s.boundAll(elseOps))
+	}
+	return op
+}
+
+func (s *Session) boundAll(ops []clientv3.Op) []clientv3.Op {
+	bops := make([]clientv3.Op, len(ops))
+	for i := range ops {
+		bops[i] = s.bound(ops[i])
+	}
+	return bops
+}
+
+func opResponseHeader(r clientv3.OpResponse) *pb.ResponseHeader {
+	switch {
+	case r.Get() != nil:
+		return r.Get().Header
+	case r.Put() != nil:
+		return r.Put().Header
+	case r.Del() != nil:
+		return r.Del().Header
+	case r.Txn() != nil:
+		return r.Txn().Header
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consistency
+
+import (
+	"context"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/v3"
+)
+
+type mockKV struct {
+	clientv3.KV
+	ops []clientv3.Op
+	txn *mockTxn
+	rev int64
+}
+
+func (kv *mockKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	kv.ops = append(kv.ops, op)
+	h := &pb.ResponseHeader{ClusterId: 1, Revision: kv.rev}
+	switch {
+	case op.IsGet():
+		return (&clientv3.GetResponse{Header: h}).OpResponse(), nil
+	case op.IsPut():
+		return (&clientv3.PutResponse{Header: h}).OpResponse(), nil
+	case op.IsTxn():
+		return (&clientv3.TxnResponse{Header: h}).OpResponse(), nil
+	default:
+		return (&clientv3.DeleteResponse{Header: h}).OpResponse(), nil
+	}
+}
+
+func (kv *mockKV) Txn(ctx context.Context) clientv3.Txn {
+	kv.txn = &mockTxn{rev: kv.rev}
+	return kv.txn
+}
+
+type mockTxn struct {
+	clientv3.Txn
+	thenOps, elseOps []clientv3.Op
+	rev              int64
+}
+
+func (txn *mockTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	txn.thenOps = ops
+	return txn
+}
+
+func (txn *mockTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	txn.elseOps = ops
+	return txn
+}
+
+func (txn *mockTxn) Commit() (*clientv3.TxnResponse, error) {
+	return &clientv3.TxnResponse{Header: &pb.ResponseHeader{ClusterId: 1, Revision: txn.rev}}, nil
+}
+
+func TestKVSession(t *testing.T) {
+	mkv := &mockKV{rev: 3}
+	s := NewSession()
+	kv := NewKV(mkv, s)
+	ctx := context.TODO()
+
+	if _, err := kv.Get(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if op := mkv.ops[0]; op.IsSerializable() || op.MinRevision() != 0 {
+		t.Fatalf("expected a linearizable get before observing a revision, got %+v", op)
+	}
+	if rev := s.Revision(); rev != 3 {
+		t.Fatalf("expected revision 3, got %d", rev)
+	}
+
+	mkv.rev = 8
+	if _, err := kv.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.Get(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if op := mkv.ops[2]; op.IsSerializable() || op.MinRevision() != 0 {
+		t.Fatalf("expected the linearizable get to be left as given, got %+v", op)
+	}
+	if _, err := kv.Get(ctx, "foo", clientv3.WithSerializable()); err != nil {
+		t.Fatal(err)
+	}
+	if op := mkv.ops[3]; !op.IsSerializable() || op.MinRevision() != 8 {
+		t.Fatalf("expected a serializable get of min revision 8, got %+v", op)
+	}
+
+	if err := s.Observe(Token{ClusterID: 1, Revision: 12}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.Delete(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.Do(ctx, clientv3.OpGet("foo", clientv3.WithSerializable())); err != nil {
+		t.Fatal(err)
+	}
+	if op := mkv.ops[5]; op.MinRevision() != 12 {
+		t.Fatalf("expected a get of min revision 12, got %+v", op)
+	}
+
+	// the serializable gets of transactions are bounded alike
+	get := clientv3.OpGet("foo", clientv3.WithSerializable())
+	txn := clientv3.OpTxn(nil, []clientv3.Op{get, clientv3.OpGet("foo")}, []clientv3.Op{clientv3.OpTxn(nil, []clientv3.Op{get}, nil)})
+	if _, err := kv.Do(ctx, txn); err != nil {
+		t.Fatal(err)
+	}
+	_, thenOps, elseOps := mkv.ops[6].Txn()
+	_, nestedOps, _ := elseOps[0].Txn()
+	if thenOps[0].MinRevision() != 12 || thenOps[1].MinRevision() != 0 || nestedOps[0].MinRevision() != 12 {
+		t.Fatalf("expected the serializable gets of the transaction to be of min revision 12, got %+v", mkv.ops[6])
+	}
+	if get.MinRevision() != 0 {
+		t.Fatalf("expected the ops of the caller to be left as given, got %+v", get)
+	}
+
+	if _, err := kv.Txn(ctx).Then(get).Else(clientv3.OpGet("bar")).Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if thenOps, elseOps := mkv.txn.thenOps, mkv.txn.elseOps; thenOps[0].MinRevision() != 12 || elseOps[0].MinRevision() != 0 {
+		t.Fatalf("expected the serializable gets of the transaction to be of min revision 12, got %+v %+v", thenOps, elseOps)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consistency
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// TokenHeader is the HTTP header conventionally carrying session consistency
+// tokens between services.
+const TokenHeader = "Etcd-Consistency-Token"
+
+var (
+	ErrInvalidToken    = errors.New("consistency: invalid token")
+	ErrClusterMismatch = errors.New("consistency: token of another cluster")
+)
+
+// Token is a portable session consistency token: the latest revision of a
+// cluster observed by a session. The zero Token observes nothing.
+type Token struct {
+	ClusterID uint64
+	Revision  int64
+}
+
+// String returns the token in the form "<hex cluster id>-<revision>", or ""
+// for the zero Token.
+func (t Token) String() string {
+	if t.Revision == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%x-%d", t.ClusterID, t.Revision)
+}
+
+// ParseToken parses a token returned by Token.String. An empty string parses
+// to the zero Token.
+func ParseToken(s string) (Token, error) {
+	if s == "" {
+		return Token{}, nil
+	}
+	i := strings.IndexByte(s, '-')
+	if i < 0 {
+		return Token{}, ErrInvalidToken
+	}
+	cid, err := strconv.ParseUint(s[:i], 16, 64)
+	if err != nil {
+		return Token{}, ErrInvalidToken
+	}
+	rev, err := strconv.ParseInt(s[i+1:], 10, 64)
+	if err != nil || rev <= 0 {
+		return Token{}, ErrInvalidToken
+	}
+	return Token{ClusterID: cid, Revision: rev}, nil
+}
+
+// Session tracks the latest revision observed by the requests of a wrapped KV
+// and the tokens it observed. It is safe for concurrent use.
+type Session struct {
+	mu        sync.RWMutex
+	clusterID uint64
+	rev       int64
+}
+
+// NewSession returns a session which has observed nothing.
+func NewSession() *Session { return &Session{} }
+
+// Revision returns the latest revision observed by the session.
+func (s *Session) Revision() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rev
+}
+
+// Token returns the token of the latest revision observed by the session.
+func (s *Session) Token() Token {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Token{ClusterID: s.clusterID, Revision: s.rev}
+}
+
+// Observe makes the session observe the revision of a token, so the later Get
+// requests of the session reflect it. It returns ErrClusterMismatch if the
+// token and the session observed different clusters.
+func (s *Session) Observe(t Token) error {
+	if t.Revision == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clusterID != 0 && t.ClusterID != 0 && s.clusterID != t.ClusterID {
+		return ErrClusterMismatch
+	}
+	if s.clusterID == 0 {
+		s.clusterID = t.ClusterID
+	}
+	if t.Revision > s.rev {
+		s.rev = t.Revision
+	}
+	return nil
+}
+
+// observeHeader makes the session observe the revision of a response header.
+// The headers of another cluster than the one observed first are ignored.
+func (s *Session) observeHeader(h *pb.ResponseHeader) {
+	if h == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clusterID == 0 {
+		s.clusterID = h.ClusterId
+	} else if h.ClusterId != 0 && h.ClusterId != s.clusterID {
+		return
+	}
+	if h.Revision > s.rev {
+		s.rev = h.Revision
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consistency
+
+import (
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestTokenString(t *testing.T) {
+	tests := []struct {
+		tok Token
+		s   string
+	}{
+		{Token{}, ""},
+		{Token{ClusterID: 0xabc, Revision: 42}, "abc-42"},
+	}
+	for i, tt := range tests {
+		if s := tt.tok.String(); s != tt.s {
+			t.Errorf("#%d: expected %q, got %q", i, tt.s, s)
+		}
+		tok, err := ParseToken(tt.s)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if tok != tt.tok {
+			t.Errorf("#%d: expected %+v, got %+v", i, tt.tok, tok)
+		}
+	}
+}
+
+func TestParseTokenInvalid(t *testing.T) {
+	for _, s := range []string{"42", "xyz-42", "abc-", "abc-0", "abc--1", "abc-4-2"} {
+		if _, err := ParseToken(s); err != ErrInvalidToken {
+			t.Errorf("%q: expected %v, got %v", s, ErrInvalidToken, err)
+		}
+	}
+}
+
+func TestSessionObserve(t *testing.T) {
+	s := NewSession()
+	if err := s.Observe(Token{}); err != nil {
+		t.Fatal(err)
+	}
+	s.observeHeader(&pb.ResponseHeader{ClusterId: 1, Revision: 5})
+	if err := s.Observe(Token{ClusterID: 1, Revision: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if tok := s.Token(); tok != (Token{ClusterID: 1, Revision: 5}) {
+		t.Fatalf("expected the older token to be ignored, got %+v", tok)
+	}
+	if err := s.Observe(Token{ClusterID: 1, Revision: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if rev := s.Revision(); rev != 7 {
+		t.Fatalf("expected revision 7, got %d", rev)
+	}
+	if err := s.Observe(Token{ClusterID: 2, Revision: 9}); err != ErrClusterMismatch {
+		t.Fatalf("expected %v, got %v", ErrClusterMismatch, err)
+	}
+	s.observeHeader(&pb.ResponseHeader{ClusterId: 2, Revision: 9})
+	if rev := s.Revision(); rev != 7 {
+		t.Fatalf("expected the header of another cluster to be ignored, got revision %d", rev)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package balancer implements the load balancing policy of the client: round
+// robin over the endpoints, where the endpoints of learner members are only
+// picked for the RPCs learners serve, when the client prefers learner reads.
+package balancer
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+// Name is the name of the load balancing policy.
+const Name = "etcd_learner_aware"
+
+// learnerMethods are the RPCs learners serve when enabled to serve reads.
+var learnerMethods = map[string]struct{}{
+	"/etcdserverpb.KV/Range":             {},
+	"/etcdserverpb.Watch/Watch":          {},
+	"/etcdserverpb.Maintenance/Status":   {},
+	"/etcdserverpb.Maintenance/Snapshot": {},
+}
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(Name, &pickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+type learnersKey struct{}
+
+// Learners is the set of the addresses of learner endpoints. It is shared by
+// all the addresses of a resolver, so that a change applies to the next pick.
+type Learners struct {
+	mu          sync.RWMutex
+	addrs       map[string]struct{}
+	preferReads bool
+	// gen is incremented by each Set, for the pickers to partition their
+	// SubConns again.
+	gen uint64
+}
+
+func NewLearners() *Learners {
+	return &Learners{addrs: make(map[string]struct{})}
+}
+
+// Set replaces the addresses of the learner endpoints.
+func (l *Learners) Set(addrs []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addrs = make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		l.addrs[addr] = struct{}{}
+	}
+	atomic.AddUint64(&l.gen, 1)
+}
+
+// SetPreferReads makes the RPCs learners serve picked to learner endpoints
+// only, as long as one of them is ready.
+func (l *Learners) SetPreferReads(prefer bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.preferReads = prefer
+}
+
+// WithLearners attaches the learner set to the address.
+func WithLearners(addr resolver.Address, l *Learners) resolver.Address {
+	if addr.Attributes == nil {
+		addr.Attributes = attributes.New(learnersKey{}, l)
+	} else {
+		addr.Attributes = addr.Attributes.WithValues(learnersKey{}, l)
+	}
+	return addr
+}
+
+type pickerBuilder struct{}
+
+func (*pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{}
+	for sc, sci := range info.ReadySCs {
+		p.subConns = append(p.subConns, sc)
+		p.addrs = append(p.addrs, sci.Address.Addr)
+		if p.learners == nil && sci.Address.Attributes != nil {
+			p.learners, _ = sci.Address.Attributes.Value(learnersKey{}).(*Learners)
+		}
+	}
+	if p.learners != nil {
+		p.partition()
+	}
+	return p
+}
+
+type picker struct {
+	// subConns and addrs are the ready SubConns and their addresses.
+	subConns []balancer.SubConn
+	addrs    []string
+	learners *Learners
+
+	// mu guards the partition of subConns into voters and learners, made
+	// from the learner set of generation gen.
+	mu          sync.RWMutex
+	partitioned bool
+	gen         uint64
+	voters      []balancer.SubConn
+	learnerSCs  []balancer.SubConn
+
+	// next are the round robin counters of all, voter and learner SubConns.
+	next, nextVoter, nextLearner uint32
+}
+
+// partition splits the SubConns into the ones of voters and learners, if the
+// learner set changed since the last partition.
+func (p *picker) partition() {
+	gen := atomic.LoadUint64(&p.learners.gen)
+	p.mu.RLock()
+	partitioned := p.partitioned && p.gen == gen
+	p.mu.RUnlock()
+	if partitioned {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.learners.mu.RLock()
+	defer p.learners.mu.RUnlock()
+	p.voters, p.learnerSCs = nil, nil
+	for i, addr := range p.addrs {
+		if _, ok := p.learners.addrs[addr]; ok {
+			p.learnerSCs = append(p.learnerSCs, p.subConns[i])
+		} else {
+			p.voters = append(p.voters, p.subConns[i])
+		}
+	}
+	p.gen = atomic.LoadUint64(&p.learners.gen)
+	p.partitioned = true
+}
+
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	if p.learners == nil {
+		return balancer.PickResult{SubConn: pick(p.subConns, &p.next)}, nil
+	}
+	p.partition()
+
+	p.learners.mu.RLock()
+	preferReads := p.learners.preferReads
+	p.learners.mu.RUnlock()
+	p.mu.RLock()
+	voters, learners := p.voters, p.learnerSCs
+	p.mu.RUnlock()
+
+	// learners are only picked for the RPCs they serve when the client prefers
+	// them for reads, or when there is no voter.
+	if _, ok := learnerMethods[info.FullMethodName]; ok && preferReads && len(learners) > 0 {
+		return balancer.PickResult{SubConn: pick(learners, &p.nextLearner)}, nil
+	}
+	if len(voters) > 0 {
+		return balancer.PickResult{SubConn: pick(voters, &p.nextVoter)}, nil
+	}
+	return balancer.PickResult{SubConn: pick(p.subConns, &p.next)}, nil
+}
+
+func pick(scs []balancer.SubConn, next *uint32) balancer.SubConn {
+	n := atomic.AddUint32(next, 1)
+	return scs[n%uint32(len(scs))]
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package balancer
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+type fakeSubConn struct{ addr string }
+
+func (sc *fakeSubConn) UpdateAddresses([]resolver.Address) {}
+func (sc *fakeSubConn) Connect()                           {}
+
+func TestPickerLearners(t *testing.T) {
+	l := NewLearners()
+	l.Set([]string{"learner"})
+	info := base.PickerBuildInfo{ReadySCs: make(map[balancer.SubConn]base.SubConnInfo)}
+	for _, addr := range []string{"voter0", "voter1", "learner"} {
+		info.ReadySCs[&fakeSubConn{addr: addr}] = base.SubConnInfo{Address: WithLearners(resolver.Address{Addr: addr}, l)}
+	}
+	p := (&pickerBuilder{}).Build(info)
+
+	picked := func(method string) map[string]int {
+		counts := make(map[string]int)
+		for i := 0; i < 6; i++ {
+			res, err := p.Pick(balancer.PickInfo{FullMethodName: method})
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[res.SubConn.(*fakeSubConn).addr]++
+		}
+		return counts
+	}
+
+	tests := []struct {
+		method      string
+		preferReads bool
+		wcounts     map[string]int
+	}{
+		{"/etcdserverpb.KV/Put", false, map[string]int{"voter0": 3, "voter1": 3}},
+		{"/etcdserverpb.KV/Range", false, map[string]int{"voter0": 3, "voter1": 3}},
+		{"/etcdserverpb.KV/Put", true, map[string]int{"voter0": 3, "voter1": 3}},
+		{"/etcdserverpb.KV/Range", true, map[string]int{"learner": 6}},
+	}
+	for i, tt := range tests {
+		l.SetPreferReads(tt.preferReads)
+		counts := picked(tt.method)
+		if len(counts) != len(tt.wcounts) {
+			t.Fatalf("#%d: picked %v, want %v", i, counts, tt.wcounts)
+		}
+		for addr, n := range tt.wcounts {
+			if counts[addr] != n {
+				t.Errorf("#%d: picked %v, want %v", i, counts, tt.wcounts)
+			}
+		}
+	}
+
+	// a change of the learner set applies to the next pick
+	l.Set([]string{"voter0"})
+	if counts := picked("/etcdserverpb.KV/Range"); counts["voter0"] != 6 {
+		t.Errorf("picked %v, want reads to the learner voter0", counts)
+	}
+
+	// writes fall back to learners without any voter
+	l.Set([]string{"voter0", "voter1", "learner"})
+	if counts := picked("/etcdserverpb.KV/Put"); len(counts) != 3 {
+		t.Errorf("picked %v, want all SubConns", counts)
+	}
+}
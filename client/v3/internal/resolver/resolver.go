@@ -15,6 +15,7 @@
 package resolver
 
 import (
+	"go.etcd.io/etcd/client/v3/internal/balancer"
 	"go.etcd.io/etcd/client/v3/internal/endpoint"
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/resolver/manual"
@@ -30,17 +31,18 @@ const (
 type EtcdManualResolver struct {
 	*manual.Resolver
 	endpoints     []string
+	learners      *balancer.Learners
 	serviceConfig *serviceconfig.ParseResult
 }
 
 func New(endpoints ...string) *EtcdManualResolver {
 	r := manual.NewBuilderWithScheme(Schema)
-	return &EtcdManualResolver{Resolver: r, endpoints: endpoints, serviceConfig: nil}
+	return &EtcdManualResolver{Resolver: r, endpoints: endpoints, learners: balancer.NewLearners(), serviceConfig: nil}
 }
 
 // Build returns itself for Resolver, because it's both a builder and a resolver.
 func (r *EtcdManualResolver) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
-	r.serviceConfig = cc.ParseServiceConfig(`{"loadBalancingPolicy": "round_robin"}`)
+	r.serviceConfig = cc.ParseServiceConfig(`{"loadBalancingPolicy": "` + balancer.Name + `"}`)
 	if r.serviceConfig.Err != nil {
 		return nil, r.serviceConfig.Err
 	}
@@ -58,12 +60,28 @@ func (r *EtcdManualResolver) SetEndpoints(endpoints []string) {
 	r.updateState()
 }
 
+// SetLearnerEndpoints sets which endpoints are the ones of learner members,
+// which are only picked for the RPCs learners serve.
+func (r *EtcdManualResolver) SetLearnerEndpoints(endpoints []string) {
+	addrs := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		addrs[i], _ = endpoint.Interpret(ep)
+	}
+	r.learners.Set(addrs)
+}
+
+// SetPreferLearnerReads makes the RPCs learners serve picked to the endpoints
+// of learner members only, as long as one of them is available.
+func (r *EtcdManualResolver) SetPreferLearnerReads(prefer bool) {
+	r.learners.SetPreferReads(prefer)
+}
+
 func (r EtcdManualResolver) updateState() {
 	if r.CC != nil {
 		addresses := make([]resolver.Address, len(r.endpoints))
 		for i, ep := range r.endpoints {
 			addr, serverName := endpoint.Interpret(ep)
-			addresses[i] = resolver.Address{Addr: addr, ServerName: serverName}
+			addresses[i] = balancer.WithLearners(resolver.Address{Addr: addr, ServerName: serverName}, r.learners)
 		}
 		state := resolver.State{
 			Addresses:     addresses,
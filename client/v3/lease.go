@@ -24,11 +24,14 @@ import (
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type (
 	LeaseRevokeResponse pb.LeaseRevokeResponse
+	LeaseMoveResponse   pb.LeaseMoveResponse
 	LeaseID             int64
 )
 
@@ -40,6 +43,44 @@ type LeaseGrantResponse struct {
 	Error string
 }
 
+// LeaseUpdateResponse wraps the protobuf message LeaseUpdateResponse.
+type LeaseUpdateResponse struct {
+	*pb.ResponseHeader
+	ID  LeaseID
+	TTL int64
+}
+
+// LeaseEventType is the kind of a LeaseEvent.
+type LeaseEventType = pb.LeaseEvent_EventType
+
+const (
+	LeaseEventGranted LeaseEventType = pb.LeaseEvent_GRANTED
+	LeaseEventRenewed LeaseEventType = pb.LeaseEvent_RENEWED
+	LeaseEventRevoked LeaseEventType = pb.LeaseEvent_REVOKED
+	LeaseEventExpired LeaseEventType = pb.LeaseEvent_EXPIRED
+)
+
+// LeaseEvent wraps the protobuf message LeaseEvent.
+type LeaseEvent struct {
+	Type LeaseEventType
+	ID   LeaseID
+	TTL  int64
+	// RemainingTTL is the remaining TTL of a renewed lease before its renewal.
+	RemainingTTL int64
+}
+
+// LeaseWatchResponse wraps the protobuf message LeaseWatchResponse.
+type LeaseWatchResponse struct {
+	*pb.ResponseHeader
+	Events []LeaseEvent
+
+	err error
+}
+
+// Err is the error that ended the watch, if any. A response with an error
+// carries no events and is the last one of the watch.
+func (wr *LeaseWatchResponse) Err() error { return wr.err }
+
 // LeaseKeepAliveResponse wraps the protobuf message LeaseKeepAliveResponse.
 type LeaseKeepAliveResponse struct {
 	*pb.ResponseHeader
@@ -83,6 +124,10 @@ const (
 
 	// retryConnWait is how long to wait before retrying request due to an error
 	retryConnWait = 500 * time.Millisecond
+
+	// maxKeepAliveBatch is the maximum number of leases kept alive by a single
+	// LeaseKeepAliveBatchRequest.
+	maxKeepAliveBatch = 10000
 )
 
 // LeaseResponseChSize is the size of buffer to store unsent lease responses.
@@ -112,12 +157,27 @@ type Lease interface {
 	// Revoke revokes the given lease.
 	Revoke(ctx context.Context, id LeaseID) (*LeaseRevokeResponse, error)
 
+	// Update changes the TTL of the given lease and renews the lease with the new TTL.
+	Update(ctx context.Context, id LeaseID, ttl int64) (*LeaseUpdateResponse, error)
+
+	// Move attaches all keys of the given lease to the target lease. The keys
+	// keep their revisions and versions, and no watch events are sent.
+	Move(ctx context.Context, id, target LeaseID) (*LeaseMoveResponse, error)
+
 	// TimeToLive retrieves the lease information of the given lease ID.
 	TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption) (*LeaseTimeToLiveResponse, error)
 
 	// Leases retrieves all leases.
 	Leases(ctx context.Context) (*LeaseLeasesResponse, error)
 
+	// WatchEvents streams the lease events of the member serving the request: grants,
+	// revocations and expiries of leases, and renewals by the leader when enabled
+	// with WithRenewThreshold. WithLeaseIDs limits the events to the given leases.
+	// WatchEvents returns once the watch is established. The channel is closed when ctx
+	// is canceled, or after a last response reporting the error ending the watch;
+	// lease events are not replayed, so events may be lost until the next WatchEvents.
+	WatchEvents(ctx context.Context, opts ...LeaseOption) (<-chan *LeaseWatchResponse, error)
+
 	// KeepAlive attempts to keep the given lease alive forever. If the keepalive responses posted
 	// to the channel are not consumed promptly the channel may become full. When full, the lease
 	// client will continue sending keep alive requests to the etcd server, but will drop responses
@@ -156,9 +216,13 @@ type lessor struct {
 
 	remote pb.LeaseClient
 
-	stream       pb.Lease_LeaseKeepAliveClient
+	stream       keepAliveStream
 	streamCancel context.CancelFunc
 
+	// noBatch is set if the server does not serve LeaseKeepAliveBatch; the
+	// leases are then kept alive one by one over LeaseKeepAlive.
+	noBatch bool
+
 	stopCtx    context.Context
 	stopCancel context.CancelFunc
 
@@ -235,6 +299,29 @@ func (l *lessor) Revoke(ctx context.Context, id LeaseID) (*LeaseRevokeResponse,
 	return nil, toErr(ctx, err)
 }
 
+func (l *lessor) Update(ctx context.Context, id LeaseID, ttl int64) (*LeaseUpdateResponse, error) {
+	r := &pb.LeaseUpdateRequest{ID: int64(id), TTL: ttl}
+	resp, err := l.remote.LeaseUpdate(ctx, r, l.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	uresp := &LeaseUpdateResponse{
+		ResponseHeader: resp.GetHeader(),
+		ID:             LeaseID(resp.ID),
+		TTL:            resp.TTL,
+	}
+	return uresp, nil
+}
+
+func (l *lessor) Move(ctx context.Context, id, target LeaseID) (*LeaseMoveResponse, error) {
+	r := &pb.LeaseMoveRequest{ID: int64(id), TargetID: int64(target)}
+	resp, err := l.remote.LeaseMove(ctx, r, l.callOpts...)
+	if err == nil {
+		return (*LeaseMoveResponse)(resp), nil
+	}
+	return nil, toErr(ctx, err)
+}
+
 func (l *lessor) TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption) (*LeaseTimeToLiveResponse, error) {
 	r := toLeaseTimeToLiveRequest(id, opts...)
 	resp, err := l.remote.LeaseTimeToLive(ctx, r, l.callOpts...)
@@ -263,6 +350,47 @@ func (l *lessor) Leases(ctx context.Context) (*LeaseLeasesResponse, error) {
 	return nil, toErr(ctx, err)
 }
 
+func (l *lessor) WatchEvents(ctx context.Context, opts ...LeaseOption) (<-chan *LeaseWatchResponse, error) {
+	ws, err := l.remote.LeaseWatch(ctx, toLeaseWatchRequest(opts...), l.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	// the first response confirms the watch is established
+	if _, err = ws.Recv(); err != nil {
+		return nil, toErr(ctx, err)
+	}
+
+	ch := make(chan *LeaseWatchResponse, LeaseResponseChSize)
+	go func() {
+		defer close(ch)
+		for {
+			resp, err := ws.Recv()
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			wr := &LeaseWatchResponse{}
+			if err != nil {
+				wr.err = toErr(ctx, err)
+			} else {
+				wr.ResponseHeader = resp.GetHeader()
+				wr.Events = make([]LeaseEvent, len(resp.Events))
+				for i, ev := range resp.Events {
+					wr.Events[i] = LeaseEvent{Type: ev.Type, ID: LeaseID(ev.ID), TTL: ev.TTL, RemainingTTL: ev.Remaining_TTL}
+				}
+			}
+			select {
+			case ch <- wr:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
 func (l *lessor) KeepAlive(ctx context.Context, id LeaseID) (<-chan *LeaseKeepAliveResponse, error) {
 	ch := make(chan *LeaseKeepAliveResponse, LeaseResponseChSize)
 
@@ -447,19 +575,29 @@ func (l *lessor) recvKeepAliveLoop() (gerr error) {
 			}
 		} else {
 			for {
-				resp, err := stream.Recv()
+				resps, err := stream.recv()
 				if err != nil {
 					if canceledByCaller(l.stopCtx, err) {
 						return err
 					}
 
+					if _, ok := stream.(*batchKeepAliveStream); ok && status.Code(err) == codes.Unimplemented {
+						l.lg.Info("lease keepalive batching is not supported by the server; falling back to per-lease keepalives")
+						l.mu.Lock()
+						l.noBatch = true
+						l.mu.Unlock()
+						break
+					}
+
 					if toErr(l.stopCtx, err) == rpctypes.ErrNoLeader {
 						l.closeRequireLeader()
 					}
 					break
 				}
 
-				l.recvKeepAlive(resp)
+				for _, resp := range resps {
+					l.recvKeepAlive(resp)
+				}
 			}
 		}
 
@@ -472,9 +610,25 @@ func (l *lessor) recvKeepAliveLoop() (gerr error) {
 }
 
 // resetRecv opens a new lease stream and starts sending keep alive requests.
-func (l *lessor) resetRecv() (pb.Lease_LeaseKeepAliveClient, error) {
+func (l *lessor) resetRecv() (keepAliveStream, error) {
+	l.mu.Lock()
+	noBatch := l.noBatch
+	l.mu.Unlock()
+
 	sctx, cancel := context.WithCancel(l.stopCtx)
-	stream, err := l.remote.LeaseKeepAlive(sctx, append(l.callOpts, withMax(0))...)
+	var (
+		stream keepAliveStream
+		err    error
+	)
+	if noBatch {
+		var s pb.Lease_LeaseKeepAliveClient
+		s, err = l.remote.LeaseKeepAlive(sctx, append(l.callOpts, withMax(0))...)
+		stream = &singleKeepAliveStream{s}
+	} else {
+		var s pb.Lease_LeaseKeepAliveBatchClient
+		s, err = l.remote.LeaseKeepAliveBatch(sctx, append(l.callOpts, withMax(0))...)
+		stream = &batchKeepAliveStream{s}
+	}
 	if err != nil {
 		cancel()
 		return nil, err
@@ -558,7 +712,7 @@ func (l *lessor) deadlineLoop() {
 }
 
 // sendKeepAliveLoop sends keep alive requests for the lifetime of the given stream.
-func (l *lessor) sendKeepAliveLoop(stream pb.Lease_LeaseKeepAliveClient) {
+func (l *lessor) sendKeepAliveLoop(stream keepAliveStream) {
 	for {
 		var tosend []LeaseID
 
@@ -571,9 +725,8 @@ func (l *lessor) sendKeepAliveLoop(stream pb.Lease_LeaseKeepAliveClient) {
 		}
 		l.mu.Unlock()
 
-		for _, id := range tosend {
-			r := &pb.LeaseKeepAliveRequest{ID: int64(id)}
-			if err := stream.Send(r); err != nil {
+		if len(tosend) > 0 {
+			if err := stream.send(tosend); err != nil {
 				l.lg.Warn("error occurred during lease keep alive request sending",
 					zap.Error(err),
 				)
@@ -583,7 +736,7 @@ func (l *lessor) sendKeepAliveLoop(stream pb.Lease_LeaseKeepAliveClient) {
 
 		select {
 		case <-time.After(retryConnWait):
-		case <-stream.Context().Done():
+		case <-stream.context().Done():
 			return
 		case <-l.donec:
 			return
@@ -599,3 +752,75 @@ func (ka *keepAlive) close() {
 		close(ch)
 	}
 }
+
+// keepAliveStream sends keep alive requests for leases and receives their responses.
+type keepAliveStream interface {
+	send(ids []LeaseID) error
+	recv() ([]*pb.LeaseKeepAliveResponse, error)
+	context() context.Context
+}
+
+// singleKeepAliveStream keeps leases alive one by one over LeaseKeepAlive.
+type singleKeepAliveStream struct {
+	s pb.Lease_LeaseKeepAliveClient
+}
+
+func (ks *singleKeepAliveStream) send(ids []LeaseID) error {
+	for _, id := range ids {
+		if err := ks.s.Send(&pb.LeaseKeepAliveRequest{ID: int64(id)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ks *singleKeepAliveStream) recv() ([]*pb.LeaseKeepAliveResponse, error) {
+	resp, err := ks.s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return []*pb.LeaseKeepAliveResponse{resp}, nil
+}
+
+func (ks *singleKeepAliveStream) context() context.Context { return ks.s.Context() }
+
+// batchKeepAliveStream coalesces the keep alives of many leases into
+// LeaseKeepAliveBatch requests.
+type batchKeepAliveStream struct {
+	s pb.Lease_LeaseKeepAliveBatchClient
+}
+
+func (ks *batchKeepAliveStream) send(ids []LeaseID) error {
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > maxKeepAliveBatch {
+			n = maxKeepAliveBatch
+		}
+		r := &pb.LeaseKeepAliveBatchRequest{IDs: make([]int64, n)}
+		for i, id := range ids[:n] {
+			r.IDs[i] = int64(id)
+		}
+		if err := ks.s.Send(r); err != nil {
+			return err
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+func (ks *batchKeepAliveStream) recv() ([]*pb.LeaseKeepAliveResponse, error) {
+	resp, err := ks.s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.TTLs) != len(resp.IDs) {
+		return nil, status.Errorf(codes.Internal, "lease keepalive batch response has %d TTLs for %d IDs", len(resp.TTLs), len(resp.IDs))
+	}
+	resps := make([]*pb.LeaseKeepAliveResponse, len(resp.IDs))
+	for i, id := range resp.IDs {
+		resps[i] = &pb.LeaseKeepAliveResponse{Header: resp.Header, ID: id, TTL: resp.TTLs[i]}
+	}
+	return resps, nil
+}
+
+func (ks *batchKeepAliveStream) context() context.Context { return ks.s.Context() }
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.uber.org/zap"
@@ -33,6 +34,28 @@ type (
 	HashKVResponse     pb.HashKVResponse
 	MoveLeaderResponse pb.MoveLeaderResponse
 	DowngradeResponse  pb.DowngradeResponse
+
+	RetentionPutResponse    pb.RetentionPutResponse
+	RetentionDeleteResponse pb.RetentionDeleteResponse
+	RetentionListResponse   pb.RetentionListResponse
+
+	ValueSchema          pb.ValueSchema
+	SchemaPutResponse    pb.SchemaPutResponse
+	SchemaGetResponse    pb.SchemaGetResponse
+	SchemaDeleteResponse pb.SchemaDeleteResponse
+	SchemaListResponse   pb.SchemaListResponse
+
+	TenantStatusResponse pb.TenantStatusResponse
+
+	ReloadConfigResponse pb.ReloadConfigResponse
+)
+
+const (
+	// ValueSchemaJSON schemas are JSON Schema documents the values must match.
+	ValueSchemaJSON = pb.ValueSchema_JSON
+	// ValueSchemaProtobuf schemas are serialized protobuf FileDescriptorSets
+	// and the name of the message type the values must decode to.
+	ValueSchemaProtobuf = pb.ValueSchema_PROTOBUF
 )
 
 type Maintenance interface {
@@ -82,6 +105,42 @@ type Maintenance interface {
 	// CANCEL = 2;
 	// Supported since etcd 3.5.
 	Downgrade(ctx context.Context, action int32, version string) (*DowngradeResponse, error)
+
+	// RetentionPut sets the history retention rule of the keys under prefix, which
+	// keeps the given number of most recent revisions of the keyspace, or the given
+	// duration of most recent history, of the keys when compacting. If both are
+	// given, the longest history of both is kept.
+	RetentionPut(ctx context.Context, prefix string, revisions int64, duration time.Duration) (*RetentionPutResponse, error)
+
+	// RetentionDelete deletes the history retention rule of the keys under prefix.
+	RetentionDelete(ctx context.Context, prefix string) (*RetentionDeleteResponse, error)
+
+	// RetentionList lists the history retention rules.
+	RetentionList(ctx context.Context) (*RetentionListResponse, error)
+
+	// SchemaPut sets the schema of the values written under its prefix. Writes of
+	// values that do not match the schema of the longest prefix of their key fail
+	// with rpctypes.ErrValueSchemaViolation.
+	SchemaPut(ctx context.Context, schema *ValueSchema) (*SchemaPutResponse, error)
+
+	// SchemaGet gets the schema of the values written under prefix.
+	SchemaGet(ctx context.Context, prefix string) (*SchemaGetResponse, error)
+
+	// SchemaDelete deletes the schema of the values written under prefix.
+	SchemaDelete(ctx context.Context, prefix string) (*SchemaDeleteResponse, error)
+
+	// SchemaList lists the value schemas.
+	SchemaList(ctx context.Context) (*SchemaListResponse, error)
+
+	// TenantStatus gets the users, the number of keys and the size of the keys
+	// and values of each tenant.
+	TenantStatus(ctx context.Context) (*TenantStatusResponse, error)
+
+	// ReloadConfig reloads the configuration of a given etcd member from its
+	// configuration file, applying the fields that can change while it runs.
+	// With dryRun, the configuration is only validated and the changed fields
+	// reported.
+	ReloadConfig(ctx context.Context, endpoint string, dryRun bool) (*ReloadConfigResponse, error)
 }
 
 // SnapshotResponse is aggregated response from the snapshot stream.
@@ -352,3 +411,61 @@ func (m *maintenance) Downgrade(ctx context.Context, action int32, version strin
 	resp, err := m.remote.Downgrade(ctx, &pb.DowngradeRequest{Action: actionType, Version: version}, m.callOpts...)
 	return (*DowngradeResponse)(resp), toErr(ctx, err)
 }
+
+func (m *maintenance) RetentionPut(ctx context.Context, prefix string, revisions int64, duration time.Duration) (*RetentionPutResponse, error) {
+	rule := &pb.RetentionRule{
+		Prefix:          []byte(prefix),
+		Revisions:       revisions,
+		DurationSeconds: int64(duration / time.Second),
+	}
+	resp, err := m.remote.RetentionPut(ctx, &pb.RetentionPutRequest{Rule: rule}, m.callOpts...)
+	return (*RetentionPutResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) RetentionDelete(ctx context.Context, prefix string) (*RetentionDeleteResponse, error) {
+	resp, err := m.remote.RetentionDelete(ctx, &pb.RetentionDeleteRequest{Prefix: []byte(prefix)}, m.callOpts...)
+	return (*RetentionDeleteResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) RetentionList(ctx context.Context) (*RetentionListResponse, error) {
+	resp, err := m.remote.RetentionList(ctx, &pb.RetentionListRequest{}, m.callOpts...)
+	return (*RetentionListResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) SchemaPut(ctx context.Context, schema *ValueSchema) (*SchemaPutResponse, error) {
+	resp, err := m.remote.SchemaPut(ctx, &pb.SchemaPutRequest{Schema: (*pb.ValueSchema)(schema)}, m.callOpts...)
+	return (*SchemaPutResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) SchemaGet(ctx context.Context, prefix string) (*SchemaGetResponse, error) {
+	resp, err := m.remote.SchemaGet(ctx, &pb.SchemaGetRequest{Prefix: []byte(prefix)}, m.callOpts...)
+	return (*SchemaGetResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) SchemaDelete(ctx context.Context, prefix string) (*SchemaDeleteResponse, error) {
+	resp, err := m.remote.SchemaDelete(ctx, &pb.SchemaDeleteRequest{Prefix: []byte(prefix)}, m.callOpts...)
+	return (*SchemaDeleteResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) SchemaList(ctx context.Context) (*SchemaListResponse, error) {
+	resp, err := m.remote.SchemaList(ctx, &pb.SchemaListRequest{}, m.callOpts...)
+	return (*SchemaListResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) TenantStatus(ctx context.Context) (*TenantStatusResponse, error) {
+	resp, err := m.remote.TenantStatus(ctx, &pb.TenantStatusRequest{}, m.callOpts...)
+	return (*TenantStatusResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) ReloadConfig(ctx context.Context, endpoint string, dryRun bool) (*ReloadConfigResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.ReloadConfig(ctx, &pb.ReloadConfigRequest{DryRun: dryRun}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*ReloadConfigResponse)(resp), nil
+}
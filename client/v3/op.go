@@ -14,7 +14,12 @@
 
 package clientv3
 
-import pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+import (
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
+)
 
 type opType int
 
@@ -26,8 +31,6 @@ const (
 	tTxn
 )
 
-var noPrefixEnd = []byte{0}
-
 // Op represents an Operation that kv can execute.
 type Op struct {
 	t   opType
@@ -45,6 +48,15 @@ type Op struct {
 	minCreateRev int64
 	maxCreateRev int64
 
+	// for range, secondary index selection
+	indexField    string
+	indexValue    []byte
+	indexValueEnd []byte
+
+	// for range, bounded staleness
+	minRevision  int64
+	maxStaleness time.Duration
+
 	// for range, watch
 	rev int64
 
@@ -56,6 +68,10 @@ type Op struct {
 	// if true, split watch events when total exceeds
 	// "--max-request-bytes" flag value + 512-byte
 	fragment bool
+	// coalesce the events of the keys the watcher cannot keep up with
+	coalesce bool
+	// maxEventsPerSecond caps the events sent per second to the watcher
+	maxEventsPerSecond int64
 
 	// for put
 	ignoreValue bool
@@ -136,6 +152,12 @@ func (op Op) MinCreateRev() int64 { return op.minCreateRev }
 // MaxCreateRev returns the operation's maximum create revision.
 func (op Op) MaxCreateRev() int64 { return op.maxCreateRev }
 
+// MinRevision returns the revision the member serving the operation must have applied.
+func (op Op) MinRevision() int64 { return op.minRevision }
+
+// MaxStaleness returns the operation's maximum staleness.
+func (op Op) MaxStaleness() time.Duration { return op.maxStaleness }
+
 // WithRangeBytes sets the byte slice for the Op's range end.
 func (op *Op) WithRangeBytes(end []byte) { op.end = end }
 
@@ -161,6 +183,11 @@ func (op Op) toRangeRequest() *pb.RangeRequest {
 		MaxModRevision:    op.maxModRev,
 		MinCreateRevision: op.minCreateRev,
 		MaxCreateRevision: op.maxCreateRev,
+		IndexField:        op.indexField,
+		IndexValue:        op.indexValue,
+		IndexValueEnd:     op.indexValueEnd,
+		MinRevision:       op.minRevision,
+		MaxStalenessMs:    op.maxStaleness.Milliseconds(),
 	}
 	if op.sort != nil {
 		r.SortOrder = pb.RangeRequest_SortOrder(op.sort.Order)
@@ -259,6 +286,8 @@ func OpDelete(key string, opts ...OpOption) Op {
 		panic("unexpected mod revision filter in delete")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in delete")
+	case ret.indexField != "":
+		panic("unexpected index in delete")
 	case ret.filterDelete, ret.filterPut:
 		panic("unexpected filter in delete")
 	case ret.createdNotify:
@@ -288,6 +317,8 @@ func OpPut(key, val string, opts ...OpOption) Op {
 		panic("unexpected mod revision filter in put")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in put")
+	case ret.indexField != "":
+		panic("unexpected index in put")
 	case ret.filterDelete, ret.filterPut:
 		panic("unexpected filter in put")
 	case ret.createdNotify:
@@ -319,6 +350,8 @@ func opWatch(key string, opts ...OpOption) Op {
 		panic("unexpected mod revision filter in watch")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in watch")
+	case ret.indexField != "":
+		panic("unexpected index in watch")
 	}
 	return ret
 }
@@ -366,22 +399,7 @@ func WithSort(target SortTarget, order SortOrder) OpOption {
 // GetPrefixRangeEnd gets the range end of the prefix.
 // 'Get(foo, WithPrefix())' is equal to 'Get(foo, WithRange(GetPrefixRangeEnd(foo))'.
 func GetPrefixRangeEnd(prefix string) string {
-	return string(getPrefix([]byte(prefix)))
-}
-
-func getPrefix(key []byte) []byte {
-	end := make([]byte, len(key))
-	copy(end, key)
-	for i := len(end) - 1; i >= 0; i-- {
-		if end[i] < 0xff {
-			end[i] = end[i] + 1
-			end = end[:i+1]
-			return end
-		}
-	}
-	// next prefix does not exist (e.g., 0xffff);
-	// default to WithFromKey policy
-	return noPrefixEnd
+	return string(keyutil.PrefixRangeEnd([]byte(prefix)))
 }
 
 // WithPrefix enables 'Get', 'Delete', or 'Watch' requests to operate
@@ -393,7 +411,7 @@ func WithPrefix() OpOption {
 			op.key, op.end = []byte{0}, []byte{0}
 			return
 		}
-		op.end = getPrefix(op.key)
+		op.end = keyutil.PrefixRangeEnd(op.key)
 		op.isOptsWithPrefix = true
 	}
 }
@@ -425,6 +443,21 @@ func WithSerializable() OpOption {
 	return func(op *Op) { op.serializable = true }
 }
 
+// WithMinRevision makes the 'Get' request serializable, served by a member
+// once it has applied the given revision. The member fails the request with
+// rpctypes.ErrStaleRead if it does not apply the revision in time.
+func WithMinRevision(rev int64) OpOption {
+	return func(op *Op) { op.serializable, op.minRevision = true, rev }
+}
+
+// WithMaxStaleness makes the 'Get' request serializable, served by a member
+// only if its data was up to date with the leader less than d ago. The member
+// fails the request with rpctypes.ErrStaleRead otherwise, which the client
+// retries, possibly on another endpoint. d is rounded down to milliseconds.
+func WithMaxStaleness(d time.Duration) OpOption {
+	return func(op *Op) { op.serializable, op.maxStaleness = true, d }
+}
+
 // WithKeysOnly makes the 'Get' request return only the keys and the corresponding
 // values will be omitted.
 func WithKeysOnly() OpOption {
@@ -448,6 +481,25 @@ func WithMinCreateRev(rev int64) OpOption { return func(op *Op) { op.minCreateRe
 // WithMaxCreateRev filters out keys for Get with creation revisions greater than the given revision.
 func WithMaxCreateRev(rev int64) OpOption { return func(op *Op) { op.maxCreateRev = rev } }
 
+// WithIndex selects the keys for Get whose value has the given value in the
+// indexed JSON field. The results are ordered by key. The requested range must
+// be covered by a secondary index on the field configured on the server.
+func WithIndex(field, value string) OpOption {
+	return func(op *Op) {
+		op.indexField, op.indexValue, op.indexValueEnd = field, []byte(value), nil
+	}
+}
+
+// WithIndexRange selects the keys for Get whose value holds a value in
+// [value, valueEnd) in the indexed JSON field. If valueEnd is "\x00", all
+// values greater than or equal to value are selected. The results are ordered
+// by the indexed value, then by key.
+func WithIndexRange(field, value, valueEnd string) OpOption {
+	return func(op *Op) {
+		op.indexField, op.indexValue, op.indexValueEnd = field, []byte(value), []byte(valueEnd)
+	}
+}
+
 // WithFirstCreate gets the key with the oldest creation revision in the request range.
 func WithFirstCreate() []OpOption { return withTop(SortByCreateRevision, SortAscend) }
 
@@ -516,6 +568,21 @@ func WithFragment() OpOption {
 	return func(op *Op) { op.fragment = true }
 }
 
+// WithCoalesce makes the watcher coalesce events: while the watcher cannot keep
+// up with the events of its range, the server keeps only the latest event of each
+// key not sent yet, and marks the events replacing older ones with Coalesced.
+// The intermediate values of the keys are not received.
+func WithCoalesce() OpOption {
+	return func(op *Op) { op.coalesce = true }
+}
+
+// WithMaxEventsPerSecond caps the events sent per second to the watcher, coalescing
+// the events over the rate like WithCoalesce. The events of a revision are always
+// sent together.
+func WithMaxEventsPerSecond(n int64) OpOption {
+	return func(op *Op) { op.coalesce, op.maxEventsPerSecond = true, n }
+}
+
 // WithIgnoreValue updates the key using its current value.
 // This option can not be combined with non-empty values.
 // Returns an error if the key does not exist.
@@ -540,6 +607,10 @@ type LeaseOp struct {
 
 	// for TimeToLive
 	attachedKeys bool
+
+	// for WatchEvents
+	watchIDs       []LeaseID
+	renewThreshold int64
 }
 
 // LeaseOption configures lease operations.
@@ -556,6 +627,27 @@ func WithAttachedKeys() LeaseOption {
 	return func(op *LeaseOp) { op.attachedKeys = true }
 }
 
+// WithLeaseIDs makes WatchEvents only report the events of the given leases.
+func WithLeaseIDs(ids ...LeaseID) LeaseOption {
+	return func(op *LeaseOp) { op.watchIDs = append(op.watchIDs, ids...) }
+}
+
+// WithRenewThreshold makes WatchEvents report the renewals of leases whose remaining
+// TTL was at most the given number of seconds when they were renewed.
+func WithRenewThreshold(ttl int64) LeaseOption {
+	return func(op *LeaseOp) { op.renewThreshold = ttl }
+}
+
+func toLeaseWatchRequest(opts ...LeaseOption) *pb.LeaseWatchRequest {
+	ret := &LeaseOp{}
+	ret.applyOpts(opts)
+	ids := make([]int64, len(ret.watchIDs))
+	for i, id := range ret.watchIDs {
+		ids[i] = int64(id)
+	}
+	return &pb.LeaseWatchRequest{IDs: ids, RenewThreshold: ret.renewThreshold}
+}
+
 func toLeaseTimeToLiveRequest(id LeaseID, opts ...LeaseOption) *pb.LeaseTimeToLiveRequest {
 	ret := &LeaseOp{id: id}
 	ret.applyOpts(opts)
@@ -17,6 +17,7 @@ package clientv3
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 )
@@ -37,6 +38,16 @@ func TestOpWithSort(t *testing.T) {
 	}
 }
 
+// TestOpWithBoundedStaleness tests if WithMinRevision and WithMaxStaleness
+// make the RangeRequest serializable with the bounds.
+func TestOpWithBoundedStaleness(t *testing.T) {
+	req := OpGet("foo", WithMinRevision(5), WithMaxStaleness(1500*time.Millisecond)).toRangeRequest()
+	wreq := &pb.RangeRequest{Key: []byte("foo"), Serializable: true, MinRevision: 5, MaxStalenessMs: 1500}
+	if !reflect.DeepEqual(req, wreq) {
+		t.Fatalf("expected %+v, got %+v", wreq, req)
+	}
+}
+
 func TestIsSortOptionValid(t *testing.T) {
 	rangeReqs := []struct {
 		sortOrder     pb.RangeRequest_SortOrder
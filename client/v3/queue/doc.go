@@ -0,0 +1,52 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package queue implements a durable work queue on top of etcd.
+//
+// Unlike a plain queue, dequeuing an item does not delete it. Instead the item
+// is claimed under a lease owned by the worker. The worker acknowledges the
+// item with Ack once it is processed, which removes it from the queue. If the
+// worker dies, or does not finish within the visibility timeout, the claim
+// lease expires and the item is returned to the queue for another worker.
+// Items that were dequeued too many times without being acknowledged are moved
+// to a dead-letter set, from which they can be inspected and redriven.
+//
+// First, create a session and a queue:
+//
+//	s, err := concurrency.NewSession(cli)
+//	if err != nil {
+//		// handle error!
+//	}
+//	q := queue.NewQueue(s, "/jobs", queue.WithMaxAttempts(5))
+//
+// Producers enqueue items:
+//
+//	id, err := q.Enqueue(ctx, "payload")
+//
+// Workers dequeue, process and acknowledge them:
+//
+//	item, err := q.Dequeue(ctx)
+//	if err != nil {
+//		// handle error!
+//	}
+//	if err = process(item.Value); err != nil {
+//		item.Nack(ctx)
+//	} else {
+//		item.Ack(ctx)
+//	}
+//
+// The queue keeps its state under the given prefix: item payloads under
+// "items/", the dequeue order under "pending/", claims under "claims/" and
+// dead letters under "dead/". Applications should not modify these keys.
+package queue
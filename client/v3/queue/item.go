@@ -0,0 +1,83 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	v3 "go.etcd.io/etcd/client/v3"
+)
+
+// Item is a queue item claimed by a worker.
+type Item struct {
+	// ID identifies the item within its queue.
+	ID string
+	// Value is the payload of the item.
+	Value string
+	// Attempts is the number of times the item has been dequeued,
+	// including the current claim.
+	Attempts int
+
+	q        *Queue
+	claimRev int64
+	lease    v3.LeaseID
+	ownLease bool
+}
+
+func (it *Item) isClaimed() v3.Cmp {
+	return v3.Compare(v3.CreateRevision(it.q.claimKey(it.ID)), "=", it.claimRev)
+}
+
+// Ack acknowledges that the item was processed and removes it from the queue.
+// It returns ErrClaimLost if the claim expired before the acknowledgement.
+func (it *Item) Ack(ctx context.Context) error {
+	return it.release(ctx, v3.OpDelete(it.q.itemKey(it.ID)))
+}
+
+// Nack gives up the claim and returns the item to the end of the queue
+// without waiting for the claim to expire.
+func (it *Item) Nack(ctx context.Context) error {
+	return it.release(ctx, v3.OpPut(it.q.pendingKey(it.ID), ""))
+}
+
+func (it *Item) release(ctx context.Context, op v3.Op) error {
+	client := it.q.s.Client()
+	resp, err := client.Txn(ctx).If(it.isClaimed()).Then(op, v3.OpDelete(it.q.claimKey(it.ID))).Commit()
+	if err != nil {
+		return err
+	}
+	if it.ownLease {
+		// the lease only guards this claim; let it go with the claim
+		client.Revoke(ctx, it.lease)
+	}
+	if !resp.Succeeded {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Extend renews the visibility timeout of the item. It is a no-op for claims
+// bound to the session lease, which is kept alive by the session.
+func (it *Item) Extend(ctx context.Context) error {
+	if !it.ownLease {
+		return nil
+	}
+	_, err := it.q.s.Client().KeepAliveOnce(ctx, it.lease)
+	if err == rpctypes.ErrLeaseNotFound {
+		return ErrClaimLost
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import "time"
+
+type queueOptions struct {
+	visibilityTimeout time.Duration
+	maxAttempts       int
+}
+
+// Option configures Queue.
+type Option func(*queueOptions)
+
+// WithVisibilityTimeout bounds how long a dequeued item stays claimed. Each
+// claim is bound to its own lease with the given TTL, rounded up to whole
+// seconds, instead of the session lease, so an item is returned to the queue
+// even if the worker stays alive but does not acknowledge it in time.
+// Use Item.Extend to renew the timeout while processing.
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(qo *queueOptions) {
+		qo.visibilityTimeout = d
+	}
+}
+
+// WithMaxAttempts moves items to the dead letters once they were dequeued n
+// times without being acknowledged. If n is <= 0, items are retried forever.
+func WithMaxAttempts(n int) Option {
+	return func(qo *queueOptions) {
+		qo.maxAttempts = n
+	}
+}
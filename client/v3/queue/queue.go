@@ -0,0 +1,454 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	v3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+const (
+	itemsPrefix   = "items/"
+	pendingPrefix = "pending/"
+	claimsPrefix  = "claims/"
+	deadPrefix    = "dead/"
+)
+
+var (
+	// ErrEmpty is returned by TryDequeue when there are no pending items.
+	ErrEmpty = errors.New("queue: empty")
+	// ErrClaimLost is returned when acknowledging or extending an item whose
+	// claim expired, so that the item may have been handed to another worker.
+	ErrClaimLost = errors.New("queue: claim lost")
+	// ErrItemNotFound is returned by Redrive when there is no dead letter
+	// with the given ID.
+	ErrItemNotFound = errors.New("queue: item not found")
+)
+
+// envelope is the stored representation of an item.
+type envelope struct {
+	Value    []byte `json:"value"`
+	Attempts int    `json:"attempts"`
+}
+
+// Queue is a durable work queue whose items are claimed under a lease and
+// removed only when acknowledged.
+type Queue struct {
+	s    *concurrency.Session
+	pfx  string
+	opts *queueOptions
+}
+
+// NewQueue creates a queue on the given prefix. Claims are bound to the
+// session lease unless a visibility timeout is configured. Until the session
+// is done, the queue returns the items of the claims that expire to the queue.
+func NewQueue(s *concurrency.Session, pfx string, opts ...Option) *Queue {
+	ops := &queueOptions{}
+	for _, opt := range opts {
+		opt(ops)
+	}
+	q := &Queue{s: s, pfx: pfx + "/", opts: ops}
+	go q.recoverLoop()
+	return q
+}
+
+func (q *Queue) itemKey(id string) string    { return q.pfx + itemsPrefix + id }
+func (q *Queue) pendingKey(id string) string { return q.pfx + pendingPrefix + id }
+func (q *Queue) claimKey(id string) string   { return q.pfx + claimsPrefix + id }
+func (q *Queue) deadKey(id string) string    { return q.pfx + deadPrefix + id }
+
+// Enqueue adds a value to the end of the queue and returns the ID of the new item.
+func (q *Queue) Enqueue(ctx context.Context, val string) (string, error) {
+	data, err := json.Marshal(&envelope{Value: []byte(val)})
+	if err != nil {
+		return "", err
+	}
+	client := q.s.Client()
+	for {
+		id := fmt.Sprintf("%016x", time.Now().UnixNano())
+		cmp := v3.Compare(v3.CreateRevision(q.itemKey(id)), "=", 0)
+		resp, err := client.Txn(ctx).If(cmp).Then(
+			v3.OpPut(q.itemKey(id), string(data)),
+			v3.OpPut(q.pendingKey(id), ""),
+		).Commit()
+		if err != nil {
+			return "", err
+		}
+		if resp.Succeeded {
+			return id, nil
+		}
+	}
+}
+
+// TryDequeue claims the oldest pending item without waiting. It returns
+// ErrEmpty if there are no pending items.
+func (q *Queue) TryDequeue(ctx context.Context) (*Item, error) {
+	it, _, err := q.tryDequeue(ctx)
+	return it, err
+}
+
+// Dequeue claims the oldest pending item, waiting for one to be enqueued or
+// returned to the queue if necessary.
+func (q *Queue) Dequeue(ctx context.Context) (*Item, error) {
+	for {
+		it, rev, err := q.tryDequeue(ctx)
+		if err != ErrEmpty {
+			return it, err
+		}
+		// pick up items orphaned while nobody was watching
+		n, err := q.Recover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			continue
+		}
+		if err = q.waitPending(ctx, rev+1); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryDequeue claims the oldest pending item. If the queue is empty, it
+// returns ErrEmpty and the revision at which the queue was observed empty.
+func (q *Queue) tryDequeue(ctx context.Context) (*Item, int64, error) {
+	client := q.s.Client()
+	for {
+		resp, err := client.Get(ctx, q.pfx+pendingPrefix, v3.WithFirstCreate()...)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(resp.Kvs) == 0 {
+			return nil, resp.Header.Revision, ErrEmpty
+		}
+		pending := resp.Kvs[0]
+		id := strings.TrimPrefix(string(pending.Key), q.pfx+pendingPrefix)
+
+		iresp, err := client.Get(ctx, q.itemKey(id))
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(iresp.Kvs) == 0 {
+			// stale pending marker of an acknowledged item
+			client.Txn(ctx).If(v3.Compare(v3.ModRevision(string(pending.Key)), "=", pending.ModRevision)).
+				Then(v3.OpDelete(string(pending.Key))).Commit()
+			continue
+		}
+		var env envelope
+		if err = json.Unmarshal(iresp.Kvs[0].Value, &env); err != nil {
+			return nil, 0, err
+		}
+
+		cmps := []v3.Cmp{
+			v3.Compare(v3.ModRevision(string(pending.Key)), "=", pending.ModRevision),
+			v3.Compare(v3.ModRevision(q.itemKey(id)), "=", iresp.Kvs[0].ModRevision),
+		}
+		if q.opts.maxAttempts > 0 && env.Attempts >= q.opts.maxAttempts {
+			// out of attempts; move the item to the dead letters
+			_, err = client.Txn(ctx).If(cmps...).Then(
+				v3.OpDelete(string(pending.Key)),
+				v3.OpDelete(q.itemKey(id)),
+				v3.OpPut(q.deadKey(id), string(iresp.Kvs[0].Value)),
+			).Commit()
+			if err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		lease, ownLease, err := q.claimLease(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		env.Attempts++
+		data, err := json.Marshal(&env)
+		if err != nil {
+			return nil, 0, err
+		}
+		tresp, err := client.Txn(ctx).If(cmps...).Then(
+			v3.OpDelete(string(pending.Key)),
+			v3.OpPut(q.itemKey(id), string(data)),
+			v3.OpPut(q.claimKey(id), "", v3.WithLease(lease)),
+		).Commit()
+		if err != nil || !tresp.Succeeded {
+			if ownLease {
+				client.Revoke(client.Ctx(), lease)
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+			// another worker claimed the item first
+			continue
+		}
+		return &Item{
+			ID:       id,
+			Value:    string(env.Value),
+			Attempts: env.Attempts,
+			q:        q,
+			claimRev: tresp.Header.Revision,
+			lease:    lease,
+			ownLease: ownLease,
+		}, 0, nil
+	}
+}
+
+// claimLease returns the lease to claim an item with and whether it is a
+// dedicated lease that must be revoked when the claim ends.
+func (q *Queue) claimLease(ctx context.Context) (v3.LeaseID, bool, error) {
+	if q.opts.visibilityTimeout <= 0 {
+		return q.s.Lease(), false, nil
+	}
+	ttl := int64(math.Ceil(q.opts.visibilityTimeout.Seconds()))
+	resp, err := q.s.Client().Grant(ctx, ttl)
+	if err != nil {
+		return v3.NoLease, false, err
+	}
+	return resp.ID, true, nil
+}
+
+// waitPending waits until an item may have become pending at or after the
+// given revision. Expired claims observed while waiting are returned to the
+// queue.
+func (q *Queue) waitPending(ctx context.Context, rev int64) error {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wr v3.WatchResponse
+	wch := q.s.Client().Watch(cctx, q.pfx, v3.WithPrefix(), v3.WithRev(rev))
+	for wr = range wch {
+		for _, ev := range wr.Events {
+			key := string(ev.Kv.Key)
+			switch {
+			case ev.Type == mvccpb.PUT && strings.HasPrefix(key, q.pfx+pendingPrefix):
+				return nil
+			case ev.Type == mvccpb.DELETE && strings.HasPrefix(key, q.pfx+claimsPrefix):
+				restored, err := q.restore(ctx, strings.TrimPrefix(key, q.pfx+claimsPrefix))
+				if err != nil || restored {
+					return err
+				}
+			}
+		}
+	}
+	if err := wr.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("lost watcher waiting for pending items")
+}
+
+// restore returns an item that is neither pending nor claimed to the queue.
+func (q *Queue) restore(ctx context.Context, id string) (bool, error) {
+	resp, err := q.s.Client().Txn(ctx).If(
+		v3.Compare(v3.Version(q.itemKey(id)), ">", 0),
+		v3.Compare(v3.Version(q.pendingKey(id)), "=", 0),
+		v3.Compare(v3.Version(q.claimKey(id)), "=", 0),
+	).Then(v3.OpPut(q.pendingKey(id), "")).Commit()
+	if err != nil {
+		return false, err
+	}
+	return resp.Succeeded, nil
+}
+
+// recoverRetryInterval is the time waited before recovering the expired
+// claims again after a failure.
+const recoverRetryInterval = time.Second
+
+// recoverLoop returns the items of the claims expiring, however many items
+// are pending, until the session is done.
+func (q *Queue) recoverLoop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-q.s.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		// the watch only ends on failures, which a later watch recovers from
+		q.watchClaims(ctx)
+		select {
+		case <-time.After(recoverRetryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchClaims returns the items whose claim expired, then the item of every
+// claim deleted, until the watch fails or ctx is done.
+func (q *Queue) watchClaims(ctx context.Context) error {
+	_, rev, err := q.recover(ctx)
+	if err != nil {
+		return err
+	}
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wr v3.WatchResponse
+	wch := q.s.Client().Watch(wctx, q.pfx+claimsPrefix, v3.WithPrefix(), v3.WithRev(rev+1), v3.WithFilterPut())
+	for wr = range wch {
+		for _, ev := range wr.Events {
+			// the claims of acknowledged items are deleted with the items
+			if _, err = q.restore(ctx, strings.TrimPrefix(string(ev.Kv.Key), q.pfx+claimsPrefix)); err != nil {
+				return err
+			}
+		}
+	}
+	if err = wr.Err(); err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("lost watcher waiting for expired claims")
+}
+
+// Recover returns all items whose claim expired while no worker was waiting
+// on the queue, and reports how many items were returned.
+func (q *Queue) Recover(ctx context.Context) (int, error) {
+	n, _, err := q.recover(ctx)
+	return n, err
+}
+
+// recover returns all items whose claim expired, and reports how many items
+// were returned and the revision the claims were read at.
+func (q *Queue) recover(ctx context.Context) (int, int64, error) {
+	resp, err := q.s.Client().Txn(ctx).Then(
+		v3.OpGet(q.pfx+itemsPrefix, v3.WithPrefix(), v3.WithKeysOnly()),
+		v3.OpGet(q.pfx+pendingPrefix, v3.WithPrefix(), v3.WithKeysOnly()),
+		v3.OpGet(q.pfx+claimsPrefix, v3.WithPrefix(), v3.WithKeysOnly()),
+	).Commit()
+	if err != nil {
+		return 0, 0, err
+	}
+	live := make(map[string]struct{})
+	for i, pfx := range []string{pendingPrefix, claimsPrefix} {
+		for _, kv := range resp.Responses[i+1].GetResponseRange().Kvs {
+			live[strings.TrimPrefix(string(kv.Key), q.pfx+pfx)] = struct{}{}
+		}
+	}
+	n := 0
+	for _, kv := range resp.Responses[0].GetResponseRange().Kvs {
+		id := strings.TrimPrefix(string(kv.Key), q.pfx+itemsPrefix)
+		if _, ok := live[id]; ok {
+			continue
+		}
+		restored, err := q.restore(ctx, id)
+		if err != nil {
+			return n, 0, err
+		}
+		if restored {
+			n++
+		}
+	}
+	return n, resp.Header.Revision, nil
+}
+
+// Stats is a snapshot of the number of items in each state.
+type Stats struct {
+	// Pending is the number of items waiting to be dequeued.
+	Pending int64
+	// Claimed is the number of items dequeued but not yet acknowledged.
+	Claimed int64
+	// Dead is the number of dead letters.
+	Dead int64
+}
+
+// Stats returns the number of items in each state.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	resp, err := q.s.Client().Txn(ctx).Then(
+		v3.OpGet(q.pfx+pendingPrefix, v3.WithPrefix(), v3.WithCountOnly()),
+		v3.OpGet(q.pfx+claimsPrefix, v3.WithPrefix(), v3.WithCountOnly()),
+		v3.OpGet(q.pfx+deadPrefix, v3.WithPrefix(), v3.WithCountOnly()),
+	).Commit()
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		Pending: resp.Responses[0].GetResponseRange().Count,
+		Claimed: resp.Responses[1].GetResponseRange().Count,
+		Dead:    resp.Responses[2].GetResponseRange().Count,
+	}, nil
+}
+
+// DeadLetters returns the items that ran out of attempts.
+func (q *Queue) DeadLetters(ctx context.Context) ([]*Item, error) {
+	resp, err := q.s.Client().Get(ctx, q.pfx+deadPrefix, v3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Item, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var env envelope
+		if err = json.Unmarshal(kv.Value, &env); err != nil {
+			return nil, err
+		}
+		items = append(items, &Item{
+			ID:       strings.TrimPrefix(string(kv.Key), q.pfx+deadPrefix),
+			Value:    string(env.Value),
+			Attempts: env.Attempts,
+		})
+	}
+	return items, nil
+}
+
+// Redrive moves a dead letter back to the end of the queue with its
+// attempts reset.
+func (q *Queue) Redrive(ctx context.Context, id string) error {
+	client := q.s.Client()
+	resp, err := client.Get(ctx, q.deadKey(id))
+	if err != nil {
+		return err
+	}
+	if len(resp.Kvs) == 0 {
+		return ErrItemNotFound
+	}
+	var env envelope
+	if err = json.Unmarshal(resp.Kvs[0].Value, &env); err != nil {
+		return err
+	}
+	env.Attempts = 0
+	data, err := json.Marshal(&env)
+	if err != nil {
+		return err
+	}
+	tresp, err := client.Txn(ctx).If(
+		v3.Compare(v3.ModRevision(q.deadKey(id)), "=", resp.Kvs[0].ModRevision),
+	).Then(
+		v3.OpDelete(q.deadKey(id)),
+		v3.OpPut(q.itemKey(id), string(data)),
+		v3.OpPut(q.pendingKey(id), ""),
+	).Commit()
+	if err != nil {
+		return err
+	}
+	if !tresp.Succeeded {
+		return ErrItemNotFound
+	}
+	return nil
+}
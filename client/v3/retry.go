@@ -148,10 +148,26 @@ func (rlc *retryLeaseClient) LeaseRevoke(ctx context.Context, in *pb.LeaseRevoke
 	return rlc.lc.LeaseRevoke(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) LeaseUpdate(ctx context.Context, in *pb.LeaseUpdateRequest, opts ...grpc.CallOption) (resp *pb.LeaseUpdateResponse, err error) {
+	return rlc.lc.LeaseUpdate(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rlc *retryLeaseClient) LeaseMove(ctx context.Context, in *pb.LeaseMoveRequest, opts ...grpc.CallOption) (resp *pb.LeaseMoveResponse, err error) {
+	return rlc.lc.LeaseMove(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rlc *retryLeaseClient) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (stream pb.Lease_LeaseKeepAliveClient, err error) {
 	return rlc.lc.LeaseKeepAlive(ctx, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) LeaseWatch(ctx context.Context, in *pb.LeaseWatchRequest, opts ...grpc.CallOption) (stream pb.Lease_LeaseWatchClient, err error) {
+	return rlc.lc.LeaseWatch(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rlc *retryLeaseClient) LeaseKeepAliveBatch(ctx context.Context, opts ...grpc.CallOption) (stream pb.Lease_LeaseKeepAliveBatchClient, err error) {
+	return rlc.lc.LeaseKeepAliveBatch(ctx, append(opts, withRetryPolicy(repeatable))...)
+}
+
 type retryClusterClient struct {
 	cc pb.ClusterClient
 }
@@ -226,6 +242,42 @@ func (rmc *retryMaintenanceClient) Downgrade(ctx context.Context, in *pb.Downgra
 	return rmc.mc.Downgrade(ctx, in, opts...)
 }
 
+func (rmc *retryMaintenanceClient) RetentionPut(ctx context.Context, in *pb.RetentionPutRequest, opts ...grpc.CallOption) (resp *pb.RetentionPutResponse, err error) {
+	return rmc.mc.RetentionPut(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) RetentionDelete(ctx context.Context, in *pb.RetentionDeleteRequest, opts ...grpc.CallOption) (resp *pb.RetentionDeleteResponse, err error) {
+	return rmc.mc.RetentionDelete(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) RetentionList(ctx context.Context, in *pb.RetentionListRequest, opts ...grpc.CallOption) (resp *pb.RetentionListResponse, err error) {
+	return rmc.mc.RetentionList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) SchemaPut(ctx context.Context, in *pb.SchemaPutRequest, opts ...grpc.CallOption) (resp *pb.SchemaPutResponse, err error) {
+	return rmc.mc.SchemaPut(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) SchemaGet(ctx context.Context, in *pb.SchemaGetRequest, opts ...grpc.CallOption) (resp *pb.SchemaGetResponse, err error) {
+	return rmc.mc.SchemaGet(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) SchemaDelete(ctx context.Context, in *pb.SchemaDeleteRequest, opts ...grpc.CallOption) (resp *pb.SchemaDeleteResponse, err error) {
+	return rmc.mc.SchemaDelete(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) SchemaList(ctx context.Context, in *pb.SchemaListRequest, opts ...grpc.CallOption) (resp *pb.SchemaListResponse, err error) {
+	return rmc.mc.SchemaList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) TenantStatus(ctx context.Context, in *pb.TenantStatusRequest, opts ...grpc.CallOption) (resp *pb.TenantStatusResponse, err error) {
+	return rmc.mc.TenantStatus(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) ReloadConfig(ctx context.Context, in *pb.ReloadConfigRequest, opts ...grpc.CallOption) (resp *pb.ReloadConfigResponse, err error) {
+	return rmc.mc.ReloadConfig(ctx, in, opts...)
+}
+
 type retryAuthClient struct {
 	ac pb.AuthClient
 }
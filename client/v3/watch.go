@@ -204,6 +204,10 @@ type watchRequest struct {
 	// if true, split watch events when total exceeds
 	// "--max-request-bytes" flag value + 512-byte
 	fragment bool
+	// coalesce the events of the keys the watcher cannot keep up with
+	coalesce bool
+	// maxEventsPerSecond caps the events sent per second to the watcher
+	maxEventsPerSecond int64
 
 	// filters is the list of events to filter out
 	filters []pb.WatchCreateRequest_FilterType
@@ -299,16 +303,18 @@ func (w *watcher) Watch(ctx context.Context, key string, opts ...OpOption) Watch
 	}
 
 	wr := &watchRequest{
-		ctx:            ctx,
-		createdNotify:  ow.createdNotify,
-		key:            string(ow.key),
-		end:            string(ow.end),
-		rev:            ow.rev,
-		progressNotify: ow.progressNotify,
-		fragment:       ow.fragment,
-		filters:        filters,
-		prevKV:         ow.prevKV,
-		retc:           make(chan chan WatchResponse, 1),
+		ctx:                ctx,
+		createdNotify:      ow.createdNotify,
+		key:                string(ow.key),
+		end:                string(ow.end),
+		rev:                ow.rev,
+		progressNotify:     ow.progressNotify,
+		fragment:           ow.fragment,
+		coalesce:           ow.coalesce,
+		maxEventsPerSecond: ow.maxEventsPerSecond,
+		filters:            filters,
+		prevKV:             ow.prevKV,
+		retc:               make(chan chan WatchResponse, 1),
 	}
 
 	ok := false
@@ -1001,13 +1007,15 @@ func (w *watchGrpcStream) openWatchClient() (ws pb.Watch_WatchClient, err error)
 // toPB converts an internal watch request structure to its protobuf WatchRequest structure.
 func (wr *watchRequest) toPB() *pb.WatchRequest {
 	req := &pb.WatchCreateRequest{
-		StartRevision:  wr.rev,
-		Key:            []byte(wr.key),
-		RangeEnd:       []byte(wr.end),
-		ProgressNotify: wr.progressNotify,
-		Filters:        wr.filters,
-		PrevKv:         wr.prevKV,
-		Fragment:       wr.fragment,
+		StartRevision:      wr.rev,
+		Key:                []byte(wr.key),
+		RangeEnd:           []byte(wr.end),
+		ProgressNotify:     wr.progressNotify,
+		Filters:            wr.filters,
+		PrevKv:             wr.prevKV,
+		Fragment:           wr.fragment,
+		Coalesce:           wr.coalesce,
+		MaxEventsPerSecond: wr.maxEventsPerSecond,
 	}
 	cr := &pb.WatchRequest_CreateRequest{CreateRequest: req}
 	return &pb.WatchRequest{RequestUnion: cr}
@@ -0,0 +1,96 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyPrune     bool
+	applyDryRun    bool
+	applyMaxTxnOps int
+)
+
+// NewApplyCommand returns the cobra command for "apply".
+func NewApplyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply [options] <file> <prefix>",
+		Short: "Applies a YAML or JSON key tree to the keys under a prefix",
+		Long: `Applies a key tree, as written by the "export" command, to the keys under a
+prefix, and prints the changes as "diff" does. The file "-" reads stdin.
+
+The changes are made in one transaction which fails if any key under the
+prefix was modified since it was read. If there are more changes than
+--max-txn-ops, they are split into several transactions, each failing if the
+prefix was modified since the previous one; the changes are then not atomic.
+`,
+		Run: applyCommandFunc,
+	}
+	cmd.Flags().BoolVar(&applyPrune, "prune", false, "Delete the keys under the prefix missing from the file")
+	cmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Print the changes without applying them")
+	cmd.Flags().IntVar(&applyMaxTxnOps, "max-txn-ops", 128, "Maximum number of operations per transaction; must not exceed the server's --max-txn-ops")
+	return cmd
+}
+
+// applyCommandFunc executes the "apply" command.
+func applyCommandFunc(cmd *cobra.Command, args []string) {
+	if applyMaxTxnOps < 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--max-txn-ops must be positive"))
+	}
+	changes, rev := keyTreeChanges(cmd, args, applyPrune)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if applyDryRun || len(changes) == 0 {
+		return
+	}
+
+	prefix := args[1]
+	c := mustClientFromCmd(cmd)
+	applied, txns := 0, 0
+	for applied < len(changes) {
+		n := len(changes) - applied
+		if n > applyMaxTxnOps {
+			n = applyMaxTxnOps
+		}
+		ops := make([]clientv3.Op, n)
+		for i := range ops {
+			ops[i] = changes[applied+i].op()
+		}
+
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(prefix).WithPrefix(), "<", rev+1)).
+			Then(ops...).
+			Commit()
+		cancel()
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("%v (%d of %d changes applied)", err, applied, len(changes)))
+		}
+		if !resp.Succeeded {
+			cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("keys under %q were modified after revision %d (%d of %d changes applied)", prefix, rev, applied, len(changes)))
+		}
+		rev = resp.Header.Revision
+		applied += n
+		txns++
+	}
+	fmt.Printf("applied %d changes in %d transaction(s) at revision %d\n", applied, txns, rev)
+}
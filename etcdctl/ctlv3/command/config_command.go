@@ -0,0 +1,96 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	configReloadDryRun bool
+)
+
+// NewConfigCommand returns the cobra command for "config".
+func NewConfigCommand() *cobra.Command {
+	cc := &cobra.Command{
+		Use:   "config <subcommand>",
+		Short: "Member configuration related commands",
+	}
+
+	cc.AddCommand(NewConfigReloadCommand())
+
+	return cc
+}
+
+func NewConfigReloadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reload",
+		Short: "Reloads the configuration file of the etcd members with given endpoints",
+		Long: `Reloads the configuration file of the etcd members with given endpoints.
+
+The members apply the changes of the log level, the CORS origins, the host
+whitelist, the cipher suites, the client certificate, key and trusted CA files,
+and the client certificate auth rules file. The changes of the other fields are
+reported as ignored: they need a restart of the member.
+`,
+		Run: configReloadCommandFunc,
+	}
+	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	cmd.Flags().BoolVar(&configReloadDryRun, "dry-run", false, "validate the configuration file and report the changes without applying them")
+	return cmd
+}
+
+// configReloadCommandFunc executes the "config reload" command.
+func configReloadCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("config reload command accepts no arguments"))
+	}
+
+	failures := 0
+	c := mustClientFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.ReloadConfig(ctx, ep, configReloadDryRun)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to reload the configuration of etcd member[%s]. (%v)\n", ep, err)
+			failures++
+			continue
+		}
+		verb := "Reloaded"
+		if configReloadDryRun {
+			verb = "Checked"
+		}
+		fmt.Printf("%s the configuration of etcd member[%s]. generation: %d\n", verb, ep, resp.Generation)
+		fmt.Printf("  applied: %s\n", fieldList(resp.Applied))
+		fmt.Printf("  ignored (restart required): %s\n", fieldList(resp.Ignored))
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
+
+func fieldList(fields []string) string {
+	if len(fields) == 0 {
+		return "none"
+	}
+	return strings.Join(fields, ", ")
+}
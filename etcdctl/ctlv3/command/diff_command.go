@@ -0,0 +1,73 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+
+	"github.com/spf13/cobra"
+)
+
+var diffPrune bool
+
+// NewDiffCommand returns the cobra command for "diff".
+func NewDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [options] <file> <prefix>",
+		Short: "Compares a YAML or JSON key tree with the keys under a prefix",
+		Long: `Compares a key tree, as written by the "export" command, with the keys under
+a prefix, and prints the changes "apply" would make: "+" for keys to create,
+"~" for keys to update and "-" for keys to delete. The file "-" reads stdin.
+`,
+		Run: diffCommandFunc,
+	}
+	cmd.Flags().BoolVar(&diffPrune, "prune", false, "Show keys missing from the file as deleted")
+	return cmd
+}
+
+// diffCommandFunc executes the "diff" command.
+func diffCommandFunc(cmd *cobra.Command, args []string) {
+	changes, _ := keyTreeChanges(cmd, args, diffPrune)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+}
+
+// keyTreeChanges returns the changes applying the tree file in args[0] to the
+// prefix in args[1], and the revision of the current keys.
+func keyTreeChanges(cmd *cobra.Command, args []string, prune bool) ([]keyChange, int64) {
+	if len(args) != 2 || args[1] == "" {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("%s command needs a file and a non-empty prefix as arguments", cmd.Name()))
+	}
+	tree, err := readKeyTreeFile(args[0])
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitInvalidInput, err)
+	}
+	desired, err := tree.flatten(args[1])
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitInvalidInput, err)
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Get(ctx, args[1], clientv3.WithPrefix())
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	return diffKeys(kvsToMap(resp.Kvs), desired, prune), resp.Header.Revision
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportRev    int64
+)
+
+// NewExportCommand returns the cobra command for "export".
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [options] <prefix>",
+		Short: "Exports the keys under a prefix as a YAML or JSON tree",
+		Long: `Exports the keys under a prefix as a tree of nested maps, splitting the keys
+relative to the prefix on "/". The output can be edited and given to the
+"diff" and "apply" commands.
+`,
+		Run: exportCommandFunc,
+	}
+	cmd.Flags().StringVar(&exportFormat, "format", "yaml", "Output format; yaml or json")
+	cmd.Flags().Int64Var(&exportRev, "rev", 0, "Specify the kv revision")
+	cmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"yaml", "json"}, cobra.ShellCompDirectiveDefault
+	})
+	return cmd
+}
+
+// exportCommandFunc executes the "export" command.
+func exportCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 || args[0] == "" {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("export command needs a non-empty prefix as argument"))
+	}
+	prefix := args[0]
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(exportRev))
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	tree, err := newKeyTree(prefix, resp.Kvs)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	out, err := encodeKeyTree(tree, exportFormat)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	os.Stdout.Write(out)
+}
@@ -169,11 +169,27 @@ func mustClientCfgFromCmd(cmd *cobra.Command) *clientv3.Config {
 	return cfg
 }
 
+// shellClient is the client shared by the commands run by "etcdctl shell".
+var shellClient *clientv3.Client
+
 func mustClientFromCmd(cmd *cobra.Command) *clientv3.Client {
+	if shellClient != nil {
+		initDisplayFromCmd(cmd)
+		return shellClient
+	}
 	cfg := clientConfigFromCmd(cmd)
 	return cfg.mustClient()
 }
 
+// releaseClient closes a client returned by mustClientFromCmd, unless it is
+// shared by the commands run by "etcdctl shell".
+func releaseClient(c *clientv3.Client) error {
+	if c == shellClient {
+		return nil
+	}
+	return c.Close()
+}
+
 func (cc *clientConfig) mustClient() *clientv3.Client {
 	cfg, err := newClientCfg(cc.endpoints, cc.dialTimeout, cc.keepAliveTime, cc.keepAliveTimeout, cc.scfg, cc.acfg)
 	if err != nil {
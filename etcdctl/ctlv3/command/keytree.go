@@ -0,0 +1,220 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/v3"
+
+	"sigs.k8s.io/yaml"
+)
+
+// A key tree holds the keys under a prefix as nested maps: the key relative
+// to the prefix is split on "/", every element but the last names a map and
+// the last one holds the value. For example, the keys "/app/db/host" and
+// "/app/db/port" under the prefix "/app/" make the tree
+//
+//	db:
+//	  host: 10.0.0.1
+//	  port: "5432"
+type keyTree map[string]interface{}
+
+// newKeyTree builds the tree of the given keys, which must all start with prefix.
+func newKeyTree(prefix string, kvs []*mvccpb.KeyValue) (keyTree, error) {
+	tree := keyTree{}
+	for _, kv := range kvs {
+		key, value := string(kv.Key), string(kv.Value)
+		if !utf8.ValidString(key) || !utf8.ValidString(value) {
+			return nil, fmt.Errorf("key %q: only UTF-8 keys and values can be exported", key)
+		}
+		path := strings.Split(strings.TrimPrefix(key, prefix), "/")
+		node := tree
+		for _, p := range path[:len(path)-1] {
+			child, ok := node[p]
+			if !ok {
+				child = keyTree{}
+				node[p] = child
+			}
+			if node, ok = child.(keyTree); !ok {
+				return nil, fmt.Errorf("key %q: %q holds both a value and keys", key, prefix+p)
+			}
+		}
+		last := path[len(path)-1]
+		if _, ok := node[last]; ok {
+			return nil, fmt.Errorf("key %q holds both a value and keys", key)
+		}
+		node[last] = value
+	}
+	return tree, nil
+}
+
+// flatten returns the keys and values of the tree under prefix. Numbers and
+// booleans are stored as their literals; lists and nulls are rejected.
+func (t keyTree) flatten(prefix string) (map[string]string, error) {
+	kvs := make(map[string]string)
+	var walk func(key string, node map[string]interface{}) error
+	walk = func(key string, node map[string]interface{}) error {
+		for p, v := range node {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				if err := walk(key+p+"/", v); err != nil {
+					return err
+				}
+			case keyTree:
+				if err := walk(key+p+"/", v); err != nil {
+					return err
+				}
+			case string:
+				kvs[key+p] = v
+			case json.Number:
+				kvs[key+p] = string(v)
+			case bool:
+				kvs[key+p] = fmt.Sprint(v)
+			default:
+				return fmt.Errorf("key %q: unsupported value %v (expected a string, number, boolean or map)", key+p, v)
+			}
+		}
+		return nil
+	}
+	if err := walk(prefix, t); err != nil {
+		return nil, err
+	}
+	return kvs, nil
+}
+
+// decodeKeyTree parses a YAML or JSON key tree.
+func decodeKeyTree(data []byte) (keyTree, error) {
+	js, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(bytes.TrimSpace(js), []byte("null")) {
+		return keyTree{}, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var tree map[string]interface{}
+	if err = dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("expected a map of keys: %v", err)
+	}
+	return tree, nil
+}
+
+// encodeKeyTree formats the tree as "yaml" or "json".
+func encodeKeyTree(tree keyTree, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		if len(tree) == 0 {
+			return []byte("{}\n"), nil
+		}
+		return yaml.Marshal(tree)
+	case "json":
+		b, err := json.MarshalIndent(tree, "", "  ")
+		return append(b, '\n'), err
+	}
+	return nil, fmt.Errorf("unknown format %q (expected yaml or json)", format)
+}
+
+// readKeyTreeFile reads the key tree in the named file, or in stdin if the name is "-".
+func readKeyTreeFile(name string) (keyTree, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decodeKeyTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return tree, nil
+}
+
+// keyChange is a change turning the current value of a key into the desired one.
+type keyChange struct {
+	key string
+	// prev is the current value, nil if the key does not exist.
+	prev *string
+	// value is the desired value, nil if the key is deleted.
+	value *string
+}
+
+func (c keyChange) String() string {
+	switch {
+	case c.prev == nil:
+		return fmt.Sprintf("+ %s = %q", c.key, *c.value)
+	case c.value == nil:
+		return fmt.Sprintf("- %s = %q", c.key, *c.prev)
+	}
+	return fmt.Sprintf("~ %s = %q -> %q", c.key, *c.prev, *c.value)
+}
+
+func (c keyChange) op() clientv3.Op {
+	if c.value == nil {
+		return clientv3.OpDelete(c.key)
+	}
+	return clientv3.OpPut(c.key, *c.value)
+}
+
+// diffKeys returns the changes, ordered by key, turning the current keys into
+// the desired ones. Current keys missing from the desired ones are only
+// deleted if prune is set.
+func diffKeys(current, desired map[string]string, prune bool) []keyChange {
+	var changes []keyChange
+	for k, v := range desired {
+		v := v
+		prev, ok := current[k]
+		switch {
+		case !ok:
+			changes = append(changes, keyChange{key: k, value: &v})
+		case prev != v:
+			changes = append(changes, keyChange{key: k, prev: &prev, value: &v})
+		}
+	}
+	if prune {
+		for k, prev := range current {
+			prev := prev
+			if _, ok := desired[k]; !ok {
+				changes = append(changes, keyChange{key: k, prev: &prev})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].key < changes[j].key })
+	return changes
+}
+
+// kvsToMap returns the keys and values of the given key-value pairs.
+func kvsToMap(kvs []*mvccpb.KeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = string(kv.Value)
+	}
+	return m
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func TestKeyTreeRoundTrip(t *testing.T) {
+	kvs := map[string]string{
+		"/app/db/host":   "10.0.0.1",
+		"/app/db/port":   "5432",
+		"/app/name":      "app",
+		"/app/dir/":      "trailing",
+		"/app/log/level": "",
+	}
+	var pkvs []*mvccpb.KeyValue
+	for k, v := range kvs {
+		pkvs = append(pkvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+	}
+	tree, err := newKeyTree("/app/", pkvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"yaml", "json"} {
+		b, err := encodeKeyTree(tree, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dtree, err := decodeKeyTree(b)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		got, err := dtree.flatten("/app/")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, kvs) {
+			t.Errorf("%s: expected %v, got %v", format, kvs, got)
+		}
+	}
+}
+
+func TestKeyTreeConflict(t *testing.T) {
+	for _, keys := range [][]string{{"/a", "/a/b"}, {"/a/b", "/a"}} {
+		var kvs []*mvccpb.KeyValue
+		for _, k := range keys {
+			kvs = append(kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte("v")})
+		}
+		if _, err := newKeyTree("/", kvs); err == nil {
+			t.Errorf("%v: expected error", keys)
+		}
+	}
+}
+
+func TestDecodeKeyTree(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]string
+		werr bool
+	}{
+		{"db:\n  port: 5432\n  tls: true\n  ratio: 0.5\nname: app\n", map[string]string{"/db/port": "5432", "/db/tls": "true", "/db/ratio": "0.5", "/name": "app"}, false},
+		{`{"db": {"port": "5432"}}`, map[string]string{"/db/port": "5432"}, false},
+		{"", map[string]string{}, false},
+		{"hosts:\n- a\n- b\n", nil, true},
+		{"name: null\n", nil, true},
+		{"- a\n", nil, true},
+	}
+	for i, tt := range tests {
+		tree, err := decodeKeyTree([]byte(tt.in))
+		var got map[string]string
+		if err == nil {
+			got, err = tree.flatten("/")
+		}
+		if (err != nil) != tt.werr {
+			t.Errorf("#%d: expected error %v, got %v", i, tt.werr, err)
+		}
+		if !tt.werr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: expected %v, got %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestDiffKeys(t *testing.T) {
+	current := map[string]string{"/a": "1", "/b": "2", "/c": "3"}
+	desired := map[string]string{"/a": "1", "/b": "20", "/d": "4"}
+	for _, tt := range []struct {
+		prune bool
+		want  []string
+	}{
+		{false, []string{`~ /b = "2" -> "20"`, `+ /d = "4"`}},
+		{true, []string{`~ /b = "2" -> "20"`, `- /c = "3"`, `+ /d = "4"`}},
+	} {
+		var got []string
+		for _, c := range diffKeys(current, desired, tt.prune) {
+			got = append(got, c.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("prune %v: expected %q, got %q", tt.prune, tt.want, got)
+		}
+	}
+}
@@ -34,9 +34,12 @@ func NewLeaseCommand() *cobra.Command {
 
 	lc.AddCommand(NewLeaseGrantCommand())
 	lc.AddCommand(NewLeaseRevokeCommand())
+	lc.AddCommand(NewLeaseUpdateCommand())
+	lc.AddCommand(NewLeaseMoveCommand())
 	lc.AddCommand(NewLeaseTimeToLiveCommand())
 	lc.AddCommand(NewLeaseListCommand())
 	lc.AddCommand(NewLeaseKeepAliveCommand())
+	lc.AddCommand(NewLeaseWatchCommand())
 
 	return lc
 }
@@ -101,6 +104,67 @@ func leaseRevokeCommandFunc(cmd *cobra.Command, args []string) {
 	display.Revoke(id, *resp)
 }
 
+// NewLeaseUpdateCommand returns the cobra command for "lease update".
+func NewLeaseUpdateCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "update <leaseID> <ttl>",
+		Short: "Changes the TTL of leases",
+
+		Run: leaseUpdateCommandFunc,
+	}
+
+	return lc
+}
+
+// leaseUpdateCommandFunc executes the "lease update" command.
+func leaseUpdateCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease update command needs lease ID and TTL as arguments"))
+	}
+
+	id := leaseFromArgs(args[0])
+	ttl, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad TTL (%v)", err))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Update(ctx, id, ttl)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to update lease (%v)", err))
+	}
+	display.LeaseUpdate(*resp)
+}
+
+// NewLeaseMoveCommand returns the cobra command for "lease move".
+func NewLeaseMoveCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "move <leaseID> <targetLeaseID>",
+		Short: "Attaches the keys of a lease to another lease",
+
+		Run: leaseMoveCommandFunc,
+	}
+
+	return lc
+}
+
+// leaseMoveCommandFunc executes the "lease move" command.
+func leaseMoveCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease move command needs lease ID and target lease ID as arguments"))
+	}
+
+	id, target := leaseFromArgs(args[0]), leaseFromArgs(args[1])
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Move(ctx, id, target)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to move lease keys (%v)", err))
+	}
+	display.LeaseMove(id, target, *resp)
+}
+
 var timeToLiveKeys bool
 
 // NewLeaseTimeToLiveCommand returns the cobra command for "lease timetolive".
@@ -199,6 +263,48 @@ func leaseKeepAliveCommandFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+var leaseWatchRenewThreshold int64
+
+// NewLeaseWatchCommand returns the cobra command for "lease watch".
+func NewLeaseWatchCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "watch [options] [leaseID...]",
+		Short: "Watches lease events",
+
+		Run: leaseWatchCommandFunc,
+	}
+
+	lc.Flags().Int64Var(&leaseWatchRenewThreshold, "renew-threshold", 0, "Reports renewals of leases with at most this many seconds left (0 disables renewal events)")
+
+	return lc
+}
+
+// leaseWatchCommandFunc executes the "lease watch" command.
+func leaseWatchCommandFunc(cmd *cobra.Command, args []string) {
+	var ids []v3.LeaseID
+	for _, arg := range args {
+		ids = append(ids, leaseFromArgs(arg))
+	}
+	if leaseWatchRenewThreshold < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("renew threshold must not be negative"))
+	}
+
+	opts := []v3.LeaseOption{v3.WithRenewThreshold(leaseWatchRenewThreshold)}
+	if len(ids) > 0 {
+		opts = append(opts, v3.WithLeaseIDs(ids...))
+	}
+	respc, err := mustClientFromCmd(cmd).WatchEvents(context.TODO(), opts...)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadConnection, err)
+	}
+	for resp := range respc {
+		if err = resp.Err(); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitInterrupted, err)
+		}
+		display.LeaseWatch(*resp)
+	}
+}
+
 func leaseFromArgs(arg string) v3.LeaseID {
 	id, err := strconv.ParseInt(arg, 16, 64)
 	if err != nil {
@@ -23,6 +23,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
@@ -30,7 +31,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var lockTTL = 10
+var (
+	lockTTL        = 10
+	lockMaxHolders = 1
+)
 
 // NewLockCommand returns the cobra command for "lock".
 func NewLockCommand() *cobra.Command {
@@ -40,6 +44,7 @@ func NewLockCommand() *cobra.Command {
 		Run:   lockCommandFunc,
 	}
 	c.Flags().IntVarP(&lockTTL, "ttl", "", lockTTL, "timeout for session")
+	c.Flags().IntVarP(&lockMaxHolders, "max-holders", "", lockMaxHolders, "number of sessions that may hold the lock at the same time")
 	return c
 }
 
@@ -47,6 +52,9 @@ func lockCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("lock takes a lock name argument and an optional command to execute"))
 	}
+	if lockMaxHolders < 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("--max-holders must be at least 1"))
+	}
 	c := mustClientFromCmd(cmd)
 	if err := lockUntilSignal(c, args[0], args[1:]); err != nil {
 		code := getExitCodeFromError(err)
@@ -74,7 +82,10 @@ func lockUntilSignal(c *clientv3.Client, lockname string, cmdArgs []string) erro
 		return err
 	}
 
-	m := concurrency.NewMutex(s, lockname)
+	var m lockHolder = concurrency.NewMutex(s, lockname)
+	if lockMaxHolders > 1 {
+		m = &semaphoreHolder{concurrency.NewSemaphore(s, lockname, lockMaxHolders)}
+	}
 	ctx, cancel := context.WithCancel(context.TODO())
 
 	// unlock in case of ordinary shutdown
@@ -121,7 +132,21 @@ func lockUntilSignal(c *clientv3.Client, lockname string, cmdArgs []string) erro
 	return errors.New("session expired")
 }
 
-func environLockResponse(m *concurrency.Mutex) []string {
+// lockHolder is the common interface of the lock primitives etcdctl can hold.
+type lockHolder interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+	Key() string
+	Header() *pb.ResponseHeader
+}
+
+// semaphoreHolder adapts a concurrency.Semaphore to the lockHolder interface.
+type semaphoreHolder struct{ *concurrency.Semaphore }
+
+func (sh *semaphoreHolder) Lock(ctx context.Context) error   { return sh.Acquire(ctx) }
+func (sh *semaphoreHolder) Unlock(ctx context.Context) error { return sh.Release(ctx) }
+
+func environLockResponse(m lockHolder) []string {
 	return []string{
 		"ETCD_LOCK_KEY=" + m.Key(),
 		fmt.Sprintf("ETCD_LOCK_REV=%d", m.Header().Revision),
@@ -45,7 +45,7 @@ func transferLeadershipCommandFunc(cmd *cobra.Command, args []string) {
 
 	c := mustClientFromCmd(cmd)
 	eps := c.Endpoints()
-	c.Close()
+	releaseClient(c)
 
 	ctx, cancel := commandCtx(cmd)
 
@@ -71,6 +71,7 @@ func transferLeadershipCommandFunc(cmd *cobra.Command, args []string) {
 	if leaderCli == nil {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("no leader endpoint given at %v", eps))
 	}
+	defer leaderCli.Close()
 
 	var resp *clientv3.MoveLeaderResponse
 	resp, err = leaderCli.MoveLeader(ctx, target)
@@ -35,6 +35,9 @@ type printer interface {
 
 	Grant(r v3.LeaseGrantResponse)
 	Revoke(id v3.LeaseID, r v3.LeaseRevokeResponse)
+	LeaseUpdate(r v3.LeaseUpdateResponse)
+	LeaseMove(id, target v3.LeaseID, r v3.LeaseMoveResponse)
+	LeaseWatch(r v3.LeaseWatchResponse)
 	KeepAlive(r v3.LeaseKeepAliveResponse)
 	TimeToLive(r v3.LeaseTimeToLiveResponse, keys bool)
 	Leases(r v3.LeaseLeasesResponse)
@@ -52,6 +55,17 @@ type printer interface {
 
 	Alarm(v3.AlarmResponse)
 
+	RetentionPut(prefix string, r v3.RetentionPutResponse)
+	RetentionDelete(prefix string, r v3.RetentionDeleteResponse)
+	RetentionList(r v3.RetentionListResponse)
+
+	SchemaSet(prefix string, r v3.SchemaPutResponse)
+	SchemaGet(r v3.SchemaGetResponse)
+	SchemaRemove(prefix string, r v3.SchemaDeleteResponse)
+	SchemaList(r v3.SchemaListResponse)
+
+	TenantStatus(r v3.TenantStatusResponse)
+
 	RoleAdd(role string, r v3.AuthRoleAddResponse)
 	RoleGet(role string, r v3.AuthRoleGetResponse)
 	RoleDelete(role string, r v3.AuthRoleDeleteResponse)
@@ -103,6 +117,12 @@ func (p *printerRPC) KeepAlive(r v3.LeaseKeepAliveResponse)              { p.p(r
 func (p *printerRPC) TimeToLive(r v3.LeaseTimeToLiveResponse, keys bool) { p.p(&r) }
 func (p *printerRPC) Leases(r v3.LeaseLeasesResponse)                    { p.p(&r) }
 
+func (p *printerRPC) LeaseUpdate(r v3.LeaseUpdateResponse) { p.p(r) }
+func (p *printerRPC) LeaseMove(_, _ v3.LeaseID, r v3.LeaseMoveResponse) {
+	p.p((*pb.LeaseMoveResponse)(&r))
+}
+func (p *printerRPC) LeaseWatch(r v3.LeaseWatchResponse) { p.p(&r) }
+
 func (p *printerRPC) MemberAdd(r v3.MemberAddResponse) { p.p((*pb.MemberAddResponse)(&r)) }
 func (p *printerRPC) MemberRemove(id uint64, r v3.MemberRemoveResponse) {
 	p.p((*pb.MemberRemoveResponse)(&r))
@@ -115,6 +135,26 @@ func (p *printerRPC) MemberPromote(id uint64, r v3.MemberPromoteResponse) {
 }
 func (p *printerRPC) MemberList(r v3.MemberListResponse) { p.p((*pb.MemberListResponse)(&r)) }
 func (p *printerRPC) Alarm(r v3.AlarmResponse)           { p.p((*pb.AlarmResponse)(&r)) }
+func (p *printerRPC) RetentionPut(_ string, r v3.RetentionPutResponse) {
+	p.p((*pb.RetentionPutResponse)(&r))
+}
+func (p *printerRPC) RetentionDelete(_ string, r v3.RetentionDeleteResponse) {
+	p.p((*pb.RetentionDeleteResponse)(&r))
+}
+func (p *printerRPC) RetentionList(r v3.RetentionListResponse) {
+	p.p((*pb.RetentionListResponse)(&r))
+}
+func (p *printerRPC) SchemaSet(_ string, r v3.SchemaPutResponse) {
+	p.p((*pb.SchemaPutResponse)(&r))
+}
+func (p *printerRPC) SchemaGet(r v3.SchemaGetResponse) { p.p((*pb.SchemaGetResponse)(&r)) }
+func (p *printerRPC) SchemaRemove(_ string, r v3.SchemaDeleteResponse) {
+	p.p((*pb.SchemaDeleteResponse)(&r))
+}
+func (p *printerRPC) SchemaList(r v3.SchemaListResponse) { p.p((*pb.SchemaListResponse)(&r)) }
+func (p *printerRPC) TenantStatus(r v3.TenantStatusResponse) {
+	p.p((*pb.TenantStatusResponse)(&r))
+}
 func (p *printerRPC) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) {
 	p.p((*pb.MoveLeaderResponse)(&r))
 }
@@ -85,6 +85,9 @@ func (p *fieldsPrinter) Watch(resp v3.WatchResponse) {
 	p.hdr(&resp.Header)
 	for _, e := range resp.Events {
 		fmt.Println(`"Type" :`, e.Type)
+		if e.Coalesced {
+			fmt.Println(`"Coalesced" :`, e.Coalesced)
+		}
 		if e.PrevKv != nil {
 			p.kv("Prev", e.PrevKv)
 		}
@@ -102,6 +105,27 @@ func (p *fieldsPrinter) Revoke(id v3.LeaseID, r v3.LeaseRevokeResponse) {
 	p.hdr(r.Header)
 }
 
+func (p *fieldsPrinter) LeaseUpdate(r v3.LeaseUpdateResponse) {
+	p.hdr(r.ResponseHeader)
+	fmt.Println(`"ID" :`, r.ID)
+	fmt.Println(`"TTL" :`, r.TTL)
+}
+
+func (p *fieldsPrinter) LeaseMove(id, target v3.LeaseID, r v3.LeaseMoveResponse) {
+	p.hdr(r.Header)
+	fmt.Println(`"Moved" :`, r.Moved)
+}
+
+func (p *fieldsPrinter) LeaseWatch(r v3.LeaseWatchResponse) {
+	p.hdr(r.ResponseHeader)
+	for _, ev := range r.Events {
+		fmt.Println(`"Type" :`, ev.Type)
+		fmt.Println(`"ID" :`, ev.ID)
+		fmt.Println(`"TTL" :`, ev.TTL)
+		fmt.Println(`"RemainingTTL" :`, ev.RemainingTTL)
+	}
+}
+
 func (p *fieldsPrinter) KeepAlive(r v3.LeaseKeepAliveResponse) {
 	p.hdr(r.ResponseHeader)
 	fmt.Println(`"ID" :`, r.ID)
@@ -186,6 +210,53 @@ func (p *fieldsPrinter) Alarm(r v3.AlarmResponse) {
 	}
 }
 
+func (p *fieldsPrinter) RetentionPut(prefix string, r v3.RetentionPutResponse) { p.hdr(r.Header) }
+func (p *fieldsPrinter) RetentionDelete(prefix string, r v3.RetentionDeleteResponse) {
+	p.hdr(r.Header)
+}
+func (p *fieldsPrinter) RetentionList(r v3.RetentionListResponse) {
+	p.hdr(r.Header)
+	for _, rule := range r.Rules {
+		fmt.Printf("\"Prefix\" : %q\n", string(rule.Prefix))
+		fmt.Println(`"Revisions" :`, rule.Revisions)
+		fmt.Println(`"DurationSeconds" :`, rule.DurationSeconds)
+		fmt.Println(`"CompactRevision" :`, rule.CompactRevision)
+		fmt.Println()
+	}
+}
+
+func (p *fieldsPrinter) SchemaSet(prefix string, r v3.SchemaPutResponse) { p.hdr(r.Header) }
+func (p *fieldsPrinter) SchemaGet(r v3.SchemaGetResponse) {
+	p.hdr(r.Header)
+	p.valueSchema(r.Schema)
+}
+func (p *fieldsPrinter) SchemaRemove(prefix string, r v3.SchemaDeleteResponse) { p.hdr(r.Header) }
+func (p *fieldsPrinter) SchemaList(r v3.SchemaListResponse) {
+	p.hdr(r.Header)
+	for _, vs := range r.Schemas {
+		p.valueSchema(vs)
+		fmt.Println()
+	}
+}
+
+func (p *fieldsPrinter) TenantStatus(r v3.TenantStatusResponse) {
+	p.hdr(r.Header)
+	for _, t := range r.Tenants {
+		fmt.Printf("\"Prefix\" : %q\n", string(t.Prefix))
+		fmt.Printf("\"Users\" : %q\n", t.Users)
+		fmt.Println(`"KeyCount" :`, t.KeyCount)
+		fmt.Println(`"SizeBytes" :`, t.SizeBytes)
+		fmt.Println()
+	}
+}
+
+func (p *fieldsPrinter) valueSchema(vs *pb.ValueSchema) {
+	fmt.Printf("\"Prefix\" : %q\n", string(vs.Prefix))
+	fmt.Println(`"Type" :`, vs.Type)
+	fmt.Printf("\"Definition\" : %q\n", string(vs.Definition))
+	fmt.Printf("\"MessageType\" : %q\n", vs.MessageType)
+}
+
 func (p *fieldsPrinter) RoleAdd(role string, r v3.AuthRoleAddResponse) { p.hdr(r.Header) }
 func (p *fieldsPrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 	p.hdr(r.Header)
@@ -193,6 +264,15 @@ func (p *fieldsPrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		fmt.Println(`"PermType" : `, p.PermType.String())
 		fmt.Printf("\"Key\" : %q\n", string(p.Key))
 		fmt.Printf("\"RangeEnd\" : %q\n", string(p.RangeEnd))
+		if p.Pattern {
+			fmt.Println(`"Pattern" : `, p.Pattern)
+		}
+		if p.Deny {
+			fmt.Println(`"Deny" : `, p.Deny)
+		}
+		for _, op := range p.Ops {
+			fmt.Println(`"Op" : `, op.String())
+		}
 	}
 }
 func (p *fieldsPrinter) RoleDelete(role string, r v3.AuthRoleDeleteResponse) { p.hdr(r.Header) }
@@ -18,7 +18,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	v3 "go.etcd.io/etcd/client/v3"
@@ -91,6 +93,24 @@ func (s *simplePrinter) Revoke(id v3.LeaseID, r v3.LeaseRevokeResponse) {
 	fmt.Printf("lease %016x revoked\n", id)
 }
 
+func (s *simplePrinter) LeaseWatch(resp v3.LeaseWatchResponse) {
+	for _, ev := range resp.Events {
+		if ev.Type == v3.LeaseEventRenewed {
+			fmt.Printf("%s %016x TTL(%ds) remaining(%ds)\n", ev.Type, ev.ID, ev.TTL, ev.RemainingTTL)
+			continue
+		}
+		fmt.Printf("%s %016x TTL(%ds)\n", ev.Type, ev.ID, ev.TTL)
+	}
+}
+
+func (s *simplePrinter) LeaseUpdate(resp v3.LeaseUpdateResponse) {
+	fmt.Printf("lease %016x updated with TTL(%ds)\n", resp.ID, resp.TTL)
+}
+
+func (s *simplePrinter) LeaseMove(id, target v3.LeaseID, r v3.LeaseMoveResponse) {
+	fmt.Printf("%d keys moved from lease %016x to lease %016x\n", r.Moved, id, target)
+}
+
 func (s *simplePrinter) KeepAlive(resp v3.LeaseKeepAliveResponse) {
 	fmt.Printf("lease %016x keepalived with TTL(%d)\n", resp.ID, resp.TTL)
 }
@@ -125,6 +145,57 @@ func (s *simplePrinter) Alarm(resp v3.AlarmResponse) {
 	}
 }
 
+func (s *simplePrinter) RetentionPut(prefix string, r v3.RetentionPutResponse) {
+	fmt.Printf("Retention rule for prefix %q updated\n", prefix)
+}
+
+func (s *simplePrinter) RetentionDelete(prefix string, r v3.RetentionDeleteResponse) {
+	fmt.Printf("Retention rule for prefix %q deleted\n", prefix)
+}
+
+func (s *simplePrinter) RetentionList(r v3.RetentionListResponse) {
+	for _, rule := range r.Rules {
+		fmt.Printf("%q, revisions: %d, duration: %v, compact revision: %d\n",
+			string(rule.Prefix), rule.Revisions, time.Duration(rule.DurationSeconds)*time.Second, rule.CompactRevision)
+	}
+}
+
+func (s *simplePrinter) SchemaSet(prefix string, r v3.SchemaPutResponse) {
+	fmt.Printf("Schema for prefix %q updated\n", prefix)
+}
+
+func (s *simplePrinter) SchemaGet(r v3.SchemaGetResponse) {
+	printValueSchema(r.Schema, true)
+}
+
+func (s *simplePrinter) SchemaRemove(prefix string, r v3.SchemaDeleteResponse) {
+	fmt.Printf("Schema for prefix %q removed\n", prefix)
+}
+
+func (s *simplePrinter) SchemaList(r v3.SchemaListResponse) {
+	for _, vs := range r.Schemas {
+		printValueSchema(vs, false)
+	}
+}
+
+func (s *simplePrinter) TenantStatus(r v3.TenantStatusResponse) {
+	for _, t := range r.Tenants {
+		fmt.Printf("%q, users: %s, keys: %d, size: %s\n",
+			string(t.Prefix), strings.Join(t.Users, ","), t.KeyCount, humanize.Bytes(uint64(t.SizeBytes)))
+	}
+}
+
+func printValueSchema(vs *pb.ValueSchema, definition bool) {
+	if vs.Type == pb.ValueSchema_PROTOBUF {
+		fmt.Printf("%q, type: protobuf, message: %s\n", string(vs.Prefix), vs.MessageType)
+		return
+	}
+	fmt.Printf("%q, type: json\n", string(vs.Prefix))
+	if definition {
+		fmt.Println(string(vs.Definition))
+	}
+}
+
 func (s *simplePrinter) MemberAdd(r v3.MemberAddResponse) {
 	fmt.Printf("Member %16x added to cluster %16x\n", r.Member.ID, r.Header.ClusterId)
 }
@@ -206,7 +277,14 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		fmt.Printf("\n")
 	}
 
+	// key permissions with patterns, deny rules or operations are listed
+	// with their operations instead
+	var opPerms []*v3.Permission
 	for _, perm := range r.Perm {
+		if perm.Pattern || perm.Deny || len(perm.Ops) > 0 {
+			opPerms = append(opPerms, (*v3.Permission)(perm))
+			continue
+		}
 		if perm.PermType == v3.PermRead || perm.PermType == v3.PermReadWrite {
 			if len(perm.RangeEnd) == 0 {
 				fmt.Printf("\t%s\n", string(perm.Key))
@@ -217,6 +295,9 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 	}
 	fmt.Println("KV Write:")
 	for _, perm := range r.Perm {
+		if perm.Pattern || perm.Deny || len(perm.Ops) > 0 {
+			continue
+		}
 		if perm.PermType == v3.PermWrite || perm.PermType == v3.PermReadWrite {
 			if len(perm.RangeEnd) == 0 {
 				fmt.Printf("\t%s\n", string(perm.Key))
@@ -225,6 +306,39 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 			}
 		}
 	}
+	if len(opPerms) == 0 {
+		return
+	}
+	fmt.Println("KV Operations:")
+	for _, perm := range opPerms {
+		action := "grant"
+		if perm.Deny {
+			action = "deny"
+		}
+		fmt.Printf("\t%s %s", action, permOperationsString(perm))
+		switch {
+		case perm.Pattern:
+			fmt.Printf(" %s (pattern)\n", string(perm.Key))
+		case len(perm.RangeEnd) == 0:
+			fmt.Printf(" %s\n", string(perm.Key))
+		case string(perm.RangeEnd) == "\x00":
+			fmt.Printf(" [%s, <open ended>\n", string(perm.Key))
+		default:
+			fmt.Printf(" [%s, %s)\n", string(perm.Key), string(perm.RangeEnd))
+		}
+	}
+}
+
+// permOperationsString returns the comma separated operations of the permission.
+func permOperationsString(perm *v3.Permission) string {
+	if len(perm.Ops) == 0 {
+		return strings.ToLower(perm.PermType.String())
+	}
+	ops := make([]string, len(perm.Ops))
+	for i, op := range perm.Ops {
+		ops[i] = strings.ToLower(op.String())
+	}
+	return strings.Join(ops, ",")
 }
 
 func (s *simplePrinter) RoleList(r v3.AuthRoleListResponse) {
@@ -264,6 +378,9 @@ func (s *simplePrinter) UserGet(name string, r v3.AuthUserGetResponse) {
 		fmt.Printf(" %s", role)
 	}
 	fmt.Printf("\n")
+	if len(r.Prefix) > 0 {
+		fmt.Printf("Tenant prefix: %s\n", string(r.Prefix))
+	}
 }
 
 func (s *simplePrinter) UserChangePassword(v3.AuthUserChangePasswordResponse) {
@@ -0,0 +1,243 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.etcd.io/etcd/client/v3/queue"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	queueTTL               = 10
+	queueVisibilityTimeout time.Duration
+	queueMaxAttempts       int
+)
+
+// NewQueueCommand returns the cobra command for "queue".
+func NewQueueCommand() *cobra.Command {
+	qc := &cobra.Command{
+		Use:   "queue <subcommand>",
+		Short: "Queue related commands",
+	}
+	qc.PersistentFlags().IntVarP(&queueTTL, "ttl", "", queueTTL, "timeout for session")
+
+	qc.AddCommand(newQueueEnqueueCommand())
+	qc.AddCommand(newQueueDequeueCommand())
+	qc.AddCommand(newQueueStatusCommand())
+	qc.AddCommand(newQueueDeadLettersCommand())
+	qc.AddCommand(newQueueRedriveCommand())
+	return qc
+}
+
+func newQueueEnqueueCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "enqueue <queue-name> <value>",
+		Short: "Adds a value to the end of a queue",
+		Run:   queueEnqueueCommandFunc,
+	}
+}
+
+func newQueueDequeueCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "dequeue <queue-name> [exec-command arg1 arg2 ...]",
+		Short: "Takes an item from a queue and acknowledges it once processed",
+		Run:   queueDequeueCommandFunc,
+	}
+	c.Flags().DurationVar(&queueVisibilityTimeout, "visibility-timeout", 0, "time after which an unacknowledged item is returned to the queue (default: until the session ends)")
+	c.Flags().IntVar(&queueMaxAttempts, "max-attempts", 0, "number of attempts after which an item is moved to the dead letters (0 retries forever)")
+	return c
+}
+
+func newQueueStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <queue-name>",
+		Short: "Prints the number of pending, claimed and dead items of a queue",
+		Run:   queueStatusCommandFunc,
+	}
+}
+
+func newQueueDeadLettersCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dead-letters <queue-name>",
+		Short: "Lists the items of a queue that ran out of attempts",
+		Run:   queueDeadLettersCommandFunc,
+	}
+}
+
+func newQueueRedriveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "redrive <queue-name> [item-id ...]",
+		Short: "Returns dead letters to a queue; all of them if no item ID is given",
+		Run:   queueRedriveCommandFunc,
+	}
+}
+
+// mustQueueFromCmd creates a session and a queue on the given name. The
+// caller is responsible for closing the session.
+func mustQueueFromCmd(cmd *cobra.Command, name string, opts ...queue.Option) (*concurrency.Session, *queue.Queue) {
+	c := mustClientFromCmd(cmd)
+	s, err := concurrency.NewSession(c, concurrency.WithTTL(queueTTL))
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	return s, queue.NewQueue(s, name, opts...)
+}
+
+func queueEnqueueCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("queue enqueue takes a queue name and a value argument"))
+	}
+	s, q := mustQueueFromCmd(cmd, args[0])
+	defer s.Close()
+
+	ctx, cancel := commandCtx(cmd)
+	id, err := q.Enqueue(ctx, args[1])
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Println(id)
+}
+
+func queueDequeueCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("queue dequeue takes a queue name argument and an optional command to execute"))
+	}
+	s, q := mustQueueFromCmd(cmd, args[0],
+		queue.WithVisibilityTimeout(queueVisibilityTimeout),
+		queue.WithMaxAttempts(queueMaxAttempts),
+	)
+	defer s.Close()
+
+	if err := dequeueUntilSignal(q, args[1:]); err != nil {
+		cobrautl.ExitWithError(getExitCodeFromError(err), err)
+	}
+}
+
+// dequeueUntilSignal waits for an item, then either prints it or runs the
+// given command on it. The item is acknowledged on success and returned to
+// the queue otherwise.
+func dequeueUntilSignal(q *queue.Queue, cmdArgs []string) error {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigc
+		cancel()
+	}()
+
+	it, err := q.Dequeue(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(cmdArgs) == 0 {
+		fmt.Println(it.Value)
+		return it.Ack(context.TODO())
+	}
+
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Env = append(environQueueItem(it), os.Environ()...)
+	cmd.Stdin = strings.NewReader(it.Value)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err = cmd.Run(); err != nil {
+		it.Nack(context.TODO())
+		return err
+	}
+	return it.Ack(context.TODO())
+}
+
+func environQueueItem(it *queue.Item) []string {
+	return []string{
+		"ETCD_QUEUE_ITEM_ID=" + it.ID,
+		"ETCD_QUEUE_ITEM_VALUE=" + it.Value,
+		fmt.Sprintf("ETCD_QUEUE_ITEM_ATTEMPTS=%d", it.Attempts),
+	}
+}
+
+func queueStatusCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("queue status takes a queue name argument"))
+	}
+	s, q := mustQueueFromCmd(cmd, args[0])
+	defer s.Close()
+
+	ctx, cancel := commandCtx(cmd)
+	st, err := q.Stats(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("pending: %d\nclaimed: %d\ndead: %d\n", st.Pending, st.Claimed, st.Dead)
+}
+
+func queueDeadLettersCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("queue dead-letters takes a queue name argument"))
+	}
+	s, q := mustQueueFromCmd(cmd, args[0])
+	defer s.Close()
+
+	ctx, cancel := commandCtx(cmd)
+	items, err := q.DeadLetters(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	for _, it := range items {
+		fmt.Printf("%s (attempts: %d)\n%s\n", it.ID, it.Attempts, it.Value)
+	}
+}
+
+func queueRedriveCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("queue redrive takes a queue name argument and optional item IDs"))
+	}
+	s, q := mustQueueFromCmd(cmd, args[0])
+	defer s.Close()
+
+	ctx, cancel := commandCtx(cmd)
+	defer cancel()
+	ids := args[1:]
+	if len(ids) == 0 {
+		items, err := q.DeadLetters(ctx)
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, err)
+		}
+		for _, it := range items {
+			ids = append(ids, it.ID)
+		}
+	}
+	for _, id := range ids {
+		if err := q.Redrive(ctx, id); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("%s: %v", id, err))
+		}
+	}
+	fmt.Printf("Redrove %d item(s)\n", len(ids))
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	retentionRevisions int64
+	retentionDuration  time.Duration
+)
+
+// NewRetentionCommand returns the cobra command for "retention".
+func NewRetentionCommand() *cobra.Command {
+	rc := &cobra.Command{
+		Use:   "retention <subcommand>",
+		Short: "Retention rule related commands",
+	}
+
+	rc.AddCommand(NewRetentionPutCommand())
+	rc.AddCommand(NewRetentionDeleteCommand())
+	rc.AddCommand(NewRetentionListCommand())
+
+	return rc
+}
+
+func NewRetentionPutCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "put <prefix>",
+		Short: "Sets the history retention of the keys under a prefix",
+		Long: `Sets the history retention of the keys under a prefix.
+
+The keys under the prefix keep the given number of most recent revisions, or
+the history of the given duration, whichever is longer, instead of following
+the compaction of the keyspace. The retention is applied when the keyspace is
+compacted, either by auto compaction or by "etcdctl compaction".
+`,
+		Run: retentionPutCommandFunc,
+	}
+	cmd.Flags().Int64Var(&retentionRevisions, "revisions", 0, "Number of most recent revisions to keep")
+	cmd.Flags().DurationVar(&retentionDuration, "duration", 0, "Duration of the most recent history to keep (e.g. '24h')")
+	return &cmd
+}
+
+// retentionPutCommandFunc executes the "retention put" command.
+func retentionPutCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("retention put command needs 1 argument"))
+	}
+	if retentionRevisions <= 0 && retentionDuration <= 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("retention put command needs --revisions or --duration"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).RetentionPut(ctx, args[0], retentionRevisions, retentionDuration)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.RetentionPut(args[0], *resp)
+}
+
+func NewRetentionDeleteCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "delete <prefix>",
+		Short: "Deletes the retention rule of a prefix",
+		Run:   retentionDeleteCommandFunc,
+	}
+	return &cmd
+}
+
+// retentionDeleteCommandFunc executes the "retention delete" command.
+func retentionDeleteCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("retention delete command needs 1 argument"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).RetentionDelete(ctx, args[0])
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.RetentionDelete(args[0], *resp)
+}
+
+func NewRetentionListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "Lists all retention rules",
+		Run:   retentionListCommandFunc,
+	}
+	return &cmd
+}
+
+// retentionListCommandFunc executes the "retention list" command.
+func retentionListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("retention list command accepts no arguments"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).RetentionList(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.RetentionList(*resp)
+}
@@ -17,8 +17,10 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/api/v3/authpb"
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 )
@@ -26,6 +28,9 @@ import (
 var (
 	rolePermPrefix  bool
 	rolePermFromKey bool
+	rolePermPattern bool
+	rolePermDeny    bool
+	rolePermOps     string
 )
 
 // NewRoleCommand returns the cobra command for "role".
@@ -86,6 +91,9 @@ func newRoleGrantPermissionCommand() *cobra.Command {
 
 	cmd.Flags().BoolVar(&rolePermPrefix, "prefix", false, "grant a prefix permission")
 	cmd.Flags().BoolVar(&rolePermFromKey, "from-key", false, "grant a permission of keys that are greater than or equal to the given key using byte compare")
+	cmd.Flags().BoolVar(&rolePermPattern, "pattern", false, "grant a permission of keys matching the given glob pattern")
+	cmd.Flags().BoolVar(&rolePermDeny, "deny", false, "deny the operations on the keys instead of granting them")
+	cmd.Flags().StringVar(&rolePermOps, "ops", "", "comma separated operations the permission is limited to (range, put, delete, lease, watch, txn)")
 
 	return cmd
 }
@@ -99,6 +107,8 @@ func newRoleRevokePermissionCommand() *cobra.Command {
 
 	cmd.Flags().BoolVar(&rolePermPrefix, "prefix", false, "revoke a prefix permission")
 	cmd.Flags().BoolVar(&rolePermFromKey, "from-key", false, "revoke a permission of keys that are greater than or equal to the given key using byte compare")
+	cmd.Flags().BoolVar(&rolePermPattern, "pattern", false, "revoke a permission of keys matching the given glob pattern")
+	cmd.Flags().BoolVar(&rolePermDeny, "deny", false, "revoke a deny permission")
 
 	return cmd
 }
@@ -166,13 +176,20 @@ func roleGrantPermissionCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role grant command requires role name, permission type, and key [endkey] as its argument"))
 	}
 
-	perm, err := clientv3.StrToPermissionType(args[1])
+	permType, err := clientv3.StrToPermissionType(args[1])
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
 	}
 
-	key, rangeEnd := permRange(args[2:])
-	resp, err := mustClientFromCmd(cmd).Auth.RoleGrantPermission(context.TODO(), args[0], key, rangeEnd, perm)
+	ops, err := permOps(rolePermOps)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+
+	perm := keyPermission(args[2:])
+	perm.PermType = authpb.Permission_Type(permType)
+	perm.Ops = ops
+	resp, err := mustClientFromCmd(cmd).Auth.RoleGrantKeyPermission(context.TODO(), args[0], perm)
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
@@ -186,12 +203,48 @@ func roleRevokePermissionCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role revoke-permission command requires role name and key [endkey] as its argument"))
 	}
 
-	key, rangeEnd := permRange(args[1:])
-	resp, err := mustClientFromCmd(cmd).Auth.RoleRevokePermission(context.TODO(), args[0], key, rangeEnd)
+	perm := keyPermission(args[1:])
+	resp, err := mustClientFromCmd(cmd).Auth.RoleRevokeKeyPermission(context.TODO(), args[0], perm)
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
-	display.RoleRevokePermission(args[0], args[1], rangeEnd, *resp)
+	display.RoleRevokePermission(args[0], args[1], string(perm.RangeEnd), *resp)
+}
+
+// keyPermission returns the permission of the key arguments and flags.
+func keyPermission(args []string) *clientv3.Permission {
+	perm := &clientv3.Permission{Deny: rolePermDeny}
+	if rolePermPattern {
+		if rolePermPrefix || rolePermFromKey {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--pattern flag is mutually exclusive with --prefix and --from-key flags"))
+		}
+		if len(args) != 1 || len(args[0]) == 0 {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--pattern flag requires a non-empty key and no endkey"))
+		}
+		perm.Key = []byte(args[0])
+		perm.Pattern = true
+		return perm
+	}
+
+	key, rangeEnd := permRange(args)
+	perm.Key = []byte(key)
+	perm.RangeEnd = []byte(rangeEnd)
+	return perm
+}
+
+func permOps(s string) ([]authpb.Permission_Operation, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	var ops []authpb.Permission_Operation
+	for _, name := range strings.Split(s, ",") {
+		op, err := clientv3.StrToPermissionOperation(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, authpb.Permission_Operation(op))
+	}
+	return ops, nil
 }
 
 func permRange(args []string) (string, string) {
@@ -0,0 +1,164 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	v3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	schemaType        string
+	schemaMessageType string
+)
+
+// NewSchemaCommand returns the cobra command for "schema".
+func NewSchemaCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "schema <subcommand>",
+		Short: "Value schema related commands",
+	}
+
+	sc.AddCommand(NewSchemaSetCommand())
+	sc.AddCommand(NewSchemaGetCommand())
+	sc.AddCommand(NewSchemaRemoveCommand())
+	sc.AddCommand(NewSchemaListCommand())
+
+	return sc
+}
+
+func NewSchemaSetCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "set <prefix> <file>",
+		Short: "Sets the schema of the values written under a prefix",
+		Long: `Sets the schema of the values written under a prefix.
+
+Puts and transactions writing a value that does not match the schema of the
+longest prefix of its key are rejected. JSON schemas are JSON Schema documents.
+Protobuf schemas are FileDescriptorSets, as written by
+"protoc --include_imports --descriptor_set_out", and need --message to name
+the type the values must decode to.
+`,
+		Run: schemaSetCommandFunc,
+	}
+	cmd.Flags().StringVar(&schemaType, "type", "json", "Schema type ('json' or 'protobuf')")
+	cmd.Flags().StringVar(&schemaMessageType, "message", "", "Full name of the protobuf message type of the values")
+	return &cmd
+}
+
+// schemaSetCommandFunc executes the "schema set" command.
+func schemaSetCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("schema set command needs 2 arguments"))
+	}
+	vs := &v3.ValueSchema{Prefix: []byte(args[0]), MessageType: schemaMessageType}
+	switch schemaType {
+	case "json":
+		vs.Type = v3.ValueSchemaJSON
+	case "protobuf":
+		vs.Type = v3.ValueSchemaProtobuf
+		if schemaMessageType == "" {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("protobuf schemas need --message"))
+		}
+	default:
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("unknown schema type %q", schemaType))
+	}
+	def, err := ioutil.ReadFile(args[1])
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	vs.Definition = def
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).SchemaPut(ctx, vs)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.SchemaSet(args[0], *resp)
+}
+
+func NewSchemaGetCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "get <prefix>",
+		Short: "Gets the schema of a prefix",
+		Run:   schemaGetCommandFunc,
+	}
+	return &cmd
+}
+
+// schemaGetCommandFunc executes the "schema get" command.
+func schemaGetCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("schema get command needs 1 argument"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).SchemaGet(ctx, args[0])
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.SchemaGet(*resp)
+}
+
+func NewSchemaRemoveCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "remove <prefix>",
+		Short: "Removes the schema of a prefix",
+		Run:   schemaRemoveCommandFunc,
+	}
+	return &cmd
+}
+
+// schemaRemoveCommandFunc executes the "schema remove" command.
+func schemaRemoveCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("schema remove command needs 1 argument"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).SchemaDelete(ctx, args[0])
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.SchemaRemove(args[0], *resp)
+}
+
+func NewSchemaListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "Lists all value schemas",
+		Run:   schemaListCommandFunc,
+	}
+	return &cmd
+}
+
+// schemaListCommandFunc executes the "schema list" command.
+func schemaListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("schema list command accepts no arguments"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).SchemaList(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.SchemaList(*resp)
+}
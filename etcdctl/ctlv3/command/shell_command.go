@@ -0,0 +1,527 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// shellCompletionLimit bounds the number of keys offered for completion.
+	shellCompletionLimit = 100
+	// shellHistoryLimit bounds the number of lines loaded from the history file.
+	shellHistoryLimit = 1000
+)
+
+var (
+	shellHistoryFile string
+	shellPrefix      string
+)
+
+var errShellNested = errors.New("already running in a shell")
+
+// shellKeyArgs maps the commands taking keys to the number of leading
+// arguments that are keys; these are completed from the cluster and
+// prefixed with the default prefix of the shell.
+var shellKeyArgs = map[string]int{
+	"get":    2,
+	"put":    1,
+	"del":    2,
+	"watch":  2,
+	"export": 1,
+}
+
+// NewShellCommand returns the cobra command for "shell".
+func NewShellCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Runs commands interactively over a single connection",
+		Long: `Runs etcdctl commands read from the standard input over a single connection.
+
+Connection and authentication flags are taken from the shell invocation; the
+other global flags may be overridden per command. On a terminal, lines can be
+edited with emacs-style keys, the arrow keys browse the history and tab
+completes commands, flags and keys.
+
+Besides the etcdctl commands, the shell understands:
+  prefix [<prefix>]  prints or sets the default prefix of key arguments
+  exit, quit         leaves the shell
+`,
+		Run: shellCommandFunc,
+	}
+	cmd.Flags().StringVar(&shellHistoryFile, "history-file", "~/.etcdctl_history", "file keeping the command history (empty disables saving the history)")
+	cmd.Flags().StringVar(&shellPrefix, "prefix", "", "default prefix prepended to the key arguments of get, put, del, watch and export")
+	return cmd
+}
+
+// shellExit is the panic value replacing os.Exit while the shell runs a command.
+type shellExit int
+
+type shell struct {
+	root   *cobra.Command
+	client *clientv3.Client
+	prefix string
+
+	// flags holds the state of all flags when the shell started, to reset
+	// the flags changed by a command before running the next one.
+	flags map[*pflag.Flag]shellFlagState
+}
+
+type shellFlagState struct {
+	value   string
+	slice   []string
+	changed bool
+}
+
+func shellCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("shell command does not take arguments"))
+	}
+	if shellClient != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errShellNested)
+	}
+
+	c := mustClientFromCmd(cmd)
+	shellClient = c
+	defer func() {
+		shellClient = nil
+		c.Close()
+	}()
+
+	sh := &shell{root: cmd.Root(), client: c, prefix: shellPrefix}
+	sh.saveFlags()
+	ed := newLineEditor(os.Stdin, os.Stdout, sh.complete)
+
+	var history *os.File
+	if fpath := expandHome(shellHistoryFile); fpath != "" {
+		ed.history = loadShellHistory(fpath)
+		f, err := os.OpenFile(fpath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to open history file (%v)\n", err)
+		} else {
+			history = f
+			defer history.Close()
+		}
+	}
+
+	prompt := ""
+	for {
+		if ed.terminal {
+			prompt = sh.prompt()
+		}
+		line, err := ed.readLine(prompt)
+		if err == errInterrupted {
+			continue
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitIO, err)
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		args, err := splitShellLine(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			continue
+		}
+		if shellRecordable(line, args) {
+			line = strings.TrimRight(line, " \t")
+			ed.addHistory(line)
+			if history != nil {
+				fmt.Fprintln(history, line)
+			}
+		}
+		if !sh.run(args) {
+			return
+		}
+	}
+}
+
+func (sh *shell) prompt() string {
+	if sh.prefix == "" {
+		return "etcdctl> "
+	}
+	return fmt.Sprintf("etcdctl %s> ", sh.prefix)
+}
+
+// run runs a command line and returns false if the shell should exit.
+func (sh *shell) run(args []string) bool {
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "prefix":
+		switch len(args) {
+		case 1:
+			fmt.Println(sh.prefix)
+		case 2:
+			sh.prefix = args[1]
+		default:
+			fmt.Fprintln(os.Stderr, "Error: prefix takes at most one argument")
+		}
+		return true
+	case "shell":
+		fmt.Fprintln(os.Stderr, "Error:", errShellNested)
+		return true
+	}
+	sh.exec(sh.withPrefix(args))
+	return true
+}
+
+// exec runs an etcdctl command. Commands exiting on errors unwind back to the
+// shell instead of terminating the process.
+func (sh *shell) exec(args []string) {
+	osArgs := os.Args
+	// some commands (e.g. watch) inspect the raw command line
+	os.Args = append([]string{osArgs[0]}, args...)
+	cobrautl.Exit = func(code int) { panic(shellExit(code)) }
+	defer func() {
+		os.Args = osArgs
+		cobrautl.Exit = os.Exit
+		sh.restoreFlags()
+		if r := recover(); r != nil {
+			if _, ok := r.(shellExit); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	sh.root.SetArgs(args)
+	// cobra reports errors and usage itself
+	sh.root.Execute()
+}
+
+// withPrefix prepends the default prefix to the key arguments of args. If a
+// command taking a key is given none, the prefix itself is used as the key.
+func (sh *shell) withPrefix(args []string) []string {
+	if sh.prefix == "" {
+		return args
+	}
+	cmd, _, err := sh.root.Find(args)
+	if err != nil || cmd.Parent() != sh.root {
+		return args
+	}
+	n, ok := shellKeyArgs[cmd.Name()]
+	if !ok {
+		return args
+	}
+	// the first positional argument is the command name
+	pos := shellPositionalArgs(cmd, args)
+	if len(pos) > 0 {
+		pos = pos[1:]
+	}
+	if len(pos) == 0 && cmd.Name() != "put" {
+		return append(args, sh.prefix)
+	}
+	out := append([]string{}, args...)
+	for i := 0; i < n && i < len(pos); i++ {
+		out[pos[i]] = sh.prefix + out[pos[i]]
+	}
+	return out
+}
+
+// shellPositionalArgs returns the indexes of the arguments of args which are
+// not flags or flag values of cmd, up to the "--" separator.
+func shellPositionalArgs(cmd *cobra.Command, args []string) []int {
+	lookup := func(name string, short bool) *pflag.Flag {
+		for _, fs := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+			if short {
+				if f := fs.ShorthandLookup(name); f != nil {
+					return f
+				}
+			} else if f := fs.Lookup(name); f != nil {
+				return f
+			}
+		}
+		return nil
+	}
+	var idx []int
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return idx
+		case strings.HasPrefix(a, "--"):
+			if !strings.Contains(a, "=") {
+				if f := lookup(a[2:], false); f != nil && f.NoOptDefVal == "" {
+					i++
+				}
+			}
+		case strings.HasPrefix(a, "-") && len(a) > 1:
+			if len(a) == 2 {
+				if f := lookup(a[1:], true); f != nil && f.NoOptDefVal == "" {
+					i++
+				}
+			}
+		default:
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// visitFlags calls fn on all flags defined on the command tree of the shell.
+func (sh *shell) visitFlags(fn func(*pflag.Flag)) {
+	var visit func(*cobra.Command)
+	visit = func(c *cobra.Command) {
+		c.Flags().VisitAll(fn)
+		c.PersistentFlags().VisitAll(fn)
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(sh.root)
+}
+
+func (sh *shell) saveFlags() {
+	sh.flags = make(map[*pflag.Flag]shellFlagState)
+	sh.visitFlags(func(f *pflag.Flag) {
+		st := shellFlagState{value: f.Value.String(), changed: f.Changed}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			st.slice = sv.GetSlice()
+		}
+		sh.flags[f] = st
+	})
+}
+
+// restoreFlags resets the flags to their state when the shell started. Flags
+// added since then (e.g. the help flags) are reset to their defaults.
+func (sh *shell) restoreFlags() {
+	sh.visitFlags(func(f *pflag.Flag) {
+		st, ok := sh.flags[f]
+		if !ok {
+			if !f.Changed {
+				return
+			}
+			st = shellFlagState{value: f.DefValue}
+		}
+		if f.Value.String() != st.value {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				sv.Replace(st.slice)
+			} else {
+				f.Value.Set(st.value)
+			}
+		}
+		f.Changed = st.changed
+	})
+}
+
+// complete returns the completion candidates of the last word of text: the
+// commands for the first word, flags for words starting with "-", and
+// otherwise subcommands or keys, depending on the command.
+func (sh *shell) complete(text string) ([]string, int) {
+	fields := strings.Fields(text)
+	word := ""
+	if len(fields) > 0 && !strings.HasSuffix(text, " ") {
+		word = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	start := len(text) - len(word)
+
+	var cands []string
+	add := func(s string) {
+		if strings.HasPrefix(s, word) {
+			cands = append(cands, s)
+		}
+	}
+	if len(fields) == 0 {
+		for _, s := range []string{"exit", "prefix", "quit"} {
+			add(s)
+		}
+		for _, c := range sh.root.Commands() {
+			if c.IsAvailableCommand() {
+				add(c.Name())
+			}
+		}
+		return cands, start
+	}
+
+	cmd, _, err := sh.root.Find(fields)
+	if err != nil || cmd == sh.root {
+		return nil, start
+	}
+	if strings.HasPrefix(word, "-") {
+		for _, fs := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				if !f.Hidden && f.Deprecated == "" {
+					add("--" + f.Name)
+				}
+			})
+		}
+		return cands, start
+	}
+	// positional arguments so far, excluding the command path
+	npos := len(shellPositionalArgs(cmd, fields)) - len(strings.Fields(cmd.CommandPath())) + 1
+	if cmd.HasAvailableSubCommands() && npos == 0 {
+		for _, c := range cmd.Commands() {
+			if c.IsAvailableCommand() {
+				add(c.Name())
+			}
+		}
+		return cands, start
+	}
+	if n, ok := shellKeyArgs[cmd.Name()]; ok && cmd.Parent() == sh.root && npos < n {
+		return sh.completeKeys(word), start
+	}
+	return nil, start
+}
+
+// completeKeys returns the keys starting with the default prefix and word,
+// relative to the default prefix. Keys are completed up to the next "/", so
+// that a key hierarchy can be completed level by level.
+func (sh *shell) completeKeys(word string) []string {
+	base := sh.prefix + word
+	start, end := base, clientv3.GetPrefixRangeEnd(base)
+	if start == "" {
+		start = "\x00"
+	}
+
+	ctx, cancel := commandCtx(sh.root)
+	defer cancel()
+
+	var cands []string
+	next := start
+	for len(cands) < shellCompletionLimit {
+		resp, err := sh.client.Get(ctx, next, clientv3.WithRange(end), clientv3.WithKeysOnly(), clientv3.WithLimit(shellCompletionLimit))
+		if err != nil || len(resp.Kvs) == 0 {
+			break
+		}
+		for _, kv := range resp.Kvs {
+			k := string(kv.Key)
+			if k < next {
+				continue
+			}
+			rest := k[len(base):]
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				// skip the other keys below this level
+				rest = rest[:i+1]
+				next = clientv3.GetPrefixRangeEnd(base + rest)
+			} else {
+				next = k + "\x00"
+			}
+			cands = append(cands, word+rest)
+			if len(cands) == shellCompletionLimit || next == "\x00" {
+				return cands
+			}
+		}
+		if !resp.More {
+			break
+		}
+	}
+	return cands
+}
+
+// splitShellLine splits a command line into arguments. Arguments are separated
+// by spaces and may be quoted with single or double quotes; a backslash escapes
+// the next character, except within single quotes.
+func splitShellLine(line string) ([]string, error) {
+	var (
+		args  []string
+		cur   strings.Builder
+		inArg bool
+		quote rune
+		esc   bool
+	)
+	for _, r := range line {
+		switch {
+		case esc:
+			cur.WriteRune(r)
+			esc = false
+		case r == '\\' && quote != '\'':
+			esc, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if esc || quote != 0 {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// shellRecordable returns false for lines that must not be saved to the
+// history: lines starting with a space and commands which may carry passwords.
+func shellRecordable(line string, args []string) bool {
+	if strings.HasPrefix(line, " ") || args[0] == "user" {
+		return false
+	}
+	for _, a := range args {
+		if strings.HasPrefix(a, "--") && strings.Contains(a, "password") {
+			return false
+		}
+	}
+	return true
+}
+
+func loadShellHistory(fpath string) []string {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) > shellHistoryLimit {
+		lines = lines[len(lines)-shellHistoryLimit:]
+	}
+	return lines
+}
+
+func expandHome(fpath string) string {
+	if !strings.HasPrefix(fpath, "~/") {
+		return fpath
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, fpath[2:])
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func Test_splitShellLine(t *testing.T) {
+	tt := []struct {
+		line string
+		args []string
+		werr bool
+	}{
+		{line: "get foo", args: []string{"get", "foo"}},
+		{line: "  put  foo\tbar ", args: []string{"put", "foo", "bar"}},
+		{line: `put /doc '{"state": "running"}'`, args: []string{"put", "/doc", `{"state": "running"}`}},
+		{line: `put foo "a \"b\" c"`, args: []string{"put", "foo", `a "b" c`}},
+		{line: `put foo a\ b ''`, args: []string{"put", "foo", "a b", ""}},
+		{line: `put 'foo`, werr: true},
+		{line: `put foo\`, werr: true},
+	}
+	for i, ts := range tt {
+		args, err := splitShellLine(ts.line)
+		if (err != nil) != ts.werr {
+			t.Errorf("#%d: expected error %v, got %v", i, ts.werr, err)
+		}
+		if !reflect.DeepEqual(args, ts.args) {
+			t.Errorf("#%d: expected %q, got %q", i, ts.args, args)
+		}
+	}
+}
+
+func TestShellWithPrefix(t *testing.T) {
+	root := &cobra.Command{Use: "etcdctl"}
+	root.PersistentFlags().StringP("write-out", "w", "simple", "")
+	root.AddCommand(NewGetCommand(), NewPutCommand(), NewMemberCommand())
+	sh := &shell{root: root, prefix: "/app/"}
+
+	tt := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"get", "foo"}, []string{"get", "/app/foo"}},
+		{[]string{"get", "--rev", "3", "a", "b"}, []string{"get", "--rev", "3", "/app/a", "/app/b"}},
+		{[]string{"-w", "json", "get", "--prefix=true", "a"}, []string{"-w", "json", "get", "--prefix=true", "/app/a"}},
+		{[]string{"get", "--prefix"}, []string{"get", "--prefix", "/app/"}},
+		{[]string{"put", "foo", "bar"}, []string{"put", "/app/foo", "bar"}},
+		{[]string{"member", "list"}, []string{"member", "list"}},
+	}
+	for i, ts := range tt {
+		if got := sh.withPrefix(ts.args); !reflect.DeepEqual(got, ts.want) {
+			t.Errorf("#%d: expected %q, got %q", i, ts.want, got)
+		}
+	}
+}
+
+func TestShellRestoreFlags(t *testing.T) {
+	root := &cobra.Command{Use: "etcdctl"}
+	root.PersistentFlags().StringSlice("endpoints", []string{"127.0.0.1:2379"}, "")
+	root.AddCommand(NewGetCommand())
+	root.SetArgs([]string{"get", "--endpoints", "a:1,b:2", "--prefix", "--limit", "2", "foo"})
+	get, _, _ := root.Find([]string{"get"})
+	get.Run = func(*cobra.Command, []string) {}
+
+	sh := &shell{root: root}
+	sh.saveFlags()
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	sh.restoreFlags()
+	for _, name := range []string{"endpoints", "prefix", "limit"} {
+		f := get.Flags().Lookup(name)
+		if f == nil {
+			f = root.PersistentFlags().Lookup(name)
+		}
+		if f.Changed || f.Value.String() != f.DefValue {
+			t.Errorf("expected flag %q to be reset, got %q (changed %v)", name, f.Value.String(), f.Changed)
+		}
+	}
+}
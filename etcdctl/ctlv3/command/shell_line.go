@@ -0,0 +1,286 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	errNotTerminal = errors.New("not a terminal")
+	errInterrupted = errors.New("interrupted")
+)
+
+// completeFunc returns the candidates replacing the word ending the given
+// text, and the byte offset at which the word starts.
+type completeFunc func(text string) (candidates []string, wordStart int)
+
+// lineEditor reads lines from a terminal with emacs-style editing keys,
+// history navigation and tab completion. If the input is not a terminal,
+// it reads plain lines.
+type lineEditor struct {
+	fd       int
+	in       *bufio.Reader
+	out      io.Writer
+	terminal bool
+
+	history  []string
+	complete completeFunc
+}
+
+func newLineEditor(in *os.File, out io.Writer, complete completeFunc) *lineEditor {
+	e := &lineEditor{fd: int(in.Fd()), in: bufio.NewReader(in), out: out, complete: complete}
+	if restore, err := makeRaw(e.fd); err == nil {
+		restore()
+		e.terminal = true
+	}
+	return e
+}
+
+// addHistory appends a line to the history navigated with the arrow keys.
+func (e *lineEditor) addHistory(line string) {
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+}
+
+// readLine prints the prompt and reads a line. It returns io.EOF at the end
+// of the input or on ctrl-d, and errInterrupted on ctrl-c.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if !e.terminal {
+		line, err := e.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	var (
+		line []rune
+		pos  int
+		// hidx is the position in the history; draft keeps the edited line
+		// while browsing the history.
+		hidx  = len(e.history)
+		draft []rune
+	)
+	setLine := func(s []rune) {
+		line = append([]rune{}, s...)
+		pos = len(line)
+	}
+	e.redraw(prompt, line, pos)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\n")
+			return string(line), nil
+		case 1: // ctrl-a
+			pos = 0
+		case 2: // ctrl-b
+			if pos > 0 {
+				pos--
+			}
+		case 3: // ctrl-c
+			fmt.Fprint(e.out, "^C\n")
+			return "", errInterrupted
+		case 4: // ctrl-d
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case 5: // ctrl-e
+			pos = len(line)
+		case 6: // ctrl-f
+			if pos < len(line) {
+				pos++
+			}
+		case 8, 127: // backspace
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case '\t':
+			line, pos = e.completeLine(line, pos)
+		case 11: // ctrl-k
+			line = line[:pos]
+		case 12: // ctrl-l
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 14: // ctrl-n
+			if hidx < len(e.history) {
+				hidx++
+				if hidx == len(e.history) {
+					setLine(draft)
+				} else {
+					setLine([]rune(e.history[hidx]))
+				}
+			}
+		case 16: // ctrl-p
+			if hidx > 0 {
+				if hidx == len(e.history) {
+					draft = append([]rune{}, line...)
+				}
+				hidx--
+				setLine([]rune(e.history[hidx]))
+			}
+		case 21: // ctrl-u
+			line = append([]rune{}, line[pos:]...)
+			pos = 0
+		case 23: // ctrl-w
+			i := pos
+			for i > 0 && line[i-1] == ' ' {
+				i--
+			}
+			for i > 0 && line[i-1] != ' ' {
+				i--
+			}
+			line = append(line[:i], line[pos:]...)
+			pos = i
+		case 27: // escape sequences of the arrow, home, end and delete keys
+			switch e.readEscape() {
+			case 'A':
+				if hidx > 0 {
+					if hidx == len(e.history) {
+						draft = append([]rune{}, line...)
+					}
+					hidx--
+					setLine([]rune(e.history[hidx]))
+				}
+			case 'B':
+				if hidx < len(e.history) {
+					hidx++
+					if hidx == len(e.history) {
+						setLine(draft)
+					} else {
+						setLine([]rune(e.history[hidx]))
+					}
+				}
+			case 'C':
+				if pos < len(line) {
+					pos++
+				}
+			case 'D':
+				if pos > 0 {
+					pos--
+				}
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(line)
+			case '~':
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if r < ' ' {
+				continue
+			}
+			line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+		}
+		e.redraw(prompt, line, pos)
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns its final
+// byte; the home, end and delete keys are mapped to 'H', 'F' and '~'.
+func (e *lineEditor) readEscape() byte {
+	b, err := e.in.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return 0
+	}
+	var num []byte
+	for {
+		if b, err = e.in.ReadByte(); err != nil {
+			return 0
+		}
+		if b < '0' || b > '9' {
+			break
+		}
+		num = append(num, b)
+	}
+	if b != '~' {
+		return b
+	}
+	switch string(num) {
+	case "1", "7":
+		return 'H'
+	case "4", "8":
+		return 'F'
+	case "3":
+		return '~'
+	}
+	return 0
+}
+
+func (e *lineEditor) redraw(prompt string, line []rune, pos int) {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", prompt, string(line))
+	if n := len(line) - pos; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", n)
+	}
+}
+
+// completeLine completes the word before the cursor. The word is replaced by
+// the common prefix of all candidates; if that does not extend the word, the
+// candidates are listed below the line.
+func (e *lineEditor) completeLine(line []rune, pos int) ([]rune, int) {
+	if e.complete == nil {
+		return line, pos
+	}
+	text := string(line[:pos])
+	cands, start := e.complete(text)
+	if len(cands) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return line, pos
+	}
+	word := text[start:]
+	common := cands[0]
+	for _, c := range cands[1:] {
+		for !strings.HasPrefix(c, common) {
+			_, size := utf8.DecodeLastRuneInString(common)
+			common = common[:len(common)-size]
+		}
+	}
+	if len(cands) == 1 && !strings.HasSuffix(common, "/") {
+		common += " "
+	}
+	if len(common) > len(word) {
+		wstart := utf8.RuneCountInString(text[:start])
+		nl := append(append([]rune{}, line[:wstart]...), []rune(common)...)
+		npos := len(nl)
+		return append(nl, line[pos:]...), npos
+	}
+	fmt.Fprintf(e.out, "\n%s\n", strings.Join(cands, "  "))
+	return line, pos
+}
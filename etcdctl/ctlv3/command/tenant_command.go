@@ -0,0 +1,61 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+// NewTenantCommand returns the cobra command for "tenant".
+func NewTenantCommand() *cobra.Command {
+	tc := &cobra.Command{
+		Use:   "tenant <subcommand>",
+		Short: "Tenant related commands",
+	}
+
+	tc.AddCommand(NewTenantStatusCommand())
+
+	return tc
+}
+
+func NewTenantStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Prints the users, the number of keys and the size of each tenant",
+		Long: `Prints the users, the number of keys and the size of each tenant.
+
+Users created with "user add --prefix" are bound to the tenant of the prefix:
+the keys of their requests are confined under the prefix by the server.
+`,
+		Run: tenantStatusCommandFunc,
+	}
+}
+
+// tenantStatusCommandFunc executes the "tenant status" command.
+func tenantStatusCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("tenant status command accepts no arguments"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).TenantStatus(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.TenantStatus(*resp)
+}
@@ -51,6 +51,7 @@ var (
 	passwordInteractive bool
 	passwordFromFlag    string
 	noPassword          bool
+	userPrefix          string
 )
 
 func newUserAddCommand() *cobra.Command {
@@ -63,6 +64,7 @@ func newUserAddCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&passwordInteractive, "interactive", true, "Read password from stdin instead of interactive terminal")
 	cmd.Flags().StringVar(&passwordFromFlag, "new-user-password", "", "Supply password from the command line flag")
 	cmd.Flags().BoolVar(&noPassword, "no-password", false, "Create a user without password (CN based auth only)")
+	cmd.Flags().StringVar(&userPrefix, "prefix", "", "Bind the user to the tenant of the keys under the prefix")
 
 	return &cmd
 }
@@ -162,6 +164,8 @@ func userAddCommandFunc(cmd *cobra.Command, args []string) {
 		options.NoPassword = true
 	}
 
+	options.Prefix = []byte(userPrefix)
+
 	resp, err := mustClientFromCmd(cmd).Auth.UserAddWithOptions(context.TODO(), user, password, options)
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
@@ -42,6 +42,8 @@ var (
 	watchInteractive bool
 	watchPrevKey     bool
 	progressNotify   bool
+	watchCoalesce    bool
+	watchMaxEvents   int64
 )
 
 // NewWatchCommand returns the cobra command for "watch".
@@ -57,6 +59,8 @@ func NewWatchCommand() *cobra.Command {
 	cmd.Flags().Int64Var(&watchRev, "rev", 0, "Revision to start watching")
 	cmd.Flags().BoolVar(&watchPrevKey, "prev-kv", false, "get the previous key-value pair before the event happens")
 	cmd.Flags().BoolVar(&progressNotify, "progress-notify", false, "get periodic watch progress notification from server")
+	cmd.Flags().BoolVar(&watchCoalesce, "coalesce", false, "coalesce the updates of a key into its latest value when not keeping up with them")
+	cmd.Flags().Int64Var(&watchMaxEvents, "max-events-per-second", 0, "cap the events received per second, coalescing the events over the rate")
 
 	return cmd
 }
@@ -85,7 +89,7 @@ func watchCommandFunc(cmd *cobra.Command, args []string) {
 	}
 
 	printWatchCh(c, wc, execArgs)
-	if err = c.Close(); err != nil {
+	if err = releaseClient(c); err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitBadConnection, err)
 	}
 	cobrautl.ExitWithError(cobrautl.ExitInterrupted, fmt.Errorf("watch is canceled by the server"))
@@ -160,6 +164,12 @@ func getWatchChan(c *clientv3.Client, args []string) (clientv3.WatchChan, error)
 	if progressNotify {
 		opts = append(opts, clientv3.WithProgressNotify())
 	}
+	if watchCoalesce {
+		opts = append(opts, clientv3.WithCoalesce())
+	}
+	if watchMaxEvents > 0 {
+		opts = append(opts, clientv3.WithMaxEventsPerSecond(watchMaxEvents))
+	}
 	return c.Watch(clientv3.WithRequireLeader(context.Background()), key, opts...), nil
 }
 
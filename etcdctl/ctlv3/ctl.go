@@ -79,8 +79,15 @@ func init() {
 		command.NewPutCommand(),
 		command.NewDelCommand(),
 		command.NewTxnCommand(),
+		command.NewExportCommand(),
+		command.NewDiffCommand(),
+		command.NewApplyCommand(),
 		command.NewCompactionCommand(),
 		command.NewAlarmCommand(),
+		command.NewRetentionCommand(),
+		command.NewSchemaCommand(),
+		command.NewTenantCommand(),
+		command.NewConfigCommand(),
 		command.NewDefragCommand(),
 		command.NewEndpointCommand(),
 		command.NewMoveLeaderCommand(),
@@ -92,11 +99,13 @@ func init() {
 		command.NewMakeMirrorCommand(),
 		command.NewLockCommand(),
 		command.NewElectCommand(),
+		command.NewQueueCommand(),
 		command.NewAuthCommand(),
 		command.NewUserCommand(),
 		command.NewRoleCommand(),
 		command.NewCheckCommand(),
 		command.NewCompletionCommand(),
+		command.NewShellCommand(),
 	)
 }
 
@@ -34,7 +34,12 @@ const (
 	ExitClusterNotHealthy = 5
 )
 
+// Exit is called by ExitWithError to terminate the program. Programs running
+// several commands in one process may replace it with a function that unwinds
+// the failed command, e.g. by panicking; it must not return.
+var Exit = os.Exit
+
 func ExitWithError(code int, err error) {
 	fmt.Fprintln(os.Stderr, "Error:", err)
-	os.Exit(code)
+	Exit(code)
 }
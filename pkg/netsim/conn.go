@@ -0,0 +1,290 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netsim
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type chunk struct {
+	data []byte
+	at   time.Time // when data becomes readable
+}
+
+// pipe is one direction of a connection.
+type pipe struct {
+	mu     sync.Mutex
+	chunks []chunk
+	last   time.Time // delivery time of the last chunk, keeps data in order
+	eof    bool      // the writer closed its end
+	err    error     // the pipe is broken; reads and writes fail with err
+	wakec  chan struct{}
+}
+
+func newPipe() *pipe {
+	return &pipe{wakec: make(chan struct{}, 1)}
+}
+
+func (p *pipe) wake() {
+	select {
+	case p.wakec <- struct{}{}:
+	default:
+	}
+}
+
+func (p *pipe) write(b []byte, delay time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	if p.eof {
+		return net.ErrClosed
+	}
+	at := time.Now().Add(delay)
+	if at.Before(p.last) {
+		at = p.last
+	}
+	p.last = at
+	p.chunks = append(p.chunks, chunk{data: append([]byte(nil), b...), at: at})
+	p.wake()
+	return nil
+}
+
+func (p *pipe) close(eof bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if eof {
+		p.eof = true
+	}
+	if err != nil && p.err == nil {
+		p.err = err
+		p.chunks = nil
+	}
+	p.wake()
+}
+
+// conn is one end of an in-memory connection.
+type conn struct {
+	n *Network
+
+	localHost, remoteHost string
+	local, remote         Addr
+	rand                  *rand.Rand // decides the fate of writes, guarded by wmu
+
+	rd, wr *pipe
+	peer   *conn
+
+	wmu sync.Mutex // serializes writes
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	closeOnce sync.Once
+	closedc   chan struct{}
+}
+
+func newConnPair(n *Network, clientHost string, clientAddr Addr, clientRand *rand.Rand, serverHost string, serverAddr Addr, serverRand *rand.Rand) (client, server *conn) {
+	up, down := newPipe(), newPipe()
+	client = &conn{
+		n:          n,
+		localHost:  clientHost,
+		remoteHost: serverHost,
+		local:      clientAddr,
+		remote:     serverAddr,
+		rand:       clientRand,
+		rd:         down,
+		wr:         up,
+		closedc:    make(chan struct{}),
+	}
+	server = &conn{
+		n:          n,
+		localHost:  serverHost,
+		remoteHost: clientHost,
+		local:      serverAddr,
+		remote:     clientAddr,
+		rand:       serverRand,
+		rd:         up,
+		wr:         down,
+		closedc:    make(chan struct{}),
+	}
+	client.peer, server.peer = server, client
+	return client, server
+}
+
+func (c *conn) opErr(op string, err error) error {
+	return &net.OpError{Op: op, Net: "tcp", Source: c.local, Addr: c.remote, Err: err}
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-c.closedc:
+			return 0, c.opErr("read", net.ErrClosed)
+		default:
+		}
+
+		c.mu.Lock()
+		deadline := c.readDeadline
+		c.mu.Unlock()
+
+		now := time.Now()
+		p := c.rd
+		p.mu.Lock()
+		if p.err != nil {
+			err := p.err
+			p.mu.Unlock()
+			return 0, c.opErr("read", err)
+		}
+		if len(p.chunks) > 0 && !now.Before(p.chunks[0].at) {
+			n := copy(b, p.chunks[0].data)
+			if n == len(p.chunks[0].data) {
+				p.chunks = p.chunks[1:]
+			} else {
+				p.chunks[0].data = p.chunks[0].data[n:]
+			}
+			p.mu.Unlock()
+			return n, nil
+		}
+		if len(p.chunks) == 0 && p.eof {
+			p.mu.Unlock()
+			return 0, io.EOF
+		}
+		var wait time.Duration = -1
+		if len(p.chunks) > 0 {
+			wait = p.chunks[0].at.Sub(now)
+		}
+		p.mu.Unlock()
+
+		if !deadline.IsZero() {
+			left := deadline.Sub(now)
+			if left <= 0 {
+				return 0, c.opErr("read", os.ErrDeadlineExceeded)
+			}
+			if wait < 0 || left < wait {
+				wait = left
+			}
+		}
+
+		var timerc <-chan time.Time
+		if wait >= 0 {
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(wait)
+			}
+			timerc = timer.C
+		}
+		select {
+		case <-p.wakec:
+		case <-timerc:
+		case <-c.closedc:
+		}
+	}
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	select {
+	case <-c.closedc:
+		return 0, c.opErr("write", net.ErrClosed)
+	default:
+	}
+	c.mu.Lock()
+	deadline := c.writeDeadline
+	c.mu.Unlock()
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, c.opErr("write", os.ErrDeadlineExceeded)
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	delay, err := c.n.sendDelay(c.localHost, c.remoteHost, c.rand)
+	if err != nil {
+		c.reset()
+		c.n.forget(c)
+		c.n.forget(c.peer)
+		return 0, c.opErr("write", err)
+	}
+	if err = c.wr.write(b, delay); err != nil {
+		return 0, c.opErr("write", err)
+	}
+	return len(b), nil
+}
+
+// reset breaks both directions of the connection, discarding data in flight.
+func (c *conn) reset() {
+	c.rd.close(false, ErrConnReset)
+	c.wr.close(false, ErrConnReset)
+}
+
+func (c *conn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closedc)
+		// the peer reads the data in flight and then EOF,
+		// and fails to write to the closed end
+		c.wr.close(true, nil)
+		c.rd.close(false, ErrBrokenPipe)
+		c.n.forget(c)
+	})
+	return nil
+}
+
+func (c *conn) LocalAddr() net.Addr  { return c.local }
+func (c *conn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *conn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline, c.writeDeadline = t, t
+	c.mu.Unlock()
+	c.rd.wake()
+	return nil
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	c.rd.wake()
+	return nil
+}
+
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return nil
+}
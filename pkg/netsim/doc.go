@@ -0,0 +1,42 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package netsim implements an in-memory network for testing distributed
+// behavior in a single process.
+//
+// A Network connects named hosts. Each host listens and dials through its
+// Host handle, and the network decides per directed link between two hosts
+// how long written bytes take to reach the other end, whether connections
+// are reset, and whether the hosts can reach each other at all:
+//
+//	n := netsim.New(seed)
+//	a, b := n.Host("a"), n.Host("b")
+//	l, _ := a.Listen("tcp", "10.0.0.1:2380")
+//	conn, _ := b.DialContext(ctx, "tcp", "10.0.0.1:2380")
+//	n.SetLink("b", "a", netsim.LinkConfig{Latency: 10 * time.Millisecond})
+//	n.Isolate("a")
+//
+// Connections behave like TCP: bytes written on one connection are read in
+// order, but since every write is delayed independently, data sent over
+// different connections between the same hosts may arrive out of order.
+// A byte stream cannot lose bytes, so message drops are simulated by
+// resetting the connection the write was sent on; protocols running over
+// it see the loss when they reconnect.
+//
+// Latency jitter and drops are drawn from random sources derived from the
+// network seed, the link and the index of the connection on the link, so
+// the decisions for a given connection do not depend on traffic on other
+// links. Time is not simulated and goroutine scheduling still varies between
+// runs, so the seed makes faults reproducible rather than whole test runs.
+package netsim
@@ -0,0 +1,214 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netsim
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func listenAccept(t *testing.T, h *Host, addr string) (net.Listener, <-chan net.Conn) {
+	l, err := h.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptc := make(chan net.Conn, 16)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				close(acceptc)
+				return
+			}
+			acceptc <- c
+		}
+	}()
+	t.Cleanup(func() { l.Close() })
+	return l, acceptc
+}
+
+func dialPair(t *testing.T, n *Network) (client, server net.Conn) {
+	_, acceptc := listenAccept(t, n.Host("a"), "10.0.0.1:2380")
+	client, err := n.Host("b").Dial("tcp", "10.0.0.1:2380")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = <-acceptc
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+func TestConnReadWrite(t *testing.T) {
+	n := New(1)
+	client, server := dialPair(t, n)
+
+	if _, err := client.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 3)
+	for _, want := range []string{"hel", "lo"} {
+		m, err := server.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:m]); got != want {
+			t.Fatalf("read %q, want %q", got, want)
+		}
+	}
+	if server.RemoteAddr().String() != client.LocalAddr().String() {
+		t.Fatalf("server remote address %q, client local address %q", server.RemoteAddr(), client.LocalAddr())
+	}
+
+	client.Close()
+	if _, err := server.Read(buf); err != io.EOF {
+		t.Fatalf("read after peer close: %v, want EOF", err)
+	}
+	if _, err := server.Write([]byte("x")); !errors.Is(err, ErrBrokenPipe) {
+		t.Fatalf("write after peer close: %v, want %v", err, ErrBrokenPipe)
+	}
+}
+
+func TestConnLatencyAndOrder(t *testing.T) {
+	n := New(1)
+	n.SetLink("b", "a", LinkConfig{Latency: 50 * time.Millisecond, Jitter: 50 * time.Millisecond})
+	client, server := dialPair(t, n)
+
+	start := time.Now()
+	for i := byte(0); i < 10; i++ {
+		if _, err := client.Write([]byte{i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := make([]byte, 1)
+	for i := byte(0); i < 10; i++ {
+		if _, err := io.ReadFull(server, buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf[0] != i {
+			t.Fatalf("read %d, want %d", buf[0], i)
+		}
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("data delivered after %v, want at least the link latency", d)
+	}
+}
+
+func TestConnReadDeadline(t *testing.T) {
+	n := New(1)
+	client, _ := dialPair(t, n)
+
+	client.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	_, err := client.Read(make([]byte, 1))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("read: %v, want deadline exceeded", err)
+	}
+	var nerr net.Error
+	if !errors.As(err, &nerr) || !nerr.Timeout() {
+		t.Fatalf("read error %v is not a timeout", err)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	n := New(1)
+	client, server := dialPair(t, n)
+
+	n.Isolate("a")
+	if _, err := server.Read(make([]byte, 1)); !errors.Is(err, ErrConnReset) {
+		t.Fatalf("read on isolated host: %v, want %v", err, ErrConnReset)
+	}
+	if _, err := client.Write([]byte("x")); !errors.Is(err, ErrConnReset) {
+		t.Fatalf("write to isolated host: %v, want %v", err, ErrConnReset)
+	}
+	if _, err := n.Host("b").Dial("tcp", "10.0.0.1:2380"); !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("dial isolated host: %v, want %v", err, ErrUnreachable)
+	}
+	if _, err := n.Host("c").Dial("tcp", "10.0.0.1:2380"); !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("dial isolated host from new host: %v, want %v", err, ErrUnreachable)
+	}
+
+	n.Heal()
+	n.Partition([]string{"a", "c"}, []string{"b"})
+	if _, err := n.Host("b").Dial("tcp", "10.0.0.1:2380"); !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("dial across partition: %v, want %v", err, ErrUnreachable)
+	}
+	c, err := n.Host("c").Dial("tcp", "10.0.0.1:2380")
+	if err != nil {
+		t.Fatalf("dial within partition: %v", err)
+	}
+	c.Close()
+
+	n.Heal()
+	c, err = n.Host("b").Dial("tcp", "10.0.0.1:2380")
+	if err != nil {
+		t.Fatalf("dial after heal: %v", err)
+	}
+	c.Close()
+}
+
+func TestDropDeterministic(t *testing.T) {
+	writesUntilReset := func(seed int64) int {
+		n := New(seed)
+		n.SetDefaultLink(LinkConfig{DropRate: 0.1})
+		client, _ := dialPair(t, n)
+		for i := 0; ; i++ {
+			if _, err := client.Write([]byte("x")); err != nil {
+				if !errors.Is(err, ErrConnReset) {
+					t.Fatalf("write: %v, want %v", err, ErrConnReset)
+				}
+				return i
+			}
+		}
+	}
+	for seed := int64(0); seed < 5; seed++ {
+		if a, b := writesUntilReset(seed), writesUntilReset(seed); a != b {
+			t.Fatalf("seed %d: connection reset after %d and %d writes", seed, a, b)
+		}
+	}
+}
+
+func TestListen(t *testing.T) {
+	n := New(1)
+	h := n.Host("a")
+	l, err := h.Listen("tcp", "10.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.Listen("tcp", l.Addr().String()); !errors.Is(err, ErrAddrInUse) {
+		t.Fatalf("listen on used address: %v, want %v", err, ErrAddrInUse)
+	}
+	l.Close()
+	if _, err = l.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("accept on closed listener: %v, want %v", err, net.ErrClosed)
+	}
+	if _, err = h.Dial("tcp", l.Addr().String()); !errors.Is(err, ErrConnRefused) {
+		t.Fatalf("dial closed listener: %v, want %v", err, ErrConnRefused)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n.SetDefaultLink(LinkConfig{Latency: time.Second})
+	listenAccept(t, h, "10.0.0.1:2380")
+	if _, err = n.Host("b").DialContext(ctx, "tcp", "10.0.0.1:2380"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("dial with canceled context: %v, want %v", err, context.Canceled)
+	}
+}
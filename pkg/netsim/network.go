@@ -0,0 +1,392 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netsim
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrConnRefused = errors.New("netsim: connection refused")
+	ErrUnreachable = errors.New("netsim: host unreachable")
+	ErrConnReset   = errors.New("netsim: connection reset by peer")
+	ErrAddrInUse   = errors.New("netsim: address already in use")
+	ErrBrokenPipe  = errors.New("netsim: broken pipe")
+)
+
+// firstEphemeralPort is the first port given to listeners on port 0
+// and to the local end of dialed connections.
+const firstEphemeralPort = 32768
+
+// acceptBacklog is the number of dialed connections a listener
+// holds before refusing new ones.
+const acceptBacklog = 128
+
+// LinkConfig describes the behavior of a directed link between two hosts.
+type LinkConfig struct {
+	// Latency is the minimum time written bytes take to become readable.
+	Latency time.Duration
+	// Jitter is the maximum random delay added to Latency.
+	Jitter time.Duration
+	// DropRate is the probability, between 0 and 1, that a write
+	// resets its connection instead of being delivered.
+	DropRate float64
+}
+
+func (lc LinkConfig) delay(r *rand.Rand) time.Duration {
+	d := lc.Latency
+	if lc.Jitter > 0 {
+		d += time.Duration(r.Int63n(int64(lc.Jitter) + 1))
+	}
+	return d
+}
+
+type link struct {
+	from, to string
+}
+
+// Network is an in-memory network connecting named hosts.
+type Network struct {
+	seed int64
+
+	mu          sync.Mutex
+	defaultLink LinkConfig
+	links       map[link]LinkConfig
+	linkConns   map[link]int64
+	cut         map[link]struct{}
+	isolated    map[string]struct{}
+	listeners   map[string]*listener
+	conns       map[*conn]struct{}
+	nextPort    int
+}
+
+// New creates a network whose random decisions are derived from seed.
+func New(seed int64) *Network {
+	return &Network{
+		seed:      seed,
+		links:     make(map[link]LinkConfig),
+		linkConns: make(map[link]int64),
+		cut:       make(map[link]struct{}),
+		isolated:  make(map[string]struct{}),
+		listeners: make(map[string]*listener),
+		conns:     make(map[*conn]struct{}),
+		nextPort:  firstEphemeralPort,
+	}
+}
+
+// Host returns the handle the named host uses to listen and dial.
+// Hosts need no registration; the same name always refers to the same host.
+func (n *Network) Host(name string) *Host {
+	return &Host{n: n, name: name}
+}
+
+// SetDefaultLink sets the behavior of links without their own configuration.
+func (n *Network) SetDefaultLink(cfg LinkConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.defaultLink = cfg
+}
+
+// SetLink sets the behavior of data sent from host "from" to host "to".
+// It applies to existing connections from their next write.
+func (n *Network) SetLink(from, to string, cfg LinkConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.links[link{from, to}] = cfg
+}
+
+// ResetLinks drops all per-link configuration.
+func (n *Network) ResetLinks() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.links = make(map[link]LinkConfig)
+}
+
+// Cut partitions hosts a and b from each other. Existing connections
+// between them are reset and new dials fail.
+func (n *Network) Cut(a, b string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cut[link{a, b}] = struct{}{}
+	n.cut[link{b, a}] = struct{}{}
+	n.resetUnreachableLocked()
+}
+
+// Isolate partitions the host from all other hosts, including ones
+// that first appear after the call.
+func (n *Network) Isolate(host string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.isolated[host] = struct{}{}
+	n.resetUnreachableLocked()
+}
+
+// Partition splits the named hosts into the given groups, cutting every
+// pair of hosts that are in different groups.
+func (n *Network) Partition(groups ...[]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i, g := range groups {
+		for _, o := range groups[i+1:] {
+			for _, a := range g {
+				for _, b := range o {
+					n.cut[link{a, b}] = struct{}{}
+					n.cut[link{b, a}] = struct{}{}
+				}
+			}
+		}
+	}
+	n.resetUnreachableLocked()
+}
+
+// Heal removes all cuts and isolations.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cut = make(map[link]struct{})
+	n.isolated = make(map[string]struct{})
+}
+
+func (n *Network) reachableLocked(from, to string) bool {
+	if from == to {
+		return true
+	}
+	if _, ok := n.isolated[from]; ok {
+		return false
+	}
+	if _, ok := n.isolated[to]; ok {
+		return false
+	}
+	_, ok := n.cut[link{from, to}]
+	return !ok
+}
+
+func (n *Network) resetUnreachableLocked() {
+	for c := range n.conns {
+		if !n.reachableLocked(c.localHost, c.remoteHost) {
+			c.reset()
+			delete(n.conns, c)
+			delete(n.conns, c.peer)
+		}
+	}
+}
+
+func (n *Network) linkLocked(from, to string) LinkConfig {
+	if cfg, ok := n.links[link{from, to}]; ok {
+		return cfg
+	}
+	return n.defaultLink
+}
+
+// randLocked returns the random source of the next connection on the link.
+func (n *Network) randLocked(from, to string) *rand.Rand {
+	l := link{from, to}
+	idx := n.linkConns[l]
+	n.linkConns[l]++
+
+	h := fnv.New64a()
+	h.Write([]byte(from))
+	h.Write([]byte{0})
+	h.Write([]byte(to))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(idx, 10)))
+	return rand.New(rand.NewSource(n.seed ^ int64(h.Sum64())))
+}
+
+func (n *Network) portLocked() int {
+	p := n.nextPort
+	n.nextPort++
+	return p
+}
+
+// sendDelay decides the fate of a write of the connection direction from host
+// "from" to host "to", returning the delay of the data or ErrConnReset.
+func (n *Network) sendDelay(from, to string, r *rand.Rand) (time.Duration, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.reachableLocked(from, to) {
+		return 0, ErrConnReset
+	}
+	cfg := n.linkLocked(from, to)
+	if cfg.DropRate > 0 && r.Float64() < cfg.DropRate {
+		return 0, ErrConnReset
+	}
+	return cfg.delay(r), nil
+}
+
+func (n *Network) forget(c *conn) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.conns, c)
+}
+
+// Host is a host attached to a Network.
+type Host struct {
+	n    *Network
+	name string
+}
+
+// Name returns the name of the host.
+func (h *Host) Name() string { return h.name }
+
+// Listen announces on the address, which must be of the form "host:port".
+// Dialers reach the listener by the exact address string, so the host part
+// is not resolved and may be any name. Port 0 picks an unused port.
+func (h *Host) Listen(network, addr string) (net.Listener, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, &net.OpError{Op: "listen", Net: network, Err: err}
+	}
+	n := h.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if port == "0" {
+		addr = net.JoinHostPort(host, strconv.Itoa(n.portLocked()))
+	}
+	if _, ok := n.listeners[addr]; ok {
+		return nil, &net.OpError{Op: "listen", Net: network, Addr: Addr(addr), Err: ErrAddrInUse}
+	}
+	l := &listener{
+		n:       n,
+		host:    h.name,
+		addr:    Addr(addr),
+		acceptc: make(chan *conn, acceptBacklog),
+		donec:   make(chan struct{}),
+	}
+	n.listeners[addr] = l
+	return l, nil
+}
+
+// Dial connects to the address on the network.
+func (h *Host) Dial(network, addr string) (net.Conn, error) {
+	return h.DialContext(context.Background(), network, addr)
+}
+
+// DialAddr connects to the address; its signature matches the dialer
+// expected by grpc.WithContextDialer.
+func (h *Host) DialAddr(ctx context.Context, addr string) (net.Conn, error) {
+	return h.DialContext(ctx, "tcp", addr)
+}
+
+// DialContext connects to the address, failing if no listener is
+// bound to it or if the listening host is unreachable. Establishing
+// the connection takes one round trip on the link.
+func (h *Host) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	n := h.n
+	opErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: network, Addr: Addr(addr), Err: err}
+	}
+
+	n.mu.Lock()
+	l, ok := n.listeners[addr]
+	if !ok {
+		n.mu.Unlock()
+		return nil, opErr(ErrConnRefused)
+	}
+	if !n.reachableLocked(h.name, l.host) {
+		n.mu.Unlock()
+		return nil, opErr(ErrUnreachable)
+	}
+	out := n.randLocked(h.name, l.host)
+	in := n.randLocked(l.host, h.name)
+	rtt := n.linkLocked(h.name, l.host).delay(out) + n.linkLocked(l.host, h.name).delay(in)
+	local := Addr(net.JoinHostPort(h.name, strconv.Itoa(n.portLocked())))
+	n.mu.Unlock()
+
+	if rtt > 0 {
+		t := time.NewTimer(rtt)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, opErr(ctx.Err())
+		}
+	}
+
+	c, s := newConnPair(n, h.name, local, out, l.host, l.addr, in)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.listeners[addr] != l {
+		return nil, opErr(ErrConnRefused)
+	}
+	if !n.reachableLocked(h.name, l.host) {
+		return nil, opErr(ErrUnreachable)
+	}
+	select {
+	case l.acceptc <- s:
+	default:
+		return nil, opErr(ErrConnRefused)
+	}
+	n.conns[c] = struct{}{}
+	n.conns[s] = struct{}{}
+	return c, nil
+}
+
+type listener struct {
+	n       *Network
+	host    string
+	addr    Addr
+	acceptc chan *conn
+
+	closeOnce sync.Once
+	donec     chan struct{}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.acceptc:
+		return c, nil
+	case <-l.donec:
+		return nil, &net.OpError{Op: "accept", Net: l.addr.Network(), Addr: l.addr, Err: net.ErrClosed}
+	}
+}
+
+func (l *listener) Close() error {
+	l.closeOnce.Do(func() {
+		l.n.mu.Lock()
+		if l.n.listeners[string(l.addr)] == l {
+			delete(l.n.listeners, string(l.addr))
+		}
+		l.n.mu.Unlock()
+		close(l.donec)
+		// reset connections dialed but never accepted
+		for {
+			select {
+			case c := <-l.acceptc:
+				c.reset()
+				l.n.forget(c)
+				l.n.forget(c.peer)
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func (l *listener) Addr() net.Addr { return l.addr }
+
+// Addr is the address of an end of a connection on a Network.
+type Addr string
+
+func (a Addr) Network() string { return "tcp" }
+func (a Addr) String() string  { return string(a) }
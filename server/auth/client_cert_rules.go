@@ -0,0 +1,165 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The fields of the client certificates the rules match.
+const (
+	CertFieldCN       = "cn"
+	CertFieldOU       = "ou"
+	CertFieldO        = "o"
+	CertFieldDNSSAN   = "dns-san"
+	CertFieldURISAN   = "uri-san"
+	CertFieldEmailSAN = "email-san"
+)
+
+// ClientCertRule maps the client certificates with a field matching a regular
+// expression to a user, and optionally to roles granted to the user for the
+// requests authenticated with the certificates.
+type ClientCertRule struct {
+	// Field is the field of the certificate the rule matches: "cn", "ou", "o",
+	// "dns-san", "uri-san" or "email-san". The rule matches if any value of the
+	// field matches.
+	Field string `json:"field"`
+	// Match is the regular expression the whole value of the field must match.
+	// Any value matches if empty.
+	Match string `json:"match,omitempty"`
+	// User is the name of the user, where $1 or ${name} are replaced with the
+	// submatches of Match as in regexp.Expand. It is the value of the field if empty.
+	User string `json:"user,omitempty"`
+	// Roles are the names of the roles granted to the user for the requests, in
+	// addition to its own roles, expanded as User. The user does not need to
+	// exist if it is granted roles. The root role cannot be granted by the rules:
+	// it is dropped if a role expands to it.
+	Roles []string `json:"roles,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// ClientCertRules map the client certificates to users and roles with the
+// first rule matching them. Nil rules map the certificates to the user of
+// their common name.
+type ClientCertRules struct {
+	rules []*ClientCertRule
+}
+
+// NewClientCertRules checks and compiles the rules.
+func NewClientCertRules(rules []ClientCertRule) (*ClientCertRules, error) {
+	cr := &ClientCertRules{}
+	for i := range rules {
+		r := rules[i]
+		switch r.Field {
+		case CertFieldCN, CertFieldOU, CertFieldO, CertFieldDNSSAN, CertFieldURISAN, CertFieldEmailSAN:
+		default:
+			return nil, fmt.Errorf("rule %d: unknown certificate field %q", i, r.Field)
+		}
+		match := r.Match
+		if match == "" {
+			match = ".*"
+		}
+		re, err := regexp.Compile("^(?:" + match + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i, err)
+		}
+		r.re = re
+		if r.User == "" {
+			r.User = "$0"
+		}
+		for _, role := range r.Roles {
+			if role == rootRole {
+				return nil, fmt.Errorf("rule %d: cannot grant the %q role", i, rootRole)
+			}
+		}
+		cr.rules = append(cr.rules, &r)
+	}
+	return cr, nil
+}
+
+// ReadClientCertRules reads the rules from a YAML or JSON file holding a
+// "rules" list.
+func ReadClientCertRules(path string) (*ClientCertRules, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Rules []ClientCertRule `json:"rules"`
+	}
+	if err = yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("cannot parse client certificate rules %q: %v", path, err)
+	}
+	return NewClientCertRules(f.Rules)
+}
+
+// identity returns the user and the roles of the certificate, false if no
+// rule matches it.
+func (cr *ClientCertRules) identity(cert *x509.Certificate) (user string, roles []string, ok bool) {
+	if cr == nil {
+		return cert.Subject.CommonName, nil, true
+	}
+	for _, r := range cr.rules {
+		for _, v := range certFieldValues(cert, r.Field) {
+			m := r.re.FindStringSubmatchIndex(v)
+			if m == nil {
+				continue
+			}
+			user = string(r.re.ExpandString(nil, r.User, v, m))
+			if user == "" {
+				continue
+			}
+			for _, role := range r.Roles {
+				// the root role would be granted by the certificate field
+				if role = string(r.re.ExpandString(nil, role, v, m)); role != "" && role != rootRole {
+					roles = append(roles, role)
+				}
+			}
+			return user, roles, true
+		}
+	}
+	return "", nil, false
+}
+
+func certFieldValues(cert *x509.Certificate, field string) []string {
+	switch field {
+	case CertFieldCN:
+		if cert.Subject.CommonName == "" {
+			return nil
+		}
+		return []string{cert.Subject.CommonName}
+	case CertFieldOU:
+		return cert.Subject.OrganizationalUnit
+	case CertFieldO:
+		return cert.Subject.Organization
+	case CertFieldDNSSAN:
+		return cert.DNSNames
+	case CertFieldURISAN:
+		uris := make([]string, len(cert.URIs))
+		for i, u := range cert.URIs {
+			uris[i] = u.String()
+		}
+		return uris
+	case CertFieldEmailSAN:
+		return cert.EmailAddresses
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientCertRules(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/payments/sa/api")
+	rules, err := NewClientCertRules([]ClientCertRule{
+		{Field: CertFieldURISAN, Match: `spiffe://cluster\.local/ns/(?P<ns>[^/]+)/sa/([^/]+)`, User: "${ns}-$2", Roles: []string{"ns-${ns}"}},
+		{Field: CertFieldOU, Match: "ops", User: "operator", Roles: []string{"operator"}},
+		{Field: CertFieldO, Match: "(.+)", User: "org", Roles: []string{"$1", "org-$1"}},
+		{Field: CertFieldDNSSAN, Match: `(.+)\.svc\.example\.com`, User: "$1"},
+		{Field: CertFieldCN},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		cert  *x509.Certificate
+		user  string
+		roles []string
+		ok    bool
+	}{
+		{
+			name:  "SPIFFE URI SAN",
+			cert:  &x509.Certificate{Subject: pkix.Name{CommonName: "api"}, URIs: []*url.URL{spiffe}},
+			user:  "payments-api",
+			roles: []string{"ns-payments"},
+			ok:    true,
+		},
+		{
+			name:  "any OU",
+			cert:  &x509.Certificate{Subject: pkix.Name{OrganizationalUnit: []string{"dev", "ops"}}},
+			user:  "operator",
+			roles: []string{"operator"},
+			ok:    true,
+		},
+		{
+			name:  "roles expanded to root are dropped",
+			cert:  &x509.Certificate{Subject: pkix.Name{Organization: []string{"root"}}},
+			user:  "org",
+			roles: []string{"org-root"},
+			ok:    true,
+		},
+		{
+			name: "OU must match as a whole",
+			cert: &x509.Certificate{Subject: pkix.Name{OrganizationalUnit: []string{"devops"}, CommonName: "alice"}},
+			user: "alice",
+			ok:   true,
+		},
+		{
+			name: "DNS SAN",
+			cert: &x509.Certificate{DNSNames: []string{"localhost", "billing.svc.example.com"}},
+			user: "billing",
+			ok:   true,
+		},
+		{
+			name: "no match",
+			cert: &x509.Certificate{DNSNames: []string{"localhost"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, roles, ok := rules.identity(tt.cert)
+			if user != tt.user || !reflect.DeepEqual(roles, tt.roles) || ok != tt.ok {
+				t.Errorf("expected (%q, %v, %v), got (%q, %v, %v)", tt.user, tt.roles, tt.ok, user, roles, ok)
+			}
+		})
+	}
+
+	// nil rules map the certificates to the user of their common name
+	var nilRules *ClientCertRules
+	if user, roles, ok := nilRules.identity(tests[0].cert); user != "api" || roles != nil || !ok {
+		t.Errorf("expected (%q, nil, true), got (%q, %v, %v)", "api", user, roles, ok)
+	}
+}
+
+func TestReadClientCertRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	if err := ioutil.WriteFile(path, []byte(`rules:
+- field: uri-san
+  match: 'spiffe://example\.org/(.+)'
+  roles: ["$1"]
+- field: cn
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := ReadClientCertRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.rules) != 2 || rules.rules[0].User != "$0" || rules.rules[1].Field != CertFieldCN {
+		t.Errorf("unexpected rules %+v", rules.rules)
+	}
+
+	for _, content := range []string{
+		"rules:\n- field: serial\n",
+		"rules:\n- field: cn\n  match: '('\n",
+		"rules:\n- field: cn\n  unknown: true\n",
+		"rules:\n- field: cn\n  roles: [root]\n",
+	} {
+		if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ReadClientCertRules(path); err == nil {
+			t.Errorf("expected an error reading %q", content)
+		}
+	}
+}
+
+func TestAuthInfoFromTLSWithClientCertRules(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	rules, err := NewClientCertRules([]ClientCertRule{
+		{Field: CertFieldURISAN, Match: `spiffe://example\.org/(.+)`, Roles: []string{"role-test"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.SetClientCertRules(rules)
+
+	id, _ := url.Parse("spiffe://example.org/worker")
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "foo"}, URIs: []*url.URL{id}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.New(nil))
+
+	ai := as.AuthInfoFromTLS(ctx)
+	if ai == nil || ai.Username != "spiffe://example.org/worker" || !reflect.DeepEqual(ai.Roles, []string{"role-test"}) {
+		t.Fatalf("unexpected auth info %+v", ai)
+	}
+
+	// certificates no rule matches are not authenticated
+	cert.URIs = nil
+	if ai = as.AuthInfoFromTLS(ctx); ai != nil {
+		t.Fatalf("expected no auth info, got %+v", ai)
+	}
+
+	// without rules the common name is the user
+	as.SetClientCertRules(nil)
+	if ai = as.AuthInfoFromTLS(ctx); ai == nil || ai.Username != "foo" || ai.Roles != nil {
+		t.Fatalf("unexpected auth info %+v", ai)
+	}
+}
+
+func TestClientCertRolesPermission(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.READWRITE, Key: []byte("/app/"), RangeEnd: []byte("/app0")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// users granted roles by the rules do not need to exist
+	ai := &AuthInfo{Username: "spiffe://example.org/worker", Revision: as.Revision(), Roles: []string{"role-test"}}
+	if err = as.IsPutPermitted(ai, []byte("/app/a")); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err = as.IsPutPermitted(ai, []byte("/other")); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.IsAdminPermitted(ai); err != ErrUserNotFound {
+		t.Errorf("expected %v, got %v", ErrUserNotFound, err)
+	}
+
+	// the roles are merged with the roles of existing users, and cached apart
+	fooAi := &AuthInfo{Username: "foo", Revision: as.Revision()}
+	if err = as.IsPutPermitted(fooAi, []byte("/app/a")); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	fooAi.Roles = []string{"role-test"}
+	if err = as.IsPutPermitted(fooAi, []byte("/app/a")); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+
+	// the roles granted by the rules never grant the root permissions
+	rootAi := &AuthInfo{Username: "operator", Revision: as.Revision(), Roles: []string{"root"}}
+	if err = as.IsAdminPermitted(rootAi); err != ErrUserNotFound {
+		t.Errorf("expected %v, got %v", ErrUserNotFound, err)
+	}
+	if err = as.IsDeleteRangePermitted(rootAi, []byte("a"), []byte("z")); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+}
+
+func TestClientCertRolesPermCacheBounded(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	for i := 0; i < maxMappedRolesPermCacheEntries+10; i++ {
+		ai := &AuthInfo{Username: "foo", Revision: as.Revision(), Roles: []string{fmt.Sprintf("role-%d", i)}}
+		as.IsPutPermitted(ai, []byte("/app/a"))
+	}
+	if n := len(as.mappedRolesPermCache); n != maxMappedRolesPermCacheEntries {
+		t.Errorf("expected %d cached permissions, got %d", maxMappedRolesPermCacheEntries, n)
+	}
+	as.invalidateCachedPerm("foo")
+	if n := len(as.mappedRolesPermCache); n != 0 {
+		t.Errorf("expected the cached permissions of the user to be invalidated, got %d", n)
+	}
+}
@@ -15,99 +15,113 @@
 package auth
 
 import (
+	"path"
+	"strings"
+
 	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
 	"go.etcd.io/etcd/pkg/v3/adt"
 	"go.uber.org/zap"
 )
 
-func getMergedPerms(tx AuthBatchTx, userName string) *unifiedRangePermissions {
+// typeOperations are the operations covered by the permission types.
+var typeOperations = map[authpb.Permission_Type][]authpb.Permission_Operation{
+	authpb.READ:      {authpb.RANGE, authpb.WATCH, authpb.TXN},
+	authpb.WRITE:     {authpb.PUT, authpb.DELETE, authpb.LEASE},
+	authpb.READWRITE: {authpb.RANGE, authpb.WATCH, authpb.TXN, authpb.PUT, authpb.DELETE, authpb.LEASE},
+}
+
+// permissionOperations returns the operations the permission applies to.
+func permissionOperations(perm *authpb.Permission) []authpb.Permission_Operation {
+	if len(perm.Ops) > 0 {
+		return perm.Ops
+	}
+	return typeOperations[perm.PermType]
+}
+
+// getMergedPerms merges the permissions of the roles of the user with the
+// permissions of the given roles. The user does not need to exist if roles
+// are given.
+func getMergedPerms(tx AuthBatchTx, userName string, roles []string) *unifiedRangePermissions {
 	user := tx.UnsafeGetUser(userName)
-	if user == nil {
+	if user == nil && len(roles) == 0 {
 		return nil
 	}
+	if user != nil {
+		roles = append(roles[:len(roles):len(roles)], user.Roles...)
+	}
 
-	readPerms := adt.NewIntervalTree()
-	writePerms := adt.NewIntervalTree()
-
-	for _, roleName := range user.Roles {
+	perms := newUnifiedRangePermissions()
+	for _, roleName := range roles {
 		role := tx.UnsafeGetRole(roleName)
 		if role == nil {
 			continue
 		}
 
 		for _, perm := range role.KeyPermission {
-			var ivl adt.Interval
-			var rangeEnd []byte
-
-			if len(perm.RangeEnd) != 1 || perm.RangeEnd[0] != 0 {
-				rangeEnd = perm.RangeEnd
-			}
-
-			if len(perm.RangeEnd) != 0 {
-				ivl = adt.NewBytesAffineInterval(perm.Key, rangeEnd)
-			} else {
-				ivl = adt.NewBytesAffinePoint(perm.Key)
-			}
-
-			switch perm.PermType {
-			case authpb.READWRITE:
-				readPerms.Insert(ivl, struct{}{})
-				writePerms.Insert(ivl, struct{}{})
-
-			case authpb.READ:
-				readPerms.Insert(ivl, struct{}{})
-
-			case authpb.WRITE:
-				writePerms.Insert(ivl, struct{}{})
+			for _, op := range permissionOperations(perm) {
+				perms.ops[op].add(perm)
 			}
 		}
 	}
-
-	return &unifiedRangePermissions{
-		readPerms:  readPerms,
-		writePerms: writePerms,
-	}
+	return perms
 }
 
 func checkKeyInterval(
 	lg *zap.Logger,
 	cachedPerms *unifiedRangePermissions,
 	key, rangeEnd []byte,
-	permtyp authpb.Permission_Type) bool {
+	op authpb.Permission_Operation) bool {
 	if len(rangeEnd) == 1 && rangeEnd[0] == 0 {
 		rangeEnd = nil
 	}
 
-	ivl := adt.NewBytesAffineInterval(key, rangeEnd)
-	switch permtyp {
-	case authpb.READ:
-		return cachedPerms.readPerms.Contains(ivl)
-	case authpb.WRITE:
-		return cachedPerms.writePerms.Contains(ivl)
-	default:
-		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
+	perms, ok := cachedPerms.ops[op]
+	if !ok {
+		lg.Panic("unknown auth operation", zap.String("auth-operation", op.String()))
 	}
-	return false
+	ivl := adt.NewBytesAffineInterval(key, rangeEnd)
+	// patterns only grant the operations on single keys, but deny the
+	// operations on the ranges that may hold keys they match
+	return perms.granted.Contains(ivl) && !perms.denied.Intersects(ivl) && !perms.deniedPatternSpans.Intersects(ivl)
 }
 
-func checkKeyPoint(lg *zap.Logger, cachedPerms *unifiedRangePermissions, key []byte, permtyp authpb.Permission_Type) bool {
+func checkKeyPoint(lg *zap.Logger, cachedPerms *unifiedRangePermissions, key []byte, op authpb.Permission_Operation) bool {
+	perms, ok := cachedPerms.ops[op]
+	if !ok {
+		lg.Panic("unknown auth operation", zap.String("auth-operation", op.String()))
+	}
 	pt := adt.NewBytesAffinePoint(key)
-	switch permtyp {
-	case authpb.READ:
-		return cachedPerms.readPerms.Intersects(pt)
-	case authpb.WRITE:
-		return cachedPerms.writePerms.Intersects(pt)
-	default:
-		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
+	if !perms.granted.Intersects(pt) && !matchAny(perms.grantedPatterns, key) {
+		return false
 	}
-	return false
+	return !perms.denied.Intersects(pt) && !matchAny(perms.deniedPatterns, key)
 }
 
-func (as *authStore) isRangeOpPermitted(tx AuthBatchTx, userName string, key, rangeEnd []byte, permtyp authpb.Permission_Type) bool {
+// maxMappedRolesPermCacheEntries bounds the cached permissions of the users
+// granted roles by the client certificate rules, one per set of roles mapped
+// from the certificates.
+const maxMappedRolesPermCacheEntries = 1024
+
+// permCacheKey returns the key of the cached permissions of the user granted
+// the roles.
+func permCacheKey(userName string, roles []string) string {
+	if len(roles) == 0 {
+		return userName
+	}
+	return userName + "\x00" + strings.Join(roles, "\x00")
+}
+
+func (as *authStore) isRangeOpPermitted(tx AuthBatchTx, userName string, roles []string, key, rangeEnd []byte, op authpb.Permission_Operation) bool {
 	// assumption: tx is Lock()ed
-	_, ok := as.rangePermCache[userName]
+	cache := as.rangePermCache
+	if len(roles) > 0 {
+		cache = as.mappedRolesPermCache
+	}
+	cacheKey := permCacheKey(userName, roles)
+	perms, ok := cache[cacheKey]
 	if !ok {
-		perms := getMergedPerms(tx, userName)
+		perms = getMergedPerms(tx, userName, roles)
 		if perms == nil {
 			as.lg.Error(
 				"failed to create a merged permission",
@@ -115,25 +129,125 @@ func (as *authStore) isRangeOpPermitted(tx AuthBatchTx, userName string, key, ra
 			)
 			return false
 		}
-		as.rangePermCache[userName] = perms
+		if len(roles) > 0 && len(cache) >= maxMappedRolesPermCacheEntries {
+			// evict any entry
+			for k := range cache {
+				delete(cache, k)
+				break
+			}
+		}
+		cache[cacheKey] = perms
 	}
 
 	if len(rangeEnd) == 0 {
-		return checkKeyPoint(as.lg, as.rangePermCache[userName], key, permtyp)
+		return checkKeyPoint(as.lg, perms, key, op)
 	}
 
-	return checkKeyInterval(as.lg, as.rangePermCache[userName], key, rangeEnd, permtyp)
+	return checkKeyInterval(as.lg, perms, key, rangeEnd, op)
 }
 
 func (as *authStore) clearCachedPerm() {
 	as.rangePermCache = make(map[string]*unifiedRangePermissions)
+	as.mappedRolesPermCache = make(map[string]*unifiedRangePermissions)
 }
 
 func (as *authStore) invalidateCachedPerm(userName string) {
 	delete(as.rangePermCache, userName)
+	// permissions of the user granted roles by client certificate rules
+	for k := range as.mappedRolesPermCache {
+		if strings.HasPrefix(k, userName+"\x00") {
+			delete(as.mappedRolesPermCache, k)
+		}
+	}
 }
 
 type unifiedRangePermissions struct {
-	readPerms  adt.IntervalTree
-	writePerms adt.IntervalTree
+	ops map[authpb.Permission_Operation]*operationPermissions
+}
+
+func newUnifiedRangePermissions() *unifiedRangePermissions {
+	perms := &unifiedRangePermissions{ops: make(map[authpb.Permission_Operation]*operationPermissions)}
+	for op := range authpb.Permission_Operation_name {
+		perms.ops[authpb.Permission_Operation(op)] = &operationPermissions{
+			granted:            adt.NewIntervalTree(),
+			denied:             adt.NewIntervalTree(),
+			deniedPatternSpans: adt.NewIntervalTree(),
+		}
+	}
+	return perms
+}
+
+// operationPermissions are the keys an operation is granted and denied on.
+type operationPermissions struct {
+	granted         adt.IntervalTree
+	denied          adt.IntervalTree
+	grantedPatterns []string
+	deniedPatterns  []string
+	// deniedPatternSpans holds the ranges of the keys the denied patterns may match.
+	deniedPatternSpans adt.IntervalTree
+}
+
+func (p *operationPermissions) add(perm *authpb.Permission) {
+	if perm.Pattern {
+		if perm.Deny {
+			p.deniedPatterns = append(p.deniedPatterns, string(perm.Key))
+			p.deniedPatternSpans.Insert(patternSpan(string(perm.Key)), struct{}{})
+		} else {
+			p.grantedPatterns = append(p.grantedPatterns, string(perm.Key))
+		}
+		return
+	}
+
+	var ivl adt.Interval
+	var rangeEnd []byte
+
+	if len(perm.RangeEnd) != 1 || perm.RangeEnd[0] != 0 {
+		rangeEnd = perm.RangeEnd
+	}
+
+	if len(perm.RangeEnd) != 0 {
+		ivl = adt.NewBytesAffineInterval(perm.Key, rangeEnd)
+	} else {
+		ivl = adt.NewBytesAffinePoint(perm.Key)
+	}
+
+	if perm.Deny {
+		p.denied.Insert(ivl, struct{}{})
+	} else {
+		p.granted.Insert(ivl, struct{}{})
+	}
+}
+
+// validPattern reports whether the glob pattern is well formed.
+func validPattern(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+func matchAny(patterns []string, key []byte) bool {
+	for _, pattern := range patterns {
+		// the patterns are validated when granted
+		if ok, _ := path.Match(pattern, string(key)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// patternSpan returns the range of the keys starting with the literal
+// prefix of the pattern, which holds all the keys it matches.
+func patternSpan(pattern string) adt.Interval {
+	prefix := []byte(pattern)
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if len(prefix) == 0 {
+		// keys are not empty, and the empty end is open ended
+		return adt.NewBytesAffineInterval([]byte{0}, nil)
+	}
+	end := keyutil.PrefixRangeEnd(prefix)
+	if len(end) == 1 && end[0] == 0 {
+		end = nil
+	}
+	return adt.NewBytesAffineInterval(prefix, end)
 }
@@ -48,12 +48,12 @@ func TestRangePermission(t *testing.T) {
 	}
 
 	for i, tt := range tests {
-		readPerms := adt.NewIntervalTree()
+		perms := newUnifiedRangePermissions()
 		for _, p := range tt.perms {
-			readPerms.Insert(p, struct{}{})
+			perms.ops[authpb.RANGE].granted.Insert(p, struct{}{})
 		}
 
-		result := checkKeyInterval(zap.NewExample(), &unifiedRangePermissions{readPerms: readPerms}, tt.begin, tt.end, authpb.READ)
+		result := checkKeyInterval(zap.NewExample(), perms, tt.begin, tt.end, authpb.RANGE)
 		if result != tt.want {
 			t.Errorf("#%d: result=%t, want=%t", i, result, tt.want)
 		}
@@ -89,14 +89,67 @@ func TestKeyPermission(t *testing.T) {
 	}
 
 	for i, tt := range tests {
-		readPerms := adt.NewIntervalTree()
+		perms := newUnifiedRangePermissions()
 		for _, p := range tt.perms {
-			readPerms.Insert(p, struct{}{})
+			perms.ops[authpb.RANGE].granted.Insert(p, struct{}{})
 		}
 
-		result := checkKeyPoint(zap.NewExample(), &unifiedRangePermissions{readPerms: readPerms}, tt.key, authpb.READ)
+		result := checkKeyPoint(zap.NewExample(), perms, tt.key, authpb.RANGE)
 		if result != tt.want {
 			t.Errorf("#%d: result=%t, want=%t", i, result, tt.want)
 		}
 	}
 }
+
+func TestPatternDenyOperationPermission(t *testing.T) {
+	perms := newUnifiedRangePermissions()
+	for _, perm := range []*authpb.Permission{
+		{PermType: authpb.READWRITE, Key: []byte("/apps/"), RangeEnd: []byte("/apps0")},
+		{PermType: authpb.WRITE, Key: []byte("/apps/*/config"), Pattern: true, Deny: true},
+		{PermType: authpb.WRITE, Key: []byte("/jobs/*/status"), Pattern: true},
+		{PermType: authpb.WRITE, Key: []byte("/leases/"), RangeEnd: []byte("/leases0"), Ops: []authpb.Permission_Operation{authpb.PUT, authpb.LEASE}},
+		{PermType: authpb.READ, Key: []byte("/apps/secret"), Deny: true},
+	} {
+		for _, op := range permissionOperations(perm) {
+			perms.ops[op].add(perm)
+		}
+	}
+
+	tests := []struct {
+		key, end []byte
+		op       authpb.Permission_Operation
+		want     bool
+	}{
+		{[]byte("/apps/a/status"), nil, authpb.PUT, true},
+		{[]byte("/apps/a/config"), nil, authpb.PUT, false},
+		{[]byte("/apps/a/config"), nil, authpb.DELETE, false},
+		{[]byte("/apps/a/config"), nil, authpb.RANGE, true},
+		{[]byte("/apps/a/b/config"), nil, authpb.PUT, true},
+		{[]byte("/jobs/a/status"), nil, authpb.PUT, true},
+		{[]byte("/jobs/a/status"), nil, authpb.RANGE, false},
+		{[]byte("/jobs/a/config"), nil, authpb.PUT, false},
+		{[]byte("/leases/a"), nil, authpb.LEASE, true},
+		{[]byte("/leases/a"), nil, authpb.DELETE, false},
+		{[]byte("/apps/secret"), nil, authpb.RANGE, false},
+		{[]byte("/apps/secret"), nil, authpb.WATCH, false},
+		{[]byte("/apps/secret"), nil, authpb.PUT, true},
+		// ranges overlapping the denied keys are denied
+		{[]byte("/apps/"), []byte("/apps0"), authpb.RANGE, false},
+		{[]byte("/apps/"), []byte("/apps0"), authpb.DELETE, false},
+		{[]byte("/apps/a"), []byte("/apps/b"), authpb.RANGE, true},
+		// patterns do not grant ranges
+		{[]byte("/jobs/"), []byte("/jobs0"), authpb.PUT, false},
+	}
+
+	for i, tt := range tests {
+		var result bool
+		if len(tt.end) == 0 {
+			result = checkKeyPoint(zap.NewExample(), perms, tt.key, tt.op)
+		} else {
+			result = checkKeyInterval(zap.NewExample(), perms, tt.key, tt.end, tt.op)
+		}
+		if result != tt.want {
+			t.Errorf("#%d: %s %q-%q result=%t, want=%t", i, tt.op, tt.key, tt.end, result, tt.want)
+		}
+	}
+}
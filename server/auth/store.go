@@ -51,6 +51,7 @@ var (
 	ErrRoleNotFound         = errors.New("auth: role not found")
 	ErrRoleEmpty            = errors.New("auth: role name is empty")
 	ErrPermissionNotGiven   = errors.New("auth: permission not given")
+	ErrInvalidPermission    = errors.New("auth: invalid permission")
 	ErrAuthFailed           = errors.New("auth: authentication failed, invalid user ID or password")
 	ErrNoPasswordUser       = errors.New("auth: authentication failed, password was given for no password user")
 	ErrPermissionDenied     = errors.New("auth: permission denied")
@@ -78,6 +79,9 @@ const (
 type AuthInfo struct {
 	Username string
 	Revision uint64
+	// Roles are granted to the user in addition to its own roles, by the
+	// client certificate rules
+	Roles []string
 }
 
 // AuthenticateParamIndex is used for a key of context in the parameters of Authenticate()
@@ -151,6 +155,16 @@ type AuthStore interface {
 	// IsDeleteRangePermitted checks delete-range permission of the user
 	IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
 
+	// IsLeasePermitted checks the permission of the user to attach leases to the key
+	// and change the leases attached to it
+	IsLeasePermitted(authInfo *AuthInfo, key []byte) error
+
+	// IsWatchPermitted checks watch permission of the user
+	IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
+	// IsTxnComparePermitted checks the permission of the user to compare the keys in a txn
+	IsTxnComparePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
 	// IsAdminPermitted checks admin permission of the user
 	IsAdminPermitted(authInfo *AuthInfo) error
 
@@ -181,6 +195,17 @@ type AuthStore interface {
 
 	// BcryptCost gets strength of hashing bcrypted auth password
 	BcryptCost() int
+
+	// UserPrefix gets the prefix of the tenant the user is bound to, nil if the
+	// user is not bound to a tenant
+	UserPrefix(user string) []byte
+
+	// Tenants gets the users bound to each tenant, by prefix
+	Tenants() map[string][]string
+
+	// SetClientCertRules sets the rules mapping the client certificates to
+	// users and roles, nil to map them to the user of their common name
+	SetClientCertRules(rules *ClientCertRules)
 }
 
 type TokenProvider interface {
@@ -235,9 +260,15 @@ type authStore struct {
 	enabledMu sync.RWMutex
 
 	rangePermCache map[string]*unifiedRangePermissions // username -> unifiedRangePermissions
+	// mappedRolesPermCache caches the permissions of the users granted roles by
+	// the client certificate rules, at most maxMappedRolesPermCacheEntries.
+	mappedRolesPermCache map[string]*unifiedRangePermissions
 
 	tokenProvider TokenProvider
 	bcryptCost    int // the algorithm cost / strength for hashing auth passwords
+
+	certRulesMu sync.RWMutex
+	certRules   *ClientCertRules
 }
 
 func (as *authStore) AuthEnable() error {
@@ -267,7 +298,7 @@ func (as *authStore) AuthEnable() error {
 	as.enabled = true
 	as.tokenProvider.enable()
 
-	as.rangePermCache = make(map[string]*unifiedRangePermissions)
+	as.clearCachedPerm()
 
 	as.setRevision(tx.UnsafeReadAuthRevision())
 
@@ -418,6 +449,28 @@ func (as *authStore) UserAdd(r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse,
 		}
 	}
 
+	if r.Name == rootUser && len(options.Prefix) > 0 {
+		as.lg.Error("cannot bind 'root' user to a tenant", zap.String("user-name", r.Name))
+		return nil, ErrInvalidAuthMgmt
+	}
+	if len(options.Prefix) > 0 {
+		// a tenant nested in another one would see the keys of the other tenant
+		for _, u := range tx.UnsafeGetAllUsers() {
+			if u.Options == nil || len(u.Options.Prefix) == 0 || bytes.Equal(u.Options.Prefix, options.Prefix) {
+				continue
+			}
+			if bytes.HasPrefix(u.Options.Prefix, options.Prefix) || bytes.HasPrefix(options.Prefix, u.Options.Prefix) {
+				as.lg.Error(
+					"cannot bind user to a tenant nested with another tenant",
+					zap.String("user-name", r.Name),
+					zap.ByteString("prefix", options.Prefix),
+					zap.ByteString("other-prefix", u.Options.Prefix),
+				)
+				return nil, ErrInvalidAuthMgmt
+			}
+		}
+	}
+
 	var password []byte
 	var err error
 
@@ -566,6 +619,9 @@ func (as *authStore) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetResponse,
 
 	var resp pb.AuthUserGetResponse
 	resp.Roles = append(resp.Roles, user.Roles...)
+	if user.Options != nil {
+		resp.Prefix = user.Options.Prefix
+	}
 	return &resp, nil
 }
 
@@ -670,7 +726,7 @@ func (as *authStore) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRequest)
 	}
 
 	for _, perm := range role.KeyPermission {
-		if !bytes.Equal(perm.Key, r.Key) || !bytes.Equal(perm.RangeEnd, r.RangeEnd) {
+		if !bytes.Equal(perm.Key, r.Key) || !bytes.Equal(perm.RangeEnd, r.RangeEnd) || perm.Pattern != r.Pattern || perm.Deny != r.Deny {
 			updatedRole.KeyPermission = append(updatedRole.KeyPermission, perm)
 		}
 	}
@@ -782,6 +838,35 @@ func (perms permSlice) Less(i, j int) bool {
 	return bytes.Compare(perms[i].Key, perms[j].Key) < 0
 }
 
+// samePermissionKeys reports whether the permissions apply to the same keys,
+// the ones a permission granted to a role replaces.
+func samePermissionKeys(a, b *authpb.Permission) bool {
+	return bytes.Equal(a.Key, b.Key) && bytes.Equal(a.RangeEnd, b.RangeEnd) && a.Pattern == b.Pattern && a.Deny == b.Deny
+}
+
+// validatePermission checks that a pattern permission has no range end and
+// a valid pattern, and that the operations of the permission are covered by
+// its type.
+func validatePermission(perm *authpb.Permission) error {
+	if perm.Pattern && (len(perm.RangeEnd) != 0 || !validPattern(string(perm.Key))) {
+		return ErrInvalidPermission
+	}
+	ops, ok := typeOperations[perm.PermType]
+	if !ok {
+		return ErrInvalidPermission
+	}
+	for _, op := range perm.Ops {
+		covered := false
+		for _, top := range ops {
+			covered = covered || op == top
+		}
+		if !covered {
+			return ErrInvalidPermission
+		}
+	}
+	return nil
+}
+
 func (perms permSlice) Swap(i, j int) {
 	perms[i], perms[j] = perms[j], perms[i]
 }
@@ -790,6 +875,9 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 	if r.Perm == nil {
 		return nil, ErrPermissionNotGiven
 	}
+	if err := validatePermission(r.Perm); err != nil {
+		return nil, err
+	}
 
 	tx := as.be.BatchTx()
 	tx.Lock()
@@ -803,20 +891,28 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 	idx := sort.Search(len(role.KeyPermission), func(i int) bool {
 		return bytes.Compare(role.KeyPermission[i].Key, r.Perm.Key) >= 0
 	})
+	// permissions of the same key may differ by their range end, pattern or deny
+	for idx < len(role.KeyPermission) && bytes.Equal(role.KeyPermission[idx].Key, r.Perm.Key) && !samePermissionKeys(role.KeyPermission[idx], r.Perm) {
+		idx++
+	}
 
-	if idx < len(role.KeyPermission) && bytes.Equal(role.KeyPermission[idx].Key, r.Perm.Key) && bytes.Equal(role.KeyPermission[idx].RangeEnd, r.Perm.RangeEnd) {
+	if idx < len(role.KeyPermission) && samePermissionKeys(role.KeyPermission[idx], r.Perm) {
 		// update existing permission
 		role.KeyPermission[idx].PermType = r.Perm.PermType
+		role.KeyPermission[idx].Ops = r.Perm.Ops
 	} else {
 		// append new permission to the role
 		newPerm := &authpb.Permission{
 			Key:      r.Perm.Key,
 			RangeEnd: r.Perm.RangeEnd,
 			PermType: r.Perm.PermType,
+			Pattern:  r.Perm.Pattern,
+			Deny:     r.Perm.Deny,
+			Ops:      r.Perm.Ops,
 		}
 
 		role.KeyPermission = append(role.KeyPermission, newPerm)
-		sort.Sort(permSlice(role.KeyPermission))
+		sort.Stable(permSlice(role.KeyPermission))
 	}
 
 	tx.UnsafePutRole(role)
@@ -835,7 +931,7 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 	return &pb.AuthRoleGrantPermissionResponse{}, nil
 }
 
-func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeEnd []byte, permTyp authpb.Permission_Type) error {
+func (as *authStore) isOpPermitted(userName string, roles []string, revision uint64, key, rangeEnd []byte, op authpb.Permission_Operation) error {
 	// TODO(mitake): this function would be costly so we need a caching mechanism
 	if !as.IsAuthEnabled() {
 		return nil
@@ -859,18 +955,19 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 	tx.Lock()
 	defer tx.Unlock()
 
+	// users mapped from client certificates with roles do not need to exist
 	user := tx.UnsafeGetUser(userName)
-	if user == nil {
+	if user == nil && len(roles) == 0 {
 		as.lg.Error("cannot find a user for permission check", zap.String("user-name", userName))
 		return ErrPermissionDenied
 	}
 
 	// root role should have permission on all ranges
-	if hasRootRole(user) {
+	if user != nil && hasRootRole(user) {
 		return nil
 	}
 
-	if as.isRangeOpPermitted(tx, userName, key, rangeEnd, permTyp) {
+	if as.isRangeOpPermitted(tx, userName, roles, key, rangeEnd, op) {
 		return nil
 	}
 
@@ -878,15 +975,27 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 }
 
 func (as *authStore) IsPutPermitted(authInfo *AuthInfo, key []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, nil, authpb.WRITE)
+	return as.isOpPermitted(authInfo.Username, authInfo.Roles, authInfo.Revision, key, nil, authpb.PUT)
 }
 
 func (as *authStore) IsRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.READ)
+	return as.isOpPermitted(authInfo.Username, authInfo.Roles, authInfo.Revision, key, rangeEnd, authpb.RANGE)
 }
 
 func (as *authStore) IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.WRITE)
+	return as.isOpPermitted(authInfo.Username, authInfo.Roles, authInfo.Revision, key, rangeEnd, authpb.DELETE)
+}
+
+func (as *authStore) IsLeasePermitted(authInfo *AuthInfo, key []byte) error {
+	return as.isOpPermitted(authInfo.Username, authInfo.Roles, authInfo.Revision, key, nil, authpb.LEASE)
+}
+
+func (as *authStore) IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo.Username, authInfo.Roles, authInfo.Revision, key, rangeEnd, authpb.WATCH)
+}
+
+func (as *authStore) IsTxnComparePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo.Username, authInfo.Roles, authInfo.Revision, key, rangeEnd, authpb.TXN)
 }
 
 func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
@@ -938,13 +1047,14 @@ func NewAuthStore(lg *zap.Logger, be AuthBackend, tp TokenProvider, bcryptCost i
 	tx.Lock()
 	enabled := tx.UnsafeReadAuthEnabled()
 	as := &authStore{
-		revision:       tx.UnsafeReadAuthRevision(),
-		lg:             lg,
-		be:             be,
-		enabled:        enabled,
-		rangePermCache: make(map[string]*unifiedRangePermissions),
-		tokenProvider:  tp,
-		bcryptCost:     bcryptCost,
+		revision:             tx.UnsafeReadAuthRevision(),
+		lg:                   lg,
+		be:                   be,
+		enabled:              enabled,
+		rangePermCache:       make(map[string]*unifiedRangePermissions),
+		mappedRolesPermCache: make(map[string]*unifiedRangePermissions),
+		tokenProvider:        tp,
+		bcryptCost:           bcryptCost,
 	}
 
 	if enabled {
@@ -988,14 +1098,27 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 		return nil
 	}
 
+	as.certRulesMu.RLock()
+	rules := as.certRules
+	as.certRulesMu.RUnlock()
+
 	tlsInfo := peer.AuthInfo.(credentials.TLSInfo)
 	for _, chains := range tlsInfo.State.VerifiedChains {
 		if len(chains) < 1 {
 			continue
 		}
+		user, roles, ok := rules.identity(chains[0])
+		if !ok {
+			as.lg.Debug(
+				"no client certificate rule matches",
+				zap.String("common-name", chains[0].Subject.CommonName),
+			)
+			continue
+		}
 		ai = &AuthInfo{
-			Username: chains[0].Subject.CommonName,
+			Username: user,
 			Revision: as.Revision(),
+			Roles:    roles,
 		}
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
@@ -1008,7 +1131,7 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 		if gw := md["grpcgateway-accept"]; len(gw) > 0 {
 			as.lg.Warn(
 				"ignoring common name in gRPC-gateway proxy request",
-				zap.String("common-name", ai.Username),
+				zap.String("common-name", chains[0].Subject.CommonName),
 				zap.String("user-name", ai.Username),
 				zap.Uint64("revision", ai.Revision),
 			)
@@ -1016,8 +1139,9 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 		}
 		as.lg.Debug(
 			"found command name",
-			zap.String("common-name", ai.Username),
+			zap.String("common-name", chains[0].Subject.CommonName),
 			zap.String("user-name", ai.Username),
+			zap.Strings("roles", ai.Roles),
 			zap.Uint64("revision", ai.Revision),
 		)
 		break
@@ -1194,6 +1318,32 @@ func (as *authStore) BcryptCost() int {
 	return as.bcryptCost
 }
 
+func (as *authStore) UserPrefix(user string) []byte {
+	u := as.be.GetUser(user)
+	if u == nil || u.Options == nil {
+		return nil
+	}
+	return u.Options.Prefix
+}
+
+func (as *authStore) Tenants() map[string][]string {
+	tenants := make(map[string][]string)
+	for _, u := range as.be.GetAllUsers() {
+		if u.Options == nil || len(u.Options.Prefix) == 0 {
+			continue
+		}
+		prefix := string(u.Options.Prefix)
+		tenants[prefix] = append(tenants[prefix], string(u.Name))
+	}
+	return tenants
+}
+
+func (as *authStore) SetClientCertRules(rules *ClientCertRules) {
+	as.certRulesMu.Lock()
+	defer as.certRulesMu.Unlock()
+	as.certRules = rules
+}
+
 func (as *authStore) setupMetricsReporter() {
 	reportCurrentAuthRevMu.Lock()
 	reportCurrentAuthRev = func() float64 {
@@ -156,6 +156,28 @@ func TestUserAdd(t *testing.T) {
 	}
 }
 
+func TestUserAddNestedTenant(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if _, err := as.UserAdd(&pb.AuthUserAddRequest{Name: "a", Password: "p", Options: &authpb.UserAddOptions{Prefix: []byte("/a")}}); err != nil {
+		t.Fatal(err)
+	}
+	// users may share a tenant
+	if _, err := as.UserAdd(&pb.AuthUserAddRequest{Name: "a2", Password: "p", Options: &authpb.UserAddOptions{Prefix: []byte("/a")}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := as.UserAdd(&pb.AuthUserAddRequest{Name: "b", Password: "p", Options: &authpb.UserAddOptions{Prefix: []byte("/b/")}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, prefix := range []string{"/ab", "/", "/b/c"} {
+		ua := &pb.AuthUserAddRequest{Name: "n" + prefix, Password: "p", Options: &authpb.UserAddOptions{Prefix: []byte(prefix)}}
+		if _, err := as.UserAdd(ua); err != ErrInvalidAuthMgmt {
+			t.Errorf("prefix %q: expected %v, got %v", prefix, ErrInvalidAuthMgmt, err)
+		}
+	}
+}
+
 func TestRecover(t *testing.T) {
 	as, tearDown := setupAuthStore(t)
 	defer as.Close()
@@ -348,7 +370,7 @@ func TestIsOpPermitted(t *testing.T) {
 
 	// check permission reflected to user
 
-	err = as.isOpPermitted("foo", as.Revision(), perm.Key, perm.RangeEnd, perm.PermType)
+	err = as.isOpPermitted("foo", nil, as.Revision(), perm.Key, perm.RangeEnd, authpb.PUT)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -450,6 +472,63 @@ func TestRoleGrantPermission(t *testing.T) {
 	assert.Equal(t, perm, r.Perm[0])
 }
 
+func TestRoleGrantPermissionPatternDeny(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: "role-test-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []*authpb.Permission{
+		{PermType: authpb.WRITE, Key: []byte("/apps/*"), RangeEnd: []byte("/apps0"), Pattern: true},
+		{PermType: authpb.WRITE, Key: []byte("/apps/["), Pattern: true},
+		{PermType: authpb.READ, Key: []byte("/apps/"), Ops: []authpb.Permission_Operation{authpb.PUT}},
+	}
+	for i, perm := range invalid {
+		_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test-1", Perm: perm})
+		if err != ErrInvalidPermission {
+			t.Errorf("#%d: expected %v, got %v", i, ErrInvalidPermission, err)
+		}
+	}
+
+	// the same key is granted as a key, a pattern and a deny rule
+	perms := []*authpb.Permission{
+		{PermType: authpb.READWRITE, Key: []byte("/apps/*")},
+		{PermType: authpb.WRITE, Key: []byte("/apps/*"), Pattern: true, Ops: []authpb.Permission_Operation{authpb.PUT}},
+		{PermType: authpb.WRITE, Key: []byte("/apps/*"), Pattern: true, Deny: true},
+	}
+	for _, perm := range perms {
+		_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test-1", Perm: perm})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, perms, r.Perm)
+
+	_, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{
+		Role:    "role-test-1",
+		Key:     []byte("/apps/*"),
+		Pattern: true,
+		Deny:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, perms[:2], r.Perm)
+}
+
 func TestRootRoleGrantPermission(t *testing.T) {
 	as, tearDown := setupAuthStore(t)
 	defer tearDown(t)
@@ -17,6 +17,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"net"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -69,6 +70,9 @@ type ServerConfig struct {
 	InitialClusterToken string
 	NewCluster          bool
 	PeerTLSInfo         transport.TLSInfo
+	// PeerDialer, if set, connects to other members instead of the
+	// operating system network stack.
+	PeerDialer func(ctx context.Context, network, addr string) (net.Conn, error)
 
 	CORS map[string]struct{}
 
@@ -117,10 +121,16 @@ type ServerConfig struct {
 
 	AutoCompactionRetention time.Duration
 	AutoCompactionMode      string
-	CompactionBatchLimit    int
-	CompactionSleepInterval time.Duration
-	QuotaBackendBytes       int64
-	MaxTxnOps               uint
+	// ExperimentalAutoCompactionMaxRevisions is the number of revisions kept since the last
+	// compaction above which the 'adaptive' auto compaction compacts.
+	ExperimentalAutoCompactionMaxRevisions int64
+	// ExperimentalAutoCompactionMaxDBSizeInUseBytes is the backend size in use above which
+	// the 'adaptive' auto compaction compacts.
+	ExperimentalAutoCompactionMaxDBSizeInUseBytes int64
+	CompactionBatchLimit                          int
+	CompactionSleepInterval                       time.Duration
+	QuotaBackendBytes                             int64
+	MaxTxnOps                                     uint
 
 	// MaxRequestBytes is the maximum request size to send over raft.
 	MaxRequestBytes uint
@@ -191,9 +201,34 @@ type ServerConfig struct {
 	// consider running defrag during bootstrap. Needs to be set to non-zero value to take effect.
 	ExperimentalBootstrapDefragThresholdMegabytes uint `json:"experimental-bootstrap-defrag-threshold-megabytes"`
 
+	// ExperimentalOnlineDefrag makes defragmentation copy the backend while serving reads and writes.
+	ExperimentalOnlineDefrag bool `json:"experimental-online-defrag"`
+	// ExperimentalAutoDefragInUseRatio defragments the backend when the ratio of its size in use to its
+	// size falls below the value. Needs to be set to non-zero value to take effect.
+	ExperimentalAutoDefragInUseRatio float64 `json:"experimental-auto-defrag-in-use-ratio"`
+	// ExperimentalAutoDefragMinFreeMegabytes is the minimum number of megabytes needed to be freed for the
+	// automatic defragmentation to run.
+	ExperimentalAutoDefragMinFreeMegabytes uint `json:"experimental-auto-defrag-min-free-megabytes"`
+	// ExperimentalAutoDefragCheckInterval is the time duration between two checks of the automatic defragmentation.
+	ExperimentalAutoDefragCheckInterval time.Duration `json:"experimental-auto-defrag-check-interval"`
+
+	// ExperimentalLearnerServeReads enables learners to serve ranges and watches.
+	ExperimentalLearnerServeReads bool `json:"experimental-learner-serve-reads"`
+
 	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
 
+	// ExperimentalSecondaryIndexes declares secondary indexes on JSON fields of values,
+	// each in the form "<prefix>=<field>".
+	ExperimentalSecondaryIndexes []string `json:"experimental-secondary-indexes"`
+
+	// ExperimentalBackendEngine is the name of the storage engine of the backend.
+	ExperimentalBackendEngine string `json:"experimental-backend-engine"`
+
+	// ExperimentalClientCertAuthRulesFile is the path of the file holding the rules that map
+	// the client certificates to users and roles.
+	ExperimentalClientCertAuthRulesFile string `json:"experimental-client-cert-auth-rules-file"`
+
 	// V2Deprecation defines a phase of v2store deprecation process.
 	V2Deprecation V2DeprecationEnum `json:"v2-deprecation"`
 }
@@ -15,6 +15,7 @@
 package embed
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -32,10 +33,13 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/pkg/v3/flags"
 	"go.etcd.io/etcd/pkg/v3/netutil"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	bolt "go.etcd.io/bbolt"
 	"go.uber.org/multierr"
@@ -61,6 +65,7 @@ const (
 	DefaultGRPCKeepAliveTimeout        = 20 * time.Second
 	DefaultDowngradeCheckTime          = 5 * time.Second
 	DefaultWaitClusterReadyTimeout     = 5 * time.Second
+	DefaultAutoDefragCheckInterval     = 5 * time.Minute
 
 	DefaultListenPeerURLs   = "http://localhost:2380"
 	DefaultListenClientURLs = "http://localhost:2379"
@@ -126,12 +131,26 @@ var (
 	// revision 5000 when the current revision is 6000.
 	// This runs every 5-minute if enough of logs have proceeded.
 	CompactorModeRevision = v3compactor.ModeRevision
+
+	// CompactorModeAdaptive is threshold-based compaction mode
+	// for "Config.AutoCompactionMode" field.
+	// If "AutoCompactionMode" is CompactorModeAdaptive and
+	// "AutoCompactionRetention" is "1h", it compacts log when
+	// the revisions kept or the backend size in use cross the
+	// thresholds, but never purges the log of the last hour.
+	CompactorModeAdaptive = v3compactor.ModeAdaptive
 )
 
 func init() {
 	defaultHostname, defaultHostStatus = netutil.GetDefaultHost()
 }
 
+// Network is a network stack to listen and dial on.
+type Network interface {
+	Listen(network, addr string) (net.Listener, error)
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
 // Config holds the arguments for configuring an etcd server.
 type Config struct {
 	Name   string `json:"name"`
@@ -223,13 +242,20 @@ type Config struct {
 	StrictReconfigCheck                 bool          `json:"strict-reconfig-check"`
 	ExperimentalWaitClusterReadyTimeout time.Duration `json:"wait-cluster-ready-timeout"`
 
-	// AutoCompactionMode is either 'periodic' or 'revision'.
+	// AutoCompactionMode is either 'periodic', 'revision' or 'adaptive'.
 	AutoCompactionMode string `json:"auto-compaction-mode"`
 	// AutoCompactionRetention is either duration string with time unit
 	// (e.g. '5m' for 5-minute), or revision unit (e.g. '5000').
-	// If no time unit is provided and compaction mode is 'periodic',
-	// the unit defaults to hour. For example, '5' translates into 5-hour.
+	// If no time unit is provided and compaction mode is 'periodic' or
+	// 'adaptive', the unit defaults to hour. For example, '5' translates
+	// into 5-hour.
 	AutoCompactionRetention string `json:"auto-compaction-retention"`
+	// ExperimentalAutoCompactionMaxRevisions is the number of revisions kept since the last
+	// compaction above which the 'adaptive' auto compaction compacts. 0 disables the threshold.
+	ExperimentalAutoCompactionMaxRevisions int64 `json:"experimental-auto-compaction-max-revisions"`
+	// ExperimentalAutoCompactionMaxDBSizeInUseBytes is the backend size in use above which
+	// the 'adaptive' auto compaction compacts. 0 disables the threshold.
+	ExperimentalAutoCompactionMaxDBSizeInUseBytes int64 `json:"experimental-auto-compaction-max-db-size-in-use-bytes"`
 
 	// GRPCKeepAliveMinTime is the minimum interval that a client should
 	// wait before pinging server. When client pings "too fast", server
@@ -296,6 +322,11 @@ type Config struct {
 	//	}
 	//	embed.StartEtcd(cfg)
 	ServiceRegister func(*grpc.Server) `json:"-"`
+	// ExperimentalNetwork, if set, replaces the operating system network stack:
+	// the server listens on its peer, client and metrics URLs and connects to
+	// other members through it. Embedding applications set it to run members
+	// over a simulated network, e.g. the one of "go.etcd.io/etcd/pkg/v3/netsim".
+	ExperimentalNetwork Network `json:"-"`
 
 	AuthToken  string `json:"auth-token"`
 	BcryptCost uint   `json:"bcrypt-cost"`
@@ -322,11 +353,38 @@ type Config struct {
 	// ExperimentalBootstrapDefragThresholdMegabytes is the minimum number of megabytes needed to be freed for etcd server to
 	// consider running defrag during bootstrap. Needs to be set to non-zero value to take effect.
 	ExperimentalBootstrapDefragThresholdMegabytes uint `json:"experimental-bootstrap-defrag-threshold-megabytes"`
+	// ExperimentalOnlineDefrag makes defragmentation copy the backend while serving reads and writes,
+	// only blocking them to catch up with the last writes and swap the database files.
+	ExperimentalOnlineDefrag bool `json:"experimental-online-defrag"`
+	// ExperimentalAutoDefragInUseRatio defragments the backend when the ratio of its size in use to its
+	// size falls below the value. Needs to be set to non-zero value to take effect, and requires
+	// ExperimentalOnlineDefrag.
+	ExperimentalAutoDefragInUseRatio float64 `json:"experimental-auto-defrag-in-use-ratio"`
+	// ExperimentalAutoDefragMinFreeMegabytes is the minimum number of megabytes needed to be freed for the
+	// automatic defragmentation to run.
+	ExperimentalAutoDefragMinFreeMegabytes uint `json:"experimental-auto-defrag-min-free-megabytes"`
+	// ExperimentalAutoDefragCheckInterval is the time duration between two checks of the automatic defragmentation.
+	ExperimentalAutoDefragCheckInterval time.Duration `json:"experimental-auto-defrag-check-interval"`
 	// ExperimentalWarningUnaryRequestDuration is the time duration after which a warning is generated if applying
 	// unary request takes more time than this value.
 	ExperimentalWarningUnaryRequestDuration time.Duration `json:"experimental-warning-unary-request-duration"`
+	// ExperimentalLearnerServeReads enables learners to serve serializable and linearizable ranges, the
+	// latter through a read index from the leader, and watches. Learners reject all other requests.
+	ExperimentalLearnerServeReads bool `json:"experimental-learner-serve-reads"`
 	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
+	// ExperimentalSecondaryIndexes declares secondary indexes on a JSON field of the values under a key prefix,
+	// each in the form "<prefix>=<field>", where field is a dot separated path such as "status.state".
+	// Range requests can select the keys under the prefix by the value of the field.
+	ExperimentalSecondaryIndexes []string `json:"experimental-secondary-indexes"`
+	// ExperimentalBackendEngine is the name of the storage engine of the backend,
+	// "bbolt" if empty. All members of a cluster must use the same engine, as
+	// database snapshots are sent between members in the format of the engine.
+	ExperimentalBackendEngine string `json:"experimental-backend-engine"`
+	// ExperimentalClientCertAuthRulesFile is the path of a YAML or JSON file holding the rules that map the
+	// client certificates to users and roles when client certificate authentication is enabled. The client
+	// certificates are mapped to the user of their common name if empty.
+	ExperimentalClientCertAuthRulesFile string `json:"experimental-client-cert-auth-rules-file"`
 
 	// ForceNewCluster starts a new cluster even if previously started; unsafe.
 	ForceNewCluster bool `json:"force-new-cluster"`
@@ -379,6 +437,12 @@ type Config struct {
 	// Do not set logger directly.
 	loggerMu *sync.RWMutex
 	logger   *zap.Logger
+	// logLevel is the level of the logger built from the configuration, nil
+	// if the logger was built by ZapLoggerBuilder.
+	logLevel *zap.AtomicLevel
+
+	// configFile is the file the configuration was read from, if any.
+	configFile string
 	// EnableGRPCGateway enables grpc gateway.
 	// The gateway translates a RESTful HTTP API into gRPC.
 	EnableGRPCGateway bool `json:"enable-grpc-gateway"`
@@ -499,6 +563,7 @@ func NewConfig() *Config {
 		EnableGRPCGateway:     true,
 
 		ExperimentalDowngradeCheckTime:           DefaultDowngradeCheckTime,
+		ExperimentalAutoDefragCheckInterval:      DefaultAutoDefragCheckInterval,
 		ExperimentalMemoryMlock:                  false,
 		ExperimentalTxnModeWriteWithSharedBuffer: true,
 		ExperimentalMaxLearners:                  membership.DefaultMaxLearners,
@@ -514,6 +579,7 @@ func ConfigFromFile(path string) (*Config, error) {
 	if err := cfg.configFromFile(path); err != nil {
 		return nil, err
 	}
+	cfg.Config.configFile = path
 	return &cfg.Config, nil
 }
 
@@ -533,8 +599,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.LPUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.LPUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up listen-peer-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up listen-peer-urls: %v", err)
 		}
 		cfg.LPUrls = []url.URL(u)
 	}
@@ -542,8 +607,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.LCUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.LCUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up listen-client-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up listen-client-urls: %v", err)
 		}
 		cfg.LCUrls = []url.URL(u)
 	}
@@ -551,8 +615,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.APUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.APUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up initial-advertise-peer-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up initial-advertise-peer-urls: %v", err)
 		}
 		cfg.APUrls = []url.URL(u)
 	}
@@ -560,8 +623,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.ACUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.ACUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up advertise-peer-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up advertise-peer-urls: %v", err)
 		}
 		cfg.ACUrls = []url.URL(u)
 	}
@@ -569,14 +631,16 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.ListenMetricsUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.ListenMetricsUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up listen-metrics-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up listen-metrics-urls: %v", err)
 		}
 		cfg.ListenMetricsUrls = []url.URL(u)
 	}
 
 	if cfg.CORSJSON != "" {
-		uv := flags.NewUniqueURLsWithExceptions(cfg.CORSJSON, "*")
+		uv := flags.NewUniqueURLsWithExceptions("", "*")
+		if err := uv.Set(cfg.CORSJSON); err != nil {
+			return fmt.Errorf("unexpected error setting up cors: %v", err)
+		}
 		cfg.CORS = uv.Values
 	}
 
@@ -688,6 +752,10 @@ func (cfg *Config) Validate() error {
 	switch cfg.AutoCompactionMode {
 	case "":
 	case CompactorModeRevision, CompactorModePeriodic:
+	case CompactorModeAdaptive:
+		if cfg.ExperimentalAutoCompactionMaxRevisions <= 0 && cfg.ExperimentalAutoCompactionMaxDBSizeInUseBytes <= 0 {
+			return fmt.Errorf("auto-compaction-mode %q needs --experimental-auto-compaction-max-revisions or --experimental-auto-compaction-max-db-size-in-use-bytes", cfg.AutoCompactionMode)
+		}
 	default:
 		return fmt.Errorf("unknown auto-compaction-mode %q", cfg.AutoCompactionMode)
 	}
@@ -707,6 +775,35 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("setting experimental-enable-lease-checkpoint-persist requires experimental-enable-lease-checkpoint")
 	}
 
+	for _, s := range cfg.ExperimentalSecondaryIndexes {
+		if _, err := mvcc.ParseSecondaryIndex(s); err != nil {
+			return fmt.Errorf("--experimental-secondary-indexes is not valid: (%v)", err)
+		}
+	}
+
+	if cfg.ExperimentalAutoDefragInUseRatio < 0 || cfg.ExperimentalAutoDefragInUseRatio >= 1 {
+		return fmt.Errorf("--experimental-auto-defrag-in-use-ratio must be in [0, 1), got %v", cfg.ExperimentalAutoDefragInUseRatio)
+	}
+	if cfg.ExperimentalAutoDefragInUseRatio > 0 && !cfg.ExperimentalOnlineDefrag {
+		return fmt.Errorf("--experimental-auto-defrag-in-use-ratio requires --experimental-online-defrag")
+	}
+	if cfg.ExperimentalAutoDefragInUseRatio > 0 && cfg.ExperimentalAutoDefragCheckInterval <= 0 {
+		return fmt.Errorf("--experimental-auto-defrag-check-interval must be positive, got %v", cfg.ExperimentalAutoDefragCheckInterval)
+	}
+
+	if cfg.ExperimentalClientCertAuthRulesFile != "" {
+		if _, err := auth.ReadClientCertRules(cfg.ExperimentalClientCertAuthRulesFile); err != nil {
+			return fmt.Errorf("--experimental-client-cert-auth-rules-file is not valid: (%v)", err)
+		}
+		if !cfg.ClientTLSInfo.ClientCertAuth {
+			cfg.logger.Warn("client certificate rules are only used with client certificate authentication enabled")
+		}
+	}
+
+	if cfg.ExperimentalBackendEngine != "" && !isRegisteredEngine(cfg.ExperimentalBackendEngine) {
+		return fmt.Errorf("--experimental-backend-engine %q is not one of %v", cfg.ExperimentalBackendEngine, backend.Engines())
+	}
+
 	return nil
 }
 
@@ -997,3 +1094,12 @@ func parseBackendFreelistType(freelistType string) bolt.FreelistType {
 
 	return bolt.FreelistMapType
 }
+
+func isRegisteredEngine(name string) bool {
+	for _, e := range backend.Engines() {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
@@ -48,6 +48,10 @@ func (cfg *Config) setupLogging() error {
 		return fmt.Errorf("--logger=capnslog is removed in v3.5")
 
 	case "zap":
+		var lvl zapcore.Level
+		if err := lvl.Set(cfg.LogLevel); err != nil {
+			return fmt.Errorf("--log-level is not valid: (%v)", err)
+		}
 		if len(cfg.LogOutputs) == 0 {
 			cfg.LogOutputs = []string{DefaultLogOutput}
 		}
@@ -117,6 +121,7 @@ func (cfg *Config) setupLogging() error {
 					return err
 				}
 				cfg.ZapLoggerBuilder = NewZapLoggerBuilder(lg)
+				cfg.logLevel = &copied.Level
 			}
 		} else {
 			if len(cfg.LogOutputs) > 1 {
@@ -156,6 +161,7 @@ func (cfg *Config) setupLogging() error {
 			)
 			if cfg.ZapLoggerBuilder == nil {
 				cfg.ZapLoggerBuilder = NewZapLoggerBuilder(zap.New(cr, zap.AddCaller(), zap.ErrorOutput(syncer)))
+				cfg.logLevel = &lvl
 			}
 		}
 
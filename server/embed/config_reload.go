@@ -0,0 +1,256 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"crypto/tls"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// The fields of the configuration applied by a reload. The files they name are
+// read again by every reload.
+const (
+	reloadLogLevel           = "log-level"
+	reloadCORS               = "cors"
+	reloadHostWhitelist      = "host-whitelist"
+	reloadCipherSuites       = "cipher-suites"
+	reloadClientCertFile     = "client-transport-security.cert-file"
+	reloadClientKeyFile      = "client-transport-security.key-file"
+	reloadClientTrustedCA    = "client-transport-security.trusted-ca-file"
+	reloadClientCertAuthFile = "experimental-client-cert-auth-rules-file"
+)
+
+// untaggedFields are the fields of the configuration compared by a reload that
+// are not read from the configuration file under their own name.
+var untaggedFields = []struct {
+	name  string
+	value func(*Config) interface{}
+}{
+	{"listen-peer-urls", func(c *Config) interface{} { return c.LPUrls }},
+	{"listen-client-urls", func(c *Config) interface{} { return c.LCUrls }},
+	{"initial-advertise-peer-urls", func(c *Config) interface{} { return c.APUrls }},
+	{"advertise-client-urls", func(c *Config) interface{} { return c.ACUrls }},
+	{"listen-metrics-urls", func(c *Config) interface{} { return c.ListenMetricsUrls }},
+	{"client-transport-security.client-cert-file", func(c *Config) interface{} { return c.ClientTLSInfo.ClientCertFile }},
+	{"client-transport-security.client-key-file", func(c *Config) interface{} { return c.ClientTLSInfo.ClientKeyFile }},
+	{"client-transport-security.client-cert-auth", func(c *Config) interface{} { return c.ClientTLSInfo.ClientCertAuth }},
+	{"client-transport-security.auto-tls", func(c *Config) interface{} { return c.ClientAutoTLS }},
+	{"peer-transport-security.cert-file", func(c *Config) interface{} { return c.PeerTLSInfo.CertFile }},
+	{"peer-transport-security.key-file", func(c *Config) interface{} { return c.PeerTLSInfo.KeyFile }},
+	{"peer-transport-security.client-cert-file", func(c *Config) interface{} { return c.PeerTLSInfo.ClientCertFile }},
+	{"peer-transport-security.client-key-file", func(c *Config) interface{} { return c.PeerTLSInfo.ClientKeyFile }},
+	{"peer-transport-security.client-cert-auth", func(c *Config) interface{} { return c.PeerTLSInfo.ClientCertAuth }},
+	{"peer-transport-security.trusted-ca-file", func(c *Config) interface{} { return c.PeerTLSInfo.TrustedCAFile }},
+	{"peer-transport-security.auto-tls", func(c *Config) interface{} { return c.PeerAutoTLS }},
+}
+
+// tlsReloader serves the TLS configuration of the connections accepted by the
+// listeners as last reloaded, the original one until the first reload.
+type tlsReloader struct {
+	cfg atomic.Value
+}
+
+func (r *tlsReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	cfg, _ := r.cfg.Load().(*tls.Config)
+	return cfg, nil
+}
+
+// reloadConfigFile reads again the configuration file of the member and
+// reloads the configuration.
+func (e *Etcd) reloadConfigFile(dryRun bool) (*etcdserver.ConfigReloadReport, error) {
+	if e.cfg.configFile == "" {
+		return nil, etcdserver.ErrConfigReloadUnsupported
+	}
+	ycfg := &configYAML{Config: *NewConfig()}
+	// keep logging with the logger of the member
+	ycfg.ZapLoggerBuilder = NewZapLoggerBuilder(e.GetLogger())
+	if err := ycfg.configFromFile(e.cfg.configFile); err != nil {
+		return nil, err
+	}
+	return e.reloadConfig(&ycfg.Config, dryRun)
+}
+
+// reloadConfig validates the new configuration against the configuration as
+// last reloaded and, unless dryRun, applies its reloadable fields. The other
+// changed fields are reported as ignored.
+func (e *Etcd) reloadConfig(newCfg *Config, dryRun bool) (*etcdserver.ConfigReloadReport, error) {
+	cur := &e.reloadCfg
+	var applied, ignored []string
+	var apply []func()
+
+	if err := updateCipherSuites(&transport.TLSInfo{}, newCfg.CipherSuites); err != nil {
+		return nil, fmt.Errorf("--cipher-suites is not valid: (%v)", err)
+	}
+
+	if newCfg.LogLevel != cur.LogLevel {
+		var lvl zapcore.Level
+		if err := lvl.Set(newCfg.LogLevel); err != nil {
+			return nil, fmt.Errorf("--log-level is not valid: (%v)", err)
+		}
+		if e.cfg.logLevel == nil {
+			// the logger was built by the embedding application
+			ignored = append(ignored, reloadLogLevel)
+		} else {
+			applied = append(applied, reloadLogLevel)
+			apply = append(apply, func() {
+				e.cfg.logLevel.SetLevel(lvl)
+				cur.LogLevel = newCfg.LogLevel
+			})
+		}
+	}
+
+	if !reflect.DeepEqual(newCfg.CORS, cur.CORS) {
+		applied = append(applied, reloadCORS)
+		apply = append(apply, func() {
+			e.Server.AccessController.SetCORS(newCfg.CORS)
+			cur.CORS = newCfg.CORS
+		})
+	}
+	if !reflect.DeepEqual(newCfg.HostWhitelist, cur.HostWhitelist) {
+		applied = append(applied, reloadHostWhitelist)
+		apply = append(apply, func() {
+			e.Server.AccessController.SetHostWhitelist(newCfg.HostWhitelist)
+			cur.HostWhitelist = newCfg.HostWhitelist
+		})
+	}
+
+	// the client certificate and key files can change while the member serves
+	// TLS with the files given by the configuration
+	clientInfo := e.cfg.ClientTLSInfo
+	clientFiles := []struct {
+		name     string
+		cur, new *string
+		info     *string
+	}{
+		{reloadClientCertFile, &cur.ClientTLSInfo.CertFile, &newCfg.ClientTLSInfo.CertFile, &clientInfo.CertFile},
+		{reloadClientKeyFile, &cur.ClientTLSInfo.KeyFile, &newCfg.ClientTLSInfo.KeyFile, &clientInfo.KeyFile},
+		{reloadClientTrustedCA, &cur.ClientTLSInfo.TrustedCAFile, &newCfg.ClientTLSInfo.TrustedCAFile, &clientInfo.TrustedCAFile},
+	}
+	filesReloadable := e.clientTLS != nil && !e.cfg.ClientAutoTLS && !cur.ClientTLSInfo.Empty() && !newCfg.ClientTLSInfo.Empty()
+	for _, f := range clientFiles {
+		if *f.new == *f.cur {
+			continue
+		}
+		if !filesReloadable {
+			ignored = append(ignored, f.name)
+			continue
+		}
+		applied = append(applied, f.name)
+		*f.info = *f.new
+		f := f
+		apply = append(apply, func() { *f.cur = *f.new })
+	}
+
+	if !reflect.DeepEqual(newCfg.CipherSuites, cur.CipherSuites) {
+		if e.clientTLS == nil && e.peerTLS == nil {
+			ignored = append(ignored, reloadCipherSuites)
+		} else {
+			applied = append(applied, reloadCipherSuites)
+			apply = append(apply, func() { cur.CipherSuites = newCfg.CipherSuites })
+		}
+	}
+
+	// the TLS configurations are built again to read the files again
+	clientTLS, err := reloadServerTLS(e.clientTLS, clientInfo, newCfg.CipherSuites)
+	if err != nil {
+		return nil, fmt.Errorf("client TLS configuration is not valid: (%v)", err)
+	}
+	peerTLS, err := reloadServerTLS(e.peerTLS, e.cfg.PeerTLSInfo, newCfg.CipherSuites)
+	if err != nil {
+		return nil, fmt.Errorf("peer TLS configuration is not valid: (%v)", err)
+	}
+	apply = append(apply, func() {
+		if clientTLS != nil {
+			e.clientTLS.cfg.Store(clientTLS)
+		}
+		if peerTLS != nil {
+			e.peerTLS.cfg.Store(peerTLS)
+		}
+	})
+
+	var certRules *auth.ClientCertRules
+	if newCfg.ExperimentalClientCertAuthRulesFile != "" {
+		if certRules, err = auth.ReadClientCertRules(newCfg.ExperimentalClientCertAuthRulesFile); err != nil {
+			return nil, fmt.Errorf("--experimental-client-cert-auth-rules-file is not valid: (%v)", err)
+		}
+	}
+	if newCfg.ExperimentalClientCertAuthRulesFile != cur.ExperimentalClientCertAuthRulesFile {
+		applied = append(applied, reloadClientCertAuthFile)
+	}
+	apply = append(apply, func() {
+		e.Server.AuthStore().SetClientCertRules(certRules)
+		cur.ExperimentalClientCertAuthRulesFile = newCfg.ExperimentalClientCertAuthRulesFile
+	})
+
+	ignored = append(ignored, changedConfigFields(cur, newCfg)...)
+	sort.Strings(applied)
+	sort.Strings(ignored)
+	if !dryRun {
+		for _, f := range apply {
+			f()
+		}
+	}
+	return &etcdserver.ConfigReloadReport{Applied: applied, Ignored: ignored}, nil
+}
+
+// reloadServerTLS builds the server TLS configuration of the listeners served
+// by the reloader, nil if there is none.
+func reloadServerTLS(r *tlsReloader, info transport.TLSInfo, cipherSuites []string) (*tls.Config, error) {
+	if r == nil || info.Empty() {
+		return nil, nil
+	}
+	info.GetConfigForClient = nil
+	info.CipherSuites = nil
+	if err := updateCipherSuites(&info, cipherSuites); err != nil {
+		return nil, err
+	}
+	return info.ServerConfig()
+}
+
+// changedConfigFields returns the names of the fields that need a restart of
+// the member to change, and differ between the configurations.
+func changedConfigFields(cur, newCfg *Config) (changed []string) {
+	cv, nv := reflect.ValueOf(cur).Elem(), reflect.ValueOf(newCfg).Elem()
+	t := cv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		switch name {
+		case "", "-", reloadLogLevel, reloadCipherSuites, reloadClientCertAuthFile, "listen-metrics-urls":
+			continue
+		}
+		if t.Field(i).Type.Kind() == reflect.Func {
+			continue
+		}
+		if !reflect.DeepEqual(cv.Field(i).Interface(), nv.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	for _, f := range untaggedFields {
+		if !reflect.DeepEqual(f.value(cur), f.value(newCfg)) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
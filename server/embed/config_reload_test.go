@@ -0,0 +1,91 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReloadConfigReport(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{"/dev/null"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	e := &Etcd{cfg: *cfg, reloadCfg: *cfg, clientTLS: &tlsReloader{}, peerTLS: &tlsReloader{}}
+
+	newConfig := func() *Config {
+		c := *cfg
+		c.ZapLoggerBuilder = NewZapLoggerBuilder(zap.NewNop())
+		return &c
+	}
+
+	// an unchanged configuration changes nothing
+	report, err := e.reloadConfig(newConfig(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Applied) != 0 || len(report.Ignored) != 0 {
+		t.Errorf("expected no changed fields, got %+v", report)
+	}
+
+	newCfg := newConfig()
+	newCfg.LogLevel = "debug"
+	newCfg.CORS = map[string]struct{}{"http://example.com": {}}
+	newCfg.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}
+	newCfg.MaxTxnOps = 256
+	purl, _ := url.Parse("http://localhost:12380")
+	newCfg.LPUrls = []url.URL{*purl}
+	newCfg.ClientTLSInfo.CertFile = "server.crt"
+	report, err = e.reloadConfig(newCfg, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wApplied := []string{reloadCipherSuites, reloadCORS, reloadLogLevel}
+	// the member does not serve client TLS, which cannot be enabled by a reload
+	wIgnored := []string{reloadClientCertFile, "listen-peer-urls", "max-txn-ops"}
+	if !reflect.DeepEqual(report.Applied, wApplied) || !reflect.DeepEqual(report.Ignored, wIgnored) {
+		t.Errorf("expected applied %v and ignored %v, got %+v", wApplied, wIgnored, report)
+	}
+	// a dry run applies nothing
+	if e.reloadCfg.LogLevel != cfg.LogLevel || len(e.reloadCfg.CipherSuites) != 0 {
+		t.Errorf("dry run changed the configuration")
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+	}{
+		{"log level", func(c *Config) { c.LogLevel = "verbose" }},
+		{"cipher suite", func(c *Config) { c.CipherSuites = []string{"TLS_NULL"} }},
+		{"client certificate rules", func(c *Config) {
+			c.ExperimentalClientCertAuthRulesFile = filepath.Join(t.TempDir(), "missing.yaml")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConfig()
+			tt.change(c)
+			if _, err := e.reloadConfig(c, false); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -181,6 +181,20 @@ func TestAutoCompactionModeInvalid(t *testing.T) {
 	}
 }
 
+func TestAutoDefragRequiresOnlineDefrag(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Logger = "zap"
+	cfg.LogOutputs = []string{"/dev/null"}
+	cfg.ExperimentalAutoDefragInUseRatio = 0.5
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("expected non-nil error, got %v", err)
+	}
+	cfg.ExperimentalOnlineDefrag = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
+
 func TestAutoCompactionModeParse(t *testing.T) {
 	tests := []struct {
 		mode      string
@@ -75,7 +75,13 @@ type Etcd struct {
 
 	Server *etcdserver.EtcdServer
 
-	cfg   Config
+	cfg Config
+	// reloadCfg is the configuration as last reloaded.
+	reloadCfg Config
+	// clientTLS and peerTLS serve the TLS configurations of the listeners, as last
+	// reloaded, nil if set by the embedding application.
+	clientTLS, peerTLS *tlsReloader
+
 	stopc chan struct{}
 	errc  chan error
 
@@ -96,7 +102,7 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		return nil, err
 	}
 	serving := false
-	e = &Etcd{cfg: *inCfg, stopc: make(chan struct{})}
+	e = &Etcd{cfg: *inCfg, reloadCfg: *inCfg, stopc: make(chan struct{})}
 	cfg := &e.cfg
 	defer func() {
 		if e == nil || err == nil {
@@ -119,6 +125,15 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 			zap.Bool("reuse-port", cfg.SocketOpts.ReusePort),
 		)
 	}
+	if cfg.PeerTLSInfo.GetConfigForClient == nil {
+		e.peerTLS = &tlsReloader{}
+		cfg.PeerTLSInfo.GetConfigForClient = e.peerTLS.configForClient
+	}
+	if cfg.ClientTLSInfo.GetConfigForClient == nil {
+		e.clientTLS = &tlsReloader{}
+		cfg.ClientTLSInfo.GetConfigForClient = e.clientTLS.configForClient
+	}
+
 	e.cfg.logger.Info(
 		"configuring peer listeners",
 		zap.Strings("listen-peer-urls", e.cfg.getLPURLs()),
@@ -179,6 +194,7 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		DiscoveryProxy:                           cfg.Dproxy,
 		NewCluster:                               cfg.IsNewCluster(),
 		PeerTLSInfo:                              cfg.PeerTLSInfo,
+		PeerDialer:                               cfg.peerDialer(),
 		TickMs:                                   cfg.TickMs,
 		ElectionTicks:                            cfg.ElectionTicks(),
 		WaitClusterReadyTimeout:                  cfg.ExperimentalWaitClusterReadyTimeout,
@@ -218,7 +234,17 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		ExperimentalMemoryMlock:                  cfg.ExperimentalMemoryMlock,
 		ExperimentalTxnModeWriteWithSharedBuffer: cfg.ExperimentalTxnModeWriteWithSharedBuffer,
 		ExperimentalBootstrapDefragThresholdMegabytes: cfg.ExperimentalBootstrapDefragThresholdMegabytes,
+		ExperimentalOnlineDefrag:                      cfg.ExperimentalOnlineDefrag,
+		ExperimentalAutoDefragInUseRatio:              cfg.ExperimentalAutoDefragInUseRatio,
+		ExperimentalAutoDefragMinFreeMegabytes:        cfg.ExperimentalAutoDefragMinFreeMegabytes,
+		ExperimentalAutoDefragCheckInterval:           cfg.ExperimentalAutoDefragCheckInterval,
+		ExperimentalAutoCompactionMaxRevisions:        cfg.ExperimentalAutoCompactionMaxRevisions,
+		ExperimentalAutoCompactionMaxDBSizeInUseBytes: cfg.ExperimentalAutoCompactionMaxDBSizeInUseBytes,
 		ExperimentalMaxLearners:                       cfg.ExperimentalMaxLearners,
+		ExperimentalLearnerServeReads:                 cfg.ExperimentalLearnerServeReads,
+		ExperimentalSecondaryIndexes:                  cfg.ExperimentalSecondaryIndexes,
+		ExperimentalBackendEngine:                     cfg.ExperimentalBackendEngine,
+		ExperimentalClientCertAuthRulesFile:           cfg.ExperimentalClientCertAuthRulesFile,
 		V2Deprecation:                                 cfg.V2DeprecationEffective(),
 	}
 
@@ -244,6 +270,7 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 	if e.Server, err = etcdserver.NewServer(srvcfg); err != nil {
 		return e, err
 	}
+	e.Server.SetConfigReloader(e.reloadConfigFile)
 
 	// buffer channel so goroutines on closed connections won't wait forever
 	e.errc = make(chan error, len(e.Peers)+len(e.Clients)+2*len(e.sctxs))
@@ -347,6 +374,7 @@ func print(lg *zap.Logger, ec Config, sc config.ServerConfig, memberInitialized
 		zap.String("discovery-proxy", sc.DiscoveryProxy),
 		zap.String("downgrade-check-interval", sc.DowngradeCheckTime.String()),
 		zap.Int("max-learners", sc.ExperimentalMaxLearners),
+		zap.Bool("learner-serve-reads", sc.ExperimentalLearnerServeReads),
 	)
 }
 
@@ -513,11 +541,15 @@ func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
 			}
 		}
 		peers[i] = &peerListener{close: func(context.Context) error { return nil }}
-		peers[i].Listener, err = transport.NewListenerWithOpts(u.Host, u.Scheme,
-			transport.WithTLSInfo(&cfg.PeerTLSInfo),
-			transport.WithSocketOpts(&cfg.SocketOpts),
-			transport.WithTimeout(rafthttp.ConnReadTimeout, rafthttp.ConnWriteTimeout),
-		)
+		if cfg.ExperimentalNetwork != nil {
+			peers[i].Listener, err = cfg.listenNetwork(u, &cfg.PeerTLSInfo)
+		} else {
+			peers[i].Listener, err = transport.NewListenerWithOpts(u.Host, u.Scheme,
+				transport.WithTLSInfo(&cfg.PeerTLSInfo),
+				transport.WithSocketOpts(&cfg.SocketOpts),
+				transport.WithTimeout(rafthttp.ConnReadTimeout, rafthttp.ConnWriteTimeout),
+			)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -529,6 +561,26 @@ func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
 	return peers, nil
 }
 
+// listenNetwork announces on the URL with the configured network,
+// serving TLS for https URLs.
+func (cfg *Config) listenNetwork(u url.URL, tlsinfo *transport.TLSInfo) (net.Listener, error) {
+	l, err := cfg.ExperimentalNetwork.Listen("tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		return transport.NewTLSListener(l, tlsinfo)
+	}
+	return l, nil
+}
+
+func (cfg *Config) peerDialer() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if cfg.ExperimentalNetwork == nil {
+		return nil
+	}
+	return cfg.ExperimentalNetwork.DialContext
+}
+
 // configure peer handlers after rafthttp.Transport started
 func (e *Etcd) servePeers() (err error) {
 	ph := etcdhttp.NewPeerHandler(e.GetLogger(), e.Server)
@@ -631,31 +683,40 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 			continue
 		}
 
-		if sctx.l, err = transport.NewListenerWithOpts(addr, u.Scheme,
-			transport.WithSocketOpts(&cfg.SocketOpts),
-			transport.WithSkipTLSInfoCheck(true),
-		); err != nil {
-			return nil, err
-		}
-		// net.Listener will rewrite ipv4 0.0.0.0 to ipv6 [::], breaking
-		// hosts that disable ipv6. So, use the address given by the user.
-		sctx.addr = addr
-
-		if fdLimit, fderr := runtimeutil.FDLimit(); fderr == nil {
-			if fdLimit <= reservedInternalFDNum {
-				cfg.logger.Fatal(
-					"file descriptor limit of etcd process is too low; please set higher",
-					zap.Uint64("limit", fdLimit),
-					zap.Int("recommended-limit", reservedInternalFDNum),
-				)
+		if cfg.ExperimentalNetwork != nil {
+			// TLS is set up when serving, as with the OS network stack
+			if sctx.l, err = cfg.ExperimentalNetwork.Listen(network, addr); err != nil {
+				return nil, err
 			}
-			sctx.l = transport.LimitListener(sctx.l, int(fdLimit-reservedInternalFDNum))
-		}
-
-		if network == "tcp" {
-			if sctx.l, err = transport.NewKeepAliveListener(sctx.l, network, nil); err != nil {
+			sctx.addr = addr
+			sctx.dial = cfg.ExperimentalNetwork.DialContext
+		} else {
+			if sctx.l, err = transport.NewListenerWithOpts(addr, u.Scheme,
+				transport.WithSocketOpts(&cfg.SocketOpts),
+				transport.WithSkipTLSInfoCheck(true),
+			); err != nil {
 				return nil, err
 			}
+			// net.Listener will rewrite ipv4 0.0.0.0 to ipv6 [::], breaking
+			// hosts that disable ipv6. So, use the address given by the user.
+			sctx.addr = addr
+
+			if fdLimit, fderr := runtimeutil.FDLimit(); fderr == nil {
+				if fdLimit <= reservedInternalFDNum {
+					cfg.logger.Fatal(
+						"file descriptor limit of etcd process is too low; please set higher",
+						zap.Uint64("limit", fdLimit),
+						zap.Int("recommended-limit", reservedInternalFDNum),
+					)
+				}
+				sctx.l = transport.LimitListener(sctx.l, int(fdLimit-reservedInternalFDNum))
+			}
+
+			if network == "tcp" {
+				if sctx.l, err = transport.NewKeepAliveListener(sctx.l, network, nil); err != nil {
+					return nil, err
+				}
+			}
 		}
 
 		defer func(u url.URL) {
@@ -736,10 +797,15 @@ func (e *Etcd) serveMetrics() (err error) {
 			if murl.Scheme == "http" {
 				tlsInfo = nil
 			}
-			ml, err := transport.NewListenerWithOpts(murl.Host, murl.Scheme,
-				transport.WithTLSInfo(tlsInfo),
-				transport.WithSocketOpts(&e.cfg.SocketOpts),
-			)
+			var ml net.Listener
+			if e.cfg.ExperimentalNetwork != nil {
+				ml, err = e.cfg.listenNetwork(murl, tlsInfo)
+			} else {
+				ml, err = transport.NewListenerWithOpts(murl.Host, murl.Scheme,
+					transport.WithTLSInfo(tlsInfo),
+					transport.WithSocketOpts(&e.cfg.SocketOpts),
+				)
+			}
 			if err != nil {
 				return err
 			}
@@ -782,7 +848,7 @@ func parseCompactionRetention(mode, retention string) (ret time.Duration, err er
 		switch mode {
 		case CompactorModeRevision:
 			ret = time.Duration(int64(h))
-		case CompactorModePeriodic:
+		case CompactorModePeriodic, CompactorModeAdaptive:
 			ret = time.Duration(int64(h)) * time.Hour
 		}
 	} else {
@@ -49,10 +49,12 @@ import (
 )
 
 type serveCtx struct {
-	lg       *zap.Logger
-	l        net.Listener
-	addr     string
-	network  string
+	lg      *zap.Logger
+	l       net.Listener
+	addr    string
+	network string
+	// dial connects to the listener when it is not on the OS network stack
+	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
 	secure   bool
 	insecure bool
 
@@ -232,6 +234,13 @@ func (sctx *serveCtx) registerGateway(opts []grpc.DialOption) (*gw.ServeMux, err
 		addr = fmt.Sprintf("%s://%s", network, addr)
 	}
 
+	if dial := sctx.dial; dial != nil {
+		network := sctx.network
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		}))
+	}
+
 	opts = append(opts, grpc.WithDefaultCallOptions([]grpc.CallOption{
 		grpc.MaxCallRecvMsgSize(math.MaxInt32),
 	}...))
@@ -30,6 +30,7 @@ import (
 	"go.etcd.io/etcd/server/v3/embed"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
+	"go.etcd.io/etcd/server/v3/storage/backend"
 
 	"go.uber.org/zap"
 	"sigs.k8s.io/yaml"
@@ -252,7 +253,9 @@ func newConfig() *config {
 	fs.BoolVar(&cfg.printVersion, "version", false, "Print the version and exit.")
 
 	fs.StringVar(&cfg.ec.AutoCompactionRetention, "auto-compaction-retention", "0", "Auto compaction retention for mvcc key value store. 0 means disable auto compaction.")
-	fs.StringVar(&cfg.ec.AutoCompactionMode, "auto-compaction-mode", "periodic", "interpret 'auto-compaction-retention' one of: periodic|revision. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention.")
+	fs.StringVar(&cfg.ec.AutoCompactionMode, "auto-compaction-mode", "periodic", "interpret 'auto-compaction-retention' one of: periodic|revision|adaptive. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention. 'adaptive' for compacting when a threshold is crossed, keeping at least the duration based retention.")
+	fs.Int64Var(&cfg.ec.ExperimentalAutoCompactionMaxRevisions, "experimental-auto-compaction-max-revisions", 0, "Number of revisions kept since the last compaction above which the 'adaptive' auto compaction compacts. 0 disables the threshold.")
+	fs.Int64Var(&cfg.ec.ExperimentalAutoCompactionMaxDBSizeInUseBytes, "experimental-auto-compaction-max-db-size-in-use-bytes", 0, "Backend size in use above which the 'adaptive' auto compaction compacts. 0 disables the threshold.")
 
 	// pprof profiler via HTTP
 	fs.BoolVar(&cfg.ec.EnablePprof, "enable-pprof", false, "Enable runtime profiling data via HTTP server. Address is at client URL + \"/debug/pprof/\"")
@@ -291,7 +294,15 @@ func newConfig() *config {
 	fs.BoolVar(&cfg.ec.ExperimentalMemoryMlock, "experimental-memory-mlock", cfg.ec.ExperimentalMemoryMlock, "Enable to enforce etcd pages (in particular bbolt) to stay in RAM.")
 	fs.BoolVar(&cfg.ec.ExperimentalTxnModeWriteWithSharedBuffer, "experimental-txn-mode-write-with-shared-buffer", true, "Enable the write transaction to use a shared buffer in its readonly check operations.")
 	fs.UintVar(&cfg.ec.ExperimentalBootstrapDefragThresholdMegabytes, "experimental-bootstrap-defrag-threshold-megabytes", 0, "Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.")
+	fs.BoolVar(&cfg.ec.ExperimentalOnlineDefrag, "experimental-online-defrag", false, "Enable to defragment the backend while serving reads and writes, only blocking them to swap the database files.")
+	fs.Float64Var(&cfg.ec.ExperimentalAutoDefragInUseRatio, "experimental-auto-defrag-in-use-ratio", 0, "Defragment the backend when the ratio of its size in use to its size falls below the value. Needs to be set to non-zero value to take effect, and requires --experimental-online-defrag.")
+	fs.UintVar(&cfg.ec.ExperimentalAutoDefragMinFreeMegabytes, "experimental-auto-defrag-min-free-megabytes", 0, "Minimum number of megabytes of disk space the automatic defragmentation must free to run.")
+	fs.DurationVar(&cfg.ec.ExperimentalAutoDefragCheckInterval, "experimental-auto-defrag-check-interval", cfg.ec.ExperimentalAutoDefragCheckInterval, "Duration of time between two checks of the automatic defragmentation.")
+	fs.BoolVar(&cfg.ec.ExperimentalLearnerServeReads, "experimental-learner-serve-reads", false, "Enable learners to serve serializable and linearizable ranges and watches.")
 	fs.IntVar(&cfg.ec.ExperimentalMaxLearners, "experimental-max-learners", membership.DefaultMaxLearners, "Sets the maximum number of learners that can be available in the cluster membership.")
+	fs.Var(flags.NewStringsValue(""), "experimental-secondary-indexes", "Comma-separated list of secondary indexes on JSON fields of values, each in the form <prefix>=<field>.")
+	fs.StringVar(&cfg.ec.ExperimentalBackendEngine, "experimental-backend-engine", backend.DefaultEngine, "Storage engine of the backend. All members of a cluster must use the same engine.")
+	fs.StringVar(&cfg.ec.ExperimentalClientCertAuthRulesFile, "experimental-client-cert-auth-rules-file", "", "Path to the YAML or JSON file of the rules mapping the client certificates to users and roles.")
 	fs.DurationVar(&cfg.ec.ExperimentalWaitClusterReadyTimeout, "experimental-wait-cluster-ready-timeout", cfg.ec.ExperimentalWaitClusterReadyTimeout, "Maximum duration to wait for the cluster to be ready.")
 
 	// unsafe
@@ -399,6 +410,7 @@ func (cfg *config) configFromCmdLine() error {
 	cfg.ec.HostWhitelist = flags.UniqueStringsMapFromFlag(cfg.cf.flagSet, "host-whitelist")
 
 	cfg.ec.CipherSuites = flags.StringsFromFlag(cfg.cf.flagSet, "cipher-suites")
+	cfg.ec.ExperimentalSecondaryIndexes = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-secondary-indexes")
 
 	cfg.ec.LogOutputs = flags.UniqueStringsFromFlag(cfg.cf.flagSet, "log-outputs")
 
@@ -15,16 +15,20 @@
 package etcdmain
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
@@ -228,6 +232,7 @@ func startEtcd(cfg *embed.Config) (<-chan struct{}, <-chan error, error) {
 		return nil, nil, err
 	}
 	osutil.RegisterInterruptHandler(e.Close)
+	reloadConfigOnSIGHUP(e)
 	select {
 	case <-e.Server.ReadyNotify(): // wait for e.Server to join the cluster
 	case <-e.Server.StopNotify(): // publish aborted from 'ErrStopped'
@@ -235,6 +240,26 @@ func startEtcd(cfg *embed.Config) (<-chan struct{}, <-chan error, error) {
 	return e.Server.StopNotify(), e.Err(), nil
 }
 
+// reloadConfigOnSIGHUP reloads the configuration of the member from its
+// configuration file on SIGHUP until the member stops.
+func reloadConfigOnSIGHUP(e *embed.Etcd) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigc)
+		for {
+			select {
+			case <-sigc:
+				e.GetLogger().Info("received SIGHUP, reloading configuration")
+				// the outcome of the reload is logged by the server
+				e.Server.ReloadConfig(context.Background(), &pb.ReloadConfigRequest{})
+			case <-e.Server.StopNotify():
+				return
+			}
+		}
+	}()
+}
+
 // startProxy launches an HTTP proxy for client communication which proxies to other etcd nodes.
 func startProxy(cfg *config) error {
 	lg := cfg.ec.GetLogger()
@@ -36,7 +36,7 @@ var (
     Show the help information about etcd.
 
   etcd --config-file
-    Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.
+    Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored. Sending SIGHUP to etcd reloads the reloadable fields of the file.
 
   etcd gateway
     Run the stateless pass-through etcd TCP connection forwarding proxy.
@@ -122,7 +122,11 @@ Clustering:
   --auto-compaction-retention '0'
     Auto compaction retention length. 0 means disable auto compaction.
   --auto-compaction-mode 'periodic'
-    Interpret 'auto-compaction-retention' one of: periodic|revision. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention.
+    Interpret 'auto-compaction-retention' one of: periodic|revision|adaptive. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention. 'adaptive' for compacting when a threshold is crossed, keeping at least the duration based retention.
+  --experimental-auto-compaction-max-revisions '0'
+    Number of revisions kept since the last compaction above which the 'adaptive' auto compaction compacts. 0 disables the threshold.
+  --experimental-auto-compaction-max-db-size-in-use-bytes '0'
+    Backend size in use above which the 'adaptive' auto compaction compacts. 0 disables the threshold.
   --v2-deprecation '` + string(cconfig.V2_DEPR_DEFAULT) + `'
     Phase of v2store deprecation. Allows to opt-in for higher compatibility mode.
     Supported values:
@@ -246,12 +250,28 @@ Experimental feature:
     Enable the write transaction to use a shared buffer in its readonly check operations.
   --experimental-bootstrap-defrag-threshold-megabytes
     Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.
+  --experimental-online-defrag 'false'
+    Enable to defragment the backend while serving reads and writes, only blocking them to swap the database files.
+  --experimental-auto-defrag-in-use-ratio '0'
+    Defragment the backend when the ratio of its size in use to its size falls below the value. Needs to be set to non-zero value to take effect, and requires --experimental-online-defrag.
+  --experimental-auto-defrag-min-free-megabytes '0'
+    Minimum number of megabytes of disk space the automatic defragmentation must free to run.
+  --experimental-auto-defrag-check-interval '5m'
+    Duration of time between two checks of the automatic defragmentation.
   --experimental-warning-unary-request-duration '300ms'
     Set time duration after which a warning is generated if a unary request takes more than this duration.
+  --experimental-learner-serve-reads 'false'
+    Enable learners to serve serializable and linearizable ranges and watches.
   --experimental-max-learners '1'
     Set the max number of learner members allowed in the cluster membership.
   --experimental-wait-cluster-ready-timeout '5s'
     Set the maximum time duration to wait for the cluster to be ready.
+  --experimental-secondary-indexes ''
+    Comma-separated list of secondary indexes on JSON fields of values, each in the form <prefix>=<field> (e.g. '/jobs/=state').
+  --experimental-backend-engine 'bbolt'
+    Storage engine of the backend. All members of a cluster must use the same engine.
+  --experimental-client-cert-auth-rules-file ''
+    Path to the YAML or JSON file of the rules mapping the client certificates (CN, OU, O, DNS, URI or email SAN) to users and roles. The common name is the user if not given.
 
 Unsafe feature:
   --force-new-cluster 'false'
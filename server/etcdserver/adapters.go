@@ -22,6 +22,7 @@ import (
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/membershippb"
 	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
 	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 )
@@ -88,3 +89,24 @@ func (s *serverVersionAdapter) UpdateStorageVersion(target semver.Version) error
 	defer tx.Unlock()
 	return schema.UnsafeMigrate(s.lg, tx, s.r.storage, target)
 }
+
+// compactionStatsAdapter implements AdaptiveStatsGetter interface needed by
+// the adaptive v3compactor.
+type compactionStatsAdapter struct {
+	*EtcdServer
+}
+
+var _ v3compactor.AdaptiveStatsGetter = (*compactionStatsAdapter)(nil)
+
+func (s *compactionStatsAdapter) Rev() int64 {
+	return s.KV().Rev()
+}
+
+func (s *compactionStatsAdapter) CompactRev() int64 {
+	// the first revision of a read is the revision of the last compaction
+	return s.KV().FirstRev()
+}
+
+func (s *compactionStatsAdapter) SizeInUse() int64 {
+	return s.Backend().SizeInUse()
+}
@@ -103,6 +103,9 @@ type Transport struct {
 	DialRetryFrequency rate.Limit
 
 	TLSInfo transport.TLSInfo // TLS information used when creating connection
+	// Dialer, if set, connects to remote peers instead of the operating
+	// system network stack, e.g. to run peers over a simulated network.
+	Dialer DialFunc
 
 	ID          types.ID   // local member ID
 	URLs        types.URLs // local peer URLs
@@ -136,7 +139,8 @@ func (t *Transport) Start() error {
 	if err != nil {
 		return err
 	}
-	t.pipelineRt, err = NewRoundTripper(t.TLSInfo, t.DialTimeout)
+	t.streamRt = withDialer(t.streamRt, t.Dialer)
+	t.pipelineRt, err = NewRoundTripperWithDialer(t.TLSInfo, t.DialTimeout, t.Dialer)
 	if err != nil {
 		return err
 	}
@@ -15,6 +15,7 @@
 package rafthttp
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -51,6 +52,17 @@ func NewRoundTripper(tlsInfo transport.TLSInfo, dialTimeout time.Duration) (http
 	return transport.NewTimeoutTransport(tlsInfo, dialTimeout, 0, 0)
 }
 
+// NewRoundTripperWithDialer returns a roundTripper like NewRoundTripper that
+// connects to remote peers with dial instead of the operating system network
+// stack. A nil dial makes it equivalent to NewRoundTripper.
+func NewRoundTripperWithDialer(tlsInfo transport.TLSInfo, dialTimeout time.Duration, dial DialFunc) (http.RoundTripper, error) {
+	rt, err := NewRoundTripper(tlsInfo, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return withDialer(rt, dial), nil
+}
+
 // newStreamRoundTripper returns a roundTripper used to send stream requests
 // to rafthttp listener of remote peers.
 // Read/write timeout is set for stream roundTripper to promptly
@@ -60,6 +72,19 @@ func newStreamRoundTripper(tlsInfo transport.TLSInfo, dialTimeout time.Duration)
 	return transport.NewTimeoutTransport(tlsInfo, dialTimeout, ConnReadTimeout, ConnWriteTimeout)
 }
 
+// DialFunc connects to the address on the named network.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func withDialer(rt http.RoundTripper, dial DialFunc) http.RoundTripper {
+	if dial == nil {
+		return rt
+	}
+	tr := rt.(*http.Transport)
+	tr.DialContext = dial
+	tr.Dial = nil
+	return tr
+}
+
 // createPostRequest creates a HTTP POST request that sends raft message.
 func createPostRequest(lg *zap.Logger, u url.URL, path string, body io.Reader, ct string, urls types.URLs, from, cid types.ID) *http.Request {
 	uu := u
@@ -30,13 +30,13 @@ import (
 func New(s *etcdserver.EtcdServer) *clientv3.Client {
 	c := clientv3.NewCtxClient(context.Background(), clientv3.WithZapLogger(s.Logger()))
 
-	kvc := adapter.KvServerToKvClient(v3rpc.NewQuotaKVServer(s))
+	kvc := adapter.KvServerToKvClient(v3rpc.NewTenantKVServer(s))
 	c.KV = clientv3.NewKVFromKVClient(kvc, c)
 
-	lc := adapter.LeaseServerToLeaseClient(v3rpc.NewQuotaLeaseServer(s))
+	lc := adapter.LeaseServerToLeaseClient(v3rpc.NewTenantLeaseServer(s))
 	c.Lease = clientv3.NewLeaseFromLeaseClient(lc, c, time.Second)
 
-	wc := adapter.WatchServerToWatchClient(v3rpc.NewWatchServer(s))
+	wc := adapter.WatchServerToWatchClient(v3rpc.NewTenantWatchServer(s))
 	c.Watcher = &watchWrapper{clientv3.NewWatchFromWatchClient(wc, c)}
 
 	mc := adapter.MaintenanceServerToMaintenanceClient(v3rpc.NewMaintenanceServer(s))
@@ -0,0 +1,219 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+)
+
+// AdaptiveThresholds are the thresholds above which the adaptive compactor
+// compacts. A zero threshold is disabled.
+type AdaptiveThresholds struct {
+	// MaxRevisions is the number of revisions kept since the last
+	// compaction above which to compact down to MaxRevisions revisions.
+	MaxRevisions int64
+	// MaxSizeInUseBytes is the size in use of the backend above which to
+	// compact all the revisions older than the window.
+	MaxSizeInUseBytes int64
+}
+
+// AdaptiveStatsGetter provides the statistics the adaptive compactor
+// compacts on.
+type AdaptiveStatsGetter interface {
+	RevGetter
+	// CompactRev returns the revision of the last compaction.
+	CompactRev() int64
+	// SizeInUse returns the size of the backend in use in bytes.
+	SizeInUse() int64
+}
+
+// Adaptive compacts the log when the number of revisions kept or the size
+// of the backend in use crosses a threshold, never purging the revisions
+// newer than the configured window.
+type Adaptive struct {
+	lg     *zap.Logger
+	clock  clockwork.Clock
+	window time.Duration
+	th     AdaptiveThresholds
+
+	sg AdaptiveStatsGetter
+	c  Compactable
+
+	// samples are the revisions recorded every check, oldest first.
+	samples []revSample
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// mu protects paused
+	mu     sync.RWMutex
+	paused bool
+}
+
+type revSample struct {
+	t   time.Time
+	rev int64
+}
+
+// newAdaptive creates a new instance of Adaptive compactor that keeps at
+// least the log of the window Duration.
+func newAdaptive(lg *zap.Logger, clock clockwork.Clock, window time.Duration, th AdaptiveThresholds, sg AdaptiveStatsGetter, c Compactable) *Adaptive {
+	ac := &Adaptive{
+		lg:     lg,
+		clock:  clock,
+		window: window,
+		th:     th,
+		sg:     sg,
+		c:      c,
+	}
+	ac.ctx, ac.cancel = context.WithCancel(context.Background())
+	return ac
+}
+
+// adaptiveMaxCheckInterval bounds the interval between two checks, so that
+// bursts of writes with a long window are noticed in time.
+const adaptiveMaxCheckInterval = time.Minute
+
+// Run runs adaptive compactor.
+func (ac *Adaptive) Run() {
+	checkInterval := ac.getCheckInterval()
+
+	go func() {
+		for {
+			ac.samples = append(ac.samples, revSample{t: ac.clock.Now(), rev: ac.sg.Rev()})
+
+			select {
+			case <-ac.ctx.Done():
+				return
+			case <-ac.clock.After(checkInterval):
+				// trim the samples while paused too, so that they do not grow
+				// on the members which are not the leader
+				ac.trimSamples()
+				ac.mu.RLock()
+				p := ac.paused
+				ac.mu.RUnlock()
+				if p {
+					continue
+				}
+			}
+
+			rev, reason := ac.compactRev()
+			if rev <= 0 {
+				continue
+			}
+
+			ac.lg.Info(
+				"starting auto adaptive compaction",
+				zap.Int64("revision", rev),
+				zap.String("reason", reason),
+				zap.Duration("compact-window", ac.window),
+			)
+			startTime := ac.clock.Now()
+			_, err := ac.c.Compact(ac.ctx, &pb.CompactionRequest{Revision: rev})
+			if err == nil || err == mvcc.ErrCompacted {
+				ac.lg.Info(
+					"completed auto adaptive compaction",
+					zap.Int64("revision", rev),
+					zap.String("reason", reason),
+					zap.Duration("compact-window", ac.window),
+					zap.Duration("took", ac.clock.Now().Sub(startTime)),
+				)
+			} else {
+				ac.lg.Warn(
+					"failed auto adaptive compaction",
+					zap.Int64("revision", rev),
+					zap.String("reason", reason),
+					zap.Duration("compact-window", ac.window),
+					zap.Duration("retry-interval", checkInterval),
+					zap.Error(err),
+				)
+			}
+		}
+	}()
+}
+
+// trimSamples drops the samples older than the newest one recorded before
+// the window, which is the first one left if any.
+func (ac *Adaptive) trimSamples() {
+	windowStart := ac.clock.Now().Add(-ac.window)
+	i := 0
+	for i+1 < len(ac.samples) && !ac.samples[i+1].t.After(windowStart) {
+		i++
+	}
+	ac.samples = ac.samples[i:]
+}
+
+// compactRev returns the revision to compact at and the threshold crossed,
+// or 0 if no compaction is needed.
+func (ac *Adaptive) compactRev() (int64, string) {
+	// the newest revision recorded before the window is the newest one that
+	// may be compacted
+	ac.trimSamples()
+	if len(ac.samples) == 0 || ac.samples[0].t.After(ac.clock.Now().Add(-ac.window)) {
+		return 0, ""
+	}
+	windowRev := ac.samples[0].rev
+
+	var rev int64
+	var reason string
+	compactRev, currentRev := ac.sg.CompactRev(), ac.sg.Rev()
+	switch {
+	case ac.th.MaxSizeInUseBytes > 0 && ac.sg.SizeInUse() > ac.th.MaxSizeInUseBytes:
+		rev, reason = windowRev, "db-size-in-use"
+	case ac.th.MaxRevisions > 0 && currentRev-compactRev > ac.th.MaxRevisions:
+		rev, reason = currentRev-ac.th.MaxRevisions, "revisions"
+		if rev > windowRev {
+			rev = windowRev
+		}
+	}
+	if rev <= compactRev {
+		return 0, ""
+	}
+	return rev, reason
+}
+
+func (ac *Adaptive) getCheckInterval() time.Duration {
+	itv := ac.window / retryDivisor
+	if itv > adaptiveMaxCheckInterval {
+		itv = adaptiveMaxCheckInterval
+	}
+	return itv
+}
+
+// Stop stops adaptive compactor.
+func (ac *Adaptive) Stop() {
+	ac.cancel()
+}
+
+// Pause pauses adaptive compactor.
+func (ac *Adaptive) Pause() {
+	ac.mu.Lock()
+	ac.paused = true
+	ac.mu.Unlock()
+}
+
+// Resume resumes adaptive compactor.
+func (ac *Adaptive) Resume() {
+	ac.mu.Lock()
+	ac.paused = false
+	ac.mu.Unlock()
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+)
+
+type fakeStatsGetter struct {
+	rev        int64
+	compactRev int64
+	sizeInUse  int64
+}
+
+func (fs *fakeStatsGetter) Rev() int64 { return atomic.LoadInt64(&fs.rev) }
+
+func (fs *fakeStatsGetter) CompactRev() int64 { return atomic.LoadInt64(&fs.compactRev) }
+
+func (fs *fakeStatsGetter) SizeInUse() int64 { return atomic.LoadInt64(&fs.sizeInUse) }
+
+func TestAdaptiveRevisions(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	sg := &fakeStatsGetter{}
+	compactable := &fakeCompactable{testutil.NewRecorderStreamWithWaitTimout(10 * time.Millisecond)}
+	window := 10 * time.Minute
+	ac := newAdaptive(zap.NewExample(), fc, window, AdaptiveThresholds{MaxRevisions: 100}, sg, compactable)
+	checkInterval := ac.getCheckInterval()
+
+	ac.Run()
+	defer ac.Stop()
+
+	// 50 revisions per check
+	for i := 0; i < retryDivisor; i++ {
+		fc.BlockUntil(1)
+		atomic.AddInt64(&sg.rev, 50)
+		fc.Advance(checkInterval)
+	}
+	// the first sample, at revision 0, is out of the window but there is
+	// nothing to compact at it
+	if _, err := compactable.Wait(1); err == nil {
+		t.Fatal("unexpected compaction within the window")
+	}
+
+	fc.BlockUntil(1)
+	atomic.AddInt64(&sg.rev, 50)
+	fc.Advance(checkInterval)
+	a, err := compactable.Wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 550 revisions, but revision 50 is the newest out of the window
+	wreq := &pb.CompactionRequest{Revision: 50}
+	if !reflect.DeepEqual(a[0].Params[0], wreq) {
+		t.Errorf("compact request = %v, want %v", a[0].Params[0], wreq)
+	}
+	fc.BlockUntil(1)
+	atomic.StoreInt64(&sg.compactRev, 50)
+
+	// a revision threshold within the window compacts down to it
+	ac.th.MaxRevisions = 990
+	atomic.AddInt64(&sg.rev, 500)
+	fc.Advance(checkInterval)
+	a, err = compactable.Wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1000 revisions since the compaction, revision 100 is out of the window
+	wreq = &pb.CompactionRequest{Revision: 60}
+	if !reflect.DeepEqual(a[0].Params[0], wreq) {
+		t.Errorf("compact request = %v, want %v", a[0].Params[0], wreq)
+	}
+}
+
+func TestAdaptiveSizeInUse(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	sg := &fakeStatsGetter{}
+	compactable := &fakeCompactable{testutil.NewRecorderStreamWithWaitTimout(10 * time.Millisecond)}
+	window := 10 * time.Minute
+	ac := newAdaptive(zap.NewExample(), fc, window, AdaptiveThresholds{MaxSizeInUseBytes: 1024}, sg, compactable)
+	checkInterval := ac.getCheckInterval()
+
+	ac.Run()
+	defer ac.Stop()
+
+	for i := 0; i <= retryDivisor; i++ {
+		fc.BlockUntil(1)
+		atomic.AddInt64(&sg.rev, 10)
+		fc.Advance(checkInterval)
+	}
+	// below the size threshold
+	if _, err := compactable.Wait(1); err == nil {
+		t.Fatal("unexpected compaction below the threshold")
+	}
+
+	fc.BlockUntil(1)
+	atomic.StoreInt64(&sg.sizeInUse, 2048)
+	atomic.AddInt64(&sg.rev, 10)
+	fc.Advance(checkInterval)
+	a, err := compactable.Wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all revisions out of the window are compacted
+	wreq := &pb.CompactionRequest{Revision: 20}
+	if !reflect.DeepEqual(a[0].Params[0], wreq) {
+		t.Errorf("compact request = %v, want %v", a[0].Params[0], wreq)
+	}
+}
+
+func TestAdaptivePause(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	sg := &fakeStatsGetter{sizeInUse: 2048}
+	compactable := &fakeCompactable{testutil.NewRecorderStream()}
+	window := 10 * time.Minute
+	ac := newAdaptive(zap.NewExample(), fc, window, AdaptiveThresholds{MaxSizeInUseBytes: 1024}, sg, compactable)
+	checkInterval := ac.getCheckInterval()
+
+	ac.Run()
+	ac.Pause()
+
+	// ac will collect 2 windows of revisions but not compact since paused
+	for i := 0; i < 2*retryDivisor; i++ {
+		fc.BlockUntil(1)
+		atomic.AddInt64(&sg.rev, 10)
+		fc.Advance(checkInterval)
+	}
+
+	select {
+	case a := <-compactable.Chan():
+		t.Fatalf("unexpected action %v", a)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	ac.Resume()
+
+	fc.BlockUntil(1)
+	fc.Advance(checkInterval)
+	a, err := compactable.Wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// revision 110 was recorded a window ago
+	wreq := &pb.CompactionRequest{Revision: 110}
+	if !reflect.DeepEqual(a[0].Params[0], wreq) {
+		t.Errorf("compact request = %v, want %v", a[0].Params[0], wreq)
+	}
+	ac.Stop()
+}
+
+func TestAdaptivePauseBoundsSamples(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	sg := &fakeStatsGetter{}
+	compactable := &fakeCompactable{testutil.NewRecorderStream()}
+	ac := newAdaptive(zap.NewExample(), fc, 10*time.Minute, AdaptiveThresholds{MaxRevisions: 100}, sg, compactable)
+	checkInterval := ac.getCheckInterval()
+
+	ac.Run()
+	defer ac.Stop()
+	ac.Pause()
+
+	// the samples older than the window are dropped while paused
+	for i := 0; i < 10*retryDivisor; i++ {
+		fc.BlockUntil(1)
+		atomic.AddInt64(&sg.rev, 10)
+		fc.Advance(checkInterval)
+	}
+	fc.BlockUntil(1)
+	if n := len(ac.samples); n > retryDivisor+2 {
+		t.Errorf("expected at most %d samples, got %d", retryDivisor+2, n)
+	}
+}
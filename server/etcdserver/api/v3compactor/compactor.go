@@ -28,6 +28,7 @@ import (
 const (
 	ModePeriodic = "periodic"
 	ModeRevision = "revision"
+	ModeAdaptive = "adaptive"
 )
 
 // Compactor purges old log from the storage periodically.
@@ -67,7 +68,24 @@ func New(
 		return newPeriodic(lg, clockwork.NewRealClock(), retention, rg, c), nil
 	case ModeRevision:
 		return newRevision(lg, clockwork.NewRealClock(), int64(retention), rg, c), nil
+	case ModeAdaptive:
+		return nil, fmt.Errorf("compaction mode %s needs thresholds, use NewAdaptive", mode)
 	default:
 		return nil, fmt.Errorf("unsupported compaction mode %s", mode)
 	}
 }
+
+// NewAdaptive returns a new Compactor compacting when a threshold is crossed,
+// while keeping at least the log of the given window.
+func NewAdaptive(
+	lg *zap.Logger,
+	window time.Duration,
+	th AdaptiveThresholds,
+	sg AdaptiveStatsGetter,
+	c Compactable,
+) Compactor {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return newAdaptive(lg, clockwork.NewRealClock(), window, th, sg, c)
+}
@@ -16,12 +16,17 @@ package v3lock
 
 import (
 	"context"
+	"errors"
 
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3lock/v3lockpb"
 )
 
+// ErrSharedSemaphore is returned when a lock request asks for shared
+// ownership of a lock with more than one holder.
+var ErrSharedSemaphore = errors.New(`"shared" cannot be combined with "max_holders"`)
+
 type lockServer struct {
 	c *clientv3.Client
 }
@@ -31,6 +36,9 @@ func NewLockServer(c *clientv3.Client) v3lockpb.LockServer {
 }
 
 func (ls *lockServer) Lock(ctx context.Context, req *v3lockpb.LockRequest) (*v3lockpb.LockResponse, error) {
+	if req.Shared && req.MaxHolders > 1 {
+		return nil, ErrSharedSemaphore
+	}
 	s, err := concurrency.NewSession(
 		ls.c,
 		concurrency.WithLease(clientv3.LeaseID(req.Lease)),
@@ -40,7 +48,22 @@ func (ls *lockServer) Lock(ctx context.Context, req *v3lockpb.LockRequest) (*v3l
 		return nil, err
 	}
 	s.Orphan()
-	m := concurrency.NewMutex(s, string(req.Name))
+	name := string(req.Name)
+	switch {
+	case req.Shared:
+		rw := concurrency.NewRWMutex(s, name)
+		if err = rw.RLock(ctx); err != nil {
+			return nil, err
+		}
+		return &v3lockpb.LockResponse{Header: rw.RHeader(), Key: []byte(rw.RKey())}, nil
+	case req.MaxHolders > 1:
+		sm := concurrency.NewSemaphore(s, name, int(req.MaxHolders))
+		if err = sm.Acquire(ctx); err != nil {
+			return nil, err
+		}
+		return &v3lockpb.LockResponse{Header: sm.Header(), Key: []byte(sm.Key())}, nil
+	}
+	m := concurrency.NewMutex(s, name)
 	if err = m.Lock(ctx); err != nil {
 		return nil, err
 	}
@@ -38,7 +38,16 @@ type LockRequest struct {
 	// the lock is automatically released. Calls to Lock with the same lease will
 	// be treated as a single acquisition; locking twice with the same lease is a
 	// no-op.
-	Lease                int64    `protobuf:"varint,2,opt,name=lease,proto3" json:"lease,omitempty"`
+	Lease int64 `protobuf:"varint,2,opt,name=lease,proto3" json:"lease,omitempty"`
+	// max_holders is the number of callers that may hold the lock at the same
+	// time. If it is zero or one, the lock is exclusive. Otherwise the lock
+	// behaves as a counting semaphore and callers are granted ownership in the
+	// order in which they requested it.
+	MaxHolders int64 `protobuf:"varint,3,opt,name=max_holders,json=maxHolders,proto3" json:"max_holders,omitempty"`
+	// shared requests the lock for reading. Any number of shared callers may
+	// hold the lock at once, but they exclude, and are excluded by, exclusive
+	// callers. It cannot be combined with max_holders.
+	Shared               bool     `protobuf:"varint,4,opt,name=shared,proto3" json:"shared,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -91,6 +100,20 @@ func (m *LockRequest) GetLease() int64 {
 	return 0
 }
 
+func (m *LockRequest) GetMaxHolders() int64 {
+	if m != nil {
+		return m.MaxHolders
+	}
+	return 0
+}
+
+func (m *LockRequest) GetShared() bool {
+	if m != nil {
+		return m.Shared
+	}
+	return false
+}
+
 type LockResponse struct {
 	Header *etcdserverpb.ResponseHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
 	// key is a key that will exist on etcd for the duration that the Lock caller
@@ -254,28 +277,30 @@ func init() {
 func init() { proto.RegisterFile("v3lock.proto", fileDescriptor_52389b3e2f253201) }
 
 var fileDescriptor_52389b3e2f253201 = []byte{
-	// 330 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x29, 0x33, 0xce, 0xc9,
-	0x4f, 0xce, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x80, 0xf0, 0x0a, 0x92, 0xa4, 0x44,
-	0xd2, 0xf3, 0xd3, 0xf3, 0xc1, 0x82, 0xfa, 0x20, 0x16, 0x44, 0x5e, 0x4a, 0x3e, 0xb5, 0x24, 0x39,
-	0x45, 0x3f, 0xb1, 0x20, 0x53, 0x1f, 0xc4, 0x28, 0x4e, 0x2d, 0x2a, 0x4b, 0x2d, 0x2a, 0x48, 0xd2,
-	0x2f, 0x2a, 0x48, 0x86, 0x2a, 0x90, 0x49, 0xcf, 0xcf, 0x4f, 0xcf, 0x49, 0x05, 0x2b, 0x49, 0xcc,
-	0xcb, 0xcb, 0x2f, 0x49, 0x2c, 0xc9, 0xcc, 0xcf, 0x2b, 0x86, 0xc8, 0x2a, 0x99, 0x73, 0x71, 0xfb,
-	0xe4, 0x27, 0x67, 0x07, 0xa5, 0x16, 0x96, 0xa6, 0x16, 0x97, 0x08, 0x09, 0x71, 0xb1, 0xe4, 0x25,
-	0xe6, 0xa6, 0x4a, 0x30, 0x2a, 0x30, 0x6a, 0xf0, 0x04, 0x81, 0xd9, 0x42, 0x22, 0x5c, 0xac, 0x39,
-	0xa9, 0x89, 0xc5, 0xa9, 0x12, 0x4c, 0x0a, 0x8c, 0x1a, 0xcc, 0x41, 0x10, 0x8e, 0x52, 0x18, 0x17,
-	0x0f, 0x44, 0x63, 0x71, 0x41, 0x7e, 0x5e, 0x71, 0xaa, 0x90, 0x09, 0x17, 0x5b, 0x46, 0x6a, 0x62,
-	0x4a, 0x6a, 0x11, 0x58, 0x2f, 0xb7, 0x91, 0x8c, 0x1e, 0xb2, 0x7b, 0xf4, 0x60, 0xea, 0x3c, 0xc0,
-	0x6a, 0x82, 0xa0, 0x6a, 0x85, 0x04, 0xb8, 0x98, 0xb3, 0x53, 0x2b, 0xc1, 0x26, 0xf3, 0x04, 0x81,
-	0x98, 0x4a, 0x8a, 0x5c, 0xbc, 0xa1, 0x79, 0x39, 0x48, 0x4e, 0x82, 0x2a, 0x61, 0x44, 0x28, 0x71,
-	0xe3, 0xe2, 0x83, 0x29, 0xa1, 0xc4, 0x72, 0xa3, 0x0d, 0x8c, 0x5c, 0x2c, 0x20, 0x3f, 0x08, 0xf9,
-	0x43, 0x69, 0x51, 0x3d, 0x58, 0x60, 0xeb, 0x21, 0x05, 0x8a, 0x94, 0x18, 0xba, 0x30, 0xc4, 0x34,
-	0x25, 0x89, 0xa6, 0xcb, 0x4f, 0x26, 0x33, 0x09, 0x29, 0xf1, 0xea, 0x97, 0x19, 0xeb, 0x83, 0x14,
-	0x80, 0x09, 0x2b, 0x46, 0x2d, 0xa1, 0x70, 0x2e, 0x36, 0x88, 0x0b, 0x85, 0xc4, 0x11, 0x7a, 0x51,
-	0xbc, 0x25, 0x25, 0x81, 0x29, 0x01, 0x35, 0x56, 0x0a, 0x6c, 0xac, 0x88, 0x12, 0x3f, 0xdc, 0xd8,
-	0xd2, 0x3c, 0xa8, 0xc1, 0x4e, 0x02, 0x27, 0x1e, 0xc9, 0x31, 0x5e, 0x78, 0x24, 0xc7, 0xf8, 0xe0,
-	0x91, 0x1c, 0xe3, 0x8c, 0xc7, 0x72, 0x0c, 0x49, 0x6c, 0xe0, 0x78, 0x34, 0x06, 0x04, 0x00, 0x00,
-	0xff, 0xff, 0x4a, 0x4d, 0xca, 0xbb, 0x36, 0x02, 0x00, 0x00,
+	// 364 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x92, 0xc1, 0x4e, 0xc2, 0x40,
+	0x10, 0x86, 0x5d, 0xc0, 0x86, 0x0c, 0x45, 0xc9, 0x06, 0xb1, 0x69, 0x48, 0xc1, 0x9e, 0x88, 0x87,
+	0x36, 0x01, 0x4f, 0x1e, 0x3d, 0x18, 0x0e, 0x26, 0x26, 0x4d, 0xd4, 0xa3, 0x59, 0xda, 0x49, 0x21,
+	0x94, 0x6e, 0xed, 0x16, 0x82, 0x57, 0x5f, 0xc1, 0x8b, 0x8f, 0xe1, 0x63, 0x78, 0x34, 0xf1, 0x05,
+	0x0c, 0xfa, 0x20, 0xa6, 0xbb, 0x45, 0x50, 0x8f, 0x5e, 0xda, 0x99, 0xf9, 0xbf, 0xfe, 0xb3, 0x7f,
+	0x5b, 0xd0, 0x17, 0x83, 0x88, 0xfb, 0x53, 0x27, 0x49, 0x79, 0xc6, 0x69, 0x55, 0x75, 0xc9, 0xc8,
+	0x6c, 0x86, 0x3c, 0xe4, 0x72, 0xe8, 0xe6, 0x95, 0xd2, 0xcd, 0x0e, 0x66, 0x7e, 0xe0, 0xb2, 0x64,
+	0xe2, 0xe6, 0x85, 0xc0, 0x74, 0x81, 0x69, 0x32, 0x72, 0xd3, 0xc4, 0x2f, 0x80, 0x76, 0xc8, 0x79,
+	0x18, 0xa1, 0x44, 0x58, 0x1c, 0xf3, 0x8c, 0x65, 0x13, 0x1e, 0x0b, 0xa5, 0xda, 0x09, 0xd4, 0x2e,
+	0xb8, 0x3f, 0xf5, 0xf0, 0x6e, 0x8e, 0x22, 0xa3, 0x14, 0x2a, 0x31, 0x9b, 0xa1, 0x41, 0xba, 0xa4,
+	0xa7, 0x7b, 0xb2, 0xa6, 0x4d, 0xd8, 0x8d, 0x90, 0x09, 0x34, 0x4a, 0x5d, 0xd2, 0x2b, 0x7b, 0xaa,
+	0xa1, 0x1d, 0xa8, 0xcd, 0xd8, 0xf2, 0x76, 0xcc, 0xa3, 0x00, 0x53, 0x61, 0x94, 0xa5, 0x06, 0x33,
+	0xb6, 0x1c, 0xaa, 0x09, 0x6d, 0x81, 0x26, 0xc6, 0x2c, 0xc5, 0xc0, 0xa8, 0x74, 0x49, 0xaf, 0xea,
+	0x15, 0x9d, 0x7d, 0x0d, 0xba, 0xda, 0x28, 0x12, 0x1e, 0x0b, 0xa4, 0x27, 0xa0, 0x8d, 0x91, 0x05,
+	0x98, 0xca, 0xa5, 0xb5, 0x7e, 0xdb, 0xd9, 0x0e, 0xe2, 0xac, 0xb9, 0xa1, 0x64, 0xbc, 0x82, 0xa5,
+	0x0d, 0x28, 0x4f, 0xf1, 0x5e, 0x1e, 0x49, 0xf7, 0xf2, 0xd2, 0x3e, 0x82, 0xfa, 0x55, 0x1c, 0x6d,
+	0x65, 0x29, 0x10, 0xb2, 0x41, 0xce, 0x61, 0x6f, 0x8d, 0xfc, 0x67, 0x79, 0xff, 0x99, 0x40, 0x25,
+	0xcf, 0x40, 0x2f, 0x8b, 0xfb, 0x81, 0xb3, 0xfe, 0x4a, 0xce, 0xd6, 0xdb, 0x34, 0x5b, 0xbf, 0xc7,
+	0xca, 0xcd, 0x36, 0x1e, 0xde, 0x3e, 0x1f, 0x4b, 0xd4, 0xae, 0xbb, 0x8b, 0x81, 0x9b, 0x03, 0xf2,
+	0x72, 0x4a, 0x8e, 0xe9, 0x0d, 0x68, 0xea, 0x84, 0xf4, 0x70, 0xf3, 0xec, 0x8f, 0x58, 0xa6, 0xf1,
+	0x57, 0x28, 0x6c, 0x4d, 0x69, 0xdb, 0xb4, 0xf7, 0xbf, 0x6d, 0xe7, 0x71, 0x61, 0x7c, 0xd6, 0x78,
+	0x59, 0x59, 0xe4, 0x75, 0x65, 0x91, 0xf7, 0x95, 0x45, 0x9e, 0x3e, 0xac, 0x9d, 0x91, 0x26, 0x7f,
+	0x80, 0xc1, 0xd7, 0x00, 0x36, 0x6b, 0x58, 0x98, 0x6f, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -436,6 +461,21 @@ func (m *LockRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Shared {
+		i--
+		if m.Shared {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MaxHolders != 0 {
+		i = encodeVarintV3Lock(dAtA, i, uint64(m.MaxHolders))
+		i--
+		dAtA[i] = 0x18
+	}
 	if m.Lease != 0 {
 		i = encodeVarintV3Lock(dAtA, i, uint64(m.Lease))
 		i--
@@ -594,6 +634,12 @@ func (m *LockRequest) Size() (n int) {
 	if m.Lease != 0 {
 		n += 1 + sovV3Lock(uint64(m.Lease))
 	}
+	if m.MaxHolders != 0 {
+		n += 1 + sovV3Lock(uint64(m.MaxHolders))
+	}
+	if m.Shared {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -740,6 +786,45 @@ func (m *LockRequest) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxHolders", wireType)
+			}
+			m.MaxHolders = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowV3Lock
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxHolders |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Shared", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowV3Lock
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Shared = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipV3Lock(dAtA[iNdEx:])
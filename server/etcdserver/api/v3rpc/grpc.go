@@ -72,9 +72,9 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 
 	grpcServer := grpc.NewServer(append(opts, gopts...)...)
 
-	pb.RegisterKVServer(grpcServer, NewQuotaKVServer(s))
-	pb.RegisterWatchServer(grpcServer, NewWatchServer(s))
-	pb.RegisterLeaseServer(grpcServer, NewQuotaLeaseServer(s))
+	pb.RegisterKVServer(grpcServer, NewTenantKVServer(s))
+	pb.RegisterWatchServer(grpcServer, NewTenantWatchServer(s))
+	pb.RegisterLeaseServer(grpcServer, NewTenantLeaseServer(s))
 	pb.RegisterClusterServer(grpcServer, NewClusterServer(s))
 	pb.RegisterAuthServer(grpcServer, NewAuthServer(s))
 	pb.RegisterMaintenanceServer(grpcServer, NewMaintenanceServer(s))
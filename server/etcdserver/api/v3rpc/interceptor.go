@@ -36,6 +36,7 @@ import (
 const (
 	maxNoLeaderCnt = 3
 	snapshotMethod = "/etcdserverpb.Maintenance/Snapshot"
+	watchMethod    = "/etcdserverpb.Watch/Watch"
 )
 
 type streamsMap struct {
@@ -49,7 +50,7 @@ func newUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
 			return nil, rpctypes.ErrGRPCNotCapable
 		}
 
-		if s.IsMemberExist(s.ID()) && s.IsLearner() && !isRPCSupportedForLearner(req) {
+		if s.IsMemberExist(s.ID()) && s.IsLearner() && !isRPCSupportedForLearner(req, s.Cfg.ExperimentalLearnerServeReads) {
 			return nil, rpctypes.ErrGPRCNotSupportedForLearner
 		}
 
@@ -218,7 +219,7 @@ func newStreamInterceptor(s *etcdserver.EtcdServer) grpc.StreamServerInterceptor
 			return rpctypes.ErrGRPCNotCapable
 		}
 
-		if s.IsMemberExist(s.ID()) && s.IsLearner() && info.FullMethod != snapshotMethod { // learner does not support stream RPC except Snapshot
+		if s.IsMemberExist(s.ID()) && s.IsLearner() && !isStreamRPCSupportedForLearner(info.FullMethod, s.Cfg.ExperimentalLearnerServeReads) {
 			return rpctypes.ErrGPRCNotSupportedForLearner
 		}
 
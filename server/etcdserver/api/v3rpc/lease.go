@@ -30,10 +30,11 @@ type LeaseServer struct {
 	lg  *zap.Logger
 	hdr header
 	le  etcdserver.Lessor
+	ag  AuthGetter
 }
 
 func NewLeaseServer(s *etcdserver.EtcdServer) pb.LeaseServer {
-	srv := &LeaseServer{lg: s.Cfg.Logger, le: s, hdr: newHeader(s)}
+	srv := &LeaseServer{lg: s.Cfg.Logger, le: s, ag: s, hdr: newHeader(s)}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
@@ -59,6 +60,24 @@ func (ls *LeaseServer) LeaseRevoke(ctx context.Context, rr *pb.LeaseRevokeReques
 	return resp, nil
 }
 
+func (ls *LeaseServer) LeaseUpdate(ctx context.Context, ur *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error) {
+	resp, err := ls.le.LeaseUpdate(ctx, ur)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ls *LeaseServer) LeaseMove(ctx context.Context, mr *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	resp, err := ls.le.LeaseMove(ctx, mr)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 func (ls *LeaseServer) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	resp, err := ls.le.LeaseTimeToLive(ctx, rr)
 	if err != nil && err != lease.ErrLeaseNotFound {
@@ -155,3 +174,149 @@ func (ls *LeaseServer) leaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) erro
 		}
 	}
 }
+
+func (ls *LeaseServer) LeaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) (err error) {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ls.leaseKeepAliveBatch(stream)
+	}()
+	select {
+	case err = <-errc:
+	case <-stream.Context().Done():
+		// the only server-side cancellation is noleader for now.
+		err = stream.Context().Err()
+		if err == context.Canceled {
+			err = rpctypes.ErrGRPCNoLeader
+		}
+	}
+	return err
+}
+
+func (ls *LeaseServer) leaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if isClientCtxErr(stream.Context().Err(), err) {
+				ls.lg.Debug("failed to receive lease keepalive batch request from gRPC stream", zap.Error(err))
+			} else {
+				ls.lg.Warn("failed to receive lease keepalive batch request from gRPC stream", zap.Error(err))
+				streamFailures.WithLabelValues("receive", "lease-keepalive-batch").Inc()
+			}
+			return err
+		}
+
+		// Create header before we sent out the renew request; see leaseKeepAlive.
+		resp := &pb.LeaseKeepAliveBatchResponse{IDs: req.IDs, Header: &pb.ResponseHeader{}}
+		ls.hdr.fill(resp.Header)
+
+		ids := make([]lease.LeaseID, len(req.IDs))
+		for i, id := range req.IDs {
+			ids[i] = lease.LeaseID(id)
+		}
+		resp.TTLs, err = ls.le.LeaseRenewBatch(stream.Context(), ids)
+		if err != nil {
+			return togRPCError(err)
+		}
+
+		err = stream.Send(resp)
+		if err != nil {
+			if isClientCtxErr(stream.Context().Err(), err) {
+				ls.lg.Debug("failed to send lease keepalive batch response to gRPC stream", zap.Error(err))
+			} else {
+				ls.lg.Warn("failed to send lease keepalive batch response to gRPC stream", zap.Error(err))
+				streamFailures.WithLabelValues("send", "lease-keepalive-batch").Inc()
+			}
+			return err
+		}
+	}
+}
+
+// maximum number of lease events sent in a single LeaseWatchResponse
+const maxLeaseWatchEvents = 1000
+
+func (ls *LeaseServer) LeaseWatch(wr *pb.LeaseWatchRequest, stream pb.Lease_LeaseWatchServer) error {
+	// watching all the leases tells the IDs of the leases of every user, like
+	// listing them, so it needs the root role.
+	if len(wr.IDs) == 0 {
+		authInfo, err := ls.ag.AuthInfoFromCtx(stream.Context())
+		if err != nil {
+			return togRPCError(err)
+		}
+		if err = ls.ag.AuthStore().IsAdminPermitted(authInfo); err != nil {
+			return togRPCError(err)
+		}
+	}
+	return ls.leaseWatch(wr, stream, nil)
+}
+
+// leaseWatch sends the events of the leases watched by the request, and accepted by
+// accept if not nil.
+func (ls *LeaseServer) leaseWatch(wr *pb.LeaseWatchRequest, stream pb.Lease_LeaseWatchServer, accept func(lease.Event) bool) error {
+	ids := make(map[lease.LeaseID]struct{}, len(wr.IDs))
+	for _, id := range wr.IDs {
+		ids[lease.LeaseID(id)] = struct{}{}
+	}
+	evc, stop := ls.le.LeaseEvents(func(ev lease.Event) bool {
+		if _, ok := ids[ev.ID]; !ok && len(ids) > 0 {
+			return false
+		}
+		if accept != nil && !accept(ev) {
+			return false
+		}
+		if ev.Type == lease.EventRenewed {
+			return wr.RenewThreshold > 0 && ev.RemainingTTL <= wr.RenewThreshold
+		}
+		return true
+	})
+	defer stop()
+
+	// the first response confirms the watch is established
+	resp := &pb.LeaseWatchResponse{Header: &pb.ResponseHeader{}}
+	for {
+		ls.hdr.fill(resp.Header)
+		if err := stream.Send(resp); err != nil {
+			if isClientCtxErr(stream.Context().Err(), err) {
+				ls.lg.Debug("failed to send lease watch response to gRPC stream", zap.Error(err))
+			} else {
+				ls.lg.Warn("failed to send lease watch response to gRPC stream", zap.Error(err))
+				streamFailures.WithLabelValues("send", "lease-watch").Inc()
+			}
+			return err
+		}
+
+		select {
+		case ev, ok := <-evc:
+			if !ok {
+				return rpctypes.ErrGRPCLeaseWatchCanceled
+			}
+			resp = &pb.LeaseWatchResponse{Header: &pb.ResponseHeader{}, Events: []*pb.LeaseEvent{toPBLeaseEvent(ev)}}
+			// batch the events already pending
+			for n := len(evc); n > 0 && len(resp.Events) < maxLeaseWatchEvents; n-- {
+				if ev, ok = <-evc; !ok {
+					break
+				}
+				resp.Events = append(resp.Events, toPBLeaseEvent(ev))
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func toPBLeaseEvent(ev lease.Event) *pb.LeaseEvent {
+	pev := &pb.LeaseEvent{ID: int64(ev.ID), TTL: ev.TTL, Remaining_TTL: ev.RemainingTTL}
+	switch ev.Type {
+	case lease.EventGranted:
+		pev.Type = pb.LeaseEvent_GRANTED
+	case lease.EventRenewed:
+		pev.Type = pb.LeaseEvent_RENEWED
+	case lease.EventRevoked:
+		pev.Type = pb.LeaseEvent_REVOKED
+	case lease.EventExpired:
+		pev.Type = pb.LeaseEvent_EXPIRED
+	}
+	return pev
+}
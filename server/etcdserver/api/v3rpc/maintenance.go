@@ -53,6 +53,27 @@ type Downgrader interface {
 	Downgrade(ctx context.Context, dr *pb.DowngradeRequest) (*pb.DowngradeResponse, error)
 }
 
+type RetentionManager interface {
+	RetentionPut(ctx context.Context, r *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error)
+	RetentionDelete(ctx context.Context, r *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error)
+	RetentionList(ctx context.Context, r *pb.RetentionListRequest) (*pb.RetentionListResponse, error)
+}
+
+type SchemaManager interface {
+	SchemaPut(ctx context.Context, r *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error)
+	SchemaGet(ctx context.Context, r *pb.SchemaGetRequest) (*pb.SchemaGetResponse, error)
+	SchemaDelete(ctx context.Context, r *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error)
+	SchemaList(ctx context.Context, r *pb.SchemaListRequest) (*pb.SchemaListResponse, error)
+}
+
+type TenantStatusGetter interface {
+	TenantStatus(ctx context.Context, r *pb.TenantStatusRequest) (*pb.TenantStatusResponse, error)
+}
+
+type ConfigReloader interface {
+	ReloadConfig(ctx context.Context, r *pb.ReloadConfigRequest) (*pb.ReloadConfigResponse, error)
+}
+
 type LeaderTransferrer interface {
 	MoveLeader(ctx context.Context, lead, target uint64) error
 }
@@ -76,10 +97,14 @@ type maintenanceServer struct {
 	hdr header
 	cs  ClusterStatusGetter
 	d   Downgrader
+	rm  RetentionManager
+	sm  SchemaManager
+	ts  TenantStatusGetter
+	cr  ConfigReloader
 }
 
 func NewMaintenanceServer(s *etcdserver.EtcdServer) pb.MaintenanceServer {
-	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, kg: s, bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s}
+	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, kg: s, bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s, rm: s, sm: s, ts: s, cr: s}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
@@ -265,6 +290,79 @@ func (ms *maintenanceServer) Downgrade(ctx context.Context, r *pb.DowngradeReque
 	return resp, nil
 }
 
+func (ms *maintenanceServer) RetentionPut(ctx context.Context, r *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error) {
+	resp, err := ms.rm.RetentionPut(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) RetentionDelete(ctx context.Context, r *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error) {
+	resp, err := ms.rm.RetentionDelete(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) RetentionList(ctx context.Context, r *pb.RetentionListRequest) (*pb.RetentionListResponse, error) {
+	resp, err := ms.rm.RetentionList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) SchemaPut(ctx context.Context, r *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error) {
+	resp, err := ms.sm.SchemaPut(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) SchemaGet(ctx context.Context, r *pb.SchemaGetRequest) (*pb.SchemaGetResponse, error) {
+	resp, err := ms.sm.SchemaGet(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) SchemaDelete(ctx context.Context, r *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error) {
+	resp, err := ms.sm.SchemaDelete(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) SchemaList(ctx context.Context, r *pb.SchemaListRequest) (*pb.SchemaListResponse, error) {
+	resp, err := ms.sm.SchemaList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) TenantStatus(ctx context.Context, r *pb.TenantStatusRequest) (*pb.TenantStatusResponse, error) {
+	resp, err := ms.ts.TenantStatus(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (ms *maintenanceServer) ReloadConfig(ctx context.Context, r *pb.ReloadConfigRequest) (*pb.ReloadConfigResponse, error) {
+	resp, err := ms.cr.ReloadConfig(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 type authMaintenanceServer struct {
 	*maintenanceServer
 	ag AuthGetter
@@ -273,10 +371,13 @@ type authMaintenanceServer struct {
 func (ams *authMaintenanceServer) isAuthenticated(ctx context.Context) error {
 	authInfo, err := ams.ag.AuthInfoFromCtx(ctx)
 	if err != nil {
-		return err
+		return togRPCError(err)
 	}
 
-	return ams.ag.AuthStore().IsAdminPermitted(authInfo)
+	if err = ams.ag.AuthStore().IsAdminPermitted(authInfo); err != nil {
+		return togRPCError(err)
+	}
+	return nil
 }
 
 func (ams *authMaintenanceServer) Defragment(ctx context.Context, sr *pb.DefragmentRequest) (*pb.DefragmentResponse, error) {
@@ -321,3 +422,38 @@ func (ams *authMaintenanceServer) MoveLeader(ctx context.Context, tr *pb.MoveLea
 func (ams *authMaintenanceServer) Downgrade(ctx context.Context, r *pb.DowngradeRequest) (*pb.DowngradeResponse, error) {
 	return ams.maintenanceServer.Downgrade(ctx, r)
 }
+
+func (ams *authMaintenanceServer) RetentionList(ctx context.Context, r *pb.RetentionListRequest) (*pb.RetentionListResponse, error) {
+	if err := ams.isAuthenticated(ctx); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.RetentionList(ctx, r)
+}
+
+func (ams *authMaintenanceServer) SchemaGet(ctx context.Context, r *pb.SchemaGetRequest) (*pb.SchemaGetResponse, error) {
+	if err := ams.isAuthenticated(ctx); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.SchemaGet(ctx, r)
+}
+
+func (ams *authMaintenanceServer) SchemaList(ctx context.Context, r *pb.SchemaListRequest) (*pb.SchemaListResponse, error) {
+	if err := ams.isAuthenticated(ctx); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.SchemaList(ctx, r)
+}
+
+func (ams *authMaintenanceServer) TenantStatus(ctx context.Context, r *pb.TenantStatusRequest) (*pb.TenantStatusResponse, error) {
+	if err := ams.isAuthenticated(ctx); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.TenantStatus(ctx, r)
+}
+
+func (ams *authMaintenanceServer) ReloadConfig(ctx context.Context, r *pb.ReloadConfigRequest) (*pb.ReloadConfigResponse, error) {
+	if err := ams.isAuthenticated(ctx); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.ReloadConfig(ctx, r)
+}
@@ -0,0 +1,572 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/lease"
+)
+
+// Users bound to a tenant only see the keys under the prefix of the tenant:
+// the keys and ranges of their requests are rewritten under the prefix, and
+// the prefix is stripped from the keys of the responses.
+
+type LeaseTenantGetter interface {
+	LeaseTenant(id lease.LeaseID) (string, bool)
+}
+
+// tenant gets the prefix of the tenant of the user of a request.
+type tenant struct {
+	ag AuthGetter
+}
+
+// prefix returns the prefix of the tenant of the user, nil if the user is not
+// bound to a tenant.
+func (t tenant) prefix(ctx context.Context) ([]byte, error) {
+	if !t.ag.AuthStore().IsAuthEnabled() {
+		return nil, nil
+	}
+	authInfo, err := t.ag.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	if authInfo == nil {
+		return nil, nil
+	}
+	return t.ag.AuthStore().UserPrefix(authInfo.Username), nil
+}
+
+type tenantKVServer struct {
+	pb.KVServer
+	t tenant
+}
+
+func NewTenantKVServer(s *etcdserver.EtcdServer) pb.KVServer {
+	return &tenantKVServer{NewQuotaKVServer(s), tenant{s}}
+}
+
+func (s *tenantKVServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	pfx, err := s.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pfx) == 0 {
+		return s.KVServer.Range(ctx, r)
+	}
+
+	r2, err := prefixRangeRequest(pfx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.KVServer.Range(ctx, r2)
+	if err != nil {
+		return nil, err
+	}
+	if err = stripRangeResponse(pfx, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *tenantKVServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	pfx, err := s.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pfx) == 0 {
+		return s.KVServer.Put(ctx, r)
+	}
+
+	r2, err := prefixPutRequest(pfx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.KVServer.Put(ctx, r2)
+	if err != nil {
+		return nil, err
+	}
+	if err = stripPutResponse(pfx, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *tenantKVServer) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
+	pfx, err := s.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pfx) == 0 {
+		return s.KVServer.DeleteRange(ctx, r)
+	}
+
+	r2, err := prefixDeleteRangeRequest(pfx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.KVServer.DeleteRange(ctx, r2)
+	if err != nil {
+		return nil, err
+	}
+	if err = stripDeleteRangeResponse(pfx, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *tenantKVServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, error) {
+	pfx, err := s.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pfx) == 0 {
+		return s.KVServer.Txn(ctx, r)
+	}
+
+	r2, err := prefixTxnRequest(pfx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.KVServer.Txn(ctx, r2)
+	if err != nil {
+		return nil, err
+	}
+	if err = stripTxnResponse(pfx, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *tenantKVServer) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
+	pfx, err := s.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pfx) > 0 {
+		// compaction drops the history of the keys of all the tenants
+		return nil, rpctypes.ErrGRPCPermissionDenied
+	}
+	return s.KVServer.Compact(ctx, r)
+}
+
+type tenantWatchServer struct {
+	pb.WatchServer
+	t tenant
+}
+
+func NewTenantWatchServer(s *etcdserver.EtcdServer) pb.WatchServer {
+	return &tenantWatchServer{NewWatchServer(s), tenant{s}}
+}
+
+func (ws *tenantWatchServer) Watch(stream pb.Watch_WatchServer) error {
+	pfx, err := ws.t.prefix(stream.Context())
+	if err != nil {
+		return err
+	}
+	if len(pfx) == 0 {
+		return ws.WatchServer.Watch(stream)
+	}
+	return ws.WatchServer.Watch(&tenantWatchStream{Watch_WatchServer: stream, prefix: pfx})
+}
+
+// tenantWatchStream rewrites the ranges of the watches created on the stream
+// under the prefix of the tenant, and strips it from the keys of the events.
+type tenantWatchStream struct {
+	pb.Watch_WatchServer
+	prefix []byte
+}
+
+func (s *tenantWatchStream) Recv() (*pb.WatchRequest, error) {
+	req, err := s.Watch_WatchServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if cr := req.GetCreateRequest(); cr != nil {
+		cr.Key, cr.RangeEnd = prefixInterval(s.prefix, cr.Key, cr.RangeEnd)
+	}
+	return req, nil
+}
+
+func (s *tenantWatchStream) Send(resp *pb.WatchResponse) error {
+	if len(resp.Events) == 0 {
+		return s.Watch_WatchServer.Send(resp)
+	}
+	// the events are shared by the watchers of the keys
+	resp2 := *resp
+	resp2.Events = make([]*mvccpb.Event, len(resp.Events))
+	for i, ev := range resp.Events {
+		ev2 := *ev
+		var err error
+		if ev2.Kv, err = stripKV(s.prefix, ev.Kv); err != nil {
+			return err
+		}
+		if ev2.PrevKv, err = stripKV(s.prefix, ev.PrevKv); err != nil {
+			return err
+		}
+		resp2.Events[i] = &ev2
+	}
+	return s.Watch_WatchServer.Send(&resp2)
+}
+
+// tenantLeaseServer hides the leases of the other tenants from the users bound to
+// a tenant. The leases revoked, updated or moved by the users are checked when the
+// requests are applied.
+type tenantLeaseServer struct {
+	pb.LeaseServer
+	t   tenant
+	lt  LeaseTenantGetter
+	ls  *LeaseServer
+	hdr header
+}
+
+func NewTenantLeaseServer(s *etcdserver.EtcdServer) pb.LeaseServer {
+	return &tenantLeaseServer{
+		LeaseServer: NewQuotaLeaseServer(s),
+		t:           tenant{s},
+		lt:          s,
+		ls:          NewLeaseServer(s).(*LeaseServer),
+		hdr:         newHeader(s),
+	}
+}
+
+// owns returns whether the lease exists and is owned by the tenant.
+func (ls *tenantLeaseServer) owns(pfx []byte, id int64) bool {
+	tenant, ok := ls.lt.LeaseTenant(lease.LeaseID(id))
+	return ok && tenant == string(pfx)
+}
+
+func (ls *tenantLeaseServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
+	pfx, err := ls.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pfx) == 0 {
+		return ls.LeaseServer.LeaseTimeToLive(ctx, r)
+	}
+	if !ls.owns(pfx, r.ID) {
+		// the leases of the other tenants do not exist for the tenant
+		resp := &pb.LeaseTimeToLiveResponse{Header: &pb.ResponseHeader{}, ID: r.ID, TTL: -1}
+		ls.hdr.fill(resp.Header)
+		return resp, nil
+	}
+	resp, err := ls.LeaseServer.LeaseTimeToLive(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	// only the keys of the tenant are listed
+	keys := make([][]byte, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		if bytes.HasPrefix(key, pfx) {
+			keys = append(keys, key[len(pfx):])
+		}
+	}
+	resp.Keys = keys
+	return resp, nil
+}
+
+func (ls *tenantLeaseServer) LeaseLeases(ctx context.Context, r *pb.LeaseLeasesRequest) (*pb.LeaseLeasesResponse, error) {
+	pfx, err := ls.t.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ls.LeaseServer.LeaseLeases(ctx, r)
+	if err != nil || len(pfx) == 0 {
+		return resp, err
+	}
+	leases := make([]*pb.LeaseStatus, 0, len(resp.Leases))
+	for _, l := range resp.Leases {
+		if ls.owns(pfx, l.ID) {
+			leases = append(leases, l)
+		}
+	}
+	resp.Leases = leases
+	return resp, nil
+}
+
+func (ls *tenantLeaseServer) LeaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) error {
+	pfx, err := ls.t.prefix(stream.Context())
+	if err != nil {
+		return err
+	}
+	if len(pfx) == 0 {
+		return ls.LeaseServer.LeaseKeepAlive(stream)
+	}
+	return ls.LeaseServer.LeaseKeepAlive(&tenantKeepAliveStream{Lease_LeaseKeepAliveServer: stream, ls: ls, prefix: pfx})
+}
+
+func (ls *tenantLeaseServer) LeaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) error {
+	pfx, err := ls.t.prefix(stream.Context())
+	if err != nil {
+		return err
+	}
+	if len(pfx) == 0 {
+		return ls.LeaseServer.LeaseKeepAliveBatch(stream)
+	}
+	return ls.LeaseServer.LeaseKeepAliveBatch(&tenantKeepAliveBatchStream{Lease_LeaseKeepAliveBatchServer: stream, ls: ls, prefix: pfx})
+}
+
+func (ls *tenantLeaseServer) LeaseWatch(r *pb.LeaseWatchRequest, stream pb.Lease_LeaseWatchServer) error {
+	pfx, err := ls.t.prefix(stream.Context())
+	if err != nil {
+		return err
+	}
+	if len(pfx) == 0 {
+		return ls.LeaseServer.LeaseWatch(r, stream)
+	}
+	return ls.ls.leaseWatch(r, stream, func(ev lease.Event) bool { return ev.Tenant == string(pfx) })
+}
+
+// tenantKeepAliveStream answers the keep alives of the leases of the other tenants
+// as if the leases did not exist, without renewing them.
+type tenantKeepAliveStream struct {
+	pb.Lease_LeaseKeepAliveServer
+	ls     *tenantLeaseServer
+	prefix []byte
+}
+
+func (s *tenantKeepAliveStream) Recv() (*pb.LeaseKeepAliveRequest, error) {
+	for {
+		req, err := s.Lease_LeaseKeepAliveServer.Recv()
+		if err != nil || s.ls.owns(s.prefix, req.ID) {
+			return req, err
+		}
+		resp := &pb.LeaseKeepAliveResponse{ID: req.ID, Header: &pb.ResponseHeader{}}
+		s.ls.hdr.fill(resp.Header)
+		if err = s.Lease_LeaseKeepAliveServer.Send(resp); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tenantKeepAliveBatchStream renews the leases of the tenant only; the TTLs of the
+// leases of the other tenants are 0 as if the leases did not exist.
+type tenantKeepAliveBatchStream struct {
+	pb.Lease_LeaseKeepAliveBatchServer
+	ls     *tenantLeaseServer
+	prefix []byte
+	// ids are the IDs of the last request received, answered by the next response
+	ids []int64
+}
+
+func (s *tenantKeepAliveBatchStream) Recv() (*pb.LeaseKeepAliveBatchRequest, error) {
+	req, err := s.Lease_LeaseKeepAliveBatchServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	s.ids = req.IDs
+	req2 := &pb.LeaseKeepAliveBatchRequest{IDs: make([]int64, len(req.IDs))}
+	for i, id := range req.IDs {
+		if s.ls.owns(s.prefix, id) {
+			req2.IDs[i] = id
+		}
+		// else renews lease.NoLease, which does not exist
+	}
+	return req2, nil
+}
+
+func (s *tenantKeepAliveBatchStream) Send(resp *pb.LeaseKeepAliveBatchResponse) error {
+	resp.IDs = s.ids
+	return s.Lease_LeaseKeepAliveBatchServer.Send(resp)
+}
+
+func prefixKey(pfx, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	pfxKey := make([]byte, len(pfx)+len(key))
+	copy(pfxKey[copy(pfxKey, pfx):], key)
+	return pfxKey, nil
+}
+
+// prefixInterval rewrites the interval [key, end) under the prefix, confining
+// the ranges open ended at the edge of the keyspace to the keys of the prefix.
+func prefixInterval(pfx, key, end []byte) (pfxKey []byte, pfxEnd []byte) {
+	pfxKey = make([]byte, len(pfx)+len(key))
+	copy(pfxKey[copy(pfxKey, pfx):], key)
+
+	if len(end) == 1 && end[0] == 0 {
+		// the edge of the keyspace
+		pfxEnd = keyutil.PrefixRangeEnd(pfx)
+	} else if len(end) >= 1 {
+		pfxEnd = make([]byte, len(pfx)+len(end))
+		copy(pfxEnd[copy(pfxEnd, pfx):], end)
+	}
+	return pfxKey, pfxEnd
+}
+
+func prefixRangeRequest(pfx []byte, r *pb.RangeRequest) (*pb.RangeRequest, error) {
+	if len(r.Key) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	r2 := *r
+	r2.Key, r2.RangeEnd = prefixInterval(pfx, r.Key, r.RangeEnd)
+	return &r2, nil
+}
+
+func prefixPutRequest(pfx []byte, r *pb.PutRequest) (*pb.PutRequest, error) {
+	key, err := prefixKey(pfx, r.Key)
+	if err != nil {
+		return nil, err
+	}
+	r2 := *r
+	r2.Key = key
+	return &r2, nil
+}
+
+func prefixDeleteRangeRequest(pfx []byte, r *pb.DeleteRangeRequest) (*pb.DeleteRangeRequest, error) {
+	if len(r.Key) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	r2 := *r
+	r2.Key, r2.RangeEnd = prefixInterval(pfx, r.Key, r.RangeEnd)
+	return &r2, nil
+}
+
+func prefixTxnRequest(pfx []byte, r *pb.TxnRequest) (*pb.TxnRequest, error) {
+	r2 := *r
+	r2.Compare = make([]*pb.Compare, len(r.Compare))
+	for i, c := range r.Compare {
+		if len(c.Key) == 0 {
+			return nil, rpctypes.ErrGRPCEmptyKey
+		}
+		c2 := *c
+		c2.Key, c2.RangeEnd = prefixInterval(pfx, c.Key, c.RangeEnd)
+		r2.Compare[i] = &c2
+	}
+	var err error
+	if r2.Success, err = prefixRequestOps(pfx, r.Success); err != nil {
+		return nil, err
+	}
+	if r2.Failure, err = prefixRequestOps(pfx, r.Failure); err != nil {
+		return nil, err
+	}
+	return &r2, nil
+}
+
+func prefixRequestOps(pfx []byte, ops []*pb.RequestOp) ([]*pb.RequestOp, error) {
+	ops2 := make([]*pb.RequestOp, len(ops))
+	for i, op := range ops {
+		var err error
+		ops2[i] = op
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestRange:
+			if tv.RequestRange == nil {
+				continue
+			}
+			var r *pb.RangeRequest
+			if r, err = prefixRangeRequest(pfx, tv.RequestRange); err == nil {
+				ops2[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestRange{RequestRange: r}}
+			}
+		case *pb.RequestOp_RequestPut:
+			if tv.RequestPut == nil {
+				continue
+			}
+			var r *pb.PutRequest
+			if r, err = prefixPutRequest(pfx, tv.RequestPut); err == nil {
+				ops2[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: r}}
+			}
+		case *pb.RequestOp_RequestDeleteRange:
+			if tv.RequestDeleteRange == nil {
+				continue
+			}
+			var r *pb.DeleteRangeRequest
+			if r, err = prefixDeleteRangeRequest(pfx, tv.RequestDeleteRange); err == nil {
+				ops2[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: r}}
+			}
+		case *pb.RequestOp_RequestTxn:
+			if tv.RequestTxn == nil {
+				continue
+			}
+			var r *pb.TxnRequest
+			if r, err = prefixTxnRequest(pfx, tv.RequestTxn); err == nil {
+				ops2[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestTxn{RequestTxn: r}}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ops2, nil
+}
+
+// stripKV strips the prefix from the key, refusing the keys outside of the tenant.
+func stripKV(pfx []byte, kv *mvccpb.KeyValue) (*mvccpb.KeyValue, error) {
+	if kv == nil {
+		return nil, nil
+	}
+	if !bytes.HasPrefix(kv.Key, pfx) {
+		return nil, rpctypes.ErrGRPCPermissionDenied
+	}
+	kv2 := *kv
+	kv2.Key = kv.Key[len(pfx):]
+	return &kv2, nil
+}
+
+func stripKVs(pfx []byte, kvs []*mvccpb.KeyValue) (err error) {
+	for i, kv := range kvs {
+		if kvs[i], err = stripKV(pfx, kv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stripRangeResponse(pfx []byte, resp *pb.RangeResponse) error {
+	return stripKVs(pfx, resp.Kvs)
+}
+
+func stripPutResponse(pfx []byte, resp *pb.PutResponse) (err error) {
+	resp.PrevKv, err = stripKV(pfx, resp.PrevKv)
+	return err
+}
+
+func stripDeleteRangeResponse(pfx []byte, resp *pb.DeleteRangeResponse) error {
+	return stripKVs(pfx, resp.PrevKvs)
+}
+
+func stripTxnResponse(pfx []byte, resp *pb.TxnResponse) error {
+	for _, op := range resp.Responses {
+		var err error
+		switch tv := op.Response.(type) {
+		case *pb.ResponseOp_ResponseRange:
+			if tv.ResponseRange != nil {
+				err = stripRangeResponse(pfx, tv.ResponseRange)
+			}
+		case *pb.ResponseOp_ResponsePut:
+			if tv.ResponsePut != nil {
+				err = stripPutResponse(pfx, tv.ResponsePut)
+			}
+		case *pb.ResponseOp_ResponseDeleteRange:
+			if tv.ResponseDeleteRange != nil {
+				err = stripDeleteRangeResponse(pfx, tv.ResponseDeleteRange)
+			}
+		case *pb.ResponseOp_ResponseTxn:
+			if tv.ResponseTxn != nil {
+				err = stripTxnResponse(pfx, tv.ResponseTxn)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
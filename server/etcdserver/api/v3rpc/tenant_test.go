@@ -0,0 +1,124 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+func TestPrefixInterval(t *testing.T) {
+	tests := []struct {
+		pfx, key, end    []byte
+		wantKey, wantEnd []byte
+	}{
+		// single key
+		{[]byte("/t/"), []byte("a"), nil, []byte("/t/a"), nil},
+		// range
+		{[]byte("/t/"), []byte("a"), []byte("b"), []byte("/t/a"), []byte("/t/b")},
+		// from key is confined to the tenant
+		{[]byte("/t/"), []byte("a"), []byte{0}, []byte("/t/a"), []byte("/t0")},
+		// all the keys of the tenant
+		{[]byte("/t/"), []byte{0}, []byte{0}, []byte("/t/\x00"), []byte("/t0")},
+		{[]byte{0xff}, []byte("a"), []byte{0}, []byte{0xff, 'a'}, []byte{0}},
+		// the trailing 0xff bytes are truncated
+		{[]byte("t\xff"), []byte("a"), []byte{0}, []byte("t\xffa"), []byte("u")},
+		{[]byte("t\xff\xff"), []byte{0}, []byte{0}, []byte("t\xff\xff\x00"), []byte("u")},
+	}
+	for i, tt := range tests {
+		key, end := prefixInterval(tt.pfx, tt.key, tt.end)
+		if !bytes.Equal(key, tt.wantKey) || !bytes.Equal(end, tt.wantEnd) {
+			t.Errorf("#%d: got [%q, %q), want [%q, %q)", i, key, end, tt.wantKey, tt.wantEnd)
+		}
+	}
+}
+
+func TestPrefixTxnRequest(t *testing.T) {
+	pfx := []byte("/t/")
+	r := &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a"), RangeEnd: []byte{0}}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("a")}}},
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("b"), RangeEnd: []byte("c")}}}},
+			}}},
+		},
+		Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestRange{RequestRange: &pb.RangeRequest{Key: []byte("a")}}}},
+	}
+	r2, err := prefixTxnRequest(pfx, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(r2.Compare[0].Key) + "," + string(r2.Compare[0].RangeEnd); got != "/t/a,/t0" {
+		t.Errorf("compare = %q", got)
+	}
+	if got := string(r2.Success[0].GetRequestPut().Key); got != "/t/a" {
+		t.Errorf("put key = %q", got)
+	}
+	dr := r2.Success[1].GetRequestTxn().Failure[0].GetRequestDeleteRange()
+	if got := string(dr.Key) + "," + string(dr.RangeEnd); got != "/t/b,/t/c" {
+		t.Errorf("nested delete range = %q", got)
+	}
+	if got := string(r2.Failure[0].GetRequestRange().Key); got != "/t/a" {
+		t.Errorf("range key = %q", got)
+	}
+	// the request of the client is left unchanged
+	if got := string(r.Success[0].GetRequestPut().Key); got != "a" {
+		t.Errorf("original put key = %q", got)
+	}
+
+	r.Success = append(r.Success, &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{}}})
+	if _, err = prefixTxnRequest(pfx, r); err != rpctypes.ErrGRPCEmptyKey {
+		t.Errorf("expected %v, got %v", rpctypes.ErrGRPCEmptyKey, err)
+	}
+}
+
+func TestStripTxnResponse(t *testing.T) {
+	pfx := []byte("/t/")
+	kv := &mvccpb.KeyValue{Key: []byte("/t/a"), Value: []byte("v")}
+	resp := &pb.TxnResponse{Responses: []*pb.ResponseOp{
+		{Response: &pb.ResponseOp_ResponseRange{ResponseRange: &pb.RangeResponse{Kvs: []*mvccpb.KeyValue{kv}}}},
+		{Response: &pb.ResponseOp_ResponseTxn{ResponseTxn: &pb.TxnResponse{Responses: []*pb.ResponseOp{
+			{Response: &pb.ResponseOp_ResponsePut{ResponsePut: &pb.PutResponse{PrevKv: kv}}},
+		}}}},
+	}}
+	if err := stripTxnResponse(pfx, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(resp.Responses[0].GetResponseRange().Kvs[0].Key); got != "a" {
+		t.Errorf("range key = %q", got)
+	}
+	if got := string(resp.Responses[1].GetResponseTxn().Responses[0].GetResponsePut().PrevKv.Key); got != "a" {
+		t.Errorf("nested put prev key = %q", got)
+	}
+	// the key values may be shared
+	if got := string(kv.Key); got != "/t/a" {
+		t.Errorf("original key = %q", got)
+	}
+}
+
+func TestStripKVOutsideTenant(t *testing.T) {
+	pfx := []byte("t\xff")
+	resp := &pb.RangeResponse{Kvs: []*mvccpb.KeyValue{{Key: []byte("t\xffa")}, {Key: []byte("u")}}}
+	if err := stripRangeResponse(pfx, resp); err != rpctypes.ErrGRPCPermissionDenied {
+		t.Errorf("expected %v, got %v", rpctypes.ErrGRPCPermissionDenied, err)
+	}
+}
@@ -16,6 +16,7 @@ package v3rpc
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
@@ -23,10 +24,12 @@ import (
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3schema"
 	"go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -47,6 +50,14 @@ var toGRPCErrorMap = map[error]error{
 	etcdserver.ErrNoSpace:         rpctypes.ErrGRPCNoSpace,
 	etcdserver.ErrTooManyRequests: rpctypes.ErrTooManyRequests,
 
+	mvcc.ErrSecondaryIndexNotFound: rpctypes.ErrGRPCSecondaryIndexNotFound,
+	mvcc.ErrRetentionRuleNotFound:  rpctypes.ErrGRPCRetentionRuleNotFound,
+	mvcc.ErrInvalidRetentionRule:   rpctypes.ErrGRPCInvalidRetentionRule,
+
+	v3schema.ErrSchemaNotFound:  rpctypes.ErrGRPCValueSchemaNotFound,
+	v3schema.ErrInvalidSchema:   rpctypes.ErrGRPCInvalidValueSchema,
+	v3schema.ErrSchemaViolation: rpctypes.ErrGRPCValueSchemaViolation,
+
 	etcdserver.ErrNoLeader:                   rpctypes.ErrGRPCNoLeader,
 	etcdserver.ErrNotLeader:                  rpctypes.ErrGRPCNotLeader,
 	etcdserver.ErrLeaderChanged:              rpctypes.ErrGRPCLeaderChanged,
@@ -65,6 +76,9 @@ var toGRPCErrorMap = map[error]error{
 	version.ErrDowngradeInProcess:             rpctypes.ErrGRPCDowngradeInProcess,
 	version.ErrNoInflightDowngrade:            rpctypes.ErrGRPCNoInflightDowngrade,
 
+	etcdserver.ErrConfigReloadUnsupported: rpctypes.ErrGRPCConfigReloadUnsupported,
+	etcdserver.ErrStaleRead:               rpctypes.ErrGRPCStaleRead,
+
 	lease.ErrLeaseNotFound:    rpctypes.ErrGRPCLeaseNotFound,
 	lease.ErrLeaseExists:      rpctypes.ErrGRPCLeaseExist,
 	lease.ErrLeaseTTLTooLarge: rpctypes.ErrGRPCLeaseTTLTooLarge,
@@ -79,6 +93,7 @@ var toGRPCErrorMap = map[error]error{
 	auth.ErrRoleEmpty:            rpctypes.ErrGRPCRoleEmpty,
 	auth.ErrAuthFailed:           rpctypes.ErrGRPCAuthFailed,
 	auth.ErrPermissionNotGiven:   rpctypes.ErrGRPCPermissionNotGiven,
+	auth.ErrInvalidPermission:    rpctypes.ErrGRPCInvalidPermission,
 	auth.ErrPermissionDenied:     rpctypes.ErrGRPCPermissionDenied,
 	auth.ErrRoleNotGranted:       rpctypes.ErrGRPCRoleNotGranted,
 	auth.ErrPermissionNotGranted: rpctypes.ErrGRPCPermissionNotGranted,
@@ -97,6 +112,14 @@ func togRPCError(err error) error {
 	if err == context.Canceled || err == context.DeadlineExceeded {
 		return err
 	}
+	var verr *v3schema.ViolationError
+	if errors.As(err, &verr) {
+		return withBadRequest(rpctypes.ErrGRPCValueSchemaViolation, string(verr.Key), verr.Reason)
+	}
+	var serr *v3schema.InvalidSchemaError
+	if errors.As(err, &serr) {
+		return withBadRequest(rpctypes.ErrGRPCInvalidValueSchema, string(serr.Prefix), serr.Reason)
+	}
 	grpcErr, ok := toGRPCErrorMap[err]
 	if !ok {
 		return status.Error(codes.Unknown, err.Error())
@@ -104,6 +127,19 @@ func togRPCError(err error) error {
 	return grpcErr
 }
 
+// withBadRequest attaches the reason a field of the request is invalid to
+// the details of the gRPC error, keeping its code and message.
+func withBadRequest(grpcErr error, field, reason string) error {
+	st, _ := status.FromError(grpcErr)
+	st, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: reason}},
+	})
+	if err != nil {
+		return grpcErr
+	}
+	return st.Err()
+}
+
 func isClientCtxErr(ctxErr error, err error) bool {
 	if ctxErr != nil {
 		return true
@@ -136,14 +172,21 @@ func isClientCtxErr(ctxErr error, err error) bool {
 	return false
 }
 
-// in v3.4, learner is allowed to serve serializable read and endpoint status
-func isRPCSupportedForLearner(req interface{}) bool {
+// in v3.4, learner is allowed to serve serializable read and endpoint status;
+// with serveReads, it also serves linearizable read through a read index.
+func isRPCSupportedForLearner(req interface{}, serveReads bool) bool {
 	switch r := req.(type) {
 	case *pb.StatusRequest:
 		return true
 	case *pb.RangeRequest:
-		return r.Serializable
+		return r.Serializable || serveReads
 	default:
 		return false
 	}
 }
+
+// isStreamRPCSupportedForLearner reports whether a learner serves the stream
+// RPC, which is Snapshot, and Watch with serveReads.
+func isStreamRPCSupportedForLearner(method string, serveReads bool) bool {
+	return method == snapshotMethod || (serveReads && method == watchMethod)
+}
@@ -229,10 +229,10 @@ func (sws *serverWatchStream) isWatchPermitted(wcr *pb.WatchCreateRequest) bool
 		return false
 	}
 	if authInfo == nil {
-		// if auth is enabled, IsRangePermitted() can cause an error
+		// if auth is enabled, IsWatchPermitted() can cause an error
 		authInfo = &auth.AuthInfo{}
 	}
-	return sws.ag.AuthStore().IsRangePermitted(authInfo, wcr.Key, wcr.RangeEnd) == nil
+	return sws.ag.AuthStore().IsWatchPermitted(authInfo, wcr.Key, wcr.RangeEnd) == nil
 }
 
 func (sws *serverWatchStream) recvLoop() error {
@@ -290,7 +290,8 @@ func (sws *serverWatchStream) recvLoop() error {
 			if rev == 0 {
 				rev = wsrev + 1
 			}
-			id, err := sws.watchStream.Watch(mvcc.WatchID(creq.WatchId), creq.Key, creq.RangeEnd, rev, filters...)
+			opts := mvcc.WatchOptions{Coalesce: creq.Coalesce, MaxEventsPerSecond: creq.MaxEventsPerSecond}
+			id, err := sws.watchStream.WatchWithOptions(mvcc.WatchID(creq.WatchId), creq.Key, creq.RangeEnd, rev, opts, filters...)
 			if err == nil {
 				sws.mu.Lock()
 				if creq.ProgressNotify {
@@ -0,0 +1,827 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is a compiled JSON Schema. It supports the validation keywords
+// of JSON Schema draft 4 to 2019-09 except the ones depending on annotations
+// ("unevaluatedProperties", "unevaluatedItems") and "dependencies"; "format"
+// is an annotation only. References are limited to JSON pointers into the
+// schema document ("#", "#/definitions/..." or "#/$defs/..."). Patterns use
+// Go regular expression syntax. Unknown keywords are ignored.
+type jsonSchema struct {
+	// always is set for the boolean schemas true and false.
+	always *bool
+	ref    *jsonSchema
+
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constVal interface{}
+
+	properties           map[string]*jsonSchema
+	patternProperties    []patternSchema
+	additionalProperties *jsonSchema
+	required             []string
+	minProperties        int
+	maxProperties        int // -1 if unbounded
+	propertyNames        *jsonSchema
+
+	items           *jsonSchema
+	tupleItems      []*jsonSchema
+	additionalItems *jsonSchema
+	contains        *jsonSchema
+	minItems        int
+	maxItems        int // -1 if unbounded
+	uniqueItems     bool
+
+	minLength int
+	maxLength int // -1 if unbounded
+	pattern   *regexp.Regexp
+
+	minimum          *big.Rat
+	maximum          *big.Rat
+	exclusiveMinimum *big.Rat
+	exclusiveMaximum *big.Rat
+	multipleOf       *big.Rat
+
+	allOf []*jsonSchema
+	anyOf []*jsonSchema
+	oneOf []*jsonSchema
+	not   *jsonSchema
+
+	ifSchema   *jsonSchema
+	thenSchema *jsonSchema
+	elseSchema *jsonSchema
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *jsonSchema
+}
+
+// maxNumberExponent bounds the exponent of the numbers, which are compared
+// exactly and would otherwise take unbounded memory.
+const maxNumberExponent = 1000
+
+func parseNumber(s string) (*big.Rat, bool) {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxNumberExponent || exp < -maxNumberExponent {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(s)
+}
+
+var jsonTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "integer": true, "string": true,
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+	return v, nil
+}
+
+type jsonSchemaCompiler struct {
+	root interface{}
+	refs map[string]*jsonSchema
+}
+
+func compileJSONSchema(definition []byte) (validator, error) {
+	doc, err := decodeJSON(definition)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse JSON schema: %v", err)
+	}
+	c := &jsonSchemaCompiler{root: doc, refs: make(map[string]*jsonSchema)}
+	s := &jsonSchema{}
+	c.refs["#"] = s
+	if err = c.compileInto(s, doc, "#"); err != nil {
+		return nil, err
+	}
+	if err = checkCycles(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sameInstance returns the subschemas applied to the same value as the schema.
+func (s *jsonSchema) sameInstance() []*jsonSchema {
+	ss := []*jsonSchema{s.ref, s.not, s.ifSchema, s.thenSchema, s.elseSchema}
+	ss = append(ss, s.allOf...)
+	ss = append(ss, s.anyOf...)
+	return append(ss, s.oneOf...)
+}
+
+// children returns all the subschemas of the schema.
+func (s *jsonSchema) children() []*jsonSchema {
+	ss := append(s.sameInstance(), s.additionalProperties, s.propertyNames, s.items, s.additionalItems, s.contains)
+	for _, ps := range s.properties {
+		ss = append(ss, ps)
+	}
+	for _, pp := range s.patternProperties {
+		ss = append(ss, pp.schema)
+	}
+	return append(ss, s.tupleItems...)
+}
+
+// checkCycles rejects schemas that apply themselves to the same value through
+// references, which validation would never return from.
+func checkCycles(root *jsonSchema) error {
+	var all []*jsonSchema
+	seen := map[*jsonSchema]bool{root: true}
+	for queue := []*jsonSchema{root}; len(queue) > 0; queue = queue[1:] {
+		all = append(all, queue[0])
+		for _, c := range queue[0].children() {
+			if c != nil && !seen[c] {
+				seen[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*jsonSchema]int)
+	var visit func(s *jsonSchema) bool
+	visit = func(s *jsonSchema) bool {
+		switch state[s] {
+		case visiting:
+			return false
+		case done:
+			return true
+		}
+		state[s] = visiting
+		for _, c := range s.sameInstance() {
+			if c != nil && !visit(c) {
+				return false
+			}
+		}
+		state[s] = done
+		return true
+	}
+	for _, s := range all {
+		if !visit(s) {
+			return errors.New("schema references itself without validating a nested value")
+		}
+	}
+	return nil
+}
+
+func (c *jsonSchemaCompiler) compile(v interface{}, path string) (*jsonSchema, error) {
+	s := &jsonSchema{}
+	return s, c.compileInto(s, v, path)
+}
+
+func (c *jsonSchemaCompiler) compileInto(s *jsonSchema, v interface{}, path string) error {
+	if b, ok := v.(bool); ok {
+		s.always = &b
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: schema must be an object or a boolean", path)
+	}
+	s.maxProperties, s.maxItems, s.maxLength = -1, -1, -1
+
+	if r, ok := m["$ref"]; ok {
+		ref, ok := r.(string)
+		if !ok {
+			return fmt.Errorf("%s/$ref: must be a string", path)
+		}
+		var err error
+		if s.ref, err = c.resolve(ref); err != nil {
+			return fmt.Errorf("%s/$ref: %v", path, err)
+		}
+	}
+
+	var err error
+	sub := func(key string) (*jsonSchema, error) {
+		v, ok := m[key]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(v, path+"/"+key)
+	}
+	subList := func(key string) ([]*jsonSchema, error) {
+		v, ok := m[key]
+		if !ok {
+			return nil, nil
+		}
+		l, ok := v.([]interface{})
+		if !ok || len(l) == 0 {
+			return nil, fmt.Errorf("%s/%s: must be a non-empty array", path, key)
+		}
+		ss := make([]*jsonSchema, len(l))
+		for i := range l {
+			if ss[i], err = c.compile(l[i], fmt.Sprintf("%s/%s/%d", path, key, i)); err != nil {
+				return nil, err
+			}
+		}
+		return ss, nil
+	}
+	count := func(key string, dst *int) error {
+		v, ok := m[key]
+		if !ok {
+			return nil
+		}
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s/%s: must be a non-negative integer", path, key)
+		}
+		i, err := strconv.ParseInt(n.String(), 10, 32)
+		if err != nil || i < 0 {
+			return fmt.Errorf("%s/%s: must be a non-negative integer", path, key)
+		}
+		*dst = int(i)
+		return nil
+	}
+	number := func(key string) (*big.Rat, error) {
+		v, ok := m[key]
+		if !ok {
+			return nil, nil
+		}
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%s/%s: must be a number", path, key)
+		}
+		r, ok := parseNumber(n.String())
+		if !ok {
+			return nil, fmt.Errorf("%s/%s: must be a number", path, key)
+		}
+		return r, nil
+	}
+
+	if t, ok := m["type"]; ok {
+		switch t := t.(type) {
+		case string:
+			s.types = []string{t}
+		case []interface{}:
+			for _, e := range t {
+				name, ok := e.(string)
+				if !ok {
+					return fmt.Errorf("%s/type: must be a string or an array of strings", path)
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return fmt.Errorf("%s/type: must be a string or an array of strings", path)
+		}
+		for _, name := range s.types {
+			if !jsonTypes[name] {
+				return fmt.Errorf("%s/type: unknown type %q", path, name)
+			}
+		}
+	}
+	if e, ok := m["enum"]; ok {
+		if s.enum, ok = e.([]interface{}); !ok {
+			return fmt.Errorf("%s/enum: must be an array", path)
+		}
+	}
+	s.constVal, s.hasConst = m["const"]
+
+	if p, ok := m["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s/properties: must be an object", path)
+		}
+		s.properties = make(map[string]*jsonSchema, len(props))
+		for name, v := range props {
+			if s.properties[name], err = c.compile(v, path+"/properties/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+	}
+	if p, ok := m["patternProperties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s/patternProperties: must be an object", path)
+		}
+		patterns := make([]string, 0, len(props))
+		for pattern := range props {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s/patternProperties: %v", path, err)
+			}
+			ps, err := c.compile(props[pattern], path+"/patternProperties/"+escapePointer(pattern))
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{re: re, schema: ps})
+		}
+	}
+	if s.additionalProperties, err = sub("additionalProperties"); err != nil {
+		return err
+	}
+	if s.propertyNames, err = sub("propertyNames"); err != nil {
+		return err
+	}
+	if r, ok := m["required"]; ok {
+		l, ok := r.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s/required: must be an array of strings", path)
+		}
+		for _, e := range l {
+			name, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("%s/required: must be an array of strings", path)
+			}
+			s.required = append(s.required, name)
+		}
+	}
+	if err = count("minProperties", &s.minProperties); err != nil {
+		return err
+	}
+	if err = count("maxProperties", &s.maxProperties); err != nil {
+		return err
+	}
+
+	if items, ok := m["items"]; ok {
+		if l, ok := items.([]interface{}); ok {
+			s.tupleItems = []*jsonSchema{}
+			if len(l) > 0 {
+				if s.tupleItems, err = subList("items"); err != nil {
+					return err
+				}
+			}
+		} else if s.items, err = sub("items"); err != nil {
+			return err
+		}
+	}
+	if s.additionalItems, err = sub("additionalItems"); err != nil {
+		return err
+	}
+	if s.contains, err = sub("contains"); err != nil {
+		return err
+	}
+	if err = count("minItems", &s.minItems); err != nil {
+		return err
+	}
+	if err = count("maxItems", &s.maxItems); err != nil {
+		return err
+	}
+	if u, ok := m["uniqueItems"]; ok {
+		if s.uniqueItems, ok = u.(bool); !ok {
+			return fmt.Errorf("%s/uniqueItems: must be a boolean", path)
+		}
+	}
+
+	if err = count("minLength", &s.minLength); err != nil {
+		return err
+	}
+	if err = count("maxLength", &s.maxLength); err != nil {
+		return err
+	}
+	if p, ok := m["pattern"]; ok {
+		pattern, ok := p.(string)
+		if !ok {
+			return fmt.Errorf("%s/pattern: must be a string", path)
+		}
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s/pattern: %v", path, err)
+		}
+	}
+
+	if s.minimum, err = number("minimum"); err != nil {
+		return err
+	}
+	if s.maximum, err = number("maximum"); err != nil {
+		return err
+	}
+	// draft 4 makes exclusiveMinimum and exclusiveMaximum booleans modifying
+	// minimum and maximum, later drafts numbers of their own
+	if b, ok := m["exclusiveMinimum"].(bool); ok {
+		if b {
+			s.exclusiveMinimum, s.minimum = s.minimum, nil
+		}
+	} else if s.exclusiveMinimum, err = number("exclusiveMinimum"); err != nil {
+		return err
+	}
+	if b, ok := m["exclusiveMaximum"].(bool); ok {
+		if b {
+			s.exclusiveMaximum, s.maximum = s.maximum, nil
+		}
+	} else if s.exclusiveMaximum, err = number("exclusiveMaximum"); err != nil {
+		return err
+	}
+	if s.multipleOf, err = number("multipleOf"); err != nil {
+		return err
+	}
+	if s.multipleOf != nil && s.multipleOf.Sign() <= 0 {
+		return fmt.Errorf("%s/multipleOf: must be strictly greater than 0", path)
+	}
+
+	if s.allOf, err = subList("allOf"); err != nil {
+		return err
+	}
+	if s.anyOf, err = subList("anyOf"); err != nil {
+		return err
+	}
+	if s.oneOf, err = subList("oneOf"); err != nil {
+		return err
+	}
+	if s.not, err = sub("not"); err != nil {
+		return err
+	}
+	if s.ifSchema, err = sub("if"); err != nil {
+		return err
+	}
+	if s.thenSchema, err = sub("then"); err != nil {
+		return err
+	}
+	if s.elseSchema, err = sub("else"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// resolve compiles the schema a local reference points to, once.
+func (c *jsonSchemaCompiler) resolve(ref string) (*jsonSchema, error) {
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported reference %q, only references into the schema are supported", ref)
+	}
+	v := c.root
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[tok]; !ok {
+				return nil, fmt.Errorf("reference %q not found", ref)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("reference %q not found", ref)
+			}
+			v = t[i]
+		default:
+			return nil, fmt.Errorf("reference %q not found", ref)
+		}
+	}
+	// register before compiling so that recursive references terminate
+	s := &jsonSchema{}
+	c.refs[ref] = s
+	return s, c.compileInto(s, v, ref)
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func (s *jsonSchema) validate(value []byte) error {
+	v, err := decodeJSON(value)
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	return s.validateValue(v, "")
+}
+
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case json.Number:
+		if r, ok := parseNumber(v.String()); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func (s *jsonSchema) validateValue(v interface{}, path string) error {
+	if s.always != nil {
+		if !*s.always {
+			return fmt.Errorf("%s: no value is allowed", pathOrRoot(path))
+		}
+		return nil
+	}
+	if s.ref != nil {
+		if err := s.ref.validateValue(v, path); err != nil {
+			return err
+		}
+	}
+
+	t := jsonType(v)
+	if len(s.types) > 0 {
+		ok := false
+		for _, want := range s.types {
+			if want == t || (want == "number" && t == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %s", pathOrRoot(path), strings.Join(s.types, " or "), t)
+		}
+	}
+	if s.enum != nil {
+		ok := false
+		for _, e := range s.enum {
+			if jsonEqual(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: value is not one of the enumerated values", pathOrRoot(path))
+		}
+	}
+	if s.hasConst && !jsonEqual(v, s.constVal) {
+		return fmt.Errorf("%s: value does not equal the constant", pathOrRoot(path))
+	}
+
+	var err error
+	switch v := v.(type) {
+	case map[string]interface{}:
+		err = s.validateObject(v, path)
+	case []interface{}:
+		err = s.validateArray(v, path)
+	case string:
+		err = s.validateString(v, path)
+	case json.Number:
+		err = s.validateNumber(v, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range s.allOf {
+		if err := sub.validateValue(v, path); err != nil {
+			return err
+		}
+	}
+	if s.anyOf != nil {
+		var firstErr error
+		for _, sub := range s.anyOf {
+			if err := sub.validateValue(v, path); err == nil {
+				firstErr = nil
+				break
+			} else if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			return fmt.Errorf("%s: value matches none of the anyOf schemas (%v)", pathOrRoot(path), firstErr)
+		}
+	}
+	if s.oneOf != nil {
+		matches := 0
+		for _, sub := range s.oneOf {
+			if sub.validateValue(v, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: value matches %d of the oneOf schemas, expected exactly one", pathOrRoot(path), matches)
+		}
+	}
+	if s.not != nil && s.not.validateValue(v, path) == nil {
+		return fmt.Errorf("%s: value matches the schema it must not match", pathOrRoot(path))
+	}
+	if s.ifSchema != nil {
+		if s.ifSchema.validateValue(v, path) == nil {
+			if s.thenSchema != nil {
+				return s.thenSchema.validateValue(v, path)
+			}
+		} else if s.elseSchema != nil {
+			return s.elseSchema.validateValue(v, path)
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(o map[string]interface{}, path string) error {
+	for _, name := range s.required {
+		if _, ok := o[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", pathOrRoot(path), name)
+		}
+	}
+	if len(o) < s.minProperties {
+		return fmt.Errorf("%s: expected at least %d properties, got %d", pathOrRoot(path), s.minProperties, len(o))
+	}
+	if s.maxProperties >= 0 && len(o) > s.maxProperties {
+		return fmt.Errorf("%s: expected at most %d properties, got %d", pathOrRoot(path), s.maxProperties, len(o))
+	}
+
+	// validate in a stable order so that the reported violation is deterministic
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := path + "/" + escapePointer(name)
+		if s.propertyNames != nil {
+			if err := s.propertyNames.validateValue(name, p); err != nil {
+				return err
+			}
+		}
+		matched := false
+		if ps, ok := s.properties[name]; ok {
+			matched = true
+			if err := ps.validateValue(o[name], p); err != nil {
+				return err
+			}
+		}
+		for _, pp := range s.patternProperties {
+			if pp.re.MatchString(name) {
+				matched = true
+				if err := pp.schema.validateValue(o[name], p); err != nil {
+					return err
+				}
+			}
+		}
+		if !matched && s.additionalProperties != nil {
+			if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+				return fmt.Errorf("%s: additional property %q is not allowed", pathOrRoot(path), name)
+			}
+			if err := s.additionalProperties.validateValue(o[name], p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(a []interface{}, path string) error {
+	if len(a) < s.minItems {
+		return fmt.Errorf("%s: expected at least %d items, got %d", pathOrRoot(path), s.minItems, len(a))
+	}
+	if s.maxItems >= 0 && len(a) > s.maxItems {
+		return fmt.Errorf("%s: expected at most %d items, got %d", pathOrRoot(path), s.maxItems, len(a))
+	}
+	for i, e := range a {
+		p := path + "/" + strconv.Itoa(i)
+		var is *jsonSchema
+		switch {
+		case s.items != nil:
+			is = s.items
+		case i < len(s.tupleItems):
+			is = s.tupleItems[i]
+		case s.tupleItems != nil:
+			is = s.additionalItems
+		}
+		if is != nil {
+			if err := is.validateValue(e, p); err != nil {
+				return err
+			}
+		}
+	}
+	if s.contains != nil {
+		found := false
+		for i, e := range a {
+			if s.contains.validateValue(e, path+"/"+strconv.Itoa(i)) == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: no item matches the contains schema", pathOrRoot(path))
+		}
+	}
+	if s.uniqueItems {
+		for i := range a {
+			for j := i + 1; j < len(a); j++ {
+				if jsonEqual(a[i], a[j]) {
+					return fmt.Errorf("%s: items %d and %d are equal", pathOrRoot(path), i, j)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(str, path string) error {
+	n := utf8.RuneCountInString(str)
+	if n < s.minLength {
+		return fmt.Errorf("%s: expected at least %d characters, got %d", pathOrRoot(path), s.minLength, n)
+	}
+	if s.maxLength >= 0 && n > s.maxLength {
+		return fmt.Errorf("%s: expected at most %d characters, got %d", pathOrRoot(path), s.maxLength, n)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		return fmt.Errorf("%s: value does not match pattern %q", pathOrRoot(path), s.pattern.String())
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateNumber(num json.Number, path string) error {
+	r, ok := parseNumber(num.String())
+	if !ok {
+		return fmt.Errorf("%s: invalid number %s", pathOrRoot(path), num)
+	}
+	if s.minimum != nil && r.Cmp(s.minimum) < 0 {
+		return fmt.Errorf("%s: %s is less than the minimum %s", pathOrRoot(path), num, s.minimum.RatString())
+	}
+	if s.maximum != nil && r.Cmp(s.maximum) > 0 {
+		return fmt.Errorf("%s: %s is greater than the maximum %s", pathOrRoot(path), num, s.maximum.RatString())
+	}
+	if s.exclusiveMinimum != nil && r.Cmp(s.exclusiveMinimum) <= 0 {
+		return fmt.Errorf("%s: %s is not greater than the exclusive minimum %s", pathOrRoot(path), num, s.exclusiveMinimum.RatString())
+	}
+	if s.exclusiveMaximum != nil && r.Cmp(s.exclusiveMaximum) >= 0 {
+		return fmt.Errorf("%s: %s is not less than the exclusive maximum %s", pathOrRoot(path), num, s.exclusiveMaximum.RatString())
+	}
+	if s.multipleOf != nil && !new(big.Rat).Quo(r, s.multipleOf).IsInt() {
+		return fmt.Errorf("%s: %s is not a multiple of %s", pathOrRoot(path), num, s.multipleOf.RatString())
+	}
+	return nil
+}
+
+// jsonEqual compares decoded JSON values, comparing numbers by value.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		bn, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ar, aok := parseNumber(a.String())
+		br, bok := parseNumber(bn.String())
+		return aok && bok && ar.Cmp(br) == 0
+	case map[string]interface{}:
+		bm, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := bm[k]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bl, ok := b.([]interface{})
+		if !ok || len(a) != len(bl) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], bl[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		// err is a substring of the error, empty if the value is valid.
+		err string
+	}{
+		{"true", `true`, `{"a": 1}`, ""},
+		{"false", `false`, `1`, "no value is allowed"},
+		{"not json", `{}`, `{`, "invalid JSON"},
+		{"type", `{"type": "object"}`, `[]`, "expected object, got array"},
+		{"types", `{"type": ["string", "null"]}`, `null`, ""},
+		{"integer", `{"type": "integer"}`, `1.0`, ""},
+		{"not integer", `{"type": "integer"}`, `1.5`, "expected integer"},
+		{"enum", `{"enum": ["a", 1]}`, `"b"`, "enum"},
+		{"const", `{"const": {"a": [1]}}`, `{"a": [1.0]}`, ""},
+		{"required", `{"required": ["a"]}`, `{"b": 1}`, `"a"`},
+		{"properties", `{"properties": {"a": {"type": "string"}}}`, `{"a": 1}`, "/a"},
+		{"additionalProperties", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, `additional property "b"`},
+		{"patternProperties", `{"patternProperties": {"^x-": {"type": "string"}}}`, `{"x-a": 1}`, "/x-a"},
+		{"items", `{"items": {"type": "integer"}}`, `[1, "2"]`, "/1"},
+		{"tuple", `{"items": [{"type": "integer"}], "additionalItems": false}`, `[1, 2]`, "/1"},
+		{"contains", `{"contains": {"const": 2}}`, `[1, 3]`, "contains"},
+		{"uniqueItems", `{"uniqueItems": true}`, `[1, 1.0]`, "items 0 and 1 are equal"},
+		{"minLength", `{"minLength": 2}`, `"é"`, "at least 2 characters, got 1"},
+		{"pattern", `{"pattern": "^[a-z]+$"}`, `"A"`, "pattern"},
+		{"minimum", `{"minimum": 1}`, `0.5`, "minimum"},
+		{"exclusiveMaximum", `{"exclusiveMaximum": 1}`, `1`, "exclusive maximum"},
+		{"draft4 exclusiveMaximum", `{"maximum": 1, "exclusiveMaximum": true}`, `1`, "maximum"},
+		{"multipleOf", `{"multipleOf": 0.1}`, `0.3`, ""},
+		{"big numbers", `{"maximum": 1e300}`, `1e301`, "maximum"},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, "anyOf"},
+		{"oneOf", `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`, `1`, "oneOf"},
+		{"not", `{"not": {"type": "null"}}`, `null`, "not"},
+		{"if then", `{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`, `{"a": 1}`, `"b"`},
+		{"if else", `{"if": {"required": ["a"]}, "else": {"required": ["b"]}}`, `{"a": 1}`, ""},
+		{"ref", `{"definitions": {"pos": {"minimum": 0}}, "properties": {"a": {"$ref": "#/definitions/pos"}}}`, `{"a": -1}`, "/a"},
+		{"recursive ref", `{"properties": {"child": {"$ref": "#"}}, "required": ["name"]}`, `{"name": "a", "child": {}}`, "/child"},
+		{"unknown keyword", `{"format": "email", "x-custom": 1}`, `"a"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := compileJSONSchema([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			err = v.validate([]byte(tt.value))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected %s to be valid, got %v", tt.value, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaCompileError(t *testing.T) {
+	tests := []string{
+		`{`,
+		`1`,
+		`{"type": "int"}`,
+		`{"required": "a"}`,
+		`{"pattern": "("}`,
+		`{"minimum": "1"}`,
+		`{"multipleOf": 0}`,
+		`{"maximum": 1e100000}`,
+		`{"$ref": "http://example.com/schema.json"}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"$ref": "#"}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"allOf": [{"$ref": "#/definitions/a"}]}}, "$ref": "#/definitions/a"}`,
+	}
+	for _, schema := range tests {
+		if _, err := compileJSONSchema([]byte(schema)); err == nil {
+			t.Errorf("expected compiling %s to fail", schema)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3schema
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoSchema requires values to be protobuf encoded messages of a type,
+// with all their required fields set and no fields unknown to the type.
+type protoSchema struct {
+	md protoreflect.MessageDescriptor
+}
+
+// compileProtoSchema finds the message type in a serialized FileDescriptorSet,
+// which must include the files the type depends on, as generated by
+// "protoc --include_imports --descriptor_set_out".
+func compileProtoSchema(definition []byte, messageType string) (validator, error) {
+	if messageType == "" {
+		return nil, errors.New("missing message type of protobuf schema")
+	}
+	fds := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(definition, fds); err != nil {
+		return nil, fmt.Errorf("cannot parse file descriptor set: %v", err)
+	}
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file descriptor set: %v", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(messageType))
+	if err != nil {
+		return nil, fmt.Errorf("message type %q: %v", messageType, err)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message type", messageType)
+	}
+	return &protoSchema{md: md}, nil
+}
+
+func (s *protoSchema) validate(value []byte) error {
+	m := dynamicpb.NewMessage(s.md)
+	if err := proto.Unmarshal(value, m); err != nil {
+		return fmt.Errorf("cannot unmarshal %s: %v", s.md.FullName(), err)
+	}
+	return checkUnknownFields(m, string(s.md.FullName()))
+}
+
+func checkUnknownFields(m protoreflect.Message, path string) error {
+	if len(m.GetUnknown()) > 0 {
+		return fmt.Errorf("%s: unknown fields", path)
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		p := path + "." + string(fd.Name())
+		switch {
+		case fd.IsList():
+			l := v.List()
+			for i := 0; i < l.Len() && err == nil; i++ {
+				err = checkUnknownFields(l.Get(i).Message(), fmt.Sprintf("%s[%d]", p, i))
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				return true
+			}
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				err = checkUnknownFields(mv.Message(), fmt.Sprintf("%s[%v]", p, k.Interface()))
+				return err == nil
+			})
+		default:
+			err = checkUnknownFields(v.Message(), p)
+		}
+		return err == nil
+	})
+	return err
+}
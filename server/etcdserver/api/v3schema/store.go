@@ -0,0 +1,220 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3schema validates the values written under key prefixes against schemas.
+package v3schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSchemaNotFound  = errors.New("v3schema: value schema not found")
+	ErrInvalidSchema   = errors.New("v3schema: invalid value schema")
+	ErrSchemaViolation = errors.New("v3schema: value does not match the schema of its key prefix")
+)
+
+// InvalidSchemaError reports why a schema cannot be used.
+type InvalidSchemaError struct {
+	Prefix []byte
+	Reason string
+}
+
+func (e *InvalidSchemaError) Error() string {
+	return fmt.Sprintf("%v: prefix %q: %s", ErrInvalidSchema, e.Prefix, e.Reason)
+}
+
+func (e *InvalidSchemaError) Unwrap() error { return ErrInvalidSchema }
+
+// ViolationError reports why a value does not match the schema of its key.
+type ViolationError struct {
+	Key    []byte
+	Prefix []byte
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("%v: key %q, schema prefix %q: %s", ErrSchemaViolation, e.Key, e.Prefix, e.Reason)
+}
+
+func (e *ViolationError) Unwrap() error { return ErrSchemaViolation }
+
+type SchemaBackend interface {
+	CreateValueSchemaBucket()
+	MustPutValueSchema(vs *pb.ValueSchema)
+	MustDeleteValueSchema(prefix []byte)
+	GetAllValueSchemas() ([]*pb.ValueSchema, error)
+}
+
+// validator checks values against a compiled schema definition.
+type validator interface {
+	validate(value []byte) error
+}
+
+type compiledSchema struct {
+	vs *pb.ValueSchema
+	v  validator
+}
+
+// SchemaStore keeps the value schemas of key prefixes and persists them to the backend.
+type SchemaStore struct {
+	lg *zap.Logger
+
+	mu      sync.RWMutex
+	schemas map[string]*compiledSchema
+
+	be SchemaBackend
+}
+
+func NewSchemaStore(lg *zap.Logger, be SchemaBackend) (*SchemaStore, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	s := &SchemaStore{lg: lg}
+	err := s.Recover(be)
+	return s, err
+}
+
+// Recover replaces the schemas with the ones persisted in the backend.
+func (s *SchemaStore) Recover(be SchemaBackend) error {
+	be.CreateValueSchemaBucket()
+	vss, err := be.GetAllValueSchemas()
+	if err != nil {
+		return err
+	}
+	schemas := make(map[string]*compiledSchema, len(vss))
+	for _, vs := range vss {
+		cs, err := compile(vs)
+		if err != nil {
+			return err
+		}
+		schemas[string(vs.Prefix)] = cs
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.be = be
+	s.schemas = schemas
+	return nil
+}
+
+// Compile checks that the schema definition is valid, returning an
+// *InvalidSchemaError otherwise.
+func Compile(vs *pb.ValueSchema) error {
+	_, err := compile(vs)
+	return err
+}
+
+func compile(vs *pb.ValueSchema) (*compiledSchema, error) {
+	if vs == nil {
+		return nil, &InvalidSchemaError{Reason: "missing schema"}
+	}
+	var (
+		v   validator
+		err error
+	)
+	switch vs.Type {
+	case pb.ValueSchema_JSON:
+		if vs.MessageType != "" {
+			err = errors.New("message type is only used by protobuf schemas")
+			break
+		}
+		v, err = compileJSONSchema(vs.Definition)
+	case pb.ValueSchema_PROTOBUF:
+		v, err = compileProtoSchema(vs.Definition, vs.MessageType)
+	default:
+		err = fmt.Errorf("unknown schema type %v", vs.Type)
+	}
+	if err != nil {
+		return nil, &InvalidSchemaError{Prefix: vs.Prefix, Reason: err.Error()}
+	}
+	return &compiledSchema{vs: vs, v: v}, nil
+}
+
+// Put sets the schema of its prefix.
+func (s *SchemaStore) Put(vs *pb.ValueSchema) error {
+	cs, err := compile(vs)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.be.MustPutValueSchema(vs)
+	s.schemas[string(vs.Prefix)] = cs
+	return nil
+}
+
+// Delete deletes the schema of the prefix.
+func (s *SchemaStore) Delete(prefix []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schemas[string(prefix)]; !ok {
+		return ErrSchemaNotFound
+	}
+	s.be.MustDeleteValueSchema(prefix)
+	delete(s.schemas, string(prefix))
+	return nil
+}
+
+// Get returns the schema of the prefix.
+func (s *SchemaStore) Get(prefix []byte) (*pb.ValueSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cs, ok := s.schemas[string(prefix)]
+	if !ok {
+		return nil, ErrSchemaNotFound
+	}
+	return cs.vs, nil
+}
+
+// List returns the schemas ordered by prefix.
+func (s *SchemaStore) List() []*pb.ValueSchema {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	vss := make([]*pb.ValueSchema, 0, len(s.schemas))
+	for _, cs := range s.schemas {
+		vss = append(vss, cs.vs)
+	}
+	sort.Slice(vss, func(i, j int) bool { return bytes.Compare(vss[i].Prefix, vss[j].Prefix) < 0 })
+	return vss
+}
+
+// Validate checks the value written to the key against the schema of
+// the longest prefix of the key, returning a *ViolationError if it
+// does not match. Keys without a schema accept any value.
+func (s *SchemaStore) Validate(key, value []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var match *compiledSchema
+	for prefix, cs := range s.schemas {
+		if bytes.HasPrefix(key, []byte(prefix)) && (match == nil || len(prefix) > len(match.vs.Prefix)) {
+			match = cs
+		}
+	}
+	if match == nil {
+		return nil
+	}
+	if err := match.v.validate(value); err != nil {
+		return &ViolationError{Key: key, Prefix: match.vs.Prefix, Reason: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3schema
+
+import (
+	"errors"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type fakeSchemaBackend struct {
+	schemas map[string]*pb.ValueSchema
+}
+
+func newFakeSchemaBackend() *fakeSchemaBackend {
+	return &fakeSchemaBackend{schemas: make(map[string]*pb.ValueSchema)}
+}
+
+func (b *fakeSchemaBackend) CreateValueSchemaBucket() {}
+
+func (b *fakeSchemaBackend) MustPutValueSchema(vs *pb.ValueSchema) { b.schemas[string(vs.Prefix)] = vs }
+
+func (b *fakeSchemaBackend) MustDeleteValueSchema(prefix []byte) { delete(b.schemas, string(prefix)) }
+
+func (b *fakeSchemaBackend) GetAllValueSchemas() ([]*pb.ValueSchema, error) {
+	var vss []*pb.ValueSchema
+	for _, vs := range b.schemas {
+		vss = append(vss, vs)
+	}
+	return vss, nil
+}
+
+func jsonValueSchema(prefix, definition string) *pb.ValueSchema {
+	return &pb.ValueSchema{Prefix: []byte(prefix), Type: pb.ValueSchema_JSON, Definition: []byte(definition)}
+}
+
+func TestSchemaStoreValidate(t *testing.T) {
+	be := newFakeSchemaBackend()
+	s, err := NewSchemaStore(zaptest.NewLogger(t), be)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Put(jsonValueSchema("/config/", `{"type": "object"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Put(jsonValueSchema("/config/raw/", `true`)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key, value string
+		prefix     string
+	}{
+		{"/other", "x", ""},
+		{"/config/a", `{}`, ""},
+		{"/config/a", `[]`, "/config/"},
+		{"/config/raw/a", `[]`, ""},
+		{"/config/raw/a", `x`, "/config/raw/"},
+	}
+	for _, tt := range tests {
+		err := s.Validate([]byte(tt.key), []byte(tt.value))
+		if tt.prefix == "" {
+			if err != nil {
+				t.Errorf("Validate(%q, %q) = %v, want nil", tt.key, tt.value, err)
+			}
+			continue
+		}
+		var verr *ViolationError
+		if !errors.As(err, &verr) || string(verr.Prefix) != tt.prefix || !errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("Validate(%q, %q) = %v, want a violation of the schema of %q", tt.key, tt.value, err, tt.prefix)
+		}
+	}
+
+	// the schemas survive a recovery from the backend
+	s2, err := NewSchemaStore(zaptest.NewLogger(t), be)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := s2.List(); len(l) != 2 || string(l[0].Prefix) != "/config/" || string(l[1].Prefix) != "/config/raw/" {
+		t.Fatalf("unexpected recovered schemas %v", l)
+	}
+
+	if err = s.Delete([]byte("/config/")); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Validate([]byte("/config/a"), []byte(`[]`)); err != nil {
+		t.Fatalf("expected no schema after deleting it, got %v", err)
+	}
+	if _, err = s.Get([]byte("/config/")); err != ErrSchemaNotFound {
+		t.Fatalf("expected %v, got %v", ErrSchemaNotFound, err)
+	}
+	if err = s.Delete([]byte("/config/")); err != ErrSchemaNotFound {
+		t.Fatalf("expected %v, got %v", ErrSchemaNotFound, err)
+	}
+}
+
+func TestSchemaStorePutInvalid(t *testing.T) {
+	s, err := NewSchemaStore(zaptest.NewLogger(t), newFakeSchemaBackend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []*pb.ValueSchema{
+		nil,
+		jsonValueSchema("/a/", `{"type": 1}`),
+		{Prefix: []byte("/a/"), Type: pb.ValueSchema_JSON, Definition: []byte(`{}`), MessageType: "a.B"},
+		{Prefix: []byte("/a/"), Type: pb.ValueSchema_PROTOBUF, Definition: []byte("garbage")},
+		{Prefix: []byte("/a/"), Type: pb.ValueSchema_PROTOBUF, Definition: testFileDescriptorSet(t), MessageType: "test.Missing"},
+		{Prefix: []byte("/a/"), Type: 7},
+	}
+	for _, vs := range tests {
+		err := s.Put(vs)
+		var ierr *InvalidSchemaError
+		if !errors.As(err, &ierr) || !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("Put(%v) = %v, want an invalid schema error", vs, err)
+		}
+	}
+	if l := s.List(); len(l) != 0 {
+		t.Fatalf("expected no schemas, got %v", l)
+	}
+}
+
+// testFileDescriptorSet describes the proto2 message
+// "message Item { required string name = 1; repeated Item children = 2; }"
+// in package test.
+func testFileDescriptorSet(t *testing.T) []byte {
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Item"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:   proto.String("name"),
+				Number: proto.Int32(1),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}, {
+				Name:     proto.String("children"),
+				Number:   proto.Int32(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".test.Item"),
+			}},
+		}},
+	}
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fd}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestProtoSchemaValidate(t *testing.T) {
+	v, err := compileProtoSchema(testFileDescriptorSet(t), "test.Item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := func(name string, children ...[]byte) []byte {
+		var b []byte
+		if name != "" {
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendString(b, name)
+		}
+		for _, c := range children {
+			b = protowire.AppendTag(b, 2, protowire.BytesType)
+			b = protowire.AppendBytes(b, c)
+		}
+		return b
+	}
+	unknown := protowire.AppendVarint(protowire.AppendTag(item("a"), 3, protowire.VarintType), 1)
+
+	tests := []struct {
+		name  string
+		value []byte
+		valid bool
+	}{
+		{"valid", item("a", item("b")), true},
+		{"missing required field", item(""), false},
+		{"missing required field of child", item("a", item("")), false},
+		{"unknown field", unknown, false},
+		{"unknown field of child", item("a", unknown), false},
+		{"not protobuf", []byte{0xff}, false},
+	}
+	for _, tt := range tests {
+		if err := v.validate(tt.value); (err == nil) != tt.valid {
+			t.Errorf("%s: unexpected validation result %v", tt.name, err)
+		}
+	}
+}
@@ -73,11 +73,20 @@ type applierV3 interface {
 
 	LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error)
 	LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
+	LeaseExpire(le *pb.LeaseExpireRequest) (*pb.LeaseRevokeResponse, error)
+	LeaseUpdate(lu *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error)
+	LeaseMove(lm *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error)
 
 	LeaseCheckpoint(lc *pb.LeaseCheckpointRequest) (*pb.LeaseCheckpointResponse, error)
 
 	Alarm(*pb.AlarmRequest) (*pb.AlarmResponse, error)
 
+	RetentionPut(rp *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error)
+	RetentionDelete(rd *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error)
+
+	SchemaPut(sp *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error)
+	SchemaDelete(sd *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error)
+
 	Authenticate(r *pb.InternalAuthenticateRequest) (*pb.AuthenticateResponse, error)
 
 	AuthEnable() (*pb.AuthEnableResponse, error)
@@ -106,6 +115,10 @@ type applierV3backend struct {
 
 	checkPut   checkReqFunc
 	checkRange checkReqFunc
+
+	// leaseTenant gets the prefix of the tenant owning the leases granted
+	// by the request being applied.
+	leaseTenant func() string
 }
 
 func (s *EtcdServer) newApplierV3Backend() applierV3 {
@@ -125,11 +138,14 @@ func (s *EtcdServer) newApplierV3Internal() applierV3Internal {
 }
 
 func (s *EtcdServer) newApplierV3() applierV3 {
-	return newAuthApplierV3(
+	base := s.newApplierV3Backend()
+	aa := newAuthApplierV3(
 		s.AuthStore(),
-		newQuotaApplierV3(s, s.newApplierV3Backend()),
+		newQuotaApplierV3(s, base),
 		s.lessor,
 	)
+	base.(*applierV3backend).leaseTenant = aa.tenant
+	return aa
 }
 
 func (a *applierV3backend) Apply(r *pb.InternalRaftRequest, shouldApplyV3 membership.ShouldApplyV3) *applyResult {
@@ -186,12 +202,33 @@ func (a *applierV3backend) Apply(r *pb.InternalRaftRequest, shouldApplyV3 member
 	case r.LeaseRevoke != nil:
 		op = "LeaseRevoke"
 		ar.resp, ar.err = a.s.applyV3.LeaseRevoke(r.LeaseRevoke)
+	case r.LeaseExpire != nil:
+		op = "LeaseExpire"
+		ar.resp, ar.err = a.s.applyV3.LeaseExpire(r.LeaseExpire)
+	case r.LeaseUpdate != nil:
+		op = "LeaseUpdate"
+		ar.resp, ar.err = a.s.applyV3.LeaseUpdate(r.LeaseUpdate)
+	case r.LeaseMove != nil:
+		op = "LeaseMove"
+		ar.resp, ar.err = a.s.applyV3.LeaseMove(r.LeaseMove)
 	case r.LeaseCheckpoint != nil:
 		op = "LeaseCheckpoint"
 		ar.resp, ar.err = a.s.applyV3.LeaseCheckpoint(r.LeaseCheckpoint)
 	case r.Alarm != nil:
 		op = "Alarm"
 		ar.resp, ar.err = a.s.applyV3.Alarm(r.Alarm)
+	case r.RetentionPut != nil:
+		op = "RetentionPut"
+		ar.resp, ar.err = a.s.applyV3.RetentionPut(r.RetentionPut)
+	case r.RetentionDelete != nil:
+		op = "RetentionDelete"
+		ar.resp, ar.err = a.s.applyV3.RetentionDelete(r.RetentionDelete)
+	case r.SchemaPut != nil:
+		op = "SchemaPut"
+		ar.resp, ar.err = a.s.applyV3.SchemaPut(r.SchemaPut)
+	case r.SchemaDelete != nil:
+		op = "SchemaDelete"
+		ar.resp, ar.err = a.s.applyV3.SchemaDelete(r.SchemaDelete)
 	case r.Authenticate != nil:
 		op = "Authenticate"
 		ar.resp, ar.err = a.s.applyV3.Authenticate(r.Authenticate)
@@ -267,6 +304,11 @@ func (a *applierV3backend) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.Put
 				return nil, nil, lease.ErrLeaseNotFound
 			}
 		}
+		if !p.IgnoreValue {
+			if err = a.s.schemaStore.Validate(p.Key, p.Value); err != nil {
+				return nil, nil, err
+			}
+		}
 		txn = a.s.KV().Write(trace)
 		defer txn.End()
 	}
@@ -360,6 +402,11 @@ func (a *applierV3backend) Range(ctx context.Context, txn mvcc.TxnRead, r *pb.Ra
 		Limit: limit,
 		Rev:   r.Revision,
 		Count: r.CountOnly,
+		Index: mvcc.IndexRange{
+			Field:    r.IndexField,
+			Value:    r.IndexValue,
+			ValueEnd: r.IndexValueEnd,
+		},
 	}
 
 	rr, err := txn.Range(ctx, r.Key, mkGteRange(r.RangeEnd), ro)
@@ -390,10 +437,11 @@ func (a *applierV3backend) Range(ctx context.Context, txn mvcc.TxnRead, r *pb.Ra
 		// sorted by keys in lexiographically ascending order,
 		// sort ASCEND by default only when target is not 'KEY'
 		sortOrder = pb.RangeRequest_ASCEND
-	} else if r.SortTarget == pb.RangeRequest_KEY && sortOrder == pb.RangeRequest_ASCEND {
+	} else if r.SortTarget == pb.RangeRequest_KEY && sortOrder == pb.RangeRequest_ASCEND && r.IndexField == "" {
 		// Since current mvcc.Range implementation returns results
 		// sorted by keys in lexiographically ascending order,
-		// don't re-sort when target is 'KEY' and order is ASCEND
+		// don't re-sort when target is 'KEY' and order is ASCEND.
+		// Secondary index ranges are sorted by the indexed value instead.
 		sortOrder = pb.RangeRequest_NONE
 	}
 	if sortOrder != pb.RangeRequest_NONE {
@@ -687,7 +735,18 @@ func (a *applierV3backend) Compaction(compaction *pb.CompactionRequest) (*pb.Com
 		traceutil.Field{Key: "revision", Value: compaction.Revision},
 	)
 
-	ch, err := a.s.KV().Compact(trace, compaction.Revision)
+	var prefixRevs map[string]int64
+	if len(compaction.RetentionCompactions) > 0 {
+		prefixRevs = make(map[string]int64, len(compaction.RetentionCompactions))
+		for _, rc := range compaction.RetentionCompactions {
+			prefixRevs[string(rc.Prefix)] = rc.Revision
+		}
+	}
+	var mark mvcc.RetentionMark
+	if m := compaction.RetentionMark; m != nil {
+		mark = mvcc.RetentionMark{Rev: m.Revision, Time: time.Unix(0, m.Time)}
+	}
+	ch, err := a.s.KV().CompactWithRetention(trace, compaction.Revision, prefixRevs, mark)
 	if err != nil {
 		return nil, ch, nil, err
 	}
@@ -697,8 +756,48 @@ func (a *applierV3backend) Compaction(compaction *pb.CompactionRequest) (*pb.Com
 	return resp, ch, trace, err
 }
 
+func (a *applierV3backend) RetentionPut(rp *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error) {
+	if rp.Rule == nil {
+		return nil, mvcc.ErrInvalidRetentionRule
+	}
+	rule := mvcc.RetentionRule{
+		Prefix:    rp.Rule.Prefix,
+		Revisions: rp.Rule.Revisions,
+		Duration:  time.Duration(rp.Rule.DurationSeconds) * time.Second,
+	}
+	if err := a.s.KV().PutRetentionRule(rule); err != nil {
+		return nil, err
+	}
+	return &pb.RetentionPutResponse{Header: newHeader(a.s)}, nil
+}
+
+func (a *applierV3backend) RetentionDelete(rd *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error) {
+	if err := a.s.KV().DeleteRetentionRule(rd.Prefix); err != nil {
+		return nil, err
+	}
+	return &pb.RetentionDeleteResponse{Header: newHeader(a.s)}, nil
+}
+
+func (a *applierV3backend) SchemaPut(sp *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error) {
+	if err := a.s.schemaStore.Put(sp.Schema); err != nil {
+		return nil, err
+	}
+	return &pb.SchemaPutResponse{Header: newHeader(a.s)}, nil
+}
+
+func (a *applierV3backend) SchemaDelete(sd *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error) {
+	if err := a.s.schemaStore.Delete(sd.Prefix); err != nil {
+		return nil, err
+	}
+	return &pb.SchemaDeleteResponse{Header: newHeader(a.s)}, nil
+}
+
 func (a *applierV3backend) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
-	l, err := a.s.lessor.Grant(lease.LeaseID(lc.ID), lc.TTL)
+	tenant := ""
+	if a.leaseTenant != nil {
+		tenant = a.leaseTenant()
+	}
+	l, err := a.s.lessor.GrantTenant(lease.LeaseID(lc.ID), lc.TTL, tenant)
 	resp := &pb.LeaseGrantResponse{}
 	if err == nil {
 		resp.ID = int64(l.ID)
@@ -713,6 +812,41 @@ func (a *applierV3backend) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevo
 	return &pb.LeaseRevokeResponse{Header: newHeader(a.s)}, err
 }
 
+func (a *applierV3backend) LeaseExpire(le *pb.LeaseExpireRequest) (*pb.LeaseRevokeResponse, error) {
+	err := a.s.lessor.RevokeExpired(lease.LeaseID(le.ID))
+	return &pb.LeaseRevokeResponse{Header: newHeader(a.s)}, err
+}
+
+func (a *applierV3backend) LeaseUpdate(lu *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error) {
+	l, err := a.s.lessor.Update(lease.LeaseID(lu.ID), lu.TTL)
+	resp := &pb.LeaseUpdateResponse{}
+	if err == nil {
+		resp.ID = int64(l.ID)
+		resp.TTL = l.TTL()
+		resp.Header = newHeader(a.s)
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) LeaseMove(lm *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	items, err := a.s.lessor.Move(lease.LeaseID(lm.ID), lease.LeaseID(lm.TargetID))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.LeaseMoveResponse{}
+	if lm.ID != lm.TargetID {
+		// the keys keep their revisions; only their stored lease changes.
+		keys := make([][]byte, len(items))
+		for i, it := range items {
+			keys[i] = []byte(it.Key)
+		}
+		resp.Moved = int64(a.s.KV().MoveLease(keys, lease.LeaseID(lm.TargetID)))
+	}
+	resp.Header = newHeader(a.s)
+	return resp, nil
+}
+
 func (a *applierV3backend) LeaseCheckpoint(lc *pb.LeaseCheckpointRequest) (*pb.LeaseCheckpointResponse, error) {
 	for _, c := range lc.Checkpoints {
 		err := a.s.lessor.Checkpoint(lease.LeaseID(c.ID), c.Remaining_TTL)
@@ -803,6 +937,10 @@ func (a *applierV3Capped) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantRe
 	return nil, ErrNoSpace
 }
 
+func (a *applierV3Capped) LeaseMove(lm *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	return nil, ErrNoSpace
+}
+
 func (a *applierV3backend) AuthEnable() (*pb.AuthEnableResponse, error) {
 	err := a.s.AuthStore().AuthEnable()
 	if err != nil {
@@ -1077,6 +1215,9 @@ func (a *applierV3backend) checkRequestPut(rv mvcc.ReadView, reqOp *pb.RequestOp
 			return lease.ErrLeaseNotFound
 		}
 	}
+	if !req.IgnoreValue {
+		return a.s.schemaStore.Validate(req.Key, req.Value)
+	}
 	return nil
 }
 
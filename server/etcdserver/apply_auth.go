@@ -50,32 +50,46 @@ func (aa *authApplierV3) Apply(r *pb.InternalRaftRequest, shouldApplyV3 membersh
 		// does not have header field
 		aa.authInfo.Username = r.Header.Username
 		aa.authInfo.Revision = r.Header.AuthRevision
+		aa.authInfo.Roles = r.Header.Roles
 	}
 	if needAdminPermission(r) {
 		if err := aa.as.IsAdminPermitted(&aa.authInfo); err != nil {
-			aa.authInfo.Username = ""
-			aa.authInfo.Revision = 0
+			aa.resetAuthInfo()
 			return &applyResult{err: err}
 		}
 	}
 	ret := aa.applierV3.Apply(r, shouldApplyV3)
-	aa.authInfo.Username = ""
-	aa.authInfo.Revision = 0
+	aa.resetAuthInfo()
 	return ret
 }
 
+// resetAuthInfo clears the auth info of the request applied, so that it does
+// not leak to the next request.
+func (aa *authApplierV3) resetAuthInfo() {
+	aa.authInfo = auth.AuthInfo{}
+}
+
 func (aa *authApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, r *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error) {
 	if err := aa.as.IsPutPermitted(&aa.authInfo, r.Key); err != nil {
 		return nil, nil, err
 	}
 
-	if err := aa.checkLeasePuts(lease.LeaseID(r.Lease)); err != nil {
+	if lease.LeaseID(r.Lease) != lease.NoLease {
+		if err := aa.as.IsLeasePermitted(&aa.authInfo, r.Key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := aa.checkLeaseKeys(lease.LeaseID(r.Lease), aa.as.IsLeasePermitted); err != nil {
 		// The specified lease is already attached with a key that cannot
-		// be written by this user. It means the user cannot revoke the
+		// be leased by this user. It means the user cannot change the
 		// lease so attaching the lease to the newly written key should
 		// be forbidden.
 		return nil, nil, err
 	}
+	if err := aa.checkLeaseTenant(lease.LeaseID(r.Lease)); err != nil {
+		return nil, nil, err
+	}
 
 	if r.PrevKv {
 		err := aa.as.IsRangePermitted(&aa.authInfo, r.Key, nil)
@@ -127,6 +141,11 @@ func checkTxnReqsPermission(as auth.AuthStore, ai *auth.AuthInfo, reqs []*pb.Req
 			if err := as.IsPutPermitted(ai, tv.RequestPut.Key); err != nil {
 				return err
 			}
+			if tv.RequestPut.Lease != 0 {
+				if err := as.IsLeasePermitted(ai, tv.RequestPut.Key); err != nil {
+					return err
+				}
+			}
 
 		case *pb.RequestOp_RequestDeleteRange:
 			if tv.RequestDeleteRange == nil {
@@ -144,6 +163,15 @@ func checkTxnReqsPermission(as auth.AuthStore, ai *auth.AuthInfo, reqs []*pb.Req
 			if err != nil {
 				return err
 			}
+
+		case *pb.RequestOp_RequestTxn:
+			if tv.RequestTxn == nil {
+				continue
+			}
+
+			if err := checkTxnAuth(as, ai, tv.RequestTxn); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -152,7 +180,7 @@ func checkTxnReqsPermission(as auth.AuthStore, ai *auth.AuthInfo, reqs []*pb.Req
 
 func checkTxnAuth(as auth.AuthStore, ai *auth.AuthInfo, rt *pb.TxnRequest) error {
 	for _, c := range rt.Compare {
-		if err := as.IsRangePermitted(ai, c.Key, c.RangeEnd); err != nil {
+		if err := as.IsTxnComparePermitted(ai, c.Key, c.RangeEnd); err != nil {
 			return err
 		}
 	}
@@ -166,21 +194,65 @@ func (aa *authApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnRes
 	if err := checkTxnAuth(aa.as, &aa.authInfo, rt); err != nil {
 		return nil, nil, err
 	}
+	if err := aa.checkTxnLeaseTenant(rt); err != nil {
+		return nil, nil, err
+	}
 	return aa.applierV3.Txn(ctx, rt)
 }
 
 func (aa *authApplierV3) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
-	if err := aa.checkLeasePuts(lease.LeaseID(lc.ID)); err != nil {
+	if err := aa.checkLeaseTenant(lease.LeaseID(lc.ID)); err != nil {
+		return nil, err
+	}
+	if err := aa.checkLeaseKeys(lease.LeaseID(lc.ID), aa.isDeletePermitted); err != nil {
 		return nil, err
 	}
 	return aa.applierV3.LeaseRevoke(lc)
 }
 
-func (aa *authApplierV3) checkLeasePuts(leaseID lease.LeaseID) error {
+func (aa *authApplierV3) LeaseExpire(le *pb.LeaseExpireRequest) (*pb.LeaseRevokeResponse, error) {
+	if err := aa.checkLeaseKeys(lease.LeaseID(le.ID), aa.isDeletePermitted); err != nil {
+		return nil, err
+	}
+	return aa.applierV3.LeaseExpire(le)
+}
+
+func (aa *authApplierV3) LeaseUpdate(lu *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error) {
+	if err := aa.checkLeaseTenant(lease.LeaseID(lu.ID)); err != nil {
+		return nil, err
+	}
+	if err := aa.checkLeaseKeys(lease.LeaseID(lu.ID), aa.as.IsLeasePermitted); err != nil {
+		return nil, err
+	}
+	return aa.applierV3.LeaseUpdate(lu)
+}
+
+func (aa *authApplierV3) LeaseMove(lm *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	if err := aa.checkLeaseTenant(lease.LeaseID(lm.ID)); err != nil {
+		return nil, err
+	}
+	if err := aa.checkLeaseTenant(lease.LeaseID(lm.TargetID)); err != nil {
+		return nil, err
+	}
+	// the moved keys change their lease like a Put of the key with the target lease.
+	if err := aa.checkLeaseKeys(lease.LeaseID(lm.ID), aa.as.IsPutPermitted); err != nil {
+		return nil, err
+	}
+	if err := aa.checkLeaseKeys(lease.LeaseID(lm.ID), aa.as.IsLeasePermitted); err != nil {
+		return nil, err
+	}
+	if err := aa.checkLeaseKeys(lease.LeaseID(lm.TargetID), aa.as.IsLeasePermitted); err != nil {
+		return nil, err
+	}
+	return aa.applierV3.LeaseMove(lm)
+}
+
+// checkLeaseKeys checks the permission of the user on each key attached to the lease.
+func (aa *authApplierV3) checkLeaseKeys(leaseID lease.LeaseID, isPermitted func(*auth.AuthInfo, []byte) error) error {
 	lease := aa.lessor.Lookup(leaseID)
 	if lease != nil {
 		for _, key := range lease.Keys() {
-			if err := aa.as.IsPutPermitted(&aa.authInfo, []byte(key)); err != nil {
+			if err := isPermitted(&aa.authInfo, []byte(key)); err != nil {
 				return err
 			}
 		}
@@ -189,11 +261,58 @@ func (aa *authApplierV3) checkLeasePuts(leaseID lease.LeaseID) error {
 	return nil
 }
 
+// tenant returns the prefix of the tenant of the user of the request being applied.
+func (aa *authApplierV3) tenant() string {
+	return string(aa.as.UserPrefix(aa.authInfo.Username))
+}
+
+// checkLeaseTenant checks that a user bound to a tenant only uses the leases granted
+// to the tenant.
+func (aa *authApplierV3) checkLeaseTenant(leaseID lease.LeaseID) error {
+	if leaseID == lease.NoLease {
+		return nil
+	}
+	tenant := aa.tenant()
+	if len(tenant) == 0 {
+		return nil
+	}
+	if l := aa.lessor.Lookup(leaseID); l != nil && l.Tenant() != tenant {
+		return auth.ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkTxnLeaseTenant checks the leases of the puts of the transaction with checkLeaseTenant.
+func (aa *authApplierV3) checkTxnLeaseTenant(rt *pb.TxnRequest) error {
+	for _, reqs := range [][]*pb.RequestOp{rt.Success, rt.Failure} {
+		for _, requ := range reqs {
+			var err error
+			switch tv := requ.Request.(type) {
+			case *pb.RequestOp_RequestPut:
+				if tv.RequestPut != nil {
+					err = aa.checkLeaseTenant(lease.LeaseID(tv.RequestPut.Lease))
+				}
+			case *pb.RequestOp_RequestTxn:
+				if tv.RequestTxn != nil {
+					err = aa.checkTxnLeaseTenant(tv.RequestTxn)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (aa *authApplierV3) isDeletePermitted(ai *auth.AuthInfo, key []byte) error {
+	return aa.as.IsDeleteRangePermitted(ai, key, nil)
+}
+
 func (aa *authApplierV3) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetResponse, error) {
 	err := aa.as.IsAdminPermitted(&aa.authInfo)
 	if err != nil && r.Name != aa.authInfo.Username {
-		aa.authInfo.Username = ""
-		aa.authInfo.Revision = 0
+		aa.resetAuthInfo()
 		return &pb.AuthUserGetResponse{}, err
 	}
 
@@ -202,15 +321,25 @@ func (aa *authApplierV3) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetRespo
 
 func (aa *authApplierV3) RoleGet(r *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse, error) {
 	err := aa.as.IsAdminPermitted(&aa.authInfo)
-	if err != nil && !aa.as.HasRole(aa.authInfo.Username, r.Role) {
-		aa.authInfo.Username = ""
-		aa.authInfo.Revision = 0
+	if err != nil && !aa.as.HasRole(aa.authInfo.Username, r.Role) && !hasMappedRole(&aa.authInfo, r.Role) {
+		aa.resetAuthInfo()
 		return &pb.AuthRoleGetResponse{}, err
 	}
 
 	return aa.applierV3.RoleGet(r)
 }
 
+// hasMappedRole checks that the user is granted the role by the client
+// certificate rules.
+func hasMappedRole(ai *auth.AuthInfo, role string) bool {
+	for _, r := range ai.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 func needAdminPermission(r *pb.InternalRaftRequest) bool {
 	switch {
 	case r.AuthEnable != nil:
@@ -241,6 +370,14 @@ func needAdminPermission(r *pb.InternalRaftRequest) bool {
 		return true
 	case r.AuthRoleList != nil:
 		return true
+	case r.RetentionPut != nil:
+		return true
+	case r.RetentionDelete != nil:
+		return true
+	case r.SchemaPut != nil:
+		return true
+	case r.SchemaDelete != nil:
+		return true
 	default:
 		return false
 	}
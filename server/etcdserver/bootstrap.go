@@ -68,7 +68,7 @@ func bootstrap(cfg config.ServerConfig) (b *bootstrappedServer, err error) {
 		return nil, fmt.Errorf("cannot access member directory: %v", terr)
 	}
 	ss := bootstrapSnapshot(cfg)
-	prt, err := rafthttp.NewRoundTripper(cfg.PeerTLSInfo, cfg.PeerDialTimeout())
+	prt, err := rafthttp.NewRoundTripperWithDialer(cfg.PeerTLSInfo, cfg.PeerDialTimeout(), cfg.PeerDialer)
 	if err != nil {
 		return nil, err
 	}
@@ -337,6 +337,34 @@ func (a *applierV3Corrupt) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevo
 	return nil, ErrCorrupt
 }
 
+func (a *applierV3Corrupt) LeaseExpire(le *pb.LeaseExpireRequest) (*pb.LeaseRevokeResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) LeaseUpdate(lu *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) LeaseMove(lm *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) RetentionPut(rp *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) RetentionDelete(rd *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) SchemaPut(sp *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) SchemaDelete(sd *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error) {
+	return nil, ErrCorrupt
+}
+
 const PeerHashKVPath = "/members/hashkv"
 
 type hashKVHandler struct {
@@ -41,6 +41,8 @@ var (
 	ErrBadLeaderTransferee         = errors.New("etcdserver: bad leader transferee")
 	ErrClusterVersionUnavailable   = errors.New("etcdserver: cluster version not found during downgrade")
 	ErrWrongDowngradeVersionFormat = errors.New("etcdserver: wrong downgrade target version format")
+	ErrConfigReloadUnsupported     = errors.New("etcdserver: member cannot reload its configuration without a configuration file")
+	ErrStaleRead                   = errors.New("etcdserver: member is staler than requested")
 )
 
 type DiscoveryError struct {
@@ -170,6 +170,19 @@ var (
 		Buckets: prometheus.ExponentialBuckets(0.0001, 2, 20),
 	},
 		[]string{"version", "op", "success"})
+	configGeneration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "config_generation",
+		Help:      "The generation of the configuration of the member, incremented by the reloads applying changes.",
+	})
+	configReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "config_reloads_total",
+		Help:      "The total number of reloads of the configuration of the member.",
+	},
+		[]string{"result"})
 )
 
 func init() {
@@ -195,6 +208,8 @@ func init() {
 	prometheus.MustRegister(fdUsed)
 	prometheus.MustRegister(fdLimit)
 	prometheus.MustRegister(applySec)
+	prometheus.MustRegister(configGeneration)
+	prometheus.MustRegister(configReloads)
 
 	currentVersion.With(prometheus.Labels{
 		"server_version": version.Version,
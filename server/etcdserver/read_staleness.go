@@ -0,0 +1,217 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// maxLeaderContacts bounds the leader contacts kept while the member has not
+// applied their commit indexes. Dropping the oldest ones only makes the member
+// look staler than it is.
+const maxLeaderContacts = 1024
+
+// leaderContact is a message received from the leader, and the commit index
+// of the leader it carried.
+type leaderContact struct {
+	at     time.Time
+	commit uint64
+}
+
+// leaderContacts tracks the entries appended by the leader to bound the
+// staleness of the serializable reads of a follower: once the follower has
+// applied the commit index carried by a MsgApp, its data is at most as stale
+// as the message. The leader records its own commits.
+type leaderContacts struct {
+	mu sync.Mutex
+	// contacts are ordered by increasing commit index and time.
+	contacts []leaderContact
+	// lastHeard is the time of the last message received from the leader.
+	lastHeard time.Time
+}
+
+// heard records a message received from the leader at t.
+func (lc *leaderContacts) heard(t time.Time) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.lastHeard = t
+}
+
+// sinceHeard returns how long ago the last message of the leader was received.
+func (lc *leaderContacts) sinceHeard() time.Duration {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.lastHeard.IsZero() {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Since(lc.lastHeard)
+}
+
+// record records a contact with the leader at t, carrying the commit index commit.
+func (lc *leaderContacts) record(t time.Time, commit uint64) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if n := len(lc.contacts); n > 0 {
+		last := &lc.contacts[n-1]
+		if commit < last.commit {
+			// reordered messages of the leader
+			return
+		}
+		if commit == last.commit {
+			last.at = t
+			return
+		}
+	}
+	if len(lc.contacts) == maxLeaderContacts {
+		lc.contacts = append(lc.contacts[:0], lc.contacts[1:]...)
+	}
+	lc.contacts = append(lc.contacts, leaderContact{at: t, commit: commit})
+}
+
+// freshAt returns the time of the latest contact with the leader whose commit
+// index is applied, or false if there is none.
+func (lc *leaderContacts) freshAt(applied uint64) (time.Time, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	i := 0
+	for i < len(lc.contacts) && lc.contacts[i].commit <= applied {
+		i++
+	}
+	if i == 0 {
+		return time.Time{}, false
+	}
+	// the contacts before the latest applied one are not needed anymore.
+	lc.contacts = lc.contacts[i-1:]
+	return lc.contacts[0].at, true
+}
+
+// observeLeaderMessage records the commit index of the leader of the current
+// term carried by its MsgApp. The commit index of a MsgHeartbeat is capped by
+// the entries the leader knows the member has, so it does not tell how far
+// behind a lagging member is.
+func (s *EtcdServer) observeLeaderMessage(m raftpb.Message) {
+	if m.Type != raftpb.MsgApp && m.Type != raftpb.MsgHeartbeat {
+		return
+	}
+	if m.Term < s.getTerm() {
+		return
+	}
+	now := time.Now()
+	s.leaderContacts.heard(now)
+	if m.Type == raftpb.MsgApp {
+		s.leaderContacts.record(now, m.Commit)
+	}
+}
+
+// checkReadStaleness bounds the staleness of a serializable range request by
+// its min_revision and max_staleness_ms, returning ErrStaleRead if the member
+// is staler than requested.
+func (s *EtcdServer) checkReadStaleness(ctx context.Context, r *pb.RangeRequest) error {
+	if r.MinRevision > 0 {
+		if err := s.waitAppliedRevision(ctx, r.MinRevision); err != nil {
+			return err
+		}
+	}
+	if r.MaxStalenessMs > 0 {
+		maxStaleness := time.Duration(r.MaxStalenessMs) * time.Millisecond
+		if s.staleness() > maxStaleness {
+			return s.confirmFreshness(ctx, maxStaleness)
+		}
+	}
+	return nil
+}
+
+// checkTxnReadStaleness bounds the staleness of a serializable read-only
+// transaction by the min_revision and max_staleness_ms of its range requests.
+func (s *EtcdServer) checkTxnReadStaleness(ctx context.Context, r *pb.TxnRequest) error {
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			rr := op.GetRequestRange()
+			if rr == nil || (rr.MinRevision == 0 && rr.MaxStalenessMs == 0) {
+				continue
+			}
+			if err := s.checkReadStaleness(ctx, rr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// confirmFreshness confirms with the leader that the member is up to date,
+// returning ErrStaleRead if it cannot within maxStaleness. The leader of an
+// idle cluster only sends heartbeats, which do not bound the staleness.
+func (s *EtcdServer) confirmFreshness(ctx context.Context, maxStaleness time.Duration) error {
+	if s.leaderContacts.sinceHeard() > maxStaleness {
+		// cut off from the leader
+		return ErrStaleRead
+	}
+	cctx, cancel := context.WithTimeout(ctx, maxStaleness)
+	defer cancel()
+	err := s.linearizableReadNotify(cctx)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == ErrStopped {
+		return err
+	}
+	return ErrStaleRead
+}
+
+// staleness returns how long ago the data of the member was known to be up
+// to date with the leader.
+func (s *EtcdServer) staleness() time.Duration {
+	applied := s.getAppliedIndex()
+	if s.isLeader() && applied >= s.getCommittedIndex() {
+		// a leader cut off from the quorum steps down after an election
+		// timeout, which bounds the staleness of its committed index.
+		return 0
+	}
+	at, ok := s.leaderContacts.freshAt(applied)
+	if !ok {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Since(at)
+}
+
+// waitAppliedRevision waits until the member has applied the revision rev,
+// for at most the request timeout.
+func (s *EtcdServer) waitAppliedRevision(ctx context.Context, rev int64) error {
+	if s.KV().Rev() >= rev {
+		return nil
+	}
+	timer := time.NewTimer(s.Cfg.ReqTimeout())
+	defer timer.Stop()
+	for s.KV().Rev() < rev {
+		select {
+		case <-s.applyWait.Wait(s.getAppliedIndex() + 1):
+		case <-timer.C:
+			return ErrStaleRead
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.stopping:
+			return ErrStopped
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestLeaderContacts(t *testing.T) {
+	var lc leaderContacts
+	if _, ok := lc.freshAt(10); ok {
+		t.Fatal("expected no fresh contact without leader contacts")
+	}
+
+	t0 := time.Unix(100, 0)
+	lc.record(t0, 5)
+	lc.record(t0.Add(time.Second), 8)
+	// reordered messages with a lower commit index do not refresh the contacts
+	lc.record(t0.Add(2*time.Second), 3)
+	// contacts with the same commit index refresh the latest one
+	lc.record(t0.Add(3*time.Second), 8)
+
+	tests := []struct {
+		applied uint64
+		wAt     time.Time
+		wOk     bool
+	}{
+		{4, time.Time{}, false},
+		{5, t0, true},
+		{7, t0, true},
+		{8, t0.Add(3 * time.Second), true},
+		{9, t0.Add(3 * time.Second), true},
+	}
+	for i, tt := range tests {
+		at, ok := lc.freshAt(tt.applied)
+		if ok != tt.wOk || !at.Equal(tt.wAt) {
+			t.Errorf("#%d: freshAt(%d) = %v, %v, want %v, %v", i, tt.applied, at, ok, tt.wAt, tt.wOk)
+		}
+	}
+}
+
+func TestLeaderContactsBounded(t *testing.T) {
+	var lc leaderContacts
+	t0 := time.Unix(100, 0)
+	for i := 1; i <= maxLeaderContacts+10; i++ {
+		lc.record(t0.Add(time.Duration(i)*time.Second), uint64(i))
+	}
+	if n := len(lc.contacts); n != maxLeaderContacts {
+		t.Fatalf("expected %d contacts, got %d", maxLeaderContacts, n)
+	}
+	if _, ok := lc.freshAt(10); ok {
+		t.Fatal("expected the oldest contacts to be dropped")
+	}
+	at, ok := lc.freshAt(maxLeaderContacts + 10)
+	if !ok || !at.Equal(t0.Add(time.Duration(maxLeaderContacts+10)*time.Second)) {
+		t.Fatalf("unexpected latest contact %v, %v", at, ok)
+	}
+}
+
+// TestStalenessLaggingFollower ensures the heartbeats of the leader do not make
+// a follower held behind the leader look up to date: their commit index is
+// capped by the entries of the follower.
+func TestStalenessLaggingFollower(t *testing.T) {
+	s := &EtcdServer{id: 1}
+	s.setLead(2)
+	s.setTerm(3)
+
+	// the follower applied the commit index of the last append it received
+	s.observeLeaderMessage(raftpb.Message{Type: raftpb.MsgApp, Term: 3, Commit: 10})
+	s.setAppliedIndex(10)
+	if st := s.staleness(); st > time.Second {
+		t.Fatalf("expected an up to date follower, got staleness %v", st)
+	}
+
+	// the leader moves on without appending to the follower, and sends it
+	// heartbeats with the commit index capped by its match index
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		s.observeLeaderMessage(raftpb.Message{Type: raftpb.MsgHeartbeat, Term: 3, Commit: 10})
+	}
+	if st := s.staleness(); st < 100*time.Millisecond {
+		t.Fatalf("expected a follower held behind to look stale, got staleness %v", st)
+	}
+}
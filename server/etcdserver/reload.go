@@ -0,0 +1,78 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.uber.org/zap"
+)
+
+// ConfigReloadReport reports the changed fields of a configuration reload.
+type ConfigReloadReport struct {
+	// Applied are the changed fields applied to the running member.
+	Applied []string
+	// Ignored are the changed fields that need a restart of the member.
+	Ignored []string
+}
+
+// ConfigReloader validates the configuration of the member read again from its
+// source and, unless dryRun, applies its reloadable fields. Nothing is applied
+// if the configuration is not valid.
+type ConfigReloader func(dryRun bool) (*ConfigReloadReport, error)
+
+// SetConfigReloader sets the function reloading the configuration of the member.
+func (s *EtcdServer) SetConfigReloader(reload ConfigReloader) {
+	s.configReloadMu.Lock()
+	defer s.configReloadMu.Unlock()
+	s.configReloader = reload
+}
+
+// ReloadConfig reloads the configuration of the member, incrementing its
+// generation if changes were applied.
+func (s *EtcdServer) ReloadConfig(ctx context.Context, r *pb.ReloadConfigRequest) (*pb.ReloadConfigResponse, error) {
+	s.configReloadMu.Lock()
+	defer s.configReloadMu.Unlock()
+	if s.configReloader == nil {
+		return nil, ErrConfigReloadUnsupported
+	}
+
+	report, err := s.configReloader(r.DryRun)
+	if err != nil {
+		configReloads.WithLabelValues("failure").Inc()
+		s.Logger().Warn("failed to reload configuration", zap.Bool("dry-run", r.DryRun), zap.Error(err))
+		return nil, err
+	}
+	if !r.DryRun {
+		configReloads.WithLabelValues("success").Inc()
+		if len(report.Applied) > 0 {
+			s.configGeneration++
+		}
+		configGeneration.Set(float64(s.configGeneration))
+		s.Logger().Info(
+			"reloaded configuration",
+			zap.Uint64("generation", s.configGeneration),
+			zap.Strings("applied", report.Applied),
+			zap.Strings("ignored", report.Ignored),
+		)
+	}
+	return &pb.ReloadConfigResponse{
+		Header:     &pb.ResponseHeader{},
+		Generation: s.configGeneration,
+		Applied:    report.Applied,
+		Ignored:    report.Ignored,
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// RetentionPut sends RetentionPut request to raft and apply it after committed.
+func (s *EtcdServer) RetentionPut(ctx context.Context, r *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{RetentionPut: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RetentionPutResponse), nil
+}
+
+// RetentionDelete sends RetentionDelete request to raft and apply it after committed.
+func (s *EtcdServer) RetentionDelete(ctx context.Context, r *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{RetentionDelete: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RetentionDeleteResponse), nil
+}
+
+// RetentionList lists the retention rules once the member caught up with the leader.
+func (s *EtcdServer) RetentionList(ctx context.Context, r *pb.RetentionListRequest) (*pb.RetentionListResponse, error) {
+	if err := s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+	resp := &pb.RetentionListResponse{Header: newHeader(s)}
+	for _, rule := range s.KV().RetentionRules() {
+		resp.Rules = append(resp.Rules, &pb.RetentionRule{
+			Prefix:          rule.Prefix,
+			Revisions:       rule.Revisions,
+			DurationSeconds: int64(rule.Duration / time.Second),
+			CompactRevision: rule.CompactRev,
+		})
+	}
+	return resp, nil
+}
+
+// retentionCompactions resolves the revisions to compact the retention rules
+// at, which are applied the same on every member. The rules of a duration are
+// resolved by the retention marks recorded by the compactions, and are not
+// compacted until a mark older than their duration is recorded.
+func (s *EtcdServer) retentionCompactions() []*pb.RetentionCompaction {
+	var rcs []*pb.RetentionCompaction
+	rev, now := s.KV().Rev(), time.Now()
+	for _, rule := range s.KV().RetentionRules() {
+		crev := rev - rule.Revisions
+		if rule.Duration > 0 {
+			drev, ok := s.KV().RetentionRevAt(now.Add(-rule.Duration))
+			if !ok {
+				continue
+			}
+			// the longest history of both is kept
+			if rule.Revisions == 0 || drev < crev {
+				crev = drev
+			}
+		}
+		if crev <= rule.CompactRev {
+			continue
+		}
+		rcs = append(rcs, &pb.RetentionCompaction{Prefix: rule.Prefix, Revision: crev})
+	}
+	return rcs
+}
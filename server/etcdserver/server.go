@@ -59,6 +59,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v2store"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3schema"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/lease"
@@ -261,6 +262,8 @@ type EtcdServer struct {
 	beHooks    *serverstorage.BackendHooks
 	authStore  auth.AuthStore
 	alarmStore *v3alarm.AlarmStore
+	// schemaStore validates the values written under key prefixes.
+	schemaStore *v3schema.SchemaStore
 
 	stats  *stats.ServerStats
 	lstats *stats.LeaderStats
@@ -287,9 +290,17 @@ type EtcdServer struct {
 	leadTimeMu      sync.RWMutex
 	leadElectedTime time.Time
 
+	// leaderContacts bounds the staleness of the serializable reads.
+	leaderContacts leaderContacts
+
 	firstCommitInTerm     *notify.Notifier
 	clusterVersionChanged *notify.Notifier
 
+	// configReloadMu serializes the reloads of the configuration.
+	configReloadMu   sync.Mutex
+	configReloader   ConfigReloader
+	configGeneration uint64
+
 	*AccessController
 }
 
@@ -332,8 +343,10 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		consistIndex:          b.storage.backend.ci,
 		firstCommitInTerm:     notify.NewNotifier(),
 		clusterVersionChanged: notify.NewNotifier(),
+		configGeneration:      1,
 	}
 	serverID.With(prometheus.Labels{"server_id": b.cluster.nodeID.String()}).Set(1)
+	configGeneration.Set(float64(srv.configGeneration))
 	srv.cluster.SetVersionChangedNotifier(srv.clusterVersionChanged)
 	srv.applyV2 = NewApplierV2(cfg.Logger, srv.v2store, srv.cluster)
 
@@ -361,13 +374,31 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		return nil, err
 	}
 
+	var certRules *auth.ClientCertRules
+	if cfg.ExperimentalClientCertAuthRulesFile != "" {
+		if certRules, err = auth.ReadClientCertRules(cfg.ExperimentalClientCertAuthRulesFile); err != nil {
+			cfg.Logger.Warn("failed to read client certificate rules", zap.Error(err))
+			return nil, err
+		}
+	}
+
 	mvccStoreConfig := mvcc.StoreConfig{
 		CompactionBatchLimit:    cfg.CompactionBatchLimit,
 		CompactionSleepInterval: cfg.CompactionSleepInterval,
 	}
+	for _, s := range cfg.ExperimentalSecondaryIndexes {
+		si, err := mvcc.ParseSecondaryIndex(s)
+		if err != nil {
+			return nil, err
+		}
+		mvccStoreConfig.SecondaryIndexes = append(mvccStoreConfig.SecondaryIndexes, si)
+	}
 	srv.kv = mvcc.New(srv.Logger(), srv.be, srv.lessor, mvccStoreConfig)
 
 	srv.authStore = auth.NewAuthStore(srv.Logger(), schema.NewAuthBackend(srv.Logger(), srv.be), tp, int(cfg.BcryptCost))
+	if certRules != nil {
+		srv.authStore.SetClientCertRules(certRules)
+	}
 
 	newSrv := srv // since srv == nil in defer if srv is returned as nil
 	defer func() {
@@ -378,9 +409,17 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		}
 	}()
 	if num := cfg.AutoCompactionRetention; num != 0 {
-		srv.compactor, err = v3compactor.New(cfg.Logger, cfg.AutoCompactionMode, num, srv.kv, srv)
-		if err != nil {
-			return nil, err
+		if cfg.AutoCompactionMode == v3compactor.ModeAdaptive {
+			th := v3compactor.AdaptiveThresholds{
+				MaxRevisions:      cfg.ExperimentalAutoCompactionMaxRevisions,
+				MaxSizeInUseBytes: cfg.ExperimentalAutoCompactionMaxDBSizeInUseBytes,
+			}
+			srv.compactor = v3compactor.NewAdaptive(cfg.Logger, num, th, &compactionStatsAdapter{srv}, srv)
+		} else {
+			srv.compactor, err = v3compactor.New(cfg.Logger, cfg.AutoCompactionMode, num, srv.kv, srv)
+			if err != nil {
+				return nil, err
+			}
 		}
 		srv.compactor.Run()
 	}
@@ -390,6 +429,9 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 	if err = srv.restoreAlarms(); err != nil {
 		return nil, err
 	}
+	if err = srv.restoreValueSchemas(); err != nil {
+		return nil, err
+	}
 
 	if srv.Cfg.EnableLeaseCheckpoint {
 		// setting checkpointer enables lease checkpoint feature.
@@ -403,6 +445,7 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		Logger:      cfg.Logger,
 		TLSInfo:     cfg.PeerTLSInfo,
 		DialTimeout: cfg.PeerDialTimeout(),
+		Dialer:      cfg.PeerDialer,
 		ID:          b.cluster.nodeID,
 		URLs:        cfg.PeerURLs,
 		ClusterID:   b.cluster.cl.ID(),
@@ -521,6 +564,7 @@ func (s *EtcdServer) Start() {
 	s.GoAttach(s.linearizableReadLoop)
 	s.GoAttach(s.monitorKVHash)
 	s.GoAttach(s.monitorDowngrade)
+	s.GoAttach(s.monitorAutoDefrag)
 }
 
 // start prepares and starts server in a new goroutine. It is no longer safe to
@@ -695,6 +739,7 @@ func (s *EtcdServer) Process(ctx context.Context, m raftpb.Message) error {
 	if m.Type == raftpb.MsgApp {
 		s.stats.RecvAppendReq(types.ID(m.From).String(), m.Size())
 	}
+	s.observeLeaderMessage(m)
 	return s.r.Step(ctx, m)
 }
 
@@ -788,6 +833,9 @@ func (s *EtcdServer) run() {
 			cci := s.getCommittedIndex()
 			if ci > cci {
 				s.setCommittedIndex(ci)
+				if s.isLeader() {
+					s.leaderContacts.record(time.Now(), ci)
+				}
 			}
 		},
 	}
@@ -844,7 +892,7 @@ func (s *EtcdServer) run() {
 					lid := lease.ID
 					s.GoAttach(func() {
 						ctx := s.authStore.WithRoot(s.ctx)
-						_, lerr := s.LeaseRevoke(ctx, &pb.LeaseRevokeRequest{ID: int64(lid)})
+						lerr := s.leaseExpire(ctx, lid)
 						if lerr == nil {
 							leaseExpired.Inc()
 						} else {
@@ -1005,6 +1053,14 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, apply *apply) {
 
 	lg.Info("restored alarm store")
 
+	lg.Info("restoring value schema store")
+
+	if err := s.restoreValueSchemas(); err != nil {
+		lg.Panic("failed to restore value schema store", zap.Error(err))
+	}
+
+	lg.Info("restored value schema store")
+
 	if s.authStore != nil {
 		lg.Info("restoring auth store")
 
@@ -2251,6 +2307,53 @@ func (s *EtcdServer) monitorDowngrade() {
 	}
 }
 
+// monitorAutoDefrag defragments the backend when the ratio of its size in use
+// to its size falls below ExperimentalAutoDefragInUseRatio. Every member
+// checks and defragments its own backend.
+func (s *EtcdServer) monitorAutoDefrag() {
+	if s.Cfg.ExperimentalAutoDefragInUseRatio == 0 {
+		return
+	}
+	lg := s.Logger()
+	if !s.Cfg.ExperimentalOnlineDefrag {
+		// an offline defragmentation blocks the member
+		lg.Warn("automatic defragmentation requires online defragmentation; disabled")
+		return
+	}
+	for {
+		// the members growing alike do not all defragment at once
+		interval := s.Cfg.ExperimentalAutoDefragCheckInterval
+		jitter := time.Duration(rand.Int63n(int64(interval)/2 + 1))
+		select {
+		case <-time.After(interval + jitter):
+		case <-s.stopping:
+			return
+		}
+
+		be := s.Backend()
+		size, sizeInUse := be.Size(), be.SizeInUse()
+		if !shouldAutoDefrag(s.Cfg, size, sizeInUse) {
+			continue
+		}
+		lg.Info(
+			"starting automatic defragmentation",
+			zap.Int64("current-db-size-bytes", size),
+			zap.Int64("current-db-size-in-use-bytes", sizeInUse),
+			zap.Float64("experimental-auto-defrag-in-use-ratio", s.Cfg.ExperimentalAutoDefragInUseRatio),
+		)
+		if err := be.Defrag(); err != nil {
+			lg.Warn("failed to defragment automatically", zap.Error(err))
+		}
+	}
+}
+
+func shouldAutoDefrag(cfg config.ServerConfig, size, sizeInUse int64) bool {
+	if size <= 0 || uint(size-sizeInUse) < cfg.ExperimentalAutoDefragMinFreeMegabytes*1024*1024 {
+		return false
+	}
+	return float64(sizeInUse)/float64(size) < cfg.ExperimentalAutoDefragInUseRatio
+}
+
 func (s *EtcdServer) parseProposeCtxErr(err error, start time.Time) error {
 	switch err {
 	case context.Canceled:
@@ -2293,6 +2396,15 @@ func (s *EtcdServer) Backend() backend.Backend {
 
 func (s *EtcdServer) AuthStore() auth.AuthStore { return s.authStore }
 
+func (s *EtcdServer) restoreValueSchemas() error {
+	ss, err := v3schema.NewSchemaStore(s.lg, schema.NewValueSchemaBackend(s.lg, s.be))
+	if err != nil {
+		return err
+	}
+	s.schemaStore = ss
+	return nil
+}
+
 func (s *EtcdServer) restoreAlarms() error {
 	s.applyV3 = s.newApplierV3()
 	as, err := v3alarm.NewAlarmStore(s.lg, schema.NewAlarmBackend(s.lg, s.be))
@@ -32,6 +32,20 @@ func NewAccessController() *AccessController {
 	}
 }
 
+// SetCORS replaces the allowed CORS origins.
+func (ac *AccessController) SetCORS(cors map[string]struct{}) {
+	ac.corsMu.Lock()
+	defer ac.corsMu.Unlock()
+	ac.CORS = cors
+}
+
+// SetHostWhitelist replaces the whitelisted hosts.
+func (ac *AccessController) SetHostWhitelist(hosts map[string]struct{}) {
+	ac.hostWhitelistMu.Lock()
+	defer ac.hostWhitelistMu.Unlock()
+	ac.HostWhitelist = hosts
+}
+
 // OriginAllowed determines whether the server will allow a given CORS origin.
 // If CORS is empty, allow all.
 func (ac *AccessController) OriginAllowed(origin string) bool {
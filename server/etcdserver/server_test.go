@@ -2040,3 +2040,31 @@ func (s *sendMsgAppRespTransporter) Send(m []raftpb.Message) {
 	}
 	s.sendC <- send
 }
+
+func TestShouldAutoDefrag(t *testing.T) {
+	tests := []struct {
+		name           string
+		ratio          float64
+		minFreeMB      uint
+		size           int64
+		sizeInUse      int64
+		expectedDefrag bool
+	}{
+		{name: "empty backend", ratio: 0.5, size: 0, sizeInUse: 0},
+		{name: "ratio above threshold", ratio: 0.5, size: 100, sizeInUse: 60},
+		{name: "ratio below threshold", ratio: 0.5, size: 100, sizeInUse: 40, expectedDefrag: true},
+		{name: "not enough to free", ratio: 0.5, minFreeMB: 1, size: 1024 * 1024, sizeInUse: 1024},
+		{name: "enough to free", ratio: 0.5, minFreeMB: 1, size: 4 * 1024 * 1024, sizeInUse: 1024, expectedDefrag: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.ServerConfig{
+				ExperimentalAutoDefragInUseRatio:       tc.ratio,
+				ExperimentalAutoDefragMinFreeMegabytes: tc.minFreeMB,
+			}
+			if got := shouldAutoDefrag(cfg, tc.size, tc.sizeInUse); got != tc.expectedDefrag {
+				t.Errorf("shouldAutoDefrag() = %v, want %v", got, tc.expectedDefrag)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"sort"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// tenantStatusBatchLimit is the number of keys read at once to account a tenant.
+const tenantStatusBatchLimit = 1000
+
+// TenantStatus accounts the keys of each tenant once the member caught up with the leader.
+func (s *EtcdServer) TenantStatus(ctx context.Context, r *pb.TenantStatusRequest) (*pb.TenantStatusResponse, error) {
+	if err := s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+
+	tenants := s.AuthStore().Tenants()
+	prefixes := make([]string, 0, len(tenants))
+	for prefix := range tenants {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	txn := s.KV().Read(mvcc.ConcurrentReadTxMode, traceutil.TODO())
+	defer txn.End()
+
+	resp := &pb.TenantStatusResponse{Header: newHeader(s)}
+	for _, prefix := range prefixes {
+		users := tenants[prefix]
+		sort.Strings(users)
+		st := &pb.TenantStatus{Prefix: []byte(prefix), Users: users}
+		if err := accountTenant(ctx, txn, st); err != nil {
+			return nil, err
+		}
+		resp.Tenants = append(resp.Tenants, st)
+	}
+	return resp, nil
+}
+
+// accountTenant counts the keys of the tenant and their size, reading them in batches.
+func accountTenant(ctx context.Context, txn mvcc.TxnRead, st *pb.TenantStatus) error {
+	key, end := st.Prefix, mkGteRange(keyutil.PrefixRangeEnd(st.Prefix))
+	for {
+		rr, err := txn.Range(ctx, key, end, mvcc.RangeOptions{Limit: tenantStatusBatchLimit, Rev: txn.Rev()})
+		if err != nil {
+			return err
+		}
+		for _, kv := range rr.KVs {
+			st.KeyCount++
+			st.SizeBytes += int64(len(kv.Key) + len(kv.Value))
+		}
+		if len(rr.KVs) < tenantStatusBatchLimit {
+			return nil
+		}
+		last := rr.KVs[len(rr.KVs)-1].Key
+		key = append(append(make([]byte, 0, len(last)+1), last...), 0)
+	}
+}
+
+// LeaseTenant returns the prefix of the tenant owning the lease, false if the lease
+// does not exist.
+func (s *EtcdServer) LeaseTenant(id lease.LeaseID) (string, bool) {
+	l := s.lessor.Lookup(id)
+	if l == nil {
+		return "", false
+	}
+	return l.Tenant(), true
+}
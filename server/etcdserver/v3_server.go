@@ -31,6 +31,7 @@ import (
 	"go.etcd.io/etcd/server/v3/lease/leasehttp"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/gogo/protobuf/proto"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
@@ -46,6 +47,9 @@ const (
 	readIndexRetryTime               = 500 * time.Millisecond
 )
 
+// leaseExpireVersion is the minimum cluster version applying LeaseExpireRequests.
+var leaseExpireVersion = semver.Version{Major: 3, Minor: 6}
+
 type RaftKV interface {
 	Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error)
 	Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error)
@@ -60,15 +64,28 @@ type Lessor interface {
 	// LeaseRevoke sends LeaseRevoke request to raft and apply it after committed.
 	LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
 
+	// LeaseUpdate sends LeaseUpdate request to raft and apply it after committed.
+	LeaseUpdate(ctx context.Context, r *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error)
+	// LeaseMove sends LeaseMove request to raft and apply it after committed.
+	LeaseMove(ctx context.Context, r *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error)
+
 	// LeaseRenew renews the lease with given ID. The renewed TTL is returned. Or an error
 	// is returned.
 	LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, error)
 
+	// LeaseRenewBatch renews the leases with given IDs. The renewed TTLs are returned in the
+	// order of the IDs, with a TTL of 0 for leases that do not exist. Or an error is returned.
+	LeaseRenewBatch(ctx context.Context, ids []lease.LeaseID) ([]int64, error)
+
 	// LeaseTimeToLive retrieves lease information.
 	LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error)
 
 	// LeaseLeases lists all leases.
 	LeaseLeases(ctx context.Context, r *pb.LeaseLeasesRequest) (*pb.LeaseLeasesResponse, error)
+
+	// LeaseEvents returns a chan receiving the lease events of this member accepted by
+	// filter, and a function stopping the watch. The chan is closed once the watch stops.
+	LeaseEvents(filter func(lease.Event) bool) (<-chan lease.Event, func())
 }
 
 type Authenticator interface {
@@ -118,6 +135,12 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 		if err != nil {
 			return nil, err
 		}
+	} else if r.MinRevision > 0 || r.MaxStalenessMs > 0 {
+		err = s.checkReadStaleness(ctx, r)
+		trace.Step("check the staleness of the member before serializable reading")
+		if err != nil {
+			return nil, err
+		}
 	}
 	chk := func(ai *auth.AuthInfo) error {
 		return s.authStore.IsRangePermitted(ai, r.Key, r.RangeEnd)
@@ -161,6 +184,8 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 			if err != nil {
 				return nil, err
 			}
+		} else if err := s.checkTxnReadStaleness(ctx, r); err != nil {
+			return nil, err
 		}
 		var resp *pb.TxnResponse
 		var err error
@@ -218,6 +243,8 @@ func isTxnReadonly(r *pb.TxnRequest) bool {
 
 func (s *EtcdServer) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
 	startTime := time.Now()
+	r.RetentionCompactions = s.retentionCompactions()
+	r.RetentionMark = &pb.RetentionMark{Revision: s.KV().Rev(), Time: startTime.UnixNano()}
 	result, err := s.processInternalRaftRequestOnce(ctx, pb.InternalRaftRequest{Compaction: r})
 	trace := traceutil.TODO()
 	if result != nil && result.trace != nil {
@@ -278,6 +305,33 @@ func (s *EtcdServer) LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest)
 	return resp.(*pb.LeaseRevokeResponse), nil
 }
 
+// leaseExpire revokes an expired lease. Members report the revocation as an
+// expiry once they all support LeaseExpireRequests.
+func (s *EtcdServer) leaseExpire(ctx context.Context, id lease.LeaseID) error {
+	if cv := s.ClusterVersion(); cv == nil || cv.LessThan(leaseExpireVersion) {
+		_, err := s.LeaseRevoke(ctx, &pb.LeaseRevokeRequest{ID: int64(id)})
+		return err
+	}
+	_, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseExpire: &pb.LeaseExpireRequest{ID: int64(id)}})
+	return err
+}
+
+func (s *EtcdServer) LeaseUpdate(ctx context.Context, r *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseUpdate: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.LeaseUpdateResponse), nil
+}
+
+func (s *EtcdServer) LeaseMove(ctx context.Context, r *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseMove: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.LeaseMoveResponse), nil
+}
+
 func (s *EtcdServer) LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, error) {
 	ttl, err := s.lessor.Renew(id)
 	if err == nil { // already requested to primary lessor(leader)
@@ -313,6 +367,60 @@ func (s *EtcdServer) LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, e
 	return -1, ErrCanceled
 }
 
+func (s *EtcdServer) LeaseRenewBatch(ctx context.Context, ids []lease.LeaseID) ([]int64, error) {
+	ttls, err := s.lessor.RenewBatch(ids)
+	if err == nil { // already requested to primary lessor(leader)
+		return ttls, nil
+	}
+	if err != lease.ErrNotPrimary {
+		return nil, err
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, s.Cfg.ReqTimeout())
+	defer cancel()
+
+	// renewals don't go through raft; forward to leader manually
+	for cctx.Err() == nil {
+		leader, lerr := s.waitLeader(cctx)
+		if lerr != nil {
+			return nil, lerr
+		}
+		for _, url := range leader.PeerURLs {
+			lurl := url + leasehttp.LeaseBatchPrefix
+			ttls, err = leasehttp.RenewBatchHTTP(cctx, ids, lurl, s.peerRt)
+			if err == nil {
+				return ttls, nil
+			}
+			if err == leasehttp.ErrLeaseBatchNotSupported {
+				return s.leaseRenewEach(ctx, ids)
+			}
+		}
+		// Throttle in case of e.g. connection problems.
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if cctx.Err() == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+	return nil, ErrCanceled
+}
+
+// leaseRenewEach renews the leases one by one, for leaders not serving batched renewals.
+func (s *EtcdServer) leaseRenewEach(ctx context.Context, ids []lease.LeaseID) ([]int64, error) {
+	ttls := make([]int64, len(ids))
+	for i, id := range ids {
+		ttl, err := s.LeaseRenew(ctx, id)
+		if err == lease.ErrLeaseNotFound {
+			ttl, err = 0, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ttls[i] = ttl
+	}
+	return ttls, nil
+}
+
 func (s *EtcdServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	if s.Leader() == s.ID() {
 		// primary; timetolive directly from leader
@@ -369,6 +477,10 @@ func (s *EtcdServer) LeaseLeases(ctx context.Context, r *pb.LeaseLeasesRequest)
 	return &pb.LeaseLeasesResponse{Header: newHeader(s), Leases: lss}, nil
 }
 
+func (s *EtcdServer) LeaseEvents(filter func(lease.Event) bool) (<-chan lease.Event, func()) {
+	return s.lessor.WatchEvents(filter)
+}
+
 func (s *EtcdServer) waitLeader(ctx context.Context) (*membership.Member, error) {
 	leader := s.cluster.Member(s.Leader())
 	for leader == nil {
@@ -659,6 +771,7 @@ func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.In
 		if authInfo != nil {
 			r.Header.Username = authInfo.Username
 			r.Header.AuthRevision = authInfo.Revision
+			r.Header.Roles = authInfo.Roles
 		}
 	}
 
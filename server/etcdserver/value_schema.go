@@ -0,0 +1,64 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3schema"
+)
+
+// SchemaPut checks the schema, sends SchemaPut request to raft and apply it after committed.
+func (s *EtcdServer) SchemaPut(ctx context.Context, r *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error) {
+	// reject invalid schemas before proposing them, with the reason they are invalid
+	if err := v3schema.Compile(r.Schema); err != nil {
+		return nil, err
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{SchemaPut: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.SchemaPutResponse), nil
+}
+
+// SchemaDelete sends SchemaDelete request to raft and apply it after committed.
+func (s *EtcdServer) SchemaDelete(ctx context.Context, r *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{SchemaDelete: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.SchemaDeleteResponse), nil
+}
+
+// SchemaGet gets the schema of a prefix once the member caught up with the leader.
+func (s *EtcdServer) SchemaGet(ctx context.Context, r *pb.SchemaGetRequest) (*pb.SchemaGetResponse, error) {
+	if err := s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+	vs, err := s.schemaStore.Get(r.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SchemaGetResponse{Header: newHeader(s), Schema: vs}, nil
+}
+
+// SchemaList lists the schemas once the member caught up with the leader.
+func (s *EtcdServer) SchemaList(ctx context.Context, r *pb.SchemaListRequest) (*pb.SchemaListResponse, error) {
+	if err := s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+	return &pb.SchemaListResponse{Header: newHeader(s), Schemas: s.schemaStore.List()}, nil
+}
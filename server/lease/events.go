@@ -0,0 +1,114 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease
+
+import "sync"
+
+// EventType is the kind of change of a lease.
+type EventType int
+
+const (
+	// EventGranted is sent when a lease is granted.
+	EventGranted EventType = iota
+	// EventRenewed is sent when the primary lessor renews a lease.
+	EventRenewed
+	// EventRevoked is sent when a lease is revoked.
+	EventRevoked
+	// EventExpired is sent when a lease is revoked because it expired.
+	EventExpired
+)
+
+// Event is a change of a lease.
+type Event struct {
+	Type EventType
+	ID   LeaseID
+	// Tenant is the prefix of the tenant owning the lease.
+	Tenant string
+	// TTL is the time-to-live of the lease in seconds.
+	TTL int64
+	// RemainingTTL is the remaining time-to-live in seconds of a renewed lease
+	// before its renewal.
+	RemainingTTL int64
+}
+
+// maximum number of events buffered for a watcher; a watcher falling further
+// behind is stopped. Configurable for tests.
+var eventBufferSize = 1024
+
+type eventWatcher struct {
+	filter func(Event) bool
+	ch     chan Event
+}
+
+// eventHub sends lease events to their watchers without blocking the lessor.
+type eventHub struct {
+	mu       sync.Mutex
+	watchers map[*eventWatcher]struct{}
+	stopped  bool
+}
+
+func (h *eventHub) watch(filter func(Event) bool) (<-chan Event, func()) {
+	w := &eventWatcher{filter: filter, ch: make(chan Event, eventBufferSize)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		close(w.ch)
+		return w.ch, func() {}
+	}
+	if h.watchers == nil {
+		h.watchers = make(map[*eventWatcher]struct{})
+	}
+	h.watchers[w] = struct{}{}
+
+	return w.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(w)
+	}
+}
+
+func (h *eventHub) remove(w *eventWatcher) {
+	if _, ok := h.watchers[w]; ok {
+		delete(h.watchers, w)
+		close(w.ch)
+	}
+}
+
+func (h *eventHub) send(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for w := range h.watchers {
+		if w.filter != nil && !w.filter(ev) {
+			continue
+		}
+		select {
+		case w.ch <- ev:
+		default:
+			// stop the watcher rather than blocking the lessor
+			h.remove(w)
+		}
+	}
+}
+
+// stop stops all watchers and rejects new ones.
+func (h *eventHub) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for w := range h.watchers {
+		h.remove(w)
+	}
+	h.stopped = true
+}
@@ -32,8 +32,11 @@ import (
 var (
 	LeasePrefix         = "/leases"
 	LeaseInternalPrefix = "/leases/internal"
+	LeaseBatchPrefix    = "/leases/batch"
 	applyTimeout        = time.Second
 	ErrLeaseHTTPTimeout = errors.New("waiting for node to catch up its applied index has timed out")
+
+	ErrLeaseBatchNotSupported = errors.New("lease: batched renewal is not supported by the leader")
 )
 
 // NewHandler returns an http Handler for lease renewals
@@ -91,6 +94,35 @@ func (h *leaseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+	case LeaseBatchPrefix:
+		lreq := pb.LeaseKeepAliveBatchRequest{}
+		if uerr := lreq.Unmarshal(b); uerr != nil {
+			http.Error(w, "error unmarshalling request", http.StatusBadRequest)
+			return
+		}
+		select {
+		case <-h.waitch():
+		case <-time.After(applyTimeout):
+			http.Error(w, ErrLeaseHTTPTimeout.Error(), http.StatusRequestTimeout)
+			return
+		}
+		ids := make([]lease.LeaseID, len(lreq.IDs))
+		for i, id := range lreq.IDs {
+			ids[i] = lease.LeaseID(id)
+		}
+		ttls, rerr := h.l.RenewBatch(ids)
+		if rerr != nil {
+			http.Error(w, rerr.Error(), http.StatusBadRequest)
+			return
+		}
+		// TODO: fill out ResponseHeader
+		resp := &pb.LeaseKeepAliveBatchResponse{IDs: lreq.IDs, TTLs: ttls}
+		v, err = resp.Marshal()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 	case LeaseInternalPrefix:
 		lreq := leasepb.LeaseInternalRequest{}
 		if lerr := lreq.Unmarshal(b); lerr != nil {
@@ -189,6 +221,57 @@ func RenewHTTP(ctx context.Context, id lease.LeaseID, url string, rt http.RoundT
 	return lresp.TTL, nil
 }
 
+// RenewBatchHTTP renews leases at a given primary server. The TTL of a lease
+// that does not exist is 0. ErrLeaseBatchNotSupported is returned if the
+// server does not serve batched renewals.
+func RenewBatchHTTP(ctx context.Context, ids []lease.LeaseID, url string, rt http.RoundTripper) ([]int64, error) {
+	pids := make([]int64, len(ids))
+	for i, id := range ids {
+		pids[i] = int64(id)
+	}
+	lreq, err := (&pb.LeaseKeepAliveBatchRequest{IDs: pids}).Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(lreq))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/protobuf")
+	req = req.WithContext(ctx)
+
+	cc := &http.Client{Transport: rt}
+	resp, err := cc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusRequestTimeout:
+		return nil, ErrLeaseHTTPTimeout
+	case http.StatusNotFound:
+		// served by a member predating batched renewals
+		return nil, ErrLeaseBatchNotSupported
+	default:
+		return nil, fmt.Errorf("lease: unknown error(%s)", string(b))
+	}
+
+	lresp := &pb.LeaseKeepAliveBatchResponse{}
+	if err := lresp.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf(`lease: %v. data = "%s"`, err, string(b))
+	}
+	if len(lresp.TTLs) != len(ids) {
+		return nil, fmt.Errorf("lease: renew batch size mismatch")
+	}
+	return lresp.TTLs, nil
+}
+
 // TimeToLiveHTTP retrieves lease information of the given lease ID.
 func TimeToLiveHTTP(ctx context.Context, id lease.LeaseID, keys bool, url string, rt http.RoundTripper) (*leasepb.LeaseInternalResponse, error) {
 	// will post lreq protobuf to leader
@@ -50,6 +50,37 @@ func TestRenewHTTP(t *testing.T) {
 	}
 }
 
+func TestRenewBatchHTTP(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewTmpBackend(t, time.Hour, 10000)
+	defer betesting.Close(t, be)
+
+	le := lease.NewLessor(lg, be, nil, lease.LessorConfig{MinLeaseTTL: int64(5)})
+	le.Promote(time.Second)
+	l, err := le.Grant(1, int64(5))
+	if err != nil {
+		t.Fatalf("failed to create lease: %v", err)
+	}
+
+	ts := httptest.NewServer(NewHandler(le, waitReady))
+	defer ts.Close()
+
+	ttls, err := RenewBatchHTTP(context.TODO(), []lease.LeaseID{l.ID, 2}, ts.URL+LeaseBatchPrefix, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ttls) != 2 || ttls[0] != 5 || ttls[1] != 0 {
+		t.Fatalf("ttls expected [5 0], got %v", ttls)
+	}
+
+	// a handler predating batched renewals does not serve the path
+	ts2 := httptest.NewServer(http.NotFoundHandler())
+	defer ts2.Close()
+	if _, err = RenewBatchHTTP(context.TODO(), []lease.LeaseID{l.ID}, ts2.URL+LeaseBatchPrefix, http.DefaultTransport); err != ErrLeaseBatchNotSupported {
+		t.Fatalf("expected %v, got %v", ErrLeaseBatchNotSupported, err)
+	}
+}
+
 func TestTimeToLiveHTTP(t *testing.T) {
 	lg := zaptest.NewLogger(t)
 	be, _ := betesting.NewTmpBackend(t, time.Hour, 10000)
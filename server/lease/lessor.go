@@ -68,6 +68,8 @@ var (
 // to avoid circular dependency with mvcc.
 type TxnDelete interface {
 	DeleteRange(key, end []byte) (n, rev int64)
+	// DeleteExpired deletes the key attached to the expired lease with given ID.
+	DeleteExpired(key []byte, id LeaseID) (n, rev int64)
 	End()
 }
 
@@ -91,11 +93,21 @@ type Lessor interface {
 
 	// Grant grants a lease that expires at least after TTL seconds.
 	Grant(id LeaseID, ttl int64) (*Lease, error)
+	// GrantTenant grants a lease like Grant, owned by the tenant with the given prefix.
+	GrantTenant(id LeaseID, ttl int64, tenant string) (*Lease, error)
 	// Revoke revokes a lease with given ID. The item attached to the
 	// given lease will be removed. If the ID does not exist, an error
 	// will be returned.
 	Revoke(id LeaseID) error
 
+	// RevokeExpired revokes an expired lease with given ID like Revoke, marking the
+	// removal of its items and its lease event as caused by the expiry.
+	RevokeExpired(id LeaseID) error
+
+	// Update changes the TTL of a lease with given ID. The lease is renewed with the new
+	// TTL if the lessor is the primary lessor. If the ID does not exist, an error will be returned.
+	Update(id LeaseID, ttl int64) (*Lease, error)
+
 	// Checkpoint applies the remainingTTL of a lease. The remainingTTL is used in Promote to set
 	// the expiry of leases to less than the full TTL when possible.
 	Checkpoint(id LeaseID, remainingTTL int64) error
@@ -104,6 +116,11 @@ type Lessor interface {
 	// If the lease does not exist, an error will be returned.
 	Attach(id LeaseID, items []LeaseItem) error
 
+	// Move detaches all items of the lease with given ID and attaches them to the
+	// target lease, returning the moved items. If either lease does not exist, an
+	// error will be returned.
+	Move(id, target LeaseID) ([]LeaseItem, error)
+
 	// GetLease returns LeaseID for given item.
 	// If no lease found, NoLease value will be returned.
 	GetLease(item LeaseItem) LeaseID
@@ -124,6 +141,10 @@ type Lessor interface {
 	// an error will be returned.
 	Renew(id LeaseID) (int64, error)
 
+	// RenewBatch renews the leases with given IDs at once. It returns the renewed TTLs in the
+	// order of the IDs; the TTL of a lease that does not exist or has expired is 0.
+	RenewBatch(ids []LeaseID) ([]int64, error)
+
 	// Lookup gives the lease at a given lease id, if any
 	Lookup(id LeaseID) *Lease
 
@@ -133,6 +154,12 @@ type Lessor interface {
 	// ExpiredLeasesC returns a chan that is used to receive expired leases.
 	ExpiredLeasesC() <-chan []*Lease
 
+	// WatchEvents returns a chan receiving the lease events accepted by filter, and a
+	// function stopping the watch. A nil filter accepts all events. The chan is closed
+	// when the watch is stopped, when the lessor is stopped, or when the receiver falls
+	// too far behind the events.
+	WatchEvents(filter func(Event) bool) (<-chan Event, func())
+
 	// Recover recovers the lessor state from the given backend and RangeDeleter.
 	Recover(b backend.Backend, rd RangeDeleter)
 
@@ -172,6 +199,8 @@ type lessor struct {
 	minLeaseTTL int64
 
 	expiredC chan []*Lease
+	// events sends lease events to their watchers.
+	events eventHub
 	// stopC is a channel whose closure indicates that the lessor should be stopped.
 	stopC chan struct{}
 	// doneC is a channel whose closure indicates that the lessor is stopped.
@@ -270,6 +299,10 @@ func (le *lessor) SetCheckpointer(cp Checkpointer) {
 }
 
 func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
+	return le.GrantTenant(id, ttl, "")
+}
+
+func (le *lessor) GrantTenant(id LeaseID, ttl int64, tenant string) (*Lease, error) {
 	if id == NoLease {
 		return nil, ErrLeaseNotFound
 	}
@@ -283,6 +316,7 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 	l := &Lease{
 		ID:      id,
 		ttl:     ttl,
+		tenant:  tenant,
 		itemSet: make(map[LeaseItem]struct{}),
 		revokec: make(chan struct{}),
 	}
@@ -309,6 +343,7 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 
 	leaseTotalTTLs.Observe(float64(l.ttl))
 	leaseGranted.Inc()
+	le.events.send(Event{Type: EventGranted, ID: l.ID, Tenant: l.tenant, TTL: l.ttl})
 
 	if le.isPrimary() {
 		item := &LeaseWithTime{id: l.ID, time: l.expiry}
@@ -320,6 +355,14 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 }
 
 func (le *lessor) Revoke(id LeaseID) error {
+	return le.revoke(id, false)
+}
+
+func (le *lessor) RevokeExpired(id LeaseID) error {
+	return le.revoke(id, true)
+}
+
+func (le *lessor) revoke(id LeaseID, expired bool) error {
 	le.mu.Lock()
 
 	l := le.leaseMap[id]
@@ -342,7 +385,11 @@ func (le *lessor) Revoke(id LeaseID) error {
 	keys := l.Keys()
 	sort.StringSlice(keys).Sort()
 	for _, key := range keys {
-		txn.DeleteRange([]byte(key), nil)
+		if expired {
+			txn.DeleteExpired([]byte(key), l.ID)
+		} else {
+			txn.DeleteRange([]byte(key), nil)
+		}
 	}
 
 	le.mu.Lock()
@@ -356,9 +403,46 @@ func (le *lessor) Revoke(id LeaseID) error {
 	txn.End()
 
 	leaseRevoked.Inc()
+	ev := Event{Type: EventRevoked, ID: l.ID, Tenant: l.tenant, TTL: l.ttl}
+	if expired {
+		ev.Type = EventExpired
+	}
+	le.events.send(ev)
 	return nil
 }
 
+func (le *lessor) Update(id LeaseID, ttl int64) (*Lease, error) {
+	if ttl > MaxLeaseTTL {
+		return nil, ErrLeaseTTLTooLarge
+	}
+
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	l := le.leaseMap[id]
+	if l == nil {
+		return nil, ErrLeaseNotFound
+	}
+
+	if ttl < le.minLeaseTTL {
+		ttl = le.minLeaseTTL
+	}
+	l.ttl = ttl
+	// a checkpointed remaining TTL belongs to the previous TTL
+	l.remainingTTL = 0
+	l.persistTo(le.b)
+
+	leaseTotalTTLs.Observe(float64(l.ttl))
+
+	if le.isPrimary() {
+		l.refresh(0)
+		item := &LeaseWithTime{id: l.ID, time: l.expiry}
+		le.leaseExpiredNotifier.RegisterOrUpdate(item)
+		le.scheduleCheckpointIfNeeded(l)
+	}
+	return l, nil
+}
+
 func (le *lessor) Checkpoint(id LeaseID, remainingTTL int64) error {
 	le.mu.Lock()
 	defer le.mu.Unlock()
@@ -431,15 +515,68 @@ func (le *lessor) Renew(id LeaseID) (int64, error) {
 	}
 
 	le.mu.Lock()
+	remaining := int64(l.Remaining().Seconds())
 	l.refresh(0)
 	item := &LeaseWithTime{id: l.ID, time: l.expiry}
 	le.leaseExpiredNotifier.RegisterOrUpdate(item)
+	le.events.send(Event{Type: EventRenewed, ID: l.ID, Tenant: l.tenant, TTL: l.ttl, RemainingTTL: remaining})
 	le.mu.Unlock()
 
 	leaseRenewed.Inc()
 	return l.ttl, nil
 }
 
+// RenewBatch renews existing leases under a single acquisition of the lessor
+// lock. Leases that do not exist are reported with a TTL of 0. If the lessor
+// is not the primary lessor, no lease is renewed and ErrNotPrimary is returned.
+func (le *lessor) RenewBatch(ids []LeaseID) ([]int64, error) {
+	ttls := make([]int64, len(ids))
+	leases := make([]*Lease, len(ids))
+
+	le.mu.RLock()
+	if !le.isPrimary() {
+		le.mu.RUnlock()
+		return nil, ErrNotPrimary
+	}
+	var cps []*pb.LeaseCheckpoint
+	for i, id := range ids {
+		l := le.leaseMap[id]
+		if l == nil || l.expired() {
+			// unlike Renew, the expired leases are not waited for: their
+			// revocation is rate limited and would hold back the other
+			// leases of the batch until they expire too.
+			continue
+		}
+		leases[i] = l
+		if le.cp != nil && l.remainingTTL > 0 {
+			cps = append(cps, &pb.LeaseCheckpoint{ID: int64(l.ID), Remaining_TTL: 0})
+		}
+	}
+	le.mu.RUnlock()
+
+	if len(cps) > 0 {
+		le.cp(context.Background(), &pb.LeaseCheckpointRequest{Checkpoints: cps})
+	}
+
+	renewed := 0
+	le.mu.Lock()
+	for i, l := range leases {
+		if l == nil {
+			continue
+		}
+		remaining := int64(l.Remaining().Seconds())
+		l.refresh(0)
+		le.leaseExpiredNotifier.RegisterOrUpdate(&LeaseWithTime{id: l.ID, time: l.expiry})
+		le.events.send(Event{Type: EventRenewed, ID: l.ID, Tenant: l.tenant, TTL: l.ttl, RemainingTTL: remaining})
+		ttls[i] = l.ttl
+		renewed++
+	}
+	le.mu.Unlock()
+
+	leaseRenewed.Add(float64(renewed))
+	return ttls, nil
+}
+
 func (le *lessor) Lookup(id LeaseID) *Lease {
 	le.mu.RLock()
 	defer le.mu.RUnlock()
@@ -561,6 +698,36 @@ func (le *lessor) Attach(id LeaseID, items []LeaseItem) error {
 	return nil
 }
 
+func (le *lessor) Move(id, target LeaseID) ([]LeaseItem, error) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	l, tl := le.leaseMap[id], le.leaseMap[target]
+	if l == nil || tl == nil {
+		return nil, ErrLeaseNotFound
+	}
+
+	l.mu.Lock()
+	items := make([]LeaseItem, 0, len(l.itemSet))
+	for it := range l.itemSet {
+		items = append(items, it)
+	}
+	if id != target {
+		l.itemSet = make(map[LeaseItem]struct{})
+	}
+	l.mu.Unlock()
+
+	if id != target {
+		tl.mu.Lock()
+		for _, it := range items {
+			tl.itemSet[it] = struct{}{}
+			le.itemMap[it] = target
+		}
+		tl.mu.Unlock()
+	}
+	return items, nil
+}
+
 func (le *lessor) GetLease(item LeaseItem) LeaseID {
 	le.mu.RLock()
 	id := le.itemMap[item]
@@ -603,9 +770,14 @@ func (le *lessor) ExpiredLeasesC() <-chan []*Lease {
 	return le.expiredC
 }
 
+func (le *lessor) WatchEvents(filter func(Event) bool) (<-chan Event, func()) {
+	return le.events.watch(filter)
+}
+
 func (le *lessor) Stop() {
 	close(le.stopC)
 	<-le.doneC
+	le.events.stop()
 }
 
 func (le *lessor) runLoop() {
@@ -807,8 +979,9 @@ func (le *lessor) initAndRecover() {
 			lpb.TTL = le.minLeaseTTL
 		}
 		le.leaseMap[ID] = &Lease{
-			ID:  ID,
-			ttl: lpb.TTL,
+			ID:     ID,
+			ttl:    lpb.TTL,
+			tenant: lpb.Tenant,
 			// itemSet will be filled in when recover key-value pairs
 			// set expiry to forever, refresh when promoted
 			itemSet:      make(map[LeaseItem]struct{}),
@@ -827,6 +1000,8 @@ type Lease struct {
 	ID           LeaseID
 	ttl          int64 // time to live of the lease in seconds
 	remainingTTL int64 // remaining time to live in seconds, if zero valued it is considered unset and the full ttl should be used
+	// tenant is the prefix of the tenant owning the lease, empty if the lease is not owned by a tenant
+	tenant string
 	// expiryMu protects concurrent accesses to expiry
 	expiryMu sync.RWMutex
 	// expiry is time when lease should expire. no expiration when expiry.IsZero() is true
@@ -843,7 +1018,7 @@ func (l *Lease) expired() bool {
 }
 
 func (l *Lease) persistTo(b backend.Backend) {
-	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL}
+	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL, Tenant: l.tenant}
 	tx := b.BatchTx()
 	tx.Lock()
 	defer tx.Unlock()
@@ -855,6 +1030,12 @@ func (l *Lease) TTL() int64 {
 	return l.ttl
 }
 
+// Tenant returns the prefix of the tenant owning the lease, empty if the lease is not
+// owned by a tenant.
+func (l *Lease) Tenant() string {
+	return l.tenant
+}
+
 // RemainingTTL returns the last checkpointed remaining TTL of the lease.
 // TODO(jpbetz): do not expose this utility method
 func (l *Lease) RemainingTTL() int64 {
@@ -920,12 +1101,22 @@ func (fl *FakeLessor) SetCheckpointer(cp Checkpointer) {}
 
 func (fl *FakeLessor) Grant(id LeaseID, ttl int64) (*Lease, error) { return nil, nil }
 
+func (fl *FakeLessor) GrantTenant(id LeaseID, ttl int64, tenant string) (*Lease, error) {
+	return nil, nil
+}
+
 func (fl *FakeLessor) Revoke(id LeaseID) error { return nil }
 
+func (fl *FakeLessor) RevokeExpired(id LeaseID) error { return nil }
+
+func (fl *FakeLessor) Update(id LeaseID, ttl int64) (*Lease, error) { return nil, nil }
+
 func (fl *FakeLessor) Checkpoint(id LeaseID, remainingTTL int64) error { return nil }
 
 func (fl *FakeLessor) Attach(id LeaseID, items []LeaseItem) error { return nil }
 
+func (fl *FakeLessor) Move(id, target LeaseID) ([]LeaseItem, error) { return nil, nil }
+
 func (fl *FakeLessor) GetLease(item LeaseItem) LeaseID            { return 0 }
 func (fl *FakeLessor) Detach(id LeaseID, items []LeaseItem) error { return nil }
 
@@ -935,12 +1126,25 @@ func (fl *FakeLessor) Demote() {}
 
 func (fl *FakeLessor) Renew(id LeaseID) (int64, error) { return 10, nil }
 
+func (fl *FakeLessor) RenewBatch(ids []LeaseID) ([]int64, error) {
+	ttls := make([]int64, len(ids))
+	for i := range ttls {
+		ttls[i] = 10
+	}
+	return ttls, nil
+}
+
 func (fl *FakeLessor) Lookup(id LeaseID) *Lease { return nil }
 
 func (fl *FakeLessor) Leases() []*Lease { return nil }
 
 func (fl *FakeLessor) ExpiredLeasesC() <-chan []*Lease { return nil }
 
+func (fl *FakeLessor) WatchEvents(filter func(Event) bool) (<-chan Event, func()) {
+	ch := make(chan Event)
+	return ch, func() { close(ch) }
+}
+
 func (fl *FakeLessor) Recover(b backend.Backend, rd RangeDeleter) {}
 
 func (fl *FakeLessor) Stop() {}
@@ -951,3 +1155,5 @@ type FakeTxnDelete struct {
 
 func (ftd *FakeTxnDelete) DeleteRange(key, end []byte) (n, rev int64) { return 0, 0 }
 func (ftd *FakeTxnDelete) End()                                       { ftd.Unlock() }
+
+func (ftd *FakeTxnDelete) DeleteExpired(key []byte, id LeaseID) (n, rev int64) { return 0, 0 }
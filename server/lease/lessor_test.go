@@ -98,7 +98,7 @@ func TestLessorGrant(t *testing.T) {
 	defer tx.Unlock()
 	lpb := schema.MustUnsafeGetLease(tx, int64(l.ID))
 	if lpb == nil {
-		t.Errorf("lpb = %d, want not nil", lpb)
+		t.Errorf("lpb = %v, want not nil", lpb)
 	}
 }
 
@@ -202,7 +202,7 @@ func TestLessorRevoke(t *testing.T) {
 	defer tx.Unlock()
 	lpb := schema.MustUnsafeGetLease(tx, int64(l.ID))
 	if lpb != nil {
-		t.Errorf("lpb = %d, want nil", lpb)
+		t.Errorf("lpb = %v, want nil", lpb)
 	}
 }
 
@@ -284,6 +284,234 @@ func TestLessorRenewWithCheckpointer(t *testing.T) {
 	}
 }
 
+func TestLessorRenewBatch(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	var checkpoints int
+	le.SetCheckpointer(func(ctx context.Context, cp *pb.LeaseCheckpointRequest) {
+		checkpoints++
+		for _, cp := range cp.GetCheckpoints() {
+			le.Checkpoint(LeaseID(cp.GetID()), cp.GetRemaining_TTL())
+		}
+	})
+	defer le.Stop()
+
+	if _, err := le.RenewBatch([]LeaseID{1}); err != ErrNotPrimary {
+		t.Fatalf("err = %v, want %v", err, ErrNotPrimary)
+	}
+	le.Promote(0)
+
+	var leases []*Lease
+	for i := 1; i <= 3; i++ {
+		l, err := le.Grant(LeaseID(i), minLeaseTTL)
+		if err != nil {
+			t.Fatalf("failed to grant lease (%v)", err)
+		}
+		leases = append(leases, l)
+	}
+
+	// manually change the ttl and remaining ttl fields
+	le.mu.Lock()
+	for _, l := range leases {
+		l.ttl = 10
+		l.remainingTTL = 10
+	}
+	le.mu.Unlock()
+	ttls, err := le.RenewBatch([]LeaseID{1, 4, 2, 3})
+	if err != nil {
+		t.Fatalf("failed to renew leases (%v)", err)
+	}
+	if want := []int64{10, 0, 10, 10}; !reflect.DeepEqual(ttls, want) {
+		t.Errorf("ttls = %v, want %v", ttls, want)
+	}
+	if checkpoints != 1 {
+		t.Errorf("checkpoints = %d, want 1", checkpoints)
+	}
+	for _, l := range leases {
+		if l.remainingTTL != 0 {
+			t.Errorf("remainingTTL of lease %d = %d, want 0", l.ID, l.remainingTTL)
+		}
+		if l.Remaining() < 9*time.Second {
+			t.Errorf("failed to renew lease %d", l.ID)
+		}
+	}
+}
+
+// TestLessorRenewBatchExpired ensures the expired leases of a batch are not renewed,
+// and do not hold back the renewal of the other leases until they are revoked.
+func TestLessorRenewBatchExpired(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.Promote(0)
+
+	expired, err := le.Grant(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = le.Grant(2, 10); err != nil {
+		t.Fatal(err)
+	}
+	expired.expiryMu.Lock()
+	expired.expiry = time.Now().Add(-time.Second)
+	expired.expiryMu.Unlock()
+
+	donec := make(chan struct{})
+	go func() {
+		defer close(donec)
+		ttls, err := le.RenewBatch([]LeaseID{1, 2})
+		if err != nil {
+			t.Errorf("failed to renew leases (%v)", err)
+		}
+		if want := []int64{0, 10}; !reflect.DeepEqual(ttls, want) {
+			t.Errorf("ttls = %v, want %v", ttls, want)
+		}
+	}()
+	select {
+	case <-donec:
+	case <-time.After(time.Second):
+		t.Fatal("renewal held back by the expired lease")
+	}
+	if !expired.expired() {
+		t.Error("expected the expired lease not to be renewed")
+	}
+}
+
+// TestLessorUpdate ensures Lessor can change the TTL of an existing lease
+// and that the new TTL is persisted.
+func TestLessorUpdate(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.Promote(0)
+
+	l, err := le.Grant(1, minLeaseTTL)
+	if err != nil {
+		t.Fatalf("failed to grant lease (%v)", err)
+	}
+
+	if l, err = le.Update(l.ID, 100); err != nil {
+		t.Fatalf("failed to update lease (%v)", err)
+	}
+	if l.ttl != 100 {
+		t.Errorf("ttl = %d, want 100", l.ttl)
+	}
+	if l.Remaining() < 99*time.Second {
+		t.Errorf("remaining = %v, want the lease refreshed with the new TTL", l.Remaining())
+	}
+
+	if l, err = le.Update(l.ID, 1); err != nil {
+		t.Fatalf("failed to update lease (%v)", err)
+	}
+	if l.ttl != minLeaseTTL {
+		t.Errorf("ttl = %d, want %d", l.ttl, minLeaseTTL)
+	}
+
+	if _, err = le.Update(l.ID, MaxLeaseTTL+1); err != ErrLeaseTTLTooLarge {
+		t.Errorf("err = %v, want %v", err, ErrLeaseTTLTooLarge)
+	}
+	if _, err = le.Update(2, 10); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	if nl := nle.Lookup(l.ID); nl == nil || nl.ttl != minLeaseTTL {
+		t.Errorf("recovered lease = %v, want ttl %d", nl, minLeaseTTL)
+	}
+}
+
+// TestLessorWatchEvents ensures the lessor sends the events of granted, renewed,
+// revoked and expired leases to its watchers.
+func TestLessorWatchEvents(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	var fds []*fakeDeleter
+	le.SetRangeDeleter(func() TxnDelete {
+		fd := newFakeDeleter(be)
+		fds = append(fds, fd)
+		return fd
+	})
+	le.Promote(0)
+
+	evc, stop := le.WatchEvents(nil)
+	defer stop()
+	skipc, skipStop := le.WatchEvents(func(ev Event) bool { return ev.ID == 1 })
+	defer skipStop()
+
+	for id := LeaseID(1); id <= 2; id++ {
+		if _, err := le.Grant(id, 10); err != nil {
+			t.Fatalf("failed to grant lease (%v)", err)
+		}
+	}
+	if err := le.Attach(2, []LeaseItem{{Key: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := le.Renew(1); err != nil {
+		t.Fatalf("failed to renew lease (%v)", err)
+	}
+	if err := le.Revoke(1); err != nil {
+		t.Fatalf("failed to revoke lease (%v)", err)
+	}
+	if err := le.RevokeExpired(2); err != nil {
+		t.Fatalf("failed to revoke lease (%v)", err)
+	}
+
+	wevs := []Event{
+		{Type: EventGranted, ID: 1, TTL: 10},
+		{Type: EventGranted, ID: 2, TTL: 10},
+		{Type: EventRenewed, ID: 1, TTL: 10, RemainingTTL: 9},
+		{Type: EventRevoked, ID: 1, TTL: 10},
+		{Type: EventExpired, ID: 2, TTL: 10},
+	}
+	for i, wev := range wevs {
+		if ev := <-evc; ev != wev {
+			t.Errorf("#%d: event = %+v, want %+v", i, ev, wev)
+		}
+	}
+	if n := len(skipc); n != 3 {
+		t.Errorf("filtered events = %d, want 3", n)
+	}
+	var deleted []string
+	for _, fd := range fds {
+		deleted = append(deleted, fd.deleted...)
+	}
+	if wdeleted := []string{"foo_expired"}; !reflect.DeepEqual(deleted, wdeleted) {
+		t.Errorf("deleted = %v, want %v", deleted, wdeleted)
+	}
+
+	// a watcher falling too far behind is stopped
+	for i := 0; i < eventBufferSize+1; i++ {
+		if _, err := le.Grant(LeaseID(i+10), 10); err != nil {
+			t.Fatalf("failed to grant lease (%v)", err)
+		}
+	}
+	n := 0
+	for range evc {
+		n++
+	}
+	if n != eventBufferSize {
+		t.Errorf("received events = %d, want %d", n, eventBufferSize)
+	}
+}
+
 // TestLessorRenewExtendPileup ensures Lessor extends leases on promotion if too many
 // expire at the same time.
 func TestLessorRenewExtendPileup(t *testing.T) {
@@ -377,6 +605,55 @@ func TestLessorDetach(t *testing.T) {
 	}
 }
 
+// TestLessorMove ensures Lessor moves the items of a lease to another lease.
+func TestLessorMove(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+
+	l1, err := le.Grant(1, 100)
+	if err != nil {
+		t.Fatalf("could not grant lease for 100s ttl (%v)", err)
+	}
+	l2, err := le.Grant(2, 100)
+	if err != nil {
+		t.Fatalf("could not grant lease for 100s ttl (%v)", err)
+	}
+	if err = le.Attach(l1.ID, []LeaseItem{{"foo"}, {"bar"}}); err != nil {
+		t.Fatalf("failed to attach items to the lease: %v", err)
+	}
+	if err = le.Attach(l2.ID, []LeaseItem{{"baz"}}); err != nil {
+		t.Fatalf("failed to attach items to the lease: %v", err)
+	}
+
+	items, err := le.Move(l1.ID, l2.ID)
+	if err != nil {
+		t.Fatalf("failed to move the items of the lease: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("len(items) = %d, want 2", len(items))
+	}
+	if len(l1.itemSet) != 0 || len(l2.itemSet) != 3 {
+		t.Errorf("items of leases = %d, %d, want 0, 3", len(l1.itemSet), len(l2.itemSet))
+	}
+	for _, key := range []string{"foo", "bar", "baz"} {
+		if id := le.GetLease(LeaseItem{key}); id != l2.ID {
+			t.Errorf("lease of %q = %d, want %d", key, id, l2.ID)
+		}
+	}
+
+	if items, err = le.Move(l2.ID, l2.ID); err != nil || len(items) != 3 || len(l2.itemSet) != 3 {
+		t.Errorf("move to the same lease = %d items (%v), want 3 items kept", len(items), err)
+	}
+	if _, err = le.Move(l1.ID, 3); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+}
+
 // TestLessorRecover ensures Lessor recovers leases from
 // persist backend.
 func TestLessorRecover(t *testing.T) {
@@ -407,6 +684,32 @@ func TestLessorRecover(t *testing.T) {
 	}
 }
 
+func TestLessorRecoverTenant(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	if _, err := le.GrantTenant(1, 10, "/t/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := le.Grant(2, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tenant owning the lease is persisted
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	if tenant := nle.Lookup(1).Tenant(); tenant != "/t/" {
+		t.Errorf("tenant = %q, want %q", tenant, "/t/")
+	}
+	if tenant := nle.Lookup(2).Tenant(); tenant != "" {
+		t.Errorf("tenant = %q, want none", tenant)
+	}
+}
+
 func TestLessorExpire(t *testing.T) {
 	lg := zap.NewNop()
 	dir, be := NewTestBackend(t)
@@ -668,6 +971,11 @@ func (fd *fakeDeleter) DeleteRange(key, end []byte) (int64, int64) {
 	return 0, 0
 }
 
+func (fd *fakeDeleter) DeleteExpired(key []byte, id LeaseID) (int64, int64) {
+	fd.deleted = append(fd.deleted, string(key)+"_expired")
+	return 0, 0
+}
+
 func NewTestBackend(t *testing.T) (string, backend.Backend) {
 	tmpPath, err := os.MkdirTemp("", "lease")
 	if err != nil {
@@ -38,6 +38,14 @@ func (c *ls2lc) LeaseRevoke(ctx context.Context, in *pb.LeaseRevokeRequest, opts
 	return c.leaseServer.LeaseRevoke(ctx, in)
 }
 
+func (c *ls2lc) LeaseUpdate(ctx context.Context, in *pb.LeaseUpdateRequest, opts ...grpc.CallOption) (*pb.LeaseUpdateResponse, error) {
+	return c.leaseServer.LeaseUpdate(ctx, in)
+}
+
+func (c *ls2lc) LeaseMove(ctx context.Context, in *pb.LeaseMoveRequest, opts ...grpc.CallOption) (*pb.LeaseMoveResponse, error) {
+	return c.leaseServer.LeaseMove(ctx, in)
+}
+
 func (c *ls2lc) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (pb.Lease_LeaseKeepAliveClient, error) {
 	cs := newPipeStream(ctx, func(ss chanServerStream) error {
 		return c.leaseServer.LeaseKeepAlive(&ls2lcServerStream{ss})
@@ -45,6 +53,20 @@ func (c *ls2lc) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (pb
 	return &ls2lcClientStream{cs}, nil
 }
 
+func (c *ls2lc) LeaseKeepAliveBatch(ctx context.Context, opts ...grpc.CallOption) (pb.Lease_LeaseKeepAliveBatchClient, error) {
+	cs := newPipeStream(ctx, func(ss chanServerStream) error {
+		return c.leaseServer.LeaseKeepAliveBatch(&ls2lcBatchServerStream{ss})
+	})
+	return &ls2lcBatchClientStream{cs}, nil
+}
+
+func (c *ls2lc) LeaseWatch(ctx context.Context, in *pb.LeaseWatchRequest, opts ...grpc.CallOption) (pb.Lease_LeaseWatchClient, error) {
+	cs := newPipeStream(ctx, func(ss chanServerStream) error {
+		return c.leaseServer.LeaseWatch(in, &ls2lcWatchServerStream{ss})
+	})
+	return &ls2lcWatchClientStream{cs}, nil
+}
+
 func (c *ls2lc) LeaseTimeToLive(ctx context.Context, in *pb.LeaseTimeToLiveRequest, opts ...grpc.CallOption) (*pb.LeaseTimeToLiveResponse, error) {
 	return c.leaseServer.LeaseTimeToLive(ctx, in)
 }
@@ -80,3 +102,59 @@ func (s *ls2lcServerStream) Recv() (*pb.LeaseKeepAliveRequest, error) {
 	}
 	return v.(*pb.LeaseKeepAliveRequest), nil
 }
+
+// ls2lcBatchClientStream implements Lease_LeaseKeepAliveBatchClient
+type ls2lcBatchClientStream struct{ chanClientStream }
+
+// ls2lcBatchServerStream implements Lease_LeaseKeepAliveBatchServer
+type ls2lcBatchServerStream struct{ chanServerStream }
+
+func (s *ls2lcBatchClientStream) Send(rr *pb.LeaseKeepAliveBatchRequest) error {
+	return s.SendMsg(rr)
+}
+func (s *ls2lcBatchClientStream) Recv() (*pb.LeaseKeepAliveBatchResponse, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseKeepAliveBatchResponse), nil
+}
+
+func (s *ls2lcBatchServerStream) Send(rr *pb.LeaseKeepAliveBatchResponse) error {
+	return s.SendMsg(rr)
+}
+func (s *ls2lcBatchServerStream) Recv() (*pb.LeaseKeepAliveBatchRequest, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseKeepAliveBatchRequest), nil
+}
+
+// ls2lcWatchClientStream implements Lease_LeaseWatchClient
+type ls2lcWatchClientStream struct{ chanClientStream }
+
+// ls2lcWatchServerStream implements Lease_LeaseWatchServer
+type ls2lcWatchServerStream struct{ chanServerStream }
+
+func (s *ls2lcWatchClientStream) Send(rr *pb.LeaseWatchRequest) error {
+	return s.SendMsg(rr)
+}
+func (s *ls2lcWatchClientStream) Recv() (*pb.LeaseWatchResponse, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseWatchResponse), nil
+}
+
+func (s *ls2lcWatchServerStream) Send(rr *pb.LeaseWatchResponse) error {
+	return s.SendMsg(rr)
+}
+func (s *ls2lcWatchServerStream) Recv() (*pb.LeaseWatchRequest, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseWatchRequest), nil
+}
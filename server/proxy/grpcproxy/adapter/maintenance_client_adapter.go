@@ -56,6 +56,42 @@ func (s *mts2mtc) Downgrade(ctx context.Context, r *pb.DowngradeRequest, opts ..
 	return s.mts.Downgrade(ctx, r)
 }
 
+func (s *mts2mtc) RetentionPut(ctx context.Context, r *pb.RetentionPutRequest, opts ...grpc.CallOption) (*pb.RetentionPutResponse, error) {
+	return s.mts.RetentionPut(ctx, r)
+}
+
+func (s *mts2mtc) RetentionDelete(ctx context.Context, r *pb.RetentionDeleteRequest, opts ...grpc.CallOption) (*pb.RetentionDeleteResponse, error) {
+	return s.mts.RetentionDelete(ctx, r)
+}
+
+func (s *mts2mtc) RetentionList(ctx context.Context, r *pb.RetentionListRequest, opts ...grpc.CallOption) (*pb.RetentionListResponse, error) {
+	return s.mts.RetentionList(ctx, r)
+}
+
+func (s *mts2mtc) SchemaPut(ctx context.Context, r *pb.SchemaPutRequest, opts ...grpc.CallOption) (*pb.SchemaPutResponse, error) {
+	return s.mts.SchemaPut(ctx, r)
+}
+
+func (s *mts2mtc) SchemaGet(ctx context.Context, r *pb.SchemaGetRequest, opts ...grpc.CallOption) (*pb.SchemaGetResponse, error) {
+	return s.mts.SchemaGet(ctx, r)
+}
+
+func (s *mts2mtc) SchemaDelete(ctx context.Context, r *pb.SchemaDeleteRequest, opts ...grpc.CallOption) (*pb.SchemaDeleteResponse, error) {
+	return s.mts.SchemaDelete(ctx, r)
+}
+
+func (s *mts2mtc) SchemaList(ctx context.Context, r *pb.SchemaListRequest, opts ...grpc.CallOption) (*pb.SchemaListResponse, error) {
+	return s.mts.SchemaList(ctx, r)
+}
+
+func (s *mts2mtc) TenantStatus(ctx context.Context, r *pb.TenantStatusRequest, opts ...grpc.CallOption) (*pb.TenantStatusResponse, error) {
+	return s.mts.TenantStatus(ctx, r)
+}
+
+func (s *mts2mtc) ReloadConfig(ctx context.Context, r *pb.ReloadConfigRequest, opts ...grpc.CallOption) (*pb.ReloadConfigResponse, error) {
+	return s.mts.ReloadConfig(ctx, r)
+}
+
 func (s *mts2mtc) Snapshot(ctx context.Context, in *pb.SnapshotRequest, opts ...grpc.CallOption) (pb.Maintenance_SnapshotClient, error) {
 	cs := newPipeStream(ctx, func(ss chanServerStream) error {
 		return s.mts.Snapshot(in, &ss2scServerStream{ss})
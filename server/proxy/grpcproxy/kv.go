@@ -16,6 +16,7 @@ package grpcproxy
 
 import (
 	"context"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/v3"
@@ -38,7 +39,8 @@ func NewKvProxy(c *clientv3.Client) (pb.KVServer, <-chan struct{}) {
 }
 
 func (p *kvProxy) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
-	if r.Serializable {
+	// bounded staleness reads are served by the members.
+	if r.Serializable && r.MinRevision == 0 && r.MaxStalenessMs == 0 {
 		resp, err := p.cache.Get(r)
 		switch err {
 		case nil:
@@ -60,6 +62,7 @@ func (p *kvProxy) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRespo
 	// cache linearizable as serializable
 	req := *r
 	req.Serializable = true
+	req.MinRevision, req.MaxStalenessMs = 0, 0
 	gresp := (*pb.RangeResponse)(resp.Get())
 	p.cache.Add(&req, gresp)
 	cacheKeys.Set(float64(p.cache.Size()))
@@ -176,6 +179,9 @@ func RangeRequestToOp(r *pb.RangeRequest) clientv3.Op {
 	opts = append(opts, clientv3.WithMinCreateRev(r.MinCreateRevision))
 	opts = append(opts, clientv3.WithMaxModRev(r.MaxModRevision))
 	opts = append(opts, clientv3.WithMinModRev(r.MinModRevision))
+	if r.IndexField != "" {
+		opts = append(opts, clientv3.WithIndexRange(r.IndexField, string(r.IndexValue), string(r.IndexValueEnd)))
+	}
 	if r.CountOnly {
 		opts = append(opts, clientv3.WithCountOnly())
 	}
@@ -184,6 +190,12 @@ func RangeRequestToOp(r *pb.RangeRequest) clientv3.Op {
 	}
 	if r.Serializable {
 		opts = append(opts, clientv3.WithSerializable())
+		if r.MinRevision > 0 {
+			opts = append(opts, clientv3.WithMinRevision(r.MinRevision))
+		}
+		if r.MaxStalenessMs > 0 {
+			opts = append(opts, clientv3.WithMaxStaleness(time.Duration(r.MaxStalenessMs)*time.Millisecond))
+		}
 	}
 
 	return clientv3.OpGet(string(r.Key), opts...)
@@ -91,6 +91,24 @@ func (lp *leaseProxy) LeaseRevoke(ctx context.Context, rr *pb.LeaseRevokeRequest
 	return (*pb.LeaseRevokeResponse)(r), nil
 }
 
+func (lp *leaseProxy) LeaseUpdate(ctx context.Context, ur *pb.LeaseUpdateRequest) (*pb.LeaseUpdateResponse, error) {
+	rp, err := lp.leaseClient.LeaseUpdate(ctx, ur, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
+func (lp *leaseProxy) LeaseMove(ctx context.Context, mr *pb.LeaseMoveRequest) (*pb.LeaseMoveResponse, error) {
+	rp, err := lp.leaseClient.LeaseMove(ctx, mr, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
 func (lp *leaseProxy) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	var (
 		r   *clientv3.LeaseTimeToLiveResponse
@@ -225,6 +243,117 @@ func (lp *leaseProxy) LeaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) error
 	}
 }
 
+// LeaseKeepAliveBatch forwards batched keepalives to etcd. Unlike LeaseKeepAlive,
+// it does not coalesce the keepalives of several clients for the same lease.
+func (lp *leaseProxy) LeaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) error {
+	lp.mu.Lock()
+	select {
+	case <-lp.ctx.Done():
+		lp.mu.Unlock()
+		return lp.ctx.Err()
+	default:
+		lp.wg.Add(1)
+	}
+	lp.mu.Unlock()
+	defer lp.wg.Done()
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	bs, err := lp.leaseClient.LeaseKeepAliveBatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	errc := make(chan error, 2)
+	go func() {
+		for {
+			rr, err := stream.Recv()
+			if err == io.EOF {
+				// responses still pending are forwarded until etcd closes its stream.
+				bs.CloseSend()
+				return
+			}
+			if err == nil {
+				err = bs.Send(rr)
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			rp, err := bs.Recv()
+			if err == io.EOF {
+				errc <- nil
+				return
+			}
+			if err == nil {
+				lp.leader.gotLeader()
+				err = stream.Send(rp)
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case err = <-errc:
+		return err
+	case <-lp.ctx.Done():
+		return status.Error(codes.Canceled, "the client connection is closing")
+	}
+}
+
+// LeaseWatch forwards the lease events of the etcd member the proxy is connected to.
+func (lp *leaseProxy) LeaseWatch(wr *pb.LeaseWatchRequest, stream pb.Lease_LeaseWatchServer) error {
+	lp.mu.Lock()
+	select {
+	case <-lp.ctx.Done():
+		lp.mu.Unlock()
+		return lp.ctx.Err()
+	default:
+		lp.wg.Add(1)
+	}
+	lp.mu.Unlock()
+	defer lp.wg.Done()
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	ws, err := lp.leaseClient.LeaseWatch(ctx, wr)
+	if err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			rp, err := ws.Recv()
+			if err == io.EOF {
+				errc <- nil
+				return
+			}
+			if err == nil {
+				err = stream.Send(rp)
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case err = <-errc:
+		return err
+	case <-lp.ctx.Done():
+		return status.Error(codes.Canceled, "the client connection is closing")
+	}
+}
+
 type leaseProxyStream struct {
 	stream pb.Lease_LeaseKeepAliveServer
 
@@ -93,3 +93,48 @@ func (mp *maintenanceProxy) Downgrade(ctx context.Context, r *pb.DowngradeReques
 	conn := mp.client.ActiveConnection()
 	return pb.NewMaintenanceClient(conn).Downgrade(ctx, r)
 }
+
+func (mp *maintenanceProxy) RetentionPut(ctx context.Context, r *pb.RetentionPutRequest) (*pb.RetentionPutResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).RetentionPut(ctx, r)
+}
+
+func (mp *maintenanceProxy) RetentionDelete(ctx context.Context, r *pb.RetentionDeleteRequest) (*pb.RetentionDeleteResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).RetentionDelete(ctx, r)
+}
+
+func (mp *maintenanceProxy) RetentionList(ctx context.Context, r *pb.RetentionListRequest) (*pb.RetentionListResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).RetentionList(ctx, r)
+}
+
+func (mp *maintenanceProxy) SchemaPut(ctx context.Context, r *pb.SchemaPutRequest) (*pb.SchemaPutResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).SchemaPut(ctx, r)
+}
+
+func (mp *maintenanceProxy) SchemaGet(ctx context.Context, r *pb.SchemaGetRequest) (*pb.SchemaGetResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).SchemaGet(ctx, r)
+}
+
+func (mp *maintenanceProxy) SchemaDelete(ctx context.Context, r *pb.SchemaDeleteRequest) (*pb.SchemaDeleteResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).SchemaDelete(ctx, r)
+}
+
+func (mp *maintenanceProxy) SchemaList(ctx context.Context, r *pb.SchemaListRequest) (*pb.SchemaListResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).SchemaList(ctx, r)
+}
+
+func (mp *maintenanceProxy) TenantStatus(ctx context.Context, r *pb.TenantStatusRequest) (*pb.TenantStatusResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).TenantStatus(ctx, r)
+}
+
+func (mp *maintenanceProxy) ReloadConfig(ctx context.Context, r *pb.ReloadConfigRequest) (*pb.ReloadConfigResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).ReloadConfig(ctx, r)
+}
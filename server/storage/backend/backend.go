@@ -15,7 +15,6 @@
 package backend
 
 import (
-	"fmt"
 	"hash/crc32"
 	"io"
 	"os"
@@ -99,9 +98,11 @@ type backend struct {
 	// mlock prevents backend database file to be swapped
 	mlock bool
 
-	mu    sync.RWMutex
-	bopts *bolt.Options
-	db    *bolt.DB
+	mu     sync.RWMutex
+	bcfg   BackendConfig
+	engine Engine
+	// defragMu serializes defragmentations.
+	defragMu sync.Mutex
 
 	batchInterval time.Duration
 	batchLimit    int
@@ -125,11 +126,14 @@ type backend struct {
 type BackendConfig struct {
 	// Path is the file path to the backend file.
 	Path string
+	// Engine is the name of the storage engine, DefaultEngine if empty.
+	Engine string
 	// BatchInterval is the maximum time before flushing the BatchTx.
 	BatchInterval time.Duration
 	// BatchLimit is the maximum puts before flushing the BatchTx.
 	BatchLimit int
-	// BackendFreelistType is the backend boltdb's freelist type.
+	// BackendFreelistType is the backend boltdb's freelist type. It only
+	// applies to the bbolt engine.
 	BackendFreelistType bolt.FreelistType
 	// MmapSize is the number of bytes to mmap for the backend.
 	MmapSize uint64
@@ -139,6 +143,14 @@ type BackendConfig struct {
 	UnsafeNoFsync bool `json:"unsafe-no-fsync"`
 	// Mlock prevents backend database file to be swapped
 	Mlock bool
+	// OnlineDefrag makes Defrag copy the database while serving reads and
+	// writes, only blocking them to catch up with the last writes and swap
+	// the databases.
+	OnlineDefrag bool
+	// OnlineDefragMaxWriteLogBytes is the maximum size of the writes recorded
+	// while an online defragmentation copies the database, above which the
+	// defragmentation is aborted. DefaultOnlineDefragMaxWriteLogBytes if 0.
+	OnlineDefragMaxWriteLogBytes int
 
 	// Hooks are getting executed during lifecycle of Backend's transactions.
 	Hooks Hooks
@@ -167,26 +179,23 @@ func newBackend(bcfg BackendConfig) *backend {
 		bcfg.Logger = zap.NewNop()
 	}
 
-	bopts := &bolt.Options{}
-	if boltOpenOptions != nil {
-		*bopts = *boltOpenOptions
+	name := bcfg.Engine
+	if name == "" {
+		name = DefaultEngine
 	}
-	bopts.InitialMmapSize = bcfg.mmapSize()
-	bopts.FreelistType = bcfg.BackendFreelistType
-	bopts.NoSync = bcfg.UnsafeNoFsync
-	bopts.NoGrowSync = bcfg.UnsafeNoFsync
-	bopts.Mlock = bcfg.Mlock
-
-	db, err := bolt.Open(bcfg.Path, 0600, bopts)
+	if err := checkEngineMarker(bcfg.Path, name); err != nil {
+		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.String("engine", name), zap.Error(err))
+	}
+	engine, err := OpenEngine(bcfg.Engine, bcfg)
 	if err != nil {
-		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.Error(err))
+		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.String("engine", bcfg.Engine), zap.Error(err))
 	}
 
 	// In future, may want to make buffering optional for low-concurrency systems
 	// or dynamically swap between buffered/non-buffered depending on workload.
 	b := &backend{
-		bopts: bopts,
-		db:    db,
+		bcfg:   bcfg,
+		engine: engine,
 
 		batchInterval: bcfg.BatchInterval,
 		batchLimit:    bcfg.BatchLimit,
@@ -198,7 +207,7 @@ func newBackend(bcfg BackendConfig) *backend {
 					txBuffer:   txBuffer{make(map[BucketID]*bucketBuffer)},
 					bufVersion: 0,
 				},
-				buckets: make(map[BucketID]*bolt.Bucket),
+				buckets: make(map[BucketID]EngineBucket),
 				txWg:    new(sync.WaitGroup),
 				txMu:    new(sync.RWMutex),
 			},
@@ -320,7 +329,7 @@ func (b *backend) Snapshot() Snapshot {
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	tx, err := b.db.Begin(false)
+	tx, err := b.engine.Begin(false)
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
 	}
@@ -364,25 +373,21 @@ func (b *backend) Hash(ignores func(bucketName, keyName []byte) bool) (uint32, e
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	err := b.db.View(func(tx *bolt.Tx) error {
-		c := tx.Cursor()
-		for next, _ := c.First(); next != nil; next, _ = c.Next() {
-			b := tx.Bucket(next)
-			if b == nil {
-				return fmt.Errorf("cannot get hash of bucket %s", string(next))
+	tx, err := b.engine.Begin(false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	err = tx.ForEachBucket(func(next []byte, b EngineBucket) error {
+		h.Write(next)
+		return b.ForEach(func(k, v []byte) error {
+			if ignores != nil && !ignores(next, k) {
+				h.Write(k)
+				h.Write(v)
 			}
-			h.Write(next)
-			b.ForEach(func(k, v []byte) error {
-				if ignores != nil && !ignores(next, k) {
-					h.Write(k)
-					h.Write(v)
-				}
-				return nil
-			})
-		}
-		return nil
+			return nil
+		})
 	})
-
 	if err != nil {
 		return 0, err
 	}
@@ -419,7 +424,7 @@ func (b *backend) run() {
 func (b *backend) Close() error {
 	close(b.stopc)
 	<-b.donec
-	return b.db.Close()
+	return b.engine.Close()
 }
 
 // Commits returns total number of commits since start
@@ -428,6 +433,11 @@ func (b *backend) Commits() int64 {
 }
 
 func (b *backend) Defrag() error {
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
+	if b.bcfg.OnlineDefrag {
+		return b.defragOnline()
+	}
 	return b.defrag()
 }
 
@@ -436,7 +446,6 @@ func (b *backend) defrag() error {
 	isDefragActive.Set(1)
 	defer isDefragActive.Set(0)
 
-	// TODO: make this non-blocking?
 	// lock batchTx to ensure nobody is using previous tx, and then
 	// close previous ongoing tx.
 	b.batchTx.Lock()
@@ -454,51 +463,69 @@ func (b *backend) defrag() error {
 
 	b.batchTx.tx = nil
 
+	tmpdb, err := b.openDefragEngine()
+	if err != nil {
+		return err
+	}
+
+	size1, sizeInUse1 := b.Size(), b.SizeInUse()
+	b.logDefragStart(false)
+	// gofail: var defragBeforeCopy struct{}
+	err = CopyEngine(b.engine, tmpdb, defragLimit)
+	if err != nil {
+		b.removeDefragEngine(tmpdb)
+		return err
+	}
+
+	b.unsafeSwapEngine(tmpdb)
+
+	took := time.Since(now)
+	defragSec.Observe(took.Seconds())
+	defragPauseSec.Observe(took.Seconds())
+	b.logDefragFinish(size1, sizeInUse1, took)
+	return nil
+}
+
+// openDefragEngine creates the temporary database a defragmentation copies
+// the backend to.
+func (b *backend) openDefragEngine() (Engine, error) {
 	// Create a temporary file to ensure we start with a clean slate.
 	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
-	dir := filepath.Dir(b.db.Path())
+	dir := filepath.Dir(b.engine.Path())
 	temp, err := os.CreateTemp(dir, "db.tmp.*")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	options := bolt.Options{}
-	if boltOpenOptions != nil {
-		options = *boltOpenOptions
-	}
-	options.OpenFile = func(_ string, _ int, _ os.FileMode) (file *os.File, err error) {
-		return temp, nil
+	tdbp := temp.Name()
+	if err = temp.Close(); err != nil {
+		return nil, err
 	}
+	tcfg := b.bcfg
+	tcfg.Path = tdbp
 	// Don't load tmp db into memory regardless of opening options
-	options.Mlock = false
-	tdbp := temp.Name()
-	tmpdb, err := bolt.Open(tdbp, 0600, &options)
+	tcfg.Mlock = false
+	tmpdb, err := OpenEngine(b.bcfg.Engine, tcfg)
 	if err != nil {
-		return err
+		os.Remove(tdbp)
+		return nil, err
 	}
+	return tmpdb, nil
+}
 
-	dbp := b.db.Path()
-	size1, sizeInUse1 := b.Size(), b.SizeInUse()
-	if b.lg != nil {
-		b.lg.Info(
-			"defragmenting",
-			zap.String("path", dbp),
-			zap.Int64("current-db-size-bytes", size1),
-			zap.String("current-db-size", humanize.Bytes(uint64(size1))),
-			zap.Int64("current-db-size-in-use-bytes", sizeInUse1),
-			zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse1))),
-		)
-	}
-	// gofail: var defragBeforeCopy struct{}
-	err = defragdb(b.db, tmpdb, defragLimit)
-	if err != nil {
-		tmpdb.Close()
-		if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
-			b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
-		}
-		return err
+func (b *backend) removeDefragEngine(tmpdb Engine) {
+	tmpdb.Close()
+	if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
+		b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
 	}
+}
 
-	err = b.db.Close()
+// unsafeSwapEngine replaces the database of the backend with the defragmented
+// tmpdb and begins new transactions on it. It must be called holding the
+// batch tx, database and read tx locks, with no transaction open.
+func (b *backend) unsafeSwapEngine(tmpdb Engine) {
+	dbp := b.engine.Path()
+	tdbp := tmpdb.Path()
+	err := b.engine.Close()
 	if err != nil {
 		b.lg.Fatal("failed to close database", zap.Error(err))
 	}
@@ -512,7 +539,7 @@ func (b *backend) defrag() error {
 		b.lg.Fatal("failed to rename tmp database", zap.Error(err))
 	}
 
-	b.db, err = bolt.Open(dbp, 0600, b.bopts)
+	b.engine, err = OpenEngine(b.bcfg.Engine, b.bcfg)
 	if err != nil {
 		b.lg.Fatal("failed to open database", zap.String("path", dbp), zap.Error(err))
 	}
@@ -522,106 +549,60 @@ func (b *backend) defrag() error {
 	b.readTx.tx = b.unsafeBegin(false)
 
 	size := b.readTx.tx.Size()
-	db := b.readTx.tx.DB()
 	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(db.Stats().FreePageN)*int64(db.Info().PageSize)))
-
-	took := time.Since(now)
-	defragSec.Observe(took.Seconds())
-
-	size2, sizeInUse2 := b.Size(), b.SizeInUse()
-	if b.lg != nil {
-		b.lg.Info(
-			"finished defragmenting directory",
-			zap.String("path", dbp),
-			zap.Int64("current-db-size-bytes-diff", size2-size1),
-			zap.Int64("current-db-size-bytes", size2),
-			zap.String("current-db-size", humanize.Bytes(uint64(size2))),
-			zap.Int64("current-db-size-in-use-bytes-diff", sizeInUse2-sizeInUse1),
-			zap.Int64("current-db-size-in-use-bytes", sizeInUse2),
-			zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse2))),
-			zap.Duration("took", took),
-		)
-	}
-	return nil
+	atomic.StoreInt64(&b.sizeInUse, size-b.engine.Stats().FreeBytes)
 }
 
-func defragdb(odb, tmpdb *bolt.DB, limit int) error {
-	// open a tx on tmpdb for writes
-	tmptx, err := tmpdb.Begin(true)
-	if err != nil {
-		return err
+func (b *backend) logDefragStart(online bool) {
+	if b.lg == nil {
+		return
 	}
-	defer func() {
-		if err != nil {
-			tmptx.Rollback()
-		}
-	}()
-
-	// open a tx on old db for read
-	tx, err := odb.Begin(false)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	c := tx.Cursor()
-
-	count := 0
-	for next, _ := c.First(); next != nil; next, _ = c.Next() {
-		b := tx.Bucket(next)
-		if b == nil {
-			return fmt.Errorf("backend: cannot defrag bucket %s", string(next))
-		}
+	size, sizeInUse := b.Size(), b.SizeInUse()
+	b.lg.Info(
+		"defragmenting",
+		zap.String("path", b.engine.Path()),
+		zap.Bool("online", online),
+		zap.Int64("current-db-size-bytes", size),
+		zap.String("current-db-size", humanize.Bytes(uint64(size))),
+		zap.Int64("current-db-size-in-use-bytes", sizeInUse),
+		zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse))),
+	)
+}
 
-		tmpb, berr := tmptx.CreateBucketIfNotExists(next)
-		if berr != nil {
-			return berr
-		}
-		tmpb.FillPercent = 0.9 // for bucket2seq write in for each
-
-		if err = b.ForEach(func(k, v []byte) error {
-			count++
-			if count > limit {
-				err = tmptx.Commit()
-				if err != nil {
-					return err
-				}
-				tmptx, err = tmpdb.Begin(true)
-				if err != nil {
-					return err
-				}
-				tmpb = tmptx.Bucket(next)
-				tmpb.FillPercent = 0.9 // for bucket2seq write in for each
-
-				count = 0
-			}
-			return tmpb.Put(k, v)
-		}); err != nil {
-			return err
-		}
+func (b *backend) logDefragFinish(size1, sizeInUse1 int64, took time.Duration) {
+	if b.lg == nil {
+		return
 	}
-
-	return tmptx.Commit()
+	size2, sizeInUse2 := b.Size(), b.SizeInUse()
+	b.lg.Info(
+		"finished defragmenting directory",
+		zap.String("path", b.engine.Path()),
+		zap.Int64("current-db-size-bytes-diff", size2-size1),
+		zap.Int64("current-db-size-bytes", size2),
+		zap.String("current-db-size", humanize.Bytes(uint64(size2))),
+		zap.Int64("current-db-size-in-use-bytes-diff", sizeInUse2-sizeInUse1),
+		zap.Int64("current-db-size-in-use-bytes", sizeInUse2),
+		zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse2))),
+		zap.Duration("took", took),
+	)
 }
 
-func (b *backend) begin(write bool) *bolt.Tx {
+func (b *backend) begin(write bool) EngineTx {
 	b.mu.RLock()
 	tx := b.unsafeBegin(write)
 	b.mu.RUnlock()
 
 	size := tx.Size()
-	db := tx.DB()
-	stats := db.Stats()
+	stats := b.engine.Stats()
 	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(stats.FreePageN)*int64(db.Info().PageSize)))
-	atomic.StoreInt64(&b.openReadTxN, int64(stats.OpenTxN))
+	atomic.StoreInt64(&b.sizeInUse, size-stats.FreeBytes)
+	atomic.StoreInt64(&b.openReadTxN, stats.OpenReadTxN)
 
 	return tx
 }
 
-func (b *backend) unsafeBegin(write bool) *bolt.Tx {
-	tx, err := b.db.Begin(write)
+func (b *backend) unsafeBegin(write bool) EngineTx {
+	tx, err := b.engine.Begin(write)
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
 	}
@@ -633,7 +614,7 @@ func (b *backend) OpenReadTxN() int64 {
 }
 
 type snapshot struct {
-	*bolt.Tx
+	EngineTx
 	stopc chan struct{}
 	donec chan struct{}
 }
@@ -641,5 +622,5 @@ type snapshot struct {
 func (s *snapshot) Close() error {
 	close(s.stopc)
 	<-s.donec
-	return s.Tx.Rollback()
+	return s.EngineTx.Rollback()
 }
@@ -192,6 +192,65 @@ func TestBackendDefrag(t *testing.T) {
 	b.ForceCommit()
 }
 
+// TestBackendOnlineDefrag ensures the writes made while an online
+// defragmentation copies the database are kept.
+func TestBackendOnlineDefrag(t *testing.T) {
+	bcfg := backend.DefaultBackendConfig()
+	bcfg.OnlineDefrag = true
+	b, _ := betesting.NewTmpBackendFromCfg(t, bcfg)
+	defer betesting.Close(t, b)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < backend.DefragLimitForTest()+100; i++ {
+		tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("foo_%d", i)), []byte("bar"))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	// free half of the database, so that the copy is smaller than the
+	// original even with the writes made while it is taken
+	const deleted = 5000
+	tx.Lock()
+	for i := 0; i < deleted; i++ {
+		tx.UnsafeDelete(schema.Test, []byte(fmt.Sprintf("foo_%d", i)))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	size := b.Size()
+
+	donec := make(chan struct{})
+	writes := 0
+	go func() {
+		defer close(donec)
+		for i := 0; i < 5000; i++ {
+			tx.Lock()
+			tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("baz_%d", i)), []byte("bar"))
+			tx.UnsafeDelete(schema.Test, []byte(fmt.Sprintf("foo_%d", deleted+i)))
+			tx.Unlock()
+			writes++
+		}
+	}()
+	if err := b.Defrag(); err != nil {
+		t.Fatal(err)
+	}
+	<-donec
+	b.ForceCommit()
+
+	tx.Lock()
+	keys, _ := tx.UnsafeRange(schema.Test, []byte("baz_"), []byte("baz`"), 0)
+	assert.Len(t, keys, writes)
+	keys, _ = tx.UnsafeRange(schema.Test, []byte("foo_"), []byte("foo`"), 0)
+	assert.Len(t, keys, backend.DefragLimitForTest()+100-deleted-writes)
+	tx.Unlock()
+
+	if nsize := b.Size(); nsize >= size {
+		t.Errorf("new size = %v, want < %d", nsize, size)
+	}
+}
+
 // TestBackendWriteback ensures writes are stored to the read txn on write txn unlock.
 func TestBackendWriteback(t *testing.T) {
 	b, _ := betesting.NewDefaultTmpBackend(t)
@@ -0,0 +1,257 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defragCatchUpRounds is the maximum number of rounds replaying the
+	// writes recorded during an online defragmentation without blocking.
+	defragCatchUpRounds = 10
+	// defragCatchUpWrites is the number of recorded writes below which an
+	// online defragmentation stops catching up and blocks to swap databases.
+	defragCatchUpWrites = 1000
+
+	// DefaultOnlineDefragMaxWriteLogBytes is the default maximum size of the
+	// writes recorded during an online defragmentation.
+	DefaultOnlineDefragMaxWriteLogBytes = 256 * 1024 * 1024
+)
+
+var ErrDefragWriteLogFull = errors.New("backend: too many writes during online defragmentation")
+
+type writeOpType uint8
+
+const (
+	writeOpCreateBucket writeOpType = iota
+	writeOpDeleteBucket
+	writeOpPut
+	writeOpSeqPut
+	writeOpDelete
+)
+
+type writeOp struct {
+	typ    writeOpType
+	bucket []byte
+	key    []byte
+	value  []byte
+}
+
+// writeLog records the writes of the batch tx while an online
+// defragmentation copies the database, up to maxBytes.
+type writeLog struct {
+	ops      []writeOp
+	bytes    int
+	maxBytes int
+	// full is set once the writes exceeded maxBytes; the log stops recording.
+	full bool
+}
+
+func newWriteLog(maxBytes int) *writeLog {
+	if maxBytes <= 0 {
+		maxBytes = DefaultOnlineDefragMaxWriteLogBytes
+	}
+	return &writeLog{maxBytes: maxBytes}
+}
+
+func (l *writeLog) record(typ writeOpType, bucket Bucket, key, value []byte) {
+	if l.full {
+		return
+	}
+	op := writeOp{typ: typ, bucket: bucket.Name()}
+	l.bytes += len(op.bucket) + len(key) + len(value)
+	if l.bytes > l.maxBytes {
+		l.full = true
+		l.ops = nil
+		return
+	}
+	// the caller may reuse key and value once the batch tx is committed
+	if key != nil {
+		op.key = append([]byte(nil), key...)
+	}
+	if value != nil {
+		op.value = append([]byte(nil), value...)
+	}
+	l.ops = append(l.ops, op)
+}
+
+// take returns the recorded writes and clears the log, or ErrDefragWriteLogFull
+// if writes were dropped.
+func (l *writeLog) take() ([]writeOp, error) {
+	if l.full {
+		return nil, ErrDefragWriteLogFull
+	}
+	ops := l.ops
+	l.ops, l.bytes = nil, 0
+	return ops, nil
+}
+
+// replayWriteOps applies ops to dst in order, committing every limit writes.
+func replayWriteOps(dst Engine, ops []writeOp, limit int) error {
+	for len(ops) > 0 {
+		n := len(ops)
+		if n > limit {
+			n = limit
+		}
+		if err := replayWriteOpsTx(dst, ops[:n]); err != nil {
+			return err
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+func replayWriteOpsTx(dst Engine, ops []writeOp) (err error) {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, op := range ops {
+		switch op.typ {
+		case writeOpCreateBucket:
+			if _, err = tx.CreateBucket(op.bucket); err != nil && err != ErrBucketExists {
+				return err
+			}
+		case writeOpDeleteBucket:
+			if err = tx.DeleteBucket(op.bucket); err != nil && err != ErrBucketNotFound {
+				return err
+			}
+		default:
+			b := tx.Bucket(op.bucket)
+			if b == nil {
+				return fmt.Errorf("%w: %q", ErrBucketNotFound, op.bucket)
+			}
+			switch op.typ {
+			case writeOpPut:
+				err = b.Put(op.key, op.value)
+			case writeOpSeqPut:
+				err = b.SeqPut(op.key, op.value)
+			case writeOpDelete:
+				err = b.Delete(op.key)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// defragOnline defragments the backend like defrag, but copies the database
+// from a read-only transaction while reads and writes go on. The batch tx
+// records the writes made meanwhile, which are replayed on the copy in
+// rounds until few enough are left to replay them while blocking reads and
+// writes to swap the databases.
+func (b *backend) defragOnline() error {
+	now := time.Now()
+	isDefragActive.Set(1)
+	defer isDefragActive.Set(0)
+
+	tmpdb, err := b.openDefragEngine()
+	if err != nil {
+		return err
+	}
+
+	// start recording from the state the copy is made of
+	b.batchTx.Lock()
+	b.batchTx.commit(false)
+	stx := b.begin(false)
+	b.batchTx.writeLog = newWriteLog(b.bcfg.OnlineDefragMaxWriteLogBytes)
+	b.batchTx.Unlock()
+
+	size1, sizeInUse1 := b.Size(), b.SizeInUse()
+	b.logDefragStart(true)
+	// gofail: var defragOnlineBeforeCopy struct{}
+	err = copyEngineTx(stx, tmpdb, defragLimit)
+	if rerr := stx.Rollback(); err == nil {
+		err = rerr
+	}
+
+	rounds, replayed := 0, 0
+	for err == nil && rounds < defragCatchUpRounds {
+		rounds++
+		b.batchTx.Lock()
+		ops, terr := b.batchTx.writeLog.take()
+		b.batchTx.Unlock()
+		if terr != nil {
+			err = terr
+			break
+		}
+		err = replayWriteOps(tmpdb, ops, defragLimit)
+		replayed += len(ops)
+		if len(ops) < defragCatchUpWrites {
+			break
+		}
+	}
+	if err != nil {
+		b.batchTx.Lock()
+		b.batchTx.writeLog = nil
+		b.batchTx.Unlock()
+		b.removeDefragEngine(tmpdb)
+		return err
+	}
+
+	pause := time.Now()
+	b.batchTx.Lock()
+	defer b.batchTx.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.readTx.Lock()
+	defer b.readTx.Unlock()
+
+	b.batchTx.unsafeCommit(true)
+	b.batchTx.tx = nil
+
+	ops, err := b.batchTx.writeLog.take()
+	b.batchTx.writeLog = nil
+	if err == nil {
+		err = replayWriteOps(tmpdb, ops, defragLimit)
+	}
+	if err != nil {
+		b.removeDefragEngine(tmpdb)
+		b.batchTx.tx = b.unsafeBegin(true)
+		b.readTx.tx = b.unsafeBegin(false)
+		return err
+	}
+	replayed += len(ops)
+
+	b.unsafeSwapEngine(tmpdb)
+
+	pauseTook, took := time.Since(pause), time.Since(now)
+	defragSec.Observe(took.Seconds())
+	defragPauseSec.Observe(pauseTook.Seconds())
+	if b.lg != nil {
+		b.lg.Info(
+			"caught up with writes during defragmentation",
+			zap.Int("rounds", rounds),
+			zap.Int("replayed-writes", replayed),
+			zap.Int("replayed-writes-in-pause", len(ops)),
+			zap.Duration("pause", pauseTook),
+		)
+	}
+	b.logDefragFinish(size1, sizeInUse1, took)
+	return nil
+}
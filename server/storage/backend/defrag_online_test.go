@@ -0,0 +1,44 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import "testing"
+
+type testBucket string
+
+func (b testBucket) ID() BucketID            { return 100 }
+func (b testBucket) Name() []byte            { return []byte(b) }
+func (b testBucket) String() string          { return string(b) }
+func (b testBucket) IsSafeRangeBucket() bool { return false }
+
+func TestWriteLogFull(t *testing.T) {
+	l := newWriteLog(10)
+	l.record(writeOpPut, testBucket("b"), []byte("k1"), []byte("v1"))
+	ops, err := l.take()
+	if err != nil || len(ops) != 1 {
+		t.Fatalf("expected 1 write, got %v (%v)", ops, err)
+	}
+
+	// the size of the writes taken is released
+	l.record(writeOpPut, testBucket("b"), []byte("k2"), []byte("v2"))
+	l.record(writeOpPut, testBucket("b"), []byte("k3"), []byte("v3"))
+	l.record(writeOpDelete, testBucket("b"), []byte("k2"), nil)
+	if _, err = l.take(); err != ErrDefragWriteLogFull {
+		t.Fatalf("expected %v, got %v", ErrDefragWriteLogFull, err)
+	}
+	if len(l.ops) != 0 {
+		t.Fatalf("expected the writes to be dropped, got %d", len(l.ops))
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// DefaultEngine is the name of the storage engine used when none is configured.
+const DefaultEngine = EngineBolt
+
+var (
+	ErrBucketExists   = errors.New("backend: bucket already exists")
+	ErrBucketNotFound = errors.New("backend: bucket not found")
+	ErrUnknownEngine  = errors.New("backend: unknown storage engine")
+	ErrEngineMismatch = errors.New("backend: storage engine does not match the data")
+)
+
+// Engine is a storage engine persisting the buckets of a Backend. It is an
+// ordered key-value store grouping keys into buckets, with atomic and
+// isolated transactions. At most one writable transaction is open at a time,
+// while any number of read-only transactions may be open concurrently with it.
+//
+// An implementation can be checked with the conformance tests in
+// server/storage/backend/testing.
+type Engine interface {
+	// Path returns the path the engine was opened at.
+	Path() string
+	// Begin starts a transaction. A read-only transaction sees the data
+	// committed before it began, and nothing committed after.
+	Begin(writable bool) (EngineTx, error)
+	// Stats returns the current space and transaction statistics.
+	Stats() EngineStats
+	// Close releases the engine. All transactions must be closed before.
+	Close() error
+}
+
+// EngineStats are statistics of an Engine.
+type EngineStats struct {
+	// FreeBytes is the number of allocated bytes not holding data.
+	FreeBytes int64
+	// OpenReadTxN is the number of open read-only transactions.
+	OpenReadTxN int64
+}
+
+// EngineTx is a transaction of an Engine. Byte slices returned by a
+// transaction are only valid until the transaction ends and must not be
+// modified.
+type EngineTx interface {
+	// Bucket returns the bucket with the given name, or nil if there is none.
+	Bucket(name []byte) EngineBucket
+	// CreateBucket creates a bucket, or returns ErrBucketExists.
+	CreateBucket(name []byte) (EngineBucket, error)
+	// DeleteBucket deletes a bucket and its keys, or returns ErrBucketNotFound.
+	DeleteBucket(name []byte) error
+	// ForEachBucket calls fn for every bucket in ascending name order.
+	ForEachBucket(fn func(name []byte, b EngineBucket) error) error
+	// Size returns the number of bytes allocated by the engine as seen by the
+	// transaction.
+	Size() int64
+	// WriteTo writes a consistent copy of the data seen by the transaction,
+	// which the engine can open as a file.
+	WriteTo(w io.Writer) (int64, error)
+	// Commit commits a writable transaction.
+	Commit() error
+	// Rollback ends a read-only transaction, or discards the changes of a
+	// writable one.
+	Rollback() error
+}
+
+// EngineBucket is a bucket accessed through an EngineTx.
+type EngineBucket interface {
+	// Get returns the value of key, or nil if the key does not exist.
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	// SeqPut is Put for mostly append-only writes, such as revisions, which
+	// the engine may lay out accordingly.
+	SeqPut(key, value []byte) error
+	// Delete deletes key; deleting a missing key is not an error.
+	Delete(key []byte) error
+	// Cursor returns a cursor over the keys of the bucket in ascending order.
+	Cursor() EngineCursor
+	// ForEach calls fn for every key in ascending order.
+	ForEach(fn func(k, v []byte) error) error
+}
+
+// EngineCursor iterates over the keys of a bucket. All methods return nil
+// keys once the cursor moves past either end of the bucket.
+type EngineCursor interface {
+	First() (key, value []byte)
+	Last() (key, value []byte)
+	Next() (key, value []byte)
+	Prev() (key, value []byte)
+	// Seek moves to the first key greater than or equal to key.
+	Seek(key []byte) (key2, value []byte)
+}
+
+// EngineOpenFunc opens the engine data at a path, creating it if missing.
+type EngineOpenFunc func(path string, bcfg BackendConfig) (Engine, error)
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]EngineOpenFunc)
+)
+
+// RegisterEngine makes a storage engine available under the given name to
+// BackendConfig.Engine. It panics if the name is already registered.
+func RegisterEngine(name string, open EngineOpenFunc) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if _, ok := engines[name]; ok {
+		panic(fmt.Sprintf("backend: storage engine %q registered twice", name))
+	}
+	engines[name] = open
+}
+
+// Engines returns the names of the registered storage engines.
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenEngine opens the data at bcfg.Path with the storage engine of the
+// given name, or the default engine if the name is empty.
+func OpenEngine(name string, bcfg BackendConfig) (Engine, error) {
+	if name == "" {
+		name = DefaultEngine
+	}
+	enginesMu.RLock()
+	open, ok := engines[name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q (registered: %v)", ErrUnknownEngine, name, Engines())
+	}
+	return open(bcfg.Path, bcfg)
+}
+
+// CopyEngine copies all buckets of src into dst, committing the writes to dst
+// every limit keys.
+func CopyEngine(src, dst Engine, limit int) error {
+	stx, err := src.Begin(false)
+	if err != nil {
+		return err
+	}
+	defer stx.Rollback()
+	return copyEngineTx(stx, dst, limit)
+}
+
+// copyEngineTx copies all buckets seen by stx into dst, committing the writes
+// to dst every limit keys.
+func copyEngineTx(stx EngineTx, dst Engine, limit int) (err error) {
+	dtx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && dtx != nil {
+			dtx.Rollback()
+		}
+	}()
+
+	count := 0
+	err = stx.ForEachBucket(func(name []byte, b EngineBucket) error {
+		db := dtx.Bucket(name)
+		if db == nil {
+			if db, err = dtx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return b.ForEach(func(k, v []byte) error {
+			count++
+			if count > limit {
+				if err := dtx.Commit(); err != nil {
+					return err
+				}
+				if dtx, err = dst.Begin(true); err != nil {
+					return err
+				}
+				db = dtx.Bucket(name)
+				count = 0
+			}
+			// buckets are copied in key order
+			return db.SeqPut(k, v)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return dtx.Commit()
+}
+
+// engineMarkerSuffix names the file next to a backend that records the storage
+// engine which wrote it. Backends written by bbolt have none, so that their
+// data dirs stay as written by earlier versions.
+const engineMarkerSuffix = ".engine"
+
+// ReadEngineMarker returns the name of the storage engine recorded for the
+// backend at path.
+func ReadEngineMarker(path string) (string, error) {
+	b, err := os.ReadFile(path + engineMarkerSuffix)
+	if os.IsNotExist(err) {
+		return EngineBolt, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// writeEngineMarker records the storage engine of the backend at path.
+func writeEngineMarker(path, name string) error {
+	if name == EngineBolt {
+		if err := os.Remove(path + engineMarkerSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path+engineMarkerSuffix, []byte(name+"\n"), 0600)
+}
+
+// checkEngineMarker checks that the backend at path was written by the
+// storage engine of the given name, recording the engine if the backend is
+// about to be created.
+func checkEngineMarker(path, name string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return writeEngineMarker(path, name)
+	}
+	recorded, err := ReadEngineMarker(path)
+	if err != nil {
+		return err
+	}
+	if recorded != name {
+		return fmt.Errorf("%w: %s was written by %q, not %q", ErrEngineMismatch, path, recorded, name)
+	}
+	return nil
+}
+
+// MigrateEngine converts the backend at path from one storage engine to
+// another: it copies the backend, then replaces it with the copy and records
+// the new engine. The original backend is kept aside until the copy is in
+// place.
+func MigrateEngine(lg *zap.Logger, path, from, to string) error {
+	if from == "" {
+		from = DefaultEngine
+	}
+	if to == "" {
+		to = DefaultEngine
+	}
+	if err := checkEngineMarker(path, from); err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	tmpPath := path + ".migrate"
+	bakPath := path + ".bak"
+	for _, p := range []string{tmpPath, bakPath} {
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+	}
+	if err := copyEngineFile(path, from, tmpPath, to); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	// a crash before the marker is written leaves a backend that refuses to
+	// open, instead of one read by the wrong engine.
+	if err := os.Rename(path, bakPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	if err := writeEngineMarker(path, to); err != nil {
+		return err
+	}
+	lg.Info("migrated backend storage engine", zap.String("path", path), zap.String("from", from), zap.String("to", to))
+	return os.RemoveAll(bakPath)
+}
+
+func copyEngineFile(srcPath, srcEngine, dstPath, dstEngine string) error {
+	src, err := OpenEngine(srcEngine, BackendConfig{Path: srcPath})
+	if err != nil {
+		return fmt.Errorf("failed to open backend with engine %q: %w", srcEngine, err)
+	}
+	defer src.Close()
+	dst, err := OpenEngine(dstEngine, BackendConfig{Path: dstPath})
+	if err != nil {
+		return fmt.Errorf("failed to create backend with engine %q: %w", dstEngine, err)
+	}
+	if err = CopyEngine(src, dst, defragLimit); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to copy backend: %w", err)
+	}
+	return dst.Close()
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// EngineBolt is the name of the bbolt storage engine.
+const EngineBolt = "bbolt"
+
+func init() {
+	RegisterEngine(EngineBolt, openBoltEngine)
+}
+
+type boltEngine struct {
+	db *bolt.DB
+}
+
+func openBoltEngine(path string, bcfg BackendConfig) (Engine, error) {
+	bopts := &bolt.Options{}
+	if boltOpenOptions != nil {
+		*bopts = *boltOpenOptions
+	}
+	bopts.InitialMmapSize = bcfg.mmapSize()
+	bopts.FreelistType = bcfg.BackendFreelistType
+	bopts.NoSync = bcfg.UnsafeNoFsync
+	bopts.NoGrowSync = bcfg.UnsafeNoFsync
+	bopts.Mlock = bcfg.Mlock
+
+	db, err := bolt.Open(path, 0600, bopts)
+	if err != nil {
+		return nil, err
+	}
+	return &boltEngine{db: db}, nil
+}
+
+func (e *boltEngine) Path() string { return e.db.Path() }
+
+func (e *boltEngine) Begin(writable bool) (EngineTx, error) {
+	tx, err := e.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &boltTx{tx}, nil
+}
+
+func (e *boltEngine) Stats() EngineStats {
+	stats := e.db.Stats()
+	return EngineStats{
+		FreeBytes:   int64(stats.FreePageN) * int64(e.db.Info().PageSize),
+		OpenReadTxN: int64(stats.OpenTxN),
+	}
+}
+
+func (e *boltEngine) Close() error { return e.db.Close() }
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t *boltTx) Bucket(name []byte) EngineBucket {
+	b := t.tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return boltBucket{b}
+}
+
+func (t *boltTx) CreateBucket(name []byte) (EngineBucket, error) {
+	b, err := t.tx.CreateBucket(name)
+	switch err {
+	case nil:
+		return boltBucket{b}, nil
+	case bolt.ErrBucketExists:
+		return nil, ErrBucketExists
+	default:
+		return nil, err
+	}
+}
+
+func (t *boltTx) DeleteBucket(name []byte) error {
+	err := t.tx.DeleteBucket(name)
+	if err == bolt.ErrBucketNotFound {
+		return ErrBucketNotFound
+	}
+	return err
+}
+
+func (t *boltTx) ForEachBucket(fn func(name []byte, b EngineBucket) error) error {
+	return t.tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return fn(name, boltBucket{b})
+	})
+}
+
+func (t *boltTx) Size() int64 { return t.tx.Size() }
+
+func (t *boltTx) WriteTo(w io.Writer) (int64, error) { return t.tx.WriteTo(w) }
+
+func (t *boltTx) Commit() error {
+	err := t.tx.Commit()
+	stats := t.tx.Stats()
+	rebalanceSec.Observe(stats.RebalanceTime.Seconds())
+	spillSec.Observe(stats.SpillTime.Seconds())
+	writeSec.Observe(stats.WriteTime.Seconds())
+	return err
+}
+
+func (t *boltTx) Rollback() error { return t.tx.Rollback() }
+
+type boltBucket struct {
+	*bolt.Bucket
+}
+
+func (b boltBucket) SeqPut(key, value []byte) error {
+	// it is useful to increase fill percent when the workloads are mostly append-only.
+	// this can delay the page split and reduce space usage.
+	b.FillPercent = 0.9
+	return b.Put(key, value)
+}
+
+func (b boltBucket) Cursor() EngineCursor { return b.Bucket.Cursor() }
@@ -0,0 +1,124 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func TestBoltEngineConformance(t *testing.T) {
+	betesting.RunEngineConformanceTests(t, backend.EngineBolt)
+}
+
+func TestMemoryEngineConformance(t *testing.T) {
+	betesting.RunEngineConformanceTests(t, betesting.EngineMemory)
+}
+
+func TestOpenUnknownEngine(t *testing.T) {
+	bcfg := backend.DefaultBackendConfig()
+	bcfg.Path = filepath.Join(t.TempDir(), "database")
+	if _, err := backend.OpenEngine("unknown", bcfg); !errors.Is(err, backend.ErrUnknownEngine) {
+		t.Fatalf("expected %v, got %v", backend.ErrUnknownEngine, err)
+	}
+}
+
+func TestMigrateEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database")
+	be := newEngineBackend(t, path, backend.EngineBolt)
+	tx := be.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Key)
+	for i := 0; i < 25000; i++ {
+		tx.UnsafeSeqPut(schema.Key, []byte(fmt.Sprintf("key%06d", i)), []byte(fmt.Sprintf("val%06d", i)))
+	}
+	tx.Unlock()
+	be.ForceCommit()
+	whash := hashEngineBackend(t, be)
+	be.Close()
+
+	// convert the bbolt backend to the test engine and back
+	engines := []string{backend.EngineBolt, betesting.EngineMemory, backend.EngineBolt}
+	for i := 1; i < len(engines); i++ {
+		from, to := engines[i-1], engines[i]
+		if err := backend.MigrateEngine(zaptest.NewLogger(t), path, from, to); err != nil {
+			t.Fatalf("failed to migrate from %q to %q: %v", from, to, err)
+		}
+		for _, p := range []string{path + ".migrate", path + ".bak"} {
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed after migrating to %q, got %v", p, to, err)
+			}
+		}
+		if engine, err := backend.ReadEngineMarker(path); err != nil || engine != to {
+			t.Errorf("expected engine %q recorded after migrating to %q, got %q (%v)", to, to, engine, err)
+		}
+
+		be = newEngineBackend(t, path, to)
+		if hash := hashEngineBackend(t, be); hash != whash {
+			t.Errorf("expected hash %d after migrating to %q, got %d", whash, to, hash)
+		}
+		be.Close()
+	}
+
+	if err := backend.MigrateEngine(zaptest.NewLogger(t), path, betesting.EngineMemory, backend.EngineBolt); !errors.Is(err, backend.ErrEngineMismatch) {
+		t.Errorf("expected %v, got %v", backend.ErrEngineMismatch, err)
+	}
+}
+
+func TestEngineMarkerMismatch(t *testing.T) {
+	tests := []struct {
+		create, open string
+	}{
+		{betesting.EngineMemory, backend.EngineBolt},
+		{backend.EngineBolt, betesting.EngineMemory},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "database")
+		newEngineBackend(t, path, tt.create).Close()
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("expected opening the backend of %q with %q to fail", tt.create, tt.open)
+				}
+			}()
+			newEngineBackend(t, path, tt.open).Close()
+		}()
+	}
+}
+
+func newEngineBackend(t *testing.T, path, engine string) backend.Backend {
+	bcfg := backend.DefaultBackendConfig()
+	bcfg.Path = path
+	bcfg.Engine = engine
+	bcfg.Logger = zaptest.NewLogger(t)
+	return backend.New(bcfg)
+}
+
+func hashEngineBackend(t *testing.T, be backend.Backend) uint32 {
+	hash, err := be.Hash(func(bucketName, keyName []byte) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
@@ -3,7 +3,7 @@ package backend
 import bolt "go.etcd.io/bbolt"
 
 func DbFromBackendForTest(b Backend) *bolt.DB {
-	return b.(*backend).db
+	return b.(*backend).engine.(*boltEngine).db
 }
 
 func DefragLimitForTest() int {
@@ -73,6 +73,17 @@ var (
 		Buckets: prometheus.ExponentialBuckets(.1, 2, 13),
 	})
 
+	defragPauseSec = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "backend_defrag_pause_duration_seconds",
+		Help:      "The latency distribution of the time reads and writes are blocked by backend defragmentation.",
+
+		// lowest bucket start of upper bound 0.001 sec (1 ms) with factor 2
+		// highest bucket start of 0.001 sec * 2^18 == 262.144 sec
+		Buckets: prometheus.ExponentialBuckets(.001, 2, 19),
+	})
+
 	snapshotTransferSec = prometheus.NewHistogram(prometheus.HistogramOpts{
 		Namespace: "etcd",
 		Subsystem: "disk",
@@ -98,6 +109,7 @@ func init() {
 	prometheus.MustRegister(spillSec)
 	prometheus.MustRegister(writeSec)
 	prometheus.MustRegister(defragSec)
+	prometheus.MustRegister(defragPauseSec)
 	prometheus.MustRegister(snapshotTransferSec)
 	prometheus.MustRegister(isDefragActive)
 }
@@ -0,0 +1,327 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package betesting
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.uber.org/zap/zaptest"
+)
+
+// RunEngineConformanceTests checks that the storage engine registered under
+// the given name fulfills the backend.Engine contract and runs a Backend.
+func RunEngineConformanceTests(t *testing.T, engine string) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, engine string)
+	}{
+		{"Buckets", testEngineBuckets},
+		{"PutGetDelete", testEnginePutGetDelete},
+		{"Cursor", testEngineCursor},
+		{"Isolation", testEngineIsolation},
+		{"Reopen", testEngineReopen},
+		{"WriteTo", testEngineWriteTo},
+		{"Copy", testEngineCopy},
+		{"Backend", testEngineBackend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, engine)
+		})
+	}
+}
+
+func openTestEngine(t *testing.T, engine, path string) backend.Engine {
+	bcfg := backend.DefaultBackendConfig()
+	bcfg.Path = path
+	bcfg.Logger = zaptest.NewLogger(t)
+	e, err := backend.OpenEngine(engine, bcfg)
+	require.NoError(t, err)
+	return e
+}
+
+func newTestEngine(t *testing.T, engine string) backend.Engine {
+	e := openTestEngine(t, engine, filepath.Join(t.TempDir(), "database"))
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+// update runs fn in a writable transaction and commits it.
+func update(t *testing.T, e backend.Engine, fn func(tx backend.EngineTx)) {
+	tx, err := e.Begin(true)
+	require.NoError(t, err)
+	fn(tx)
+	require.NoError(t, tx.Commit())
+}
+
+// view runs fn in a read-only transaction.
+func view(t *testing.T, e backend.Engine, fn func(tx backend.EngineTx)) {
+	tx, err := e.Begin(false)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	fn(tx)
+}
+
+func putKeys(t *testing.T, b backend.EngineBucket, n int) {
+	for i := 0; i < n; i++ {
+		require.NoError(t, b.Put([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("val%03d", i))))
+	}
+}
+
+func bucketKeys(t *testing.T, b backend.EngineBucket) []string {
+	var keys []string
+	require.NoError(t, b.ForEach(func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	}))
+	return keys
+}
+
+func testEngineBuckets(t *testing.T, engine string) {
+	e := newTestEngine(t, engine)
+	update(t, e, func(tx backend.EngineTx) {
+		assert.Nil(t, tx.Bucket([]byte("b")))
+		for _, name := range []string{"c", "a", "b"} {
+			_, err := tx.CreateBucket([]byte(name))
+			require.NoError(t, err)
+		}
+		_, err := tx.CreateBucket([]byte("a"))
+		assert.Equal(t, backend.ErrBucketExists, err)
+		assert.Equal(t, backend.ErrBucketNotFound, tx.DeleteBucket([]byte("d")))
+		require.NoError(t, tx.DeleteBucket([]byte("c")))
+	})
+	view(t, e, func(tx backend.EngineTx) {
+		assert.NotNil(t, tx.Bucket([]byte("a")))
+		assert.Nil(t, tx.Bucket([]byte("c")))
+		var names []string
+		require.NoError(t, tx.ForEachBucket(func(name []byte, b backend.EngineBucket) error {
+			names = append(names, string(name))
+			return nil
+		}))
+		assert.Equal(t, []string{"a", "b"}, names)
+	})
+}
+
+func testEnginePutGetDelete(t *testing.T, engine string) {
+	e := newTestEngine(t, engine)
+	update(t, e, func(tx backend.EngineTx) {
+		b, err := tx.CreateBucket([]byte("test"))
+		require.NoError(t, err)
+		require.NoError(t, b.Put([]byte("foo"), []byte("bar")))
+		assert.Equal(t, []byte("bar"), b.Get([]byte("foo")))
+		require.NoError(t, b.Put([]byte("foo"), []byte("baz")))
+		require.NoError(t, b.SeqPut([]byte("seq"), []byte("1")))
+		require.NoError(t, b.Put([]byte("del"), []byte("x")))
+		require.NoError(t, b.Delete([]byte("del")))
+		require.NoError(t, b.Delete([]byte("missing")))
+	})
+	view(t, e, func(tx backend.EngineTx) {
+		b := tx.Bucket([]byte("test"))
+		require.NotNil(t, b)
+		assert.Equal(t, []byte("baz"), b.Get([]byte("foo")))
+		assert.Equal(t, []byte("1"), b.Get([]byte("seq")))
+		assert.Nil(t, b.Get([]byte("del")))
+		assert.Equal(t, []string{"foo", "seq"}, bucketKeys(t, b))
+	})
+}
+
+func testEngineCursor(t *testing.T, engine string) {
+	e := newTestEngine(t, engine)
+	update(t, e, func(tx backend.EngineTx) {
+		b, err := tx.CreateBucket([]byte("test"))
+		require.NoError(t, err)
+		for _, k := range []string{"b", "d", "a", "c"} {
+			require.NoError(t, b.Put([]byte(k), []byte("v"+k)))
+		}
+	})
+	view(t, e, func(tx backend.EngineTx) {
+		c := tx.Bucket([]byte("test")).Cursor()
+
+		var keys []string
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			keys = append(keys, string(k))
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+
+		keys = nil
+		for k, _ := c.Last(); k != nil; k, _ = c.Prev() {
+			keys = append(keys, string(k))
+		}
+		assert.Equal(t, []string{"d", "c", "b", "a"}, keys)
+
+		k, v := c.Seek([]byte("c"))
+		assert.Equal(t, "c", string(k))
+		assert.Equal(t, "vc", string(v))
+		k, _ = c.Seek([]byte("bb"))
+		assert.Equal(t, "c", string(k))
+		k, _ = c.Seek([]byte("e"))
+		assert.Nil(t, k)
+	})
+}
+
+func testEngineIsolation(t *testing.T, engine string) {
+	e := newTestEngine(t, engine)
+	update(t, e, func(tx backend.EngineTx) {
+		b, err := tx.CreateBucket([]byte("test"))
+		require.NoError(t, err)
+		require.NoError(t, b.Put([]byte("foo"), []byte("old")))
+	})
+
+	// a discarded transaction leaves no trace
+	wtx, err := e.Begin(true)
+	require.NoError(t, err)
+	require.NoError(t, wtx.Bucket([]byte("test")).Put([]byte("foo"), []byte("discarded")))
+	require.NoError(t, wtx.Rollback())
+
+	rtx, err := e.Begin(false)
+	require.NoError(t, err)
+	defer rtx.Rollback()
+
+	// a read transaction does not see writes committed after it began
+	update(t, e, func(tx backend.EngineTx) {
+		require.NoError(t, tx.Bucket([]byte("test")).Put([]byte("foo"), []byte("new")))
+		assert.Equal(t, []byte("old"), rtx.Bucket([]byte("test")).Get([]byte("foo")))
+	})
+	assert.Equal(t, []byte("old"), rtx.Bucket([]byte("test")).Get([]byte("foo")))
+	assert.GreaterOrEqual(t, e.Stats().OpenReadTxN, int64(1))
+
+	view(t, e, func(tx backend.EngineTx) {
+		assert.Equal(t, []byte("new"), tx.Bucket([]byte("test")).Get([]byte("foo")))
+	})
+}
+
+func testEngineReopen(t *testing.T, engine string) {
+	path := filepath.Join(t.TempDir(), "database")
+	e := openTestEngine(t, engine, path)
+	assert.Equal(t, path, e.Path())
+	update(t, e, func(tx backend.EngineTx) {
+		b, err := tx.CreateBucket([]byte("test"))
+		require.NoError(t, err)
+		putKeys(t, b, 100)
+	})
+	require.NoError(t, e.Close())
+
+	e = openTestEngine(t, engine, path)
+	defer e.Close()
+	view(t, e, func(tx backend.EngineTx) {
+		b := tx.Bucket([]byte("test"))
+		require.NotNil(t, b)
+		assert.Len(t, bucketKeys(t, b), 100)
+		assert.Greater(t, tx.Size(), int64(0))
+	})
+}
+
+func testEngineWriteTo(t *testing.T, engine string) {
+	e := newTestEngine(t, engine)
+	update(t, e, func(tx backend.EngineTx) {
+		b, err := tx.CreateBucket([]byte("test"))
+		require.NoError(t, err)
+		putKeys(t, b, 100)
+	})
+
+	var buf bytes.Buffer
+	view(t, e, func(tx backend.EngineTx) {
+		n, err := tx.WriteTo(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+	})
+	path := filepath.Join(t.TempDir(), "snapshot")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+	snap := openTestEngine(t, engine, path)
+	defer snap.Close()
+	view(t, snap, func(tx backend.EngineTx) {
+		b := tx.Bucket([]byte("test"))
+		require.NotNil(t, b)
+		assert.Len(t, bucketKeys(t, b), 100)
+	})
+}
+
+func testEngineCopy(t *testing.T, engine string) {
+	src, dst := newTestEngine(t, engine), newTestEngine(t, engine)
+	update(t, src, func(tx backend.EngineTx) {
+		for _, name := range []string{"a", "b"} {
+			b, err := tx.CreateBucket([]byte(name))
+			require.NoError(t, err)
+			putKeys(t, b, 25)
+		}
+	})
+	require.NoError(t, backend.CopyEngine(src, dst, 10))
+	view(t, dst, func(tx backend.EngineTx) {
+		for _, name := range []string{"a", "b"} {
+			b := tx.Bucket([]byte(name))
+			require.NotNil(t, b)
+			assert.Len(t, bucketKeys(t, b), 25)
+			assert.Equal(t, []byte("val007"), b.Get([]byte("key007")))
+		}
+	})
+}
+
+type testBucket struct{}
+
+func (testBucket) ID() backend.BucketID    { return 1 }
+func (testBucket) Name() []byte            { return []byte("test") }
+func (testBucket) String() string          { return "test" }
+func (testBucket) IsSafeRangeBucket() bool { return true }
+
+func testEngineBackend(t *testing.T, engine string) {
+	bcfg := backend.DefaultBackendConfig()
+	bcfg.Engine = engine
+	bcfg.BatchInterval, bcfg.BatchLimit = time.Hour, 10000
+	b, path := NewTmpBackendFromCfg(t, bcfg)
+
+	bucket := testBucket{}
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(bucket)
+	for i := 0; i < 100; i++ {
+		tx.UnsafeSeqPut(bucket, []byte(fmt.Sprintf("key%03d", i)), []byte("val"))
+	}
+	for i := 0; i < 50; i++ {
+		tx.UnsafeDelete(bucket, []byte(fmt.Sprintf("key%03d", i)))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	hash, err := b.Hash(nil)
+	require.NoError(t, err)
+	require.NoError(t, b.Defrag())
+	hash2, err := b.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, hash, hash2)
+	assert.Greater(t, b.Size(), int64(0))
+
+	rtx := b.ReadTx()
+	rtx.RLock()
+	keys, _ := rtx.UnsafeRange(bucket, []byte("key"), []byte("kez"), 0)
+	rtx.RUnlock()
+	assert.Len(t, keys, 50)
+	require.NoError(t, b.Close())
+
+	bcfg.Path = path
+	b = backend.New(bcfg)
+	defer b.Close()
+	hash3, err := b.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, hash, hash3)
+}
@@ -0,0 +1,379 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package betesting
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// EngineMemory is the name of a storage engine keeping the buckets in memory
+// and writing all of them to its file on every commit. It exercises the
+// engine-agnostic code of the backend in tests and is not meant for production.
+const EngineMemory = "betesting-memory"
+
+var errTxNotWritable = errors.New("betesting: transaction not writable")
+
+func init() {
+	backend.RegisterEngine(EngineMemory, openMemoryEngine)
+}
+
+// memBucketData is the content of a bucket. Once committed it is shared by
+// the transactions and never modified; a writable transaction clones it first.
+type memBucketData struct {
+	keys []string
+	vals map[string][]byte
+}
+
+func newMemBucketData() *memBucketData {
+	return &memBucketData{vals: make(map[string][]byte)}
+}
+
+func (d *memBucketData) clone() *memBucketData {
+	c := &memBucketData{keys: append([]string(nil), d.keys...), vals: make(map[string][]byte, len(d.vals))}
+	for k, v := range d.vals {
+		c.vals[k] = v
+	}
+	return c
+}
+
+type memEngine struct {
+	path string
+	// writeMu is held by the open writable transaction.
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	buckets map[string]*memBucketData
+	size    int64
+
+	openReadTxN int64
+}
+
+func openMemoryEngine(path string, _ backend.BackendConfig) (backend.Engine, error) {
+	e := &memEngine{path: path, buckets: make(map[string]*memBucketData)}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return e, e.save(e.buckets)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var file []memFileBucket
+	switch err = gob.NewDecoder(f).Decode(&file); err {
+	case nil:
+	case io.EOF:
+		// an empty file, such as the one created for a defragmentation
+		return e, e.save(e.buckets)
+	default:
+		return nil, err
+	}
+	for _, fb := range file {
+		d := newMemBucketData()
+		for i, k := range fb.Keys {
+			d.keys = append(d.keys, k)
+			d.vals[k] = fb.Vals[i]
+		}
+		e.buckets[fb.Name] = d
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	e.size = fi.Size()
+	return e, nil
+}
+
+func (e *memEngine) Path() string { return e.path }
+
+func (e *memEngine) Begin(writable bool) (backend.EngineTx, error) {
+	if writable {
+		e.writeMu.Lock()
+	} else {
+		atomic.AddInt64(&e.openReadTxN, 1)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	tx := &memTx{e: e, writable: writable, buckets: e.buckets, size: e.size}
+	if writable {
+		tx.buckets = make(map[string]*memBucketData, len(e.buckets))
+		for name, d := range e.buckets {
+			tx.buckets[name] = d
+		}
+		tx.owned = make(map[string]bool)
+	}
+	return tx, nil
+}
+
+func (e *memEngine) Stats() backend.EngineStats {
+	return backend.EngineStats{OpenReadTxN: atomic.LoadInt64(&e.openReadTxN)}
+}
+
+func (e *memEngine) Close() error { return nil }
+
+// save writes the buckets to the file of the engine, replacing it.
+func (e *memEngine) save(buckets map[string]*memBucketData) error {
+	tmp := e.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	n, err := writeMemBuckets(f, buckets)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, e.path); err != nil {
+		return err
+	}
+	e.size = n
+	return nil
+}
+
+// memFileBucket is the encoding of a bucket in the file of the engine.
+type memFileBucket struct {
+	Name string
+	Keys []string
+	Vals [][]byte
+}
+
+func writeMemBuckets(w io.Writer, buckets map[string]*memBucketData) (int64, error) {
+	file := make([]memFileBucket, 0, len(buckets))
+	for _, name := range sortedBucketNames(buckets) {
+		d := buckets[name]
+		fb := memFileBucket{Name: name, Keys: d.keys, Vals: make([][]byte, len(d.keys))}
+		for i, k := range d.keys {
+			fb.Vals[i] = d.vals[k]
+		}
+		file = append(file, fb)
+	}
+	cw := &countingWriter{w: w}
+	err := gob.NewEncoder(cw).Encode(file)
+	return cw.n, err
+}
+
+func sortedBucketNames(buckets map[string]*memBucketData) []string {
+	names := make([]string, 0, len(buckets))
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type memTx struct {
+	e        *memEngine
+	writable bool
+	done     bool
+	buckets  map[string]*memBucketData
+	// owned records the buckets cloned by a writable transaction.
+	owned map[string]bool
+	size  int64
+}
+
+func (t *memTx) Bucket(name []byte) backend.EngineBucket {
+	if _, ok := t.buckets[string(name)]; !ok {
+		return nil
+	}
+	return &memBucket{tx: t, name: string(name)}
+}
+
+func (t *memTx) CreateBucket(name []byte) (backend.EngineBucket, error) {
+	if !t.writable {
+		return nil, errTxNotWritable
+	}
+	if _, ok := t.buckets[string(name)]; ok {
+		return nil, backend.ErrBucketExists
+	}
+	t.buckets[string(name)] = newMemBucketData()
+	t.owned[string(name)] = true
+	return &memBucket{tx: t, name: string(name)}, nil
+}
+
+func (t *memTx) DeleteBucket(name []byte) error {
+	if !t.writable {
+		return errTxNotWritable
+	}
+	if _, ok := t.buckets[string(name)]; !ok {
+		return backend.ErrBucketNotFound
+	}
+	delete(t.buckets, string(name))
+	delete(t.owned, string(name))
+	return nil
+}
+
+func (t *memTx) ForEachBucket(fn func(name []byte, b backend.EngineBucket) error) error {
+	for _, name := range sortedBucketNames(t.buckets) {
+		if err := fn([]byte(name), &memBucket{tx: t, name: name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *memTx) Size() int64 { return t.size }
+
+func (t *memTx) WriteTo(w io.Writer) (int64, error) { return writeMemBuckets(w, t.buckets) }
+
+func (t *memTx) Commit() error {
+	if !t.writable {
+		return errTxNotWritable
+	}
+	if t.done {
+		return nil
+	}
+	t.done = true
+	defer t.e.writeMu.Unlock()
+	t.e.mu.Lock()
+	defer t.e.mu.Unlock()
+	if err := t.e.save(t.buckets); err != nil {
+		return err
+	}
+	t.e.buckets = t.buckets
+	return nil
+}
+
+func (t *memTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	if t.writable {
+		t.e.writeMu.Unlock()
+	} else {
+		atomic.AddInt64(&t.e.openReadTxN, -1)
+	}
+	return nil
+}
+
+type memBucket struct {
+	tx   *memTx
+	name string
+}
+
+func (b *memBucket) data() *memBucketData { return b.tx.buckets[b.name] }
+
+// mutable returns the data of the bucket for writing, cloning it once per
+// transaction so that the committed data stays untouched.
+func (b *memBucket) mutable() (*memBucketData, error) {
+	if !b.tx.writable {
+		return nil, errTxNotWritable
+	}
+	d, ok := b.tx.buckets[b.name]
+	if !ok {
+		return nil, backend.ErrBucketNotFound
+	}
+	if !b.tx.owned[b.name] {
+		d = d.clone()
+		b.tx.buckets[b.name] = d
+		b.tx.owned[b.name] = true
+	}
+	return d, nil
+}
+
+func (b *memBucket) Get(key []byte) []byte { return b.data().vals[string(key)] }
+
+func (b *memBucket) Put(key, value []byte) error {
+	d, err := b.mutable()
+	if err != nil {
+		return err
+	}
+	k := string(key)
+	if _, ok := d.vals[k]; !ok {
+		i := sort.SearchStrings(d.keys, k)
+		d.keys = append(d.keys, "")
+		copy(d.keys[i+1:], d.keys[i:])
+		d.keys[i] = k
+	}
+	d.vals[k] = append([]byte{}, value...)
+	return nil
+}
+
+func (b *memBucket) SeqPut(key, value []byte) error { return b.Put(key, value) }
+
+func (b *memBucket) Delete(key []byte) error {
+	d, err := b.mutable()
+	if err != nil {
+		return err
+	}
+	k := string(key)
+	if _, ok := d.vals[k]; !ok {
+		return nil
+	}
+	i := sort.SearchStrings(d.keys, k)
+	d.keys = append(d.keys[:i], d.keys[i+1:]...)
+	delete(d.vals, k)
+	return nil
+}
+
+func (b *memBucket) Cursor() backend.EngineCursor { return &memCursor{b: b} }
+
+func (b *memBucket) ForEach(fn func(k, v []byte) error) error {
+	d := b.data()
+	for _, k := range d.keys {
+		if err := fn([]byte(k), d.vals[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memCursor struct {
+	b *memBucket
+	i int
+}
+
+func (c *memCursor) at(i int) (key, value []byte) {
+	d := c.b.data()
+	if i < 0 || i >= len(d.keys) {
+		c.i = len(d.keys)
+		return nil, nil
+	}
+	c.i = i
+	return []byte(d.keys[i]), d.vals[d.keys[i]]
+}
+
+func (c *memCursor) First() (key, value []byte) { return c.at(0) }
+func (c *memCursor) Last() (key, value []byte)  { return c.at(len(c.b.data().keys) - 1) }
+func (c *memCursor) Next() (key, value []byte)  { return c.at(c.i + 1) }
+func (c *memCursor) Prev() (key, value []byte)  { return c.at(c.i - 1) }
+
+func (c *memCursor) Seek(key []byte) (key2, value []byte) {
+	return c.at(sort.SearchStrings(c.b.data().keys, string(key)))
+}
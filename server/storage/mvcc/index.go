@@ -30,8 +30,8 @@ type index interface {
 	Put(key []byte, rev revision)
 	Tombstone(key []byte, rev revision) error
 	RangeSince(key, end []byte, rev int64) []revision
-	Compact(rev int64) map[revision]struct{}
-	Keep(rev int64) map[revision]struct{}
+	Compact(rev int64, rr *retentionRevs) map[revision]struct{}
+	Keep(rev int64, rr *retentionRevs) map[revision]struct{}
 	Equal(b index) bool
 
 	Insert(ki *keyIndex)
@@ -209,9 +209,16 @@ func (ti *treeIndex) RangeSince(key, end []byte, rev int64) []revision {
 	return revs
 }
 
-func (ti *treeIndex) Compact(rev int64) map[revision]struct{} {
+// Compact compacts the keys at rev, or at the revision of their retention rule
+// if rr is not nil, and returns the revisions up to the newest revision any
+// key is compacted at that must be kept.
+func (ti *treeIndex) Compact(rev int64, rr *retentionRevs) map[revision]struct{} {
 	available := make(map[revision]struct{})
 	ti.lg.Info("compact tree index", zap.Int64("revision", rev))
+	end := rev
+	if rr != nil {
+		end = rr.endRev()
+	}
 	ti.Lock()
 	clone := ti.tree.Clone()
 	ti.Unlock()
@@ -221,7 +228,12 @@ func (ti *treeIndex) Compact(rev int64) map[revision]struct{} {
 		// Lock is needed here to prevent modification to the keyIndex while
 		// compaction is going on or revision added to empty before deletion
 		ti.Lock()
-		keyi.compact(ti.lg, rev, available)
+		atRev := rev
+		if rr != nil {
+			atRev = rr.keyRev(keyi.key)
+		}
+		keyi.compact(ti.lg, atRev, available)
+		keyi.keepSince(atRev, end, available)
 		if keyi.isEmpty() {
 			item := ti.tree.Delete(keyi)
 			if item == nil {
@@ -235,13 +247,22 @@ func (ti *treeIndex) Compact(rev int64) map[revision]struct{} {
 }
 
 // Keep finds all revisions to be kept for a Compaction at the given rev.
-func (ti *treeIndex) Keep(rev int64) map[revision]struct{} {
+// If rr is not nil, the revisions newer than the revision of the retention
+// rule of their key are kept too, up to the newest compacted revision.
+func (ti *treeIndex) Keep(rev int64, rr *retentionRevs) map[revision]struct{} {
 	available := make(map[revision]struct{})
 	ti.RLock()
 	defer ti.RUnlock()
+	var end int64
+	if rr != nil {
+		end = rr.endRev()
+	}
 	ti.tree.Ascend(func(i btree.Item) bool {
 		keyi := i.(*keyIndex)
 		keyi.keep(rev, available)
+		if rr != nil {
+			keyi.keepSince(rr.keyRev(keyi.key), end, available)
+		}
 		return true
 	})
 	return available
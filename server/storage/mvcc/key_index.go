@@ -244,6 +244,20 @@ func (ki *keyIndex) keep(atRev int64, available map[revision]struct{}) {
 	}
 }
 
+// keepSince adds the revisions in (atRev, endRev] to available.
+func (ki *keyIndex) keepSince(atRev, endRev int64, available map[revision]struct{}) {
+	if atRev >= endRev {
+		return
+	}
+	for _, g := range ki.generations {
+		for _, rev := range g.revs {
+			if rev.main > atRev && rev.main <= endRev {
+				available[rev] = struct{}{}
+			}
+		}
+	}
+}
+
 func (ki *keyIndex) doCompact(atRev int64, available map[revision]struct{}) (genIdx int, revIndex int) {
 	// walk until reaching the first revision smaller or equal to "atRev",
 	// and add the revision to the available map
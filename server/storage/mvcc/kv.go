@@ -16,6 +16,7 @@ package mvcc
 
 import (
 	"context"
+	"time"
 
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/pkg/v3/traceutil"
@@ -27,6 +28,8 @@ type RangeOptions struct {
 	Limit int64
 	Rev   int64
 	Count bool
+	// Index selects keys by the value of a secondary index field.
+	Index IndexRange
 }
 
 type RangeResult struct {
@@ -85,6 +88,9 @@ type WriteView interface {
 type TxnWrite interface {
 	TxnRead
 	WriteView
+	// DeleteExpired deletes the key attached to the expired lease with given ID.
+	// The DELETE event of the key is marked as caused by the lease expiry.
+	DeleteExpired(key []byte, id lease.LeaseID) (n, rev int64)
 	// Changes gets the changes made since opening the write txn.
 	Changes() []mvccpb.KeyValue
 }
@@ -93,6 +99,9 @@ type TxnWrite interface {
 type txnReadWrite struct{ TxnRead }
 
 func (trw *txnReadWrite) DeleteRange(key, end []byte) (n, rev int64) { panic("unexpected DeleteRange") }
+func (trw *txnReadWrite) DeleteExpired(key []byte, id lease.LeaseID) (n, rev int64) {
+	panic("unexpected DeleteExpired")
+}
 func (trw *txnReadWrite) Put(key, value []byte, lease lease.LeaseID) (rev int64) {
 	panic("unexpected Put")
 }
@@ -126,8 +135,32 @@ type KV interface {
 	HashByRev(rev int64) (hash uint32, revision int64, compactRev int64, err error)
 
 	// Compact frees all superseded keys with revisions less than rev.
+	// The keys under a retention rule are compacted at the revision of the rule.
 	Compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, error)
 
+	// CompactWithRetention compacts like Compact, first moving the compaction of
+	// the retention rules forward to the given revisions, keyed by rule prefix,
+	// and recording the retention mark, if any.
+	CompactWithRetention(trace *traceutil.Trace, rev int64, prefixRevs map[string]int64, mark RetentionMark) (<-chan struct{}, error)
+
+	// PutRetentionRule sets the retention rule of the prefix of the rule.
+	PutRetentionRule(rule RetentionRule) error
+
+	// DeleteRetentionRule deletes the retention rule of the prefix.
+	DeleteRetentionRule(prefix []byte) error
+
+	// RetentionRules returns the retention rules, ordered by prefix.
+	RetentionRules() []RetentionRule
+
+	// RetentionRevAt returns the revision of the newest retention mark
+	// recorded at or before t, if any.
+	RetentionRevAt(t time.Time) (int64, bool)
+
+	// MoveLease sets the lease of the current revision of each key to the given
+	// lease in place, without creating a revision or events. It returns the
+	// number of keys found.
+	MoveLease(keys [][]byte, id lease.LeaseID) int
+
 	// Commit commits outstanding txns into the underlying backend.
 	Commit()
 
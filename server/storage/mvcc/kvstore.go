@@ -54,6 +54,8 @@ var minimumBatchInterval = 10 * time.Millisecond
 type StoreConfig struct {
 	CompactionBatchLimit    int
 	CompactionSleepInterval time.Duration
+	// SecondaryIndexes are the secondary indexes maintained on key values.
+	SecondaryIndexes []SecondaryIndex
 }
 
 type store struct {
@@ -79,6 +81,20 @@ type store struct {
 	// compactMainRev is the main revision of the last compaction.
 	compactMainRev int64
 
+	// retentionMu protects retention and retentionMarks. It is locked after revMu.
+	retentionMu sync.RWMutex
+	// retention holds the retention rules ordered by prefix, including the
+	// deleted rules still applying until a compaction catches up with them.
+	retention []RetentionRule
+	// retentionMarks are the retention marks recorded by the compactions,
+	// oldest first.
+	retentionMarks []RetentionMark
+
+	// leaseMovesMu protects leaseMoves.
+	leaseMovesMu sync.RWMutex
+	// leaseMoves holds the leases key revisions were moved to by MoveLease.
+	leaseMoves map[revision]lease.LeaseID
+
 	fifoSched schedule.Scheduler
 
 	stopc chan struct{}
@@ -124,6 +140,8 @@ func NewStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg StoreConfi
 	tx.Lock()
 	tx.UnsafeCreateBucket(schema.Key)
 	schema.UnsafeCreateMetaBucket(tx)
+	schema.UnsafeCreateRetentionBucket(tx)
+	schema.UnsafeCreateLeaseMovesBucket(tx)
 	tx.Unlock()
 	s.b.ForceCommit()
 
@@ -186,7 +204,8 @@ func (s *store) HashByRev(rev int64) (hash uint32, currentRev int64, compactRev
 	if rev == 0 {
 		rev = currentRev
 	}
-	keep := s.kvindex.Keep(rev)
+	rr := s.retentionRevs()
+	keep := s.kvindex.Keep(rev, rr)
 
 	tx := s.b.ReadTx()
 	tx.RLock()
@@ -194,7 +213,7 @@ func (s *store) HashByRev(rev int64) (hash uint32, currentRev int64, compactRev
 	s.mu.RUnlock()
 
 	upper := revision{main: rev + 1}
-	lower := revision{main: compactRev + 1}
+	lower := revision{main: rr.endRev() + 1}
 	h := crc32.New(crc32.MakeTable(crc32.Castagnoli))
 
 	h.Write(schema.Key.Name())
@@ -220,7 +239,7 @@ func (s *store) HashByRev(rev int64) (hash uint32, currentRev int64, compactRev
 	return hash, currentRev, compactRev, err
 }
 
-func (s *store) updateCompactRev(rev int64) (<-chan struct{}, error) {
+func (s *store) updateCompactRev(rev int64, prefixRevs map[string]int64, mark RetentionMark) (<-chan struct{}, error) {
 	s.revMu.Lock()
 	if rev <= s.compactMainRev {
 		ch := make(chan struct{})
@@ -236,7 +255,12 @@ func (s *store) updateCompactRev(rev int64) (<-chan struct{}, error) {
 
 	s.compactMainRev = rev
 
-	SetScheduledCompact(s.b.BatchTx(), rev)
+	tx := s.b.BatchTx()
+	tx.Lock()
+	s.unsafeCompactRetention(tx, prefixRevs)
+	s.unsafeAddRetentionMark(tx, mark)
+	UnsafeSetScheduledCompact(tx, rev)
+	tx.Unlock()
 	// ensure that desired compaction is persisted
 	s.b.ForceCommit()
 
@@ -245,7 +269,7 @@ func (s *store) updateCompactRev(rev int64) (<-chan struct{}, error) {
 	return nil, nil
 }
 
-func (s *store) compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, error) {
+func (s *store) compact(trace *traceutil.Trace, rev int64, rr *retentionRevs) (<-chan struct{}, error) {
 	ch := make(chan struct{})
 	var j = func(ctx context.Context) {
 		if ctx.Err() != nil {
@@ -253,9 +277,9 @@ func (s *store) compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, err
 			return
 		}
 		start := time.Now()
-		keep := s.kvindex.Compact(rev)
+		keep := s.kvindex.Compact(rev, rr)
 		indexCompactionPauseMs.Observe(float64(time.Since(start) / time.Millisecond))
-		if !s.scheduleCompaction(rev, keep) {
+		if !s.scheduleCompaction(rev, rr.endRev(), keep) {
 			s.compactBarrier(context.TODO(), ch)
 			return
 		}
@@ -268,26 +292,32 @@ func (s *store) compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, err
 }
 
 func (s *store) compactLockfree(rev int64) (<-chan struct{}, error) {
-	ch, err := s.updateCompactRev(rev)
+	ch, err := s.updateCompactRev(rev, nil, RetentionMark{})
 	if err != nil {
 		return ch, err
 	}
 
-	return s.compact(traceutil.TODO(), rev)
+	return s.compact(traceutil.TODO(), rev, s.retentionRevs())
 }
 
 func (s *store) Compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, error) {
+	return s.CompactWithRetention(trace, rev, nil, RetentionMark{})
+}
+
+func (s *store) CompactWithRetention(trace *traceutil.Trace, rev int64, prefixRevs map[string]int64, mark RetentionMark) (<-chan struct{}, error) {
 	s.mu.Lock()
 
-	ch, err := s.updateCompactRev(rev)
+	ch, err := s.updateCompactRev(rev, prefixRevs, mark)
 	trace.Step("check and update compact revision")
 	if err != nil {
 		s.mu.Unlock()
 		return ch, err
 	}
+	// the rules may change before the compaction runs
+	rr := s.retentionRevs()
 	s.mu.Unlock()
 
-	return s.compact(trace, rev)
+	return s.compact(trace, rev, rr)
 }
 
 func (s *store) Commit() {
@@ -313,6 +343,13 @@ func (s *store) Restore(b backend.Backend) error {
 		s.compactMainRev = -1
 		s.revMu.Unlock()
 	}
+	s.retentionMu.Lock()
+	s.retention = nil
+	s.retentionMarks = nil
+	s.retentionMu.Unlock()
+	s.leaseMovesMu.Lock()
+	s.leaseMoves = nil
+	s.leaseMovesMu.Unlock()
 
 	s.fifoSched = schedule.NewFIFOScheduler()
 	s.stopc = make(chan struct{})
@@ -345,6 +382,8 @@ func (s *store) restore() error {
 		)
 		s.revMu.Unlock()
 	}
+	s.restoreRetention(tx)
+	s.restoreLeaseMoves(tx)
 	scheduledCompact, _ := UnsafeReadScheduledCompact(tx)
 	// index keys concurrently as they're loaded in from tx
 	keysGauge.Set(0)
@@ -356,7 +395,7 @@ func (s *store) restore() error {
 		}
 		// rkvc blocks if the total pending keys exceeds the restore
 		// chunk size to keep keys from consuming too much memory.
-		restoreChunk(s.lg, rkvc, keys, vals, keyToLease)
+		restoreChunk(s.lg, rkvc, keys, vals, keyToLease, s.leaseMoves)
 		if len(keys) < restoreChunkKeys {
 			// partial set implies final set
 			break
@@ -381,6 +420,8 @@ func (s *store) restore() error {
 		s.revMu.Unlock()
 	}
 
+	s.restoreSecondaryIndexes(tx)
+
 	if scheduledCompact <= s.compactMainRev {
 		scheduledCompact = 0
 	}
@@ -471,12 +512,15 @@ func restoreIntoIndex(lg *zap.Logger, idx index) (chan<- revKeyValue, <-chan int
 	return rkvc, revc
 }
 
-func restoreChunk(lg *zap.Logger, kvc chan<- revKeyValue, keys, vals [][]byte, keyToLease map[string]lease.LeaseID) {
+func restoreChunk(lg *zap.Logger, kvc chan<- revKeyValue, keys, vals [][]byte, keyToLease map[string]lease.LeaseID, leaseMoves map[revision]lease.LeaseID) {
 	for i, key := range keys {
 		rkv := revKeyValue{key: key}
 		if err := rkv.kv.Unmarshal(vals[i]); err != nil {
 			lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
 		}
+		if id, ok := leaseMoves[bytesToRev(key)]; ok {
+			rkv.kv.Lease = int64(id)
+		}
 		rkv.kstr = string(rkv.kv.Key)
 		if isTombstone(key) {
 			delete(keyToLease, rkv.kstr)
@@ -22,7 +22,11 @@ import (
 	"go.uber.org/zap"
 )
 
-func (s *store) scheduleCompaction(compactMainRev int64, keep map[revision]struct{}) bool {
+// scheduleCompaction deletes the revisions up to endRev not in keep, and marks
+// the compaction at compactMainRev finished. The revisions newer than
+// compactMainRev are of keys compacted at the newer revision of their
+// retention rule.
+func (s *store) scheduleCompaction(compactMainRev, endRev int64, keep map[revision]struct{}) bool {
 	totalStart := time.Now()
 	defer func() { dbCompactionTotalMs.Observe(float64(time.Since(totalStart) / time.Millisecond)) }()
 	keyCompactions := 0
@@ -30,7 +34,7 @@ func (s *store) scheduleCompaction(compactMainRev int64, keep map[revision]struc
 	defer func() { dbCompactionLast.Set(float64(time.Now().Unix())) }()
 
 	end := make([]byte, 8)
-	binary.BigEndian.PutUint64(end, uint64(compactMainRev+1))
+	binary.BigEndian.PutUint64(end, uint64(endRev+1))
 
 	batchNum := s.cfg.CompactionBatchLimit
 	batchInterval := s.cfg.CompactionSleepInterval
@@ -48,6 +52,7 @@ func (s *store) scheduleCompaction(compactMainRev int64, keep map[revision]struc
 			rev = bytesToRev(key)
 			if _, ok := keep[rev]; !ok {
 				tx.UnsafeDelete(schema.Key, key)
+				s.unsafeCompactLeaseMove(tx, rev, key[:revBytesLen])
 				keyCompactions++
 			}
 		}
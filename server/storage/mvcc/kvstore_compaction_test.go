@@ -79,7 +79,7 @@ func TestScheduleCompaction(t *testing.T) {
 		}
 		tx.Unlock()
 
-		s.scheduleCompaction(tt.rev, tt.keep)
+		s.scheduleCompaction(tt.rev, tt.rev, tt.keep)
 
 		tx.Lock()
 		for _, rev := range tt.wrevs {
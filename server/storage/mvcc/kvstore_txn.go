@@ -26,8 +26,9 @@ import (
 )
 
 type storeTxnRead struct {
-	s  *store
-	tx backend.ReadTx
+	s    *store
+	tx   backend.ReadTx
+	mode ReadTxMode
 
 	firstRev int64
 	rev      int64
@@ -52,7 +53,7 @@ func (s *store) Read(mode ReadTxMode, trace *traceutil.Trace) TxnRead {
 	tx.RLock() // RLock is no-op. concurrentReadTx does not need to be locked after it is created.
 	firstRev, rev := s.compactMainRev, s.currentRev
 	s.revMu.RUnlock()
-	return newMetricsTxnRead(&storeTxnRead{s, tx, firstRev, rev, trace})
+	return newMetricsTxnRead(&storeTxnRead{s, tx, mode, firstRev, rev, trace})
 }
 
 func (tr *storeTxnRead) FirstRev() int64 { return tr.firstRev }
@@ -70,9 +71,12 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 	if rev <= 0 {
 		rev = curRev
 	}
-	if rev < tr.s.compactMainRev {
+	if rev < tr.s.rangeCompactRev(key, end, tr.s.compactMainRev) {
 		return &RangeResult{KVs: nil, Count: -1, Rev: 0}, ErrCompacted
 	}
+	if ro.Index.Field != "" {
+		return tr.rangeIndex(ctx, key, end, rev, curRev, ro)
+	}
 	if ro.Count {
 		total := tr.s.kvindex.CountRevisions(key, end, rev)
 		tr.trace.Step("count revisions from in-memory index tree")
@@ -112,6 +116,7 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 				zap.Error(err),
 			)
 		}
+		tr.s.applyLeaseMove(revpair, &kvs[i])
 	}
 	tr.trace.Step("range keys from bolt db")
 	return &RangeResult{KVs: kvs, Count: total, Rev: curRev}, nil
@@ -135,7 +140,7 @@ func (s *store) Write(trace *traceutil.Trace) TxnWrite {
 	tx := s.b.BatchTx()
 	tx.Lock()
 	tw := &storeTxnWrite{
-		storeTxnRead: storeTxnRead{s, tx, 0, 0, trace},
+		storeTxnRead: storeTxnRead{s, tx, 0, 0, 0, trace},
 		tx:           tx,
 		beginRev:     s.currentRev,
 		changes:      make([]mvccpb.KeyValue, 0, 4),
@@ -154,7 +159,14 @@ func (tw *storeTxnWrite) Range(ctx context.Context, key, end []byte, ro RangeOpt
 }
 
 func (tw *storeTxnWrite) DeleteRange(key, end []byte) (int64, int64) {
-	if n := tw.deleteRange(key, end); n != 0 || len(tw.changes) > 0 {
+	if n := tw.deleteRange(key, end, lease.NoLease); n != 0 || len(tw.changes) > 0 {
+		return n, tw.beginRev + 1
+	}
+	return 0, tw.beginRev
+}
+
+func (tw *storeTxnWrite) DeleteExpired(key []byte, id lease.LeaseID) (int64, int64) {
+	if n := tw.deleteRange(key, nil, id); n != 0 || len(tw.changes) > 0 {
 		return n, tw.beginRev + 1
 	}
 	return 0, tw.beginRev
@@ -219,6 +231,10 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 	tw.s.kvindex.Put(key, idxRev)
 	tw.changes = append(tw.changes, kv)
 	tw.trace.Step("store kv pair into bolt db")
+	if len(tw.s.cfg.SecondaryIndexes) > 0 {
+		tw.updateSecondaryIndexes(key, value)
+		tw.trace.Step("update secondary indexes")
+	}
 
 	if oldLease == leaseID {
 		tw.trace.Step("attach lease to kv pair")
@@ -249,7 +265,9 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 	tw.trace.Step("attach lease to kv pair")
 }
 
-func (tw *storeTxnWrite) deleteRange(key, end []byte) int64 {
+// deleteRange deletes the keys in the given range. If expired is not NoLease,
+// the keys are deleted because the lease expired.
+func (tw *storeTxnWrite) deleteRange(key, end []byte, expired lease.LeaseID) int64 {
 	rrev := tw.beginRev
 	if len(tw.changes) > 0 {
 		rrev++
@@ -259,19 +277,21 @@ func (tw *storeTxnWrite) deleteRange(key, end []byte) int64 {
 		return 0
 	}
 	for _, key := range keys {
-		tw.delete(key)
+		tw.delete(key, expired)
 	}
 	return int64(len(keys))
 }
 
-func (tw *storeTxnWrite) delete(key []byte) {
+func (tw *storeTxnWrite) delete(key []byte, expired lease.LeaseID) {
 	ibytes := newRevBytes()
 	idxRev := revision{main: tw.beginRev + 1, sub: int64(len(tw.changes))}
 	revToBytes(idxRev, ibytes)
 
 	ibytes = appendMarkTombstone(tw.storeTxnRead.s.lg, ibytes)
 
-	kv := mvccpb.KeyValue{Key: key}
+	// the tombstone of a key deleted by the expiry of its lease records the
+	// lease, so that the DELETE event can be marked as caused by the expiry
+	kv := mvccpb.KeyValue{Key: key, Lease: int64(expired)}
 
 	d, err := kv.Marshal()
 	if err != nil {
@@ -291,6 +311,9 @@ func (tw *storeTxnWrite) delete(key []byte) {
 		)
 	}
 	tw.changes = append(tw.changes, kv)
+	if len(tw.s.cfg.SecondaryIndexes) > 0 {
+		tw.updateSecondaryIndexes(key, nil)
+	}
 
 	item := lease.LeaseItem{Key: string(key)}
 	leaseID := tw.s.le.GetLease(item)
@@ -0,0 +1,97 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.uber.org/zap"
+)
+
+// The lease of a moved key is recorded next to the key bucket, by the
+// revision of the key, instead of rewriting the revision: the key bucket, and
+// so the hashes compared between members at a revision, stay the same.
+
+// MoveLease sets the lease of the current revision of each key to the given
+// lease, without creating a revision. It returns the number of keys found.
+func (s *store) MoveLease(keys [][]byte, id lease.LeaseID) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tx := s.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	// writers hold the batch tx until the revision is updated.
+	s.revMu.RLock()
+	rev := s.currentRev
+	s.revMu.RUnlock()
+
+	s.leaseMovesMu.Lock()
+	defer s.leaseMovesMu.Unlock()
+	n := 0
+	revBytes := newRevBytes()
+	for _, key := range keys {
+		modRev, _, _, err := s.kvindex.Get(key, rev)
+		if err != nil {
+			continue
+		}
+		revToBytes(modRev, revBytes)
+		schema.UnsafePutLeaseMove(tx, revBytes, int64(id))
+		if s.leaseMoves == nil {
+			s.leaseMoves = make(map[revision]lease.LeaseID)
+		}
+		s.leaseMoves[modRev] = id
+		n++
+	}
+	return n
+}
+
+// applyLeaseMove sets the lease of kv, stored at rev, to the lease it was
+// moved to, if any.
+func (s *store) applyLeaseMove(rev revision, kv *mvccpb.KeyValue) {
+	s.leaseMovesMu.RLock()
+	if id, ok := s.leaseMoves[rev]; ok {
+		kv.Lease = int64(id)
+	}
+	s.leaseMovesMu.RUnlock()
+}
+
+// unsafeCompactLeaseMove deletes the moved lease of a compacted revision.
+// It must be called with the batch tx locked.
+func (s *store) unsafeCompactLeaseMove(tx backend.BatchTx, rev revision, revBytes []byte) {
+	s.leaseMovesMu.Lock()
+	defer s.leaseMovesMu.Unlock()
+	if _, ok := s.leaseMoves[rev]; ok {
+		schema.UnsafeDeleteLeaseMove(tx, revBytes)
+		delete(s.leaseMoves, rev)
+	}
+}
+
+// restoreLeaseMoves loads the moved leases. It must be called with the
+// batch tx locked.
+func (s *store) restoreLeaseMoves(tx backend.BatchTx) {
+	moves := make(map[revision]lease.LeaseID)
+	err := schema.UnsafeReadLeaseMoves(tx, func(rev []byte, id int64) {
+		moves[bytesToRev(rev)] = lease.LeaseID(id)
+	})
+	if err != nil {
+		s.lg.Fatal("failed to restore moved leases", zap.Error(err))
+	}
+	s.leaseMovesMu.Lock()
+	s.leaseMoves = moves
+	s.leaseMovesMu.Unlock()
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"testing"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap"
+)
+
+func TestStoreMoveLease(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+
+	s.Put([]byte("foo"), []byte("bar"), 1)
+	s.Put([]byte("foo1"), []byte("bar"), 1)
+	s.DeleteRange([]byte("foo1"), nil)
+	rev := s.Rev()
+	hash, _, _, err := s.HashByRev(rev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := s.MoveLease([][]byte{[]byte("foo"), []byte("foo1")}, 2); n != 1 {
+		t.Errorf("expected 1 moved key, got %d", n)
+	}
+	if s.Rev() != rev {
+		t.Errorf("expected revision %d, got %d", rev, s.Rev())
+	}
+	if hash2, _, _, _ := s.HashByRev(rev); hash2 != hash {
+		t.Errorf("expected hash %d, got %d", hash, hash2)
+	}
+
+	check := func(s *store, wlease int64) {
+		r, err := s.Range(context.TODO(), []byte("foo"), nil, RangeOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.KVs) != 1 || r.KVs[0].Lease != wlease || r.KVs[0].ModRevision != 2 || r.KVs[0].Version != 1 {
+			t.Errorf("expected foo at revision 2 and version 1 with lease %d, got %+v", wlease, r.KVs)
+		}
+	}
+	check(s, 2)
+	s.Close()
+
+	// the moved leases are restored
+	s = NewStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	check(s, 2)
+
+	// and deleted with their compacted revisions
+	s.Put([]byte("foo"), []byte("baz"), 1)
+	ch, err := s.Compact(traceutil.TODO(), s.Rev())
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	if len(s.leaseMoves) != 0 {
+		t.Errorf("expected no moved leases, got %v", s.leaseMoves)
+	}
+}
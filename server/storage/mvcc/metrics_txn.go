@@ -46,6 +46,11 @@ func (tw *metricsTxnWrite) DeleteRange(key, end []byte) (n, rev int64) {
 	return tw.TxnWrite.DeleteRange(key, end)
 }
 
+func (tw *metricsTxnWrite) DeleteExpired(key []byte, id lease.LeaseID) (n, rev int64) {
+	tw.deletes++
+	return tw.TxnWrite.DeleteExpired(key, id)
+}
+
 func (tw *metricsTxnWrite) Put(key, value []byte, lease lease.LeaseID) (rev int64) {
 	tw.puts++
 	size := int64(len(key) + len(value))
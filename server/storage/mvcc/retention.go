@@ -0,0 +1,370 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrRetentionRuleNotFound = errors.New("mvcc: retention rule not found")
+	ErrInvalidRetentionRule  = errors.New("mvcc: invalid retention rule")
+)
+
+// RetentionRule overrides the compaction of the keys under a prefix, keeping
+// a longer or a shorter history of them than of the rest of the keyspace.
+//
+// The keys under the prefix are compacted at CompactRev, which only moves
+// forward when the keyspace is compacted: the revisions to compact the rules
+// at are resolved by the caller of CompactWithRetention, from Revisions and
+// Duration, so that they are the same on every member. The rule of the
+// longest prefix applies to a key.
+type RetentionRule struct {
+	Prefix []byte
+	// Revisions is the number of most recent revisions of the keyspace kept.
+	Revisions int64
+	// Duration is the duration of the most recent history kept.
+	Duration time.Duration
+	// CompactRev is the revision the keys under Prefix are compacted at.
+	CompactRev int64
+}
+
+// RetentionMark records the revision of the store at a time. The marks are
+// recorded by the compactions, so that every member resolves the revisions
+// of the retention rules of a duration the same, across restarts.
+type RetentionMark struct {
+	Rev  int64
+	Time time.Time
+}
+
+// maxRetentionMarks bounds the retention marks; every other mark is dropped
+// when reached, decreasing the resolution of the older ones.
+const maxRetentionMarks = 4096
+
+// deleted reports whether the rule was deleted while its keys are compacted
+// at a newer revision than they would be without it. Such a rule applies
+// until a compaction catches up with it.
+func (r RetentionRule) deleted() bool {
+	return r.Revisions == 0 && r.Duration == 0
+}
+
+func (r RetentionRule) valid() bool {
+	return len(r.Prefix) > 0 && r.Revisions >= 0 && r.Duration >= 0 && !r.deleted()
+}
+
+func retentionRuleFromPB(rule *pb.RetentionRule) RetentionRule {
+	return RetentionRule{
+		Prefix:     rule.Prefix,
+		Revisions:  rule.Revisions,
+		Duration:   time.Duration(rule.DurationSeconds) * time.Second,
+		CompactRev: rule.CompactRevision,
+	}
+}
+
+func (r RetentionRule) toPB() *pb.RetentionRule {
+	return &pb.RetentionRule{
+		Prefix:          r.Prefix,
+		Revisions:       r.Revisions,
+		DurationSeconds: int64(r.Duration / time.Second),
+		CompactRevision: r.CompactRev,
+	}
+}
+
+// retentionRevs resolves the revisions keys are compacted at from the
+// compaction revision of the keyspace and a snapshot of the retention rules.
+type retentionRevs struct {
+	compactRev int64
+	// rules are ordered by prefix.
+	rules []RetentionRule
+}
+
+// keyRev returns the revision the key is compacted at.
+func (rr *retentionRevs) keyRev(key []byte) int64 {
+	rev, n := rr.compactRev, -1
+	for _, r := range rr.rules {
+		if len(r.Prefix) > n && bytes.HasPrefix(key, r.Prefix) {
+			rev, n = r.CompactRev, len(r.Prefix)
+		}
+	}
+	return rev
+}
+
+// rangeRev returns the newest revision a key in [key, end) is compacted at.
+// An empty end is unbounded and a nil end selects the key only.
+func (rr *retentionRevs) rangeRev(key, end []byte) int64 {
+	if end == nil {
+		end = append(append(make([]byte, 0, len(key)+1), key...), 0)
+	}
+	// the rule of the longest prefix containing the range applies to the
+	// keys not under a more specific rule, which may only overlap it
+	rev, n, overlapping := rr.compactRev, -1, int64(0)
+	for _, r := range rr.rules {
+		rend := keyutil.PrefixRangeEnd(r.Prefix)
+		if len(rend) == 1 && rend[0] == 0 {
+			rend = nil
+		}
+		switch {
+		case bytes.Compare(r.Prefix, key) <= 0 && rangeEndLE(end, rend):
+			if len(r.Prefix) > n {
+				rev, n = r.CompactRev, len(r.Prefix)
+			}
+		case (len(rend) == 0 || bytes.Compare(key, rend) < 0) && (len(end) == 0 || bytes.Compare(r.Prefix, end) < 0):
+			if r.CompactRev > overlapping {
+				overlapping = r.CompactRev
+			}
+		}
+	}
+	if overlapping > rev {
+		return overlapping
+	}
+	return rev
+}
+
+// endRev returns the newest revision any key is compacted at.
+func (rr *retentionRevs) endRev() int64 {
+	rev := rr.compactRev
+	for _, r := range rr.rules {
+		if r.CompactRev > rev {
+			rev = r.CompactRev
+		}
+	}
+	return rev
+}
+
+// rangeEndLE reports whether a <= b, where an empty end is unbounded.
+func rangeEndLE(a, b []byte) bool {
+	if len(b) == 0 {
+		return true
+	}
+	return len(a) != 0 && bytes.Compare(a, b) <= 0
+}
+
+// PutRetentionRule sets the retention rule of the prefix of the rule. The
+// keys under a new rule are compacted at the revision they already are.
+func (s *store) PutRetentionRule(rule RetentionRule) error {
+	if !rule.valid() {
+		return ErrInvalidRetentionRule
+	}
+	rule.Prefix = append([]byte(nil), rule.Prefix...)
+
+	s.revMu.RLock()
+	defer s.revMu.RUnlock()
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+
+	rr := retentionRevs{compactRev: s.compactMainRev, rules: s.retention}
+	rule.CompactRev = rr.keyRev(rule.Prefix)
+	if rule.CompactRev < 0 {
+		rule.CompactRev = 0
+	}
+	i := sort.Search(len(s.retention), func(i int) bool { return bytes.Compare(s.retention[i].Prefix, rule.Prefix) >= 0 })
+	if i < len(s.retention) && bytes.Equal(s.retention[i].Prefix, rule.Prefix) {
+		s.retention[i] = rule
+	} else {
+		s.retention = append(s.retention, RetentionRule{})
+		copy(s.retention[i+1:], s.retention[i:])
+		s.retention[i] = rule
+	}
+
+	tx := s.b.BatchTx()
+	tx.Lock()
+	schema.UnsafePutRetentionRule(tx, rule.toPB())
+	tx.Unlock()
+	return nil
+}
+
+// DeleteRetentionRule deletes the retention rule of the prefix.
+func (s *store) DeleteRetentionRule(prefix []byte) error {
+	s.revMu.RLock()
+	defer s.revMu.RUnlock()
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+
+	i := sort.Search(len(s.retention), func(i int) bool { return bytes.Compare(s.retention[i].Prefix, prefix) >= 0 })
+	if i == len(s.retention) || !bytes.Equal(s.retention[i].Prefix, prefix) || s.retention[i].deleted() {
+		return ErrRetentionRuleNotFound
+	}
+
+	tx := s.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	r := &s.retention[i]
+	r.Revisions, r.Duration = 0, 0
+	if s.unsafeRemoveDeletedRetentionRule(tx, i) {
+		return nil
+	}
+	schema.UnsafePutRetentionRule(tx, r.toPB())
+	return nil
+}
+
+// unsafeRemoveDeletedRetentionRule removes the deleted rule at i if its keys
+// would not be compacted at an older revision without it. It must be called
+// with retentionMu and the batch tx locked.
+func (s *store) unsafeRemoveDeletedRetentionRule(tx backend.BatchTx, i int) bool {
+	r := s.retention[i]
+	others := make([]RetentionRule, 0, len(s.retention)-1)
+	others = append(append(others, s.retention[:i]...), s.retention[i+1:]...)
+	rr := retentionRevs{compactRev: s.compactMainRev, rules: others}
+	if r.CompactRev > rr.keyRev(r.Prefix) {
+		return false
+	}
+	s.retention = others
+	schema.UnsafeDeleteRetentionRule(tx, r.Prefix)
+	return true
+}
+
+// RetentionRules returns the retention rules, ordered by prefix.
+func (s *store) RetentionRules() []RetentionRule {
+	s.retentionMu.RLock()
+	defer s.retentionMu.RUnlock()
+	var rules []RetentionRule
+	for _, r := range s.retention {
+		if !r.deleted() {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// unsafeCompactRetention moves the compaction of the rules forward to the
+// given revisions, keyed by prefix, and removes the deleted rules caught up
+// with. It must be called with revMu and the batch tx locked, after updating
+// compactMainRev.
+func (s *store) unsafeCompactRetention(tx backend.BatchTx, prefixRevs map[string]int64) {
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+
+	for i := range s.retention {
+		r := &s.retention[i]
+		rev, ok := prefixRevs[string(r.Prefix)]
+		if !ok {
+			continue
+		}
+		if rev > s.currentRev {
+			rev = s.currentRev
+		}
+		if rev > r.CompactRev {
+			r.CompactRev = rev
+		}
+	}
+	// removing a rule only moves the compaction of the more specific rules
+	// forward, which come after it
+	for i := 0; i < len(s.retention); {
+		if s.retention[i].deleted() && s.unsafeRemoveDeletedRetentionRule(tx, i) {
+			continue
+		}
+		schema.UnsafePutRetentionRule(tx, s.retention[i].toPB())
+		i++
+	}
+}
+
+// unsafeAddRetentionMark records the mark, unless it is not newer than the
+// latest one, which happens if the clocks of the members proposing the
+// compactions differ. It must be called with the batch tx locked.
+func (s *store) unsafeAddRetentionMark(tx backend.BatchTx, mark RetentionMark) {
+	if mark.Rev <= 0 {
+		return
+	}
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+
+	if n := len(s.retentionMarks); n > 0 {
+		if last := s.retentionMarks[n-1]; !mark.Time.After(last.Time) || mark.Rev < last.Rev {
+			return
+		}
+	}
+	if len(s.retentionMarks) == maxRetentionMarks {
+		n := 0
+		for i := 0; i < len(s.retentionMarks); i += 2 {
+			s.retentionMarks[n] = s.retentionMarks[i]
+			n++
+		}
+		s.retentionMarks = s.retentionMarks[:n]
+	}
+	s.retentionMarks = append(s.retentionMarks, mark)
+
+	marks := make([]*pb.RetentionMark, len(s.retentionMarks))
+	for i, m := range s.retentionMarks {
+		marks[i] = &pb.RetentionMark{Revision: m.Rev, Time: m.Time.UnixNano()}
+	}
+	schema.UnsafeSetRetentionMarks(tx, marks)
+}
+
+// RetentionRevAt returns the revision of the newest retention mark recorded
+// at or before t, if any.
+func (s *store) RetentionRevAt(t time.Time) (int64, bool) {
+	s.retentionMu.RLock()
+	defer s.retentionMu.RUnlock()
+	i := sort.Search(len(s.retentionMarks), func(i int) bool { return s.retentionMarks[i].Time.After(t) })
+	if i == 0 {
+		return 0, false
+	}
+	return s.retentionMarks[i-1].Rev, true
+}
+
+// retentionRevs returns the revisions keys are compacted at.
+func (s *store) retentionRevs() *retentionRevs {
+	s.revMu.RLock()
+	defer s.revMu.RUnlock()
+	s.retentionMu.RLock()
+	defer s.retentionMu.RUnlock()
+	return &retentionRevs{
+		compactRev: s.compactMainRev,
+		rules:      append([]RetentionRule(nil), s.retention...),
+	}
+}
+
+// rangeCompactRev returns the revision the range is compacted at, given the
+// compaction revision of the keyspace.
+func (s *store) rangeCompactRev(key, end []byte, compactRev int64) int64 {
+	s.retentionMu.RLock()
+	defer s.retentionMu.RUnlock()
+	if len(s.retention) == 0 {
+		return compactRev
+	}
+	rr := retentionRevs{compactRev: compactRev, rules: s.retention}
+	return rr.rangeRev(key, end)
+}
+
+func (s *store) restoreRetention(tx backend.BatchTx) {
+	rules, err := schema.UnsafeReadRetentionRules(tx)
+	if err != nil {
+		s.lg.Fatal("failed to restore retention rules", zap.Error(err))
+	}
+	marks, err := schema.UnsafeReadRetentionMarks(tx)
+	if err != nil {
+		s.lg.Fatal("failed to restore retention marks", zap.Error(err))
+	}
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+	s.retention = nil
+	for _, rule := range rules {
+		s.retention = append(s.retention, retentionRuleFromPB(rule))
+	}
+	s.retentionMarks = nil
+	for _, m := range marks {
+		s.retentionMarks = append(s.retentionMarks, RetentionMark{Rev: m.Revision, Time: time.Unix(0, m.Time)})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap"
+)
+
+func TestRetentionRevsRangeRev(t *testing.T) {
+	rr := retentionRevs{
+		compactRev: 10,
+		rules: []RetentionRule{
+			{Prefix: []byte("/a/"), CompactRev: 5},
+			{Prefix: []byte("/a/b/"), CompactRev: 20},
+			{Prefix: []byte("/c/"), CompactRev: 2},
+		},
+	}
+	tests := []struct {
+		key, end []byte
+		wrev     int64
+	}{
+		{[]byte("/a/x"), nil, 5},
+		{[]byte("/a/b/x"), nil, 20},
+		{[]byte("/b"), nil, 10},
+		{[]byte("/a/"), []byte("/a0"), 20},
+		{[]byte("/a/c"), []byte("/a/d"), 5},
+		{[]byte("/c/"), []byte("/c0"), 2},
+		{[]byte("/c/x"), []byte{}, 10},
+		{[]byte("/"), []byte("0"), 20},
+		{[]byte("/b"), []byte("/c/"), 10},
+	}
+	for i, tt := range tests {
+		if rev := rr.rangeRev(tt.key, tt.end); rev != tt.wrev {
+			t.Errorf("#%d: range [%q, %q) compact revision = %d, want %d", i, tt.key, tt.end, rev, tt.wrev)
+		}
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+
+	if err := s.PutRetentionRule(RetentionRule{Prefix: []byte("/x/")}); err != ErrInvalidRetentionRule {
+		t.Fatalf("expected %v, got %v", ErrInvalidRetentionRule, err)
+	}
+	mustPutRetentionRule(t, s, RetentionRule{Prefix: []byte("/config/"), Duration: time.Hour})
+	mustPutRetentionRule(t, s, RetentionRule{Prefix: []byte("/hb/"), Revisions: 1})
+
+	for i, k := range []string{"/config/a", "/hb/a", "/other/a", "/config/a", "/hb/a", "/other/a", "/hb/a"} {
+		s.Put([]byte(k), []byte{byte('0' + i)}, lease.NoLease)
+	}
+	// the keys under /hb/ are compacted at the current revision, and the
+	// ones under /config/ are not compacted
+	ch, err := s.CompactWithRetention(traceutil.TODO(), 6, map[string]int64{"/hb/": 8}, RetentionMark{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+
+	tests := []struct {
+		key, end []byte
+		rev      int64
+		werr     error
+		wvalue   string
+	}{
+		{[]byte("/config/a"), nil, 2, nil, "0"},
+		{[]byte("/config/"), []byte("/config0"), 4, nil, "0"},
+		{[]byte("/other/a"), nil, 4, ErrCompacted, ""},
+		{[]byte("/other/a"), nil, 6, nil, "2"},
+		{[]byte("/hb/a"), nil, 7, ErrCompacted, ""},
+		{[]byte("/hb/a"), nil, 8, nil, "6"},
+		{[]byte("/"), []byte("0"), 7, ErrCompacted, ""},
+	}
+	check := func(s *store) {
+		for i, tt := range tests {
+			r, err := s.Range(context.TODO(), tt.key, tt.end, RangeOptions{Rev: tt.rev})
+			if err != tt.werr {
+				t.Fatalf("#%d: expected error %v, got %v", i, tt.werr, err)
+			}
+			if err == nil && (len(r.KVs) != 1 || string(r.KVs[0].Value) != tt.wvalue) {
+				t.Errorf("#%d: expected value %q, got %+v", i, tt.wvalue, r.KVs)
+			}
+		}
+		rules := s.RetentionRules()
+		if len(rules) != 2 || rules[0].CompactRev != 0 || rules[1].CompactRev != 8 {
+			t.Errorf("unexpected rules %+v", rules)
+		}
+	}
+	check(s)
+	s.Close()
+
+	// rules and retained revisions are restored
+	s = NewStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	check(s)
+
+	// the keys of a deleted rule with a longer history fall back to the
+	// compaction of the keyspace at once
+	if err = s.DeleteRetentionRule([]byte("/config/")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Range(context.TODO(), []byte("/config/a"), nil, RangeOptions{Rev: 2}); err != ErrCompacted {
+		t.Errorf("expected %v, got %v", ErrCompacted, err)
+	}
+	// the ones of a rule with a shorter history only on the next compaction
+	if err = s.DeleteRetentionRule([]byte("/hb/")); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.DeleteRetentionRule([]byte("/hb/")); err != ErrRetentionRuleNotFound {
+		t.Errorf("expected %v, got %v", ErrRetentionRuleNotFound, err)
+	}
+	if rules := s.RetentionRules(); len(rules) != 0 {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+	if _, err = s.Range(context.TODO(), []byte("/hb/a"), nil, RangeOptions{Rev: 7}); err != ErrCompacted {
+		t.Errorf("expected %v, got %v", ErrCompacted, err)
+	}
+	s.Put([]byte("/other/a"), []byte("7"), lease.NoLease)
+	if ch, err = s.Compact(traceutil.TODO(), 9); err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	if rr := s.retentionRevs(); len(rr.rules) != 0 {
+		t.Errorf("unexpected rules %+v", rr.rules)
+	}
+}
+
+func TestWatchRetention(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	mustPutRetentionRule(t, s.store, RetentionRule{Prefix: []byte("/config/"), Revisions: 100})
+	mustPutRetentionRule(t, s.store, RetentionRule{Prefix: []byte("/hb/"), Revisions: 1})
+	for _, k := range []string{"/config/a", "/hb/a", "/other/a", "/hb/a"} {
+		s.Put([]byte(k), []byte("v"), lease.NoLease)
+	}
+	ch, err := s.CompactWithRetention(traceutil.TODO(), 4, map[string]int64{"/hb/": 5}, RetentionMark{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+
+	w := s.NewWatchStream()
+	defer w.Close()
+	// the history of /config/ older than the compaction is retained
+	w.Watch(0, []byte("/config/"), []byte("/config0"), 2)
+	// the one of /hb/ is compacted at a newer revision
+	w.Watch(1, []byte("/hb/"), []byte("/hb0"), 4)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case resp := <-w.Chan():
+			switch resp.WatchID {
+			case 0:
+				if len(resp.Events) != 1 || resp.Events[0].Kv.ModRevision != 2 {
+					t.Errorf("unexpected events %+v", resp.Events)
+				}
+			case 1:
+				if resp.CompactRevision != 5 {
+					t.Errorf("compact revision = %d, want 5", resp.CompactRevision)
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("failed to receive watch response")
+		}
+	}
+}
+
+func TestStoreRetentionMarks(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+
+	t0 := time.Unix(1000, 0)
+	marks := []RetentionMark{
+		{Rev: 2, Time: t0},
+		{Rev: 4, Time: t0.Add(time.Minute)},
+		// not newer than the latest mark
+		{Rev: 5, Time: t0.Add(30 * time.Second)},
+		{Rev: 6, Time: t0.Add(2 * time.Minute)},
+	}
+	for i, m := range marks {
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+		ch, err := s.CompactWithRetention(traceutil.TODO(), int64(2*i+1), nil, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-ch
+	}
+	// the marks are only recorded by successful compactions
+	if _, err := s.CompactWithRetention(traceutil.TODO(), 1, nil, RetentionMark{Rev: 8, Time: t0.Add(time.Hour)}); err != ErrCompacted {
+		t.Fatalf("expected %v, got %v", ErrCompacted, err)
+	}
+
+	check := func(s *store) {
+		tests := []struct {
+			t    time.Time
+			wrev int64
+			wok  bool
+		}{
+			{t0.Add(-time.Second), 0, false},
+			{t0, 2, true},
+			{t0.Add(90 * time.Second), 4, true},
+			{t0.Add(time.Hour), 6, true},
+		}
+		for i, tt := range tests {
+			rev, ok := s.RetentionRevAt(tt.t)
+			if rev != tt.wrev || ok != tt.wok {
+				t.Errorf("#%d: expected (%d, %v), got (%d, %v)", i, tt.wrev, tt.wok, rev, ok)
+			}
+		}
+	}
+	check(s)
+	s.Close()
+
+	// the marks are restored
+	s = NewStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	check(s)
+}
+
+func mustPutRetentionRule(t *testing.T, s *store, rule RetentionRule) {
+	if err := s.PutRetentionRule(rule); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.uber.org/zap"
+)
+
+var ErrSecondaryIndexNotFound = errors.New("mvcc: no secondary index on the requested field covers the requested range")
+
+// SecondaryIndex indexes the keys under Prefix by a field of their JSON values.
+type SecondaryIndex struct {
+	// Prefix is the key prefix covered by the index. An empty prefix covers all keys.
+	Prefix string
+	// Field is the dot separated path of the indexed field, e.g. "status.state".
+	Field string
+}
+
+// ParseSecondaryIndex parses an index definition of the form "<prefix>=<field>".
+func ParseSecondaryIndex(s string) (SecondaryIndex, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return SecondaryIndex{}, fmt.Errorf("invalid secondary index %q: expected <prefix>=<field>", s)
+	}
+	si := SecondaryIndex{Prefix: s[:i], Field: s[i+1:]}
+	if si.Field == "" {
+		return SecondaryIndex{}, fmt.Errorf("invalid secondary index %q: empty field", s)
+	}
+	for _, p := range strings.Split(si.Field, ".") {
+		if p == "" {
+			return SecondaryIndex{}, fmt.Errorf("invalid secondary index %q: empty field path element", s)
+		}
+	}
+	return si, nil
+}
+
+func (si SecondaryIndex) String() string { return si.Prefix + "=" + si.Field }
+
+// covers returns true if the key range [key, end) only holds keys under the index prefix.
+func (si SecondaryIndex) covers(key, end []byte) bool {
+	if !bytes.HasPrefix(key, []byte(si.Prefix)) {
+		return false
+	}
+	if end == nil || si.Prefix == "" {
+		return true
+	}
+	return len(end) > 0 && bytes.Compare(end, keyutil.PrefixRangeEnd([]byte(si.Prefix))) <= 0
+}
+
+// extract returns the indexed value of a JSON document. Strings are indexed
+// by their content; numbers, booleans and null by their JSON literal. Values
+// that are not JSON objects or do not hold the field as a scalar are not indexed.
+func (si SecondaryIndex) extract(value []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	for _, p := range strings.Split(si.Field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[p]; !ok {
+			return nil, false
+		}
+	}
+	switch fv := v.(type) {
+	case string:
+		return []byte(fv), true
+	case json.Number:
+		return []byte(fv), true
+	case bool:
+		if fv {
+			return []byte("true"), true
+		}
+		return []byte("false"), true
+	case nil:
+		return []byte("null"), true
+	}
+	return nil, false
+}
+
+// IndexRange selects keys by the value of an indexed field.
+type IndexRange struct {
+	// Field is the indexed field. Secondary indexes are not used if it is empty.
+	Field string
+	// Value is the value, or the first value of the range, to match.
+	Value []byte
+	// ValueEnd is the end of the value range. If empty, only Value is matched;
+	// if "\x00", all values greater than or equal to Value are matched.
+	ValueEnd []byte
+}
+
+func (ir IndexRange) match(v []byte) bool {
+	switch {
+	case len(ir.ValueEnd) == 0:
+		return bytes.Equal(v, ir.Value)
+	case len(ir.ValueEnd) == 1 && ir.ValueEnd[0] == 0:
+		return bytes.Compare(v, ir.Value) >= 0
+	}
+	return bytes.Compare(v, ir.Value) >= 0 && bytes.Compare(v, ir.ValueEnd) < 0
+}
+
+// secondaryIndexFor returns the index on the given field that covers the key range.
+func (s *store) secondaryIndexFor(field string, key, end []byte) (SecondaryIndex, bool) {
+	for _, si := range s.cfg.SecondaryIndexes {
+		if si.Field == field && si.covers(key, end) {
+			return si, true
+		}
+	}
+	return SecondaryIndex{}, false
+}
+
+// updateSecondaryIndexes replaces the index entries of the key. A nil value
+// removes them.
+func (tw *storeTxnWrite) updateSecondaryIndexes(key, value []byte) {
+	for _, si := range tw.s.cfg.SecondaryIndexes {
+		if !bytes.HasPrefix(key, []byte(si.Prefix)) {
+			continue
+		}
+		name := si.String()
+		schema.UnsafeDeleteSecondaryIndexEntry(tw.tx, name, key)
+		if value == nil {
+			continue
+		}
+		if v, ok := si.extract(value); ok {
+			schema.UnsafePutSecondaryIndexEntry(tw.tx, name, v, key)
+		}
+	}
+}
+
+// restoreSecondaryIndexes rebuilds the secondary indexes if the configured
+// set differs from the one stored in the backend.
+func (s *store) restoreSecondaryIndexes(tx backend.BatchTx) {
+	names := make([]string, len(s.cfg.SecondaryIndexes))
+	for i, si := range s.cfg.SecondaryIndexes {
+		names[i] = si.String()
+	}
+	sort.Strings(names)
+	stored := schema.UnsafeReadSecondaryIndexDefinitions(tx)
+	sort.Strings(stored)
+	if strings.Join(names, "\n") == strings.Join(stored, "\n") {
+		return
+	}
+
+	s.lg.Info(
+		"rebuilding secondary indexes",
+		zap.Strings("stored-indexes", stored),
+		zap.Strings("configured-indexes", names),
+	)
+	schema.UnsafeResetSecondaryIndexes(tx, names)
+	revBytes := newRevBytes()
+	for _, si := range s.cfg.SecondaryIndexes {
+		end := keyutil.PrefixRangeEnd([]byte(si.Prefix))
+		if len(end) == 1 && end[0] == 0 {
+			// all the keys from the prefix
+			end = []byte{}
+		}
+		keys, revs := s.kvindex.Range([]byte(si.Prefix), end, s.currentRev)
+		for i, rev := range revs {
+			revToBytes(rev, revBytes)
+			_, vs := tx.UnsafeRange(schema.Key, revBytes, nil, 0)
+			if len(vs) != 1 {
+				s.lg.Fatal(
+					"failed to find revision pair",
+					zap.Int64("revision-main", rev.main),
+					zap.Int64("revision-sub", rev.sub),
+				)
+			}
+			var kv mvccpb.KeyValue
+			if err := kv.Unmarshal(vs[0]); err != nil {
+				s.lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
+			}
+			if v, ok := si.extract(kv.Value); ok {
+				schema.UnsafePutSecondaryIndexEntry(tx, si.String(), v, keys[i])
+			}
+		}
+	}
+}
+
+// indexEntries returns the keys of the entries of the index matching ro.Index
+// at curRev, or false if they cannot be read. The entries are overwritten and
+// deleted within a batch, so they are ranged in the batch transaction, which
+// write transactions hold and concurrent read transactions lock. The read
+// transactions sharing the read buffer hold its lock, which the batch
+// transaction takes on commit, so they cannot lock it.
+func (tr *storeTxnRead) indexEntries(si SecondaryIndex, ro RangeOptions, curRev int64) ([][]byte, bool) {
+	if tx, ok := tr.tx.(backend.BatchTx); ok {
+		keys, _ := schema.UnsafeRangeSecondaryIndex(tx, si.String(), ro.Index.Value, ro.Index.ValueEnd)
+		return keys, true
+	}
+	if tr.mode != ConcurrentReadTxMode {
+		return nil, false
+	}
+	tx := tr.s.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	// the revision is only increased by write transactions holding the batch
+	// transaction, or by restores excluding the read transactions, so it does
+	// not change while locked. If writes were applied since the transaction
+	// began, the entries do not match curRev.
+	if tr.s.currentRev != curRev {
+		return nil, false
+	}
+	keys, _ := schema.UnsafeRangeSecondaryIndex(tx, si.String(), ro.Index.Value, ro.Index.ValueEnd)
+	return keys, true
+}
+
+type indexedKeyValue struct {
+	value []byte
+	kv    mvccpb.KeyValue
+}
+
+// rangeIndex returns the keys in [key, end) at rev whose indexed field matches
+// ro.Index, ordered by the field value, then by key. The index entries are only
+// used at the current revision; older revisions are scanned.
+func (tr *storeTxnRead) rangeIndex(ctx context.Context, key, end []byte, rev, curRev int64, ro RangeOptions) (*RangeResult, error) {
+	si, ok := tr.s.secondaryIndexFor(ro.Index.Field, key, end)
+	if !ok {
+		return &RangeResult{KVs: nil, Count: -1, Rev: curRev}, ErrSecondaryIndexNotFound
+	}
+
+	var candidates [][]byte
+	indexed := false
+	if rev == curRev {
+		candidates, indexed = tr.indexEntries(si, ro, curRev)
+	}
+	if indexed {
+		tr.trace.Step("range keys from secondary index")
+	} else {
+		candidates, _ = tr.s.kvindex.Range(key, end, rev)
+		tr.trace.Step("range keys from in-memory index tree")
+	}
+
+	// verify every candidate against its value at rev.
+	seen := make(map[string]struct{}, len(candidates))
+	var ikvs []indexedKeyValue
+	revBytes := newRevBytes()
+	for _, k := range candidates {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		if !inRange(k, key, end) {
+			continue
+		}
+		if _, ok := seen[string(k)]; ok {
+			continue
+		}
+		seen[string(k)] = struct{}{}
+		krev, _, _, err := tr.s.kvindex.Get(k, rev)
+		if err != nil {
+			continue
+		}
+		revToBytes(krev, revBytes)
+		_, vs := tr.tx.UnsafeRange(schema.Key, revBytes, nil, 0)
+		if len(vs) != 1 {
+			tr.s.lg.Fatal(
+				"range failed to find revision pair",
+				zap.Int64("revision-main", krev.main),
+				zap.Int64("revision-sub", krev.sub),
+			)
+		}
+		var ikv indexedKeyValue
+		if err := ikv.kv.Unmarshal(vs[0]); err != nil {
+			tr.s.lg.Fatal(
+				"failed to unmarshal mvccpb.KeyValue",
+				zap.Error(err),
+			)
+		}
+		tr.s.applyLeaseMove(krev, &ikv.kv)
+		v, ok := si.extract(ikv.kv.Value)
+		if !ok || !ro.Index.match(v) {
+			continue
+		}
+		ikv.value = v
+		ikvs = append(ikvs, ikv)
+	}
+	tr.trace.Step("filter keys by indexed value")
+
+	sort.Slice(ikvs, func(i, j int) bool {
+		if c := bytes.Compare(ikvs[i].value, ikvs[j].value); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(ikvs[i].kv.Key, ikvs[j].kv.Key) < 0
+	})
+	total := len(ikvs)
+	if ro.Count {
+		return &RangeResult{KVs: nil, Count: total, Rev: curRev}, nil
+	}
+	if ro.Limit > 0 && int(ro.Limit) < len(ikvs) {
+		ikvs = ikvs[:ro.Limit]
+	}
+	kvs := make([]mvccpb.KeyValue, len(ikvs))
+	for i := range ikvs {
+		kvs[i] = ikvs[i].kv
+	}
+	return &RangeResult{KVs: kvs, Count: total, Rev: curRev}, nil
+}
+
+// inRange returns true if k is in [key, end). A nil end selects key only; an
+// empty end selects all keys from key.
+func inRange(k, key, end []byte) bool {
+	if end == nil {
+		return bytes.Equal(k, key)
+	}
+	return bytes.Compare(k, key) >= 0 && (len(end) == 0 || bytes.Compare(k, end) < 0)
+}
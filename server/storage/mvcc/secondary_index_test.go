@@ -0,0 +1,184 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap"
+)
+
+func TestParseSecondaryIndex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SecondaryIndex
+		werr bool
+	}{
+		{"/jobs/=state", SecondaryIndex{Prefix: "/jobs/", Field: "state"}, false},
+		{"/a=b/=status.phase", SecondaryIndex{Prefix: "/a=b/", Field: "status.phase"}, false},
+		{"=state", SecondaryIndex{Prefix: "", Field: "state"}, false},
+		{"/jobs/", SecondaryIndex{}, true},
+		{"/jobs/=", SecondaryIndex{}, true},
+		{"/jobs/=status..phase", SecondaryIndex{}, true},
+	}
+	for i, tt := range tests {
+		si, err := ParseSecondaryIndex(tt.in)
+		if (err != nil) != tt.werr {
+			t.Errorf("#%d: expected error %v, got %v", i, tt.werr, err)
+		}
+		if si != tt.want {
+			t.Errorf("#%d: expected %+v, got %+v", i, tt.want, si)
+		}
+	}
+}
+
+func TestSecondaryIndexExtract(t *testing.T) {
+	si := SecondaryIndex{Field: "status.phase"}
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{`{"status":{"phase":"running"}}`, "running", true},
+		{`{"status":{"phase":3.50}}`, "3.50", true},
+		{`{"status":{"phase":true}}`, "true", true},
+		{`{"status":{"phase":null}}`, "null", true},
+		{`{"status":{"phase":["a"]}}`, "", false},
+		{`{"status":"running"}`, "", false},
+		{`{"phase":"running"}`, "", false},
+		{`not json`, "", false},
+	}
+	for i, tt := range tests {
+		v, ok := si.extract([]byte(tt.value))
+		if ok != tt.ok || string(v) != tt.want {
+			t.Errorf("#%d: expected %q (%v), got %q (%v)", i, tt.want, tt.ok, v, ok)
+		}
+	}
+}
+
+func TestStoreRangeSecondaryIndex(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	cfg := StoreConfig{SecondaryIndexes: []SecondaryIndex{{Prefix: "/jobs/", Field: "state"}}}
+	s := NewStore(zap.NewExample(), b, &lease.FakeLessor{}, cfg)
+
+	s.Put([]byte("/jobs/a"), []byte(`{"state":"running"}`), lease.NoLease)
+	s.Put([]byte("/jobs/b"), []byte(`{"state":"done"}`), lease.NoLease)
+	s.Put([]byte("/jobs/c"), []byte(`{"state":"running"}`), lease.NoLease)
+	s.Put([]byte("/jobs/d"), []byte(`not json`), lease.NoLease)
+	s.Put([]byte("/other/a"), []byte(`{"state":"running"}`), lease.NoLease)
+	// move /jobs/a out of "running" and delete /jobs/c within the same batch
+	s.Put([]byte("/jobs/a"), []byte(`{"state":"failed"}`), lease.NoLease)
+	oldRev := s.Rev()
+	s.DeleteRange([]byte("/jobs/c"), nil)
+	s.Put([]byte("/jobs/e"), []byte(`{"state":"running"}`), lease.NoLease)
+
+	jobs, jobsEnd := []byte("/jobs/"), []byte("/jobs0")
+	tests := []struct {
+		ro    RangeOptions
+		wkeys []string
+		wcnt  int
+	}{
+		{
+			RangeOptions{Index: IndexRange{Field: "state", Value: []byte("running")}},
+			[]string{"/jobs/e"}, 1,
+		},
+		{
+			RangeOptions{Index: IndexRange{Field: "state", Value: []byte("d"), ValueEnd: []byte{0}}},
+			[]string{"/jobs/b", "/jobs/a", "/jobs/e"}, 3,
+		},
+		{
+			RangeOptions{Index: IndexRange{Field: "state", Value: []byte("a"), ValueEnd: []byte("f")}, Limit: 1},
+			[]string{"/jobs/b"}, 1,
+		},
+		{
+			RangeOptions{Index: IndexRange{Field: "state", Value: []byte("a"), ValueEnd: []byte("z")}, Limit: 2},
+			[]string{"/jobs/b", "/jobs/a"}, 3,
+		},
+		{
+			RangeOptions{Index: IndexRange{Field: "state", Value: []byte("running")}, Rev: oldRev},
+			[]string{"/jobs/c"}, 1,
+		},
+	}
+	check := func(s *store) {
+		for i, tt := range tests {
+			r, err := s.Range(context.TODO(), jobs, jobsEnd, tt.ro)
+			if err != nil {
+				t.Fatalf("#%d: %v", i, err)
+			}
+			var keys []string
+			for _, kv := range r.KVs {
+				keys = append(keys, string(kv.Key))
+			}
+			if !reflect.DeepEqual(keys, tt.wkeys) || r.Count != tt.wcnt {
+				t.Errorf("#%d: expected %v (count %d), got %v (count %d)", i, tt.wkeys, tt.wcnt, keys, r.Count)
+			}
+		}
+	}
+	check(s)
+
+	// read transactions read the index as of their revision, and write
+	// transactions read their own writes
+	running := RangeOptions{Index: IndexRange{Field: "state", Value: []byte("running")}}
+	rangeKeys := func(txn TxnRead) []string {
+		r, err := txn.Range(context.TODO(), jobs, jobsEnd, running)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, kv := range r.KVs {
+			keys = append(keys, string(kv.Key))
+		}
+		return keys
+	}
+	txn := s.Read(SharedBufReadTxMode, traceutil.TODO())
+	wkeys := rangeKeys(txn)
+	txn.End()
+	txn = s.Read(ConcurrentReadTxMode, traceutil.TODO())
+	tw := s.Write(traceutil.TODO())
+	tw.Put([]byte("/jobs/b"), []byte(`{"state":"running"}`), lease.NoLease)
+	if keys := rangeKeys(tw); !reflect.DeepEqual(keys, []string{"/jobs/b", "/jobs/e"}) {
+		t.Errorf("expected the write transaction to read [/jobs/b /jobs/e], got %v", keys)
+	}
+	tw.End()
+	if keys := rangeKeys(txn); !reflect.DeepEqual(keys, wkeys) {
+		t.Errorf("expected the read transaction to read %v, got %v", wkeys, keys)
+	}
+	txn.End()
+	s.Put([]byte("/jobs/b"), []byte(`{"state":"done"}`), lease.NoLease)
+	check(s)
+	s.Close()
+
+	// rebuilt on restore if the index definitions change
+	cfg.SecondaryIndexes = append(cfg.SecondaryIndexes, SecondaryIndex{Prefix: "/other/", Field: "state"})
+	s = NewStore(zap.NewExample(), b, &lease.FakeLessor{}, cfg)
+	defer s.Close()
+	check(s)
+	r, err := s.Range(context.TODO(), []byte("/other/"), []byte("/other0"), RangeOptions{Index: IndexRange{Field: "state", Value: []byte("running")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.KVs) != 1 || string(r.KVs[0].Key) != "/other/a" {
+		t.Errorf("expected /other/a, got %+v", r.KVs)
+	}
+
+	if _, err = s.Range(context.TODO(), []byte("/"), []byte("0"), RangeOptions{Index: IndexRange{Field: "state", Value: []byte("running")}}); err != ErrSecondaryIndexNotFound {
+		t.Errorf("expected %v, got %v", ErrSecondaryIndexNotFound, err)
+	}
+}
@@ -39,7 +39,7 @@ var (
 )
 
 type watchable interface {
-	watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc)
+	watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, opts WatchOptions, fcs ...FilterFunc) (*watcher, cancelFunc)
 	progress(w *watcher)
 	rev() int64
 }
@@ -62,6 +62,10 @@ type watchableStore struct {
 	// The key of the map is the key that the watcher watches on.
 	synced watcherGroup
 
+	// coalescing are the coalescing watchers with pending events, either
+	// synced or unsynced.
+	coalescing watcherSet
+
 	stopc chan struct{}
 	wg    sync.WaitGroup
 }
@@ -79,11 +83,12 @@ func newWatchableStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg S
 		lg = zap.NewNop()
 	}
 	s := &watchableStore{
-		store:    NewStore(lg, b, le, cfg),
-		victimc:  make(chan struct{}, 1),
-		unsynced: newWatcherGroup(),
-		synced:   newWatcherGroup(),
-		stopc:    make(chan struct{}),
+		store:      NewStore(lg, b, le, cfg),
+		victimc:    make(chan struct{}, 1),
+		unsynced:   newWatcherGroup(),
+		synced:     newWatcherGroup(),
+		coalescing: make(watcherSet),
+		stopc:      make(chan struct{}),
 	}
 	s.store.ReadView = &readView{s}
 	s.store.WriteView = &writeView{s}
@@ -113,14 +118,18 @@ func (s *watchableStore) NewWatchStream() WatchStream {
 	}
 }
 
-func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc) {
+func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, opts WatchOptions, fcs ...FilterFunc) (*watcher, cancelFunc) {
 	wa := &watcher{
-		key:    key,
-		end:    end,
-		minRev: startRev,
-		id:     id,
-		ch:     ch,
-		fcs:    fcs,
+		key:      key,
+		end:      end,
+		minRev:   startRev,
+		id:       id,
+		ch:       ch,
+		fcs:      fcs,
+		coalesce: opts.Coalesce || opts.MaxEventsPerSecond > 0,
+	}
+	if opts.MaxEventsPerSecond > 0 {
+		wa.limiter = newEventLimiter(opts.MaxEventsPerSecond, time.Now())
 	}
 
 	s.mu.Lock()
@@ -148,6 +157,7 @@ func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch c
 func (s *watchableStore) cancelWatcher(wa *watcher) {
 	for {
 		s.mu.Lock()
+		delete(s.coalescing, wa)
 		if s.unsynced.delete(wa) {
 			slowWatcherGauge.Dec()
 			watcherGauge.Dec()
@@ -239,7 +249,8 @@ func (s *watchableStore) syncWatchersLoop() {
 }
 
 // syncVictimsLoop tries to write precomputed watcher responses to
-// watchers that had a blocked watcher channel
+// watchers that had a blocked watcher channel, and the pending events
+// of coalescing watchers
 func (s *watchableStore) syncVictimsLoop() {
 	defer s.wg.Done()
 
@@ -247,8 +258,9 @@ func (s *watchableStore) syncVictimsLoop() {
 		for s.moveVictims() != 0 {
 			// try to update all victim watchers
 		}
+		s.flushCoalesced()
 		s.mu.RLock()
-		isEmpty := len(s.victims) == 0
+		isEmpty := len(s.victims) == 0 && len(s.coalescing) == 0
 		s.mu.RUnlock()
 
 		var tickc <-chan time.Time
@@ -344,8 +356,11 @@ func (s *watchableStore) syncWatchers() int {
 	// query the backend store of key-value pairs
 	curRev := s.store.currentRev
 	compactionRev := s.store.compactMainRev
+	rangeCompactRev := func(key, end []byte) int64 {
+		return s.store.rangeCompactRev(key, end, compactionRev)
+	}
 
-	wg, minRev := s.unsynced.choose(maxWatchersPerSync, curRev, compactionRev)
+	wg, minRev := s.unsynced.choose(maxWatchersPerSync, curRev, rangeCompactRev)
 	minBytes, maxBytes := newRevBytes(), newRevBytes()
 	revToBytes(revision{main: minRev}, minBytes)
 	revToBytes(revision{main: curRev + 1}, maxBytes)
@@ -378,6 +393,18 @@ func (s *watchableStore) syncWatchers() int {
 			w.minRev = eb.moreRev
 		}
 
+		if w.coalesce {
+			if eb.moreRev != 0 {
+				// stay unsynced; more to read
+				s.sendCoalescing(w, eb.evs, eb.moreRev-1)
+				continue
+			}
+			s.sendCoalescing(w, eb.evs, curRev)
+			s.synced.add(w)
+			s.unsynced.delete(w)
+			continue
+		}
+
 		if w.send(WatchResponse{WatchID: w.id, Events: eb.evs, Revision: curRev}) {
 			pendingEventsGauge.Add(float64(len(eb.evs)))
 		} else {
@@ -418,13 +445,14 @@ func kvsToEvents(lg *zap.Logger, wg *watcherGroup, revs, vals [][]byte) (evs []m
 			continue
 		}
 
-		ty := mvccpb.PUT
+		ty, expired := mvccpb.PUT, false
 		if isTombstone(revs[i]) {
 			ty = mvccpb.DELETE
 			// patch in mod revision so watchers won't skip
 			kv.ModRevision = bytesToRev(revs[i]).main
+			expired = kv.Lease != 0
 		}
-		evs = append(evs, mvccpb.Event{Kv: &kv, Type: ty})
+		evs = append(evs, mvccpb.Event{Kv: &kv, Type: ty, LeaseExpired: expired})
 	}
 	return evs
 }
@@ -440,6 +468,11 @@ func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
 				zap.Int("number-of-revisions", eb.revs),
 			)
 		}
+		if w.coalesce {
+			w.minRev = rev + 1
+			s.sendCoalescing(w, eb.evs, rev)
+			continue
+		}
 		if w.send(WatchResponse{WatchID: w.id, Events: eb.evs, Revision: rev}) {
 			pendingEventsGauge.Add(float64(len(eb.evs)))
 		} else {
@@ -454,6 +487,69 @@ func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
 	s.addVictim(victim)
 }
 
+// sendCoalescing sends the events evs observed up to rev to the coalescing
+// watcher w, or coalesces them into its pending events if it has pending
+// events, is over its rate or is blocked.
+func (s *watchableStore) sendCoalescing(w *watcher, evs []mvccpb.Event, rev int64) {
+	evs = w.filter(evs)
+	if len(evs) == 0 {
+		return
+	}
+	if w.pending == nil && (w.limiter == nil || w.limiter.available(time.Now()) >= len(evs)) &&
+		w.send(WatchResponse{WatchID: w.id, Events: evs, Revision: rev}) {
+		if w.limiter != nil {
+			w.limiter.take(len(evs))
+		}
+		pendingEventsGauge.Add(float64(len(evs)))
+		return
+	}
+	if w.pending == nil {
+		w.pending = newCoalescedEvents()
+		s.coalescing.add(w)
+		select {
+		case s.victimc <- struct{}{}:
+		default:
+		}
+	}
+	w.pending.add(evs, rev)
+}
+
+// flushCoalesced sends the pending events of the coalescing watchers within
+// their rate limits.
+func (s *watchableStore) flushCoalesced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for w := range s.coalescing {
+		if w.compacted || w.ch == nil {
+			w.pending = nil
+			delete(s.coalescing, w)
+			continue
+		}
+		n := 0
+		if w.limiter != nil {
+			n = w.limiter.available(now)
+			if n == 0 || (n < w.limiter.batch() && n < w.pending.len()) {
+				continue
+			}
+		}
+		evs, rev := w.pending.next(n)
+		if !w.send(WatchResponse{WatchID: w.id, Events: evs, Revision: rev}) {
+			continue
+		}
+		if w.limiter != nil {
+			w.limiter.take(len(evs))
+		}
+		pendingEventsGauge.Add(float64(len(evs)))
+		w.pending.remove(evs)
+		if w.pending.len() == 0 {
+			w.pending = nil
+			delete(s.coalescing, w)
+		}
+	}
+}
+
 func (s *watchableStore) addVictim(victim watcherBatch) {
 	if len(victim) == 0 {
 		return
@@ -471,7 +567,8 @@ func (s *watchableStore) progress(w *watcher) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if _, ok := s.synced.watchers[w]; ok {
+	// the progress of a coalescing watcher with pending events is sent with them.
+	if _, ok := s.synced.watchers[w]; ok && w.pending == nil {
 		w.send(WatchResponse{WatchID: w.id, Revision: s.rev()})
 		// If the ch is full, this watcher is receiving events.
 		// We do not need to send progress at all.
@@ -504,6 +601,15 @@ type watcher struct {
 	id     WatchID
 
 	fcs []FilterFunc
+
+	// coalesce is set when the events of the keys the watcher cannot keep up
+	// with are coalesced into their latest event.
+	coalesce bool
+	// limiter caps the events per second sent to a coalescing watcher, if set.
+	limiter *eventLimiter
+	// pending holds the coalesced events not sent yet to a coalescing watcher.
+	pending *coalescedEvents
+
 	// a chan to send out the watch response.
 	// The chan might be shared with other watchers.
 	ch chan<- WatchResponse
@@ -512,22 +618,7 @@ type watcher struct {
 func (w *watcher) send(wr WatchResponse) bool {
 	progressEvent := len(wr.Events) == 0
 
-	if len(w.fcs) != 0 {
-		ne := make([]mvccpb.Event, 0, len(wr.Events))
-		for i := range wr.Events {
-			filtered := false
-			for _, filter := range w.fcs {
-				if filter(wr.Events[i]) {
-					filtered = true
-					break
-				}
-			}
-			if !filtered {
-				ne = append(ne, wr.Events[i])
-			}
-		}
-		wr.Events = ne
-	}
+	wr.Events = w.filter(wr.Events)
 
 	// if all events are filtered out, we should send nothing.
 	if !progressEvent && len(wr.Events) == 0 {
@@ -540,3 +631,24 @@ func (w *watcher) send(wr WatchResponse) bool {
 		return false
 	}
 }
+
+// filter returns the events of evs not filtered out by the filters of the watcher.
+func (w *watcher) filter(evs []mvccpb.Event) []mvccpb.Event {
+	if len(w.fcs) == 0 {
+		return evs
+	}
+	ne := make([]mvccpb.Event, 0, len(evs))
+	for i := range evs {
+		filtered := false
+		for _, filter := range w.fcs {
+			if filter(evs[i]) {
+				filtered = true
+				break
+			}
+		}
+		if !filtered {
+			ne = append(ne, evs[i])
+		}
+	}
+	return ne
+}
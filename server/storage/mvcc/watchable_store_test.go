@@ -300,6 +300,55 @@ func TestWatchFutureRev(t *testing.T) {
 	}
 }
 
+// TestWatchLeaseExpiredDelete ensures the DELETE events of keys deleted by the
+// expiry of their lease are marked, for both synced and unsynced watchers.
+func TestWatchLeaseExpiredDelete(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+
+	defer func() {
+		b.Close()
+		s.Close()
+		os.Remove(tmpPath)
+	}()
+
+	s.Put([]byte("bar"), []byte("v"), lease.NoLease)
+	s.Put([]byte("foo"), []byte("v"), lease.NoLease)
+
+	synced := s.NewWatchStream()
+	defer synced.Close()
+	synced.Watch(0, []byte("bar"), []byte("fop"), 0)
+
+	txn := s.Write(traceutil.TODO())
+	txn.DeleteRange([]byte("bar"), nil)
+	txn.DeleteExpired([]byte("foo"), 5)
+	txn.End()
+
+	unsynced := s.NewWatchStream()
+	defer unsynced.Close()
+	unsynced.Watch(0, []byte("bar"), []byte("fop"), 4)
+
+	for _, w := range []WatchStream{synced, unsynced} {
+		select {
+		case resp := <-w.Chan():
+			if len(resp.Events) != 2 {
+				t.Fatalf("len(events) = %d, want 2", len(resp.Events))
+			}
+			for i, wexpired := range []bool{false, true} {
+				ev := resp.Events[i]
+				if ev.Type != mvccpb.DELETE || ev.LeaseExpired != wexpired {
+					t.Errorf("event %d = %+v, want DELETE with lease expired %v", i, ev, wexpired)
+				}
+			}
+			if resp.Events[1].Kv.Lease != 5 {
+				t.Errorf("expired lease = %d, want 5", resp.Events[1].Kv.Lease)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("failed to receive event in 1 second.")
+		}
+	}
+}
+
 func TestWatchRestore(t *testing.T) {
 	test := func(delay time.Duration) func(t *testing.T) {
 		return func(t *testing.T) {
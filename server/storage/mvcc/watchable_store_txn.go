@@ -33,6 +33,7 @@ func (tw *watchableStoreTxnWrite) End() {
 		if change.CreateRevision == 0 {
 			evs[i].Type = mvccpb.DELETE
 			evs[i].Kv.ModRevision = rev
+			evs[i].LeaseExpired = change.Lease != 0
 		} else {
 			evs[i].Type = mvccpb.PUT
 		}
@@ -30,6 +30,7 @@ var (
 	ErrWatcherNotExist    = errors.New("mvcc: watcher does not exist")
 	ErrEmptyWatcherRange  = errors.New("mvcc: watcher range is empty")
 	ErrWatcherDuplicateID = errors.New("mvcc: duplicate watch ID provided on the WatchStream")
+	ErrWatcherInvalidRate = errors.New("mvcc: max events per second of the watcher is negative")
 )
 
 type WatchID int64
@@ -50,6 +51,9 @@ type WatchStream interface {
 	// an auto-generated watch ID is returned.
 	Watch(id WatchID, key, end []byte, startRev int64, fcs ...FilterFunc) (WatchID, error)
 
+	// WatchWithOptions creates a watcher like Watch, with the given options.
+	WatchWithOptions(id WatchID, key, end []byte, startRev int64, opts WatchOptions, fcs ...FilterFunc) (WatchID, error)
+
 	// Chan returns a chan. All watch response will be sent to the returned chan.
 	Chan() <-chan WatchResponse
 
@@ -72,6 +76,17 @@ type WatchStream interface {
 	Rev() int64
 }
 
+// WatchOptions are the options of a watcher.
+type WatchOptions struct {
+	// Coalesce makes the watcher coalesce the events of the keys it cannot keep up
+	// with into their latest event, instead of buffering all of them.
+	Coalesce bool
+
+	// MaxEventsPerSecond caps the events per second sent to a coalescing watcher,
+	// coalescing the events over the rate. 0 means no limit.
+	MaxEventsPerSecond int64
+}
+
 type WatchResponse struct {
 	// WatchID is the WatchID of the watcher this response sent to.
 	WatchID WatchID
@@ -106,6 +121,14 @@ type watchStream struct {
 
 // Watch creates a new watcher in the stream and returns its WatchID.
 func (ws *watchStream) Watch(id WatchID, key, end []byte, startRev int64, fcs ...FilterFunc) (WatchID, error) {
+	return ws.WatchWithOptions(id, key, end, startRev, WatchOptions{}, fcs...)
+}
+
+// WatchWithOptions creates a new watcher with the given options in the stream and returns its WatchID.
+func (ws *watchStream) WatchWithOptions(id WatchID, key, end []byte, startRev int64, opts WatchOptions, fcs ...FilterFunc) (WatchID, error) {
+	if opts.MaxEventsPerSecond < 0 {
+		return -1, ErrWatcherInvalidRate
+	}
 	// prevent wrong range where key >= end lexicographically
 	// watch request with 'WithFromKey' has empty-byte range end
 	if len(end) != 0 && bytes.Compare(key, end) != -1 {
@@ -128,7 +151,7 @@ func (ws *watchStream) Watch(id WatchID, key, end []byte, startRev int64, fcs ..
 		return -1, ErrWatcherDuplicateID
 	}
 
-	w, c := ws.watchable.watch(key, end, startRev, id, ws.ch, fcs...)
+	w, c := ws.watchable.watch(key, end, startRev, id, ws.ch, opts, fcs...)
 
 	ws.cancels[id] = c
 	ws.watchers[id] = w
@@ -0,0 +1,123 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// coalescedEvents holds the latest event of each key not sent yet to a
+// coalescing watcher.
+type coalescedEvents struct {
+	evs map[string]mvccpb.Event
+	// rev is the revision the events were observed up to.
+	rev int64
+}
+
+func newCoalescedEvents() *coalescedEvents {
+	return &coalescedEvents{evs: make(map[string]mvccpb.Event)}
+}
+
+func (ce *coalescedEvents) len() int { return len(ce.evs) }
+
+// add coalesces the events evs observed up to rev into the pending events,
+// marking the events replacing older ones of their key as coalesced.
+func (ce *coalescedEvents) add(evs []mvccpb.Event, rev int64) {
+	for _, ev := range evs {
+		k := string(ev.Kv.Key)
+		if _, ok := ce.evs[k]; ok {
+			ev.Coalesced = true
+		}
+		ce.evs[k] = ev
+	}
+	if rev > ce.rev {
+		ce.rev = rev
+	}
+}
+
+// next returns the n oldest pending events, or all of them if n <= 0, and the
+// revision the watcher has observed once they are sent. The events of the
+// revision of the last one are all returned, so revisions are never split.
+func (ce *coalescedEvents) next(n int) ([]mvccpb.Event, int64) {
+	evs := make([]mvccpb.Event, 0, len(ce.evs))
+	for _, ev := range ce.evs {
+		evs = append(evs, ev)
+	}
+	sort.Slice(evs, func(i, j int) bool {
+		if evs[i].Kv.ModRevision != evs[j].Kv.ModRevision {
+			return evs[i].Kv.ModRevision < evs[j].Kv.ModRevision
+		}
+		return bytes.Compare(evs[i].Kv.Key, evs[j].Kv.Key) < 0
+	})
+	if n <= 0 || n >= len(evs) {
+		return evs, ce.rev
+	}
+	for n < len(evs) && evs[n].Kv.ModRevision == evs[n-1].Kv.ModRevision {
+		n++
+	}
+	if n == len(evs) {
+		return evs, ce.rev
+	}
+	return evs[:n], evs[n-1].Kv.ModRevision
+}
+
+// remove removes the sent events evs returned by next.
+func (ce *coalescedEvents) remove(evs []mvccpb.Event) {
+	for _, ev := range evs {
+		delete(ce.evs, string(ev.Kv.Key))
+	}
+}
+
+// eventLimiter caps the events per second sent to a watcher with a token
+// bucket holding up to a second of events. Revisions with more events than
+// available are sent at once, and paid back before the next events.
+type eventLimiter struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newEventLimiter(eventsPerSecond int64, now time.Time) *eventLimiter {
+	rate := float64(eventsPerSecond)
+	return &eventLimiter{rate: rate, tokens: rate, last: now}
+}
+
+// available returns the number of events that can be sent at now.
+func (l *eventLimiter) available(now time.Time) int {
+	l.tokens = math.Min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens < 1 {
+		return 0
+	}
+	return int(l.tokens)
+}
+
+// take takes the tokens of n sent events.
+func (l *eventLimiter) take(n int) { l.tokens -= float64(n) }
+
+// batch returns the number of events, a tenth of a second worth of them, to
+// wait for before sending pending events, so they are not sorted for every
+// single event.
+func (l *eventLimiter) batch() int {
+	if b := int(l.rate / 10); b > 1 {
+		return b
+	}
+	return 1
+}
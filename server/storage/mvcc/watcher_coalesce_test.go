@@ -0,0 +1,175 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap"
+)
+
+func TestCoalescedEvents(t *testing.T) {
+	ev := func(key string, rev int64) mvccpb.Event {
+		return mvccpb.Event{Kv: &mvccpb.KeyValue{Key: []byte(key), ModRevision: rev}}
+	}
+	ce := newCoalescedEvents()
+	ce.add([]mvccpb.Event{ev("a", 2)}, 2)
+	ce.add([]mvccpb.Event{ev("b", 3), ev("c", 3)}, 3)
+	ce.add([]mvccpb.Event{ev("a", 4)}, 4)
+	ce.add([]mvccpb.Event{ev("d", 5)}, 5)
+
+	evs, rev := ce.next(1)
+	// the events of a revision are not split
+	if len(evs) != 2 || string(evs[0].Kv.Key) != "b" || string(evs[1].Kv.Key) != "c" || rev != 3 {
+		t.Fatalf("unexpected next events %+v at revision %d", evs, rev)
+	}
+	if evs[0].Coalesced || evs[1].Coalesced {
+		t.Fatalf("unexpected coalesced events %+v", evs)
+	}
+	ce.remove(evs)
+
+	evs, rev = ce.next(0)
+	if len(evs) != 2 || string(evs[0].Kv.Key) != "a" || string(evs[1].Kv.Key) != "d" || rev != 5 {
+		t.Fatalf("unexpected next events %+v at revision %d", evs, rev)
+	}
+	if !evs[0].Coalesced || evs[0].Kv.ModRevision != 4 || evs[1].Coalesced {
+		t.Fatalf("expected the latest event of a to be coalesced, got %+v", evs)
+	}
+	ce.remove(evs)
+	if ce.len() != 0 {
+		t.Fatalf("expected no pending events, got %d", ce.len())
+	}
+}
+
+func TestEventLimiter(t *testing.T) {
+	now := time.Unix(100, 0)
+	l := newEventLimiter(100, now)
+	if n := l.available(now); n != 100 {
+		t.Fatalf("expected a burst of 100 events, got %d", n)
+	}
+	l.take(130)
+	if n := l.available(now.Add(200 * time.Millisecond)); n != 0 {
+		t.Fatalf("expected the events over the burst to be paid back, got %d available", n)
+	}
+	if n := l.available(now.Add(500 * time.Millisecond)); n != 20 {
+		t.Fatalf("expected 20 events available, got %d", n)
+	}
+	if n := l.available(now.Add(time.Hour)); n != 100 {
+		t.Fatalf("expected the burst to be capped to 100 events, got %d", n)
+	}
+}
+
+// TestWatchCoalesceSlowWatcher ensures the events of a coalescing watcher
+// blocked on its channel are coalesced into the latest event of each key.
+func TestWatchCoalesceSlowWatcher(t *testing.T) {
+	oldChanBufLen := chanBufLen
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+	defer func() {
+		b.Close()
+		s.Close()
+		os.Remove(tmpPath)
+		chanBufLen = oldChanBufLen
+	}()
+	chanBufLen = 1
+
+	w := s.NewWatchStream()
+	defer w.Close()
+	if _, err := w.WatchWithOptions(0, []byte("foo"), []byte("fop"), 0, WatchOptions{Coalesce: true}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		s.Put([]byte("foo"), []byte(fmt.Sprintf("v%d", i)), lease.NoLease)
+		s.Put([]byte("foo1"), []byte(fmt.Sprintf("v%d", i)), lease.NoLease)
+	}
+	lastRev := s.Rev()
+
+	// the first event fills the channel
+	wr := <-w.Chan()
+	if len(wr.Events) != 1 || string(wr.Events[0].Kv.Value) != "v0" || wr.Events[0].Coalesced {
+		t.Fatalf("unexpected first response %+v", wr)
+	}
+	latest := make(map[string]mvccpb.Event)
+	timeout := time.After(5 * time.Second)
+	for wr.Revision != lastRev {
+		select {
+		case wr = <-w.Chan():
+		case <-timeout:
+			t.Fatalf("timed out waiting for revision %d, got %+v", lastRev, latest)
+		}
+		for _, ev := range wr.Events {
+			latest[string(ev.Kv.Key)] = ev
+		}
+	}
+	for _, k := range []string{"foo", "foo1"} {
+		ev := latest[k]
+		if ev.Kv == nil || string(ev.Kv.Value) != "v9" || !ev.Coalesced {
+			t.Errorf("expected the coalesced latest event of %q, got %+v", k, ev)
+		}
+	}
+}
+
+// TestWatchMaxEventsPerSecond ensures the events of a rate limited watcher
+// are sent within its rate, in revision order.
+func TestWatchMaxEventsPerSecond(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zap.NewExample(), b, &lease.FakeLessor{}, StoreConfig{})
+	defer func() {
+		b.Close()
+		s.Close()
+		os.Remove(tmpPath)
+	}()
+
+	w := s.NewWatchStream()
+	defer w.Close()
+	if _, err := w.WatchWithOptions(0, []byte("foo"), []byte("fop"), 0, WatchOptions{Coalesce: true, MaxEventsPerSecond: -1}); err != ErrWatcherInvalidRate {
+		t.Fatalf("expected %v, got %v", ErrWatcherInvalidRate, err)
+	}
+	if _, err := w.WatchWithOptions(0, []byte("foo"), []byte("fop"), 0, WatchOptions{MaxEventsPerSecond: 50}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	numKeys := 100
+	for i := 0; i < numKeys; i++ {
+		s.Put([]byte(fmt.Sprintf("foo%03d", i)), []byte("bar"), lease.NoLease)
+	}
+
+	evs, lastModRev := 0, int64(0)
+	timeout := time.After(10 * time.Second)
+	for evs < numKeys {
+		select {
+		case wr := <-w.Chan():
+			for _, ev := range wr.Events {
+				if ev.Kv.ModRevision <= lastModRev {
+					t.Fatalf("expected events in revision order, got %d after %d", ev.Kv.ModRevision, lastModRev)
+				}
+				lastModRev = ev.Kv.ModRevision
+			}
+			evs += len(wr.Events)
+		case <-timeout:
+			t.Fatalf("timed out after %d events", evs)
+		}
+	}
+	// a burst of 50 events, then 50 events per second
+	if d := time.Since(start); d < 800*time.Millisecond {
+		t.Fatalf("expected %d events to take about a second at 50 events per second, took %v", numKeys, d)
+	}
+}
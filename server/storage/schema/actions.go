@@ -63,6 +63,21 @@ func restoreFieldValueAction(tx backend.BatchTx, bucket backend.Bucket, fieldNam
 	}
 }
 
+type noopAction struct{}
+
+func (a noopAction) unsafeDo(tx backend.BatchTx) (action, error) {
+	return noopAction{}, nil
+}
+
+// checkAction changes nothing, and fails if check returns an error.
+type checkAction struct {
+	check func(tx backend.BatchTx) error
+}
+
+func (a checkAction) unsafeDo(tx backend.BatchTx) (action, error) {
+	return noopAction{}, a.check(tx)
+}
+
 type ActionList []action
 
 // unsafeExecute executes actions one by one. If one of actions returns error,
@@ -15,6 +15,8 @@
 package schema
 
 import (
+	"fmt"
+
 	"go.etcd.io/etcd/api/v3/authpb"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.uber.org/zap"
@@ -86,3 +88,21 @@ func (atx *authBatchTx) UnsafePutRole(role *authpb.Role) {
 func (atx *authBatchTx) UnsafeDeleteRole(rolename string) {
 	atx.tx.UnsafeDelete(AuthRoles, []byte(rolename))
 }
+
+// unsafeCheckPermissionsDowngradable returns an error if a role has permissions
+// with key patterns, deny rules or operations, which v3.5 would take for
+// permissions granting all the operations of their type on their key.
+func unsafeCheckPermissionsDowngradable(tx backend.BatchTx) error {
+	return tx.UnsafeForEach(AuthRoles, func(k, v []byte) error {
+		role := &authpb.Role{}
+		if err := role.Unmarshal(v); err != nil {
+			return fmt.Errorf("cannot unmarshal role %q: %w", k, err)
+		}
+		for _, perm := range role.KeyPermission {
+			if perm.Pattern || perm.Deny || len(perm.Ops) > 0 {
+				return fmt.Errorf("role %q has permissions with key patterns, deny rules or operations, which are not supported before v3.6", role.Name)
+			}
+		}
+		return nil
+	})
+}
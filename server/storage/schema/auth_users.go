@@ -15,7 +15,10 @@
 package schema
 
 import (
+	"fmt"
+
 	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.uber.org/zap"
 )
 
@@ -80,3 +83,18 @@ func (atx *authBatchTx) UnsafePutUser(user *authpb.User) {
 func (atx *authBatchTx) UnsafeDeleteUser(username string) {
 	atx.tx.UnsafeDelete(AuthUsers, []byte(username))
 }
+
+// unsafeCheckUsersDowngradable returns an error if a user is bound to a tenant,
+// which v3.5 would give access to all the keys.
+func unsafeCheckUsersDowngradable(tx backend.BatchTx) error {
+	return tx.UnsafeForEach(AuthUsers, func(k, v []byte) error {
+		user := &authpb.User{}
+		if err := user.Unmarshal(v); err != nil {
+			return fmt.Errorf("cannot unmarshal user %q: %w", k, err)
+		}
+		if user.Options != nil && len(user.Options.Prefix) > 0 {
+			return fmt.Errorf("user %q is bound to the tenant of prefix %q, which is not supported before v3.6", user.Name, user.Options.Prefix)
+		}
+		return nil
+	})
+}
@@ -36,6 +36,14 @@ var (
 	authUsersBucketName = []byte("authUsers")
 	authRolesBucketName = []byte("authRoles")
 
+	secondaryIndexBucketName = []byte("secondaryIndex")
+
+	retentionBucketName = []byte("retention")
+
+	valueSchemaBucketName = []byte("valueSchema")
+
+	leaseMovesBucketName = []byte("leaseMoves")
+
 	testBucketName = []byte("test")
 )
 
@@ -53,6 +61,17 @@ var (
 	AuthUsers = backend.Bucket(bucket{id: 21, name: authUsersBucketName, safeRangeBucket: false})
 	AuthRoles = backend.Bucket(bucket{id: 22, name: authRolesBucketName, safeRangeBucket: false})
 
+	// SecondaryIndex entries are overwritten and deleted within a batch
+	// interval, so they are ranged in the batch transaction.
+	SecondaryIndex = backend.Bucket(bucket{id: 30, name: secondaryIndexBucketName, safeRangeBucket: false})
+
+	Retention = backend.Bucket(bucket{id: 40, name: retentionBucketName, safeRangeBucket: false})
+
+	ValueSchema = backend.Bucket(bucket{id: 41, name: valueSchemaBucketName, safeRangeBucket: false})
+
+	// LeaseMoves holds the leases that key revisions were moved to, keyed by revision.
+	LeaseMoves = backend.Bucket(bucket{id: 42, name: leaseMovesBucketName, safeRangeBucket: false})
+
 	Test = backend.Bucket(bucket{id: 100, name: testBucketName, safeRangeBucket: false})
 )
 
@@ -80,12 +99,18 @@ var (
 	ClusterClusterVersionKeyName = []byte("clusterVersion")
 	ClusterDowngradeKeyName      = []byte("downgrade")
 	// Since v3.6
-	MetaStorageVersionName = []byte("storageVersion")
+	MetaStorageVersionName    = []byte("storageVersion")
+	MetaRetentionMarksKeyName = []byte("retentionMarks")
 	// Before adding new meta key please update server/etcdserver/version
 )
 
 // DefaultIgnores defines buckets & keys to ignore in hash checking.
 func DefaultIgnores(bucket, key []byte) bool {
+	// secondary indexes are derived from the key bucket according to the
+	// local configuration of each member.
+	if bytes.Equal(bucket, SecondaryIndex.Name()) {
+		return true
+	}
 	// consistent index & term might be changed due to v2 internal sync, which
 	// is not controllable by the user.
 	// storage version might change after wal snapshot and is not controller by user.
@@ -36,6 +36,15 @@ func addNewField(bucket backend.Bucket, fieldName []byte, fieldValue []byte) sch
 	}
 }
 
+// checkBeforeDowngrade represents data that older versions would misinterpret.
+// Upgrade does nothing, downgrade fails with the error returned by check.
+func checkBeforeDowngrade(check func(tx backend.BatchTx) error) schemaChange {
+	return simpleSchemaChange{
+		upgrade:   noopAction{},
+		downgrade: checkAction{check: check},
+	}
+}
+
 type simpleSchemaChange struct {
 	upgrade   action
 	downgrade action
@@ -70,6 +70,32 @@ func MustUnsafeGetLease(tx backend.BatchTx, leaseID int64) *leasepb.Lease {
 	return &lpb
 }
 
+func UnsafeCreateLeaseMovesBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(LeaseMoves)
+}
+
+// UnsafeReadLeaseMoves calls fn with each key revision, in the encoding of the
+// key bucket, and the ID of the lease it was moved to.
+func UnsafeReadLeaseMoves(tx backend.ReadTx, fn func(rev []byte, leaseID int64)) error {
+	return tx.UnsafeForEach(LeaseMoves, func(k, v []byte) error {
+		if len(v) != 8 {
+			return fmt.Errorf("cannot decode moved lease of length %d", len(v))
+		}
+		fn(k, bytesToLeaseID(v))
+		return nil
+	})
+}
+
+// UnsafePutLeaseMove records that the key revision rev was moved to the lease.
+func UnsafePutLeaseMove(tx backend.BatchTx, rev []byte, leaseID int64) {
+	tx.UnsafePut(LeaseMoves, rev, leaseIdToBytes(leaseID))
+}
+
+// UnsafeDeleteLeaseMove deletes the moved lease of the key revision rev.
+func UnsafeDeleteLeaseMove(tx backend.BatchTx, rev []byte) {
+	tx.UnsafeDelete(LeaseMoves, rev)
+}
+
 func leaseIdToBytes(n int64) []byte {
 	bytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(bytes, uint64(n))
@@ -0,0 +1,89 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+func UnsafeCreateRetentionBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(Retention)
+}
+
+// UnsafeReadRetentionRules returns the retention rules stored in the backend, ordered by prefix.
+func UnsafeReadRetentionRules(tx backend.ReadTx) ([]*etcdserverpb.RetentionRule, error) {
+	var rules []*etcdserverpb.RetentionRule
+	err := tx.UnsafeForEach(Retention, func(k, v []byte) error {
+		rule := &etcdserverpb.RetentionRule{}
+		if err := rule.Unmarshal(v); err != nil {
+			return fmt.Errorf("cannot unmarshal retention rule %q: %w", k, err)
+		}
+		rules = append(rules, rule)
+		return nil
+	})
+	return rules, err
+}
+
+// UnsafePutRetentionRule stores the retention rule under its prefix.
+func UnsafePutRetentionRule(tx backend.BatchTx, rule *etcdserverpb.RetentionRule) {
+	v, err := rule.Marshal()
+	if err != nil {
+		panic(fmt.Errorf("cannot marshal retention rule: %w", err))
+	}
+	tx.UnsafePut(Retention, rule.Prefix, v)
+}
+
+// UnsafeDeleteRetentionRule deletes the retention rule of the prefix.
+func UnsafeDeleteRetentionRule(tx backend.BatchTx, prefix []byte) {
+	tx.UnsafeDelete(Retention, prefix)
+}
+
+// retentionMarkLen is the length of an encoded retention mark: its revision
+// and time, big endian.
+const retentionMarkLen = 16
+
+// UnsafeReadRetentionMarks returns the retention marks stored in the backend, oldest first.
+func UnsafeReadRetentionMarks(tx backend.ReadTx) ([]*etcdserverpb.RetentionMark, error) {
+	_, vs := tx.UnsafeRange(Meta, MetaRetentionMarksKeyName, nil, 0)
+	if len(vs) == 0 {
+		return nil, nil
+	}
+	v := vs[0]
+	if len(v)%retentionMarkLen != 0 {
+		return nil, fmt.Errorf("cannot decode retention marks of length %d", len(v))
+	}
+	marks := make([]*etcdserverpb.RetentionMark, 0, len(v)/retentionMarkLen)
+	for i := 0; i < len(v); i += retentionMarkLen {
+		marks = append(marks, &etcdserverpb.RetentionMark{
+			Revision: int64(binary.BigEndian.Uint64(v[i:])),
+			Time:     int64(binary.BigEndian.Uint64(v[i+8:])),
+		})
+	}
+	return marks, nil
+}
+
+// UnsafeSetRetentionMarks stores the retention marks, oldest first.
+func UnsafeSetRetentionMarks(tx backend.BatchTx, marks []*etcdserverpb.RetentionMark) {
+	v := make([]byte, len(marks)*retentionMarkLen)
+	for i, m := range marks {
+		binary.BigEndian.PutUint64(v[i*retentionMarkLen:], uint64(m.Revision))
+		binary.BigEndian.PutUint64(v[i*retentionMarkLen+8:], uint64(m.Time))
+	}
+	tx.UnsafePut(Meta, MetaRetentionMarksKeyName, v)
+}
@@ -134,6 +134,8 @@ var (
 	schemaChanges = map[semver.Version][]schemaChange{
 		V3_6: {
 			addNewField(Meta, MetaStorageVersionName, emptyStorageVersion),
+			checkBeforeDowngrade(unsafeCheckPermissionsDowngradable),
+			checkBeforeDowngrade(unsafeCheckUsersDowngradable),
 		},
 	}
 	// emptyStorageVersion is used for v3.6 Step for the first time, in all other version StoragetVersion should be set by migrator.
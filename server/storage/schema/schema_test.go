@@ -20,6 +20,7 @@ import (
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/authpb"
 	"go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/membershippb"
 	"go.etcd.io/etcd/raft/v3/raftpb"
@@ -194,6 +195,42 @@ func TestMigrate(t *testing.T) {
 			expectError:    true,
 			expectErrorMsg: "cannot downgrade storage, WAL contains newer entries",
 		},
+		{
+			name:          "Downgrading v3.6 to v3.5 fails if roles have permissions with deny rules",
+			version:       V3_6,
+			targetVersion: V3_5,
+			overrideKeys: func(tx backend.BatchTx) {
+				MustUnsafeSaveConfStateToBackend(zap.NewNop(), tx, &raftpb.ConfState{})
+				UnsafeUpdateConsistentIndex(tx, 1, 1, false)
+				UnsafeSetStorageVersion(tx, &V3_6)
+				UnsafeCreateAuthRolesBucket(tx)
+				(&authBatchTx{tx: tx, lg: zap.NewNop()}).UnsafePutRole(&authpb.Role{
+					Name:          []byte("app"),
+					KeyPermission: []*authpb.Permission{{PermType: authpb.WRITE, Key: []byte("/apps/*/config"), Pattern: true, Deny: true}},
+				})
+			},
+			expectVersion:  &V3_6,
+			expectError:    true,
+			expectErrorMsg: `role "app" has permissions with key patterns, deny rules or operations, which are not supported before v3.6`,
+		},
+		{
+			name:          "Downgrading v3.6 to v3.5 fails if users are bound to tenants",
+			version:       V3_6,
+			targetVersion: V3_5,
+			overrideKeys: func(tx backend.BatchTx) {
+				MustUnsafeSaveConfStateToBackend(zap.NewNop(), tx, &raftpb.ConfState{})
+				UnsafeUpdateConsistentIndex(tx, 1, 1, false)
+				UnsafeSetStorageVersion(tx, &V3_6)
+				tx.UnsafeCreateBucket(AuthUsers)
+				(&authBatchTx{tx: tx, lg: zap.NewNop()}).UnsafePutUser(&authpb.User{
+					Name:    []byte("tenant-a"),
+					Options: &authpb.UserAddOptions{Prefix: []byte("/tenants/a/")},
+				})
+			},
+			expectVersion:  &V3_6,
+			expectError:    true,
+			expectErrorMsg: `user "tenant-a" is bound to the tenant of prefix "/tenants/a/", which is not supported before v3.6`,
+		},
 		{
 			name:           "Downgrading v3.5 to v3.4 is not supported as schema was introduced in v3.6",
 			version:        V3_5,
@@ -0,0 +1,159 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"go.etcd.io/etcd/client/pkg/v3/keyutil"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// The secondary index bucket holds three kinds of entries, each prefixed by a
+// single byte:
+//
+//	'd' + name                         -> ""     index definition
+//	'f' + esc(name) + esc(value) + key -> ""     forward entry, ordered by value
+//	'r' + esc(name) + key              -> value  reverse entry of a key
+//
+// esc escapes 0x00 as 0x00 0xff and terminates with 0x00 0x01, which keeps the
+// byte order of the escaped strings.
+const (
+	secondaryIndexDefinition = 'd'
+	secondaryIndexForward    = 'f'
+	secondaryIndexReverse    = 'r'
+)
+
+func UnsafeCreateSecondaryIndexBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(SecondaryIndex)
+}
+
+// UnsafeReadSecondaryIndexDefinitions returns the names of the indexes stored in the backend.
+func UnsafeReadSecondaryIndexDefinitions(tx backend.ReadTx) []string {
+	var names []string
+	tx.UnsafeForEach(SecondaryIndex, func(k, v []byte) error {
+		if len(k) > 0 && k[0] == secondaryIndexDefinition {
+			names = append(names, string(k[1:]))
+		}
+		return nil
+	})
+	return names
+}
+
+// UnsafeResetSecondaryIndexes drops all index entries and stores the given index definitions.
+func UnsafeResetSecondaryIndexes(tx backend.BatchTx, names []string) {
+	tx.UnsafeDeleteBucket(SecondaryIndex)
+	tx.UnsafeCreateBucket(SecondaryIndex)
+	for _, name := range names {
+		tx.UnsafePut(SecondaryIndex, append([]byte{secondaryIndexDefinition}, name...), []byte{})
+	}
+}
+
+// UnsafePutSecondaryIndexEntry records that the key has the given value in the named index.
+// Any previous entry of the key must be deleted first.
+func UnsafePutSecondaryIndexEntry(tx backend.BatchTx, name string, value, key []byte) {
+	tx.UnsafePut(SecondaryIndex, forwardKey(name, value, key), []byte{})
+	tx.UnsafePut(SecondaryIndex, reverseKey(name, key), value)
+}
+
+// UnsafeDeleteSecondaryIndexEntry removes the entry of the key from the named index, if any.
+func UnsafeDeleteSecondaryIndexEntry(tx backend.BatchTx, name string, key []byte) {
+	rk := reverseKey(name, key)
+	_, vs := tx.UnsafeRange(SecondaryIndex, rk, nil, 0)
+	if len(vs) == 0 {
+		return
+	}
+	tx.UnsafeDelete(SecondaryIndex, forwardKey(name, vs[0], key))
+	tx.UnsafeDelete(SecondaryIndex, rk)
+}
+
+// UnsafeRangeSecondaryIndex returns the keys and values of the entries of the named index
+// with a value in [value, valueEnd). If valueEnd is empty, only entries with exactly the
+// given value are returned; if it is "\x00", all entries with a value >= value are returned.
+//
+// The entries are overwritten and deleted within a batch, so they must be ranged in the
+// batch transaction, holding its lock.
+func UnsafeRangeSecondaryIndex(tx backend.BatchTx, name string, value, valueEnd []byte) (keys, values [][]byte) {
+	pfx := append([]byte{secondaryIndexForward}, escapeIndexBytes(nil, []byte(name), true)...)
+	var start, end []byte
+	switch {
+	case len(valueEnd) == 0:
+		start = escapeIndexBytes(append([]byte{}, pfx...), value, true)
+		end = keyutil.PrefixRangeEnd(start)
+	case len(valueEnd) == 1 && valueEnd[0] == 0:
+		start = escapeIndexBytes(append([]byte{}, pfx...), value, false)
+		end = keyutil.PrefixRangeEnd(pfx)
+	default:
+		start = escapeIndexBytes(append([]byte{}, pfx...), value, false)
+		end = escapeIndexBytes(append([]byte{}, pfx...), valueEnd, false)
+	}
+	ks, _ := tx.UnsafeRange(SecondaryIndex, start, end, 0)
+	for _, k := range ks {
+		v, rest, ok := unescapeIndexBytes(k[len(pfx):])
+		if !ok {
+			continue
+		}
+		keys = append(keys, rest)
+		values = append(values, v)
+	}
+	return keys, values
+}
+
+func forwardKey(name string, value, key []byte) []byte {
+	b := escapeIndexBytes([]byte{secondaryIndexForward}, []byte(name), true)
+	b = escapeIndexBytes(b, value, true)
+	return append(b, key...)
+}
+
+func reverseKey(name string, key []byte) []byte {
+	b := escapeIndexBytes([]byte{secondaryIndexReverse}, []byte(name), true)
+	return append(b, key...)
+}
+
+func escapeIndexBytes(dst, src []byte, terminate bool) []byte {
+	for _, c := range src {
+		if c == 0 {
+			dst = append(dst, 0, 0xff)
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	if terminate {
+		dst = append(dst, 0, 1)
+	}
+	return dst
+}
+
+// unescapeIndexBytes decodes a terminated escaped string from the front of b
+// and returns it with the remaining bytes.
+func unescapeIndexBytes(b []byte) (value, rest []byte, ok bool) {
+	for i := 0; i < len(b); i++ {
+		if b[i] != 0 {
+			value = append(value, b[i])
+			continue
+		}
+		if i+1 >= len(b) {
+			return nil, nil, false
+		}
+		switch b[i+1] {
+		case 0xff:
+			value = append(value, 0)
+			i++
+		case 1:
+			return value, b[i+2:], true
+		default:
+			return nil, nil, false
+		}
+	}
+	return nil, nil, false
+}
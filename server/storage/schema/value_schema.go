@@ -0,0 +1,79 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.uber.org/zap"
+)
+
+type valueSchemaBackend struct {
+	lg *zap.Logger
+	be backend.Backend
+}
+
+func NewValueSchemaBackend(lg *zap.Logger, be backend.Backend) *valueSchemaBackend {
+	return &valueSchemaBackend{
+		lg: lg,
+		be: be,
+	}
+}
+
+func (s *valueSchemaBackend) CreateValueSchemaBucket() {
+	tx := s.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	tx.UnsafeCreateBucket(ValueSchema)
+}
+
+// MustPutValueSchema stores the value schema under its prefix.
+func (s *valueSchemaBackend) MustPutValueSchema(vs *etcdserverpb.ValueSchema) {
+	v, err := vs.Marshal()
+	if err != nil {
+		s.lg.Panic("failed to marshal value schema", zap.Error(err))
+	}
+	tx := s.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	tx.UnsafePut(ValueSchema, vs.Prefix, v)
+}
+
+// MustDeleteValueSchema deletes the value schema of the prefix.
+func (s *valueSchemaBackend) MustDeleteValueSchema(prefix []byte) {
+	tx := s.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	tx.UnsafeDelete(ValueSchema, prefix)
+}
+
+// GetAllValueSchemas returns the value schemas, ordered by prefix.
+func (s *valueSchemaBackend) GetAllValueSchemas() ([]*etcdserverpb.ValueSchema, error) {
+	tx := s.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	var vss []*etcdserverpb.ValueSchema
+	err := tx.UnsafeForEach(ValueSchema, func(k, v []byte) error {
+		vs := &etcdserverpb.ValueSchema{}
+		if err := vs.Unmarshal(v); err != nil {
+			return fmt.Errorf("cannot unmarshal value schema %q: %w", k, err)
+		}
+		vss = append(vss, vs)
+		return nil
+	})
+	return vss, err
+}
@@ -169,8 +169,10 @@ type ClusterConfig struct {
 
 	WatchProgressNotifyInterval time.Duration
 	ExperimentalMaxLearners     int
+	LearnerServeReads           bool
 	StrictReconfigCheck         bool
 	CorruptCheckTime            time.Duration
+	SecondaryIndexes            []string
 }
 
 type Cluster struct {
@@ -282,8 +284,10 @@ func (c *Cluster) mustNewMember(t testutil.TB) *Member {
 			LeaseCheckpointPersist:      c.Cfg.LeaseCheckpointPersist,
 			WatchProgressNotifyInterval: c.Cfg.WatchProgressNotifyInterval,
 			ExperimentalMaxLearners:     c.Cfg.ExperimentalMaxLearners,
+			LearnerServeReads:           c.Cfg.LearnerServeReads,
 			StrictReconfigCheck:         c.Cfg.StrictReconfigCheck,
 			CorruptCheckTime:            c.Cfg.CorruptCheckTime,
+			SecondaryIndexes:            c.Cfg.SecondaryIndexes,
 		})
 	m.DiscoveryURL = c.Cfg.DiscoveryURL
 	return m
@@ -572,8 +576,10 @@ type MemberConfig struct {
 	LeaseCheckpointPersist      bool
 	WatchProgressNotifyInterval time.Duration
 	ExperimentalMaxLearners     int
+	LearnerServeReads           bool
 	StrictReconfigCheck         bool
 	CorruptCheckTime            time.Duration
+	SecondaryIndexes            []string
 }
 
 // MustNewMember return an inited member with the given name. If peerTLS is
@@ -685,10 +691,12 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	if mcfg.ExperimentalMaxLearners != 0 {
 		m.ExperimentalMaxLearners = mcfg.ExperimentalMaxLearners
 	}
+	m.ExperimentalLearnerServeReads = mcfg.LearnerServeReads
 	m.V2Deprecation = config.V2_DEPR_DEFAULT
 	m.GrpcServerRecorder = &grpc_testing.GrpcRecorder{}
 	m.Logger = memberLogger(t, mcfg.Name)
 	m.StrictReconfigCheck = mcfg.StrictReconfigCheck
+	m.ExperimentalSecondaryIndexes = mcfg.SecondaryIndexes
 	if err := m.listenGRPC(); err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,128 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestBarrierSessionExpired ensures the barrier is released when the
+// session of its holder ends.
+func TestBarrierSessionExpired(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 2)
+	holder := concurrency.NewBarrier(ss[0], "/test-barrier")
+	if err = holder.Hold(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = concurrency.NewBarrier(ss[1], "/test-barrier").Hold(context.TODO()); err != concurrency.ErrBarrierHeld {
+		t.Fatalf("expected %v, got %v", concurrency.ErrBarrierHeld, err)
+	}
+
+	waited := make(chan error, 1)
+	go func() { waited <- concurrency.NewBarrier(ss[1], "/test-barrier").Wait(context.TODO()) }()
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case err = <-waited:
+		t.Fatalf("wait returned before the barrier was released (%v)", err)
+	}
+
+	if err = ss[0].Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait did not return after holder session closed")
+	case err = <-waited:
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDoubleBarrier(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	waiters := 5
+	ss := newTestSessions(t, cli, waiters)
+
+	entered := make(chan error, waiters)
+	left := make(chan error, waiters)
+	leavec := make(chan struct{})
+	for i := 0; i < waiters-1; i++ {
+		b := concurrency.NewDoubleBarrier(ss[i], "/test-double-barrier", waiters)
+		go func() {
+			entered <- b.Enter(context.TODO())
+			<-leavec
+			left <- b.Leave(context.TODO())
+		}()
+	}
+
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case err = <-entered:
+		t.Fatalf("entered before all processes arrived (%v)", err)
+	}
+
+	last := concurrency.NewDoubleBarrier(ss[waiters-1], "/test-double-barrier", waiters)
+	if err = last.Enter(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < waiters-1; i++ {
+		select {
+		case <-time.After(5 * time.Second):
+			t.Fatal("barrier enter timed out")
+		case err = <-entered:
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	close(leavec)
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case err = <-left:
+		t.Fatalf("left before all processes left (%v)", err)
+	}
+	if err = last.Leave(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < waiters-1; i++ {
+		select {
+		case <-time.After(5 * time.Second):
+			t.Fatal("barrier leave timed out")
+		case err = <-left:
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
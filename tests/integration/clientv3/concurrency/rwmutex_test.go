@@ -0,0 +1,147 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestRWMutexReadersShare(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 3)
+	r1 := concurrency.NewRWMutex(ss[0], "/test-rwmutex")
+	r2 := concurrency.NewRWMutex(ss[1], "/test-rwmutex")
+	w := concurrency.NewRWMutex(ss[2], "/test-rwmutex")
+
+	if err = r1.RLock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = r2.TryRLock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.TryLock(context.TODO()); err != concurrency.ErrLocked {
+		t.Fatalf("expected %v, got %v", concurrency.ErrLocked, err)
+	}
+
+	locked := make(chan error, 1)
+	go func() { locked <- w.Lock(context.TODO()) }()
+
+	if err = r1.RUnlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case err = <-locked:
+		t.Fatalf("writer locked while a reader holds the lock (%v)", err)
+	}
+
+	if err = r2.RUnlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer did not lock after readers unlocked")
+	case err = <-locked:
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Unlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRWMutexWriterNotStarved ensures a waiting writer blocks later readers.
+func TestRWMutexWriterNotStarved(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 3)
+	r1 := concurrency.NewRWMutex(ss[0], "/test-rwmutex-starve")
+	w := concurrency.NewRWMutex(ss[1], "/test-rwmutex-starve")
+	r2 := concurrency.NewRWMutex(ss[2], "/test-rwmutex-starve")
+
+	if err = r1.RLock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	wlocked := make(chan error, 1)
+	go func() { wlocked <- w.Lock(context.TODO()) }()
+	time.Sleep(100 * time.Millisecond)
+
+	if err = r2.TryRLock(context.TODO()); err != concurrency.ErrLocked {
+		t.Fatalf("expected %v, got %v", concurrency.ErrLocked, err)
+	}
+	if err = r1.RUnlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer did not lock after reader unlocked")
+	case err = <-wlocked:
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Unlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRWMutexExcludesMutex ensures the write lock and a Mutex on the same
+// prefix exclude each other.
+func TestRWMutexExcludesMutex(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 2)
+	rw := concurrency.NewRWMutex(ss[0], "/test-rwmutex-mutex")
+	m := concurrency.NewMutex(ss[1], "/test-rwmutex-mutex")
+
+	if err = rw.RLock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.TryLock(context.TODO()); err != concurrency.ErrLocked {
+		t.Fatalf("expected %v, got %v", concurrency.ErrLocked, err)
+	}
+	if err = rw.RUnlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.TryLock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = rw.TryRLock(context.TODO()); err != concurrency.ErrLocked {
+		t.Fatalf("expected %v, got %v", concurrency.ErrLocked, err)
+	}
+	if err = m.Unlock(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func newTestSessions(t *testing.T, cli *clientv3.Client, n int) []*concurrency.Session {
+	var ss []*concurrency.Session
+	for i := 0; i < n; i++ {
+		s, err := concurrency.NewSession(cli)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+func TestSemaphoreAcquire(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 3)
+	sm1 := concurrency.NewSemaphore(ss[0], "/test-semaphore", 2)
+	sm2 := concurrency.NewSemaphore(ss[1], "/test-semaphore", 2)
+	sm3 := concurrency.NewSemaphore(ss[2], "/test-semaphore", 2)
+
+	// two permits are available without waiting
+	if err = sm1.Acquire(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = sm2.TryAcquire(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err = sm3.TryAcquire(context.TODO()); err != concurrency.ErrNoPermits {
+		t.Fatalf("expected %v, got %v", concurrency.ErrNoPermits, err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- sm3.Acquire(context.TODO()) }()
+
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case err = <-acquired:
+		t.Fatalf("acquired more permits than the limit (%v)", err)
+	}
+
+	if err = sm1.Release(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter did not acquire released permit")
+	case err = <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestSemaphoreFIFO ensures that a waiter is never overtaken by a later one.
+func TestSemaphoreFIFO(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 3)
+	holder := concurrency.NewSemaphore(ss[0], "/test-semaphore-fifo", 1)
+	if err = holder.Acquire(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		sm := concurrency.NewSemaphore(ss[i], "/test-semaphore-fifo", 1)
+		go func(i int) {
+			if err := sm.Acquire(context.TODO()); err != nil {
+				t.Error(err)
+			}
+			order <- i
+			sm.Release(context.TODO())
+		}(i)
+		// give the waiter time to register
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err = holder.Release(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	for want := 1; want <= 2; want++ {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("expected waiter %d to acquire, got %d", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for waiter %d", want)
+		}
+	}
+}
+
+func TestSemaphoreAcquireCanceled(t *testing.T) {
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: exampleEndpoints()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ss := newTestSessions(t, cli, 2)
+	sm1 := concurrency.NewSemaphore(ss[0], "/test-semaphore-cancel", 1)
+	sm2 := concurrency.NewSemaphore(ss[1], "/test-semaphore-cancel", 1)
+	if err = sm1.Acquire(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+	if err = sm2.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// the canceled waiter must not leave a stale entry behind
+	resp, err := cli.Get(context.TODO(), "/test-semaphore-cancel/", clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 1 {
+		t.Fatalf("expected 1 semaphore key, got %d", resp.Count)
+	}
+}
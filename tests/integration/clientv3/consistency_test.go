@@ -0,0 +1,124 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/consistency"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestConsistencyTokenAcrossClients ensures that a client observing the token
+// of another client's session reads its writes from a lagging member once the
+// member catches up.
+func TestConsistencyTokenAcrossClients(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lead := clus.WaitLeader(t)
+	follower := (lead + 1) % 3
+	var others []*integration2.Member
+	for i, m := range clus.Members {
+		if i != follower {
+			others = append(others, m)
+		}
+	}
+
+	ctx := context.TODO()
+	wcli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{clus.Members[lead].GRPCURL()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wcli.Close()
+	writer := consistency.NewSession()
+	wkv := consistency.NewKV(wcli.KV, writer)
+	if _, err = wkv.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{clus.Members[follower].GRPCURL()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	// ensure the follower has applied the first write
+	if _, err = cli.Get(ctx, "foo", clientv3.WithMinRevision(writer.Revision())); err != nil {
+		t.Fatal(err)
+	}
+
+	clus.Members[follower].InjectPartition(t, others...)
+	time.Sleep(time.Second)
+
+	if _, err = wkv.Put(ctx, "foo", "baz"); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := consistency.ParseToken(writer.Token().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := consistency.NewSession()
+	if err = reader.Observe(tok); err != nil {
+		t.Fatal(err)
+	}
+	rkv := consistency.NewKV(cli.KV, reader)
+
+	// the partitioned member has not applied the second write
+	tctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	_, err = rkv.Get(tctx, "foo", clientv3.WithSerializable())
+	cancel()
+	if err == nil {
+		t.Fatal("expected the get to wait for the partitioned member to apply the token revision")
+	}
+	tctx, cancel = context.WithTimeout(ctx, 500*time.Millisecond)
+	_, err = rkv.Txn(tctx).Then(clientv3.OpGet("foo", clientv3.WithSerializable())).Commit()
+	cancel()
+	if err == nil {
+		t.Fatal("expected the transaction to wait for the partitioned member to apply the token revision")
+	}
+	gresp, err := cli.Get(ctx, "foo", clientv3.WithSerializable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gresp.Kvs[0].Value) != "bar" {
+		t.Fatalf("expected the partitioned member to miss the second write, got %q", gresp.Kvs[0].Value)
+	}
+
+	clus.Members[follower].RecoverPartition(t, others...)
+
+	tctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	gresp, err = rkv.Get(tctx, "foo", clientv3.WithSerializable())
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gresp.Kvs[0].Value) != "baz" {
+		t.Fatalf("expected to read the write of the token, got %q", gresp.Kvs[0].Value)
+	}
+	tresp, err := rkv.Txn(ctx).Then(clientv3.OpGet("foo", clientv3.WithSerializable())).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kvs := tresp.Responses[0].GetResponseRange().Kvs; string(kvs[0].Value) != "baz" {
+		t.Fatalf("expected the transaction to read the write of the token, got %q", kvs[0].Value)
+	}
+	if reader.Revision() < tok.Revision {
+		t.Fatalf("expected the session revision to be at least %d, got %d", tok.Revision, reader.Revision())
+	}
+}
@@ -519,6 +519,79 @@ func TestKVNewAfterClose(t *testing.T) {
 	}
 }
 
+func TestKVGetIndex(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3, SecondaryIndexes: []string{"/jobs/=status.state"}})
+	defer clus.Terminate(t)
+
+	kv := clus.RandClient()
+	ctx := context.TODO()
+
+	jobs := map[string]string{
+		"/jobs/1": `{"status":{"state":"running"}}`,
+		"/jobs/2": `{"status":{"state":"done"}}`,
+		"/jobs/3": `{"status":{"state":"running"}}`,
+		"/jobs/4": `{"status":{"state":"failed"}}`,
+		"/other":  `{"status":{"state":"running"}}`,
+	}
+	for k, v := range jobs {
+		if _, err := kv.Put(ctx, k, v); err != nil {
+			t.Fatalf("couldn't put %q (%v)", k, err)
+		}
+	}
+	if _, err := kv.Put(ctx, "/jobs/3", `{"status":{"state":"done"}}`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opts []clientv3.OpOption
+
+		wkeys  []string
+		wcount int64
+		wmore  bool
+	}{
+		{
+			[]clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithIndex("status.state", "running")},
+			[]string{"/jobs/1"}, 1, false,
+		},
+		{
+			[]clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithIndex("status.state", "done")},
+			[]string{"/jobs/2", "/jobs/3"}, 2, false,
+		},
+		{
+			[]clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithIndexRange("status.state", "d", "\x00"), clientv3.WithLimit(3)},
+			[]string{"/jobs/2", "/jobs/3", "/jobs/4"}, 4, true,
+		},
+		{
+			[]clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithIndexRange("status.state", "d", "r"), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend)},
+			[]string{"/jobs/4", "/jobs/3", "/jobs/2"}, 3, false,
+		},
+		{
+			[]clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithIndex("status.state", "done"), clientv3.WithCountOnly()},
+			nil, 2, false,
+		},
+	}
+	for i, tt := range tests {
+		resp, err := kv.Get(ctx, "/jobs/", tt.opts...)
+		if err != nil {
+			t.Fatalf("#%d: couldn't range (%v)", i, err)
+		}
+		var keys []string
+		for _, kv := range resp.Kvs {
+			keys = append(keys, string(kv.Key))
+		}
+		if !reflect.DeepEqual(keys, tt.wkeys) || resp.Count != tt.wcount || resp.More != tt.wmore {
+			t.Errorf("#%d: expected %v (count %d, more %v), got %v (count %d, more %v)", i, tt.wkeys, tt.wcount, tt.wmore, keys, resp.Count, resp.More)
+		}
+	}
+
+	_, err := kv.Get(ctx, "/", clientv3.WithPrefix(), clientv3.WithIndex("status.state", "running"))
+	if err != rpctypes.ErrSecondaryIndexNotFound {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrSecondaryIndexNotFound, err)
+	}
+}
+
 func TestKVDeleteRange(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -1126,3 +1199,116 @@ func TestBalancerSupportLearner(t *testing.T) {
 		t.Errorf("expect no error (balancer should retry when request to learner fails), got error: %v", err)
 	}
 }
+
+// TestKVForLearnerServeReads ensures a learner serving reads accepts linearizable
+// reads and watches, and still rejects writes.
+func TestKVForLearnerServeReads(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3, LearnerServeReads: true})
+	defer clus.Terminate(t)
+
+	clus.AddAndLaunchLearnerMember(t)
+
+	// clus.Members[3] is the newly added learner member, which was appended to clus.Members
+	cfg := clientv3.Config{
+		Endpoints:   []string{clus.Members[3].GRPCURL()},
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{grpc.WithBlock()},
+	}
+	cli, err := integration2.NewClient(t, cfg)
+	if err != nil {
+		t.Fatalf("failed to create clientv3: %v", err)
+	}
+	defer cli.Close()
+
+	<-clus.Members[3].ReadyNotify()
+
+	wch := cli.Watch(context.TODO(), "foo")
+	presp, err := clus.Client(0).Put(context.TODO(), "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the linearizable read waits for the learner to apply the put
+	gresp, err := cli.Get(context.TODO(), "foo")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if len(gresp.Kvs) != 1 || string(gresp.Kvs[0].Value) != "bar" || gresp.Header.Revision < presp.Header.Revision {
+		t.Fatalf("unexpected response %+v", gresp)
+	}
+
+	select {
+	case wresp := <-wch:
+		if err = wresp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(wresp.Events) != 1 || string(wresp.Events[0].Kv.Value) != "bar" {
+			t.Fatalf("unexpected events %+v", wresp.Events)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed to receive watch response")
+	}
+
+	if _, err = cli.Put(context.TODO(), "foo", "baz"); err == nil {
+		t.Fatal("expect Put request to learner to fail, got no error")
+	}
+}
+
+// TestBalancerLearnerEndpoints ensures the client sends writes to voting members
+// only, and reads to learners when preferred.
+func TestBalancerLearnerEndpoints(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3, LearnerServeReads: true})
+	defer clus.Terminate(t)
+
+	clus.AddAndLaunchLearnerMember(t)
+
+	cfg := clientv3.Config{
+		Endpoints:          []string{clus.Members[0].GRPCURL()},
+		LearnerEndpoints:   []string{clus.Members[3].GRPCURL()},
+		PreferLearnerReads: true,
+		DialTimeout:        5 * time.Second,
+		DialOptions:        []grpc.DialOption{grpc.WithBlock()},
+	}
+	cli, err := integration2.NewClient(t, cfg)
+	if err != nil {
+		t.Fatalf("failed to create clientv3: %v", err)
+	}
+	defer cli.Close()
+
+	<-clus.Members[3].ReadyNotify()
+
+	if len(cli.Endpoints()) != 2 {
+		t.Fatalf("expected the learner endpoint to be added, got %v", cli.Endpoints())
+	}
+	for i := 0; i < 10; i++ {
+		v := fmt.Sprintf("v%d", i)
+		if _, err = cli.Put(context.TODO(), "foo", v); err != nil {
+			t.Fatalf("#%d: expect no error, got %v", i, err)
+		}
+		gresp, err := cli.Get(context.TODO(), "foo")
+		if err != nil {
+			t.Fatalf("#%d: expect no error, got %v", i, err)
+		}
+		if len(gresp.Kvs) != 1 || string(gresp.Kvs[0].Value) != v {
+			t.Fatalf("#%d: unexpected response %+v", i, gresp)
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, r := range clus.Members[3].RecordedRequests() {
+		counts[r.FullMethod]++
+	}
+	if counts["/etcdserverpb.KV/Range"] != 10 || counts["/etcdserverpb.KV/Put"] != 0 {
+		t.Fatalf("expected the learner to serve all the ranges and no put, got %v", counts)
+	}
+
+	// reads are still served with the learner down
+	clus.Members[3].Stop(t)
+	if _, err = cli.Get(context.TODO(), "foo"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+}
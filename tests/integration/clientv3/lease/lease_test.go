@@ -651,6 +651,89 @@ func TestLeaseLeases(t *testing.T) {
 	}
 }
 
+// TestLeaseWatchEvents ensures lease events are streamed for granted, renewed,
+// revoked and expired leases, and that the deletions of the keys of an expired
+// lease are marked in their DELETE events.
+func TestLeaseWatchEvents(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	evc, err := cli.WatchEvents(ctx, clientv3.WithRenewThreshold(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wch := cli.Watch(ctx, "foo")
+
+	l1, err := cli.Grant(ctx, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.KeepAliveOnce(ctx, l1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Revoke(ctx, l1.ID); err != nil {
+		t.Fatal(err)
+	}
+	l2, err := cli.Grant(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(ctx, "foo", "bar", clientv3.WithLease(l2.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	type event struct {
+		typ clientv3.LeaseEventType
+		id  clientv3.LeaseID
+	}
+	wevs := []event{
+		{clientv3.LeaseEventGranted, l1.ID},
+		{clientv3.LeaseEventRenewed, l1.ID},
+		{clientv3.LeaseEventRevoked, l1.ID},
+		{clientv3.LeaseEventGranted, l2.ID},
+		{clientv3.LeaseEventExpired, l2.ID},
+	}
+	var evs []event
+	for len(evs) < len(wevs) {
+		select {
+		case resp := <-evc:
+			if resp.Err() != nil {
+				t.Fatal(resp.Err())
+			}
+			for _, ev := range resp.Events {
+				evs = append(evs, event{ev.Type, ev.ID})
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for lease events, got %v", evs)
+		}
+	}
+	if !reflect.DeepEqual(evs, wevs) {
+		t.Fatalf("expected lease events %v, got %v", wevs, evs)
+	}
+
+	var kevs []*clientv3.Event
+	for len(kevs) < 2 {
+		select {
+		case wresp := <-wch:
+			kevs = append(kevs, wresp.Events...)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for key events")
+		}
+	}
+	if kevs[0].Type != clientv3.EventTypePut || kevs[0].LeaseExpired {
+		t.Errorf("expected a put, got %+v", kevs[0])
+	}
+	if kevs[1].Type != clientv3.EventTypeDelete || !kevs[1].LeaseExpired || clientv3.LeaseID(kevs[1].Kv.Lease) != l2.ID {
+		t.Errorf("expected a delete by the expiry of lease %x, got %+v", l2.ID, kevs[1])
+	}
+}
+
 // TestLeaseRenewLostQuorum ensures keepalives work after losing quorum
 // for a while.
 func TestLeaseRenewLostQuorum(t *testing.T) {
@@ -0,0 +1,258 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.etcd.io/etcd/client/v3/queue"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestQueueAck(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	s, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	q := queue.NewQueue(s, "/test-queue")
+
+	for _, v := range []string{"a", "b", "c"} {
+		if _, err = q.Enqueue(context.TODO(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		it, err := q.Dequeue(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if it.Value != want || it.Attempts != 1 {
+			t.Fatalf("expected %q with 1 attempt, got %q with %d", want, it.Value, it.Attempts)
+		}
+		if err = it.Ack(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = q.TryDequeue(context.TODO()); err != queue.ErrEmpty {
+		t.Fatalf("expected %v, got %v", queue.ErrEmpty, err)
+	}
+	st, err := q.Stats(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st != (queue.Stats{}) {
+		t.Fatalf("expected empty queue, got %+v", st)
+	}
+}
+
+// TestQueueWorkerCrash ensures an item claimed by a worker whose session
+// ends is returned to the queue.
+func TestQueueWorkerCrash(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	s1, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q1 := queue.NewQueue(s1, "/test-queue")
+	if _, err = q1.Enqueue(context.TODO(), "job"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = q1.Dequeue(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	q2 := queue.NewQueue(s2, "/test-queue")
+	if _, err = q2.TryDequeue(context.TODO()); err != queue.ErrEmpty {
+		t.Fatalf("expected %v while the item is claimed, got %v", queue.ErrEmpty, err)
+	}
+
+	donec := make(chan *queue.Item, 1)
+	go func() {
+		it, derr := q2.Dequeue(context.TODO())
+		if derr != nil {
+			t.Error(derr)
+		}
+		donec <- it
+	}()
+
+	// the worker crashes; its lease is revoked
+	if err = s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case it := <-donec:
+		if it == nil {
+			t.Fatal("expected item")
+		}
+		if it.Value != "job" || it.Attempts != 2 {
+			t.Fatalf("expected %q with 2 attempts, got %q with %d", "job", it.Value, it.Attempts)
+		}
+		if err = it.Ack(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("item was not returned to the queue")
+	}
+}
+
+// TestQueueWorkerCrashPending ensures an item claimed by a worker whose
+// session ends is returned to the queue while other items are pending.
+func TestQueueWorkerCrashPending(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	s1, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q1 := queue.NewQueue(s1, "/test-queue")
+	for _, v := range []string{"a", "b"} {
+		if _, err = q1.Enqueue(context.TODO(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = q1.Dequeue(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	q2 := queue.NewQueue(s2, "/test-queue")
+
+	// the worker crashes; its lease is revoked
+	if err = s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; ; i++ {
+		st, err := q2.Stats(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Pending == 2 && st.Claimed == 0 {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("expected the claimed item to be returned to the queue, got %+v", st)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestQueueVisibilityTimeout(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	s, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	q := queue.NewQueue(s, "/test-queue", queue.WithVisibilityTimeout(time.Second))
+	if _, err = q.Enqueue(context.TODO(), "job"); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := q.Dequeue(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	it, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", it.Attempts)
+	}
+	if err = stale.Ack(context.TODO()); err != queue.ErrClaimLost {
+		t.Fatalf("expected %v, got %v", queue.ErrClaimLost, err)
+	}
+	if err = it.Ack(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueueDeadLetter(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	s, err := concurrency.NewSession(clus.Client(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	q := queue.NewQueue(s, "/test-queue", queue.WithMaxAttempts(2))
+	id, err := q.Enqueue(context.TODO(), "poison")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		it, err := q.TryDequeue(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = it.Nack(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = q.TryDequeue(context.TODO()); err != queue.ErrEmpty {
+		t.Fatalf("expected %v, got %v", queue.ErrEmpty, err)
+	}
+
+	dead, err := q.DeadLetters(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].ID != id || dead[0].Attempts != 2 {
+		t.Fatalf("expected dead letter %q with 2 attempts, got %+v", id, dead)
+	}
+
+	if err = q.Redrive(context.TODO(), id); err != nil {
+		t.Fatal(err)
+	}
+	it, err := q.TryDequeue(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.ID != id || it.Attempts != 1 {
+		t.Fatalf("expected item %q with 1 attempt, got %q with %d", id, it.ID, it.Attempts)
+	}
+	if err = it.Ack(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+// +build !cluster_proxy
+
+package clientv3test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestWatchMaxEventsPerSecondCoalesces ensures the updates of a key sent over
+// the rate of a watcher are coalesced into its latest value.
+func TestWatchMaxEventsPerSecondCoalesces(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wch := cli.Watch(ctx, "foo", clientv3.WithMaxEventsPerSecond(5), clientv3.WithCreatedNotify())
+	if wresp := <-wch; !wresp.Created {
+		t.Fatalf("expected created response, got %+v", wresp)
+	}
+
+	numPuts := 50
+	var lastRev int64
+	for i := 0; i < numPuts; i++ {
+		resp, err := cli.Put(ctx, "foo", fmt.Sprintf("v%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastRev = resp.Header.Revision
+	}
+
+	evs, coalesced := 0, 0
+	for {
+		wresp, ok := <-wch
+		if !ok {
+			t.Fatalf("watch closed before revision %d (%v)", lastRev, ctx.Err())
+		}
+		if err := wresp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range wresp.Events {
+			evs++
+			if ev.Coalesced {
+				coalesced++
+			}
+			if ev.Kv.ModRevision == lastRev {
+				if string(ev.Kv.Value) != fmt.Sprintf("v%d", numPuts-1) {
+					t.Fatalf("unexpected latest value %q", ev.Kv.Value)
+				}
+				if evs >= numPuts || coalesced == 0 {
+					t.Fatalf("expected coalesced events, got %d events with %d coalesced", evs, coalesced)
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+// +build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/netsim"
+	"go.etcd.io/etcd/server/v3/embed"
+	"google.golang.org/grpc"
+)
+
+// TestEmbedEtcdSimulatedNetwork runs a three member cluster over a simulated
+// network with latency and message drops, isolates the leader and checks that
+// the remaining members elect a new one and that the old leader catches up
+// once the partition heals.
+func TestEmbedEtcdSimulatedNetwork(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	n := netsim.New(1)
+	n.SetDefaultLink(netsim.LinkConfig{Latency: time.Millisecond, Jitter: 2 * time.Millisecond, DropRate: 0.005})
+
+	const size = 3
+	var initialCluster []string
+	for i := 1; i <= size; i++ {
+		initialCluster = append(initialCluster, fmt.Sprintf("m%d=http://10.0.0.%d:2380", i, i))
+	}
+	etcds := make([]*embed.Etcd, size)
+	endpoints := make([]string, size)
+	for i := range etcds {
+		cfg := embed.NewConfig()
+		cfg.Name = fmt.Sprintf("m%d", i+1)
+		cfg.Dir = t.TempDir()
+		cfg.Logger = "zap"
+		cfg.LogOutputs = []string{"/dev/null"}
+		cfg.TickMs, cfg.ElectionMs = 10, 100
+		cfg.InitialCluster = strings.Join(initialCluster, ",")
+		curl, _ := url.Parse(fmt.Sprintf("http://10.0.0.%d:2379", i+1))
+		purl, _ := url.Parse(fmt.Sprintf("http://10.0.0.%d:2380", i+1))
+		cfg.LCUrls, cfg.ACUrls = []url.URL{*curl}, []url.URL{*curl}
+		cfg.LPUrls, cfg.APUrls = []url.URL{*purl}, []url.URL{*purl}
+		cfg.ExperimentalNetwork = n.Host(cfg.Name)
+		endpoints[i] = curl.String()
+
+		e, err := embed.StartEtcd(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer e.Close()
+		etcds[i] = e
+	}
+	for _, e := range etcds {
+		select {
+		case <-e.Server.ReadyNotify():
+		case <-time.After(10 * time.Second):
+			t.Fatalf("member %s not ready", e.Config().Name)
+		}
+	}
+
+	newClient := func(eps ...string) *clientv3.Client {
+		cli, err := clientv3.New(clientv3.Config{
+			Endpoints:   eps,
+			DialTimeout: 5 * time.Second,
+			DialOptions: []grpc.DialOption{grpc.WithContextDialer(n.Host("client").DialAddr)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { cli.Close() })
+		return cli
+	}
+	put := func(cli *clientv3.Client, key string) {
+		var err error
+		for i := 0; i < 10; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			_, err = cli.Put(ctx, key, "v")
+			cancel()
+			if err == nil {
+				return
+			}
+		}
+		t.Fatalf("failed to put %q: %v", key, err)
+	}
+	put(newClient(endpoints...), "before")
+
+	waitFor(t, "leader", func() bool { return etcds[0].Server.Lead() != 0 })
+	oldLead := etcds[0].Server.Lead()
+	var leader int
+	var others []int
+	for i, e := range etcds {
+		if uint64(e.Server.ID()) == oldLead {
+			leader = i
+		} else {
+			others = append(others, i)
+		}
+	}
+
+	n.Isolate(etcds[leader].Config().Name)
+	waitFor(t, "new leader", func() bool {
+		lead := etcds[others[0]].Server.Lead()
+		return lead != 0 && lead != oldLead && lead == etcds[others[1]].Server.Lead()
+	})
+	put(newClient(endpoints[others[0]], endpoints[others[1]]), "during")
+	if _, err := newClient(endpoints[leader]).Put(shortContext(t), "isolated", "v"); err == nil {
+		t.Fatal("put on the isolated member succeeded")
+	}
+
+	n.Heal()
+	rev := etcds[others[0]].Server.KV().Rev()
+	waitFor(t, "isolated member to catch up", func() bool {
+		return etcds[leader].Server.KV().Rev() >= rev
+	})
+	resp, err := newClient(endpoints[leader]).Get(shortContext(t), "during")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 {
+		t.Fatalf("key written during the partition not found on the old leader")
+	}
+}
+
+func shortContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
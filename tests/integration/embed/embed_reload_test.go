@@ -0,0 +1,143 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+// +build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestEmbedEtcdReloadConfig(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(true, 2)
+	curl, purl := urls[0].String(), urls[1].String()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "etcd.conf.yaml")
+	writeConfig := func(extra string) {
+		conf := fmt.Sprintf(`name: default
+data-dir: %s
+listen-client-urls: %s
+advertise-client-urls: %s
+listen-peer-urls: %s
+initial-advertise-peer-urls: %s
+initial-cluster: default=%s
+log-outputs: [/dev/null]
+client-transport-security:
+  cert-file: %s
+  key-file: %s
+  trusted-ca-file: %s
+  client-cert-auth: true
+peer-transport-security:
+  cert-file: %s
+  key-file: %s
+  trusted-ca-file: %s
+%s`, filepath.Join(dir, "data"), curl, curl, purl, purl, purl,
+			testTLSInfo.CertFile, testTLSInfo.KeyFile, testTLSInfo.TrustedCAFile,
+			testTLSInfo.CertFile, testTLSInfo.KeyFile, testTLSInfo.TrustedCAFile, extra)
+		if err := os.WriteFile(path, []byte(conf), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("")
+
+	cfg, err := embed.ConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	newClient := func() *clientv3.Client {
+		tls, err := testTLSInfo.ClientConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{curl}, TLS: tls, DialTimeout: 5 * time.Second})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+	cli := newClient()
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// an unchanged configuration changes nothing
+	resp, err := cli.ReloadConfig(ctx, curl, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Generation != 1 || len(resp.Applied) != 0 || len(resp.Ignored) != 0 {
+		t.Fatalf("unexpected reload of an unchanged configuration %+v", resp)
+	}
+
+	writeConfig(`log-level: debug
+cors: http://example.com:8080
+max-txn-ops: 256
+cipher-suites: [TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384]
+`)
+	wApplied := []string{"cipher-suites", "cors", "log-level"}
+	wIgnored := []string{"max-txn-ops"}
+	for _, dryRun := range []bool{true, false} {
+		resp, err = cli.ReloadConfig(ctx, curl, dryRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(resp.Applied, wApplied) || !reflect.DeepEqual(resp.Ignored, wIgnored) {
+			t.Fatalf("expected applied %v and ignored %v, got %+v", wApplied, wIgnored, resp)
+		}
+		if e.Server.OriginAllowed("http://other.com:8080") != dryRun {
+			t.Fatalf("expected the CORS origins to be applied only without dry run")
+		}
+	}
+	if resp.Generation != 2 {
+		t.Fatalf("expected generation 2, got %d", resp.Generation)
+	}
+
+	// new connections use the reloaded TLS configuration
+	cli2 := newClient()
+	defer cli2.Close()
+	if _, err = cli2.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid configurations are not applied
+	writeConfig("log-level: verbose\ncors: http://other.com:8080\n")
+	if _, err = cli.ReloadConfig(ctx, curl, false); err == nil {
+		t.Fatal("expected an error reloading an invalid configuration")
+	}
+	if e.Server.OriginAllowed("http://other.com:8080") {
+		t.Fatal("expected the invalid configuration not to be applied")
+	}
+}
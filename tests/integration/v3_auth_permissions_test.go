@@ -0,0 +1,116 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestV3AuthKeyPermissions tests key patterns, deny rules and operations of role permissions.
+func TestV3AuthKeyPermissions(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "/apps/",
+			end:      "/apps0",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+
+	ctx := context.TODO()
+	perms := []*clientv3.Permission{
+		{PermType: clientv3.PermWrite, Key: []byte("/apps/*/config"), Pattern: true, Deny: true},
+		{PermType: clientv3.PermWrite, Key: []byte("/leases/"), RangeEnd: []byte("/leases0"), Ops: []authpb.Permission_Operation{clientv3.PermOpPut, clientv3.PermOpLease}},
+	}
+	for _, perm := range perms {
+		if _, err := rootc.RoleGrantKeyPermission(ctx, "role1", perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rootc.RoleGrantKeyPermission(ctx, "role1", &clientv3.Permission{Key: []byte("/a/*"), RangeEnd: []byte("/b"), Pattern: true}); err != rpctypes.ErrInvalidPermission {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrInvalidPermission, err)
+	}
+
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+
+	if _, err := userc.Put(ctx, "/apps/a/status", "up"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := userc.Put(ctx, "/apps/a/config", "cfg"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err := userc.Get(ctx, "/apps/a/config"); err != nil {
+		t.Fatal(err)
+	}
+	// the range holds keys matching the denied pattern
+	if _, err := userc.Delete(ctx, "/apps/", clientv3.WithPrefix()); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// nested transactions are checked as well
+	nested := clientv3.OpTxn(nil, []clientv3.Op{clientv3.OpPut("/apps/b/config", "cfg")}, nil)
+	if _, err := userc.Txn(ctx).Then(nested).Commit(); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// the keys under /leases/ may be leased but not deleted
+	leaseResp, err := rootc.Grant(ctx, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = userc.Put(ctx, "/leases/a", "val", clientv3.WithLease(leaseResp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = userc.Delete(ctx, "/leases/a"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = userc.Revoke(ctx, leaseResp.ID); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = userc.Get(ctx, "/leases/a"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// revoking the deny rule permits the writes again
+	if _, err = rootc.RoleRevokeKeyPermission(ctx, "role1", perms[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = userc.Put(ctx, "/apps/a/config", "cfg"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -16,6 +16,7 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -293,6 +294,98 @@ func TestV3AuthWithLeaseAttach(t *testing.T) {
 	}
 }
 
+// TestV3AuthWithLeaseMove ensures moving the keys of a lease requires the
+// permission to put them, not only to change their leases.
+func TestV3AuthWithLeaseMove(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	api := integration.ToGRPC(clus.Client(0))
+	authSetupUsers(t, api.Auth, []user{{name: "user1", password: "user1-123", role: "role1"}})
+	leasePerm := &authpb.Permission{PermType: authpb.WRITE, Key: []byte("k1"), RangeEnd: []byte("k3"), Ops: []authpb.Permission_Operation{authpb.LEASE}}
+	if _, err := api.Auth.RoleGrantPermission(context.TODO(), &pb.AuthRoleGrantPermissionRequest{Name: "role1", Perm: leasePerm}); err != nil {
+		t.Fatal(err)
+	}
+	authSetupRoot(t, api.Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+
+	var ids []clientv3.LeaseID
+	for i := 0; i < 2; i++ {
+		resp, err := rootc.Grant(context.TODO(), 90)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, resp.ID)
+	}
+	if _, err := rootc.Put(context.TODO(), "k1", "val", clientv3.WithLease(ids[0])); err != nil {
+		t.Fatal(err)
+	}
+
+	// user1 may change the lease of k1 but not put it
+	if _, err := userc.Move(context.TODO(), ids[0], ids[1]); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// replace the permission with one also granting puts
+	putPerm := &authpb.Permission{PermType: authpb.WRITE, Key: []byte("k1"), RangeEnd: []byte("k3"), Ops: []authpb.Permission_Operation{authpb.PUT, authpb.LEASE}}
+	if _, err := rootc.RoleGrantKeyPermission(context.TODO(), "role1", (*clientv3.Permission)(putPerm)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := userc.Move(context.TODO(), ids[0], ids[1]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestV3AuthLeaseWatch ensures watching the events of all leases requires the
+// root role, unlike watching given leases.
+func TestV3AuthLeaseWatch(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	api := integration.ToGRPC(clus.Client(0))
+	authSetupUsers(t, api.Auth, []user{{name: "user1", password: "user1-123", role: "role1", key: "k1", end: "k3"}})
+	authSetupRoot(t, api.Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	if _, err := userc.WatchEvents(ctx); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	resp, err := userc.Grant(context.TODO(), 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = userc.WatchEvents(ctx, clientv3.WithLeaseIDs(resp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rootc.WatchEvents(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func authSetupUsers(t *testing.T, auth pb.AuthClient, users []user) {
 	for _, user := range users {
 		if _, err := auth.UserAdd(context.TODO(), &pb.AuthUserAddRequest{Name: user.name, Password: user.password, Options: &authpb.UserAddOptions{NoPassword: false}}); err != nil {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"reflect"
 	"testing"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/tests/v3/framework/integration"
 
 	"google.golang.org/grpc/codes"
@@ -315,6 +317,171 @@ func TestV3LeaseKeepAlive(t *testing.T) {
 
 // TestV3LeaseCheckpoint ensures a lease checkpoint results in a remaining TTL being persisted
 // across leader elections.
+// TestV3LeaseKeepAliveBatch ensures a follower forwards batched keepalives to
+// the leader and reports leases that do not exist with a zero TTL.
+func TestV3LeaseKeepAliveBatch(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lead := clus.WaitLeader(t)
+	lc := integration.ToGRPC(clus.Client((lead + 1) % 3)).Lease
+
+	var ids []int64
+	for i := 0; i < 2; i++ {
+		lresp, err := lc.LeaseGrant(context.TODO(), &pb.LeaseGrantRequest{TTL: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, lresp.ID)
+	}
+	ids = append(ids, ids[0]+ids[1])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lac, err := lc.LeaseKeepAliveBatch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lac.CloseSend()
+
+	if err = lac.Send(&pb.LeaseKeepAliveBatchRequest{IDs: ids}); err != nil {
+		t.Fatal(err)
+	}
+	lresp, err := lac.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lresp.IDs, ids) {
+		t.Fatalf("expected lease IDs %v, got %v", ids, lresp.IDs)
+	}
+	if want := []int64{30, 30, 0}; !reflect.DeepEqual(lresp.TTLs, want) {
+		t.Fatalf("expected TTLs %v, got %v", want, lresp.TTLs)
+	}
+}
+
+// TestV3LeaseUpdate ensures the TTL of a lease can be changed and that
+// updating a missing lease fails.
+func TestV3LeaseUpdate(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lc := integration.ToGRPC(clus.RandClient()).Lease
+	lresp, err := lc.LeaseGrant(context.TODO(), &pb.LeaseGrantRequest{TTL: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uresp, err := lc.LeaseUpdate(context.TODO(), &pb.LeaseUpdateRequest{ID: lresp.ID, TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uresp.ID != lresp.ID || uresp.TTL != 300 {
+		t.Fatalf("expected lease %x with TTL 300, got lease %x with TTL %d", lresp.ID, uresp.ID, uresp.TTL)
+	}
+
+	tresp, err := integration.ToGRPC(clus.RandClient()).Lease.LeaseTimeToLive(context.TODO(), &pb.LeaseTimeToLiveRequest{ID: lresp.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tresp.GrantedTTL != 300 || tresp.TTL <= 30 {
+		t.Fatalf("expected granted TTL 300 and remaining TTL above 30, got %d and %d", tresp.GrantedTTL, tresp.TTL)
+	}
+
+	_, err = lc.LeaseUpdate(context.TODO(), &pb.LeaseUpdateRequest{ID: lresp.ID + 1, TTL: 300})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCLeaseNotFound) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCLeaseNotFound)
+	}
+}
+
+// TestV3LeaseMove ensures the keys of a lease are moved to another lease
+// in place, so that they outlive the source lease and are deleted with the
+// target, without a new revision or watch events.
+func TestV3LeaseMove(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lc := integration.ToGRPC(clus.RandClient()).Lease
+	kvc := integration.ToGRPC(clus.RandClient()).KV
+
+	var ids []int64
+	for i := 0; i < 2; i++ {
+		lresp, err := lc.LeaseGrant(context.TODO(), &pb.LeaseGrantRequest{TTL: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, lresp.ID)
+	}
+	keys := []string{"foo", "bar"}
+	for _, k := range keys {
+		if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte(k), Value: []byte("v-" + k), Lease: ids[0]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wctx, wcancel := context.WithCancel(context.Background())
+	defer wcancel()
+	wch := clus.RandClient().Watch(wctx, "", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+	if wresp := <-wch; !wresp.Created {
+		t.Fatalf("expected watch created, got %+v", wresp)
+	}
+
+	mresp, err := lc.LeaseMove(context.TODO(), &pb.LeaseMoveRequest{ID: ids[0], TargetID: ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mresp.Moved != int64(len(keys)) {
+		t.Fatalf("expected %d keys moved, got %d", len(keys), mresp.Moved)
+	}
+
+	// the first event seen is the next put
+	presp, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("sentinel")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presp.Header.Revision != mresp.Header.Revision+1 {
+		t.Fatalf("expected the move to keep revision %d, got put at %d", presp.Header.Revision-1, mresp.Header.Revision)
+	}
+	wresp := <-wch
+	if len(wresp.Events) != 1 || string(wresp.Events[0].Kv.Key) != "sentinel" {
+		t.Fatalf("expected only the sentinel put event, got %+v", wresp.Events)
+	}
+
+	if _, err = lc.LeaseRevoke(context.TODO(), &pb.LeaseRevokeRequest{ID: ids[0]}); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		rresp, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte(k)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rresp.Kvs) != 1 || string(rresp.Kvs[0].Value) != "v-"+k || rresp.Kvs[0].Lease != ids[1] || rresp.Kvs[0].Version != 1 {
+			t.Fatalf("expected key %q with value %q at version 1 on lease %x, got %+v", k, "v-"+k, ids[1], rresp.Kvs)
+		}
+	}
+
+	_, err = lc.LeaseMove(context.TODO(), &pb.LeaseMoveRequest{ID: ids[0], TargetID: ids[1]})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCLeaseNotFound) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCLeaseNotFound)
+	}
+
+	if _, err = lc.LeaseRevoke(context.TODO(), &pb.LeaseRevokeRequest{ID: ids[1]}); err != nil {
+		t.Fatal(err)
+	}
+	rresp, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("bar"), RangeEnd: []byte("fop")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rresp.Kvs) != 0 {
+		t.Fatalf("target lease revoked but keys remain: %+v", rresp.Kvs)
+	}
+}
+
 func TestV3LeaseCheckpoint(t *testing.T) {
 	tcs := []struct {
 		name                  string
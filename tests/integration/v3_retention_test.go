@@ -0,0 +1,180 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestV3Retention ensures the keys under a retention rule keep their own
+// history through a compaction of the keyspace, the same on every member.
+func TestV3Retention(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	mc := integration.ToGRPC(clus.RandClient()).Maintenance
+	kvc := integration.ToGRPC(clus.RandClient()).KV
+
+	rules := []*pb.RetentionRule{
+		{Prefix: []byte("/config/"), Revisions: 100},
+		{Prefix: []byte("/hb/"), Revisions: 1},
+	}
+	for _, rule := range rules {
+		if _, err := mc.RetentionPut(context.TODO(), &pb.RetentionPutRequest{Rule: rule}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := mc.RetentionPut(context.TODO(), &pb.RetentionPutRequest{Rule: &pb.RetentionRule{Prefix: []byte("/x/")}})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCInvalidRetentionRule) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCInvalidRetentionRule)
+	}
+
+	// revisions 2 to 7
+	var rev int64
+	for _, k := range []string{"/config/a", "/hb/a", "/other/a", "/config/a", "/hb/a", "/other/a"} {
+		resp, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte(k), Value: []byte("v")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rev = resp.Header.Revision
+	}
+	if _, err = kvc.Compact(context.TODO(), &pb.CompactionRequest{Revision: rev - 1, Physical: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		rev  int64
+		werr error
+	}{
+		{"/config/a", 2, nil},
+		{"/other/a", 4, rpctypes.ErrGRPCCompacted},
+		{"/other/a", 6, nil},
+		{"/hb/a", 5, rpctypes.ErrGRPCCompacted},
+		{"/hb/a", 6, nil},
+	}
+	for _, m := range clus.Members {
+		for i, tt := range tests {
+			_, err := integration.ToGRPC(m.Client).KV.Range(context.TODO(), &pb.RangeRequest{Key: []byte(tt.key), Revision: tt.rev})
+			if !eqErrGRPC(err, tt.werr) {
+				t.Fatalf("member %s #%d: err = %v, want %v", m.Name, i, err, tt.werr)
+			}
+		}
+	}
+
+	lresp, err := mc.RetentionList(context.TODO(), &pb.RetentionListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lresp.Rules) != 2 || lresp.Rules[0].CompactRevision != 0 || lresp.Rules[1].CompactRevision != 6 {
+		t.Fatalf("unexpected rules %+v", lresp.Rules)
+	}
+
+	// the history of the keys under a deleted rule is compacted at once
+	if _, err = mc.RetentionDelete(context.TODO(), &pb.RetentionDeleteRequest{Prefix: []byte("/config/")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("/config/a"), Revision: 2})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCCompacted) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCCompacted)
+	}
+	_, err = mc.RetentionDelete(context.TODO(), &pb.RetentionDeleteRequest{Prefix: []byte("/config/")})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCRetentionRuleNotFound) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCRetentionRuleNotFound)
+	}
+}
+
+// TestV3RetentionDurationRestart ensures the keys under a retention rule of a
+// duration are compacted at the revision recorded by a compaction at least
+// the duration ago, after the members restart.
+func TestV3RetentionDurationRestart(t *testing.T) {
+	integration.BeforeTest(t)
+	if integration.ThroughProxy {
+		t.Skip("the keys put through the proxy are namespaced, out of the retention rule")
+	}
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	newClient := func(m *integration.Member) *clientv3.Client {
+		cli, err := integration.NewClient(t, clientv3.Config{Endpoints: []string{m.GRPCURL()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+	cli := newClient(clus.Members[0])
+	defer cli.Close()
+	mc, kvc := integration.ToGRPC(cli).Maintenance, integration.ToGRPC(cli).KV
+
+	rule := &pb.RetentionRule{Prefix: []byte("/hb/"), DurationSeconds: 1}
+	if _, err := mc.RetentionPut(context.TODO(), &pb.RetentionPutRequest{Rule: rule}); err != nil {
+		t.Fatal(err)
+	}
+	put := func() int64 {
+		resp, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("/hb/a"), Value: []byte("v")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Header.Revision
+	}
+
+	// the first compaction records revision 3
+	put()
+	markRev := put()
+	if _, err := kvc.Compact(context.TODO(), &pb.CompactionRequest{Revision: markRev - 1, Physical: true}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	put()
+	rev := put()
+
+	for _, m := range clus.Members {
+		m.Stop(t)
+	}
+	for _, m := range clus.Members {
+		if err := m.Restart(t); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clus.WaitMembersForLeader(t, clus.Members)
+
+	for i, m := range clus.Members {
+		cli := newClient(m)
+		defer cli.Close()
+		kvc := integration.ToGRPC(cli).KV
+		if i == 0 {
+			if _, err := kvc.Compact(context.TODO(), &pb.CompactionRequest{Revision: rev - 1, Physical: true}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("/hb/a"), Revision: markRev - 1})
+		if !eqErrGRPC(err, rpctypes.ErrGRPCCompacted) {
+			t.Fatalf("member %s: err = %v, want %v", m.Name, err, rpctypes.ErrGRPCCompacted)
+		}
+		if _, err = kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("/hb/a"), Revision: markRev}); err != nil {
+			t.Fatalf("member %s: %v", m.Name, err)
+		}
+	}
+}
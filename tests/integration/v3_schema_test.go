@@ -0,0 +1,129 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// TestV3ValueSchema ensures puts and txns writing values that do not match
+// the schema of their key prefix are rejected with the reason, and that the
+// schemas survive a member restart.
+func TestV3ValueSchema(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	mc := integration.ToGRPC(clus.Client(0)).Maintenance
+	kvc := integration.ToGRPC(clus.Client(0)).KV
+
+	_, err := mc.SchemaPut(context.TODO(), &pb.SchemaPutRequest{Schema: &pb.ValueSchema{
+		Prefix: []byte("/config/"), Definition: []byte(`{"type": "object", "required": ["replicas"]`),
+	}})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCInvalidValueSchema) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCInvalidValueSchema)
+	}
+	vs := &pb.ValueSchema{
+		Prefix:     []byte("/config/"),
+		Definition: []byte(`{"type": "object", "properties": {"replicas": {"type": "integer", "minimum": 1}}, "required": ["replicas"]}`),
+	}
+	if _, err = mc.SchemaPut(context.TODO(), &pb.SchemaPutRequest{Schema: vs}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("/config/a"), Value: []byte(`{"replicas": 3}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("/other"), Value: []byte(`not json`)}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("/config/a"), Value: []byte(`{"replicas": 0}`)})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCValueSchemaViolation) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCValueSchemaViolation)
+	}
+	st, _ := status.FromError(err)
+	var violation *errdetails.BadRequest_FieldViolation
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok && len(br.FieldViolations) == 1 {
+			violation = br.FieldViolations[0]
+		}
+	}
+	if violation == nil || violation.Field != "/config/a" || violation.Description == "" {
+		t.Fatalf("expected the violation of /config/a in the error details, got %v", st.Details())
+	}
+
+	// a violation in a nested txn rejects the whole txn
+	txn := &pb.TxnRequest{Success: []*pb.RequestOp{
+		{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("/other"), Value: []byte("v")}}},
+		{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("/config/b"), Value: []byte(`{}`)}}},
+		}}}},
+	}}
+	_, err = kvc.Txn(context.TODO(), txn)
+	if !eqErrGRPC(err, rpctypes.ErrGRPCValueSchemaViolation) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCValueSchemaViolation)
+	}
+	resp, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("/other")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Kvs[0].Value) != "not json" {
+		t.Fatalf("rejected txn wrote %q", resp.Kvs[0].Value)
+	}
+
+	clus.Members[1].Stop(t)
+	if err = clus.Members[1].Restart(t); err != nil {
+		t.Fatal(err)
+	}
+	integration.WaitClientV3(t, clus.Members[1].Client)
+	mc1 := integration.ToGRPC(clus.Members[1].Client).Maintenance
+	gresp, err := mc1.SchemaGet(context.TODO(), &pb.SchemaGetRequest{Prefix: []byte("/config/")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gresp.Schema.Definition) != string(vs.Definition) {
+		t.Fatalf("schema = %q, want %q", gresp.Schema.Definition, vs.Definition)
+	}
+	_, err = integration.ToGRPC(clus.Members[1].Client).KV.Put(context.TODO(), &pb.PutRequest{Key: []byte("/config/c"), Value: []byte(`[]`)})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCValueSchemaViolation) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCValueSchemaViolation)
+	}
+
+	if _, err = mc.SchemaDelete(context.TODO(), &pb.SchemaDeleteRequest{Prefix: []byte("/config/")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = mc.SchemaDelete(context.TODO(), &pb.SchemaDeleteRequest{Prefix: []byte("/config/")})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCValueSchemaNotFound) {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrGRPCValueSchemaNotFound)
+	}
+	lresp, err := mc.SchemaList(context.TODO(), &pb.SchemaListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lresp.Schemas) != 0 {
+		t.Fatalf("expected no schemas, got %v", lresp.Schemas)
+	}
+	if _, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("/config/a"), Value: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+}
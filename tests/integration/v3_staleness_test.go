@@ -0,0 +1,112 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestV3RangeBoundedStaleness ensures serializable ranges with a min revision
+// or a max staleness are served by an up to date follower, and fail on a
+// partitioned one.
+func TestV3RangeBoundedStaleness(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lead := clus.WaitLeader(t)
+	follower := (lead + 1) % 3
+	var others []*integration.Member
+	for i, m := range clus.Members {
+		if i != follower {
+			others = append(others, m)
+		}
+	}
+
+	ctx := context.TODO()
+	presp, err := clus.Client(lead).Put(ctx, "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gresp, err := clus.Client(follower).Get(ctx, "foo", clientv3.WithMinRevision(presp.Header.Revision))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 1 || string(gresp.Kvs[0].Value) != "bar" {
+		t.Fatalf("expected foo=bar at revision %d, got %+v", presp.Header.Revision, gresp.Kvs)
+	}
+	if _, err = clus.Client(follower).Get(ctx, "foo", clientv3.WithMaxStaleness(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = clus.Client(lead).Get(ctx, "foo", clientv3.WithMaxStaleness(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	clus.Members[follower].InjectPartition(t, others...)
+	time.Sleep(time.Second)
+
+	kvc := integration.ToGRPC(clus.Client(follower)).KV
+	_, err = kvc.Range(ctx, &pb.RangeRequest{Key: []byte("foo"), Serializable: true, MaxStalenessMs: 500})
+	if err == nil || rpctypes.ErrorDesc(err) != rpctypes.ErrorDesc(rpctypes.ErrGRPCStaleRead) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrGRPCStaleRead, err)
+	}
+	// without bound, the partitioned member still serves serializable ranges
+	if _, err = kvc.Range(ctx, &pb.RangeRequest{Key: []byte("foo"), Serializable: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	presp, err = clus.Client(lead).Put(ctx, "foo", "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	_, err = kvc.Range(tctx, &pb.RangeRequest{Key: []byte("foo"), Serializable: true, MinRevision: presp.Header.Revision})
+	cancel()
+	if err == nil || rpctypes.ErrorDesc(err) != context.DeadlineExceeded.Error() {
+		t.Fatalf("expected the range to wait for revision %d until the deadline, got %v", presp.Header.Revision, err)
+	}
+
+	clus.Members[follower].RecoverPartition(t, others...)
+
+	tctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	gresp, err = clus.Client(follower).Get(tctx, "foo", clientv3.WithMinRevision(presp.Header.Revision))
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 1 || string(gresp.Kvs[0].Value) != "baz" {
+		t.Fatalf("expected foo=baz at revision %d, got %+v", presp.Header.Revision, gresp.Kvs)
+	}
+	tctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	_, err = clus.Client(follower).Get(tctx, "foo", clientv3.WithMaxStaleness(time.Second))
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the leader of an idle cluster only sends heartbeats, which do not bound the
+	// staleness: the follower confirms it is up to date with the leader instead
+	time.Sleep(1500 * time.Millisecond)
+	if _, err = kvc.Range(ctx, &pb.RangeRequest{Key: []byte("foo"), Serializable: true, MaxStalenessMs: 1000}); err != nil {
+		t.Fatal(err)
+	}
+}
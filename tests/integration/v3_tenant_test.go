@@ -0,0 +1,192 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestV3Tenant tests that the requests of the users bound to tenants are confined to their prefix.
+func TestV3Tenant(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+	rootc, err := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rootc.Close()
+
+	ctx := context.TODO()
+	for _, tenant := range []struct{ user, prefix string }{{"alice", "/tenants/a/"}, {"bob", "/tenants/b/"}} {
+		if _, err = rootc.UserAddWithOptions(ctx, tenant.user, "123", &clientv3.UserAddOptions{Prefix: []byte(tenant.prefix)}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = rootc.RoleAdd(ctx, tenant.user); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = rootc.RoleGrantPermission(ctx, tenant.user, tenant.prefix, clientv3.GetPrefixRangeEnd(tenant.prefix), clientv3.PermissionType(clientv3.PermReadWrite)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = rootc.UserGrantRole(ctx, tenant.user, tenant.user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if resp, uerr := rootc.UserGet(ctx, "alice"); uerr != nil || string(resp.Prefix) != "/tenants/a/" {
+		t.Fatalf("unexpected user get response %v (%v)", resp, uerr)
+	}
+	if _, err = rootc.Put(ctx, "/tenants/b/secret", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	alice, err := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "alice", Password: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer alice.Close()
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wch := alice.Watch(wctx, "", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+	if wresp := <-wch; !wresp.Created {
+		t.Fatalf("expected the watch to be created, got %+v", wresp)
+	}
+
+	if _, err = alice.Put(ctx, "x", "a"); err != nil {
+		t.Fatal(err)
+	}
+	// the keys of the other tenants are out of reach
+	if _, err = rootc.Put(ctx, "/tenants/b/y", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alice.Txn(ctx).Then(clientv3.OpPut("y", "a", clientv3.WithPrevKV())).Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for len(keys) < 2 {
+		select {
+		case wresp := <-wch:
+			for _, ev := range wresp.Events {
+				keys = append(keys, string(ev.Kv.Key))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the events, got %v", keys)
+		}
+	}
+	if !reflect.DeepEqual(keys, []string{"x", "y"}) {
+		t.Fatalf("expected events of keys [x y], got %v", keys)
+	}
+
+	gresp, err := alice.Get(ctx, "", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 2 || string(gresp.Kvs[0].Key) != "x" || string(gresp.Kvs[1].Key) != "y" {
+		t.Fatalf("expected the keys [x y] of the tenant, got %v", gresp.Kvs)
+	}
+	if gresp, err = rootc.Get(ctx, "/tenants/a/x"); err != nil || len(gresp.Kvs) != 1 {
+		t.Fatalf("expected the key to be written under the prefix, got %v (%v)", gresp, err)
+	}
+
+	if _, err = alice.Compact(ctx, gresp.Header.Revision); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// the leases of the keys of the other tenants cannot be revoked
+	lresp, err := rootc.Grant(ctx, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rootc.Put(ctx, "/tenants/b/l", "b", clientv3.WithLease(lresp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alice.Revoke(ctx, lresp.ID); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if lresp, err = alice.Grant(ctx, 90); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alice.Put(ctx, "l", "a", clientv3.WithLease(lresp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	ttl, err := alice.TimeToLive(ctx, lresp.ID, clientv3.WithAttachedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ttl.Keys) != 1 || string(ttl.Keys[0]) != "l" {
+		t.Fatalf("expected the attached keys [l], got %q", ttl.Keys)
+	}
+
+	// the leases of the other tenants without keys are out of reach too
+	other, err := rootc.Grant(ctx, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alice.Revoke(ctx, other.ID); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = alice.Put(ctx, "o", "a", clientv3.WithLease(other.ID)); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = alice.KeepAliveOnce(ctx, other.ID); err != rpctypes.ErrLeaseNotFound {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrLeaseNotFound, err)
+	}
+	if ttl, err = alice.TimeToLive(ctx, other.ID); err != nil || ttl.TTL != -1 {
+		t.Fatalf("expected the lease not to be found, got %v (%v)", ttl, err)
+	}
+	leases, err := alice.Leases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases.Leases) != 1 || leases.Leases[0].ID != lresp.ID {
+		t.Fatalf("expected the leases [%x] of the tenant, got %v", lresp.ID, leases.Leases)
+	}
+
+	// the tenants do not nest
+	if _, err = rootc.UserAddWithOptions(ctx, "carol", "123", &clientv3.UserAddOptions{Prefix: []byte("/tenants/a/c/")}); err != rpctypes.ErrInvalidAuthMgmt {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrInvalidAuthMgmt, err)
+	}
+
+	if _, err = alice.TenantStatus(ctx); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	sresp, err := rootc.TenantStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sresp.Tenants) != 2 {
+		t.Fatalf("expected 2 tenants, got %v", sresp.Tenants)
+	}
+	a, b := sresp.Tenants[0], sresp.Tenants[1]
+	if string(a.Prefix) != "/tenants/a/" || !reflect.DeepEqual(a.Users, []string{"alice"}) || a.KeyCount != 3 {
+		t.Errorf("unexpected status of tenant a: %v", a)
+	}
+	if wantSize := int64(len("/tenants/a/x") + len("/tenants/a/y") + len("/tenants/a/l") + 3); a.SizeBytes != wantSize {
+		t.Errorf("expected size %d of tenant a, got %d", wantSize, a.SizeBytes)
+	}
+	if string(b.Prefix) != "/tenants/b/" || b.KeyCount != 3 {
+		t.Errorf("unexpected status of tenant b: %v", b)
+	}
+}
@@ -74,3 +74,109 @@ func TestV3LockLockWaiter(t *testing.T) {
 	case <-lockc:
 	}
 }
+
+// TestV3LockMaxHolders tests that up to max_holders clients hold a lock at
+// the same time and further clients wait.
+func TestV3LockMaxHolders(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	var leases []int64
+	for i := 0; i < 3; i++ {
+		lresp, err := integration.ToGRPC(clus.RandClient()).Lease.LeaseGrant(context.TODO(), &pb.LeaseGrantRequest{TTL: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leases = append(leases, lresp.ID)
+	}
+
+	lc := integration.ToGRPC(clus.Client(0)).Lock
+	l1, err := lc.Lock(context.TODO(), &lockpb.LockRequest{Name: []byte("foo"), Lease: leases[0], MaxHolders: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = lc.Lock(context.TODO(), &lockpb.LockRequest{Name: []byte("foo"), Lease: leases[1], MaxHolders: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	lockc := make(chan struct{})
+	go func() {
+		if _, lerr := lc.Lock(context.TODO(), &lockpb.LockRequest{Name: []byte("foo"), Lease: leases[2], MaxHolders: 2}); lerr != nil {
+			t.Error(lerr)
+		}
+		close(lockc)
+	}()
+
+	select {
+	case <-time.After(200 * time.Millisecond):
+	case <-lockc:
+		t.Fatalf("locked with more than max_holders holders")
+	}
+
+	if _, uerr := lc.Unlock(context.TODO(), &lockpb.UnlockRequest{Key: l1.Key}); uerr != nil {
+		t.Fatal(uerr)
+	}
+
+	select {
+	case <-time.After(200 * time.Millisecond):
+		t.Fatalf("waiter did not lock after unlock")
+	case <-lockc:
+	}
+}
+
+// TestV3LockShared tests that shared holders exclude exclusive holders
+// but not each other.
+func TestV3LockShared(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	var leases []int64
+	for i := 0; i < 3; i++ {
+		lresp, err := integration.ToGRPC(clus.RandClient()).Lease.LeaseGrant(context.TODO(), &pb.LeaseGrantRequest{TTL: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leases = append(leases, lresp.ID)
+	}
+
+	lc := integration.ToGRPC(clus.Client(0)).Lock
+	var keys [][]byte
+	for i := 0; i < 2; i++ {
+		l, err := lc.Lock(context.TODO(), &lockpb.LockRequest{Name: []byte("foo"), Lease: leases[i], Shared: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, l.Key)
+	}
+
+	lockc := make(chan struct{})
+	go func() {
+		if _, lerr := lc.Lock(context.TODO(), &lockpb.LockRequest{Name: []byte("foo"), Lease: leases[2]}); lerr != nil {
+			t.Error(lerr)
+		}
+		close(lockc)
+	}()
+
+	for _, k := range keys {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-lockc:
+			t.Fatalf("exclusive lock acquired while shared holders exist")
+		}
+		if _, uerr := lc.Unlock(context.TODO(), &lockpb.UnlockRequest{Key: k}); uerr != nil {
+			t.Fatal(uerr)
+		}
+	}
+
+	select {
+	case <-time.After(200 * time.Millisecond):
+		t.Fatalf("waiter did not lock after shared holders unlocked")
+	case <-lockc:
+	}
+
+	if _, err := lc.Lock(context.TODO(), &lockpb.LockRequest{Name: []byte("bar"), Lease: leases[0], Shared: true, MaxHolders: 2}); err == nil {
+		t.Fatal("expected error combining shared and max_holders")
+	}
+}
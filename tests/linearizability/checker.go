@@ -0,0 +1,246 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Operation is an operation of a client, which took effect at some point
+// between its call and its return. An operation with an unknown outcome
+// returns at math.MaxInt64.
+type Operation struct {
+	ClientID int
+	Input    interface{}
+	Call     int64
+	Output   interface{}
+	Return   int64
+}
+
+// Model is the sequential specification histories are checked against.
+type Model struct {
+	// Init returns the initial state.
+	Init func() interface{}
+	// Step reports whether the operation of the input and output is legal
+	// in the state, and returns the state after it. It must not modify the
+	// given state.
+	Step func(state, input, output interface{}) (bool, interface{})
+	// Equal reports whether two states are equal.
+	Equal func(a, b interface{}) bool
+}
+
+// CheckResult is the result of a linearizability check.
+type CheckResult int
+
+const (
+	// Ok means the history is linearizable.
+	Ok CheckResult = iota
+	// Illegal means the history is not linearizable.
+	Illegal
+	// Unknown means the check timed out.
+	Unknown
+)
+
+func (r CheckResult) String() string {
+	switch r {
+	case Ok:
+		return "Ok"
+	case Illegal:
+		return "Illegal"
+	default:
+		return "Unknown"
+	}
+}
+
+// CheckOperations checks whether the history is linearizable with the model,
+// with the algorithm of Wing & Gong as improved by Lowe: operations are
+// linearized in order of call as long as the model accepts them, and
+// backtracked otherwise, skipping the (linearized operations, state) pairs
+// already explored. It gives up after the timeout, unless it is zero.
+//
+// It also returns the longest sequence of operations linearized, which ends
+// before the first operation that could not be linearized when Illegal.
+func CheckOperations(model Model, history []Operation, timeout time.Duration) (CheckResult, []Operation) {
+	head := makeEntries(history)
+	start := time.Now()
+
+	type call struct {
+		entry *entry
+		state interface{}
+	}
+	var (
+		calls      []call
+		longest    []int
+		state      = model.Init()
+		linearized = newBitset(len(history))
+		cache      = make(map[uint64][]cacheEntry)
+		n          = 0
+	)
+	entry := head.next
+	for head.next != nil {
+		if n++; timeout > 0 && n%1000 == 0 && time.Since(start) > timeout {
+			return Unknown, operations(history, longest)
+		}
+		if entry.match != nil {
+			ok, newState := model.Step(state, entry.op.Input, entry.op.Output)
+			if ok {
+				newLinearized := linearized.clone().set(entry.id)
+				if cacheAdd(cache, model, newLinearized, newState) {
+					calls = append(calls, call{entry: entry, state: state})
+					state = newState
+					linearized.set(entry.id)
+					entry.lift()
+					if len(calls) > len(longest) {
+						longest = longest[:0]
+						for _, c := range calls {
+							longest = append(longest, c.entry.id)
+						}
+					}
+					entry = head.next
+					continue
+				}
+			}
+			entry = entry.next
+			continue
+		}
+		// the return of an operation not linearized yet is reached
+		if len(calls) == 0 {
+			return Illegal, operations(history, longest)
+		}
+		top := calls[len(calls)-1]
+		calls = calls[:len(calls)-1]
+		entry, state = top.entry, top.state
+		linearized.clear(entry.id)
+		entry.unlift()
+		entry = entry.next
+	}
+	return Ok, operations(history, longest)
+}
+
+func operations(history []Operation, ids []int) []Operation {
+	ops := make([]Operation, len(ids))
+	for i, id := range ids {
+		ops[i] = history[id]
+	}
+	return ops
+}
+
+// entry is the call or the return of an operation, in a doubly linked list
+// ordered by time.
+type entry struct {
+	id         int
+	op         Operation
+	time       int64
+	isCall     bool
+	match      *entry // the return of a call
+	prev, next *entry
+}
+
+func makeEntries(history []Operation) *entry {
+	var es []*entry
+	for i, op := range history {
+		ret := &entry{id: i, op: op, time: op.Return}
+		es = append(es, &entry{id: i, op: op, time: op.Call, isCall: true, match: ret}, ret)
+	}
+	// calls come first at the same time, which makes operations touching
+	// each other concurrent
+	sort.SliceStable(es, func(i, j int) bool {
+		if es[i].time != es[j].time {
+			return es[i].time < es[j].time
+		}
+		return es[i].isCall && !es[j].isCall
+	})
+	head := &entry{id: -1, time: math.MinInt64}
+	prev := head
+	for _, e := range es {
+		prev.next, e.prev = e, prev
+		prev = e
+	}
+	return head
+}
+
+// lift removes the call and its return from the list.
+func (e *entry) lift() {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	m := e.match
+	m.prev.next = m.next
+	if m.next != nil {
+		m.next.prev = m.prev
+	}
+}
+
+// unlift puts back the call and its return lifted last.
+func (e *entry) unlift() {
+	m := e.match
+	m.prev.next = m
+	if m.next != nil {
+		m.next.prev = m
+	}
+	e.prev.next = e
+	e.next.prev = e
+}
+
+type cacheEntry struct {
+	linearized bitset
+	state      interface{}
+}
+
+// cacheAdd adds the pair to the cache, and reports whether it was missing.
+func cacheAdd(cache map[uint64][]cacheEntry, model Model, linearized bitset, state interface{}) bool {
+	h := linearized.hash()
+	for _, ce := range cache[h] {
+		if ce.linearized.equals(linearized) && model.Equal(ce.state, state) {
+			return false
+		}
+	}
+	cache[h] = append(cache[h], cacheEntry{linearized: linearized, state: state})
+	return true
+}
+
+type bitset []uint64
+
+func newBitset(n int) bitset { return make(bitset, (n+63)/64) }
+
+func (b bitset) clone() bitset { return append(bitset(nil), b...) }
+
+func (b bitset) set(i int) bitset {
+	b[i/64] |= 1 << uint(i%64)
+	return b
+}
+
+func (b bitset) clear(i int) bitset {
+	b[i/64] &^= 1 << uint(i%64)
+	return b
+}
+
+func (b bitset) equals(o bitset) bool {
+	for i := range b {
+		if b[i] != o[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b bitset) hash() uint64 {
+	h := uint64(len(b))
+	for _, v := range b {
+		h = h*31 + v
+	}
+	return h
+}
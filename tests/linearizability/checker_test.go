@@ -0,0 +1,179 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"math"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+func op(client int, call, ret int64, req EtcdRequest, resp EtcdResponse) Operation {
+	return Operation{ClientID: client, Input: req, Call: call, Output: resp, Return: ret}
+}
+
+func TestCheckOperations(t *testing.T) {
+	put := func(key, value string) EtcdRequest { return EtcdRequest{Op: Put, Key: key, Value: value} }
+	get := func(key string) EtcdRequest { return EtcdRequest{Op: Get, Key: key} }
+	unknown := EtcdResponse{Unknown: true}
+
+	tests := []struct {
+		name    string
+		history []Operation
+		want    CheckResult
+	}{
+		{
+			name: "sequential",
+			history: []Operation{
+				op(0, 0, 1, put("a", "1"), EtcdResponse{Revision: 2}),
+				op(0, 2, 3, get("a"), EtcdResponse{Revision: 2, Value: "1", Found: true}),
+			},
+			want: Ok,
+		},
+		{
+			name: "concurrent read sees either value",
+			history: []Operation{
+				op(0, 0, 10, put("a", "1"), EtcdResponse{Revision: 2}),
+				op(1, 1, 2, get("a"), EtcdResponse{Revision: 1}),
+				op(2, 3, 4, get("a"), EtcdResponse{Revision: 2, Value: "1", Found: true}),
+			},
+			want: Ok,
+		},
+		{
+			name: "stale read",
+			history: []Operation{
+				op(0, 0, 1, put("a", "1"), EtcdResponse{Revision: 2}),
+				op(1, 2, 3, get("a"), EtcdResponse{Revision: 1}),
+			},
+			want: Illegal,
+		},
+		{
+			name: "read goes back in time",
+			history: []Operation{
+				op(0, 0, 10, put("a", "1"), EtcdResponse{Revision: 2}),
+				op(1, 1, 2, get("a"), EtcdResponse{Revision: 2, Value: "1", Found: true}),
+				op(1, 3, 4, get("a"), EtcdResponse{Revision: 1}),
+			},
+			want: Illegal,
+		},
+		{
+			name: "concurrent writes in either order",
+			history: []Operation{
+				op(0, 0, 10, put("a", "1"), EtcdResponse{Revision: 3}),
+				op(1, 0, 10, put("b", "2"), EtcdResponse{Revision: 2}),
+			},
+			want: Ok,
+		},
+		{
+			name: "revisions of sequential writes out of order",
+			history: []Operation{
+				op(0, 0, 1, put("a", "1"), EtcdResponse{Revision: 3}),
+				op(1, 2, 3, put("b", "2"), EtcdResponse{Revision: 2}),
+			},
+			want: Illegal,
+		},
+		{
+			name: "unknown write observed later",
+			history: []Operation{
+				op(0, 0, math.MaxInt64, put("a", "1"), unknown),
+				op(1, 5, 6, get("a"), EtcdResponse{Revision: 2, Value: "1", Found: true}),
+			},
+			want: Ok,
+		},
+		{
+			name: "unknown write never observed",
+			history: []Operation{
+				op(0, 0, math.MaxInt64, put("a", "1"), unknown),
+				op(1, 5, 6, get("a"), EtcdResponse{Revision: 1}),
+				op(1, 7, 8, put("a", "2"), EtcdResponse{Revision: 2}),
+			},
+			want: Ok,
+		},
+		{
+			name: "lost write",
+			history: []Operation{
+				op(0, 0, 1, put("a", "1"), EtcdResponse{Revision: 2}),
+				op(1, 2, 3, put("a", "2"), EtcdResponse{Revision: 3}),
+				op(1, 4, 5, get("a"), EtcdResponse{Revision: 3, Value: "1", Found: true}),
+			},
+			want: Illegal,
+		},
+		{
+			name: "txn succeeds once",
+			history: []Operation{
+				op(0, 0, 10, EtcdRequest{Op: Txn, Key: "a", Value: "1"}, EtcdResponse{Revision: 2, Succeeded: true}),
+				op(1, 0, 10, EtcdRequest{Op: Txn, Key: "a", Value: "2"}, EtcdResponse{Revision: 3, Succeeded: true}),
+			},
+			want: Illegal,
+		},
+		{
+			name: "revoke deletes the keys of the lease",
+			history: []Operation{
+				op(0, 0, 1, EtcdRequest{Op: LeaseGrant}, EtcdResponse{Revision: 1, LeaseID: 7}),
+				op(0, 2, 3, EtcdRequest{Op: PutWithLease, Key: "a", Value: "1", LeaseID: 7}, EtcdResponse{Revision: 2}),
+				op(0, 4, 5, EtcdRequest{Op: LeaseRevoke, LeaseID: 7}, EtcdResponse{Revision: 3}),
+				op(0, 6, 7, get("a"), EtcdResponse{Revision: 3}),
+				op(0, 8, 9, EtcdRequest{Op: LeaseRevoke, LeaseID: 7}, EtcdResponse{Err: rpctypes.ErrLeaseNotFound}),
+			},
+			want: Ok,
+		},
+		{
+			name: "put with a revoked lease succeeds",
+			history: []Operation{
+				op(0, 0, 1, EtcdRequest{Op: LeaseGrant}, EtcdResponse{Revision: 1, LeaseID: 7}),
+				op(0, 2, 3, EtcdRequest{Op: LeaseRevoke, LeaseID: 7}, EtcdResponse{Revision: 1}),
+				op(0, 4, 5, EtcdRequest{Op: PutWithLease, Key: "a", Value: "1", LeaseID: 7}, EtcdResponse{Revision: 2}),
+			},
+			want: Illegal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := CheckOperations(Etcd, tt.history, 0); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWatchEvents(t *testing.T) {
+	put := func(rev int64, key, value string) WatchEvent {
+		return WatchEvent{Revision: rev, Key: key, Value: value}
+	}
+	history := []Operation{
+		op(0, 0, 1, EtcdRequest{Op: Put, Key: "a", Value: "1"}, EtcdResponse{Revision: 2}),
+		op(0, 2, 3, EtcdRequest{Op: Put, Key: "b", Value: "2"}, EtcdResponse{Revision: 3}),
+	}
+	tests := []struct {
+		name     string
+		watchers [][]WatchEvent
+		wantErr  bool
+	}{
+		{"valid", [][]WatchEvent{{put(2, "a", "1"), put(3, "b", "2")}, {put(2, "a", "1")}}, false},
+		{"gap", [][]WatchEvent{{put(2, "a", "1"), put(4, "c", "3")}}, true},
+		{"duplicate", [][]WatchEvent{{put(2, "a", "1"), put(2, "a", "1"), put(3, "b", "2")}}, true},
+		{"unordered", [][]WatchEvent{{put(2, "a", "1"), put(3, "b", "2"), put(2, "c", "3")}}, true},
+		{"diverging watchers", [][]WatchEvent{{put(2, "a", "1"), put(3, "b", "2")}, {put(2, "a", "1"), put(3, "b", "x")}}, true},
+		{"missing write", [][]WatchEvent{{put(2, "a", "1"), put(3, "c", "2")}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWatchEvents(tt.watchers, history); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
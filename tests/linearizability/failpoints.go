@@ -0,0 +1,160 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// faultDuration is how long a fault lasts before it is recovered from.
+const faultDuration = 300 * time.Millisecond
+
+// Failpoint injects a fault into a cluster and recovers from it.
+type Failpoint interface {
+	Name() string
+	// Available reports whether the failpoint applies to the cluster.
+	Available(clus *integration.Cluster) bool
+	Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error
+}
+
+var (
+	// KillFailpoint stops a random member and restarts it.
+	KillFailpoint Failpoint = killFailpoint{}
+	// PartitionLeaderFailpoint cuts the leader off the other members.
+	PartitionLeaderFailpoint Failpoint = partitionLeaderFailpoint{}
+	// BlackholeFailpoint drops the client traffic of a random member, which
+	// needs the cluster to use bridges.
+	BlackholeFailpoint Failpoint = blackholeFailpoint{}
+	// PauseFailpoint stops a random member from sending and receiving raft
+	// messages.
+	PauseFailpoint Failpoint = pauseFailpoint{}
+	// DefragFailpoint defragments the backend of a random member.
+	DefragFailpoint Failpoint = defragFailpoint{}
+	// RandomFailpoint triggers one of the other failpoints available.
+	RandomFailpoint Failpoint = randomFailpoint{[]Failpoint{
+		KillFailpoint, PartitionLeaderFailpoint, BlackholeFailpoint, PauseFailpoint, DefragFailpoint,
+	}}
+)
+
+type killFailpoint struct{}
+
+func (killFailpoint) Name() string                             { return "Kill" }
+func (killFailpoint) Available(clus *integration.Cluster) bool { return true }
+
+func (killFailpoint) Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error {
+	m := clus.Members[rand.Intn(len(clus.Members))]
+	m.Stop(t)
+	sleep(ctx, faultDuration)
+	if err := m.Restart(t); err != nil {
+		return err
+	}
+	clus.WaitLeader(t)
+	return nil
+}
+
+type partitionLeaderFailpoint struct{}
+
+func (partitionLeaderFailpoint) Name() string { return "PartitionLeader" }
+func (partitionLeaderFailpoint) Available(clus *integration.Cluster) bool {
+	return len(clus.Members) > 1
+}
+
+func (partitionLeaderFailpoint) Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error {
+	lead := clus.WaitLeader(t)
+	var others []*integration.Member
+	for i, m := range clus.Members {
+		if i != lead {
+			others = append(others, m)
+		}
+	}
+	clus.Members[lead].InjectPartition(t, others...)
+	sleep(ctx, faultDuration)
+	clus.Members[lead].RecoverPartition(t, others...)
+	clus.WaitLeader(t)
+	return nil
+}
+
+type blackholeFailpoint struct{}
+
+func (blackholeFailpoint) Name() string { return "Blackhole" }
+func (blackholeFailpoint) Available(clus *integration.Cluster) bool {
+	return clus.Cfg.UseBridge
+}
+
+func (blackholeFailpoint) Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error {
+	m := clus.Members[rand.Intn(len(clus.Members))]
+	m.Bridge().Blackhole()
+	sleep(ctx, faultDuration)
+	m.Bridge().Unblackhole()
+	return nil
+}
+
+type pauseFailpoint struct{}
+
+func (pauseFailpoint) Name() string { return "Pause" }
+func (pauseFailpoint) Available(clus *integration.Cluster) bool {
+	return len(clus.Members) > 1
+}
+
+func (pauseFailpoint) Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error {
+	m := clus.Members[rand.Intn(len(clus.Members))]
+	m.Pause()
+	sleep(ctx, faultDuration)
+	m.Resume()
+	clus.WaitLeader(t)
+	return nil
+}
+
+type defragFailpoint struct{}
+
+func (defragFailpoint) Name() string                             { return "Defrag" }
+func (defragFailpoint) Available(clus *integration.Cluster) bool { return true }
+
+func (defragFailpoint) Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error {
+	i := rand.Intn(len(clus.Members))
+	_, err := clus.Client(i).Defragment(ctx, clus.Members[i].GRPCURL())
+	return err
+}
+
+type randomFailpoint struct {
+	failpoints []Failpoint
+}
+
+func (randomFailpoint) Name() string                             { return "Random" }
+func (randomFailpoint) Available(clus *integration.Cluster) bool { return true }
+
+func (f randomFailpoint) Trigger(ctx context.Context, t *testing.T, clus *integration.Cluster) error {
+	var available []Failpoint
+	for _, fp := range f.failpoints {
+		if fp.Available(clus) {
+			available = append(available, fp)
+		}
+	}
+	fp := available[rand.Intn(len(available))]
+	t.Logf("triggering failpoint %s", fp.Name())
+	return fp.Trigger(ctx, t, clus)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
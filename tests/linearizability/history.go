@@ -0,0 +1,167 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// recordingClient records the history of the operations of a client. The
+// call and return times are relative to a base time shared by all clients.
+type recordingClient struct {
+	id       int
+	client   *clientv3.Client
+	baseTime time.Time
+	history  []Operation
+}
+
+func newRecordingClient(id int, client *clientv3.Client, baseTime time.Time) *recordingClient {
+	return &recordingClient{id: id, client: client, baseTime: baseTime}
+}
+
+func (c *recordingClient) now() int64 { return int64(time.Since(c.baseTime)) }
+
+func (c *recordingClient) Get(ctx context.Context, key string) error {
+	call := c.now()
+	resp, err := c.client.Get(ctx, key)
+	ret := c.now()
+	if err != nil {
+		// a failed read does not constrain the history
+		return err
+	}
+	out := EtcdResponse{Revision: resp.Header.Revision}
+	if len(resp.Kvs) == 1 {
+		out.Value, out.Found = string(resp.Kvs[0].Value), true
+	}
+	c.append(EtcdRequest{Op: Get, Key: key}, call, out, ret)
+	return nil
+}
+
+func (c *recordingClient) Put(ctx context.Context, key, value string) error {
+	call := c.now()
+	resp, err := c.client.Put(ctx, key, value)
+	ret := c.now()
+	var out EtcdResponse
+	if err == nil {
+		out.Revision = resp.Header.Revision
+	}
+	c.appendWrite(EtcdRequest{Op: Put, Key: key, Value: value}, call, out, ret, err)
+	return err
+}
+
+func (c *recordingClient) PutWithLease(ctx context.Context, key, value string, leaseID int64) error {
+	call := c.now()
+	resp, err := c.client.Put(ctx, key, value, clientv3.WithLease(clientv3.LeaseID(leaseID)))
+	ret := c.now()
+	var out EtcdResponse
+	if err == nil {
+		out.Revision = resp.Header.Revision
+	}
+	c.appendWrite(EtcdRequest{Op: PutWithLease, Key: key, Value: value, LeaseID: leaseID}, call, out, ret, err)
+	return err
+}
+
+func (c *recordingClient) Delete(ctx context.Context, key string) error {
+	call := c.now()
+	resp, err := c.client.Delete(ctx, key)
+	ret := c.now()
+	var out EtcdResponse
+	if err == nil {
+		out.Revision, out.Deleted = resp.Header.Revision, resp.Deleted
+	}
+	c.appendWrite(EtcdRequest{Op: Delete, Key: key}, call, out, ret, err)
+	return err
+}
+
+func (c *recordingClient) Txn(ctx context.Context, key, expectValue, value string) error {
+	cmp := clientv3.Compare(clientv3.Value(key), "=", expectValue)
+	if expectValue == "" {
+		cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+	}
+	call := c.now()
+	resp, err := c.client.Txn(ctx).If(cmp).Then(clientv3.OpPut(key, value)).Commit()
+	ret := c.now()
+	var out EtcdResponse
+	if err == nil {
+		out.Revision, out.Succeeded = resp.Header.Revision, resp.Succeeded
+	}
+	c.appendWrite(EtcdRequest{Op: Txn, Key: key, ExpectValue: expectValue, Value: value}, call, out, ret, err)
+	return err
+}
+
+// LeaseGrant grants a lease, returning its ID. A grant of an unknown outcome
+// is not recorded, as its lease is used by no other operation.
+func (c *recordingClient) LeaseGrant(ctx context.Context, ttl int64) (int64, error) {
+	call := c.now()
+	resp, err := c.client.Grant(ctx, ttl)
+	ret := c.now()
+	if err != nil {
+		return 0, err
+	}
+	c.append(EtcdRequest{Op: LeaseGrant}, call, EtcdResponse{Revision: resp.ResponseHeader.Revision, LeaseID: int64(resp.ID)}, ret)
+	return int64(resp.ID), nil
+}
+
+func (c *recordingClient) LeaseRevoke(ctx context.Context, leaseID int64) error {
+	call := c.now()
+	resp, err := c.client.Revoke(ctx, clientv3.LeaseID(leaseID))
+	ret := c.now()
+	var out EtcdResponse
+	if err == nil {
+		out.Revision = resp.Header.Revision
+	}
+	c.appendWrite(EtcdRequest{Op: LeaseRevoke, LeaseID: leaseID}, call, out, ret, err)
+	return err
+}
+
+// appendWrite records a write, which took effect or not when it failed
+// with a definite error, and may or may not have taken effect otherwise.
+func (c *recordingClient) appendWrite(req EtcdRequest, call int64, out EtcdResponse, ret int64, err error) {
+	if err != nil {
+		if isDefiniteError(err) {
+			out.Err = err
+		} else {
+			out.Unknown, ret = true, math.MaxInt64
+		}
+	}
+	c.append(req, call, out, ret)
+}
+
+func (c *recordingClient) append(req EtcdRequest, call int64, out EtcdResponse, ret int64) {
+	c.history = append(c.history, Operation{ClientID: c.id, Input: req, Call: call, Output: out, Return: ret})
+}
+
+// isDefiniteError reports whether the request failing with the error did not
+// take effect.
+func isDefiniteError(err error) bool {
+	return errors.Is(err, rpctypes.ErrLeaseNotFound)
+}
+
+// mergeHistories merges the histories of clients, ordered by call.
+func mergeHistories(clients []*recordingClient) []Operation {
+	var ops []Operation
+	for _, c := range clients {
+		ops = append(ops, c.history...)
+	}
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].Call < ops[j].Call })
+	return ops
+}
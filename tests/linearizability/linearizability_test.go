@@ -0,0 +1,183 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	trafficDuration   = 2 * time.Second
+	trafficQPS        = 200
+	clientCount       = 8
+	failpointTriggers = 2
+	watchTimeout      = 10 * time.Second
+	checkTimeout      = time.Minute
+)
+
+func TestLinearizability(t *testing.T) {
+	tcs := []struct {
+		name      string
+		failpoint Failpoint
+		config    integration.ClusterConfig
+	}{
+		{name: "ClusterOfSize1", config: integration.ClusterConfig{Size: 1}},
+		{name: "ClusterOfSize3", config: integration.ClusterConfig{Size: 3}},
+		{name: "Kill", failpoint: KillFailpoint, config: integration.ClusterConfig{Size: 3}},
+		{name: "PartitionLeader", failpoint: PartitionLeaderFailpoint, config: integration.ClusterConfig{Size: 3}},
+		{name: "Blackhole", failpoint: BlackholeFailpoint, config: integration.ClusterConfig{Size: 3, UseBridge: true}},
+		{name: "Pause", failpoint: PauseFailpoint, config: integration.ClusterConfig{Size: 3}},
+		{name: "Defrag", failpoint: DefragFailpoint, config: integration.ClusterConfig{Size: 3}},
+		{name: "Random", failpoint: RandomFailpoint, config: integration.ClusterConfig{Size: 3, UseBridge: true}},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			integration.BeforeTest(t)
+			clus := integration.NewCluster(t, &tc.config)
+			defer clus.Terminate(t)
+			testLinearizability(t, clus, tc.failpoint, DefaultTraffic)
+		})
+	}
+}
+
+func testLinearizability(t *testing.T, clus *integration.Cluster, failpoint Failpoint, traffic Traffic) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// watch every member from the start
+	var (
+		stops    []chan int64
+		watchers = make([][]WatchEvent, len(clus.Members))
+		watchWG  sync.WaitGroup
+	)
+	watchCtx, watchCancel := context.WithTimeout(ctx, trafficDuration+watchTimeout)
+	defer watchCancel()
+	for i, m := range clus.Members {
+		c, stop := newClient(t, m.GRPCURL()), make(chan int64, 1)
+		stops = append(stops, stop)
+		watchWG.Add(1)
+		go func(i int) {
+			defer watchWG.Done()
+			events, err := collectWatchEvents(watchCtx, c, stop)
+			if err != nil {
+				t.Errorf("watcher %d: %v", i, err)
+			}
+			watchers[i] = events
+		}(i)
+	}
+
+	trafficCtx, trafficCancel := context.WithTimeout(ctx, trafficDuration)
+	defer trafficCancel()
+	baseTime := time.Now()
+	limiter := rate.NewLimiter(trafficQPS, 1)
+	var (
+		clients   []*recordingClient
+		trafficWG sync.WaitGroup
+	)
+	for i := 0; i < clientCount; i++ {
+		rc := newRecordingClient(i, newClient(t, clus.Members[i%len(clus.Members)].GRPCURL()), baseTime)
+		clients = append(clients, rc)
+		trafficWG.Add(1)
+		go func() {
+			defer trafficWG.Done()
+			traffic.Run(trafficCtx, rc, limiter)
+		}()
+	}
+	if failpoint != nil {
+		for i := 0; i < failpointTriggers; i++ {
+			sleep(trafficCtx, trafficDuration/(failpointTriggers+1))
+			if trafficCtx.Err() != nil {
+				break
+			}
+			if err := failpoint.Trigger(trafficCtx, t, clus); err != nil {
+				t.Errorf("failed to trigger failpoint %s: %v", failpoint.Name(), err)
+			}
+		}
+	}
+	trafficWG.Wait()
+
+	// a last write outside of the history tells watchers where to stop
+	c := newClient(t, clus.Members[0].GRPCURL())
+	resp, err := c.Put(ctx, "done", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stop := range stops {
+		stop <- resp.Header.Revision
+	}
+	watchWG.Wait()
+
+	history := mergeHistories(clients)
+	t.Logf("recorded %d operations", len(history))
+	if err = validateWatchEvents(watchers, history); err != nil {
+		t.Error(err)
+	}
+	result, linearized := CheckOperations(Etcd, history, checkTimeout)
+	switch result {
+	case Illegal:
+		t.Errorf("history is not linearizable, linearized %d of %d operations", len(linearized), len(history))
+		logOperations(t, linearized, history)
+	case Unknown:
+		t.Logf("timed out checking the history of %d operations", len(history))
+	}
+}
+
+func newClient(t *testing.T, endpoint string) *clientv3.Client {
+	c, err := integration.NewClient(t, clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 5 * time.Second,
+		Logger:      zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// logOperations logs the end of the longest linearization found, and the
+// operations left.
+func logOperations(t *testing.T, linearized, history []Operation) {
+	const last = 20
+	t.Log("end of the longest linearization:")
+	start := 0
+	if len(linearized) > last {
+		start = len(linearized) - last
+	}
+	done := make(map[Operation]bool)
+	for i, op := range linearized {
+		done[op] = true
+		if i >= start {
+			t.Log("  " + describeOperation(op))
+		}
+	}
+	t.Log("operations left:")
+	n := 0
+	for _, op := range history {
+		if !done[op] && n < last {
+			t.Log("  " + describeOperation(op))
+			n++
+		}
+	}
+}
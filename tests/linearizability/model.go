@@ -0,0 +1,219 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type OperationType string
+
+const (
+	Get          OperationType = "get"
+	Put          OperationType = "put"
+	PutWithLease OperationType = "put-with-lease"
+	Delete       OperationType = "delete"
+	// Txn puts Value if the value of the key is ExpectValue, or the key is
+	// missing when ExpectValue is empty.
+	Txn         OperationType = "txn"
+	LeaseGrant  OperationType = "lease-grant"
+	LeaseRevoke OperationType = "lease-revoke"
+)
+
+// EtcdRequest is the input of an operation of the etcd model.
+type EtcdRequest struct {
+	Op          OperationType
+	Key         string
+	Value       string
+	ExpectValue string
+	LeaseID     int64
+}
+
+// EtcdResponse is the output of an operation of the etcd model.
+type EtcdResponse struct {
+	// Unknown means the outcome of the request is unknown, such as after a
+	// timeout: the request may or may not have taken effect.
+	Unknown bool
+	// Err is a definite failure of the request, which did not take effect.
+	Err error
+
+	Revision  int64
+	Value     string
+	Found     bool
+	Deleted   int64
+	Succeeded bool
+	LeaseID   int64
+}
+
+// EtcdState is the state of the etcd model.
+type EtcdState struct {
+	Revision  int64
+	KeyValues map[string]string
+	KeyLeases map[string]int64
+	Leases    map[int64]struct{}
+}
+
+func (s EtcdState) clone() EtcdState {
+	ns := EtcdState{
+		Revision:  s.Revision,
+		KeyValues: make(map[string]string, len(s.KeyValues)),
+		KeyLeases: make(map[string]int64, len(s.KeyLeases)),
+		Leases:    make(map[int64]struct{}, len(s.Leases)),
+	}
+	for k, v := range s.KeyValues {
+		ns.KeyValues[k] = v
+	}
+	for k, l := range s.KeyLeases {
+		ns.KeyLeases[k] = l
+	}
+	for l := range s.Leases {
+		ns.Leases[l] = struct{}{}
+	}
+	return ns
+}
+
+// Etcd is the model of the KV and lease API of etcd, starting from an empty
+// keyspace at revision 1. It checks the revision of every response, so that
+// the order of the writes is checked, and not only the values read.
+var Etcd = Model{
+	Init: func() interface{} {
+		return EtcdState{
+			Revision:  1,
+			KeyValues: make(map[string]string),
+			KeyLeases: make(map[string]int64),
+			Leases:    make(map[int64]struct{}),
+		}
+	},
+	Step: func(st, in, out interface{}) (bool, interface{}) {
+		return step(st.(EtcdState), in.(EtcdRequest), out.(EtcdResponse))
+	},
+	Equal: func(a, b interface{}) bool {
+		return reflect.DeepEqual(a, b)
+	},
+}
+
+func step(s EtcdState, req EtcdRequest, resp EtcdResponse) (bool, interface{}) {
+	if resp.Err != nil {
+		// a definite failure leaves the state as is
+		return isDefiniteFailure(s, req), s
+	}
+	ns, want := apply(s, req)
+	if resp.Unknown {
+		return true, ns
+	}
+	want.Unknown, want.Err = false, nil
+	if req.Op == LeaseGrant {
+		want.LeaseID = resp.LeaseID
+		ns.Leases[resp.LeaseID] = struct{}{}
+	}
+	return resp == want, ns
+}
+
+// isDefiniteFailure reports whether the request fails in the state.
+func isDefiniteFailure(s EtcdState, req EtcdRequest) bool {
+	switch req.Op {
+	case PutWithLease, LeaseRevoke:
+		_, ok := s.Leases[req.LeaseID]
+		return !ok
+	}
+	return false
+}
+
+// apply returns the state after the request and the expected response. The
+// state after a request failing in it is the same.
+func apply(s EtcdState, req EtcdRequest) (EtcdState, EtcdResponse) {
+	switch req.Op {
+	case Get:
+		v, ok := s.KeyValues[req.Key]
+		return s, EtcdResponse{Revision: s.Revision, Value: v, Found: ok}
+	case Put:
+		ns := s.clone()
+		ns.put(req.Key, req.Value, 0)
+		return ns, EtcdResponse{Revision: ns.Revision}
+	case PutWithLease:
+		if _, ok := s.Leases[req.LeaseID]; !ok {
+			return s, EtcdResponse{}
+		}
+		ns := s.clone()
+		ns.put(req.Key, req.Value, req.LeaseID)
+		return ns, EtcdResponse{Revision: ns.Revision}
+	case Delete:
+		if _, ok := s.KeyValues[req.Key]; !ok {
+			return s, EtcdResponse{Revision: s.Revision}
+		}
+		ns := s.clone()
+		ns.Revision++
+		delete(ns.KeyValues, req.Key)
+		delete(ns.KeyLeases, req.Key)
+		return ns, EtcdResponse{Revision: ns.Revision, Deleted: 1}
+	case Txn:
+		v, ok := s.KeyValues[req.Key]
+		if (req.ExpectValue == "" && ok) || (req.ExpectValue != "" && v != req.ExpectValue) {
+			return s, EtcdResponse{Revision: s.Revision}
+		}
+		ns := s.clone()
+		ns.put(req.Key, req.Value, 0)
+		return ns, EtcdResponse{Revision: ns.Revision, Succeeded: true}
+	case LeaseGrant:
+		return s.clone(), EtcdResponse{Revision: s.Revision}
+	case LeaseRevoke:
+		if _, ok := s.Leases[req.LeaseID]; !ok {
+			return s, EtcdResponse{}
+		}
+		ns := s.clone()
+		delete(ns.Leases, req.LeaseID)
+		deleted := false
+		for k, l := range ns.KeyLeases {
+			if l == req.LeaseID {
+				delete(ns.KeyValues, k)
+				delete(ns.KeyLeases, k)
+				deleted = true
+			}
+		}
+		// all the keys of the lease are deleted at the same revision
+		if deleted {
+			ns.Revision++
+		}
+		return ns, EtcdResponse{Revision: ns.Revision}
+	default:
+		panic(fmt.Sprintf("unknown operation %q", req.Op))
+	}
+}
+
+func (s *EtcdState) put(key, value string, leaseID int64) {
+	s.Revision++
+	s.KeyValues[key] = value
+	if leaseID == 0 {
+		delete(s.KeyLeases, key)
+	} else {
+		s.KeyLeases[key] = leaseID
+	}
+}
+
+// describeOperation returns a readable description of the operation.
+func describeOperation(op Operation) string {
+	req, resp := op.Input.(EtcdRequest), op.Output.(EtcdResponse)
+	var r string
+	switch {
+	case resp.Unknown:
+		r = "unknown"
+	case resp.Err != nil:
+		r = resp.Err.Error()
+	default:
+		r = fmt.Sprintf("%+v", resp)
+	}
+	return fmt.Sprintf("client %d [%d, %d]: %+v -> %s", op.ClientID, op.Call, op.Return, req, r)
+}
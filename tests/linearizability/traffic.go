@@ -0,0 +1,125 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// requestTimeout bounds every request, so that the operations of
+	// unknown outcome are not left running after the traffic stops.
+	requestTimeout = 500 * time.Millisecond
+	// leaseTTL is long enough for leases to never expire during a test.
+	leaseTTL = 600
+)
+
+// Traffic is a random mix of KV and lease requests on a small set of keys,
+// so that clients conflict with each other.
+type Traffic struct {
+	Keys []string
+	// Weights of the requests, by operation type.
+	Weights map[OperationType]int
+}
+
+// DefaultTraffic mostly reads and writes keys, and sometimes attaches them to
+// leases revoked later on.
+var DefaultTraffic = Traffic{
+	Keys: []string{"key0", "key1", "key2", "key3"},
+	Weights: map[OperationType]int{
+		Get:          40,
+		Put:          20,
+		Delete:       5,
+		Txn:          15,
+		PutWithLease: 8,
+		LeaseGrant:   6,
+		LeaseRevoke:  6,
+	},
+}
+
+// Run sends requests through the client until ctx is done, no faster than
+// the limiter. The values put are unique to the client.
+func (t Traffic) Run(ctx context.Context, c *recordingClient, limiter *rate.Limiter) {
+	rnd := rand.New(rand.NewSource(int64(c.id)))
+	var (
+		leases []int64
+		n      int
+		last   = make(map[string]string)
+	)
+	types := make([]OperationType, 0, len(t.Weights))
+	total := 0
+	for _, op := range []OperationType{Get, Put, Delete, Txn, PutWithLease, LeaseGrant, LeaseRevoke} {
+		if w := t.Weights[op]; w > 0 {
+			types = append(types, op)
+			total += w
+		}
+	}
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+		x := rnd.Intn(total)
+		var op OperationType
+		for _, op = range types {
+			if x -= t.Weights[op]; x < 0 {
+				break
+			}
+		}
+		key := t.Keys[rnd.Intn(len(t.Keys))]
+		n++
+		value := fmt.Sprintf("%d-%d", c.id, n)
+
+		reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		switch op {
+		case Get:
+			c.Get(reqCtx, key)
+		case Put:
+			c.Put(reqCtx, key, value)
+			last[key] = value
+		case Delete:
+			c.Delete(reqCtx, key)
+		case Txn:
+			// expects the last value put by the client, which others may
+			// have overwritten since
+			c.Txn(reqCtx, key, last[key], value)
+			last[key] = value
+		case PutWithLease:
+			if len(leases) == 0 {
+				break
+			}
+			c.PutWithLease(reqCtx, key, value, leases[rnd.Intn(len(leases))])
+		case LeaseGrant:
+			if id, err := c.LeaseGrant(reqCtx, leaseTTL); err == nil {
+				leases = append(leases, id)
+			}
+		case LeaseRevoke:
+			if len(leases) == 0 {
+				break
+			}
+			i := rnd.Intn(len(leases))
+			c.LeaseRevoke(reqCtx, leases[i])
+			leases = append(leases[:i], leases[i+1:]...)
+		}
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linearizability
+
+import (
+	"context"
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// WatchEvent is an event received by a watcher.
+type WatchEvent struct {
+	Revision int64
+	Key      string
+	Value    string
+	IsDelete bool
+}
+
+// collectWatchEvents watches the whole keyspace from revision 1, until it
+// received the events of the revision sent on stop, or ctx is done.
+func collectWatchEvents(ctx context.Context, c *clientv3.Client, stop <-chan int64) ([]WatchEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		events []WatchEvent
+		maxRev int64
+	)
+	wch := c.Watch(ctx, "", clientv3.WithPrefix(), clientv3.WithRev(1))
+	for {
+		select {
+		case resp, ok := <-wch:
+			if !ok {
+				return events, ctx.Err()
+			}
+			if err := resp.Err(); err != nil {
+				return events, err
+			}
+			for _, ev := range resp.Events {
+				events = append(events, WatchEvent{
+					Revision: ev.Kv.ModRevision,
+					Key:      string(ev.Kv.Key),
+					Value:    string(ev.Kv.Value),
+					IsDelete: ev.Type == mvccpb.DELETE,
+				})
+			}
+		case maxRev = <-stop:
+			stop = nil
+		}
+		if maxRev > 0 && len(events) > 0 && events[len(events)-1].Revision >= maxRev {
+			return events, nil
+		}
+	}
+}
+
+// validateWatchEvents checks the guarantees of watch: the events of every
+// watcher are ordered by revision, without gaps nor duplicates, all
+// watchers receive the same events, and every write of a known revision in
+// the history has its event.
+func validateWatchEvents(watchers [][]WatchEvent, history []Operation) error {
+	for i, events := range watchers {
+		if err := validateOrdered(events); err != nil {
+			return fmt.Errorf("watcher %d: %w", i, err)
+		}
+	}
+	for i := 1; i < len(watchers); i++ {
+		if err := validateSame(watchers[0], watchers[i]); err != nil {
+			return fmt.Errorf("watchers 0 and %d: %w", i, err)
+		}
+	}
+	if len(watchers) > 0 {
+		if err := validateWrites(watchers[0], history); err != nil {
+			return fmt.Errorf("watcher 0: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateOrdered checks that the events have increasing revisions, with
+// distinct keys at the same revision, and that no revision is skipped.
+func validateOrdered(events []WatchEvent) error {
+	keys := make(map[string]struct{})
+	for i, ev := range events {
+		if i == 0 {
+			// the first write of the cluster is at revision 2
+			if ev.Revision != 2 {
+				return fmt.Errorf("first event at revision %d, want 2", ev.Revision)
+			}
+			keys[ev.Key] = struct{}{}
+			continue
+		}
+		prev := events[i-1]
+		switch {
+		case ev.Revision == prev.Revision:
+			if _, ok := keys[ev.Key]; ok {
+				return fmt.Errorf("duplicated event of key %q at revision %d", ev.Key, ev.Revision)
+			}
+		case ev.Revision == prev.Revision+1:
+			keys = make(map[string]struct{})
+		case ev.Revision < prev.Revision:
+			return fmt.Errorf("event at revision %d after revision %d", ev.Revision, prev.Revision)
+		default:
+			return fmt.Errorf("gap between revisions %d and %d", prev.Revision, ev.Revision)
+		}
+		keys[ev.Key] = struct{}{}
+	}
+	return nil
+}
+
+// validateSame checks that the events of a watcher are a prefix of the ones
+// of the other, or the opposite.
+func validateSame(a, b []WatchEvent) error {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return fmt.Errorf("event %d differs: %+v != %+v", i, a[i], b[i])
+		}
+	}
+	return nil
+}
+
+// validateWrites checks that the writes of a known revision have their
+// events, when the events go up to the revision.
+func validateWrites(events []WatchEvent, history []Operation) error {
+	if len(events) == 0 {
+		return nil
+	}
+	byRev := make(map[int64][]WatchEvent)
+	for _, ev := range events {
+		byRev[ev.Revision] = append(byRev[ev.Revision], ev)
+	}
+	lastRev := events[len(events)-1].Revision
+	for _, op := range history {
+		req, resp := op.Input.(EtcdRequest), op.Output.(EtcdResponse)
+		if resp.Unknown || resp.Err != nil || resp.Revision > lastRev {
+			continue
+		}
+		var want *WatchEvent
+		switch {
+		case req.Op == Put || req.Op == PutWithLease || (req.Op == Txn && resp.Succeeded):
+			want = &WatchEvent{Revision: resp.Revision, Key: req.Key, Value: req.Value}
+		case req.Op == Delete && resp.Deleted > 0:
+			want = &WatchEvent{Revision: resp.Revision, Key: req.Key, IsDelete: true}
+		}
+		if want == nil {
+			continue
+		}
+		found := false
+		for _, ev := range byRev[want.Revision] {
+			if ev == *want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("missing event %+v of %s", *want, describeOperation(op))
+		}
+	}
+	return nil
+}
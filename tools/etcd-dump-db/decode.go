@@ -0,0 +1,99 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.uber.org/zap"
+)
+
+// schemaDecoders print the content of a bucket read through the storage
+// schema rather than its raw key-value pairs.
+var schemaDecoders = map[string]func(be backend.Backend){
+	"lease": decodeLeaseBucket,
+	"auth":  decodeAuthBuckets,
+	"meta":  decodeMetaBucket,
+}
+
+func decodeBucket(dbPath, bucket string) error {
+	dec, ok := schemaDecoders[bucket]
+	if !ok {
+		return fmt.Errorf("cannot decode bucket %q, must be one of lease, auth or meta", bucket)
+	}
+	be := backend.NewDefaultBackend(dbPath)
+	defer be.Close()
+	dec(be)
+	return nil
+}
+
+func decodeLeaseBucket(be backend.Backend) {
+	tx := be.ReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+	for _, l := range schema.MustUnsafeGetAllLeases(tx) {
+		fmt.Printf("lease ID=%016x, TTL=%ds, remaining TTL=%ds\n", l.ID, l.TTL, l.RemainingTTL)
+	}
+}
+
+// decodeAuthBuckets prints the auth status, users and roles. Passwords are
+// never printed.
+func decodeAuthBuckets(be backend.Backend) {
+	tx := schema.NewAuthBackend(zap.NewNop(), be).BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	fmt.Printf("authEnabled=%v\n", tx.UnsafeReadAuthEnabled())
+	fmt.Printf("authRevision=%d\n", tx.UnsafeReadAuthRevision())
+	for _, u := range tx.UnsafeGetAllUsers() {
+		fmt.Printf("user=%q, roles=%q, noPassword=%v\n", u.Name, u.Roles, u.Options != nil && u.Options.NoPassword)
+	}
+	for _, r := range tx.UnsafeGetAllRoles() {
+		if len(r.KeyPermission) == 0 {
+			fmt.Printf("role=%q\n", r.Name)
+		}
+		for _, perm := range r.KeyPermission {
+			fmt.Printf("role=%q, permission=%s, key=%q, range_end=%q\n", r.Name, perm.PermType, perm.Key, perm.RangeEnd)
+		}
+	}
+}
+
+func decodeMetaBucket(be backend.Backend) {
+	tx := be.ReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+	index, term := schema.UnsafeReadConsistentIndex(tx)
+	fmt.Printf("consistentIndex=%d\n", index)
+	fmt.Printf("term=%d\n", term)
+	if cs := schema.UnsafeConfStateFromBackend(zap.NewNop(), tx); cs != nil {
+		b, err := json.Marshal(cs)
+		if err != nil {
+			b = []byte(fmt.Sprintf("confstate err: %v", err))
+		}
+		fmt.Printf("confState=%s\n", b)
+	}
+	if v := schema.UnsafeReadStorageVersion(tx); v != nil {
+		fmt.Printf("storageVersion=%s\n", v)
+	}
+	if rev, found := mvcc.UnsafeReadScheduledCompact(tx); found {
+		fmt.Printf("scheduledCompactRev=%d\n", rev)
+	}
+	if rev, found := mvcc.UnsafeReadFinishedCompact(tx); found {
+		fmt.Printf("finishedCompactRev=%d\n", rev)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/lease/leasepb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.uber.org/zap"
+)
+
+func TestEtcdDumpDB(t *testing.T) {
+	// directory where the command is
+	binDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dumpDBBinary := path.Join(binDir + "/etcd-dump-db")
+	if !fileutil.Exist(dumpDBBinary) {
+		t.Skipf("%q does not exist", dumpDBBinary)
+	}
+
+	p := t.TempDir()
+	snapdir := snapDir(p)
+	err = os.MkdirAll(snapdir, 0744)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeDB(t, filepath.Join(snapdir, "db"))
+
+	argtests := []struct {
+		name         string
+		args         []string
+		fileExpected string
+	}{
+		{"list bucket", []string{"list-bucket", p}, "expectedoutput/listBucket.output"},
+		{"history", []string{"history", p, "foo"}, "expectedoutput/history.output"},
+		{"history prefix", []string{"history", "--prefix", p, "foo"}, "expectedoutput/historyPrefix.output"},
+		{"revision count", []string{"revision-count", p}, "expectedoutput/revisionCount.output"},
+		{"revision count prefix and limit", []string{"revision-count", "--prefix", "foo", "--limit", "1", p}, "expectedoutput/revisionCountPrefixLimit.output"},
+		{"decode lease bucket", []string{"decode-bucket", p, "lease"}, "expectedoutput/decodeLease.output"},
+		{"decode auth buckets", []string{"decode-bucket", p, "auth"}, "expectedoutput/decodeAuth.output"},
+		{"decode meta bucket", []string{"decode-bucket", p, "meta"}, "expectedoutput/decodeMeta.output"},
+	}
+
+	for _, argtest := range argtests {
+		t.Run(argtest.name, func(t *testing.T) {
+			cmd := exec.Command(dumpDBBinary, argtest.args...)
+			actual, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := os.ReadFile(path.Join(binDir, argtest.fileExpected))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(actual, expected) {
+				t.Errorf(`Got input of length %d, wanted input of length %d
+==== BEGIN RECEIVED FILE ====
+%s
+==== END RECEIVED FILE ====
+==== BEGIN EXPECTED FILE ====
+%s
+==== END EXPECTED FILE ====
+`, len(actual), len(expected), actual, expected)
+			}
+		})
+	}
+}
+
+// writeDB writes a db with the compacted history of a few keys, a lease, auth
+// users and roles, and the meta of a member.
+func writeDB(t *testing.T, dbPath string) {
+	lg := zap.NewNop()
+	be := backend.NewDefaultBackend(dbPath)
+	defer be.Close()
+
+	s := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo"), []byte("baz"), 0x1234)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("zoo"), []byte("bar"), lease.NoLease)
+	s.DeleteRange([]byte("foo1"), nil)
+	s.Put([]byte("foo1"), []byte("bar2"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar3"), lease.NoLease)
+	ch, err := s.Compact(traceutil.TODO(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	s.Close()
+
+	tx := be.BatchTx()
+	tx.Lock()
+	schema.UnsafeCreateLeaseBucket(tx)
+	schema.MustUnsafePutLease(tx, &leasepb.Lease{ID: 0x1234, TTL: 60, RemainingTTL: 30})
+	schema.UnsafeUpdateConsistentIndex(tx, 10, 2, false)
+	schema.MustUnsafeSaveConfStateToBackend(lg, tx, &raftpb.ConfState{Voters: []uint64{1, 2, 3}})
+	schema.UnsafeSetStorageVersion(tx, &semver.Version{Major: 3, Minor: 6})
+	tx.Unlock()
+
+	ab := schema.NewAuthBackend(lg, be)
+	ab.CreateAuthBuckets()
+	atx := ab.BatchTx()
+	atx.Lock()
+	atx.UnsafeSaveAuthEnabled(true)
+	atx.UnsafeSaveAuthRevision(4)
+	atx.UnsafePutUser(&authpb.User{Name: []byte("root"), Password: []byte("secret"), Roles: []string{"root"}})
+	atx.UnsafePutUser(&authpb.User{Name: []byte("reader"), Roles: []string{"reader"}, Options: &authpb.UserAddOptions{NoPassword: true}})
+	atx.UnsafePutRole(&authpb.Role{Name: []byte("root")})
+	atx.UnsafePutRole(&authpb.Role{
+		Name: []byte("reader"),
+		KeyPermission: []*authpb.Permission{
+			{PermType: authpb.READ, Key: []byte("foo"), RangeEnd: []byte("fop")},
+			{PermType: authpb.READWRITE, Key: []byte("zoo")},
+		},
+	})
+	atx.Unlock()
+	be.ForceCommit()
+}
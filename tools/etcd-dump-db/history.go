@@ -0,0 +1,134 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// revBytesLen is the length of a revision in the key bucket; keys of
+	// tombstones have an extra 't' mark.
+	revBytesLen   = 8 + 1 + 8
+	markTombstone = 't'
+)
+
+// keyRevision is a revision of a key stored in the key bucket.
+type keyRevision struct {
+	rev       revision
+	tombstone bool
+	kv        mvccpb.KeyValue
+}
+
+// iterateKeyRevisions calls f with every revision of the key bucket in
+// ascending revision order.
+func iterateKeyRevisions(dbPath string, f func(kr *keyRevision)) error {
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: flockTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open bolt DB %v", err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(schema.Key.Name())
+		if b == nil {
+			return fmt.Errorf("got nil bucket for %s", schema.Key.Name())
+		}
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			kr := keyRevision{
+				rev:       bytesToRev(k),
+				tombstone: len(k) == revBytesLen+1 && k[revBytesLen] == markTombstone,
+			}
+			if err := kr.kv.Unmarshal(v); err != nil {
+				return fmt.Errorf("failed to decode revision %+v: %v", kr.rev, err)
+			}
+			f(&kr)
+		}
+		return nil
+	})
+}
+
+// printKeyHistory prints every revision of key, or of the keys with the
+// prefix key, from the oldest to the newest.
+func printKeyHistory(dbPath string, key []byte, prefix bool) error {
+	return iterateKeyRevisions(dbPath, func(kr *keyRevision) {
+		if prefix && !bytes.HasPrefix(kr.kv.Key, key) || !prefix && !bytes.Equal(kr.kv.Key, key) {
+			return
+		}
+		if kr.tombstone {
+			fmt.Printf("rev=%+v, key=%q, deleted", kr.rev, kr.kv.Key)
+			// the tombstones of keys deleted by a lease expiry record the lease
+			if kr.kv.Lease != 0 {
+				fmt.Printf(", expired lease=%016x", kr.kv.Lease)
+			}
+			fmt.Println()
+			return
+		}
+		fmt.Printf("rev=%+v, key=%q, value=%q, created=%d, mod=%d, ver=%d", kr.rev, kr.kv.Key, kr.kv.Value, kr.kv.CreateRevision, kr.kv.ModRevision, kr.kv.Version)
+		if kr.kv.Lease != 0 {
+			fmt.Printf(", lease=%016x", kr.kv.Lease)
+		}
+		fmt.Println()
+	})
+}
+
+type keyRevisionCount struct {
+	key       string
+	revisions int
+	deletes   int
+}
+
+// countKeyRevisions counts the revisions, including deletions, of every key
+// with the given prefix. It returns the keys with the most revisions first.
+func countKeyRevisions(dbPath string, prefix []byte) ([]*keyRevisionCount, error) {
+	counts := make(map[string]*keyRevisionCount)
+	err := iterateKeyRevisions(dbPath, func(kr *keyRevision) {
+		if !bytes.HasPrefix(kr.kv.Key, prefix) {
+			return
+		}
+		c, ok := counts[string(kr.kv.Key)]
+		if !ok {
+			c = &keyRevisionCount{key: string(kr.kv.Key)}
+			counts[c.key] = c
+		}
+		c.revisions++
+		if kr.tombstone {
+			c.deletes++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]*keyRevisionCount, 0, len(counts))
+	for _, c := range counts {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].revisions != cs[j].revisions {
+			return cs[i].revisions > cs[j].revisions
+		}
+		return cs[i].key < cs[j].key
+	})
+	return cs, nil
+}
@@ -45,20 +45,45 @@ var (
 		Short: "hash computes the hash of db file.",
 		Run:   getHashCommandFunc,
 	}
+	historyCommand = &cobra.Command{
+		Use:   "history [data dir or db file path] [key]",
+		Short: "history lists all revisions of a key from the oldest to the newest.",
+		Run:   historyCommandFunc,
+	}
+	revisionCountCommand = &cobra.Command{
+		Use:   "revision-count [data dir or db file path]",
+		Short: "revision-count lists the number of revisions of each key, most revisions first.",
+		Run:   revisionCountCommandFunc,
+	}
+	decodeBucketCommand = &cobra.Command{
+		Use:   "decode-bucket [data dir or db file path] [lease|auth|meta]",
+		Short: "decode-bucket prints the content of the lease, auth or meta bucket.",
+		Run:   decodeBucketCommandFunc,
+	}
 )
 
 var flockTimeout time.Duration
 var iterateBucketLimit uint64
 var iterateBucketDecode bool
+var historyPrefix bool
+var revisionCountPrefix string
+var revisionCountLimit int
 
 func init() {
 	rootCommand.PersistentFlags().DurationVar(&flockTimeout, "timeout", 10*time.Second, "time to wait to obtain a file lock on db file, 0 to block indefinitely")
 	iterateBucketCommand.PersistentFlags().Uint64Var(&iterateBucketLimit, "limit", 0, "max number of key-value pairs to iterate (0< to iterate all)")
 	iterateBucketCommand.PersistentFlags().BoolVar(&iterateBucketDecode, "decode", false, "true to decode Protocol Buffer encoded data")
 
+	historyCommand.PersistentFlags().BoolVar(&historyPrefix, "prefix", false, "true to list the revisions of all keys with the given prefix")
+	revisionCountCommand.PersistentFlags().StringVar(&revisionCountPrefix, "prefix", "", "only count the revisions of the keys with the given prefix")
+	revisionCountCommand.PersistentFlags().IntVar(&revisionCountLimit, "limit", 0, "max number of keys to list (0 to list all)")
+
 	rootCommand.AddCommand(listBucketCommand)
 	rootCommand.AddCommand(iterateBucketCommand)
 	rootCommand.AddCommand(getHashCommand)
+	rootCommand.AddCommand(historyCommand)
+	rootCommand.AddCommand(revisionCountCommand)
+	rootCommand.AddCommand(decodeBucketCommand)
 }
 
 func main() {
@@ -125,3 +150,59 @@ func getHashCommandFunc(cmd *cobra.Command, args []string) {
 	}
 	fmt.Printf("db path: %s\nHash: %d\n", dp, hash)
 }
+
+func historyCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		log.Fatalf("Must provide 2 arguments (got %v)", args)
+	}
+	dp := args[0]
+	if !strings.HasSuffix(dp, "db") {
+		dp = filepath.Join(snapDir(dp), "db")
+	}
+	if !existFileOrDir(dp) {
+		log.Fatalf("%q does not exist", dp)
+	}
+	if err := printKeyHistory(dp, []byte(args[1]), historyPrefix); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func revisionCountCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		log.Fatalf("Must provide at least 1 argument (got %v)", args)
+	}
+	dp := args[0]
+	if !strings.HasSuffix(dp, "db") {
+		dp = filepath.Join(snapDir(dp), "db")
+	}
+	if !existFileOrDir(dp) {
+		log.Fatalf("%q does not exist", dp)
+	}
+
+	counts, err := countKeyRevisions(dp, []byte(revisionCountPrefix))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if revisionCountLimit > 0 && len(counts) > revisionCountLimit {
+		counts = counts[:revisionCountLimit]
+	}
+	for _, c := range counts {
+		fmt.Printf("key=%q, revisions=%d, deletes=%d\n", c.key, c.revisions, c.deletes)
+	}
+}
+
+func decodeBucketCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		log.Fatalf("Must provide 2 arguments (got %v)", args)
+	}
+	dp := args[0]
+	if !strings.HasSuffix(dp, "db") {
+		dp = filepath.Join(snapDir(dp), "db")
+	}
+	if !existFileOrDir(dp) {
+		log.Fatalf("%q does not exist", dp)
+	}
+	if err := decodeBucket(dp, args[1]); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -106,6 +106,15 @@ func TestEtcdDumpLogEntryType(t *testing.T) {
 		{"confchange and txn entry-type", []string{"-entry-type", "ConfigChange,IRRCompaction", p}, "expectedoutput/listConfigChangeIRRCompaction.output"},
 		{"decoder_correctoutputformat", []string{"-stream-decoder", decoder_correctoutputformat, p}, "expectedoutput/decoder_correctoutputformat.output"},
 		{"decoder_wrongoutputformat", []string{"-stream-decoder", decoder_wrongoutputformat, p}, "expectedoutput/decoder_wrongoutputformat.output"},
+		{"json output", []string{"-output", "json", "-entry-type", "ConfigChange,Request,IRRTxn", p}, "expectedoutput/listJSON.output"},
+		{"json output of auth requests", []string{"-output", "json", "-request-type", "authenticate,auth_user_add,auth_role_grant_permission", p}, "expectedoutput/listJSONAuth.output"},
+		{"json output with decoder", []string{"-output", "json", "-entry-type", "IRRTxn", "-stream-decoder", decoder_correctoutputformat, p}, "expectedoutput/listJSONDecoder.output"},
+		{"key prefix", []string{"-key-prefix", "foo", p}, "expectedoutput/listKeyPrefix.output"},
+		{"member id", []string{"-member-id", "2", p}, "expectedoutput/listMemberID.output"},
+		{"request type", []string{"-request-type", "put,lease_grant", p}, "expectedoutput/listRequestType.output"},
+		{"index and term range", []string{"-start-term", "3", "-end-term", "5", "-end-index", "10", p}, "expectedoutput/listIndexTermRange.output"},
+		{"summary", []string{"-summary", "-summary-depth", "1", p}, "expectedoutput/summary.output"},
+		{"json summary", []string{"-summary", "-output", "json", p}, "expectedoutput/summaryJSON.output"},
 	}
 
 	for _, argtest := range argtests {
@@ -134,6 +143,49 @@ func TestEtcdDumpLogEntryType(t *testing.T) {
 
 }
 
+func TestRequestOpHasPrefix(t *testing.T) {
+	tests := []struct {
+		key, end string
+		want     bool
+	}{
+		{"foo", "", true},
+		{"foo/bar", "", true},
+		{"fo", "", false},
+		{"a", "b", false},
+		{"a", "foo", false},
+		{"a", "foo0", true},
+		{"a", "\x00", true},
+		{"foo", "foo0", true},
+		{"fop", "\x00", false},
+	}
+	for _, tt := range tests {
+		op := requestOp{key: []byte(tt.key), end: []byte(tt.end)}
+		if got := op.hasPrefix([]byte("foo")); got != tt.want {
+			t.Errorf("[%q, %q) hasPrefix(foo) = %v, want %v", tt.key, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key   string
+		depth int
+		want  string
+	}{
+		{"/registry/pods/default/nginx", 2, "/registry/pods/"},
+		{"/registry/pods/default/nginx", 1, "/registry/"},
+		{"/registry/pods", 2, "/registry/pods"},
+		{"registry/pods/default", 2, "registry/pods/"},
+		{"foo", 1, "foo"},
+		{"foo", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := keyPrefix([]byte(tt.key), tt.depth); string(got) != tt.want {
+			t.Errorf("keyPrefix(%q, %d) = %q, want %q", tt.key, tt.depth, got, tt.want)
+		}
+	}
+}
+
 func appendConfigChangeEnts(ents *[]raftpb.Entry) {
 	configChangeData := []raftpb.ConfChange{
 		{ID: 1, Type: raftpb.ConfChangeAddNode, NodeID: 2, Context: []byte("")},
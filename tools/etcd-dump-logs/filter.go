@@ -0,0 +1,217 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// entrySelector selects entries by index, term, member, key prefix and
+// request type on top of the entry-type filters. Zero fields match any entry.
+type entrySelector struct {
+	endIndex  uint64
+	startTerm uint64
+	endTerm   uint64
+	memberID  types.ID
+	keyPrefix []byte
+	// requestTypes holds protobuf field names of InternalRaftRequest,
+	// such as "put" or "lease_grant".
+	requestTypes map[string]struct{}
+}
+
+func (s entrySelector) match(entry raftpb.Entry) bool {
+	if s.endIndex != 0 && entry.Index > s.endIndex {
+		return false
+	}
+	if entry.Term < s.startTerm || (s.endTerm != 0 && entry.Term > s.endTerm) {
+		return false
+	}
+	if s.memberID == 0 && len(s.keyPrefix) == 0 && len(s.requestTypes) == 0 {
+		return true
+	}
+
+	if entry.Type == raftpb.EntryConfChange {
+		var cc raftpb.ConfChange
+		if len(s.keyPrefix) != 0 || len(s.requestTypes) != 0 || cc.Unmarshal(entry.Data) != nil {
+			return false
+		}
+		return types.ID(cc.NodeID) == s.memberID
+	}
+	if entry.Type != raftpb.EntryNormal {
+		return false
+	}
+	var rr etcdserverpb.InternalRaftRequest
+	if rr.Unmarshal(entry.Data) == nil {
+		return s.matchInternalRaftRequest(&rr)
+	}
+	var r etcdserverpb.Request
+	if r.Unmarshal(entry.Data) == nil {
+		return s.matchRequest(&r)
+	}
+	return false
+}
+
+func (s entrySelector) matchInternalRaftRequest(rr *etcdserverpb.InternalRaftRequest) bool {
+	if len(s.requestTypes) != 0 {
+		if _, ok := s.requestTypes[requestType(rr)]; !ok {
+			return false
+		}
+	}
+	if s.memberID != 0 {
+		var id uint64
+		switch {
+		case rr.ClusterMemberAttrSet != nil:
+			id = rr.ClusterMemberAttrSet.Member_ID
+		case rr.Alarm != nil:
+			id = rr.Alarm.MemberID
+		}
+		if types.ID(id) != s.memberID {
+			return false
+		}
+	}
+	if len(s.keyPrefix) != 0 {
+		for _, op := range requestOps(rr) {
+			if op.hasPrefix(s.keyPrefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (s entrySelector) matchRequest(r *etcdserverpb.Request) bool {
+	// v2 requests have no InternalRaftRequest type
+	if len(s.requestTypes) != 0 {
+		return false
+	}
+	if s.memberID != 0 && !strings.HasPrefix(r.Path, "/0/members/"+s.memberID.String()+"/") {
+		return false
+	}
+	return strings.HasPrefix(r.Path, string(s.keyPrefix))
+}
+
+// requestType returns the protobuf field name of the request carried by an
+// InternalRaftRequest, such as "put" or "lease_grant".
+func requestType(rr *etcdserverpb.InternalRaftRequest) string {
+	v := reflect.ValueOf(rr).Elem()
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f, sf := v.Field(i), t.Field(i)
+		if f.Kind() != reflect.Ptr || f.IsNil() || sf.Name == "Header" {
+			continue
+		}
+		return protoName(sf)
+	}
+	return ""
+}
+
+// protoName returns the protobuf field name of a generated struct field, or
+// the empty string for fields without a protobuf tag.
+func protoName(sf reflect.StructField) string {
+	for _, part := range strings.Split(sf.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+	return ""
+}
+
+type requestOpType int
+
+const (
+	opRange requestOpType = iota
+	opPut
+	opDelete
+	opCompare
+)
+
+// requestOp is a key range accessed by a request.
+type requestOp struct {
+	typ requestOpType
+	key []byte
+	end []byte
+	// size is the number of bytes written by a put or delete.
+	size int
+}
+
+// requestOps returns the key ranges accessed by a KV request, including the
+// compares and operations of both branches of transactions.
+func requestOps(rr *etcdserverpb.InternalRaftRequest) []requestOp {
+	switch {
+	case rr.Range != nil:
+		return []requestOp{rangeOp(rr.Range)}
+	case rr.Put != nil:
+		return []requestOp{putOp(rr.Put)}
+	case rr.DeleteRange != nil:
+		return []requestOp{deleteOp(rr.DeleteRange)}
+	case rr.Txn != nil:
+		return txnOps(rr.Txn)
+	}
+	return nil
+}
+
+func rangeOp(r *etcdserverpb.RangeRequest) requestOp {
+	return requestOp{typ: opRange, key: r.Key, end: r.RangeEnd}
+}
+
+func putOp(r *etcdserverpb.PutRequest) requestOp {
+	return requestOp{typ: opPut, key: r.Key, size: len(r.Key) + len(r.Value)}
+}
+
+func deleteOp(r *etcdserverpb.DeleteRangeRequest) requestOp {
+	return requestOp{typ: opDelete, key: r.Key, end: r.RangeEnd, size: len(r.Key) + len(r.RangeEnd)}
+}
+
+func txnOps(txn *etcdserverpb.TxnRequest) []requestOp {
+	var ops []requestOp
+	for _, c := range txn.Compare {
+		ops = append(ops, requestOp{typ: opCompare, key: c.Key, end: c.RangeEnd})
+	}
+	for _, branch := range [][]*etcdserverpb.RequestOp{txn.Success, txn.Failure} {
+		for _, op := range branch {
+			switch {
+			case op.GetRequestRange() != nil:
+				ops = append(ops, rangeOp(op.GetRequestRange()))
+			case op.GetRequestPut() != nil:
+				ops = append(ops, putOp(op.GetRequestPut()))
+			case op.GetRequestDeleteRange() != nil:
+				ops = append(ops, deleteOp(op.GetRequestDeleteRange()))
+			case op.GetRequestTxn() != nil:
+				ops = append(ops, txnOps(op.GetRequestTxn())...)
+			}
+		}
+	}
+	return ops
+}
+
+// hasPrefix reports whether the key range of op overlaps the keys with the
+// given prefix.
+func (op requestOp) hasPrefix(prefix []byte) bool {
+	if bytes.HasPrefix(op.key, prefix) {
+		return true
+	}
+	if len(op.end) == 0 || bytes.Compare(op.key, prefix) > 0 {
+		return false
+	}
+	// op.key sorts before prefix, so [key, end) overlaps the prefix if it ends after it
+	return bytes.Equal(op.end, []byte{0}) || bytes.Compare(op.end, prefix) > 0
+}
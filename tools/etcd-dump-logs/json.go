@@ -0,0 +1,150 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// jsonEntry is the JSON representation of a WAL entry, printed one per line.
+type jsonEntry struct {
+	Term  uint64 `json:"term"`
+	Index uint64 `json:"index"`
+	// Type is one of ConfigChange, Request, InternalRaftRequest or UnknownNormal.
+	Type       string          `json:"type"`
+	ConfChange *jsonConfChange `json:"conf_change,omitempty"`
+	// Request is a decoded v2 request.
+	Request map[string]interface{} `json:"request,omitempty"`
+	// RequestType is the protobuf field name of the InternalRaftRequest request.
+	RequestType         string                 `json:"request_type,omitempty"`
+	InternalRaftRequest map[string]interface{} `json:"internal_raft_request,omitempty"`
+	DecoderStatus       string                 `json:"decoder_status,omitempty"`
+	DecodedData         string                 `json:"decoded_data,omitempty"`
+}
+
+type jsonConfChange struct {
+	Method string `json:"method"`
+	ID     string `json:"id"`
+}
+
+func newJSONEntry(entry raftpb.Entry, entrytype string) jsonEntry {
+	je := jsonEntry{Term: entry.Term, Index: entry.Index, Type: entrytype}
+	switch entrytype {
+	case "ConfigChange":
+		var cc raftpb.ConfChange
+		if err := cc.Unmarshal(entry.Data); err == nil {
+			je.ConfChange = &jsonConfChange{Method: cc.Type.String(), ID: types.ID(cc.NodeID).String()}
+		}
+	case "Request":
+		var r etcdserverpb.Request
+		if err := r.Unmarshal(entry.Data); err == nil {
+			je.Request = decodeMessage(reflect.ValueOf(&r))
+		}
+	case "InternalRaftRequest":
+		var rr etcdserverpb.InternalRaftRequest
+		if err := rr.Unmarshal(entry.Data); err == nil {
+			je.RequestType = requestType(&rr)
+			je.InternalRaftRequest = decodeMessage(reflect.ValueOf(&rr))
+		}
+	}
+	return je
+}
+
+// redactedFields are protobuf fields whose values are never printed.
+var redactedFields = map[string]struct{}{
+	"password":       {},
+	"hashedPassword": {},
+	"simple_token":   {},
+}
+
+// decodeMessage converts a protobuf message into a map keyed by protobuf field
+// names, leaving out unset fields. Oneof fields are inlined into the message.
+func decodeMessage(v reflect.Value) map[string]interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	m := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, sf := v.Field(i), t.Field(i)
+		if sf.PkgPath != "" || f.IsZero() {
+			continue
+		}
+		if f.Kind() == reflect.Interface {
+			for name, val := range decodeMessage(f.Elem()) {
+				m[name] = val
+			}
+			continue
+		}
+		name := protoName(sf)
+		if name == "" {
+			continue
+		}
+		if _, ok := redactedFields[name]; ok {
+			m[name] = "<value removed>"
+			continue
+		}
+		m[name] = decodeValue(f)
+	}
+	return m
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+func decodeValue(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == bytesType:
+		return jsonBytes(v.Bytes())
+	case v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct, v.Kind() == reflect.Struct:
+		return decodeMessage(v)
+	case v.Kind() == reflect.Ptr:
+		return decodeValue(v.Elem())
+	case v.Kind() == reflect.Slice:
+		vals := make([]interface{}, v.Len())
+		for i := range vals {
+			vals[i] = decodeValue(v.Index(i))
+		}
+		return vals
+	case v.Kind() == reflect.Int32:
+		// enums are printed by name
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+	return v.Interface()
+}
+
+// jsonBytes encodes keys and values as JSON strings when they are valid UTF-8,
+// and otherwise as {"base64": "..."} objects.
+type jsonBytes []byte
+
+func (b jsonBytes) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(struct {
+		Base64 []byte `json:"base64"`
+	}{b})
+}
@@ -41,6 +41,9 @@ import (
 
 const (
 	defaultEntryTypes string = "Normal,ConfigChange"
+
+	outputText = "text"
+	outputJSON = "json"
 )
 
 func main() {
@@ -54,6 +57,20 @@ IRRCompaction, IRRLeaseGrant, IRRLeaseRevoke, IRRLeaseCheckpoint`)
 	streamdecoder := flag.String("stream-decoder", "", `The name of an executable decoding tool, the executable must process
 hex encoded lines of binary input (from etcd-dump-logs)
 and output a hex encoded line of binary for each input line`)
+	output := flag.String("output", outputText, `The output format, one of "text" or "json". With "json", each entry
+is printed on its own line as a JSON object and no headers are printed`)
+	endIndex := flag.Uint64("end-index", 0, "If set, the last index to dump")
+	startTerm := flag.Uint64("start-term", 0, "If set, the first term to dump")
+	endTerm := flag.Uint64("end-term", 0, "If set, the last term to dump")
+	memberID := flag.String("member-id", "", `If set, only dumps the config changes of the member with the given hex ID
+and the requests about it, such as its attribute updates and alarms`)
+	keyPrefix := flag.String("key-prefix", "", `If set, only dumps the requests accessing keys with the given prefix,
+including the compares and operations of transactions`)
+	requestType := flag.String("request-type", "", `If set, only dumps the InternalRaftRequest entries of the given comma separated
+request types, named as in the InternalRaftRequest protobuf message, e.g. put,txn,lease_grant`)
+	summary := flag.Bool("summary", false, `Prints the number of puts and deletes and the written bytes per key prefix
+instead of the entries`)
+	summaryDepth := flag.Int("summary-depth", 2, "The number of key path segments grouped together by -summary")
 
 	flag.Parse()
 
@@ -65,6 +82,34 @@ and output a hex encoded line of binary for each input line`)
 	if *snapfile != "" && *index != 0 {
 		log.Fatal("start-snap and start-index flags cannot be used together.")
 	}
+	if *output != outputText && *output != outputJSON {
+		log.Fatalf("output must be %q or %q (got %q)", outputText, outputJSON, *output)
+	}
+
+	opts := dumpOptions{entrytype: *entrytype, streamdecoder: *streamdecoder, output: *output}
+	opts.selector = entrySelector{endIndex: *endIndex, startTerm: *startTerm, endTerm: *endTerm, keyPrefix: []byte(*keyPrefix)}
+	if *memberID != "" {
+		id, err := types.IDFromString(*memberID)
+		if err != nil {
+			log.Fatalf("Failed parsing member-id: %v", err)
+		}
+		opts.selector.memberID = id
+	}
+	if *requestType != "" {
+		opts.selector.requestTypes = make(map[string]struct{})
+		for _, rt := range strings.Split(*requestType, ",") {
+			opts.selector.requestTypes[rt] = struct{}{}
+		}
+	}
+	if *summary {
+		opts.summary = newWriteSummary(*summaryDepth)
+	}
+	// headers are only printed with the text output
+	printf := func(format string, a ...interface{}) {
+		if *output == outputText {
+			fmt.Printf(format, a...)
+		}
+	}
 
 	var (
 		walsnap  walpb.Snapshot
@@ -75,7 +120,7 @@ and output a hex encoded line of binary for each input line`)
 	isIndex := *index != 0
 
 	if isIndex {
-		fmt.Printf("Start dumping log entries from index %d.\n", *index)
+		printf("Start dumping log entries from index %d.\n", *index)
 		walsnap.Index = *index
 	} else {
 		if *snapfile == "" {
@@ -93,14 +138,14 @@ and output a hex encoded line of binary for each input line`)
 			if err != nil {
 				confstateJson = []byte(fmt.Sprintf("confstate err: %v", err))
 			}
-			fmt.Printf("Snapshot:\nterm=%d index=%d nodes=%s confstate=%s\n",
+			printf("Snapshot:\nterm=%d index=%d nodes=%s confstate=%s\n",
 				walsnap.Term, walsnap.Index, nodes, confstateJson)
 		case snap.ErrNoSnapshot:
-			fmt.Printf("Snapshot:\nempty\n")
+			printf("Snapshot:\nempty\n")
 		default:
 			log.Fatalf("Failed loading snapshot: %v", err)
 		}
-		fmt.Println("Start dumping log entries from snapshot.")
+		printf("Start dumping log entries from snapshot.\n")
 	}
 
 	w, err := wal.OpenForRead(zap.NewExample(), walDir(dataDir), walsnap)
@@ -114,19 +159,21 @@ and output a hex encoded line of binary for each input line`)
 	}
 	id, cid := parseWALMetadata(wmetadata)
 	vid := types.ID(state.Vote)
-	fmt.Printf("WAL metadata:\nnodeID=%s clusterID=%s term=%d commitIndex=%d vote=%s\n",
+	printf("WAL metadata:\nnodeID=%s clusterID=%s term=%d commitIndex=%d vote=%s\n",
 		id, cid, state.Term, state.Commit, vid)
 
-	fmt.Printf("WAL entries:\n")
-	fmt.Printf("lastIndex=%d\n", ents[len(ents)-1].Index)
+	printf("WAL entries:\n")
+	printf("lastIndex=%d\n", ents[len(ents)-1].Index)
 
-	fmt.Printf("%4s\t%10s\ttype\tdata", "term", "index")
-	if *streamdecoder != "" {
-		fmt.Printf("\tdecoder_status\tdecoded_data")
+	if !*summary {
+		printf("%4s\t%10s\ttype\tdata", "term", "index")
+		if *streamdecoder != "" {
+			printf("\tdecoder_status\tdecoded_data")
+		}
+		printf("\n")
 	}
-	fmt.Println()
 
-	listEntriesType(*entrytype, *streamdecoder, ents)
+	listEntriesType(opts, ents)
 }
 
 func walDir(dataDir string) string { return filepath.Join(dataDir, "member", "wal") }
@@ -308,8 +355,20 @@ IRRCompaction, IRRLeaseGrant, IRRLeaseRevoke, IRRLeaseCheckpoint`, et)
 	return filters
 }
 
-//  listEntriesType filters and prints entries based on the entry-type flag,
-func listEntriesType(entrytype string, streamdecoder string, ents []raftpb.Entry) {
+// dumpOptions are the flags deciding which entries are dumped and how.
+type dumpOptions struct {
+	entrytype     string
+	streamdecoder string
+	output        string
+	selector      entrySelector
+	// summary, if set, aggregates the selected entries instead of printing them.
+	summary *writeSummary
+}
+
+// listEntriesType filters and prints entries based on the entry-type flag and
+// the entry selector, or summarizes them.
+func listEntriesType(opts dumpOptions, ents []raftpb.Entry) {
+	entrytype, streamdecoder := opts.entrytype, opts.streamdecoder
 	entryFilters := evaluateEntrytypeFlag(entrytype)
 	printerMap := map[string]EntryPrinter{"InternalRaftRequest": printInternalRaftRequest,
 		"Request":       printRequest,
@@ -335,6 +394,8 @@ func listEntriesType(entrytype string, streamdecoder string, ents []raftpb.Entry
 	}
 
 	cnt := 0
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
 
 	for _, e := range ents {
 		passed := false
@@ -342,18 +403,27 @@ func listEntriesType(entrytype string, streamdecoder string, ents []raftpb.Entry
 		for _, filter := range entryFilters {
 			passed, currtype = filter(e)
 			if passed {
-				cnt++
 				break
 			}
 		}
-		if passed {
+		if !passed || !opts.selector.match(e) {
+			continue
+		}
+		cnt++
+
+		if opts.summary != nil {
+			opts.summary.add(e)
+			continue
+		}
+
+		var je jsonEntry
+		if opts.output == outputJSON {
+			je = newJSONEntry(e, currtype)
+		} else {
 			printer := printerMap[currtype]
 			printer(e)
-			if streamdecoder == "" {
-				fmt.Println()
-				continue
-			}
-
+		}
+		if streamdecoder != "" {
 			// if decoder is set, pass the e.Data to stdin and read the stdout from decoder
 			io.WriteString(stdin, hex.EncodeToString(e.Data))
 			io.WriteString(stdin, "\n")
@@ -366,7 +436,18 @@ func listEntriesType(entrytype string, streamdecoder string, ents []raftpb.Entry
 
 			decoder_status, decoded_data := parseDecoderOutput(decoderoutput)
 
-			fmt.Printf("\t%s\t%s", decoder_status, decoded_data)
+			if opts.output == outputJSON {
+				je.DecoderStatus, je.DecodedData = decoder_status, strings.TrimSuffix(decoded_data, "\n")
+			} else {
+				fmt.Printf("\t%s\t%s", decoder_status, decoded_data)
+			}
+		} else if opts.output != outputJSON {
+			fmt.Println()
+		}
+		if opts.output == outputJSON {
+			if err := enc.Encode(je); err != nil {
+				log.Panic(err)
+			}
 		}
 	}
 
@@ -381,7 +462,12 @@ func listEntriesType(entrytype string, streamdecoder string, ents []raftpb.Entry
 		}
 	}
 
-	fmt.Printf("\nEntry types (%s) count is : %d\n", entrytype, cnt)
+	if opts.summary != nil {
+		opts.summary.print(opts.output)
+	}
+	if opts.output == outputText {
+		fmt.Printf("\nEntry types (%s) count is : %d\n", entrytype, cnt)
+	}
 }
 
 func parseDecoderOutput(decoderoutput string) (string, string) {
@@ -0,0 +1,126 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// prefixStats counts the writes to the keys under a prefix.
+type prefixStats struct {
+	Prefix  jsonBytes `json:"prefix"`
+	Puts    int       `json:"puts"`
+	Deletes int       `json:"deletes"`
+	// Bytes is the size of the written keys and values.
+	Bytes int `json:"bytes"`
+}
+
+// writeSummary aggregates the writes of v3 requests per key prefix. Keys are
+// grouped by their first depth path segments, so that with depth 2 the key
+// "/registry/pods/default/nginx" is counted under "/registry/pods/". Operations
+// of both transaction branches are counted, as the WAL does not record which
+// branch was taken.
+type writeSummary struct {
+	depth    int
+	prefixes map[string]*prefixStats
+}
+
+func newWriteSummary(depth int) *writeSummary {
+	return &writeSummary{depth: depth, prefixes: make(map[string]*prefixStats)}
+}
+
+func (s *writeSummary) add(entry raftpb.Entry) {
+	var rr etcdserverpb.InternalRaftRequest
+	if entry.Type != raftpb.EntryNormal || rr.Unmarshal(entry.Data) != nil {
+		return
+	}
+	for _, op := range requestOps(&rr) {
+		if op.typ != opPut && op.typ != opDelete {
+			continue
+		}
+		prefix := keyPrefix(op.key, s.depth)
+		ps, ok := s.prefixes[string(prefix)]
+		if !ok {
+			ps = &prefixStats{Prefix: prefix}
+			s.prefixes[string(prefix)] = ps
+		}
+		if op.typ == opPut {
+			ps.Puts++
+		} else {
+			ps.Deletes++
+		}
+		ps.Bytes += op.size
+	}
+}
+
+// stats returns the prefixes sorted by written bytes, largest first.
+func (s *writeSummary) stats() []*prefixStats {
+	stats := make([]*prefixStats, 0, len(s.prefixes))
+	for _, ps := range s.prefixes {
+		stats = append(stats, ps)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Bytes != stats[j].Bytes {
+			return stats[i].Bytes > stats[j].Bytes
+		}
+		return bytes.Compare(stats[i].Prefix, stats[j].Prefix) < 0
+	})
+	return stats
+}
+
+func (s *writeSummary) print(output string) {
+	stats := s.stats()
+	if output == outputJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(struct {
+			Prefixes []*prefixStats `json:"prefixes"`
+		}{stats}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return
+	}
+	fmt.Printf("prefix\tputs\tdeletes\tbytes\n")
+	for _, ps := range stats {
+		fmt.Printf("%q\t%d\t%d\t%d\n", []byte(ps.Prefix), ps.Puts, ps.Deletes, ps.Bytes)
+	}
+}
+
+// keyPrefix returns the first depth '/'-separated segments of key including
+// the trailing separator, ignoring a leading separator. Keys with fewer
+// segments are returned whole.
+func keyPrefix(key []byte, depth int) []byte {
+	if depth <= 0 {
+		return []byte{}
+	}
+	start := 0
+	if len(key) > 0 && key[0] == '/' {
+		start = 1
+	}
+	for i := start; i < len(key); i++ {
+		if key[i] != '/' {
+			continue
+		}
+		if depth--; depth == 0 {
+			return key[:i+1]
+		}
+	}
+	return key
+}